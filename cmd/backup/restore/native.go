@@ -0,0 +1,155 @@
+package restore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/backups/engine"
+	"github.com/chainlaunch/chainlaunch/pkg/backups/service"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+)
+
+// openNativeRepository opens the repository of a backup target using the native engine
+func openNativeRepository(ctx context.Context) (*engine.Repository, error) {
+	var backend engine.Backend
+	switch {
+	case localPath != "":
+		backend = engine.NewLocalBackend(localPath)
+	case s3Endpoint != "" && bucketName != "":
+		s3, err := engine.NewS3Backend(engine.S3Config{
+			Endpoint:        s3Endpoint,
+			Bucket:          bucketName,
+			Prefix:          bucketPath,
+			AccessKeyID:     awsAccessKey,
+			SecretAccessKey: awsSecretKey,
+			PathStyle:       s3PathStyle,
+		})
+		if err != nil {
+			return nil, err
+		}
+		backend = s3
+	default:
+		return nil, fmt.Errorf("the native engine needs either --local-path or both --s3-endpoint and --bucket-name")
+	}
+	return engine.Open(ctx, backend, resticPassword)
+}
+
+// runNativeRestore restores a snapshot written by the native engine
+func runNativeRestore(ctx context.Context, log *logger.Logger) error {
+	repo, err := openNativeRepository(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	if listSnapshots {
+		return listNativeSnapshots(ctx, repo, log)
+	}
+
+	snapshot, err := repo.Snapshot(ctx, snapshotID)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot %s: %w", snapshotID, err)
+	}
+	log.Info("Found snapshot", "id", snapshot.ID, "time", snapshot.Time.Format(time.RFC3339))
+
+	include := func(p string) bool {
+		if !includeGlobal && path.Base(p) == "global-config.json" {
+			return false
+		}
+		if excludeConfig && strings.Contains(p, "/config/") {
+			return false
+		}
+		return true
+	}
+
+	if dryRun {
+		for _, file := range snapshot.Files {
+			if file.Type == engine.FileTypeFile && include(file.Path) {
+				fmt.Printf("%s (%s)\n", file.Path, formatByteSize(file.Size))
+			}
+		}
+		log.Info("Dry run completed. No files were actually restored.")
+		return nil
+	}
+
+	log.Info("Starting restore operation", "outputPath", outputPath)
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory %s: %w", outputPath, err)
+	}
+	result, err := repo.Restore(ctx, snapshot, outputPath, engine.RestoreOptions{
+		Root:    service.NativeDataSource,
+		Include: include,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	// The database is restored next to the data, where the restic engine places its copy
+	dbPath := filepath.Join(outputPath, "dbs", "chainlaunch.db")
+	if _, err := repo.Restore(ctx, snapshot, dbPath, engine.RestoreOptions{Root: service.NativeDatabaseSource}); err != nil {
+		return fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	log.Info("Restore completed successfully",
+		"outputPath", outputPath,
+		"database", dbPath,
+		"files", result.Files,
+		"size", formatByteSize(result.Bytes),
+		"timestamp", time.Now().Format(time.RFC3339))
+	return nil
+}
+
+// listNativeSnapshots lists the snapshots of a native engine repository with pagination
+func listNativeSnapshots(ctx context.Context, repo *engine.Repository, log *logger.Logger) error {
+	snapshots, err := repo.Snapshots(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get snapshots: %w", err)
+	}
+	if len(snapshots) == 0 {
+		log.Info("No snapshots found in the repository")
+		return nil
+	}
+
+	totalPages := (len(snapshots) + snapshotsLimit - 1) / snapshotsLimit
+	if snapshotsPage < 1 {
+		snapshotsPage = 1
+	} else if snapshotsPage > totalPages {
+		snapshotsPage = totalPages
+	}
+	startIndex := (snapshotsPage - 1) * snapshotsLimit
+	endIndex := startIndex + snapshotsLimit
+	if endIndex > len(snapshots) {
+		endIndex = len(snapshots)
+	}
+
+	log.Info(fmt.Sprintf("Showing snapshots %d-%d of %d (Page %d of %d)",
+		startIndex+1, endIndex, len(snapshots), snapshotsPage, totalPages))
+
+	fmt.Println("\nAvailable Snapshots:")
+	fmt.Println("===================")
+	for i := startIndex; i < endIndex; i++ {
+		snapshot := snapshots[i]
+		fmt.Printf("%d. ID: %s\n", i+1, snapshot.ID)
+		fmt.Printf("   Created: %s\n", snapshot.Time.Format(time.RFC3339))
+		fmt.Printf("   Host: %s\n", snapshot.Hostname)
+		fmt.Printf("   Size: %s in %d files\n", formatByteSize(snapshot.Summary.TotalBytes), snapshot.Summary.Files)
+		if len(snapshot.Tags) > 0 {
+			fmt.Printf("   Tags: %s\n", strings.Join(snapshot.Tags, ", "))
+		}
+		fmt.Println()
+	}
+
+	if totalPages > 1 {
+		fmt.Println("\nPagination:")
+		fmt.Printf("Use --page=N to view different pages (1-%d)\n", totalPages)
+		fmt.Printf("Use --limit=N to change the number of snapshots per page (current: %d)\n", snapshotsLimit)
+	}
+
+	fmt.Println("\nTo restore a specific snapshot:")
+	fmt.Println("chainlaunch backup restore --engine=native --snapshot-id=<ID> [other options]")
+	return nil
+}
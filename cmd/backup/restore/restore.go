@@ -10,11 +10,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/chainlaunch/chainlaunch/pkg/backups/service"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
 	"github.com/spf13/cobra"
 )
 
 var (
+	backupEngine   string
 	snapshotID     string
 	repoURL        string
 	awsAccessKey   string
@@ -24,6 +26,14 @@ var (
 	bucketName     string
 	bucketPath     string
 	s3PathStyle    bool
+	localPath      string
+	sftpHost       string
+	sftpPort       int
+	sftpUser       string
+	sftpKeyFile    string
+	sftpKnownHosts string
+	sftpPath       string
+	resticOptions  []string
 	outputPath     string
 	includeGlobal  bool
 	excludeConfig  bool
@@ -59,10 +69,15 @@ func NewRestoreCmd() *cobra.Command {
 		Short: "Restore a backup of ChainLaunch",
 		Long: `Restore a ChainLaunch instance from a backup.
 This command allows restoring from a specific restic snapshot to a designated location.
+The repository can be an S3 bucket, a local directory (--local-path) or a directory on
+an SFTP server (--sftp-host, --sftp-user, --sftp-key, --sftp-known-hosts and --sftp-path).
+Backups written by the native engine (--engine=native) are restored without the restic
+binary, from an S3 bucket or a local directory.
 `,
 		RunE: runRestore,
 	}
 
+	cmd.Flags().StringVar(&backupEngine, "engine", "restic", "Engine that wrote the backup (restic or native)")
 	cmd.Flags().StringVar(&snapshotID, "snapshot-id", "latest", "ID of the restic snapshot to restore (defaults to latest snapshot)")
 	cmd.Flags().StringVar(&repoURL, "repo-url", "", "Restic repository URL (e.g. s3:https://s3.amazonaws.com/bucket-name/path)")
 	cmd.Flags().StringVar(&awsAccessKey, "aws-access-key", "", "AWS Access Key ID for S3 access")
@@ -72,6 +87,13 @@ This command allows restoring from a specific restic snapshot to a designated lo
 	cmd.Flags().StringVar(&bucketName, "bucket-name", "", "S3 bucket name")
 	cmd.Flags().StringVar(&bucketPath, "bucket-path", "", "Path within the S3 bucket")
 	cmd.Flags().BoolVar(&s3PathStyle, "s3-path-style", false, "Use S3 path style addressing")
+	cmd.Flags().StringVar(&localPath, "local-path", "", "Directory of a local filesystem backup target")
+	cmd.Flags().StringVar(&sftpHost, "sftp-host", "", "SFTP server host")
+	cmd.Flags().IntVar(&sftpPort, "sftp-port", service.DefaultSFTPPort, "SFTP server port")
+	cmd.Flags().StringVar(&sftpUser, "sftp-user", "", "SFTP username")
+	cmd.Flags().StringVar(&sftpKeyFile, "sftp-key", "", "Private key file used to authenticate to the SFTP server")
+	cmd.Flags().StringVar(&sftpKnownHosts, "sftp-known-hosts", "", "known_hosts file holding the host key of the SFTP server")
+	cmd.Flags().StringVar(&sftpPath, "sftp-path", "", "Directory on the SFTP server")
 	cmd.Flags().StringVar(&outputPath, "output", "", "Path where to restore the backup (default: .chainlaunch in home directory)")
 	cmd.Flags().BoolVar(&includeGlobal, "include-global", false, "Include global configuration in the restore")
 	cmd.Flags().BoolVar(&excludeConfig, "exclude-config", false, "Exclude configuration files from restore")
@@ -99,13 +121,32 @@ func runRestore(cmd *cobra.Command, cmdArgs []string) error {
 		outputPath = filepath.Join(homeDir, ".chainlaunch")
 	}
 
-	// Validate required flags for S3 access
-	if repoURL == "" && (s3Endpoint == "" || bucketName == "") {
-		return fmt.Errorf("either --repo-url or both --s3-endpoint and --bucket-name must be specified")
+	switch strings.ToUpper(backupEngine) {
+	case string(service.BackupEngineNative):
+		return runNativeRestore(ctx, log)
+	case string(service.BackupEngineRestic):
+	default:
+		return fmt.Errorf("unsupported engine %s, expected restic or native", backupEngine)
 	}
 
 	// Construct repository URL if not provided directly
-	if repoURL == "" {
+	switch {
+	case repoURL != "":
+		// Repository given directly
+	case localPath != "":
+		repoURL = fmt.Sprintf("local:%s", localPath)
+	case sftpHost != "":
+		if sftpUser == "" || sftpKeyFile == "" || sftpKnownHosts == "" || sftpPath == "" {
+			return fmt.Errorf("--sftp-user, --sftp-key, --sftp-known-hosts and --sftp-path must be specified with --sftp-host")
+		}
+		if err := service.ValidateSFTPLogin(sftpUser, sftpHost); err != nil {
+			return err
+		}
+		repoURL = service.SFTPRepositoryURL(sftpUser, sftpHost, sftpPath)
+		resticOptions = []string{"-o", "sftp.command=" + service.SFTPCommand(sftpUser, sftpHost, sftpPort, sftpKeyFile, sftpKnownHosts)}
+	case s3Endpoint == "" || bucketName == "":
+		return fmt.Errorf("either --repo-url, --local-path, --sftp-host or both --s3-endpoint and --bucket-name must be specified")
+	default:
 		endpointURL := s3Endpoint
 		if !strings.HasPrefix(endpointURL, "http://") && !strings.HasPrefix(endpointURL, "https://") {
 			endpointURL = "https://" + endpointURL
@@ -175,8 +216,7 @@ func runRestore(cmd *cobra.Command, cmdArgs []string) error {
 	}
 
 	// Execute restore command
-	cmd2 := exec.CommandContext(ctx, "restic", args...)
-	cmd2.Env = append(os.Environ(), env...)
+	cmd2 := resticCommand(ctx, env, args...)
 	cmd2.Stdout = os.Stdout
 	cmd2.Stderr = os.Stderr
 
@@ -198,8 +238,7 @@ func runRestore(cmd *cobra.Command, cmdArgs []string) error {
 // listAvailableSnapshots lists available snapshots with pagination
 func listAvailableSnapshots(env []string, log *logger.Logger) error {
 	// Get all snapshots in JSON format
-	cmd := exec.Command("restic", "snapshots", "--json")
-	cmd.Env = append(os.Environ(), env...)
+	cmd := resticCommand(context.Background(), env, "snapshots", "--json")
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -298,7 +337,7 @@ func formatByteSize(bytes int64) string {
 	}
 }
 
-// prepareResticEnvironment sets up the environment variables required for restic to access S3
+// prepareResticEnvironment sets up the environment variables required for restic to access the repository
 func prepareResticEnvironment() []string {
 	// Set up restic environment variables for S3
 	env := []string{
@@ -328,10 +367,16 @@ func prepareResticEnvironment() []string {
 	return env
 }
 
+// resticCommand returns a restic command running against the repository
+func resticCommand(ctx context.Context, env []string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "restic", append(append([]string{}, resticOptions...), args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	return cmd
+}
+
 // verifyResticRepo verifies that the restic repository exists and is accessible
 func verifyResticRepo(env []string) error {
-	cmd := exec.Command("restic", "check")
-	cmd.Env = append(os.Environ(), env...)
+	cmd := resticCommand(context.Background(), env, "check")
 	cmd.Stdout = nil // Discard output
 
 	return cmd.Run()
@@ -339,8 +384,7 @@ func verifyResticRepo(env []string) error {
 
 // getLatestSnapshotID gets the ID of the latest snapshot in the repository
 func getLatestSnapshotID(env []string) (string, error) {
-	cmd := exec.Command("restic", "snapshots", "latest", "--json")
-	cmd.Env = append(os.Environ(), env...)
+	cmd := resticCommand(context.Background(), env, "snapshots", "latest", "--json")
 
 	output, err := cmd.Output()
 	if err != nil {
@@ -18,18 +18,28 @@ type Client struct {
 	baseURL  string
 	username string
 	password string
+	// token is an API token, used instead of the username and password when set
+	token string
 }
 
-// NewClientFromEnv creates a new client using environment variables
+// NewClientFromEnv creates a new client using environment variables.
+// CHAINLAUNCH_API_TOKEN takes precedence over CHAINLAUNCH_USER and CHAINLAUNCH_PASSWORD.
 func NewClientFromEnv() (*Client, error) {
 	apiURL := os.Getenv("CHAINLAUNCH_API_URL")
 	if apiURL == "" {
 		apiURL = defaultAPIURL
 	}
 
+	if token := os.Getenv("CHAINLAUNCH_API_TOKEN"); token != "" {
+		return &Client{
+			baseURL: apiURL,
+			token:   token,
+		}, nil
+	}
+
 	username := os.Getenv("CHAINLAUNCH_USER")
 	if username == "" {
-		return nil, fmt.Errorf("CHAINLAUNCH_USER environment variable is not set")
+		return nil, fmt.Errorf("CHAINLAUNCH_API_TOKEN or CHAINLAUNCH_USER environment variable is not set")
 	}
 
 	password := os.Getenv("CHAINLAUNCH_PASSWORD")
@@ -61,7 +71,11 @@ func (c *Client) DoRequest(method, path string, body interface{}) (*http.Respons
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.SetBasicAuth(c.username, c.password)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else {
+		req.SetBasicAuth(c.username, c.password)
+	}
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	httptypes "github.com/chainlaunch/chainlaunch/pkg/networks/http"
+	networkservice "github.com/chainlaunch/chainlaunch/pkg/networks/service"
 )
 
 // CreateFabricNetwork creates a new Fabric network using the REST API
@@ -153,3 +154,37 @@ func (c *Client) ListBesuNetworks() (*httptypes.ListNetworksResponse, error) {
 	}
 	return &result, nil
 }
+
+// UpdateBesuValidators proposes validator set changes on a Besu network using the REST API
+func (c *Client) UpdateBesuValidators(networkID int64, req *httptypes.UpdateBesuValidatorsRequest) (*httptypes.BesuValidatorChangesResponse, error) {
+	resp, err := c.Post(fmt.Sprintf("/networks/besu/%d/validators", networkID), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update besu validators: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := CheckResponse(resp, http.StatusAccepted); err != nil {
+		return nil, err
+	}
+	var changes httptypes.BesuValidatorChangesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&changes); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &changes, nil
+}
+
+// GetBesuValidatorChange gets a validator change of a Besu network using the REST API
+func (c *Client) GetBesuValidatorChange(networkID, changeID int64) (*networkservice.BesuValidatorChange, error) {
+	resp, err := c.Get(fmt.Sprintf("/networks/besu/%d/validators/changes/%d", networkID, changeID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get besu validator change: %w", err)
+	}
+	defer resp.Body.Close()
+	if err := CheckResponse(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+	var change networkservice.BesuValidatorChange
+	if err := json.NewDecoder(resp.Body).Decode(&change); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &change, nil
+}
@@ -0,0 +1,123 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
+)
+
+// ListServiceAccounts lists all service accounts
+func (c *Client) ListServiceAccounts() ([]auth.ServiceAccount, error) {
+	resp, err := c.Get("/service-accounts")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+	if err := CheckResponse(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	body, err := ReadBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var accounts []auth.ServiceAccount
+	if err := json.Unmarshal(body, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return accounts, nil
+}
+
+// CreateServiceAccount creates a service account
+func (c *Client) CreateServiceAccount(req *auth.CreateServiceAccountRequest) (*auth.ServiceAccount, error) {
+	resp, err := c.Post("/service-accounts", req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+	if err := CheckResponse(resp, http.StatusCreated); err != nil {
+		return nil, err
+	}
+
+	body, err := ReadBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var account auth.ServiceAccount
+	if err := json.Unmarshal(body, &account); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &account, nil
+}
+
+// DeleteServiceAccount deletes a service account and all of its API tokens
+func (c *Client) DeleteServiceAccount(id int64) error {
+	resp, err := c.Delete(fmt.Sprintf("/service-accounts/%d", id))
+	if err != nil {
+		return fmt.Errorf("failed to delete service account: %w", err)
+	}
+	if err := CheckResponse(resp, http.StatusNoContent); err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ListAPITokens lists the API tokens of a service account
+func (c *Client) ListAPITokens(accountID int64) ([]auth.APIToken, error) {
+	resp, err := c.Get(fmt.Sprintf("/service-accounts/%d/tokens", accountID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	if err := CheckResponse(resp, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	body, err := ReadBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var tokens []auth.APIToken
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return tokens, nil
+}
+
+// CreateAPIToken creates an API token for a service account
+func (c *Client) CreateAPIToken(accountID int64, req *auth.CreateAPITokenRequest) (*auth.CreateAPITokenResponse, error) {
+	resp, err := c.Post(fmt.Sprintf("/service-accounts/%d/tokens", accountID), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API token: %w", err)
+	}
+	if err := CheckResponse(resp, http.StatusCreated); err != nil {
+		return nil, err
+	}
+
+	body, err := ReadBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var token auth.CreateAPITokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return &token, nil
+}
+
+// RevokeAPIToken revokes an API token
+func (c *Client) RevokeAPIToken(id int64) error {
+	resp, err := c.Delete(fmt.Sprintf("/api-tokens/%d", id))
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	if err := CheckResponse(resp, http.StatusNoContent); err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
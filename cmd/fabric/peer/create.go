@@ -26,6 +26,8 @@ type createCmd struct {
 	envVars                 []string
 	addressOverrides        []string
 	ordererAddressOverrides []string
+	couchDB                 bool
+	couchDBURL              string
 	logger                  *logger.Logger
 }
 
@@ -124,6 +126,12 @@ func (c *createCmd) run(out io.Writer) error {
 		AddressOverrides:        addressOverrides,
 		OrdererAddressOverrides: ordererAddressOverrides,
 	}
+	if c.couchDB {
+		req.CouchDB = &types.CouchDBConfig{
+			Enabled: true,
+			URL:     c.couchDBURL,
+		}
+	}
 
 	// Create peer node
 	node, err := client.CreatePeerNode(req)
@@ -167,6 +175,8 @@ func NewCreateCmd(logger *logger.Logger) *cobra.Command {
 	flags.StringArrayVar(&c.envVars, "env", []string{}, "Environment variables in KEY=VALUE format")
 	flags.StringArrayVar(&c.addressOverrides, "address-override", []string{}, "Address overrides in FROM=TO format")
 	flags.StringArrayVar(&c.ordererAddressOverrides, "orderer-address-override", []string{}, "Orderer address overrides in FROM=TO format")
+	flags.BoolVar(&c.couchDB, "couchdb", false, "Use CouchDB as the state database instead of goleveldb")
+	flags.StringVar(&c.couchDBURL, "couchdb-url", "", "URL of the external CouchDB, required in service mode")
 
 	cmd.MarkFlagRequired("name")
 	cmd.MarkFlagRequired("msp-id")
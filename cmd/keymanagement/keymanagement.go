@@ -16,6 +16,7 @@ func NewKeyManagementCmd() *cobra.Command {
 
 	rootCmd.AddCommand(NewCreateCmd())
 	rootCmd.AddCommand(NewGetCmd())
+	rootCmd.AddCommand(NewRekeyCmd())
 	return rootCmd
 }
 
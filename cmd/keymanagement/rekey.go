@@ -0,0 +1,79 @@
+package keymanagement
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chainlaunch/chainlaunch/cmd/common"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/models"
+)
+
+type rekeyCmd struct{}
+
+func (c *rekeyCmd) run(out *os.File) error {
+	client, err := common.NewClientFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	resp, err := client.Post("/keys/rekey", nil)
+	if err != nil {
+		return fmt.Errorf("failed to rekey keys: %w", err)
+	}
+
+	if err := common.CheckResponse(resp, 200); err != nil {
+		return err
+	}
+
+	var rekeyResp models.RekeyResponse
+	body, err := common.ReadBody(resp)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := json.Unmarshal(body, &rekeyResp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	prettyJSON, err := json.MarshalIndent(rekeyResp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to format response: %w", err)
+	}
+
+	fmt.Fprintln(out, string(prettyJSON))
+	return nil
+}
+
+// NewRekeyCmd returns the rekey command
+func NewRekeyCmd() *cobra.Command {
+	c := &rekeyCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "rekey",
+		Short: "Re-encrypt stored private keys with the active key encryption key",
+		Long: `Re-encrypt every private key stored by the database provider with the active
+key encryption key, together with the secrets other services store in the
+database: backup target secrets, SMTP passwords and CouchDB passwords of peers.
+They are updated in a single transaction; the number of secrets re-encrypted
+is reported as resealedSecrets.
+
+To rotate the key encryption key, restart the server with the new key in
+KEY_ENCRYPTION_KEY (or KEY_ENCRYPTION_KEY_FILE) and the old one in
+KEY_ENCRYPTION_KEY_PREVIOUS, run this command, then remove the old key.
+
+When neither variable is set the server reads the key from the encryption_key
+file of its data directory. If that file exists, a successful rekey overwrites
+it with the new active key (as configured, so a Vault wrapped key stays
+wrapped), so the server still finds the key after a restart without the
+variables. The updated file is reported as keyFile.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(os.Stdout)
+		},
+	}
+
+	return cmd
+}
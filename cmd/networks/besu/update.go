@@ -1,82 +1,110 @@
 package besu
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"time"
 
 	"github.com/chainlaunch/chainlaunch/cmd/common"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
 	"github.com/chainlaunch/chainlaunch/pkg/networks/http"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service"
 	"github.com/spf13/cobra"
 )
 
 func newUpdateCmd(logger *logger.Logger) *cobra.Command {
 	var (
-		networkID  int64
-		configFile string
+		networkID          int64
+		addValidatorKeys   []int64
+		addValidators      []string
+		removeValidatorKey []int64
+		removeValidators   []string
+		wait               bool
+		timeout            time.Duration
 	)
 
 	updateCmd := &cobra.Command{
 		Use:   "update",
-		Short: "Update an existing Besu network",
-		Long:  `Update the configuration of an existing Besu network.`,
+		Short: "Update the validators of a Besu network",
+		Long: `Add or remove validators of a running Besu network.
+
+The validators managed by ChainLaunch vote for every change. A change is applied
+by the network once more than half of the validators voted for it.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := common.NewClientFromEnv()
 			if err != nil {
 				return fmt.Errorf("failed to create client: %w", err)
 			}
 
-			// Read config file
-			configData, err := os.ReadFile(configFile)
-			if err != nil {
-				return fmt.Errorf("failed to read config file: %w", err)
+			var req http.UpdateBesuValidatorsRequest
+			for _, keyID := range addValidatorKeys {
+				req.Changes = append(req.Changes, http.BesuValidatorChangeRequest{Action: service.BesuValidatorActionAdd, KeyID: keyID})
 			}
-
-			var config http.CreateBesuNetworkRequest
-			if err := json.Unmarshal(configData, &config); err != nil {
-				return fmt.Errorf("failed to parse besu config: %w", err)
+			for _, address := range addValidators {
+				req.Changes = append(req.Changes, http.BesuValidatorChangeRequest{Action: service.BesuValidatorActionAdd, Address: address})
+			}
+			for _, keyID := range removeValidatorKey {
+				req.Changes = append(req.Changes, http.BesuValidatorChangeRequest{Action: service.BesuValidatorActionRemove, KeyID: keyID})
+			}
+			for _, address := range removeValidators {
+				req.Changes = append(req.Changes, http.BesuValidatorChangeRequest{Action: service.BesuValidatorActionRemove, Address: address})
+			}
+			if len(req.Changes) == 0 {
+				return fmt.Errorf("no validator changes requested")
 			}
 
-			resp, err := updateBesuNetwork(client, networkID, &config)
+			resp, err := client.UpdateBesuValidators(networkID, &req)
 			if err != nil {
-				return fmt.Errorf("failed to update besu network: %w", err)
+				return fmt.Errorf("failed to update besu validators: %w", err)
 			}
 
-			// Print response
-			fmt.Printf("Besu network updated successfully:\n")
-			fmt.Printf("ID: %d\n", resp.ID)
-			fmt.Printf("Name: %s\n", resp.Name)
-			fmt.Printf("Platform: %s\n", resp.Platform)
-			fmt.Printf("Status: %s\n", resp.Status)
+			for _, change := range resp.Changes {
+				printValidatorChange(change)
+			}
+			if !wait {
+				return nil
+			}
+
+			deadline := time.Now().Add(timeout)
+			for _, change := range resp.Changes {
+				for change.Status == service.BesuValidatorChangeStatusPending {
+					if time.Now().After(deadline) {
+						return fmt.Errorf("timed out waiting for validator change %d", change.ID)
+					}
+					time.Sleep(5 * time.Second)
+					change, err = client.GetBesuValidatorChange(networkID, change.ID)
+					if err != nil {
+						return fmt.Errorf("failed to get validator change: %w", err)
+					}
+				}
+				printValidatorChange(change)
+				if change.Status != service.BesuValidatorChangeStatusApplied {
+					return fmt.Errorf("validator change %d %s: %s", change.ID, change.Status, change.Error)
+				}
+			}
 
 			return nil
 		},
 	}
 
 	updateCmd.Flags().Int64Var(&networkID, "id", 0, "Network ID")
-	updateCmd.Flags().StringVar(&configFile, "config", "", "Path to network configuration file")
+	updateCmd.Flags().Int64SliceVar(&addValidatorKeys, "add-validator-key", nil, "ID of the key of a validator to add")
+	updateCmd.Flags().StringSliceVar(&addValidators, "add-validator", nil, "Address of a validator to add")
+	updateCmd.Flags().Int64SliceVar(&removeValidatorKey, "remove-validator-key", nil, "ID of the key of a validator to remove")
+	updateCmd.Flags().StringSliceVar(&removeValidators, "remove-validator", nil, "Address of a validator to remove")
+	updateCmd.Flags().BoolVar(&wait, "wait", false, "Wait until the changes are applied")
+	updateCmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "Maximum time to wait for the changes to be applied")
 
 	updateCmd.MarkFlagRequired("id")
-	updateCmd.MarkFlagRequired("config")
 
 	return updateCmd
 }
 
-func updateBesuNetwork(client *common.Client, networkID int64, req *http.CreateBesuNetworkRequest) (*http.NetworkResponse, error) {
-	resp, err := client.Put(fmt.Sprintf("/networks/besu/%d", networkID), req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to update besu network: %w", err)
-	}
-
-	if err := common.CheckResponse(resp, 200); err != nil {
-		return nil, fmt.Errorf("failed to update besu network: %w", err)
+func printValidatorChange(change *service.BesuValidatorChange) {
+	votes := 0
+	for _, vote := range change.Votes {
+		if vote.Error == "" {
+			votes++
+		}
 	}
-
-	var network http.NetworkResponse
-	if err := json.NewDecoder(resp.Body).Decode(&network); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	return &network, nil
+	fmt.Printf("Change %d: %s %s - %s (%d/%d votes)\n", change.ID, change.Action, change.Address, change.Status, votes, change.VotesRequired)
 }
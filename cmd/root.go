@@ -11,6 +11,7 @@ import (
 	"github.com/chainlaunch/chainlaunch/cmd/metrics"
 	"github.com/chainlaunch/chainlaunch/cmd/networks"
 	"github.com/chainlaunch/chainlaunch/cmd/serve"
+	"github.com/chainlaunch/chainlaunch/cmd/serviceaccounts"
 	"github.com/chainlaunch/chainlaunch/cmd/testnet"
 	"github.com/chainlaunch/chainlaunch/cmd/version"
 	"github.com/chainlaunch/chainlaunch/config"
@@ -39,6 +40,7 @@ func NewRootCmd(configCMD config.ConfigCMD) *cobra.Command {
 	rootCmd.AddCommand(keymanagement.NewKeyManagementCmd())
 	rootCmd.AddCommand(testnet.NewTestnetCmd())
 	rootCmd.AddCommand(metrics.NewMetricsCmd())
+	rootCmd.AddCommand(serviceaccounts.NewServiceAccountsCmd())
 	// In the function where rootCmd is defined and commands are added:
 	// rootCmd.AddCommand(testnet.NewTestnetCmd())
 	return rootCmd
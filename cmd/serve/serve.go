@@ -24,8 +24,9 @@ import (
 	"github.com/chainlaunch/chainlaunch/pkg/db"
 	fabrichandler "github.com/chainlaunch/chainlaunch/pkg/fabric/handler"
 	fabricservice "github.com/chainlaunch/chainlaunch/pkg/fabric/service"
-	"github.com/chainlaunch/chainlaunch/pkg/http/response"
 	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/handler"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/kek"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/secrets"
 	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/service"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
 	metricscommon "github.com/chainlaunch/chainlaunch/pkg/metrics/common"
@@ -35,6 +36,7 @@ import (
 	"github.com/chainlaunch/chainlaunch/pkg/audit"
 	"github.com/chainlaunch/chainlaunch/pkg/chainlaunchdeploy"
 	"github.com/chainlaunch/chainlaunch/pkg/metrics"
+	"github.com/chainlaunch/chainlaunch/pkg/metrics/alerts"
 	networkshttp "github.com/chainlaunch/chainlaunch/pkg/networks/http"
 	networksservice "github.com/chainlaunch/chainlaunch/pkg/networks/service"
 	nodeshttp "github.com/chainlaunch/chainlaunch/pkg/nodes/http"
@@ -283,7 +285,7 @@ func ensureKeyExists(filename string, dataPath string) (string, error) {
 }
 
 // setupServer configures and returns the HTTP server
-func setupServer(queries *db.Queries, authService *auth.AuthService, views embed.FS, dev bool, dbPath string, dataPath string) *chi.Mux {
+func setupServer(queries *db.Queries, authService *auth.AuthService, views embed.FS, dev bool, dbPath string, dataPath string, certExpiryConfig *monitoring.CertificateExpiryConfig) *chi.Mux {
 	// Initialize services
 	keyManagementService, err := service.NewKeyManagementService(queries)
 	if err != nil {
@@ -292,6 +294,16 @@ func setupServer(queries *db.Queries, authService *auth.AuthService, views embed
 	if err := keyManagementService.InitializeKeyProviders(context.Background()); err != nil {
 		log.Fatal("Failed to initialize key providers:", err)
 	}
+	configDir, err := getConfigDir(dataPath)
+	if err != nil {
+		log.Fatal("Failed to get config directory:", err)
+	}
+	keyManagementService.SetKeyEncryptionKeyFile(filepath.Join(configDir, encryptionKeyFile))
+	secretStore, err := secrets.Load(context.Background())
+	if err != nil {
+		log.Fatal("Failed to initialize secret store:", err)
+	}
+	keyManagementService.SetSecretStore(secretStore)
 	configService := configservice.NewConfigService(dataPath)
 	organizationService := fabricservice.NewOrganizationService(queries, keyManagementService, configService)
 	logger := logger.NewDefault()
@@ -308,7 +320,7 @@ func setupServer(queries *db.Queries, authService *auth.AuthService, views embed
 
 	// Initialize metrics service
 	metricsConfig := metricscommon.DefaultConfig()
-	nodesService := nodesservice.NewNodeService(queries, logger, keyManagementService, organizationService, nodeEventService, configService, settingsService)
+	nodesService := nodesservice.NewNodeService(queries, logger, keyManagementService, organizationService, nodeEventService, configService, settingsService, secretStore)
 	metricsService, err := metrics.NewService(metricsConfig, queries, nodesService)
 	if err != nil {
 		log.Fatal("Failed to initialize metrics service:", err)
@@ -317,17 +329,21 @@ func setupServer(queries *db.Queries, authService *auth.AuthService, views embed
 	metricsHandler := metrics.NewHandler(metricsService, logger)
 
 	networksService := networksservice.NewNetworkService(queries, nodesService, keyManagementService, logger, organizationService)
-	notificationService := notificationservice.NewNotificationService(queries, logger)
-	backupService := backupservice.NewBackupService(queries, logger, notificationService, dbPath, configService)
+	notificationService := notificationservice.NewNotificationService(queries, logger, secretStore)
+	backupService := backupservice.NewBackupService(queries, logger, notificationService, dbPath, configService, nodesService, secretStore)
 
 	// Initialize and start monitoring service
 	monitoringConfig := &monitoring.Config{
-		DefaultCheckInterval:    1 * time.Minute,  // Check nodes every minute by default
-		DefaultTimeout:          10 * time.Second, // 10 second timeout for checks
-		DefaultFailureThreshold: 3,                // Alert after 3 consecutive failures
-		Workers:                 3,                // Use 3 worker goroutines
+		DefaultCheckInterval:    1 * time.Minute,      // Check nodes every minute by default
+		DefaultTimeout:          10 * time.Second,     // 10 second timeout for checks
+		DefaultFailureThreshold: 3,                    // Alert after 3 consecutive failures
+		Workers:                 3,                    // Use 3 worker goroutines
+		CheckRetention:          7 * 24 * time.Hour,   // Keep raw check results for a week
+		RollupRetention:         365 * 24 * time.Hour, // Keep hourly uptime rollups for a year
+		MaintenanceInterval:     15 * time.Minute,     // Roll up and clean history every 15 minutes
+		MaxBlockLag:             5,                    // Report nodes more than 5 blocks behind as degraded
 	}
-	monitoringService := monitoring.NewService(logger, monitoringConfig, notificationService, nodesService)
+	monitoringService := monitoring.NewService(logger, monitoringConfig, notificationService, nodesService, queries)
 
 	// Start the monitoring service with a background context
 	monitoringCtx, monitoringCancel := context.WithCancel(context.Background())
@@ -335,6 +351,18 @@ func setupServer(queries *db.Queries, authService *auth.AuthService, views embed
 		log.Fatal("Failed to start monitoring service:", err)
 	}
 
+	// Start the certificate expiry scanner
+	certExpiryScanner := monitoring.NewCertificateExpiryScanner(logger, certExpiryConfig, queries, notificationService, nodesService)
+	if err := certExpiryScanner.Start(monitoringCtx); err != nil {
+		log.Fatal("Failed to start certificate expiry scanner:", err)
+	}
+
+	// Start evaluating the alert rules against Prometheus
+	alertsService := alerts.NewService(logger, alerts.DefaultConfig(), queries, metricsService, notificationService)
+	if err := alertsService.Start(monitoringCtx); err != nil {
+		log.Fatal("Failed to start alerts service:", err)
+	}
+
 	// Register shutdown handler for the monitoring service
 	go func() {
 		// This is a simple channel to catch SIGINT/SIGTERM
@@ -345,6 +373,10 @@ func setupServer(queries *db.Queries, authService *auth.AuthService, views embed
 		if err := monitoringService.Stop(); err != nil {
 			log.Printf("Error stopping monitoring service: %v", err)
 		}
+		if err := certExpiryScanner.Stop(); err != nil {
+			log.Printf("Error stopping certificate expiry scanner: %v", err)
+		}
+		alertsService.Stop()
 	}()
 
 	// Add nodes to monitor based on existing nodes in the system
@@ -485,8 +517,37 @@ func setupServer(queries *db.Queries, authService *auth.AuthService, views embed
 		nodesService,
 	)
 	backupHandler := backuphttp.NewHandler(backupService)
+	monitoringHandler := monitoring.NewHandler(monitoringService, logger)
+	alertsHandler := alerts.NewHandler(alertsService, logger)
 	notificationHandler := notificationhttp.NewNotificationHandler(notificationService)
 	authHandler := auth.NewHandler(authService)
+	// Two-factor authentication is optional unless CHAINLAUNCH_REQUIRE_TOTP enforces it
+	totpPolicy, err := auth.TOTPPolicyFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid two-factor authentication policy: %v", err)
+	}
+	authService.SetTOTPPolicy(totpPolicy)
+	// X-Forwarded-For is only honored from the reverse proxies in CHAINLAUNCH_TRUSTED_PROXIES
+	trustedProxies, err := auth.TrustedProxiesFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+	authService.SetTrustedProxies(trustedProxies)
+	authService.SetSecurityEventLogger(auditService)
+	// Single sign-on is optional, the OIDC provider stays nil when it is not configured
+	var oidcProvider *auth.OIDCProvider
+	oidcConfig, err := auth.OIDCConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid OIDC configuration: %v", err)
+	}
+	if oidcConfig != nil {
+		oidcProvider, err = auth.NewOIDCProvider(context.Background(), oidcConfig)
+		if err != nil {
+			log.Fatalf("Failed to initialize OIDC provider: %v", err)
+		}
+		logger.Infof("Single sign-on enabled with OIDC provider %s", oidcConfig.IssuerURL)
+	}
+	oidcHandler := auth.NewOIDCHandler(authService, oidcProvider)
 	auditHandler := audit.NewHandler(auditService, logger)
 	// Setup router
 	r := chi.NewRouter()
@@ -510,7 +571,8 @@ func setupServer(queries *db.Queries, authService *auth.AuthService, views embed
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
 		// Public routes (no auth required)
-		r.Post("/auth/login", response.Middleware(authHandler.LoginHandler))
+		authHandler.RegisterPublicRoutes(r)
+		oidcHandler.RegisterRoutes(r)
 
 		// Protected routes
 		r.Group(func(r chi.Router) {
@@ -538,6 +600,10 @@ func setupServer(queries *db.Queries, authService *auth.AuthService, views embed
 			pluginHandler.RegisterRoutes(r)
 			// Mount metrics routes
 			metricsHandler.RegisterRoutes(r)
+			// Mount monitoring routes
+			monitoringHandler.RegisterRoutes(r)
+			// Mount alerts routes
+			alertsHandler.RegisterRoutes(r)
 
 			// Mount audit routes
 			auditHandler.RegisterRoutes(r)
@@ -610,6 +676,9 @@ type serveCmd struct {
 	dataPath    string
 	dev         bool
 
+	certExpiryInterval   time.Duration
+	certExpiryThresholds []int
+
 	queries *db.Queries
 }
 
@@ -628,6 +697,15 @@ func (c *serveCmd) validate() error {
 		return fmt.Errorf("both TLS certificate and key files must be provided")
 	}
 
+	if c.certExpiryInterval <= 0 {
+		return fmt.Errorf("certificate expiry interval must be positive")
+	}
+	for _, days := range c.certExpiryThresholds {
+		if days <= 0 {
+			return fmt.Errorf("invalid certificate expiry threshold: %d days", days)
+		}
+	}
+
 	// If TLS files are provided, verify they exist
 	if c.tlsCertFile != "" {
 		if _, err := os.Stat(c.tlsCertFile); os.IsNotExist(err) {
@@ -683,13 +761,16 @@ func (c *serveCmd) preRun() error {
 }
 
 func (c *serveCmd) run() error {
-	// Initialize encryption key with dataPath
-	encryptionKey, err := ensureKeyExists(encryptionKeyFile, c.dataPath)
-	if err != nil {
-		log.Fatalf("Failed to initialize encryption key: %v", err)
-	}
-	if err := os.Setenv("KEY_ENCRYPTION_KEY", encryptionKey); err != nil {
-		log.Fatalf("Failed to set encryption key environment variable: %v", err)
+	// Initialize encryption key with dataPath, unless the key encryption key is
+	// provided explicitly (env var, file or Vault wrapped)
+	if !kek.Configured() {
+		encryptionKey, err := ensureKeyExists(encryptionKeyFile, c.dataPath)
+		if err != nil {
+			log.Fatalf("Failed to initialize encryption key: %v", err)
+		}
+		if err := os.Setenv(kek.EnvKey, encryptionKey); err != nil {
+			log.Fatalf("Failed to set encryption key environment variable: %v", err)
+		}
 	}
 
 	// Initialize session key with dataPath
@@ -756,7 +837,10 @@ func (c *serveCmd) run() error {
 	}
 
 	// Setup and start HTTP server
-	router := setupServer(c.queries, authService, c.configCMD.Views, c.dev, c.dbPath, c.dataPath)
+	router := setupServer(c.queries, authService, c.configCMD.Views, c.dev, c.dbPath, c.dataPath, &monitoring.CertificateExpiryConfig{
+		ScanInterval:  c.certExpiryInterval,
+		ThresholdDays: c.certExpiryThresholds,
+	})
 
 	// Start HTTP server in a goroutine
 	httpServer := &http.Server{
@@ -837,5 +921,9 @@ For example:
 	// Add development mode flag
 	cmd.Flags().BoolVar(&serveCmd.dev, "dev", false, "Run in development mode")
 
+	// Add certificate expiry scanner flags
+	cmd.Flags().DurationVar(&serveCmd.certExpiryInterval, "cert-expiry-interval", time.Hour, "Interval between certificate expiry scans")
+	cmd.Flags().IntSliceVar(&serveCmd.certExpiryThresholds, "cert-expiry-thresholds", []int{30, 7, 1}, "Days before expiry at which certificate expiry notifications are sent")
+
 	return cmd
 }
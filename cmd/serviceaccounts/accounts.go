@@ -0,0 +1,165 @@
+package serviceaccounts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/chainlaunch/chainlaunch/cmd/common"
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
+	"github.com/spf13/cobra"
+)
+
+type createCmd struct {
+	name string
+	role string
+}
+
+func (c *createCmd) validate() error {
+	if c.name == "" {
+		return fmt.Errorf("--name is required")
+	}
+	switch auth.Role(c.role) {
+	case auth.RoleAdmin, auth.RoleManager, auth.RoleViewer:
+	default:
+		return fmt.Errorf("--role must be one of admin, manager, viewer")
+	}
+	return nil
+}
+
+func (c *createCmd) run(out *os.File) error {
+	client, err := common.NewClientFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	account, err := client.CreateServiceAccount(&auth.CreateServiceAccountRequest{
+		Name: c.name,
+		Role: auth.Role(c.role),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Service account %s created with ID %d and role %s\n", account.Name, account.ID, account.Role)
+	return nil
+}
+
+// NewCreateCmd returns the create service account command
+func NewCreateCmd() *cobra.Command {
+	c := &createCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a service account",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVar(&c.name, "name", "", "Name of the service account")
+	cmd.Flags().StringVar(&c.role, "role", string(auth.RoleViewer), "Role of the service account (admin, manager, viewer)")
+
+	return cmd
+}
+
+type listCmd struct {
+	output string // "tsv" or "json"
+}
+
+func (c *listCmd) run(out *os.File) error {
+	client, err := common.NewClientFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	accounts, err := client.ListServiceAccounts()
+	if err != nil {
+		return err
+	}
+
+	switch c.output {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(accounts); err != nil {
+			return fmt.Errorf("failed to encode service accounts as JSON: %w", err)
+		}
+		return nil
+	case "tsv":
+		w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "ID\tName\tRole\tCreated")
+		fmt.Fprintln(w, "--\t----\t----\t-------")
+		for _, account := range accounts {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n",
+				account.ID,
+				account.Name,
+				account.Role,
+				account.CreatedAt.Format("2006-01-02 15:04:05"),
+			)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unsupported output type: %s (must be 'tsv' or 'json')", c.output)
+	}
+}
+
+// NewListCmd returns the list service accounts command
+func NewListCmd() *cobra.Command {
+	c := &listCmd{output: "tsv"}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List service accounts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.run(os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.output, "output", "o", "tsv", "Output format (tsv or json)")
+
+	return cmd
+}
+
+type deleteCmd struct {
+	accountID int64
+}
+
+func (c *deleteCmd) run(out *os.File) error {
+	client, err := common.NewClientFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if err := client.DeleteServiceAccount(c.accountID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Service account %d deleted, its API tokens no longer work\n", c.accountID)
+	return nil
+}
+
+// NewDeleteCmd returns the delete service account command
+func NewDeleteCmd() *cobra.Command {
+	c := &deleteCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "delete [account-id]",
+		Short: "Delete a service account and all of its API tokens",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "service account")
+			if err != nil {
+				return err
+			}
+			c.accountID = id
+			return c.run(os.Stdout)
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,34 @@
+// Package serviceaccounts provides the commands to manage service accounts and their API tokens.
+package serviceaccounts
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// NewServiceAccountsCmd returns the root command for service accounts
+func NewServiceAccountsCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "service-accounts",
+		Short: "Manage service accounts and API tokens",
+		Long: `Create, list and delete service accounts and the API tokens they authenticate with.
+
+API tokens are sent as Bearer tokens, set CHAINLAUNCH_API_TOKEN to use one with this CLI.`,
+	}
+
+	rootCmd.AddCommand(NewCreateCmd())
+	rootCmd.AddCommand(NewListCmd())
+	rootCmd.AddCommand(NewDeleteCmd())
+	rootCmd.AddCommand(NewTokensCmd())
+	return rootCmd
+}
+
+func parseID(s string, what string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s ID: %s", what, s)
+	}
+	return id, nil
+}
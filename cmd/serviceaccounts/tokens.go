@@ -0,0 +1,222 @@
+package serviceaccounts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/cmd/common"
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
+	"github.com/spf13/cobra"
+)
+
+// NewTokensCmd returns the command grouping the API token commands
+func NewTokensCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Manage the API tokens of service accounts",
+	}
+
+	cmd.AddCommand(newTokenCreateCmd())
+	cmd.AddCommand(newTokenListCmd())
+	cmd.AddCommand(newTokenRevokeCmd())
+	return cmd
+}
+
+type tokenCreateCmd struct {
+	accountID     int64
+	name          string
+	role          string
+	expiresInDays int
+}
+
+func (c *tokenCreateCmd) validate() error {
+	if c.name == "" {
+		return fmt.Errorf("--name is required")
+	}
+	switch auth.Role(c.role) {
+	case "", auth.RoleAdmin, auth.RoleManager, auth.RoleViewer:
+	default:
+		return fmt.Errorf("--role must be one of admin, manager, viewer")
+	}
+	if c.expiresInDays < 0 {
+		return fmt.Errorf("--expires-in-days cannot be negative")
+	}
+	return nil
+}
+
+func (c *tokenCreateCmd) run(out *os.File) error {
+	client, err := common.NewClientFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	token, err := client.CreateAPIToken(c.accountID, &auth.CreateAPITokenRequest{
+		Name:          c.name,
+		Role:          auth.Role(c.role),
+		ExpiresInDays: c.expiresInDays,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "API token %s created with ID %d and role %s\n", token.Name, token.ID, token.Role)
+	if token.ExpiresAt != nil {
+		fmt.Fprintf(out, "Expires at %s\n", token.ExpiresAt.Format(time.RFC3339))
+	}
+	fmt.Fprintln(out, "Store the token now, it cannot be retrieved again:")
+	fmt.Fprintln(out, token.Token)
+	return nil
+}
+
+func newTokenCreateCmd() *cobra.Command {
+	c := &tokenCreateCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "create [account-id]",
+		Short: "Create an API token for a service account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "service account")
+			if err != nil {
+				return err
+			}
+			c.accountID = id
+			if err := c.validate(); err != nil {
+				return err
+			}
+			return c.run(os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVar(&c.name, "name", "", "Name of the token, for instance the pipeline using it")
+	cmd.Flags().StringVar(&c.role, "role", "", "Role of the token, defaults to the role of the service account")
+	cmd.Flags().IntVar(&c.expiresInDays, "expires-in-days", 0, "Lifetime of the token in days, 0 never expires")
+
+	return cmd
+}
+
+type tokenListCmd struct {
+	accountID int64
+	output    string // "tsv" or "json"
+}
+
+func (c *tokenListCmd) run(out *os.File) error {
+	client, err := common.NewClientFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	tokens, err := client.ListAPITokens(c.accountID)
+	if err != nil {
+		return err
+	}
+
+	switch c.output {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(tokens); err != nil {
+			return fmt.Errorf("failed to encode API tokens as JSON: %w", err)
+		}
+		return nil
+	case "tsv":
+		w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "ID\tName\tPrefix\tRole\tStatus\tExpires\tLast Used")
+		fmt.Fprintln(w, "--\t----\t------\t----\t------\t-------\t---------")
+		for _, token := range tokens {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				token.ID,
+				token.Name,
+				token.TokenPrefix,
+				token.Role,
+				tokenStatus(token),
+				formatTime(token.ExpiresAt, "never"),
+				formatTime(token.LastUsedAt, "never"),
+			)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unsupported output type: %s (must be 'tsv' or 'json')", c.output)
+	}
+}
+
+func newTokenListCmd() *cobra.Command {
+	c := &tokenListCmd{output: "tsv"}
+
+	cmd := &cobra.Command{
+		Use:   "list [account-id]",
+		Short: "List the API tokens of a service account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "service account")
+			if err != nil {
+				return err
+			}
+			c.accountID = id
+			return c.run(os.Stdout)
+		},
+	}
+
+	cmd.Flags().StringVarP(&c.output, "output", "o", "tsv", "Output format (tsv or json)")
+
+	return cmd
+}
+
+type tokenRevokeCmd struct {
+	tokenID int64
+}
+
+func (c *tokenRevokeCmd) run(out *os.File) error {
+	client, err := common.NewClientFromEnv()
+	if err != nil {
+		return fmt.Errorf("failed to create client: %w", err)
+	}
+
+	if err := client.RevokeAPIToken(c.tokenID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "API token %d revoked\n", c.tokenID)
+	return nil
+}
+
+func newTokenRevokeCmd() *cobra.Command {
+	c := &tokenRevokeCmd{}
+
+	cmd := &cobra.Command{
+		Use:   "revoke [token-id]",
+		Short: "Revoke an API token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0], "API token")
+			if err != nil {
+				return err
+			}
+			c.tokenID = id
+			return c.run(os.Stdout)
+		},
+	}
+
+	return cmd
+}
+
+func tokenStatus(token auth.APIToken) string {
+	switch {
+	case token.RevokedAt != nil:
+		return "revoked"
+	case token.ExpiresAt != nil && time.Now().After(*token.ExpiresAt):
+		return "expired"
+	default:
+		return "active"
+	}
+}
+
+func formatTime(t *time.Time, fallback string) string {
+	if t == nil {
+		return fallback
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
@@ -21,11 +21,12 @@ func generateShortUUID() string {
 
 // BesuTestnetConfig holds the parameters for creating a Besu testnet
 type BesuTestnetConfig struct {
-	Name    string
-	Nodes   int
-	Prefix  string
-	Mode    string
-	Version string
+	Name      string
+	Nodes     int
+	Prefix    string
+	Mode      string
+	Version   string
+	Consensus string
 	// Initial account balances in wei (hex format)
 	InitialBalances map[string]string
 }
@@ -43,9 +44,15 @@ func (r *BesuTestnetRunner) Validate() error {
 	if r.Config.Nodes < 1 {
 		return fmt.Errorf("--nodes must be at least 1")
 	}
-	// For QBFT consensus, require at least 4 nodes
-	if r.Config.Nodes < 4 {
-		return fmt.Errorf("--nodes must be at least 4 for QBFT consensus")
+	switch r.Config.Consensus {
+	case "qbft", "ibft2":
+		// BFT consensus requires at least 4 nodes
+		if r.Config.Nodes < 4 {
+			return fmt.Errorf("--nodes must be at least 4 for %s consensus", r.Config.Consensus)
+		}
+	case "clique":
+	default:
+		return fmt.Errorf("--consensus must be one of 'qbft', 'ibft2' or 'clique'")
 	}
 	if r.Config.Mode != "docker" && r.Config.Mode != "service" {
 		return fmt.Errorf("--mode must be either 'docker' or 'service'")
@@ -100,7 +107,7 @@ func (r *BesuTestnetRunner) Run() error {
 		Name:        r.Config.Name,
 		Description: "",
 	}
-	netReq.Config.Consensus = "qbft"
+	netReq.Config.Consensus = r.Config.Consensus
 	netReq.Config.ChainID = 1337
 	netReq.Config.BlockPeriod = 5
 	netReq.Config.EpochLength = 30000
@@ -108,7 +115,11 @@ func (r *BesuTestnetRunner) Run() error {
 	netReq.Config.InitialValidatorKeyIds = keyIDs
 	netReq.Config.GasLimit = "0x29b92700" // 700000000 in hex
 	netReq.Config.Difficulty = "0x1"      // numberToHex(1)
-	netReq.Config.MixHash = "0x63746963616c2062797a616e74696e65206661756c7420746f6c6572616e6365"
+	if r.Config.Consensus == "clique" {
+		netReq.Config.MixHash = "0x0000000000000000000000000000000000000000000000000000000000000000"
+	} else {
+		netReq.Config.MixHash = "0x63746963616c2062797a616e74696e65206661756c7420746f6c6572616e6365"
+	}
 	netReq.Config.Coinbase = "0x0000000000000000000000000000000000000000"
 	netReq.Config.Timestamp = fmt.Sprintf("0x%x", time.Now().Unix()) // Current Unix timestamp in hex (seconds)
 	netReq.Config.Nonce = "0x0"                                      // numberToHex(0)
@@ -220,6 +231,7 @@ func NewBesuTestnetCmd() *cobra.Command {
 	cmd.Flags().StringVar(&runner.Config.Prefix, "prefix", "besu", "Prefix for node names")
 	cmd.Flags().StringVar(&runner.Config.Mode, "mode", "service", "Node mode (service or docker)")
 	cmd.Flags().StringVar(&runner.Config.Version, "version", "25.5.0", "Besu version (default 25.5.0)")
+	cmd.Flags().StringVar(&runner.Config.Consensus, "consensus", "qbft", "Consensus algorithm (qbft, ibft2 or clique)")
 	cmd.Flags().StringToStringVar(&runner.Config.InitialBalances, "initial-balance", map[string]string{}, "Initial account balances in wei (hex format), e.g. '0x1234...=0x1000000000000000000'")
 
 	return cmd
@@ -41,7 +41,7 @@ func isSecurityEvent(path string, method string) bool {
 		return true
 	}
 	// Authorization changes
-	if strings.Contains(path, "/permissions") || strings.Contains(path, "/roles") {
+	if strings.Contains(path, "/permissions") || strings.Contains(path, "/role") {
 		return true
 	}
 	// System configuration changes
@@ -90,6 +90,10 @@ func HTTPMiddleware(service *AuditService) func(http.Handler) http.Handler {
 			// Start timing the request with UTC timestamp
 			start := time.Now().UTC()
 
+			// Collect the authorization decisions taken by the handlers
+			ctx, recorder := auth.ContextWithAuthorizationRecorder(r.Context())
+			r = r.WithContext(ctx)
+
 			// Process the request
 			next.ServeHTTP(rw, r)
 
@@ -119,6 +123,10 @@ func HTTPMiddleware(service *AuditService) func(http.Handler) http.Handler {
 				}
 			}
 
+			if decisions := recorder.Decisions(); len(decisions) > 0 {
+				details["authorization"] = decisions
+			}
+
 			// Add response body for non-GET requests or error responses
 			if (r.Method != http.MethodGet || rw.statusCode >= 400) && len(rw.body) > 0 && len(rw.body) <= maxBodySize {
 				details["response_body"] = string(rw.body)
@@ -4,9 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
 	"github.com/chainlaunch/chainlaunch/pkg/db"
 	"github.com/google/uuid"
 )
@@ -243,3 +245,37 @@ func (s *AuditService) GetLog(ctx context.Context, id int64) (*Event, error) {
 		SessionID:        log.SessionID.String,
 	}, nil
 }
+
+// LogSecurityEvent records an authentication event such as a failed login, a
+// lockout or a two-factor authentication change
+func (s *AuditService) LogSecurityEvent(ctx context.Context, securityEvent auth.SecurityEvent) {
+	details := map[string]interface{}{
+		"username":          securityEvent.Username,
+		"is_security_event": true,
+	}
+	for k, v := range securityEvent.Details {
+		details[k] = v
+	}
+
+	event := NewEvent().WithDetails(details)
+	event.EventSource = "auth"
+	event.EventType = securityEvent.Type
+	event.UserIdentity = securityEvent.UserID
+	event.SourceIP = securityEvent.SourceIP
+	event.RequestID = uuid.New()
+	if securityEvent.UserID != 0 {
+		event.AffectedResource = fmt.Sprintf("user:%d", securityEvent.UserID)
+	}
+	if securityEvent.Success {
+		event.EventOutcome = EventOutcomeSuccess
+		event.Severity = SeverityInfo
+	} else {
+		event.EventOutcome = EventOutcomeFailure
+		event.Severity = SeverityWarning
+	}
+	if securityEvent.Type == auth.SecurityEventLoginLocked {
+		event.Severity = SeverityCritical
+	}
+
+	s.LogEventAsync(event)
+}
@@ -0,0 +1,273 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+)
+
+const (
+	// APITokenPrefix tells API tokens apart from session tokens in the Authorization header
+	APITokenPrefix = "clt_"
+	// apiTokenDisplayLength is the number of characters of a token kept to identify it
+	apiTokenDisplayLength = 12
+	// apiTokenLastUsedInterval limits how often the last use of a token is written
+	apiTokenLastUsedInterval = time.Minute
+)
+
+var (
+	// ErrServiceAccountNotFound is returned when a service account does not exist
+	ErrServiceAccountNotFound = errors.New("service account not found")
+	// ErrAPITokenNotFound is returned when an API token does not exist
+	ErrAPITokenNotFound = errors.New("API token not found")
+	// ErrInvalidAPIToken is returned when an API token is unknown, revoked or expired
+	ErrInvalidAPIToken = errors.New("invalid API token")
+)
+
+// roleRank orders the roles, a higher rank grants more permissions
+var roleRank = map[Role]int{
+	RoleViewer:  1,
+	RoleManager: 2,
+	RoleAdmin:   3,
+}
+
+// IsAPIToken returns true if the bearer token is an API token rather than a session token
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// hashAPIToken returns the hex encoded SHA-256 of a token
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateServiceAccount creates a user that cannot log in and only authenticates with API tokens
+func (s *AuthService) CreateServiceAccount(ctx context.Context, req *CreateServiceAccountRequest) (*ServiceAccount, error) {
+	if _, ok := roleRank[req.Role]; !ok {
+		return nil, fmt.Errorf("invalid role: %s", req.Role)
+	}
+
+	dbUser, err := s.db.CreateServiceAccount(ctx, &db.CreateServiceAccountParams{
+		Username: req.Name,
+		Role:     sql.NullString{String: string(req.Role), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	return toServiceAccount(dbUser), nil
+}
+
+// ListServiceAccounts returns all service accounts
+func (s *AuthService) ListServiceAccounts(ctx context.Context) ([]*ServiceAccount, error) {
+	dbUsers, err := s.db.ListServiceAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service accounts: %w", err)
+	}
+
+	accounts := make([]*ServiceAccount, len(dbUsers))
+	for i, dbUser := range dbUsers {
+		accounts[i] = toServiceAccount(dbUser)
+	}
+	return accounts, nil
+}
+
+// DeleteServiceAccount deletes a service account and all of its API tokens
+func (s *AuthService) DeleteServiceAccount(ctx context.Context, id int64) error {
+	if _, err := s.getServiceAccount(ctx, id); err != nil {
+		return err
+	}
+	if err := s.db.DeleteUser(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete service account: %w", err)
+	}
+	return nil
+}
+
+// CreateAPIToken creates an API token for a service account. The returned
+// token is the only time the secret is available, only its hash is stored.
+func (s *AuthService) CreateAPIToken(ctx context.Context, accountID int64, createdBy int64, req *CreateAPITokenRequest) (*APIToken, string, error) {
+	account, err := s.getServiceAccount(ctx, accountID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = Role(account.Role.String)
+	}
+	rank, ok := roleRank[role]
+	if !ok {
+		return nil, "", fmt.Errorf("invalid role: %s", role)
+	}
+	if rank > roleRank[Role(account.Role.String)] {
+		return nil, "", fmt.Errorf("token role %s is above the role of the service account", role)
+	}
+	if req.ExpiresInDays < 0 {
+		return nil, "", fmt.Errorf("expires_in_days cannot be negative")
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := APITokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	var expiresAt sql.NullTime
+	if req.ExpiresInDays > 0 {
+		expiresAt = sql.NullTime{Time: time.Now().AddDate(0, 0, req.ExpiresInDays), Valid: true}
+	}
+
+	dbToken, err := s.db.CreateAPIToken(ctx, &db.CreateAPITokenParams{
+		UserID:      account.ID,
+		Name:        req.Name,
+		TokenPrefix: token[:apiTokenDisplayLength],
+		TokenHash:   hashAPIToken(token),
+		Role:        string(role),
+		ExpiresAt:   expiresAt,
+		CreatedBy:   sql.NullInt64{Int64: createdBy, Valid: createdBy != 0},
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create API token: %w", err)
+	}
+
+	return toAPIToken(dbToken), token, nil
+}
+
+// ListAPITokens returns the API tokens of a service account, including revoked ones
+func (s *AuthService) ListAPITokens(ctx context.Context, accountID int64) ([]*APIToken, error) {
+	if _, err := s.getServiceAccount(ctx, accountID); err != nil {
+		return nil, err
+	}
+
+	dbTokens, err := s.db.ListAPITokensByUser(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+
+	tokens := make([]*APIToken, len(dbTokens))
+	for i, dbToken := range dbTokens {
+		tokens[i] = toAPIToken(dbToken)
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken revokes an API token, revoking an already revoked token is a no-op
+func (s *AuthService) RevokeAPIToken(ctx context.Context, id int64) error {
+	if _, err := s.db.GetAPIToken(ctx, id); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrAPITokenNotFound
+		}
+		return fmt.Errorf("failed to get API token: %w", err)
+	}
+	if err := s.db.RevokeAPIToken(ctx, id); err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	return nil
+}
+
+// ValidateAPIToken validates an API token and returns its service account
+// with the role of the token
+func (s *AuthService) ValidateAPIToken(ctx context.Context, token string, clientIP string) (*User, error) {
+	dbToken, err := s.db.GetAPITokenByHash(ctx, hashAPIToken(token))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidAPIToken
+		}
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	now := time.Now()
+	if dbToken.RevokedAt.Valid {
+		return nil, fmt.Errorf("%w: token revoked", ErrInvalidAPIToken)
+	}
+	if dbToken.ExpiresAt.Valid && now.After(dbToken.ExpiresAt.Time) {
+		return nil, fmt.Errorf("%w: token expired", ErrInvalidAPIToken)
+	}
+
+	user, err := s.db.GetUser(ctx, dbToken.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// A token never grants more than its account, even if the account was downgraded
+	role := Role(dbToken.Role)
+	var maxRole Role
+	if roleRank[role] > roleRank[Role(user.Role.String)] {
+		role = Role(user.Role.String)
+	} else if roleRank[role] < roleRank[Role(user.Role.String)] {
+		maxRole = role
+	}
+
+	// Writing on every request would turn reads into writes, a minute is precise enough
+	if !dbToken.LastUsedAt.Valid || now.Sub(dbToken.LastUsedAt.Time) > apiTokenLastUsedInterval {
+		if err := s.db.UpdateAPITokenLastUsed(ctx, &db.UpdateAPITokenLastUsedParams{
+			LastUsedIp: sql.NullString{String: clientIP, Valid: clientIP != ""},
+			ID:         dbToken.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to update API token last use: %w", err)
+		}
+	}
+
+	return &User{
+		ID:       user.ID,
+		Username: user.Username,
+		Role:     role,
+		MaxRole:  maxRole,
+	}, nil
+}
+
+// getServiceAccount returns the user with the given ID if it is a service account
+func (s *AuthService) getServiceAccount(ctx context.Context, id int64) (*db.User, error) {
+	user, err := s.db.GetUser(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrServiceAccountNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !user.ServiceAccount {
+		return nil, ErrServiceAccountNotFound
+	}
+	return user, nil
+}
+
+func toServiceAccount(user *db.User) *ServiceAccount {
+	return &ServiceAccount{
+		ID:        user.ID,
+		Name:      user.Username,
+		Role:      Role(user.Role.String),
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+func toAPIToken(token *db.ApiToken) *APIToken {
+	t := &APIToken{
+		ID:          token.ID,
+		UserID:      token.UserID,
+		Name:        token.Name,
+		TokenPrefix: token.TokenPrefix,
+		Role:        Role(token.Role),
+		LastUsedIP:  token.LastUsedIp.String,
+		CreatedAt:   token.CreatedAt,
+	}
+	if token.ExpiresAt.Valid {
+		t.ExpiresAt = &token.ExpiresAt.Time
+	}
+	if token.LastUsedAt.Valid {
+		t.LastUsedAt = &token.LastUsedAt.Time
+	}
+	if token.RevokedAt.Valid {
+		t.RevokedAt = &token.RevokedAt.Time
+	}
+	return t
+}
@@ -7,7 +7,9 @@ import (
 type contextKey string
 
 const (
-	userContextKey contextKey = "user"
+	userContextKey        contextKey = "user"
+	authServiceContextKey contextKey = "auth_service"
+	recorderContextKey    contextKey = "authorization_recorder"
 )
 
 // UserFromContext retrieves the user from the context
@@ -25,6 +25,18 @@ func NewHandler(authService *AuthService) *Handler {
 	}
 }
 
+// RegisterPublicRoutes registers the login routes, they don't require
+// authentication and are rate limited per client IP
+func (h *Handler) RegisterPublicRoutes(r chi.Router) {
+	r.Route("/auth/login", func(r chi.Router) {
+		r.Use(h.LoginRateLimitMiddleware)
+		r.Post("/", response.Middleware(h.LoginHandler))
+		r.Post("/totp", response.Middleware(h.LoginTOTPHandler))
+		r.Post("/totp/enroll", response.Middleware(h.LoginEnrollTOTPHandler))
+		r.Post("/totp/enroll/confirm", response.Middleware(h.LoginConfirmTOTPHandler))
+	})
+}
+
 // RegisterRoutes registers all authentication and user management routes
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	// Auth routes
@@ -32,6 +44,12 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Post("/logout", response.Middleware(h.LogoutHandler))
 		r.Get("/me", response.Middleware(h.GetCurrentUserHandler))
 		r.Post("/change-password", response.Middleware(h.ChangePasswordHandler))
+		r.Get("/permissions", response.Middleware(h.ListMyRoleBindingsHandler))
+		r.Get("/totp", response.Middleware(h.GetTOTPStatusHandler))
+		r.Post("/totp/enroll", response.Middleware(h.EnrollTOTPHandler))
+		r.Post("/totp/enable", response.Middleware(h.EnableTOTPHandler))
+		r.Post("/totp/disable", response.Middleware(h.DisableTOTPHandler))
+		r.Post("/totp/recovery-codes", response.Middleware(h.RegenerateRecoveryCodesHandler))
 	})
 
 	// User management routes
@@ -43,19 +61,35 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Delete("/{id}", response.Middleware(h.DeleteUserHandler))
 		r.Put("/{id}/password", response.Middleware(h.UpdateUserPasswordHandler))
 		r.Put("/{id}/role", response.Middleware(h.UpdateUserRoleHandler))
+		r.Get("/{id}/permissions", response.Middleware(h.ListRoleBindingsHandler))
+		r.Post("/{id}/permissions", response.Middleware(h.CreateRoleBindingHandler))
+		r.Delete("/{id}/permissions/{permissionId}", response.Middleware(h.DeleteRoleBindingHandler))
+		r.Delete("/{id}/totp", response.Middleware(h.ResetTOTPHandler))
+		r.Post("/{id}/unlock", response.Middleware(h.UnlockUserHandler))
+	})
+
+	// Service account and API token routes
+	r.Route("/service-accounts", func(r chi.Router) {
+		r.Get("/", response.Middleware(h.ListServiceAccountsHandler))
+		r.Post("/", response.Middleware(h.CreateServiceAccountHandler))
+		r.Delete("/{id}", response.Middleware(h.DeleteServiceAccountHandler))
+		r.Get("/{id}/tokens", response.Middleware(h.ListAPITokensHandler))
+		r.Post("/{id}/tokens", response.Middleware(h.CreateAPITokenHandler))
 	})
+	r.Delete("/api-tokens/{id}", response.Middleware(h.RevokeAPITokenHandler))
 }
 
 // @Summary Login user
-// @Description Authenticates a user and returns a session cookie
+// @Description Authenticates a user and returns a session cookie. Users with two-factor authentication get a challenge to complete with /auth/login/totp instead.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param credentials body LoginRequest true "Login credentials"
-// @Success 200 {object} LoginResponse "Login successful"
+// @Success 200 {object} LoginResponse "Login successful or second factor required"
 // @Failure 400 {object} response.Response "Invalid request body"
 // @Failure 401 {object} response.Response "Invalid credentials"
 // @Failure 405 {object} response.Response "Method not allowed"
+// @Failure 429 {object} response.Response "Too many login attempts"
 // @Router /auth/login [post]
 // @BasePath /api/v1
 func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) error {
@@ -69,26 +103,24 @@ func (h *Handler) LoginHandler(w http.ResponseWriter, r *http.Request) error {
 	}
 
 	ctx := r.Context()
-	session, err := h.authService.Login(ctx, req.Username, req.Password)
+	result, err := h.authService.BeginLogin(ctx, req.Username, req.Password, h.authService.clientIP(r))
 	if err != nil {
 		log.Printf("Error logging in: %v", err)
-		return errors.NewValidationError("invalid credentials", nil)
+		return loginError(err)
 	}
-
-	// Sign the session ID
-	signature := signSessionID(session.ID)
-	signedSessionID := session.ID + "." + signature
-
-	// Set session cookie
-	http.SetCookie(w, &http.Cookie{
-		Name:     SessionCookieName,
-		Value:    signedSessionID,
-		Path:     "/",
-		Expires:  session.ExpiresAt,
-		HttpOnly: true,
-		Secure:   r.TLS != nil,
-		SameSite: http.SameSiteStrictMode,
-	})
+	if result.Session == nil {
+		message := "Two-factor authentication required"
+		if result.EnrollmentRequired {
+			message = "Two-factor authentication enrollment required"
+		}
+		return response.WriteJSON(w, http.StatusOK, LoginResponse{
+			Message:                message,
+			TOTPRequired:           !result.EnrollmentRequired,
+			TOTPEnrollmentRequired: result.EnrollmentRequired,
+			Challenge:              result.Challenge,
+		})
+	}
+	setSessionCookie(w, r, result.Session)
 
 	return response.WriteJSON(w, http.StatusOK, LoginResponse{
 		Message: "Login successful",
@@ -588,3 +620,223 @@ func (h *Handler) ChangePasswordHandler(w http.ResponseWriter, r *http.Request)
 		"message": "Password changed successfully",
 	})
 }
+
+// @Summary List service accounts
+// @Description Returns all service accounts (admin only)
+// @Tags Service Accounts
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {array} ServiceAccount "List of service accounts"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
+// @Router /service-accounts [get]
+// @BasePath /api/v1
+func (h *Handler) ListServiceAccountsHandler(w http.ResponseWriter, r *http.Request) error {
+	if _, err := requireAdmin(r); err != nil {
+		return err
+	}
+
+	accounts, err := h.authService.ListServiceAccounts(r.Context())
+	if err != nil {
+		return err
+	}
+
+	return response.WriteJSON(w, http.StatusOK, accounts)
+}
+
+// @Summary Create service account
+// @Description Creates a service account, which cannot log in and authenticates with API tokens (admin only)
+// @Tags Service Accounts
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param account body CreateServiceAccountRequest true "Service account to create"
+// @Success 201 {object} ServiceAccount "Service account created"
+// @Failure 400 {object} response.Response "Invalid request body"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
+// @Router /service-accounts [post]
+// @BasePath /api/v1
+func (h *Handler) CreateServiceAccountHandler(w http.ResponseWriter, r *http.Request) error {
+	if _, err := requireAdmin(r); err != nil {
+		return err
+	}
+
+	var req CreateServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", nil)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.NewValidationError("name is required", nil)
+	}
+	if _, ok := roleRank[req.Role]; !ok {
+		return errors.NewValidationError("role must be one of admin, manager, viewer", nil)
+	}
+
+	account, err := h.authService.CreateServiceAccount(r.Context(), &req)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return errors.NewConflictError("a user or service account with this name already exists", nil)
+		}
+		return err
+	}
+
+	return response.WriteJSON(w, http.StatusCreated, account)
+}
+
+// @Summary Delete service account
+// @Description Deletes a service account and revokes all of its API tokens (admin only)
+// @Tags Service Accounts
+// @Security CookieAuth
+// @Param id path int true "Service account ID"
+// @Success 204 "Service account deleted"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
+// @Failure 404 {object} response.Response "Service account not found"
+// @Router /service-accounts/{id} [delete]
+// @BasePath /api/v1
+func (h *Handler) DeleteServiceAccountHandler(w http.ResponseWriter, r *http.Request) error {
+	if _, err := requireAdmin(r); err != nil {
+		return err
+	}
+
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid service account ID", nil)
+	}
+
+	if err := h.authService.DeleteServiceAccount(r.Context(), accountID); err != nil {
+		if err == ErrServiceAccountNotFound {
+			return errors.NewNotFoundError("service account not found", nil)
+		}
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// @Summary List API tokens
+// @Description Returns the API tokens of a service account, including revoked ones (admin only)
+// @Tags Service Accounts
+// @Produce json
+// @Security CookieAuth
+// @Param id path int true "Service account ID"
+// @Success 200 {array} APIToken "List of API tokens"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
+// @Failure 404 {object} response.Response "Service account not found"
+// @Router /service-accounts/{id}/tokens [get]
+// @BasePath /api/v1
+func (h *Handler) ListAPITokensHandler(w http.ResponseWriter, r *http.Request) error {
+	if _, err := requireAdmin(r); err != nil {
+		return err
+	}
+
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid service account ID", nil)
+	}
+
+	tokens, err := h.authService.ListAPITokens(r.Context(), accountID)
+	if err != nil {
+		if err == ErrServiceAccountNotFound {
+			return errors.NewNotFoundError("service account not found", nil)
+		}
+		return err
+	}
+
+	return response.WriteJSON(w, http.StatusOK, tokens)
+}
+
+// @Summary Create API token
+// @Description Creates an API token for a service account, the token is only returned once (admin only)
+// @Tags Service Accounts
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param id path int true "Service account ID"
+// @Param token body CreateAPITokenRequest true "API token to create"
+// @Success 201 {object} CreateAPITokenResponse "API token created"
+// @Failure 400 {object} response.Response "Invalid request body"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
+// @Failure 404 {object} response.Response "Service account not found"
+// @Router /service-accounts/{id}/tokens [post]
+// @BasePath /api/v1
+func (h *Handler) CreateAPITokenHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := requireAdmin(r)
+	if err != nil {
+		return err
+	}
+
+	accountID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid service account ID", nil)
+	}
+
+	var req CreateAPITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", nil)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.NewValidationError("name is required", nil)
+	}
+
+	apiToken, token, err := h.authService.CreateAPIToken(r.Context(), accountID, session.UserID, &req)
+	if err != nil {
+		if err == ErrServiceAccountNotFound {
+			return errors.NewNotFoundError("service account not found", nil)
+		}
+		return errors.NewValidationError(err.Error(), nil)
+	}
+
+	return response.WriteJSON(w, http.StatusCreated, CreateAPITokenResponse{
+		APIToken: *apiToken,
+		Token:    token,
+	})
+}
+
+// @Summary Revoke API token
+// @Description Revokes an API token, requests using it are rejected from then on (admin only)
+// @Tags Service Accounts
+// @Security CookieAuth
+// @Param id path int true "API token ID"
+// @Success 204 "API token revoked"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
+// @Failure 404 {object} response.Response "API token not found"
+// @Router /api-tokens/{id} [delete]
+// @BasePath /api/v1
+func (h *Handler) RevokeAPITokenHandler(w http.ResponseWriter, r *http.Request) error {
+	if _, err := requireAdmin(r); err != nil {
+		return err
+	}
+
+	tokenID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid API token ID", nil)
+	}
+
+	if err := h.authService.RevokeAPIToken(r.Context(), tokenID); err != nil {
+		if err == ErrAPITokenNotFound {
+			return errors.NewNotFoundError("API token not found", nil)
+		}
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// requireAdmin returns the session of the request if it belongs to an admin
+func requireAdmin(r *http.Request) (*Session, error) {
+	session, ok := SessionFromContext(r.Context())
+	if !ok {
+		return nil, errors.NewValidationError("unauthorized", nil)
+	}
+	if session.Role != RoleAdmin {
+		return nil, errors.NewValidationError("forbidden - requires admin role", nil)
+	}
+	return session, nil
+}
@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/http/response"
+)
+
+const (
+	// maxFailedLogins is the number of failed logins after which a username is locked
+	maxFailedLogins = 5
+	// failedLoginWindow is the period in which failed logins are counted
+	failedLoginWindow = 15 * time.Minute
+	// loginLockoutDuration is how long a username stays locked
+	loginLockoutDuration = 15 * time.Minute
+	// loginRateLimit is the number of login requests allowed per client IP and loginRateWindow
+	loginRateLimit = 20
+	// loginRateWindow is the period of loginRateLimit
+	loginRateWindow = time.Minute
+)
+
+// Security event types recorded in the audit log
+const (
+	SecurityEventLoginFailed      = "LOGIN_FAILED"
+	SecurityEventLoginLocked      = "LOGIN_LOCKED"
+	SecurityEventLoginUnlocked    = "LOGIN_UNLOCKED"
+	SecurityEventLoginRateLimited = "LOGIN_RATE_LIMITED"
+	SecurityEventTOTPFailed       = "TOTP_FAILED"
+	SecurityEventTOTPEnabled      = "TOTP_ENABLED"
+	SecurityEventTOTPDisabled     = "TOTP_DISABLED"
+	SecurityEventTOTPReset        = "TOTP_RESET"
+	SecurityEventRecoveryCodeUsed = "RECOVERY_CODE_USED"
+)
+
+var (
+	// ErrInvalidCredentials is returned when the username, password or second factor is wrong
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrLoginLocked is returned when a username is locked after too many failed logins
+	ErrLoginLocked = errors.New("too many failed login attempts, try again later")
+)
+
+// SecurityEvent is an authentication event worth keeping in the audit log
+type SecurityEvent struct {
+	Type     string
+	UserID   int64
+	Username string
+	SourceIP string
+	Success  bool
+	Details  map[string]interface{}
+}
+
+// SecurityEventLogger records security events, it is implemented by the audit service
+type SecurityEventLogger interface {
+	LogSecurityEvent(ctx context.Context, event SecurityEvent)
+}
+
+// SetSecurityEventLogger sets where login failures, lockouts and two-factor changes are recorded
+func (s *AuthService) SetSecurityEventLogger(logger SecurityEventLogger) {
+	s.securityEvents = logger
+}
+
+func (s *AuthService) logSecurityEvent(ctx context.Context, event SecurityEvent) {
+	if s.securityEvents == nil {
+		return
+	}
+	s.securityEvents.LogSecurityEvent(ctx, event)
+}
+
+// checkLoginLocked returns ErrLoginLocked if the username is locked
+func (s *AuthService) checkLoginLocked(ctx context.Context, username string) error {
+	failure, err := s.db.GetLoginFailure(ctx, username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return fmt.Errorf("failed to get login failures: %w", err)
+	}
+	if failure.LockedUntil.Valid && time.Now().Before(failure.LockedUntil.Time) {
+		return ErrLoginLocked
+	}
+	return nil
+}
+
+// recordLoginFailure counts a failed login and locks the username once
+// maxFailedLogins is reached within failedLoginWindow
+func (s *AuthService) recordLoginFailure(ctx context.Context, eventType string, userID int64, username string, clientIP string) {
+	now := time.Now()
+	failure, err := s.db.RecordLoginFailure(ctx, &db.RecordLoginFailureParams{
+		Username:    username,
+		WindowStart: now.Add(-failedLoginWindow),
+	})
+	if err != nil {
+		log.Printf("Error recording failed login for %s: %v", username, err)
+		return
+	}
+
+	s.logSecurityEvent(ctx, SecurityEvent{
+		Type:     eventType,
+		UserID:   userID,
+		Username: username,
+		SourceIP: clientIP,
+		Details:  map[string]interface{}{"failed_attempts": failure.FailedAttempts},
+	})
+
+	if failure.FailedAttempts < maxFailedLogins {
+		return
+	}
+	lockedUntil := now.Add(loginLockoutDuration)
+	if err := s.db.LockLogin(ctx, &db.LockLoginParams{
+		LockedUntil: sql.NullTime{Time: lockedUntil, Valid: true},
+		Username:    username,
+	}); err != nil {
+		log.Printf("Error locking login for %s: %v", username, err)
+		return
+	}
+	s.logSecurityEvent(ctx, SecurityEvent{
+		Type:     SecurityEventLoginLocked,
+		UserID:   userID,
+		Username: username,
+		SourceIP: clientIP,
+		Details: map[string]interface{}{
+			"failed_attempts": failure.FailedAttempts,
+			"locked_until":    lockedUntil.UTC().Format(time.RFC3339),
+		},
+	})
+}
+
+// clearLoginFailures forgets the failed logins of a username after a successful login
+func (s *AuthService) clearLoginFailures(ctx context.Context, username string) {
+	if err := s.db.DeleteLoginFailure(ctx, username); err != nil {
+		log.Printf("Error clearing failed logins for %s: %v", username, err)
+	}
+}
+
+// UnlockUser lifts the lockout of a user before it expires
+func (s *AuthService) UnlockUser(ctx context.Context, userID int64, unlockedBy int64) error {
+	user, err := s.db.GetUser(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.db.DeleteLoginFailure(ctx, user.Username); err != nil {
+		return fmt.Errorf("failed to unlock user: %w", err)
+	}
+	s.logSecurityEvent(ctx, SecurityEvent{
+		Type:     SecurityEventLoginUnlocked,
+		UserID:   user.ID,
+		Username: user.Username,
+		Success:  true,
+		Details:  map[string]interface{}{"unlocked_by": unlockedBy},
+	})
+	return nil
+}
+
+// loginRateLimiter limits login requests per client IP with a sliding window
+type loginRateLimiter struct {
+	mu          sync.Mutex
+	limit       int
+	window      time.Duration
+	requests    map[string][]time.Time
+	lastCleanup time.Time
+}
+
+func newLoginRateLimiter(limit int, window time.Duration) *loginRateLimiter {
+	return &loginRateLimiter{
+		limit:    limit,
+		window:   window,
+		requests: make(map[string][]time.Time),
+	}
+}
+
+// allow records a request and returns false, with the time to wait, if the client is over the limit
+func (l *loginRateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cutoff := now.Add(-l.window)
+	if now.Sub(l.lastCleanup) > l.window {
+		for k, times := range l.requests {
+			if len(times) == 0 || times[len(times)-1].Before(cutoff) {
+				delete(l.requests, k)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	times := l.requests[key]
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	times = times[i:]
+	if len(times) >= l.limit {
+		l.requests[key] = times
+		return false, times[0].Add(l.window).Sub(now)
+	}
+	l.requests[key] = append(times, now)
+	return true, 0
+}
+
+// allowLogin counts a login request of the client IP and, when it is above the
+// login rate limit, writes a 429 response and returns false
+func (s *AuthService) allowLogin(w http.ResponseWriter, r *http.Request) bool {
+	ip := s.clientIP(r)
+	ok, retryAfter := s.loginLimiter.allow(ip, time.Now())
+	if ok {
+		return true
+	}
+	s.logSecurityEvent(r.Context(), SecurityEvent{
+		Type:     SecurityEventLoginRateLimited,
+		SourceIP: ip,
+		Details:  map[string]interface{}{"path": r.URL.Path},
+	})
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+	response.WriteJSON(w, http.StatusTooManyRequests, response.Response{
+		Message: "too many login attempts, try again later",
+	})
+	return false
+}
+
+// LoginRateLimitMiddleware rejects login requests from a client IP above the login rate limit
+func (h *Handler) LoginRateLimitMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !h.authService.allowLogin(w, r) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
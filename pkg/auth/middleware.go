@@ -4,6 +4,8 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
+	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -11,6 +13,10 @@ import (
 
 const (
 	SessionCookieName = "session_id"
+
+	// EnvTrustedProxies lists the reverse proxies, as IPs or CIDRs separated by
+	// commas, whose X-Forwarded-For header gives the client address
+	EnvTrustedProxies = "CHAINLAUNCH_TRUSTED_PROXIES"
 )
 
 // getEncryptionKey returns the session encryption key from environment variable
@@ -57,6 +63,80 @@ func parseBasicAuth(r *http.Request) (username, password string, ok bool) {
 	return pair[0], pair[1], true
 }
 
+// TrustedProxiesFromEnv reads the trusted reverse proxies from the environment
+func TrustedProxiesFromEnv() ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+	for _, entry := range strings.Split(os.Getenv(EnvTrustedProxies), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid %s entry %q", EnvTrustedProxies, entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", EnvTrustedProxies, entry, err)
+		}
+		proxies = append(proxies, ipNet)
+	}
+	return proxies, nil
+}
+
+// SetTrustedProxies sets the reverse proxies whose X-Forwarded-For header is honored
+func (s *AuthService) SetTrustedProxies(proxies []*net.IPNet) {
+	s.trustedProxies = proxies
+}
+
+func (s *AuthService) isTrustedProxy(ip net.IP) bool {
+	for _, proxy := range s.trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address of the client. X-Forwarded-For can be set by
+// anyone, so it is only honored when the request comes from a trusted proxy,
+// and then the client is the last address not added by a trusted proxy.
+func (s *AuthService) clientIP(r *http.Request) string {
+	remote := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remote = host
+	}
+	remoteIP := net.ParseIP(remote)
+	if remoteIP == nil || !s.isTrustedProxy(remoteIP) {
+		return remote
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		client = hop.String()
+		if !s.isTrustedProxy(hop) {
+			break
+		}
+	}
+	return client
+}
+
 // GetSessionID extracts and validates the session ID from the request
 // Returns the session ID if valid, empty string otherwise
 func GetSessionID(r *http.Request) string {
@@ -99,9 +179,12 @@ func AuthMiddleware(authService *AuthService) func(http.Handler) http.Handler {
 			var user *User
 			var err error
 
-			// First try Basic Auth
+			// First try Basic Auth, every request is a login so it shares the login rate limit
 			if username, password, ok := parseBasicAuth(r); ok {
-				session, err := authService.Login(r.Context(), username, password)
+				if !authService.allowLogin(w, r) {
+					return
+				}
+				session, err := authService.Login(r.Context(), username, password, authService.clientIP(r))
 				if err != nil {
 					http.Error(w, "Invalid credentials", http.StatusUnauthorized)
 					return
@@ -149,7 +232,13 @@ func AuthMiddleware(authService *AuthService) func(http.Handler) http.Handler {
 				}
 
 				// Validate session for both Bearer token and Cookie auth
-				if token != "" {
+				if IsAPIToken(token) {
+					user, err = authService.ValidateAPIToken(r.Context(), token, authService.clientIP(r))
+					if err != nil {
+						http.Error(w, "Invalid, revoked or expired API token", http.StatusUnauthorized)
+						return
+					}
+				} else if token != "" {
 					user, err = authService.ValidateSessionByToken(r.Context(), token)
 					if err != nil {
 						http.Error(w, "Invalid or expired session", http.StatusUnauthorized)
@@ -170,8 +259,9 @@ func AuthMiddleware(authService *AuthService) func(http.Handler) http.Handler {
 				return
 			}
 
-			// Add user to context
+			// Add user to context, with the service resolving its role bindings
 			ctx := ContextWithUser(r.Context(), user)
+			ctx = contextWithAuthService(ctx, authService)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+)
+
+func newTestQueries(t *testing.T) *db.Queries {
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	driver, err := sqlite3.WithInstance(database, &sqlite3.Config{})
+	if err != nil {
+		t.Fatalf("failed to create sqlite driver: %v", err)
+	}
+	source, err := iofs.New(os.DirFS("../db/migrations"), ".")
+	if err != nil {
+		t.Fatalf("failed to open migrations: %v", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", driver)
+	if err != nil {
+		t.Fatalf("failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return db.New(database)
+}
+
+func TestTrustedProxiesFromEnv(t *testing.T) {
+	t.Setenv(EnvTrustedProxies, "10.0.0.1, 192.168.0.0/16,::1")
+	proxies, err := TrustedProxiesFromEnv()
+	if err != nil {
+		t.Fatalf("failed to parse trusted proxies: %v", err)
+	}
+	if len(proxies) != 3 {
+		t.Fatalf("expected 3 trusted proxies, got %d", len(proxies))
+	}
+
+	t.Setenv(EnvTrustedProxies, "proxy.local")
+	if _, err := TrustedProxiesFromEnv(); err == nil {
+		t.Fatal("expected an error for a host name")
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	t.Setenv(EnvTrustedProxies, "10.0.0.0/8")
+	proxies, err := TrustedProxiesFromEnv()
+	if err != nil {
+		t.Fatalf("failed to parse trusted proxies: %v", err)
+	}
+	s := NewAuthService(nil)
+	s.SetTrustedProxies(proxies)
+
+	cases := []struct {
+		name      string
+		remote    string
+		forwarded string
+		expected  string
+	}{
+		{"direct client", "203.0.113.7:5000", "", "203.0.113.7"},
+		{"spoofed header from a client", "203.0.113.7:5000", "198.51.100.1", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:443", "198.51.100.1", "198.51.100.1"},
+		{"spoofed entry before the proxy", "10.0.0.2:443", "1.2.3.4, 198.51.100.1", "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.2:443", "198.51.100.1, 10.0.0.3", "198.51.100.1"},
+		{"trusted proxy without header", "10.0.0.2:443", "", "10.0.0.2"},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = c.remote
+		if c.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", c.forwarded)
+		}
+		if ip := s.clientIP(r); ip != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.name, c.expected, ip)
+		}
+	}
+}
+
+func TestBasicAuthRateLimit(t *testing.T) {
+	s := NewAuthService(newTestQueries(t))
+	handler := AuthMiddleware(s)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(remote, forwarded string) int {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/nodes", nil)
+		r.RemoteAddr = remote
+		r.Header.Set("X-Forwarded-For", forwarded)
+		r.SetBasicAuth("admin", "wrong-password")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	for i := 0; i < loginRateLimit; i++ {
+		// A new X-Forwarded-For value doesn't give a client a new allowance
+		if code := request("203.0.113.7:5000", "198.51.100."+strconv.Itoa(i)); code != http.StatusUnauthorized {
+			t.Fatalf("request %d: expected 401, got %d", i+1, code)
+		}
+	}
+	if code := request("203.0.113.7:5000", "198.51.100.200"); code != http.StatusTooManyRequests {
+		t.Fatalf("expected basic auth to be rate limited, got %d", code)
+	}
+	if code := request("203.0.113.8:5000", ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected another client not to be limited, got %d", code)
+	}
+}
@@ -0,0 +1,537 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// Environment variables configuring OpenID Connect single sign-on
+const (
+	EnvOIDCIssuer        = "CHAINLAUNCH_OIDC_ISSUER"
+	EnvOIDCClientID      = "CHAINLAUNCH_OIDC_CLIENT_ID"
+	EnvOIDCClientSecret  = "CHAINLAUNCH_OIDC_CLIENT_SECRET"
+	EnvOIDCRedirectURL   = "CHAINLAUNCH_OIDC_REDIRECT_URL"
+	EnvOIDCProviderName  = "CHAINLAUNCH_OIDC_PROVIDER_NAME"
+	EnvOIDCScopes        = "CHAINLAUNCH_OIDC_SCOPES"
+	EnvOIDCUsernameClaim = "CHAINLAUNCH_OIDC_USERNAME_CLAIM"
+	EnvOIDCGroupsClaim   = "CHAINLAUNCH_OIDC_GROUPS_CLAIM"
+	EnvOIDCAdminGroups   = "CHAINLAUNCH_OIDC_ADMIN_GROUPS"
+	EnvOIDCManagerGroups = "CHAINLAUNCH_OIDC_MANAGER_GROUPS"
+	EnvOIDCViewerGroups  = "CHAINLAUNCH_OIDC_VIEWER_GROUPS"
+	EnvOIDCDefaultRole   = "CHAINLAUNCH_OIDC_DEFAULT_ROLE"
+)
+
+// oidcKeysRefreshInterval limits how often the signing keys are fetched again
+// when an ID token is signed with an unknown key
+const oidcKeysRefreshInterval = time.Minute
+
+// OIDCConfig represents the configuration of an OpenID Connect identity provider
+type OIDCConfig struct {
+	// ProviderName identifies the identity provider, it is stored as the provider of the users it creates
+	ProviderName string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the URL of the callback endpoint, /api/v1/auth/oidc/callback
+	RedirectURL string
+	Scopes      []string
+	// UsernameClaim is the claim used as username, falling back to email and then sub
+	UsernameClaim string
+	// GroupsClaim is the claim holding the groups of the user, nested claims use dots
+	// such as realm_access.roles for Keycloak realm roles
+	GroupsClaim   string
+	AdminGroups   []string
+	ManagerGroups []string
+	ViewerGroups  []string
+	// DefaultRole is the role of users in none of the groups, empty denies them access
+	DefaultRole Role
+}
+
+// OIDCConfigFromEnv reads the OpenID Connect configuration from the environment.
+// It returns nil if single sign-on is not configured.
+func OIDCConfigFromEnv() (*OIDCConfig, error) {
+	issuer := os.Getenv(EnvOIDCIssuer)
+	if issuer == "" {
+		return nil, nil
+	}
+
+	config := &OIDCConfig{
+		ProviderName:  envOrDefault(EnvOIDCProviderName, "oidc"),
+		IssuerURL:     issuer,
+		ClientID:      os.Getenv(EnvOIDCClientID),
+		ClientSecret:  os.Getenv(EnvOIDCClientSecret),
+		RedirectURL:   os.Getenv(EnvOIDCRedirectURL),
+		Scopes:        splitList(envOrDefault(EnvOIDCScopes, "openid,profile,email")),
+		UsernameClaim: envOrDefault(EnvOIDCUsernameClaim, "preferred_username"),
+		GroupsClaim:   envOrDefault(EnvOIDCGroupsClaim, "groups"),
+		AdminGroups:   splitList(os.Getenv(EnvOIDCAdminGroups)),
+		ManagerGroups: splitList(os.Getenv(EnvOIDCManagerGroups)),
+		ViewerGroups:  splitList(os.Getenv(EnvOIDCViewerGroups)),
+		DefaultRole:   Role(os.Getenv(EnvOIDCDefaultRole)),
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks the configuration for required fields
+func (c *OIDCConfig) Validate() error {
+	if c.IssuerURL == "" {
+		return fmt.Errorf("%s is required", EnvOIDCIssuer)
+	}
+	if c.ClientID == "" {
+		return fmt.Errorf("%s is required", EnvOIDCClientID)
+	}
+	if c.RedirectURL == "" {
+		return fmt.Errorf("%s is required", EnvOIDCRedirectURL)
+	}
+	if c.DefaultRole != "" {
+		if _, ok := roleRank[c.DefaultRole]; !ok {
+			return fmt.Errorf("%s must be one of admin, manager, viewer", EnvOIDCDefaultRole)
+		}
+	}
+	hasOpenID := false
+	for _, scope := range c.Scopes {
+		if scope == "openid" {
+			hasOpenID = true
+		}
+	}
+	if !hasOpenID {
+		return fmt.Errorf("the openid scope is required")
+	}
+	return nil
+}
+
+// RoleForGroups returns the highest role granted by the groups, or the default role
+func (c *OIDCConfig) RoleForGroups(groups []string) (Role, bool) {
+	member := make(map[string]bool, len(groups))
+	for _, group := range groups {
+		member[group] = true
+	}
+	for _, mapping := range []struct {
+		role   Role
+		groups []string
+	}{
+		{RoleAdmin, c.AdminGroups},
+		{RoleManager, c.ManagerGroups},
+		{RoleViewer, c.ViewerGroups},
+	} {
+		for _, group := range mapping.groups {
+			if member[group] {
+				return mapping.role, true
+			}
+		}
+	}
+	if c.DefaultRole != "" {
+		return c.DefaultRole, true
+	}
+	return "", false
+}
+
+// OIDCIdentity represents the claims of a verified ID token
+type OIDCIdentity struct {
+	Subject  string
+	Username string
+	Name     string
+	Email    string
+	Picture  string
+	Groups   []string
+}
+
+// OIDCProvider performs the authorization code flow with PKCE against an
+// OpenID Connect identity provider and verifies the ID tokens it issues
+type OIDCProvider struct {
+	config     *OIDCConfig
+	oauth2     *oauth2.Config
+	issuer     string
+	jwksURL    string
+	httpClient *http.Client
+
+	keysMutex     sync.Mutex
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+// oidcDiscovery represents the fields of the discovery document that are used
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// NewOIDCProvider discovers the endpoints of the identity provider
+func NewOIDCProvider(ctx context.Context, config *OIDCConfig) (*OIDCProvider, error) {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+
+	discoveryURL := strings.TrimSuffix(config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, httpClient, discoveryURL, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(config.IssuerURL, "/") {
+		return nil, fmt.Errorf("issuer %s of the discovery document does not match %s", discovery.Issuer, config.IssuerURL)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document of %s is missing endpoints", config.IssuerURL)
+	}
+
+	return &OIDCProvider{
+		config: config,
+		oauth2: &oauth2.Config{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			RedirectURL:  config.RedirectURL,
+			Scopes:       config.Scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  discovery.AuthorizationEndpoint,
+				TokenURL: discovery.TokenEndpoint,
+			},
+		},
+		issuer:     discovery.Issuer,
+		jwksURL:    discovery.JWKSURI,
+		httpClient: httpClient,
+		keys:       map[string]interface{}{},
+	}, nil
+}
+
+// Name returns the name of the identity provider
+func (p *OIDCProvider) Name() string {
+	return p.config.ProviderName
+}
+
+// Config returns the configuration of the identity provider
+func (p *OIDCProvider) Config() *OIDCConfig {
+	return p.config
+}
+
+// AuthCodeURL returns the URL of the identity provider the user is redirected to
+func (p *OIDCProvider) AuthCodeURL(state, nonce, verifier string) string {
+	return p.oauth2.AuthCodeURL(state,
+		oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", nonce),
+	)
+}
+
+// Exchange exchanges the authorization code for tokens and returns the identity
+// of the verified ID token
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+	token, err := p.oauth2.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("token response does not contain an ID token")
+	}
+
+	return p.VerifyIDToken(ctx, rawIDToken, nonce)
+}
+
+// VerifyIDToken verifies the signature, issuer, audience, expiry and nonce of
+// an ID token and returns the identity it carries
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*OIDCIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(rawIDToken, claims,
+		func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			return p.signingKey(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(p.issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, fmt.Errorf("invalid ID token: nonce mismatch")
+	}
+
+	identity := &OIDCIdentity{
+		Subject: claimString(claims, "sub"),
+		Name:    claimString(claims, "name"),
+		Email:   claimString(claims, "email"),
+		Picture: claimString(claims, "picture"),
+		Groups:  claimStrings(claims, p.config.GroupsClaim),
+	}
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("invalid ID token: missing sub claim")
+	}
+	identity.Username = claimString(claims, p.config.UsernameClaim)
+	if identity.Username == "" {
+		identity.Username = identity.Email
+	}
+	if identity.Username == "" {
+		identity.Username = identity.Subject
+	}
+	return identity, nil
+}
+
+// signingKey returns the key with the given ID, fetching the keys of the
+// identity provider again when the key is unknown to follow key rotations
+func (p *OIDCProvider) signingKey(ctx context.Context, kid string) (interface{}, error) {
+	p.keysMutex.Lock()
+	defer p.keysMutex.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < oidcKeysRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey returns the key with the given ID, or the only key if the token has no key ID
+func (p *OIDCProvider) lookupKey(kid string) (interface{}, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+// jsonWebKey represents the fields of a JSON Web Key that are used
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchKeys fetches the signing keys of the identity provider, skipping keys of unsupported types
+func (p *OIDCProvider) fetchKeys(ctx context.Context) (map[string]interface{}, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := getJSON(ctx, p.httpClient, p.jwksURL, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no supported signing keys found at %s", p.jwksURL)
+	}
+	return keys, nil
+}
+
+// publicKey decodes an RSA or EC JSON Web Key
+func (k *jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64URLInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBase64URLInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64URLInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+	}
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode key: %w", err)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", url, err)
+	}
+	return nil
+}
+
+// lookupClaim returns the claim at a dot separated path
+func lookupClaim(claims map[string]interface{}, path string) interface{} {
+	var value interface{} = claims
+	for _, part := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[part]
+	}
+	return value
+}
+
+func claimString(claims map[string]interface{}, path string) string {
+	s, _ := lookupClaim(claims, path).(string)
+	return s
+}
+
+// claimStrings returns a claim holding a list of strings, a single string is a list of one
+func claimStrings(claims map[string]interface{}, path string) []string {
+	switch v := lookupClaim(claims, path).(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func envOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func splitList(s string) []string {
+	var values []string
+	for _, value := range strings.Split(s, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// LoginOIDC returns a session for the user of a verified identity, creating the
+// user on its first login. The role is granted by the identity provider on every
+// login, so removing a user from a group takes effect on the next login.
+func (s *AuthService) LoginOIDC(ctx context.Context, provider string, identity *OIDCIdentity, role Role) (*Session, error) {
+	providerName := sql.NullString{String: provider, Valid: true}
+	user, err := s.db.GetUserByProvider(ctx, &db.GetUserByProviderParams{
+		Provider:   providerName,
+		ProviderID: sql.NullString{String: identity.Subject, Valid: true},
+	})
+	switch {
+	case err == sql.ErrNoRows:
+		if _, err := s.db.GetUserByUsername(ctx, identity.Username); err == nil {
+			return nil, fmt.Errorf("username %s is already taken by another user", identity.Username)
+		} else if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		user, err = s.db.CreateOIDCUser(ctx, &db.CreateOIDCUserParams{
+			Username:   identity.Username,
+			Name:       nullString(identity.Name),
+			Email:      nullString(identity.Email),
+			Role:       sql.NullString{String: string(role), Valid: true},
+			Provider:   providerName,
+			ProviderID: sql.NullString{String: identity.Subject, Valid: true},
+			AvatarUrl:  nullString(identity.Picture),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	default:
+		user, err = s.db.UpdateOIDCUser(ctx, &db.UpdateOIDCUserParams{
+			Name:      nullString(identity.Name),
+			Email:     nullString(identity.Email),
+			Role:      sql.NullString{String: string(role), Valid: true},
+			AvatarUrl: nullString(identity.Picture),
+			ID:        user.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+
+	return s.createSession(ctx, user)
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/errors"
+	"github.com/chainlaunch/chainlaunch/pkg/http/response"
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// OIDCLoginCookieName holds the state of a login in progress with the identity provider
+	OIDCLoginCookieName = "oidc_login"
+	// oidcLoginTimeout is how long the user has to log in with the identity provider
+	oidcLoginTimeout = 10 * time.Minute
+)
+
+// OIDCHandler handles single sign-on with an OpenID Connect identity provider
+type OIDCHandler struct {
+	authService *AuthService
+	provider    *OIDCProvider
+}
+
+// NewOIDCHandler creates a new single sign-on handler, provider is nil when single sign-on is disabled
+func NewOIDCHandler(authService *AuthService, provider *OIDCProvider) *OIDCHandler {
+	return &OIDCHandler{
+		authService: authService,
+		provider:    provider,
+	}
+}
+
+// RegisterRoutes registers the single sign-on routes, they don't require authentication
+func (h *OIDCHandler) RegisterRoutes(r chi.Router) {
+	r.Route("/auth/oidc", func(r chi.Router) {
+		r.Get("/config", response.Middleware(h.ConfigHandler))
+		r.Get("/login", response.Middleware(h.LoginHandler))
+		r.Get("/callback", response.Middleware(h.CallbackHandler))
+	})
+}
+
+// OIDCConfigResponse represents the HTTP response describing single sign-on
+type OIDCConfigResponse struct {
+	Enabled      bool   `json:"enabled"`
+	ProviderName string `json:"provider_name,omitempty"`
+	LoginURL     string `json:"login_url,omitempty"`
+}
+
+// oidcLoginState is the state of a login in progress, kept in a signed cookie
+// so that no server-side storage is needed between the redirects
+type oidcLoginState struct {
+	State     string    `json:"state"`
+	Nonce     string    `json:"nonce"`
+	Verifier  string    `json:"verifier"`
+	ReturnTo  string    `json:"return_to"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// @Summary Get single sign-on configuration
+// @Description Returns whether single sign-on with an OpenID Connect identity provider is enabled
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} OIDCConfigResponse "Single sign-on configuration"
+// @Router /auth/oidc/config [get]
+// @BasePath /api/v1
+func (h *OIDCHandler) ConfigHandler(w http.ResponseWriter, r *http.Request) error {
+	if h.provider == nil {
+		return response.WriteJSON(w, http.StatusOK, OIDCConfigResponse{Enabled: false})
+	}
+	return response.WriteJSON(w, http.StatusOK, OIDCConfigResponse{
+		Enabled:      true,
+		ProviderName: h.provider.Name(),
+		LoginURL:     "/api/v1/auth/oidc/login",
+	})
+}
+
+// @Summary Start single sign-on login
+// @Description Redirects to the identity provider using the authorization code flow with PKCE
+// @Tags Authentication
+// @Param redirect_to query string false "Path to return to after logging in"
+// @Success 302 "Redirect to the identity provider"
+// @Failure 404 {object} response.Response "Single sign-on is not enabled"
+// @Router /auth/oidc/login [get]
+// @BasePath /api/v1
+func (h *OIDCHandler) LoginHandler(w http.ResponseWriter, r *http.Request) error {
+	if h.provider == nil {
+		return errors.NewNotFoundError("single sign-on is not enabled", nil)
+	}
+
+	state, err := randomString(32)
+	if err != nil {
+		return errors.NewInternalError("failed to start login", err, nil)
+	}
+	nonce, err := randomString(32)
+	if err != nil {
+		return errors.NewInternalError("failed to start login", err, nil)
+	}
+	login := oidcLoginState{
+		State:     state,
+		Nonce:     nonce,
+		Verifier:  oauth2.GenerateVerifier(),
+		ReturnTo:  safeReturnTo(r.URL.Query().Get("redirect_to")),
+		ExpiresAt: time.Now().Add(oidcLoginTimeout),
+	}
+
+	value, err := encodeOIDCLoginState(&login)
+	if err != nil {
+		return errors.NewInternalError("failed to start login", err, nil)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     OIDCLoginCookieName,
+		Value:    value,
+		Path:     "/api/v1/auth/oidc",
+		Expires:  login.ExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		// Lax so the cookie is sent on the redirect back from the identity provider
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, h.provider.AuthCodeURL(login.State, login.Nonce, login.Verifier), http.StatusFound)
+	return nil
+}
+
+// @Summary Complete single sign-on login
+// @Description Exchanges the authorization code, provisions the user on its first login, sets the session cookie and redirects to the UI
+// @Tags Authentication
+// @Param code query string true "Authorization code"
+// @Param state query string true "State of the login"
+// @Success 302 "Redirect to the UI"
+// @Failure 404 {object} response.Response "Single sign-on is not enabled"
+// @Router /auth/oidc/callback [get]
+// @BasePath /api/v1
+func (h *OIDCHandler) CallbackHandler(w http.ResponseWriter, r *http.Request) error {
+	if h.provider == nil {
+		return errors.NewNotFoundError("single sign-on is not enabled", nil)
+	}
+
+	// The login state is only valid once
+	http.SetCookie(w, &http.Cookie{
+		Name:     OIDCLoginCookieName,
+		Value:    "",
+		Path:     "/api/v1/auth/oidc",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	query := r.URL.Query()
+	if idpError := query.Get("error"); idpError != "" {
+		redirectLoginError(w, r, fmt.Sprintf("identity provider error: %s %s", idpError, query.Get("error_description")))
+		return nil
+	}
+
+	cookie, err := r.Cookie(OIDCLoginCookieName)
+	if err != nil {
+		redirectLoginError(w, r, "login expired, please try again")
+		return nil
+	}
+	login, err := decodeOIDCLoginState(cookie.Value)
+	if err != nil || time.Now().After(login.ExpiresAt) {
+		redirectLoginError(w, r, "login expired, please try again")
+		return nil
+	}
+	if query.Get("state") != login.State {
+		redirectLoginError(w, r, "invalid login state")
+		return nil
+	}
+
+	identity, err := h.provider.Exchange(r.Context(), query.Get("code"), login.Verifier, login.Nonce)
+	if err != nil {
+		log.Printf("Error completing OIDC login: %v", err)
+		redirectLoginError(w, r, "failed to verify the identity provider response")
+		return nil
+	}
+
+	role, ok := h.provider.Config().RoleForGroups(identity.Groups)
+	if !ok {
+		redirectLoginError(w, r, fmt.Sprintf("user %s is not allowed to access ChainLaunch", identity.Username))
+		return nil
+	}
+
+	session, err := h.authService.LoginOIDC(r.Context(), h.provider.Name(), identity, role)
+	if err != nil {
+		log.Printf("Error logging in OIDC user %s: %v", identity.Username, err)
+		redirectLoginError(w, r, err.Error())
+		return nil
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    session.ID + "." + signSessionID(session.ID),
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		// Strict cookies are not sent on the redirect chain started by the identity provider
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, login.ReturnTo, http.StatusFound)
+	return nil
+}
+
+// redirectLoginError sends the user back to the login page of the UI with an error
+func redirectLoginError(w http.ResponseWriter, r *http.Request, message string) {
+	http.Redirect(w, r, "/login?error="+url.QueryEscape(message), http.StatusFound)
+}
+
+// safeReturnTo only allows paths on this server to prevent open redirects
+func safeReturnTo(returnTo string) string {
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.HasPrefix(returnTo, "/\\") {
+		return "/"
+	}
+	return returnTo
+}
+
+func randomString(length int) (string, error) {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func encodeOIDCLoginState(login *oidcLoginState) (string, error) {
+	payload, err := json.Marshal(login)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal login state: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signSessionID(encoded), nil
+}
+
+func decodeOIDCLoginState(value string) (*oidcLoginState, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 || !verifySessionID(parts[0], parts[1]) {
+		return nil, fmt.Errorf("invalid login state signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode login state: %w", err)
+	}
+	var login oidcLoginState
+	if err := json.Unmarshal(payload, &login); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal login state: %w", err)
+	}
+	return &login, nil
+}
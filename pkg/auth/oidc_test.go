@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIdP is a minimal OpenID Connect identity provider issuing ID tokens for a single authorization code
+type mockIdP struct {
+	t         *testing.T
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	clientID  string
+	code      string
+	challenge string
+	claims    jwt.MapClaims
+}
+
+func newMockIdP(t *testing.T, clientID string) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	idp := &mockIdP{t: t, key: key, clientID: clientID, code: "test-code"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "test-key",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if r.Form.Get("code") != idp.code || base64.RawURLEncoding.EncodeToString(sum[:]) != idp.challenge {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     idp.signIDToken(idp.claims),
+		})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) signIDToken(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "test-key"
+	signed, err := token.SignedString(idp.key)
+	if err != nil {
+		idp.t.Fatalf("failed to sign ID token: %v", err)
+	}
+	return signed
+}
+
+func (idp *mockIdP) baseClaims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":                idp.server.URL,
+		"aud":                idp.clientID,
+		"sub":                "user-1",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              nonce,
+		"preferred_username": "alice",
+		"email":              "alice@example.com",
+		"name":               "Alice",
+		"realm_access":       map[string]interface{}{"roles": []interface{}{"chainlaunch-managers"}},
+	}
+}
+
+func newTestOIDCProvider(t *testing.T, idp *mockIdP) *OIDCProvider {
+	provider, err := NewOIDCProvider(context.Background(), &OIDCConfig{
+		ProviderName:  "keycloak",
+		IssuerURL:     idp.server.URL,
+		ClientID:      idp.clientID,
+		RedirectURL:   "http://localhost:8100/api/v1/auth/oidc/callback",
+		Scopes:        []string{"openid", "profile", "email"},
+		UsernameClaim: "preferred_username",
+		GroupsClaim:   "realm_access.roles",
+		ManagerGroups: []string{"chainlaunch-managers"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	return provider
+}
+
+func TestOIDCProviderExchange(t *testing.T) {
+	idp := newMockIdP(t, "chainlaunch")
+	provider := newTestOIDCProvider(t, idp)
+
+	verifier := "test-verifier-0123456789-0123456789-0123456789"
+	authURL, err := url.Parse(provider.AuthCodeURL("state", "nonce", verifier))
+	if err != nil {
+		t.Fatalf("failed to parse auth URL: %v", err)
+	}
+	query := authURL.Query()
+	if query.Get("code_challenge_method") != "S256" || query.Get("nonce") != "nonce" || query.Get("state") != "state" {
+		t.Fatalf("unexpected auth URL %s", authURL)
+	}
+	idp.challenge = query.Get("code_challenge")
+	idp.claims = idp.baseClaims("nonce")
+
+	identity, err := provider.Exchange(context.Background(), idp.code, verifier, "nonce")
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+	if identity.Subject != "user-1" || identity.Username != "alice" || identity.Email != "alice@example.com" {
+		t.Errorf("unexpected identity %+v", identity)
+	}
+	role, ok := provider.Config().RoleForGroups(identity.Groups)
+	if !ok || role != RoleManager {
+		t.Errorf("expected manager role, got %q (%v)", role, ok)
+	}
+
+	if _, err := provider.Exchange(context.Background(), idp.code, "wrong-verifier-0123456789-0123456789-0123456789", "nonce"); err == nil {
+		t.Error("expected exchange with a wrong PKCE verifier to fail")
+	}
+}
+
+func TestOIDCProviderVerifyIDToken(t *testing.T) {
+	idp := newMockIdP(t, "chainlaunch")
+	provider := newTestOIDCProvider(t, idp)
+
+	if _, err := provider.VerifyIDToken(context.Background(), idp.signIDToken(idp.baseClaims("nonce")), "nonce"); err != nil {
+		t.Fatalf("expected valid ID token, got %v", err)
+	}
+
+	invalid := map[string]func(jwt.MapClaims){
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "other-client" },
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"wrong nonce":    func(c jwt.MapClaims) { c["nonce"] = "replayed" },
+		"missing sub":    func(c jwt.MapClaims) { delete(c, "sub") },
+	}
+	for name, mutate := range invalid {
+		claims := idp.baseClaims("nonce")
+		mutate(claims)
+		if _, err := provider.VerifyIDToken(context.Background(), idp.signIDToken(claims), "nonce"); err == nil {
+			t.Errorf("%s: expected ID token to be rejected", name)
+		}
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodRS256, idp.baseClaims("nonce"))
+	forged.Header["kid"] = "test-key"
+	signed, err := forged.SignedString(otherKey)
+	if err != nil {
+		t.Fatalf("failed to sign ID token: %v", err)
+	}
+	if _, err := provider.VerifyIDToken(context.Background(), signed, "nonce"); err == nil {
+		t.Error("expected ID token signed with another key to be rejected")
+	}
+}
+
+func TestOIDCRoleForGroups(t *testing.T) {
+	config := &OIDCConfig{
+		AdminGroups:   []string{"admins"},
+		ManagerGroups: []string{"operators"},
+		ViewerGroups:  []string{"auditors"},
+	}
+	cases := []struct {
+		groups []string
+		role   Role
+		ok     bool
+	}{
+		{[]string{"auditors", "admins"}, RoleAdmin, true},
+		{[]string{"operators"}, RoleManager, true},
+		{[]string{"auditors"}, RoleViewer, true},
+		{[]string{"developers"}, "", false},
+		{nil, "", false},
+	}
+	for _, c := range cases {
+		role, ok := config.RoleForGroups(c.groups)
+		if role != c.role || ok != c.ok {
+			t.Errorf("groups %v: expected %q (%v), got %q (%v)", c.groups, c.role, c.ok, role, ok)
+		}
+	}
+
+	config.DefaultRole = RoleViewer
+	if role, ok := config.RoleForGroups([]string{"developers"}); !ok || role != RoleViewer {
+		t.Errorf("expected default role viewer, got %q (%v)", role, ok)
+	}
+}
+
+func TestOIDCLoginState(t *testing.T) {
+	login := &oidcLoginState{State: "state", Nonce: "nonce", Verifier: "verifier", ReturnTo: "/nodes", ExpiresAt: time.Now().Add(time.Minute)}
+	value, err := encodeOIDCLoginState(login)
+	if err != nil {
+		t.Fatalf("failed to encode login state: %v", err)
+	}
+	decoded, err := decodeOIDCLoginState(value)
+	if err != nil {
+		t.Fatalf("failed to decode login state: %v", err)
+	}
+	if decoded.State != login.State || decoded.Verifier != login.Verifier || decoded.ReturnTo != login.ReturnTo {
+		t.Errorf("unexpected login state %+v", decoded)
+	}
+	if _, err := decodeOIDCLoginState("tampered" + value); err == nil {
+		t.Error("expected tampered login state to be rejected")
+	}
+
+	for returnTo, expected := range map[string]string{
+		"/nodes":              "/nodes",
+		"":                    "/",
+		"https://evil.com":    "/",
+		"//evil.com":          "/",
+		"/\\evil.com":         "/",
+		"javascript:alert(1)": "/",
+	} {
+		if got := safeReturnTo(returnTo); got != expected {
+			t.Errorf("safeReturnTo(%q) = %q, expected %q", returnTo, got, expected)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	apperrors "github.com/chainlaunch/chainlaunch/pkg/errors"
+	"github.com/chainlaunch/chainlaunch/pkg/http/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// ResourceType is the kind of resource a role binding applies to
+type ResourceType string
+
+const (
+	ResourceOrganization ResourceType = "organization"
+	ResourceNetwork      ResourceType = "network"
+	ResourceNode         ResourceType = "node"
+	ResourceKey          ResourceType = "key"
+)
+
+var (
+	// ErrForbidden is returned when the user lacks the role required on a resource
+	ErrForbidden = errors.New("forbidden")
+	// ErrUserNotFound is returned when binding a role to a user that does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrRoleBindingNotFound is returned when a role binding does not exist
+	ErrRoleBindingNotFound = errors.New("role binding not found")
+)
+
+// validResourceTypes are the resource types roles can be bound to
+var validResourceTypes = map[ResourceType]bool{
+	ResourceOrganization: true,
+	ResourceNetwork:      true,
+	ResourceNode:         true,
+	ResourceKey:          true,
+}
+
+// RoleBinding grants a user a role on a single resource and the resources
+// under it, on top of the global role of the user
+type RoleBinding struct {
+	ID           int64        `json:"id"`
+	UserID       int64        `json:"user_id"`
+	ResourceType ResourceType `json:"resource_type"`
+	ResourceID   int64        `json:"resource_id"`
+	Role         Role         `json:"role"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+// CreateRoleBindingRequest represents the request to grant a role on a resource
+type CreateRoleBindingRequest struct {
+	ResourceType ResourceType `json:"resource_type" validate:"required,oneof=organization network node key"`
+	ResourceID   int64        `json:"resource_id" validate:"required"`
+	Role         Role         `json:"role" validate:"required,oneof=admin manager"`
+}
+
+// AuthorizationDecision is the outcome of an authorization check, recorded in the audit log
+type AuthorizationDecision struct {
+	ResourceType  ResourceType `json:"resource_type,omitempty"`
+	ResourceID    int64        `json:"resource_id,omitempty"`
+	RequiredRole  Role         `json:"required_role"`
+	EffectiveRole Role         `json:"effective_role"`
+	// GrantedBy is the resource whose role binding granted the effective role,
+	// empty when it is the global role of the user
+	GrantedBy string `json:"granted_by,omitempty"`
+	Allowed   bool   `json:"allowed"`
+}
+
+// AuthorizationRecorder collects the authorization decisions taken while serving a request
+type AuthorizationRecorder struct {
+	mu        sync.Mutex
+	decisions []AuthorizationDecision
+}
+
+// Decisions returns the decisions recorded so far
+func (r *AuthorizationRecorder) Decisions() []AuthorizationDecision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]AuthorizationDecision(nil), r.decisions...)
+}
+
+func (r *AuthorizationRecorder) record(decision AuthorizationDecision) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decisions = append(r.decisions, decision)
+}
+
+// ContextWithAuthorizationRecorder adds a recorder collecting the authorization decisions of a request
+func ContextWithAuthorizationRecorder(ctx context.Context) (context.Context, *AuthorizationRecorder) {
+	recorder := &AuthorizationRecorder{}
+	return context.WithValue(ctx, recorderContextKey, recorder), recorder
+}
+
+func contextWithAuthService(ctx context.Context, authService *AuthService) context.Context {
+	return context.WithValue(ctx, authServiceContextKey, authService)
+}
+
+// CreateRoleBinding grants a user a role on a resource
+func (s *AuthService) CreateRoleBinding(ctx context.Context, userID int64, createdBy int64, req *CreateRoleBindingRequest) (*RoleBinding, error) {
+	if !validResourceTypes[req.ResourceType] {
+		return nil, fmt.Errorf("invalid resource type: %s", req.ResourceType)
+	}
+	if req.Role != RoleAdmin && req.Role != RoleManager {
+		return nil, fmt.Errorf("invalid role: %s, only admin and manager can be bound to a resource", req.Role)
+	}
+	if _, err := s.db.GetUser(ctx, userID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	binding, err := s.db.CreateRoleBinding(ctx, &db.CreateRoleBindingParams{
+		UserID:       userID,
+		ResourceType: string(req.ResourceType),
+		ResourceID:   req.ResourceID,
+		Role:         string(req.Role),
+		CreatedBy:    sql.NullInt64{Int64: createdBy, Valid: createdBy != 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create role binding: %w", err)
+	}
+	return toRoleBinding(binding), nil
+}
+
+// GetRoleBinding returns a role binding by ID
+func (s *AuthService) GetRoleBinding(ctx context.Context, id int64) (*RoleBinding, error) {
+	binding, err := s.db.GetRoleBinding(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRoleBindingNotFound
+		}
+		return nil, fmt.Errorf("failed to get role binding: %w", err)
+	}
+	return toRoleBinding(binding), nil
+}
+
+// ListRoleBindings returns the role bindings of a user
+func (s *AuthService) ListRoleBindings(ctx context.Context, userID int64) ([]*RoleBinding, error) {
+	dbBindings, err := s.db.ListRoleBindingsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role bindings: %w", err)
+	}
+
+	bindings := make([]*RoleBinding, len(dbBindings))
+	for i, binding := range dbBindings {
+		bindings[i] = toRoleBinding(binding)
+	}
+	return bindings, nil
+}
+
+// DeleteRoleBinding revokes a role binding
+func (s *AuthService) DeleteRoleBinding(ctx context.Context, id int64) error {
+	if _, err := s.GetRoleBinding(ctx, id); err != nil {
+		return err
+	}
+	if err := s.db.DeleteRoleBinding(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete role binding: %w", err)
+	}
+	return nil
+}
+
+// EffectiveRole returns the highest role of the user on a resource, that is
+// its global role or a role bound to the resource or to a resource above it:
+// a node belongs to its organization and networks, a key to the organizations
+// using it. It also returns the resource whose binding granted the role.
+func (s *AuthService) EffectiveRole(ctx context.Context, user *User, resourceType ResourceType, resourceID int64) (Role, string, error) {
+	role := user.Role
+	if role == RoleAdmin || resourceType == "" {
+		return role, "", nil
+	}
+
+	bindings, err := s.db.ListRoleBindingsByUser(ctx, user.ID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to list role bindings: %w", err)
+	}
+	if len(bindings) == 0 {
+		return role, "", nil
+	}
+
+	scopes, err := s.resourceScopes(ctx, resourceType, resourceID)
+	if err != nil {
+		return "", "", err
+	}
+
+	grantedBy := ""
+	for _, binding := range bindings {
+		scope := fmt.Sprintf("%s:%d", binding.ResourceType, binding.ResourceID)
+		if !scopes[scope] {
+			continue
+		}
+		bound := Role(binding.Role)
+		// A restricted API token never gains more through its bindings
+		if user.MaxRole != "" && roleRank[bound] > roleRank[user.MaxRole] {
+			bound = user.MaxRole
+		}
+		if roleRank[bound] > roleRank[role] {
+			role = bound
+			grantedBy = scope
+		}
+	}
+	return role, grantedBy, nil
+}
+
+// resourceScopes returns the resource and the resources above it as "type:id"
+func (s *AuthService) resourceScopes(ctx context.Context, resourceType ResourceType, resourceID int64) (map[string]bool, error) {
+	scopes := map[string]bool{
+		fmt.Sprintf("%s:%d", resourceType, resourceID): true,
+	}
+
+	switch resourceType {
+	case ResourceNode:
+		node, err := s.db.GetNode(ctx, resourceID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get node: %w", err)
+		}
+		if err == nil && node.FabricOrganizationID.Valid {
+			scopes[fmt.Sprintf("%s:%d", ResourceOrganization, node.FabricOrganizationID.Int64)] = true
+		}
+		networkIDs, err := s.db.ListNetworkIDsByNode(ctx, resourceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list networks of node: %w", err)
+		}
+		for _, networkID := range networkIDs {
+			scopes[fmt.Sprintf("%s:%d", ResourceNetwork, networkID)] = true
+		}
+	case ResourceKey:
+		orgIDs, err := s.db.ListOrganizationIDsByKey(ctx, sql.NullInt64{Int64: resourceID, Valid: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list organizations of key: %w", err)
+		}
+		for _, orgID := range orgIDs {
+			scopes[fmt.Sprintf("%s:%d", ResourceOrganization, orgID)] = true
+		}
+	}
+	return scopes, nil
+}
+
+// Authorize checks that the user of the context has at least the given role
+// on a resource, an empty resource type checks the global role only. The
+// decision is recorded for the audit log. It returns ErrForbidden when the
+// role is missing.
+func Authorize(ctx context.Context, resourceType ResourceType, resourceID int64, required Role) error {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("%w: not authenticated", ErrForbidden)
+	}
+
+	effective, grantedBy := user.Role, ""
+	if authService, ok := ctx.Value(authServiceContextKey).(*AuthService); ok {
+		var err error
+		effective, grantedBy, err = authService.EffectiveRole(ctx, user, resourceType, resourceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	allowed := roleRank[effective] >= roleRank[required]
+	if recorder, ok := ctx.Value(recorderContextKey).(*AuthorizationRecorder); ok {
+		recorder.record(AuthorizationDecision{
+			ResourceType:  resourceType,
+			ResourceID:    resourceID,
+			RequiredRole:  required,
+			EffectiveRole: effective,
+			GrantedBy:     grantedBy,
+			Allowed:       allowed,
+		})
+	}
+
+	if !allowed {
+		if resourceType == "" {
+			return fmt.Errorf("%w: requires %s role", ErrForbidden, required)
+		}
+		return fmt.Errorf("%w: requires %s role on %s %d", ErrForbidden, required, resourceType, resourceID)
+	}
+	return nil
+}
+
+// AuthorizeOrganizationMSP checks the role of the user of the context on the organization with the given MSP ID
+func AuthorizeOrganizationMSP(ctx context.Context, mspID string, required Role) error {
+	authService, ok := ctx.Value(authServiceContextKey).(*AuthService)
+	if !ok {
+		return Authorize(ctx, "", 0, required)
+	}
+	org, err := authService.db.GetFabricOrganizationByMSPID(ctx, mspID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// Unknown organizations can only be acted upon with the global role
+			return Authorize(ctx, "", 0, required)
+		}
+		return fmt.Errorf("failed to get organization: %w", err)
+	}
+	return Authorize(ctx, ResourceOrganization, org.ID, required)
+}
+
+// RequireRole ensures the user has at least the given global role
+func RequireRole(required Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := Authorize(r.Context(), "", 0, required); err != nil {
+				writeAuthorizationError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireResourceRole ensures the user has at least the given role on the
+// resource whose ID is the URL parameter param
+func RequireResourceRole(resourceType ResourceType, param string, required Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resourceID, err := strconv.ParseInt(chi.URLParam(r, param), 10, 64)
+			if err != nil {
+				response.WriteError(w, apperrors.NewValidationError(fmt.Sprintf("invalid %s ID", resourceType), nil))
+				return
+			}
+			if err := Authorize(r.Context(), resourceType, resourceID, required); err != nil {
+				writeAuthorizationError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeAuthorizationError writes 403 for a missing role and 500 for a failed check
+func writeAuthorizationError(w http.ResponseWriter, err error) {
+	response.WriteError(w, AuthorizationError(err))
+}
+
+func toRoleBinding(binding *db.RoleBinding) *RoleBinding {
+	return &RoleBinding{
+		ID:           binding.ID,
+		UserID:       binding.UserID,
+		ResourceType: ResourceType(binding.ResourceType),
+		ResourceID:   binding.ResourceID,
+		Role:         Role(binding.Role),
+		CreatedAt:    binding.CreatedAt,
+	}
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chainlaunch/chainlaunch/pkg/errors"
+	"github.com/chainlaunch/chainlaunch/pkg/http/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary List my permissions
+// @Description Returns the roles granted to the current user on organizations, networks, nodes and keys
+// @Tags Authentication
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {array} RoleBinding "Role bindings of the current user"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /auth/permissions [get]
+// @BasePath /api/v1
+func (h *Handler) ListMyRoleBindingsHandler(w http.ResponseWriter, r *http.Request) error {
+	session, ok := SessionFromContext(r.Context())
+	if !ok {
+		return errors.NewValidationError("unauthorized", nil)
+	}
+
+	bindings, err := h.authService.ListRoleBindings(r.Context(), session.UserID)
+	if err != nil {
+		return err
+	}
+
+	return response.WriteJSON(w, http.StatusOK, bindings)
+}
+
+// @Summary List user permissions
+// @Description Returns the roles granted to a user on organizations, networks, nodes and keys (admin only)
+// @Tags Users
+// @Produce json
+// @Security CookieAuth
+// @Param id path int true "User ID"
+// @Success 200 {array} RoleBinding "Role bindings of the user"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
+// @Router /users/{id}/permissions [get]
+// @BasePath /api/v1
+func (h *Handler) ListRoleBindingsHandler(w http.ResponseWriter, r *http.Request) error {
+	if _, err := requireAdmin(r); err != nil {
+		return err
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid user ID", nil)
+	}
+
+	bindings, err := h.authService.ListRoleBindings(r.Context(), userID)
+	if err != nil {
+		return err
+	}
+
+	return response.WriteJSON(w, http.StatusOK, bindings)
+}
+
+// @Summary Grant a permission
+// @Description Grants a user the admin or manager role on an organization, network, node or key. Requires the admin role globally or on the resource.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param id path int true "User ID"
+// @Param binding body CreateRoleBindingRequest true "Role to grant"
+// @Success 201 {object} RoleBinding "Permission granted"
+// @Failure 400 {object} response.Response "Invalid request body"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role on the resource"
+// @Failure 404 {object} response.Response "User not found"
+// @Failure 409 {object} response.Response "Role already bound on the resource"
+// @Router /users/{id}/permissions [post]
+// @BasePath /api/v1
+func (h *Handler) CreateRoleBindingHandler(w http.ResponseWriter, r *http.Request) error {
+	session, ok := SessionFromContext(r.Context())
+	if !ok {
+		return errors.NewValidationError("unauthorized", nil)
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid user ID", nil)
+	}
+
+	var req CreateRoleBindingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", nil)
+	}
+	if !validResourceTypes[req.ResourceType] {
+		return errors.NewValidationError("resource_type must be one of organization, network, node or key", nil)
+	}
+	if req.ResourceID <= 0 {
+		return errors.NewValidationError("resource_id is required", nil)
+	}
+
+	// Admins of a resource manage who else can act on it
+	if err := Authorize(r.Context(), req.ResourceType, req.ResourceID, RoleAdmin); err != nil {
+		return AuthorizationError(err)
+	}
+
+	binding, err := h.authService.CreateRoleBinding(r.Context(), userID, session.UserID, &req)
+	if err != nil {
+		if err == ErrUserNotFound {
+			return errors.NewNotFoundError("user not found", nil)
+		}
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return errors.NewConflictError("user already has a role on this resource", nil)
+		}
+		return errors.NewValidationError(err.Error(), nil)
+	}
+
+	return response.WriteJSON(w, http.StatusCreated, binding)
+}
+
+// @Summary Revoke a permission
+// @Description Revokes a role granted to a user on a resource. Requires the admin role globally or on the resource.
+// @Tags Users
+// @Security CookieAuth
+// @Param id path int true "User ID"
+// @Param permissionId path int true "Permission ID"
+// @Success 204 "Permission revoked"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role on the resource"
+// @Failure 404 {object} response.Response "Permission not found"
+// @Router /users/{id}/permissions/{permissionId} [delete]
+// @BasePath /api/v1
+func (h *Handler) DeleteRoleBindingHandler(w http.ResponseWriter, r *http.Request) error {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid user ID", nil)
+	}
+	bindingID, err := strconv.ParseInt(chi.URLParam(r, "permissionId"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid permission ID", nil)
+	}
+
+	binding, err := h.authService.GetRoleBinding(r.Context(), bindingID)
+	if err != nil {
+		if err == ErrRoleBindingNotFound {
+			return errors.NewNotFoundError("permission not found", nil)
+		}
+		return err
+	}
+	if binding.UserID != userID {
+		return errors.NewNotFoundError("permission not found", nil)
+	}
+
+	if err := Authorize(r.Context(), binding.ResourceType, binding.ResourceID, RoleAdmin); err != nil {
+		return AuthorizationError(err)
+	}
+
+	if err := h.authService.DeleteRoleBinding(r.Context(), bindingID); err != nil {
+		if err == ErrRoleBindingNotFound {
+			return errors.NewNotFoundError("permission not found", nil)
+		}
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// AuthorizationError converts the error of an authorization check to an HTTP error
+func AuthorizationError(err error) error {
+	if stderrors.Is(err, ErrForbidden) {
+		return errors.NewAuthorizationError(err.Error(), nil)
+	}
+	return errors.NewInternalError("failed to check permissions", err, nil)
+}
@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"encoding/base64"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/chainlaunch/chainlaunch/pkg/db"
@@ -14,13 +15,18 @@ import (
 
 // AuthService handles authentication operations
 type AuthService struct {
-	db *db.Queries
+	db             *db.Queries
+	securityEvents SecurityEventLogger
+	totpPolicy     TOTPPolicy
+	loginLimiter   *loginRateLimiter
+	trustedProxies []*net.IPNet
 }
 
 // NewAuthService creates a new authentication service
 func NewAuthService(db *db.Queries) *AuthService {
 	return &AuthService{
-		db: db,
+		db:           db,
+		loginLimiter: newLoginRateLimiter(loginRateLimit, loginRateWindow),
 	}
 }
 
@@ -86,22 +92,21 @@ func (s *AuthService) GetUser(ctx context.Context, id int64) (*User, error) {
 	}, nil
 }
 
-// Login authenticates a user and returns a session
-func (s *AuthService) Login(ctx context.Context, username, password string) (*Session, error) {
-	// Get user from database
-	user, err := s.db.GetUserByUsername(ctx, username)
+// Login authenticates a user and returns a session. It returns ErrTOTPRequired
+// for users with two-factor authentication, which must log in with BeginLogin.
+func (s *AuthService) Login(ctx context.Context, username, password, clientIP string) (*Session, error) {
+	result, err := s.BeginLogin(ctx, username, password, clientIP)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("invalid credentials")
-		}
-		return nil, fmt.Errorf("failed to get user: %w", err)
+		return nil, err
 	}
-
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+	if result.Session == nil {
+		return nil, ErrTOTPRequired
 	}
+	return result.Session, nil
+}
 
+// createSession creates a session for a user that was authenticated
+func (s *AuthService) createSession(ctx context.Context, user *db.User) (*Session, error) {
 	// Generate session ID
 	sessionID := make([]byte, 32)
 	if _, err := rand.Read(sessionID); err != nil {
@@ -137,7 +142,7 @@ func (s *AuthService) Login(ctx context.Context, username, password string) (*Se
 	return &Session{
 		ID:        dbSession.SessionID,
 		Token:     token,
-		Username:  username,
+		Username:  user.Username,
 		UserID:    user.ID,
 		Role:      Role(user.Role.String),
 		CreatedAt: dbSession.CreatedAt,
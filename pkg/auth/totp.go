@@ -0,0 +1,639 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// EnvRequireTOTP enforces two-factor authentication: "admin" for admins, "all" for every local user
+	EnvRequireTOTP = "CHAINLAUNCH_REQUIRE_TOTP"
+
+	totpIssuer = "ChainLaunch"
+	totpDigits = 6
+	totpPeriod = 30
+	// totpSkew is the number of periods accepted before and after the current one to allow for clock drift
+	totpSkew = 1
+	// recoveryCodeCount is the number of recovery codes generated when enabling two-factor authentication
+	recoveryCodeCount = 10
+	// loginChallengeTimeout is how long the user has to enter the second factor after the password
+	loginChallengeTimeout = 5 * time.Minute
+)
+
+// TOTPPolicy tells which users must use two-factor authentication
+type TOTPPolicy string
+
+const (
+	TOTPPolicyOptional TOTPPolicy = ""
+	TOTPPolicyAdmins   TOTPPolicy = "admin"
+	TOTPPolicyAll      TOTPPolicy = "all"
+)
+
+var (
+	// ErrTOTPRequired is returned by Login when the user must complete a second factor
+	ErrTOTPRequired = errors.New("two-factor authentication required")
+	// ErrTOTPNotEnabled is returned when the user has not enabled two-factor authentication
+	ErrTOTPNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrTOTPAlreadyEnabled is returned when enrolling a user that already uses two-factor authentication
+	ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTOTPEnforced is returned when disabling two-factor authentication that is required for the user
+	ErrTOTPEnforced = errors.New("two-factor authentication is required and cannot be disabled")
+	// ErrInvalidTOTPCode is returned when a two-factor authentication or recovery code is wrong
+	ErrInvalidTOTPCode = errors.New("invalid two-factor authentication code")
+	// ErrInvalidLoginChallenge is returned when a login challenge is tampered with or expired
+	ErrInvalidLoginChallenge = errors.New("invalid or expired login, please log in again")
+)
+
+// TOTPPolicyFromEnv reads the two-factor authentication policy from the environment
+func TOTPPolicyFromEnv() (TOTPPolicy, error) {
+	policy := TOTPPolicy(strings.ToLower(strings.TrimSpace(os.Getenv(EnvRequireTOTP))))
+	switch policy {
+	case TOTPPolicyOptional, TOTPPolicyAdmins, TOTPPolicyAll:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid %s %q, expected admin or all", EnvRequireTOTP, policy)
+}
+
+// SetTOTPPolicy sets which users must use two-factor authentication
+func (s *AuthService) SetTOTPPolicy(policy TOTPPolicy) {
+	s.totpPolicy = policy
+}
+
+// totpRequired returns true if users with the role must use two-factor authentication
+func (s *AuthService) totpRequired(role Role) bool {
+	switch s.totpPolicy {
+	case TOTPPolicyAll:
+		return true
+	case TOTPPolicyAdmins:
+		return role == RoleAdmin
+	}
+	return false
+}
+
+// loginChallenge is the state of a login waiting for its second factor. It is
+// signed and returned to the client so that no server-side storage is needed.
+type loginChallenge struct {
+	UserID    int64     `json:"user_id"`
+	Enroll    bool      `json:"enroll"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BeginLogin verifies the password of a local user. It returns a session, or a
+// challenge to complete with CompleteLoginTOTP when the user has two-factor
+// authentication enabled, or with CompleteLoginEnrollment when the user must enroll.
+// Failed attempts count towards the lockout of the username.
+func (s *AuthService) BeginLogin(ctx context.Context, username, password, clientIP string) (*LoginResult, error) {
+	if err := s.checkLoginLocked(ctx, username); err != nil {
+		return nil, err
+	}
+
+	user, err := s.db.GetUserByUsername(ctx, username)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	// Service accounts only authenticate with API tokens
+	if err == sql.ErrNoRows || user.ServiceAccount {
+		s.recordLoginFailure(ctx, SecurityEventLoginFailed, 0, username, clientIP)
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		s.recordLoginFailure(ctx, SecurityEventLoginFailed, user.ID, username, clientIP)
+		return nil, ErrInvalidCredentials
+	}
+
+	enabled, err := s.totpEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled || s.totpRequired(Role(user.Role.String)) {
+		challenge, err := encodeLoginChallenge(&loginChallenge{
+			UserID:    user.ID,
+			Enroll:    !enabled,
+			ExpiresAt: time.Now().Add(loginChallengeTimeout),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Challenge: challenge, EnrollmentRequired: !enabled}, nil
+	}
+
+	s.clearLoginFailures(ctx, username)
+	session, err := s.createSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Session: session}, nil
+}
+
+// CompleteLoginTOTP completes a login with a two-factor authentication or recovery code
+func (s *AuthService) CompleteLoginTOTP(ctx context.Context, challenge, code, clientIP string) (*Session, error) {
+	login, user, err := s.decodeLoginChallenge(ctx, challenge)
+	if err != nil {
+		return nil, err
+	}
+	if login.Enroll {
+		return nil, ErrInvalidLoginChallenge
+	}
+	if err := s.checkLoginLocked(ctx, user.Username); err != nil {
+		return nil, err
+	}
+	if err := s.verifySecondFactor(ctx, user, code, clientIP); err != nil {
+		return nil, err
+	}
+
+	s.clearLoginFailures(ctx, user.Username)
+	return s.createSession(ctx, user)
+}
+
+// EnrollTOTPForLogin starts the enrollment of a user that must use two-factor authentication to log in
+func (s *AuthService) EnrollTOTPForLogin(ctx context.Context, challenge string) (*TOTPEnrollment, error) {
+	login, user, err := s.decodeLoginChallenge(ctx, challenge)
+	if err != nil {
+		return nil, err
+	}
+	if !login.Enroll {
+		return nil, ErrInvalidLoginChallenge
+	}
+	return s.EnrollTOTP(ctx, user.ID)
+}
+
+// CompleteLoginEnrollment enables two-factor authentication with the first code
+// and completes the login, it returns the session and the recovery codes
+func (s *AuthService) CompleteLoginEnrollment(ctx context.Context, challenge, code, clientIP string) (*Session, []string, error) {
+	login, user, err := s.decodeLoginChallenge(ctx, challenge)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !login.Enroll {
+		return nil, nil, ErrInvalidLoginChallenge
+	}
+
+	recoveryCodes, err := s.EnableTOTP(ctx, user.ID, code, clientIP)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.clearLoginFailures(ctx, user.Username)
+	session, err := s.createSession(ctx, user)
+	if err != nil {
+		return nil, nil, err
+	}
+	return session, recoveryCodes, nil
+}
+
+// GetTOTPStatus returns the two-factor authentication status of a user
+func (s *AuthService) GetTOTPStatus(ctx context.Context, userID int64) (*TOTPStatus, error) {
+	user, err := s.db.GetUser(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	status := &TOTPStatus{Required: s.totpRequired(Role(user.Role.String))}
+	totp, err := s.db.GetUserTOTP(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return status, nil
+		}
+		return nil, fmt.Errorf("failed to get two-factor authentication: %w", err)
+	}
+	if !totp.Enabled {
+		return status, nil
+	}
+
+	status.Enabled = true
+	if totp.EnabledAt.Valid {
+		status.EnabledAt = &totp.EnabledAt.Time
+	}
+	status.RecoveryCodesRemaining, err = s.db.CountUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return status, nil
+}
+
+// EnrollTOTP generates a new secret for a user, it is only used once confirmed with EnableTOTP
+func (s *AuthService) EnrollTOTP(ctx context.Context, userID int64) (*TOTPEnrollment, error) {
+	user, err := s.db.GetUser(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	enabled, err := s.totpEnabled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := encryptTOTPSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.db.UpsertUserTOTP(ctx, &db.UpsertUserTOTPParams{
+		UserID: userID,
+		Secret: encrypted,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save two-factor authentication secret: %w", err)
+	}
+
+	return &TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(secret, user.Username),
+	}, nil
+}
+
+// EnableTOTP confirms the enrollment of a user with a code from the
+// authenticator app and returns new recovery codes
+func (s *AuthService) EnableTOTP(ctx context.Context, userID int64, code, clientIP string) ([]string, error) {
+	totp, err := s.db.GetUserTOTP(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTOTPNotEnabled
+		}
+		return nil, fmt.Errorf("failed to get two-factor authentication: %w", err)
+	}
+	if totp.Enabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := decryptTOTPSecret(totp.Secret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := verifyTOTP(secret, code, time.Now(), 0)
+	if !ok {
+		return nil, ErrInvalidTOTPCode
+	}
+
+	if err := s.db.EnableUserTOTP(ctx, &db.EnableUserTOTPParams{
+		LastUsedStep: step,
+		UserID:       userID,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	recoveryCodes, err := s.generateRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logSecurityEvent(ctx, SecurityEvent{
+		Type:     SecurityEventTOTPEnabled,
+		UserID:   userID,
+		SourceIP: clientIP,
+		Success:  true,
+	})
+	return recoveryCodes, nil
+}
+
+// DisableTOTP disables two-factor authentication of a user, it requires the
+// password and a code and is refused when the policy requires it for the user
+func (s *AuthService) DisableTOTP(ctx context.Context, userID int64, password, code, clientIP string) error {
+	user, err := s.db.GetUser(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if s.totpRequired(Role(user.Role.String)) {
+		return ErrTOTPEnforced
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		s.recordLoginFailure(ctx, SecurityEventLoginFailed, user.ID, user.Username, clientIP)
+		return ErrInvalidCredentials
+	}
+	if err := s.verifySecondFactor(ctx, user, code, clientIP); err != nil {
+		return err
+	}
+
+	if err := s.deleteTOTP(ctx, userID); err != nil {
+		return err
+	}
+	s.logSecurityEvent(ctx, SecurityEvent{
+		Type:     SecurityEventTOTPDisabled,
+		UserID:   user.ID,
+		Username: user.Username,
+		SourceIP: clientIP,
+		Success:  true,
+	})
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user, it requires a current code
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code, clientIP string) ([]string, error) {
+	user, err := s.db.GetUser(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.verifySecondFactor(ctx, user, code, clientIP); err != nil {
+		return nil, err
+	}
+	return s.generateRecoveryCodes(ctx, userID)
+}
+
+// ResetTOTP removes the two-factor authentication of a user that lost its
+// authenticator and recovery codes, the user must enroll again if it is required
+func (s *AuthService) ResetTOTP(ctx context.Context, userID int64, resetBy int64) error {
+	user, err := s.db.GetUser(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if err := s.deleteTOTP(ctx, userID); err != nil {
+		return err
+	}
+	s.logSecurityEvent(ctx, SecurityEvent{
+		Type:     SecurityEventTOTPReset,
+		UserID:   user.ID,
+		Username: user.Username,
+		Success:  true,
+		Details:  map[string]interface{}{"reset_by": resetBy},
+	})
+	return nil
+}
+
+// verifySecondFactor checks a two-factor authentication code, or consumes a
+// recovery code, failures count towards the lockout of the user
+func (s *AuthService) verifySecondFactor(ctx context.Context, user *db.User, code, clientIP string) error {
+	totp, err := s.db.GetUserTOTP(ctx, user.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrTOTPNotEnabled
+		}
+		return fmt.Errorf("failed to get two-factor authentication: %w", err)
+	}
+	if !totp.Enabled {
+		return ErrTOTPNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) == totpDigits {
+		secret, err := decryptTOTPSecret(totp.Secret)
+		if err != nil {
+			return err
+		}
+		if step, ok := verifyTOTP(secret, code, time.Now(), totp.LastUsedStep); ok {
+			if err := s.db.UpdateUserTOTPLastUsedStep(ctx, &db.UpdateUserTOTPLastUsedStepParams{
+				LastUsedStep: step,
+				UserID:       user.ID,
+			}); err != nil {
+				return fmt.Errorf("failed to update two-factor authentication: %w", err)
+			}
+			return nil
+		}
+	} else {
+		used, err := s.db.UseRecoveryCode(ctx, &db.UseRecoveryCodeParams{
+			UserID:   user.ID,
+			CodeHash: hashRecoveryCode(code),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to use recovery code: %w", err)
+		}
+		if used == 1 {
+			s.logSecurityEvent(ctx, SecurityEvent{
+				Type:     SecurityEventRecoveryCodeUsed,
+				UserID:   user.ID,
+				Username: user.Username,
+				SourceIP: clientIP,
+				Success:  true,
+			})
+			return nil
+		}
+	}
+
+	s.recordLoginFailure(ctx, SecurityEventTOTPFailed, user.ID, user.Username, clientIP)
+	return ErrInvalidTOTPCode
+}
+
+// totpEnabled returns true if the user completed its two-factor authentication enrollment
+func (s *AuthService) totpEnabled(ctx context.Context, userID int64) (bool, error) {
+	totp, err := s.db.GetUserTOTP(ctx, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get two-factor authentication: %w", err)
+	}
+	return totp.Enabled, nil
+}
+
+func (s *AuthService) deleteTOTP(ctx context.Context, userID int64) error {
+	if err := s.db.DeleteUserTOTP(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete two-factor authentication: %w", err)
+	}
+	if err := s.db.DeleteRecoveryCodesByUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	return nil
+}
+
+// generateRecoveryCodes replaces the recovery codes of a user, only their hash is stored
+func (s *AuthService) generateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	if err := s.db.DeleteRecoveryCodesByUser(ctx, userID); err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		codes[i] = code[:4] + "-" + code[4:]
+		if err := s.db.CreateRecoveryCode(ctx, &db.CreateRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(codes[i]),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to save recovery code: %w", err)
+		}
+	}
+	return codes, nil
+}
+
+// decodeLoginChallenge verifies a login challenge and returns the user it belongs to
+func (s *AuthService) decodeLoginChallenge(ctx context.Context, value string) (*loginChallenge, *db.User, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 2 || !verifySessionID(parts[0], parts[1]) {
+		return nil, nil, ErrInvalidLoginChallenge
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, nil, ErrInvalidLoginChallenge
+	}
+	var login loginChallenge
+	if err := json.Unmarshal(payload, &login); err != nil {
+		return nil, nil, ErrInvalidLoginChallenge
+	}
+	if time.Now().After(login.ExpiresAt) {
+		return nil, nil, ErrInvalidLoginChallenge
+	}
+
+	user, err := s.db.GetUser(ctx, login.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, ErrInvalidLoginChallenge
+		}
+		return nil, nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &login, user, nil
+}
+
+func encodeLoginChallenge(login *loginChallenge) (string, error) {
+	payload, err := json.Marshal(login)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal login challenge: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signSessionID(encoded), nil
+}
+
+// generateTOTPSecret returns a random 160 bit secret encoded in base32 as expected by authenticator apps
+func generateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b), nil
+}
+
+// totpProvisioningURI returns the otpauth URI to render as a QR code for authenticator apps
+func totpProvisioningURI(secret, username string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", totpIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprintf("%d", totpDigits))
+	query.Set("period", fmt.Sprintf("%d", totpPeriod))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + totpIssuer + ":" + username,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// totpCode computes the code of a period as defined by RFC 6238 with HMAC-SHA1
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// verifyTOTP checks a code against the periods around now and returns the
+// matching period, periods up to lastStep are refused so a code works only once
+func verifyTOTP(secret, code string, now time.Time, lastStep int64) (int64, bool) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if hmac.Equal([]byte(totpCode(key, step)), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// hashRecoveryCode returns the hex encoded SHA-256 of a recovery code, ignoring case and separators
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// totpEncryptionKey derives the key encrypting TOTP secrets at rest from the session encryption key
+func totpEncryptionKey() []byte {
+	sum := sha256.Sum256(append([]byte("totp:"), getEncryptionKey()...))
+	return sum[:]
+}
+
+func encryptTOTPSecret(secret string) (string, error) {
+	block, err := aes.NewCipher(totpEncryptionKey())
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+func decryptTOTPSecret(encrypted string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	block, err := aes.NewCipher(totpEncryptionKey())
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cipher: %w", err)
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted secret")
+	}
+	secret, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		// The session encryption key changed since the user enrolled
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(secret), nil
+}
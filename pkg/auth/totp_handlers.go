@@ -0,0 +1,339 @@
+package auth
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/chainlaunch/chainlaunch/pkg/errors"
+	"github.com/chainlaunch/chainlaunch/pkg/http/response"
+	"github.com/go-chi/chi/v5"
+)
+
+// @Summary Complete login with two-factor authentication
+// @Description Completes a login started with /auth/login using a code from the authenticator app or a recovery code
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body LoginTOTPRequest true "Login challenge and code"
+// @Success 200 {object} LoginResponse "Login successful"
+// @Failure 400 {object} response.Response "Invalid code or expired login"
+// @Failure 429 {object} response.Response "Too many login attempts"
+// @Router /auth/login/totp [post]
+// @BasePath /api/v1
+func (h *Handler) LoginTOTPHandler(w http.ResponseWriter, r *http.Request) error {
+	var req LoginTOTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", nil)
+	}
+	if req.Challenge == "" || req.Code == "" {
+		return errors.NewValidationError("challenge and code are required", nil)
+	}
+
+	session, err := h.authService.CompleteLoginTOTP(r.Context(), req.Challenge, req.Code, h.authService.clientIP(r))
+	if err != nil {
+		log.Printf("Error completing two-factor login: %v", err)
+		return loginError(err)
+	}
+
+	setSessionCookie(w, r, session)
+	return response.WriteJSON(w, http.StatusOK, LoginResponse{
+		Message: "Login successful",
+	})
+}
+
+// @Summary Enroll two-factor authentication during login
+// @Description Returns a new secret for a user that must enroll two-factor authentication to log in
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body LoginEnrollRequest true "Login challenge"
+// @Success 200 {object} TOTPEnrollment "Secret and provisioning URI"
+// @Failure 400 {object} response.Response "Expired login"
+// @Failure 429 {object} response.Response "Too many login attempts"
+// @Router /auth/login/totp/enroll [post]
+// @BasePath /api/v1
+func (h *Handler) LoginEnrollTOTPHandler(w http.ResponseWriter, r *http.Request) error {
+	var req LoginEnrollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", nil)
+	}
+
+	enrollment, err := h.authService.EnrollTOTPForLogin(r.Context(), req.Challenge)
+	if err != nil {
+		return totpError(err)
+	}
+
+	return response.WriteJSON(w, http.StatusOK, enrollment)
+}
+
+// @Summary Confirm two-factor authentication enrollment during login
+// @Description Enables two-factor authentication with a first code, completes the login and returns the recovery codes
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body LoginTOTPRequest true "Login challenge and code"
+// @Success 200 {object} LoginEnrollmentResponse "Login successful"
+// @Failure 400 {object} response.Response "Invalid code or expired login"
+// @Failure 429 {object} response.Response "Too many login attempts"
+// @Router /auth/login/totp/enroll/confirm [post]
+// @BasePath /api/v1
+func (h *Handler) LoginConfirmTOTPHandler(w http.ResponseWriter, r *http.Request) error {
+	var req LoginTOTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", nil)
+	}
+	if req.Challenge == "" || req.Code == "" {
+		return errors.NewValidationError("challenge and code are required", nil)
+	}
+
+	session, recoveryCodes, err := h.authService.CompleteLoginEnrollment(r.Context(), req.Challenge, req.Code, h.authService.clientIP(r))
+	if err != nil {
+		return totpError(err)
+	}
+
+	setSessionCookie(w, r, session)
+	return response.WriteJSON(w, http.StatusOK, LoginEnrollmentResponse{
+		Message:       "Login successful",
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// @Summary Get two-factor authentication status
+// @Description Returns whether the current user has two-factor authentication enabled or required
+// @Tags Authentication
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {object} TOTPStatus "Two-factor authentication status"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /auth/totp [get]
+// @BasePath /api/v1
+func (h *Handler) GetTOTPStatusHandler(w http.ResponseWriter, r *http.Request) error {
+	session, ok := SessionFromContext(r.Context())
+	if !ok {
+		return errors.NewValidationError("unauthorized", nil)
+	}
+
+	status, err := h.authService.GetTOTPStatus(r.Context(), session.UserID)
+	if err != nil {
+		return totpError(err)
+	}
+
+	return response.WriteJSON(w, http.StatusOK, status)
+}
+
+// @Summary Enroll two-factor authentication
+// @Description Generates a new secret for the current user, it must be confirmed with /auth/totp/enable
+// @Tags Authentication
+// @Produce json
+// @Security CookieAuth
+// @Success 200 {object} TOTPEnrollment "Secret and provisioning URI"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 409 {object} response.Response "Two-factor authentication already enabled"
+// @Router /auth/totp/enroll [post]
+// @BasePath /api/v1
+func (h *Handler) EnrollTOTPHandler(w http.ResponseWriter, r *http.Request) error {
+	session, ok := SessionFromContext(r.Context())
+	if !ok {
+		return errors.NewValidationError("unauthorized", nil)
+	}
+
+	enrollment, err := h.authService.EnrollTOTP(r.Context(), session.UserID)
+	if err != nil {
+		return totpError(err)
+	}
+
+	return response.WriteJSON(w, http.StatusOK, enrollment)
+}
+
+// @Summary Enable two-factor authentication
+// @Description Confirms the enrollment with a code from the authenticator app and returns the recovery codes
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param request body TOTPCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} RecoveryCodesResponse "Recovery codes"
+// @Failure 400 {object} response.Response "Invalid code"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 409 {object} response.Response "Two-factor authentication already enabled"
+// @Router /auth/totp/enable [post]
+// @BasePath /api/v1
+func (h *Handler) EnableTOTPHandler(w http.ResponseWriter, r *http.Request) error {
+	session, ok := SessionFromContext(r.Context())
+	if !ok {
+		return errors.NewValidationError("unauthorized", nil)
+	}
+
+	var req TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", nil)
+	}
+
+	recoveryCodes, err := h.authService.EnableTOTP(r.Context(), session.UserID, req.Code, h.authService.clientIP(r))
+	if err != nil {
+		return totpError(err)
+	}
+
+	return response.WriteJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// @Summary Disable two-factor authentication
+// @Description Disables two-factor authentication of the current user, unless it is required by the policy
+// @Tags Authentication
+// @Accept json
+// @Security CookieAuth
+// @Param request body DisableTOTPRequest true "Password and code"
+// @Success 204 "Two-factor authentication disabled"
+// @Failure 400 {object} response.Response "Invalid password or code"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Two-factor authentication is required"
+// @Router /auth/totp/disable [post]
+// @BasePath /api/v1
+func (h *Handler) DisableTOTPHandler(w http.ResponseWriter, r *http.Request) error {
+	session, ok := SessionFromContext(r.Context())
+	if !ok {
+		return errors.NewValidationError("unauthorized", nil)
+	}
+
+	var req DisableTOTPRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", nil)
+	}
+
+	if err := h.authService.DisableTOTP(r.Context(), session.UserID, req.Password, req.Code, h.authService.clientIP(r)); err != nil {
+		return totpError(err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// @Summary Regenerate recovery codes
+// @Description Replaces the recovery codes of the current user, the previous codes stop working
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security CookieAuth
+// @Param request body TOTPCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} RecoveryCodesResponse "Recovery codes"
+// @Failure 400 {object} response.Response "Invalid code"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Router /auth/totp/recovery-codes [post]
+// @BasePath /api/v1
+func (h *Handler) RegenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) error {
+	session, ok := SessionFromContext(r.Context())
+	if !ok {
+		return errors.NewValidationError("unauthorized", nil)
+	}
+
+	var req TOTPCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", nil)
+	}
+
+	recoveryCodes, err := h.authService.RegenerateRecoveryCodes(r.Context(), session.UserID, req.Code, h.authService.clientIP(r))
+	if err != nil {
+		return totpError(err)
+	}
+
+	return response.WriteJSON(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: recoveryCodes})
+}
+
+// @Summary Reset two-factor authentication
+// @Description Removes two-factor authentication of a user who lost their authenticator and recovery codes (admin only)
+// @Tags Users
+// @Security CookieAuth
+// @Param id path int true "User ID"
+// @Success 204 "Two-factor authentication reset"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
+// @Failure 404 {object} response.Response "User not found"
+// @Router /users/{id}/totp [delete]
+// @BasePath /api/v1
+func (h *Handler) ResetTOTPHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := requireAdmin(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid user ID", nil)
+	}
+
+	if err := h.authService.ResetTOTP(r.Context(), userID, session.UserID); err != nil {
+		return totpError(err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// @Summary Unlock user
+// @Description Lifts the lockout of a user after too many failed logins (admin only)
+// @Tags Users
+// @Security CookieAuth
+// @Param id path int true "User ID"
+// @Success 204 "User unlocked"
+// @Failure 401 {object} response.Response "Unauthorized"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
+// @Failure 404 {object} response.Response "User not found"
+// @Router /users/{id}/unlock [post]
+// @BasePath /api/v1
+func (h *Handler) UnlockUserHandler(w http.ResponseWriter, r *http.Request) error {
+	session, err := requireAdmin(r)
+	if err != nil {
+		return err
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid user ID", nil)
+	}
+
+	if err := h.authService.UnlockUser(r.Context(), userID, session.UserID); err != nil {
+		return totpError(err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// setSessionCookie sets the signed session cookie of a successful login
+func setSessionCookie(w http.ResponseWriter, r *http.Request, session *Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    session.ID + "." + signSessionID(session.ID),
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// loginError converts a login error to an HTTP error without telling which credential was wrong
+func loginError(err error) error {
+	switch err {
+	case ErrLoginLocked, ErrInvalidLoginChallenge:
+		return errors.NewValidationError(err.Error(), nil)
+	}
+	return errors.NewValidationError("invalid credentials", nil)
+}
+
+// totpError converts a two-factor authentication error to an HTTP error
+func totpError(err error) error {
+	switch err {
+	case ErrUserNotFound:
+		return errors.NewNotFoundError("user not found", nil)
+	case ErrTOTPAlreadyEnabled:
+		return errors.NewConflictError(err.Error(), nil)
+	case ErrTOTPEnforced:
+		return errors.NewAuthorizationError(err.Error(), nil)
+	case ErrTOTPNotEnabled, ErrInvalidTOTPCode, ErrInvalidCredentials, ErrInvalidLoginChallenge, ErrLoginLocked:
+		return errors.NewValidationError(err.Error(), nil)
+	}
+	return err
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestTOTPCode(t *testing.T) {
+	// Test vectors of RFC 6238 appendix B for SHA-1, truncated to 6 digits
+	key := []byte("12345678901234567890")
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range cases {
+		if code := totpCode(key, unix/totpPeriod); code != expected {
+			t.Errorf("time %d: expected %s, got %s", unix, expected, code)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		t.Fatalf("failed to generate secret: %v", err)
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("failed to decode secret: %v", err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	current := now.Unix() / totpPeriod
+
+	step, ok := verifyTOTP(secret, totpCode(key, current), now, 0)
+	if !ok || step != current {
+		t.Fatalf("expected current code to be valid, got step %d (%v)", step, ok)
+	}
+	if _, ok := verifyTOTP(secret, totpCode(key, current-1), now, 0); !ok {
+		t.Error("expected previous code to be accepted for clock drift")
+	}
+	if _, ok := verifyTOTP(secret, totpCode(key, current-2), now, 0); ok {
+		t.Error("expected code two periods old to be rejected")
+	}
+	if _, ok := verifyTOTP(secret, totpCode(key, current), now, current); ok {
+		t.Error("expected code to be rejected once used")
+	}
+	if _, ok := verifyTOTP(secret, "12345", now, 0); ok {
+		t.Error("expected short code to be rejected")
+	}
+}
+
+func TestTOTPSecretEncryption(t *testing.T) {
+	encrypted, err := encryptTOTPSecret("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("failed to encrypt secret: %v", err)
+	}
+	if encrypted == "JBSWY3DPEHPK3PXP" {
+		t.Fatal("expected secret to be encrypted")
+	}
+	secret, err := decryptTOTPSecret(encrypted)
+	if err != nil || secret != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("expected secret to round trip, got %q (%v)", secret, err)
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	u, err := url.Parse(totpProvisioningURI("JBSWY3DPEHPK3PXP", "alice"))
+	if err != nil {
+		t.Fatalf("failed to parse URI: %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/ChainLaunch:alice" {
+		t.Errorf("unexpected URI %s", u)
+	}
+	if u.Query().Get("secret") != "JBSWY3DPEHPK3PXP" || u.Query().Get("issuer") != "ChainLaunch" {
+		t.Errorf("unexpected URI parameters %s", u.RawQuery)
+	}
+}
+
+func TestHashRecoveryCode(t *testing.T) {
+	if hashRecoveryCode("abcd-efgh") != hashRecoveryCode("ABCDEFGH") {
+		t.Error("expected recovery codes to ignore case and separators")
+	}
+}
+
+func TestLoginRateLimiter(t *testing.T) {
+	limiter := newLoginRateLimiter(3, time.Minute)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if ok, _ := limiter.allow("10.0.0.1", now); !ok {
+			t.Fatalf("request %d: expected to be allowed", i)
+		}
+	}
+	ok, retryAfter := limiter.allow("10.0.0.1", now)
+	if ok || retryAfter <= 0 {
+		t.Fatalf("expected fourth request to be limited, got %v (%s)", ok, retryAfter)
+	}
+	if ok, _ := limiter.allow("10.0.0.2", now); !ok {
+		t.Error("expected another client to be allowed")
+	}
+	if ok, _ := limiter.allow("10.0.0.1", now.Add(time.Minute+time.Second)); !ok {
+		t.Error("expected client to be allowed once the window passed")
+	}
+}
@@ -19,6 +19,9 @@ type User struct {
 	Role        Role
 	CreatedAt   time.Time
 	LastLoginAt time.Time
+	// MaxRole caps the roles granted by role bindings, it is set when an API
+	// token is restricted below the role of its account
+	MaxRole Role
 }
 
 // LoginRequest represents the login credentials
@@ -30,6 +33,72 @@ type LoginRequest struct {
 // LoginResponse represents the HTTP response for successful login
 type LoginResponse struct {
 	Message string `json:"message"`
+	// TOTPRequired is set when the login must be completed with a two-factor authentication code
+	TOTPRequired bool `json:"totp_required,omitempty"`
+	// TOTPEnrollmentRequired is set when the user must enroll two-factor authentication to log in
+	TOTPEnrollmentRequired bool `json:"totp_enrollment_required,omitempty"`
+	// Challenge identifies the login to complete, it expires after 5 minutes
+	Challenge string `json:"challenge,omitempty"`
+}
+
+// LoginResult is the outcome of a password login, either a session or a
+// challenge for the second factor
+type LoginResult struct {
+	Session            *Session
+	Challenge          string
+	EnrollmentRequired bool
+}
+
+// LoginTOTPRequest represents the second step of a login with two-factor authentication
+type LoginTOTPRequest struct {
+	Challenge string `json:"challenge" validate:"required"`
+	// Code is a code from the authenticator app or a recovery code
+	Code string `json:"code" validate:"required"`
+}
+
+// LoginEnrollRequest represents the request to enroll two-factor authentication during a login
+type LoginEnrollRequest struct {
+	Challenge string `json:"challenge" validate:"required"`
+}
+
+// LoginEnrollmentResponse represents the HTTP response for a login completed by enrolling two-factor authentication
+type LoginEnrollmentResponse struct {
+	Message string `json:"message"`
+	// RecoveryCodes are shown only once, each can replace a code from the authenticator app once
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// TOTPStatus represents the two-factor authentication status of a user
+type TOTPStatus struct {
+	Enabled bool `json:"enabled"`
+	// Required is set when the policy requires two-factor authentication for the user
+	Required               bool       `json:"required"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollment represents a new two-factor authentication secret to add to an authenticator app
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	// ProvisioningURI is the otpauth URI to render as a QR code
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// TOTPCodeRequest represents a request confirmed with a two-factor authentication code
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// DisableTOTPRequest represents the request to disable two-factor authentication
+type DisableTOTPRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// RecoveryCodesResponse represents the HTTP response with new recovery codes
+type RecoveryCodesResponse struct {
+	// RecoveryCodes are shown only once, each can replace a code from the authenticator app once
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 // LogoutResponse represents the HTTP response for successful logout
@@ -69,3 +138,47 @@ type Session struct {
 	CreatedAt time.Time
 	ExpiresAt time.Time
 }
+
+// ServiceAccount represents a non-human user that only authenticates with API tokens
+type ServiceAccount struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Role      Role      `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateServiceAccountRequest represents the request to create a service account
+type CreateServiceAccountRequest struct {
+	Name string `json:"name" validate:"required"`
+	Role Role   `json:"role" validate:"required,oneof=admin manager viewer"`
+}
+
+// APIToken represents a long-lived API token, the token itself is only known when it is created
+type APIToken struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Role        Role       `json:"role"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP  string     `json:"last_used_ip,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateAPITokenRequest represents the request to create an API token
+type CreateAPITokenRequest struct {
+	Name string `json:"name" validate:"required"`
+	// Role defaults to the role of the account and cannot be above it
+	Role Role `json:"role,omitempty" validate:"omitempty,oneof=admin manager viewer"`
+	// ExpiresInDays is the lifetime of the token, 0 means it never expires
+	ExpiresInDays int `json:"expires_in_days,omitempty"`
+}
+
+// CreateAPITokenResponse represents the HTTP response for a created API token
+type CreateAPITokenResponse struct {
+	APIToken
+	// Token is the secret to send as a Bearer token, it cannot be retrieved again
+	Token string `json:"token"`
+}
@@ -0,0 +1,115 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned by backends when a file doesn't exist in the repository
+var ErrNotFound = errors.New("file not found in repository")
+
+// Backend stores the files of a repository. Names are slash separated and
+// relative to the root of the repository, e.g. "snapshots/<id>".
+type Backend interface {
+	// Save writes a file, replacing it if it exists
+	Save(ctx context.Context, name string, data []byte) error
+	// Load reads a file, returning ErrNotFound if it doesn't exist
+	Load(ctx context.Context, name string) ([]byte, error)
+	// List returns the names of the files below a directory
+	List(ctx context.Context, dir string) ([]string, error)
+	// Remove deletes a file, it is not an error if the file doesn't exist
+	Remove(ctx context.Context, name string) error
+}
+
+// LocalBackend stores a repository in a directory of the local filesystem
+type LocalBackend struct {
+	root string
+}
+
+// NewLocalBackend creates a backend storing the repository in root
+func NewLocalBackend(root string) *LocalBackend {
+	return &LocalBackend{root: root}
+}
+
+func (b *LocalBackend) path(name string) string {
+	return filepath.Join(b.root, filepath.FromSlash(name))
+}
+
+// Save writes the file to a temporary file first so readers never see partial content
+func (b *LocalBackend) Save(ctx context.Context, name string, data []byte) error {
+	path := b.path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save %s: %w", name, err)
+	}
+	return nil
+}
+
+// Load reads a file of the repository
+func (b *LocalBackend) Load(ctx context.Context, name string) ([]byte, error) {
+	data, err := os.ReadFile(b.path(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// List returns the files below a directory of the repository
+func (b *LocalBackend) List(ctx context.Context, dir string) ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(b.path(dir), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	return names, nil
+}
+
+// Remove deletes a file of the repository
+func (b *LocalBackend) Remove(ctx context.Context, name string) error {
+	if err := os.Remove(b.path(name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", name, err)
+	}
+	return nil
+}
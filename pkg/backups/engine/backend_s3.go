@@ -0,0 +1,301 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config is the location and credentials of an S3 compatible bucket
+type S3Config struct {
+	// Endpoint is the base URL of the S3 service, e.g. https://s3.amazonaws.com
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is the directory of the repository in the bucket
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses the bucket in the path instead of the host name
+	PathStyle bool
+}
+
+// S3Backend stores a repository in an S3 compatible bucket using Signature Version 4
+type S3Backend struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Backend creates a backend storing the repository in an S3 bucket
+func NewS3Backend(config S3Config) (*S3Backend, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	endpoint := config.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3.amazonaws.com"
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint URL: %w", err)
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	config.Prefix = strings.Trim(config.Prefix, "/")
+	return &S3Backend{
+		config:   config,
+		endpoint: u,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+func (b *S3Backend) key(name string) string {
+	if b.config.Prefix == "" {
+		return name
+	}
+	return b.config.Prefix + "/" + name
+}
+
+// objectURL returns the URL of an object, or of the bucket when key is empty
+func (b *S3Backend) objectURL(key string, query url.Values) *url.URL {
+	u := *b.endpoint
+	basePath := strings.TrimSuffix(u.Path, "/")
+	if b.config.PathStyle {
+		u.Path = basePath + "/" + b.config.Bucket + "/" + key
+	} else {
+		u.Host = b.config.Bucket + "." + u.Host
+		u.Path = basePath + "/" + key
+	}
+	u.RawPath = ""
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+func (b *S3Backend) do(ctx context.Context, method string, u *url.URL, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	b.sign(req, body)
+	return b.client.Do(req)
+}
+
+// Save uploads an object
+func (b *S3Backend) Save(ctx context.Context, name string, data []byte) error {
+	resp, err := b.do(ctx, http.MethodPut, b.objectURL(b.key(name), nil), data)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to upload %s: %s", name, s3Error(resp))
+	}
+	return nil
+}
+
+// Load downloads an object
+func (b *S3Backend) Load(ctx context.Context, name string) ([]byte, error) {
+	resp, err := b.do(ctx, http.MethodGet, b.objectURL(b.key(name), nil), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", name, s3Error(resp))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	return data, nil
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the objects below a directory
+func (b *S3Backend) List(ctx context.Context, dir string) ([]string, error) {
+	prefix := b.key(dir) + "/"
+	var names []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := b.do(ctx, http.MethodGet, b.objectURL("", query), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := fmt.Errorf("failed to list %s: %s", dir, s3Error(resp))
+			resp.Body.Close()
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bucket listing: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			name := object.Key
+			if b.config.Prefix != "" {
+				name = strings.TrimPrefix(name, b.config.Prefix+"/")
+			}
+			names = append(names, name)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return names, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Remove deletes an object
+func (b *S3Backend) Remove(ctx context.Context, name string) error {
+	resp, err := b.do(ctx, http.MethodDelete, b.objectURL(b.key(name), nil), nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to delete %s: %s", name, s3Error(resp))
+	}
+	return nil
+}
+
+// s3Error extracts the error of an S3 response
+func s3Error(resp *http.Response) string {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Sprintf("%s (%s: %s)", resp.Status, body.Code, body.Message)
+	}
+	return resp.Status
+}
+
+// sign adds the AWS Signature Version 4 headers to the request
+func (b *S3Backend) sign(req *http.Request, body []byte) {
+	now := b.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + b.config.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+b.config.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, b.config.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query parameters sorted by name as required by Signature Version 4
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, uriEncode(key, true)+"="+uriEncode(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but unreserved characters, and slashes unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// CheckOptions are the options of a repository check
+type CheckOptions struct {
+	// ReadData downloads and decrypts every file of every snapshot and checks
+	// its hash, instead of only checking that the chunks exist
+	ReadData bool
+	// Snapshots limits the check to these snapshot IDs, all snapshots when empty
+	Snapshots []string
+}
+
+// CheckResult is the outcome of a repository check
+type CheckResult struct {
+	Snapshots     int      `json:"snapshots"`
+	Files         int      `json:"files"`
+	Chunks        int      `json:"chunks"`
+	MissingChunks int      `json:"missingChunks"`
+	Errors        []string `json:"errors,omitempty"`
+}
+
+// OK reports whether the check found no problem
+func (c *CheckResult) OK() bool {
+	return len(c.Errors) == 0
+}
+
+// Check verifies the integrity of the repository. Problems found in the
+// repository are listed in the result; the error is only set when the check
+// itself could not run.
+func (r *Repository) Check(ctx context.Context, opts CheckOptions) (*CheckResult, error) {
+	var snapshots []*Snapshot
+	if len(opts.Snapshots) == 0 {
+		var err error
+		if snapshots, err = r.Snapshots(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, id := range opts.Snapshots {
+			snapshot, err := r.Snapshot(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+
+	stored, err := r.chunkIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{Snapshots: len(snapshots)}
+	seen := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		for _, file := range snapshot.Files {
+			if file.Type != FileTypeFile {
+				continue
+			}
+			result.Files++
+
+			complete := true
+			for _, id := range file.Chunks {
+				if !seen[id] {
+					seen[id] = true
+					result.Chunks++
+					if !stored[id] {
+						result.MissingChunks++
+					}
+				}
+				if !stored[id] {
+					complete = false
+				}
+			}
+			if !complete {
+				result.Errors = append(result.Errors, fmt.Sprintf("snapshot %s: %s has missing chunks", snapshot.ID, file.Path))
+				continue
+			}
+
+			if opts.ReadData {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				if err := r.WriteFile(ctx, file, io.Discard); err != nil {
+					result.Errors = append(result.Errors, fmt.Sprintf("snapshot %s: %v", snapshot.ID, err))
+				}
+			}
+		}
+	}
+	return result, nil
+}
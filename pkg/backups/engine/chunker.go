@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"io"
+)
+
+// Default chunk sizes, chunks average about 1 MiB
+const (
+	defaultMinChunkSize = 256 << 10
+	defaultAvgChunkBits = 20
+	defaultMaxChunkSize = 4 << 20
+)
+
+// chunkerParams are the chunk sizes of a repository
+type chunkerParams struct {
+	MinSize int `json:"minSize"`
+	AvgBits int `json:"avgBits"`
+	MaxSize int `json:"maxSize"`
+}
+
+var defaultChunkerParams = chunkerParams{
+	MinSize: defaultMinChunkSize,
+	AvgBits: defaultAvgChunkBits,
+	MaxSize: defaultMaxChunkSize,
+}
+
+// chunker splits data into content-defined chunks with the FastCDC gear hash,
+// so an insertion only changes the chunks around it and the rest deduplicate
+type chunker struct {
+	gear   [256]uint64
+	min    int
+	avg    int
+	max    int
+	maskS  uint64
+	maskL  uint64
+	buffer []byte
+}
+
+func newChunker(params chunkerParams, seed uint64) *chunker {
+	c := &chunker{
+		min: params.MinSize,
+		avg: 1 << params.AvgBits,
+		max: params.MaxSize,
+		// Normalized chunking: harder to cut before the average size, easier after
+		maskS: ^uint64(0) << (64 - (params.AvgBits + 2)),
+		maskL: ^uint64(0) << (64 - (params.AvgBits - 2)),
+	}
+	// splitmix64 expands the seed into the gear table
+	state := seed
+	for i := range c.gear {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		c.gear[i] = z ^ (z >> 31)
+	}
+	return c
+}
+
+// cut returns the length of the first chunk of data
+func (c *chunker) cut(data []byte) int {
+	n := len(data)
+	if n <= c.min {
+		return n
+	}
+	if n > c.max {
+		n = c.max
+	}
+	normal := c.avg
+	if normal > n {
+		normal = n
+	}
+
+	var h uint64
+	i := c.min
+	for ; i < normal; i++ {
+		h = (h << 1) + c.gear[data[i]]
+		if h&c.maskS == 0 {
+			return i + 1
+		}
+	}
+	for ; i < n; i++ {
+		h = (h << 1) + c.gear[data[i]]
+		if h&c.maskL == 0 {
+			return i + 1
+		}
+	}
+	return n
+}
+
+// split reads r to the end and calls fn with each chunk. The chunk is only
+// valid until fn returns.
+func (c *chunker) split(r io.Reader, fn func(chunk []byte) error) error {
+	if c.buffer == nil {
+		c.buffer = make([]byte, c.max)
+	}
+	buf := c.buffer[:0]
+	eof := false
+	for {
+		for !eof && len(buf) < c.max {
+			n, err := r.Read(buf[len(buf):c.max])
+			buf = buf[:len(buf)+n]
+			if err == io.EOF {
+				eof = true
+			} else if err != nil {
+				return err
+			}
+		}
+		if len(buf) == 0 {
+			return nil
+		}
+
+		size := c.cut(buf)
+		if err := fn(buf[:size]); err != nil {
+			return err
+		}
+		buf = buf[:copy(buf, buf[size:])]
+	}
+}
@@ -0,0 +1,143 @@
+package engine
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Scrypt parameters used to derive the key protecting the master key
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// Payload encodings stored in the first byte of each encrypted blob
+const (
+	encodingRaw     byte = 0
+	encodingDeflate byte = 1
+)
+
+// errDecrypt is returned when a blob can't be authenticated with the key
+var errDecrypt = errors.New("failed to decrypt: wrong key or corrupted data")
+
+// masterKey holds the keys of a repository. It is stored in the repository
+// config encrypted with a key derived from the repository password.
+type masterKey struct {
+	// Encryption is the AES-256 key of chunks and snapshots
+	Encryption []byte `json:"encryption"`
+	// ID is the HMAC key used to compute chunk IDs, so IDs don't reveal the content hash
+	ID []byte `json:"id"`
+	// ChunkerSeed seeds the gear table so chunk boundaries differ between repositories
+	ChunkerSeed uint64 `json:"chunkerSeed"`
+}
+
+func newMasterKey() (*masterKey, error) {
+	key := &masterKey{
+		Encryption: make([]byte, 32),
+		ID:         make([]byte, 32),
+	}
+	if _, err := rand.Read(key.Encryption); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(key.ID); err != nil {
+		return nil, err
+	}
+	seed := make([]byte, 8)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, err
+	}
+	for _, b := range seed {
+		key.ChunkerSeed = key.ChunkerSeed<<8 | uint64(b)
+	}
+	return key, nil
+}
+
+// chunkID returns the ID of a chunk
+func (k *masterKey) chunkID(data []byte) string {
+	mac := hmac.New(sha256.New, k.ID)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func deriveKey(password string, salt []byte, n, r, p int) ([]byte, error) {
+	return scrypt.Key([]byte(password), salt, n, r, p, 32)
+}
+
+// seal encrypts and authenticates plaintext with AES-256-GCM, binding it to aad
+func seal(key, plaintext, aad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open decrypts data sealed with seal
+func open(key, data, aad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errDecrypt
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], aad)
+	if err != nil {
+		return nil, errDecrypt
+	}
+	return plaintext, nil
+}
+
+// encode compresses a payload when that makes it smaller and prefixes its encoding
+func encode(data []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(encodingDeflate)
+	w, _ := flate.NewWriter(&buf, flate.BestSpeed)
+	w.Write(data)
+	w.Close()
+	if buf.Len() < len(data)+1 {
+		return buf.Bytes()
+	}
+	return append([]byte{encodingRaw}, data...)
+}
+
+// decode reverses encode
+func decode(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty payload")
+	}
+	switch data[0] {
+	case encodingRaw:
+		return data[1:], nil
+	case encodingDeflate:
+		r := flate.NewReader(bytes.NewReader(data[1:]))
+		defer r.Close()
+		return io.ReadAll(r)
+	default:
+		return nil, fmt.Errorf("unknown payload encoding %d", data[0])
+	}
+}
@@ -0,0 +1,351 @@
+// Package engine implements the built-in backup engine: an encrypted,
+// deduplicating snapshot repository stored on a pluggable Backend.
+//
+// A repository holds a plaintext "config" file with the master key encrypted
+// with the repository password, content-defined chunks of file data under
+// "data/" and snapshot manifests under "snapshots/". Chunks and snapshots are
+// encrypted with AES-256-GCM and chunks are addressed by a keyed hash of
+// their content, so identical data is stored once across snapshots.
+package engine
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	repositoryVersion = 1
+	configFile        = "config"
+	dataDir           = "data"
+	snapshotsDir      = "snapshots"
+)
+
+var (
+	// ErrRepositoryNotFound is returned when opening a location without a repository
+	ErrRepositoryNotFound = errors.New("repository does not exist")
+	// ErrRepositoryExists is returned when initializing a location that already has a repository
+	ErrRepositoryExists = errors.New("repository already exists")
+	// ErrWrongPassword is returned when the password doesn't open the repository
+	ErrWrongPassword = errors.New("wrong repository password")
+	// ErrSnapshotNotFound is returned when a snapshot doesn't exist
+	ErrSnapshotNotFound = errors.New("snapshot not found")
+)
+
+// repositoryConfig is the plaintext config file of a repository
+type repositoryConfig struct {
+	Version   int           `json:"version"`
+	ID        string        `json:"id"`
+	CreatedAt time.Time     `json:"createdAt"`
+	KDF       kdfParams     `json:"kdf"`
+	Chunker   chunkerParams `json:"chunker"`
+	// Key is the master key encrypted with the key derived from the password
+	Key []byte `json:"key"`
+}
+
+type kdfParams struct {
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+// Repository is an open snapshot repository
+type Repository struct {
+	backend Backend
+	config  repositoryConfig
+	key     *masterKey
+}
+
+// Init creates a repository protected by password
+func Init(ctx context.Context, backend Backend, password string) (*Repository, error) {
+	return initRepository(ctx, backend, password, defaultChunkerParams)
+}
+
+func initRepository(ctx context.Context, backend Backend, password string, params chunkerParams) (*Repository, error) {
+	if password == "" {
+		return nil, fmt.Errorf("repository password is required")
+	}
+	if _, err := backend.Load(ctx, configFile); err == nil {
+		return nil, ErrRepositoryExists
+	} else if err != ErrNotFound {
+		return nil, fmt.Errorf("failed to check repository: %w", err)
+	}
+
+	key, err := newMasterKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate master key: %w", err)
+	}
+	config := repositoryConfig{
+		Version:   repositoryVersion,
+		ID:        randomID(),
+		CreatedAt: time.Now().UTC(),
+		KDF:       kdfParams{N: scryptN, R: scryptR, P: scryptP, Salt: make([]byte, 32)},
+		Chunker:   params,
+	}
+	if _, err := rand.Read(config.KDF.Salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	passwordKey, err := deriveKey(password, config.KDF.Salt, config.KDF.N, config.KDF.R, config.KDF.P)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	plainKey, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+	config.Key, err = seal(passwordKey, plainKey, []byte(config.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt master key: %w", err)
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := backend.Save(ctx, configFile, data); err != nil {
+		return nil, fmt.Errorf("failed to save repository config: %w", err)
+	}
+
+	return &Repository{backend: backend, config: config, key: key}, nil
+}
+
+// Open opens an existing repository with its password
+func Open(ctx context.Context, backend Backend, password string) (*Repository, error) {
+	data, err := backend.Load(ctx, configFile)
+	if err != nil {
+		if err == ErrNotFound {
+			return nil, ErrRepositoryNotFound
+		}
+		return nil, fmt.Errorf("failed to load repository config: %w", err)
+	}
+
+	var config repositoryConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse repository config: %w", err)
+	}
+	if config.Version != repositoryVersion {
+		return nil, fmt.Errorf("unsupported repository version %d", config.Version)
+	}
+
+	passwordKey, err := deriveKey(password, config.KDF.Salt, config.KDF.N, config.KDF.R, config.KDF.P)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	plainKey, err := open(passwordKey, config.Key, []byte(config.ID))
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+	var key masterKey
+	if err := json.Unmarshal(plainKey, &key); err != nil {
+		return nil, fmt.Errorf("failed to parse master key: %w", err)
+	}
+
+	return &Repository{backend: backend, config: config, key: &key}, nil
+}
+
+// OpenOrInit opens the repository, initializing it first if it doesn't exist
+func OpenOrInit(ctx context.Context, backend Backend, password string) (*Repository, error) {
+	repo, err := Open(ctx, backend, password)
+	if err == ErrRepositoryNotFound {
+		return Init(ctx, backend, password)
+	}
+	return repo, err
+}
+
+// ID returns the unique ID of the repository
+func (r *Repository) ID() string {
+	return r.config.ID
+}
+
+func chunkName(id string) string {
+	return fmt.Sprintf("%s/%s/%s", dataDir, id[:2], id)
+}
+
+func snapshotName(id string) string {
+	return fmt.Sprintf("%s/%s", snapshotsDir, id)
+}
+
+// saveChunk stores a chunk and returns the number of bytes written to the backend
+func (r *Repository) saveChunk(ctx context.Context, id string, data []byte) (int64, error) {
+	sealed, err := seal(r.key.Encryption, encode(data), []byte(id))
+	if err != nil {
+		return 0, fmt.Errorf("failed to encrypt chunk: %w", err)
+	}
+	if err := r.backend.Save(ctx, chunkName(id), sealed); err != nil {
+		return 0, err
+	}
+	return int64(len(sealed)), nil
+}
+
+// loadChunk reads a chunk and checks that its content matches its ID
+func (r *Repository) loadChunk(ctx context.Context, id string) ([]byte, error) {
+	sealed, err := r.backend.Load(ctx, chunkName(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chunk %s: %w", id, err)
+	}
+	encoded, err := open(r.key.Encryption, sealed, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("chunk %s: %w", id, err)
+	}
+	data, err := decode(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("chunk %s: %w", id, err)
+	}
+	if r.key.chunkID(data) != id {
+		return nil, fmt.Errorf("chunk %s: content does not match its ID", id)
+	}
+	return data, nil
+}
+
+// chunkIDs returns the IDs of all chunks stored in the repository
+func (r *Repository) chunkIDs(ctx context.Context) (map[string]bool, error) {
+	names, err := r.backend.List(ctx, dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks: %w", err)
+	}
+	ids := make(map[string]bool, len(names))
+	for _, name := range names {
+		ids[name[strings.LastIndex(name, "/")+1:]] = true
+	}
+	return ids, nil
+}
+
+func (r *Repository) saveSnapshot(ctx context.Context, snapshot *Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	sealed, err := seal(r.key.Encryption, encode(data), []byte(snapshot.ID))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt snapshot: %w", err)
+	}
+	if err := r.backend.Save(ctx, snapshotName(snapshot.ID), sealed); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return nil
+}
+
+// Snapshot loads a snapshot by ID, "latest" loads the most recent one
+func (r *Repository) Snapshot(ctx context.Context, id string) (*Snapshot, error) {
+	if id == "latest" {
+		snapshots, err := r.Snapshots(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(snapshots) == 0 {
+			return nil, ErrSnapshotNotFound
+		}
+		return snapshots[0], nil
+	}
+
+	sealed, err := r.backend.Load(ctx, snapshotName(id))
+	if err != nil {
+		if err == ErrNotFound {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, fmt.Errorf("failed to load snapshot %s: %w", id, err)
+	}
+	encoded, err := open(r.key.Encryption, sealed, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", id, err)
+	}
+	data, err := decode(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", id, err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", id, err)
+	}
+	return &snapshot, nil
+}
+
+// Snapshots returns all snapshots of the repository, most recent first
+func (r *Repository) Snapshots(ctx context.Context) ([]*Snapshot, error) {
+	names, err := r.backend.List(ctx, snapshotsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	snapshots := make([]*Snapshot, 0, len(names))
+	for _, name := range names {
+		snapshot, err := r.Snapshot(ctx, strings.TrimPrefix(name, snapshotsDir+"/"))
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.After(snapshots[j].Time)
+	})
+	return snapshots, nil
+}
+
+// Forget removes snapshots. The chunks they used stay in the repository until Prune.
+func (r *Repository) Forget(ctx context.Context, ids ...string) error {
+	for _, id := range ids {
+		if _, err := r.backend.Load(ctx, snapshotName(id)); err != nil {
+			if err == ErrNotFound {
+				return fmt.Errorf("%w: %s", ErrSnapshotNotFound, id)
+			}
+			return fmt.Errorf("failed to load snapshot %s: %w", id, err)
+		}
+		if err := r.backend.Remove(ctx, snapshotName(id)); err != nil {
+			return fmt.Errorf("failed to remove snapshot %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// PruneResult describes the chunks removed by Prune
+type PruneResult struct {
+	RemovedChunks int `json:"removedChunks"`
+	KeptChunks    int `json:"keptChunks"`
+}
+
+// Prune removes the chunks no snapshot refers to. It must not run while a
+// backup is writing to the repository.
+func (r *Repository) Prune(ctx context.Context) (*PruneResult, error) {
+	snapshots, err := r.Snapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		for _, file := range snapshot.Files {
+			for _, id := range file.Chunks {
+				used[id] = true
+			}
+		}
+	}
+
+	stored, err := r.chunkIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &PruneResult{}
+	for id := range stored {
+		if used[id] {
+			result.KeptChunks++
+			continue
+		}
+		if err := r.backend.Remove(ctx, chunkName(id)); err != nil {
+			return nil, fmt.Errorf("failed to remove chunk %s: %w", id, err)
+		}
+		result.RemovedChunks++
+	}
+	return result, nil
+}
+
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RestoreOptions are the options of a restore
+type RestoreOptions struct {
+	// Root restores only the entry at this snapshot path and what is below it,
+	// written relative to the target: a directory becomes the target directory
+	// and a file is written to the target path
+	Root string
+	// Include restores only the paths for which it returns true, all paths when nil
+	Include func(path string) bool
+}
+
+// RestoreResult describes what a restore wrote
+type RestoreResult struct {
+	Files int   `json:"files"`
+	Dirs  int   `json:"dirs"`
+	Bytes int64 `json:"bytes"`
+}
+
+// Restore writes the files of a snapshot below target. Existing files are
+// replaced, every file is checked against the hash recorded at backup time.
+func (r *Repository) Restore(ctx context.Context, snapshot *Snapshot, target string, opts RestoreOptions) (*RestoreResult, error) {
+	files := make([]File, 0, len(snapshot.Files))
+	for _, file := range snapshot.Files {
+		if !validPath(file.Path) {
+			return nil, fmt.Errorf("snapshot contains an invalid path %q", file.Path)
+		}
+		if opts.Root != "" && file.Path != opts.Root && !strings.HasPrefix(file.Path, opts.Root+"/") {
+			continue
+		}
+		if opts.Include == nil || opts.Include(file.Path) {
+			files = append(files, file)
+		}
+	}
+	destination := func(file File) string {
+		if opts.Root != "" {
+			return filepath.Join(target, filepath.FromSlash(strings.TrimPrefix(file.Path, opts.Root)))
+		}
+		return filepath.Join(target, filepath.FromSlash(file.Path))
+	}
+	// Parents first so directories exist before their content
+	sort.SliceStable(files, func(i, j int) bool {
+		return strings.Count(files[i].Path, "/") < strings.Count(files[j].Path, "/")
+	})
+
+	result := &RestoreResult{}
+	var dirs, symlinks []File
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		dest := destination(file)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", file.Path, err)
+		}
+
+		switch file.Type {
+		case FileTypeDir:
+			if err := os.MkdirAll(dest, 0700); err != nil {
+				return nil, fmt.Errorf("failed to create directory %s: %w", file.Path, err)
+			}
+			dirs = append(dirs, file)
+			result.Dirs++
+		case FileTypeSymlink:
+			symlinks = append(symlinks, file)
+		case FileTypeFile:
+			if err := r.restoreFile(ctx, file, dest); err != nil {
+				return nil, err
+			}
+			result.Files++
+			result.Bytes += file.Size
+		}
+	}
+
+	// Symlinks once everything else is written, so no file is written through one
+	for _, file := range symlinks {
+		dest := destination(file)
+		os.RemoveAll(dest)
+		if err := os.Symlink(file.LinkTarget, dest); err != nil {
+			return nil, fmt.Errorf("failed to create symlink %s: %w", file.Path, err)
+		}
+	}
+
+	// Directory permissions and times last, writing their content changes them
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dest := destination(dirs[i])
+		os.Chmod(dest, dirs[i].Mode)
+		os.Chtimes(dest, dirs[i].ModTime, dirs[i].ModTime)
+	}
+	return result, nil
+}
+
+// restoreFile writes a file next to its destination and moves it in place once verified
+func (r *Repository) restoreFile(ctx context.Context, file File, dest string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".restore-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", file.Path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := r.WriteFile(ctx, file, tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file.Path, err)
+	}
+	if err := os.Chmod(tmp.Name(), file.Mode); err != nil {
+		return fmt.Errorf("failed to set mode of %s: %w", file.Path, err)
+	}
+	os.Chtimes(tmp.Name(), file.ModTime, file.ModTime)
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", file.Path, err)
+	}
+	return nil
+}
+
+// WriteFile writes the content of a snapshot file to w and checks its hash
+func (r *Repository) WriteFile(ctx context.Context, file File, w io.Writer) error {
+	if file.Type != FileTypeFile {
+		return fmt.Errorf("%s is not a regular file", file.Path)
+	}
+	hash := sha256.New()
+	out := io.MultiWriter(w, hash)
+	var size int64
+	for _, id := range file.Chunks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		data, err := r.loadChunk(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.Path, err)
+		}
+		if _, err := out.Write(data); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.Path, err)
+		}
+		size += int64(len(data))
+	}
+	if size != file.Size || hex.EncodeToString(hash.Sum(nil)) != file.SHA256 {
+		return fmt.Errorf("%s does not match the hash recorded in the snapshot", file.Path)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RetentionPolicy selects the snapshots to keep, grandfather-father-son style.
+// A snapshot is kept when any rule selects it. The zero policy keeps everything.
+type RetentionPolicy struct {
+	// KeepLast keeps the newest snapshots
+	KeepLast int
+	// KeepDaily, KeepWeekly and KeepMonthly keep the newest snapshot of that
+	// many days, ISO weeks and months which have one
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+	// KeepWithin keeps every snapshot taken within that duration before now
+	KeepWithin time.Duration
+}
+
+// Empty reports whether the policy has no rule, in which case nothing is removed
+func (p RetentionPolicy) Empty() bool {
+	return p.KeepLast <= 0 && p.KeepDaily <= 0 && p.KeepWeekly <= 0 && p.KeepMonthly <= 0 && p.KeepWithin <= 0
+}
+
+// RetentionDecision is the outcome of a policy for one snapshot
+type RetentionDecision struct {
+	Keep bool `json:"keep"`
+	// Reasons names the rules which keep the snapshot
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// retentionBucket keeps the newest snapshot of count periods
+type retentionBucket struct {
+	reason string
+	count  int
+	period func(time.Time) string
+	last   string
+}
+
+// Apply decides which of the snapshots taken at times are kept. Decisions are
+// returned in the order of times. The newest snapshot is always kept.
+func (p RetentionPolicy) Apply(times []time.Time, now time.Time) []RetentionDecision {
+	decisions := make([]RetentionDecision, len(times))
+	if p.Empty() {
+		for i := range decisions {
+			decisions[i] = RetentionDecision{Keep: true, Reasons: []string{"no policy"}}
+		}
+		return decisions
+	}
+
+	order := make([]int, len(times))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return times[order[a]].After(times[order[b]])
+	})
+
+	buckets := []*retentionBucket{
+		{reason: "last", count: p.KeepLast, period: func(t time.Time) string {
+			return t.Format(time.RFC3339Nano)
+		}},
+		{reason: "daily", count: p.KeepDaily, period: func(t time.Time) string {
+			return t.Format("2006-01-02")
+		}},
+		{reason: "weekly", count: p.KeepWeekly, period: func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{reason: "monthly", count: p.KeepMonthly, period: func(t time.Time) string {
+			return t.Format("2006-01")
+		}},
+	}
+
+	for n, i := range order {
+		t := times[i].UTC()
+		decision := &decisions[i]
+		if n == 0 {
+			decision.Reasons = append(decision.Reasons, "newest")
+		}
+		if p.KeepWithin > 0 && now.Sub(times[i]) <= p.KeepWithin {
+			decision.Reasons = append(decision.Reasons, "within")
+		}
+		for _, bucket := range buckets {
+			if bucket.count <= 0 {
+				continue
+			}
+			period := bucket.period(t)
+			// Snapshots are visited newest first, the first of a period is its newest
+			if period == bucket.last {
+				continue
+			}
+			bucket.last = period
+			bucket.count--
+			decision.Reasons = append(decision.Reasons, bucket.reason)
+		}
+		decision.Keep = len(decision.Reasons) > 0
+	}
+	return decisions
+}
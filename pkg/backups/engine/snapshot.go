@@ -0,0 +1,229 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FileType is the type of an entry of a snapshot
+type FileType string
+
+const (
+	FileTypeFile    FileType = "file"
+	FileTypeDir     FileType = "dir"
+	FileTypeSymlink FileType = "symlink"
+)
+
+// Snapshot is the manifest of a backup: every file it holds and the chunks of their content
+type Snapshot struct {
+	ID       string          `json:"id"`
+	Time     time.Time       `json:"time"`
+	Hostname string          `json:"hostname"`
+	Tags     []string        `json:"tags,omitempty"`
+	Sources  []string        `json:"sources"`
+	Files    []File          `json:"files"`
+	Summary  SnapshotSummary `json:"summary"`
+}
+
+// File is an entry of a snapshot
+type File struct {
+	// Path is slash separated and starts with the name of its source
+	Path       string      `json:"path"`
+	Type       FileType    `json:"type"`
+	Mode       fs.FileMode `json:"mode"`
+	Size       int64       `json:"size,omitempty"`
+	ModTime    time.Time   `json:"modTime"`
+	LinkTarget string      `json:"linkTarget,omitempty"`
+	Chunks     []string    `json:"chunks,omitempty"`
+	// SHA256 is the hash of the whole content, checked on restore and verification
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+// SnapshotSummary holds statistics of a snapshot
+type SnapshotSummary struct {
+	Files      int   `json:"files"`
+	Dirs       int   `json:"dirs"`
+	TotalBytes int64 `json:"totalBytes"`
+	// NewChunks and AddedBytes count what this snapshot added to the repository after deduplication
+	NewChunks  int   `json:"newChunks"`
+	AddedBytes int64 `json:"addedBytes"`
+}
+
+// Source is a file or directory to back up
+type Source struct {
+	// Name is the top-level path of the source in the snapshot
+	Name string
+	// Path is the file or directory on disk
+	Path string
+	// Exclude skips paths, relative to Path and slash separated, for which it returns true
+	Exclude func(rel string) bool
+}
+
+// BackupOptions are the options of a backup
+type BackupOptions struct {
+	Hostname string
+	Tags     []string
+	// Progress is called after each file is stored
+	Progress func(file File)
+}
+
+// Backup stores the sources as a new snapshot
+func (r *Repository) Backup(ctx context.Context, sources []Source, opts BackupOptions) (*Snapshot, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no sources to back up")
+	}
+	names := make(map[string]bool)
+	for _, source := range sources {
+		if !validPath(source.Name) || strings.Contains(source.Name, "/") {
+			return nil, fmt.Errorf("invalid source name %q", source.Name)
+		}
+		if names[source.Name] {
+			return nil, fmt.Errorf("duplicate source name %q", source.Name)
+		}
+		names[source.Name] = true
+	}
+
+	known, err := r.chunkIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname := opts.Hostname
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	snapshot := &Snapshot{
+		ID:       randomID(),
+		Time:     time.Now().UTC(),
+		Hostname: hostname,
+		Tags:     opts.Tags,
+	}
+
+	c := newChunker(r.config.Chunker, r.key.ChunkerSeed)
+	for _, source := range sources {
+		snapshot.Sources = append(snapshot.Sources, source.Name)
+		err := filepath.WalkDir(source.Path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(source.Path, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if rel != "." && source.Exclude != nil && source.Exclude(rel) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			file := File{
+				Path:    source.Name,
+				Mode:    info.Mode().Perm(),
+				ModTime: info.ModTime().UTC(),
+			}
+			if rel != "." {
+				file.Path = path.Join(source.Name, rel)
+			}
+
+			switch {
+			case info.Mode().IsRegular():
+				file.Type = FileTypeFile
+				if err := r.storeFile(ctx, c, p, &file, known, &snapshot.Summary); err != nil {
+					return err
+				}
+				snapshot.Summary.Files++
+				snapshot.Summary.TotalBytes += file.Size
+			case info.IsDir():
+				file.Type = FileTypeDir
+				snapshot.Summary.Dirs++
+			case info.Mode()&fs.ModeSymlink != 0:
+				file.Type = FileTypeSymlink
+				if file.LinkTarget, err = os.Readlink(p); err != nil {
+					return err
+				}
+			default:
+				// Sockets, devices and pipes can't be restored meaningfully
+				return nil
+			}
+
+			snapshot.Files = append(snapshot.Files, file)
+			if opts.Progress != nil {
+				opts.Progress(file)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up %s: %w", source.Path, err)
+		}
+	}
+
+	if err := r.saveSnapshot(ctx, snapshot); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// storeFile chunks a file and stores the chunks the repository doesn't have yet
+func (r *Repository) storeFile(ctx context.Context, c *chunker, p string, file *File, known map[string]bool, summary *SnapshotSummary) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	err = c.split(f, func(chunk []byte) error {
+		hash.Write(chunk)
+		file.Size += int64(len(chunk))
+
+		id := r.key.chunkID(chunk)
+		file.Chunks = append(file.Chunks, id)
+		if known[id] {
+			return nil
+		}
+		written, err := r.saveChunk(ctx, id, chunk)
+		if err != nil {
+			return err
+		}
+		known[id] = true
+		summary.NewChunks++
+		summary.AddedBytes += written
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store %s: %w", p, err)
+	}
+	file.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+// validPath reports whether a snapshot path is relative and stays inside the restore target
+func validPath(p string) bool {
+	if p == "" || strings.HasPrefix(p, "/") || strings.Contains(p, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(p, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -1,11 +1,14 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
+	stderrors "errors"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
 	"github.com/chainlaunch/chainlaunch/pkg/backups/service"
 	"github.com/chainlaunch/chainlaunch/pkg/errors"
 	"github.com/chainlaunch/chainlaunch/pkg/http/response"
@@ -28,12 +31,13 @@ func NewHandler(service *service.BackupService) *Handler {
 // RegisterRoutes registers the backup routes
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Route("/backups", func(r chi.Router) {
-		// Backup targets
-		r.Post("/targets", response.Middleware(h.CreateBackupTarget))
+		// Backup targets hold credentials and decide where data leaves the instance
+		r.With(auth.RequireRole(auth.RoleAdmin)).Post("/targets", response.Middleware(h.CreateBackupTarget))
 		r.Get("/targets", response.Middleware(h.ListBackupTargets))
 		r.Get("/targets/{id}", response.Middleware(h.GetBackupTarget))
-		r.Delete("/targets/{id}", response.Middleware(h.DeleteBackupTarget))
-		r.Put("/targets/{id}", response.Middleware(h.UpdateBackupTarget))
+		r.With(auth.RequireRole(auth.RoleAdmin)).Delete("/targets/{id}", response.Middleware(h.DeleteBackupTarget))
+		r.With(auth.RequireRole(auth.RoleAdmin)).Put("/targets/{id}", response.Middleware(h.UpdateBackupTarget))
+		r.Post("/targets/{id}/check", response.Middleware(h.CheckBackupTarget))
 
 		// Backup schedules
 		r.Post("/schedules", response.Middleware(h.CreateBackupSchedule))
@@ -43,24 +47,33 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Put("/schedules/{id}/disable", response.Middleware(h.DisableBackupSchedule))
 		r.Delete("/schedules/{id}", response.Middleware(h.DeleteBackupSchedule))
 		r.Put("/schedules/{id}", response.Middleware(h.UpdateBackupSchedule))
+		r.Get("/schedules/{id}/retention", response.Middleware(h.PreviewBackupRetention))
+		// Enforcing retention deletes snapshots for good
+		r.With(auth.RequireRole(auth.RoleAdmin)).Post("/schedules/{id}/retention", response.Middleware(h.EnforceBackupRetention))
 
 		// Backups
 		r.Get("/", response.Middleware(h.ListBackups))
 		r.Post("/", response.Middleware(h.CreateBackup))
+		// Restoring replaces the state of the whole instance
+		r.With(auth.RequireRole(auth.RoleAdmin)).Post("/restore", response.Middleware(h.RestoreBackup))
 		r.Get("/{id}", response.Middleware(h.GetBackup))
-		r.Delete("/{id}", response.Middleware(h.DeleteBackup))
+		// Verifying reads the whole repository and restores the backup into a scratch directory
+		r.With(auth.RequireRole(auth.RoleManager)).Post("/{id}/verify", response.Middleware(h.VerifyBackup))
+		// Deleting a backup removes its snapshot from the target
+		r.With(auth.RequireRole(auth.RoleAdmin)).Delete("/{id}", response.Middleware(h.DeleteBackup))
 	})
 }
 
 // CreateBackupTarget godoc
 // @Summary Create a new backup target
-// @Description Create a new backup target with the specified configuration
+// @Description Create a new S3, local filesystem or SFTP backup target. The target is only saved once its repository could be reached.
 // @Tags Backup Targets
 // @Accept json
 // @Produce json
 // @Param request body CreateBackupTargetRequest true "Backup target creation request"
 // @Success 201 {object} BackupTargetResponse
-// @Failure 400 {object} response.Response "Validation error"
+// @Failure 400 {object} response.Response "Validation error or target not reachable"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /backups/targets [post]
 func (h *Handler) CreateBackupTarget(w http.ResponseWriter, r *http.Request) error {
@@ -85,17 +98,29 @@ func (h *Handler) CreateBackupTarget(w http.ResponseWriter, r *http.Request) err
 	}
 
 	target, err := h.service.CreateBackupTarget(r.Context(), service.CreateBackupTargetParams{
-		Name:           req.Name,
-		Type:           service.BackupTargetType(req.Type),
-		BucketName:     req.BucketName,
-		Region:         req.Region,
-		Endpoint:       req.Endpoint,
-		BucketPath:     req.BucketPath,
-		AccessKeyID:    req.AccessKeyID,
-		SecretKey:      req.SecretKey,
-		ForcePathStyle: req.ForcePathStyle,
+		Name:               req.Name,
+		Type:               service.BackupTargetType(req.Type),
+		Engine:             service.BackupEngine(req.Engine),
+		BucketName:         req.BucketName,
+		Region:             req.Region,
+		Endpoint:           req.Endpoint,
+		BucketPath:         req.BucketPath,
+		AccessKeyID:        req.AccessKeyID,
+		SecretKey:          req.SecretKey,
+		ForcePathStyle:     req.ForcePathStyle,
+		LocalPath:          req.LocalPath,
+		SFTPHost:           req.SFTPHost,
+		SFTPPort:           req.SFTPPort,
+		SFTPUsername:       req.SFTPUsername,
+		SFTPPrivateKey:     req.SFTPPrivateKey,
+		SFTPPath:           req.SFTPPath,
+		SFTPHostKey:        req.SFTPHostKey,
+		RepositoryPassword: req.RepositoryPassword,
 	})
 	if err != nil {
+		if targetErr := backupTargetError(err); targetErr != nil {
+			return targetErr
+		}
 		if strings.Contains(err.Error(), "already exists") {
 			return errors.NewConflictError("backup target already exists", map[string]interface{}{
 				"detail": err.Error(),
@@ -167,6 +192,54 @@ func (h *Handler) GetBackupTarget(w http.ResponseWriter, r *http.Request) error
 	return response.WriteJSON(w, http.StatusOK, toBackupTargetResponse(target))
 }
 
+// CheckBackupTarget godoc
+// @Summary Check the integrity of a backup target
+// @Description Verify that every snapshot of the target repository is complete. With readData the content of every file is downloaded and checked against its hash.
+// @Tags Backup Targets
+// @Accept json
+// @Produce json
+// @Param id path int true "Backup Target ID"
+// @Param readData query bool false "Download and verify the content of every file"
+// @Success 200 {object} BackupTargetCheckResponse
+// @Failure 400 {object} response.Response "Invalid ID format or target not reachable"
+// @Failure 404 {object} response.Response "Target not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /backups/targets/{id}/check [post]
+func (h *Handler) CheckBackupTarget(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid backup target ID", map[string]interface{}{
+			"detail": err.Error(),
+			"code":   "INVALID_ID_FORMAT",
+		})
+	}
+	readData, _ := strconv.ParseBool(r.URL.Query().Get("readData"))
+
+	result, err := h.service.CheckBackupTarget(r.Context(), id, readData)
+	if err != nil {
+		if err == service.ErrTargetNotFound {
+			return errors.NewNotFoundError("backup target not found", map[string]interface{}{
+				"detail":    "The requested backup target does not exist",
+				"code":      "TARGET_NOT_FOUND",
+				"target_id": id,
+			})
+		}
+		if targetErr := backupTargetError(err); targetErr != nil {
+			return targetErr
+		}
+		return errors.NewInternalError("failed to check backup target", err, nil)
+	}
+
+	return response.WriteJSON(w, http.StatusOK, BackupTargetCheckResponse{
+		OK:            result.OK(),
+		Snapshots:     result.Snapshots,
+		Files:         result.Files,
+		Chunks:        result.Chunks,
+		MissingChunks: result.MissingChunks,
+		Errors:        result.Errors,
+	})
+}
+
 // DeleteBackupTarget godoc
 // @Summary Delete a backup target
 // @Description Delete a backup target and all associated backups
@@ -177,6 +250,7 @@ func (h *Handler) GetBackupTarget(w http.ResponseWriter, r *http.Request) error
 // @Success 204 "No Content"
 // @Failure 400 {object} response.Response "Invalid ID format"
 // @Failure 404 {object} response.Response "Target not found"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /backups/targets/{id} [delete]
 func (h *Handler) DeleteBackupTarget(w http.ResponseWriter, r *http.Request) error {
@@ -211,6 +285,7 @@ func (h *Handler) DeleteBackupTarget(w http.ResponseWriter, r *http.Request) err
 // @Param request body CreateBackupScheduleRequest true "Backup schedule creation request"
 // @Success 201 {object} BackupScheduleResponse
 // @Failure 400 {object} response.Response "Validation error"
+// @Failure 403 {object} response.Response "Forbidden - Requires manager role on the backed up nodes"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /backups/schedules [post]
 func (h *Handler) CreateBackupSchedule(w http.ResponseWriter, r *http.Request) error {
@@ -234,15 +309,32 @@ func (h *Handler) CreateBackupSchedule(w http.ResponseWriter, r *http.Request) e
 		})
 	}
 
+	if err := authorizeBackupScope(r.Context(), service.BackupScope(req.Scope), req.NodeIDs, req.NetworkID); err != nil {
+		return err
+	}
+
 	schedule, err := h.service.CreateBackupSchedule(r.Context(), service.CreateBackupScheduleParams{
-		Name:           req.Name,
-		Description:    req.Description,
-		CronExpression: req.CronExpression,
-		TargetID:       req.TargetID,
-		RetentionDays:  req.RetentionDays,
-		Enabled:        req.Enabled,
+		Name:                 req.Name,
+		Description:          req.Description,
+		CronExpression:       req.CronExpression,
+		TargetID:             req.TargetID,
+		RetentionDays:        req.RetentionDays,
+		KeepLast:             req.KeepLast,
+		KeepDaily:            req.KeepDaily,
+		KeepWeekly:           req.KeepWeekly,
+		KeepMonthly:          req.KeepMonthly,
+		Enabled:              req.Enabled,
+		Scope:                service.BackupScope(req.Scope),
+		NodeIDs:              req.NodeIDs,
+		NetworkID:            req.NetworkID,
+		Consistency:          service.ConsistencyStrategy(req.Consistency),
+		StopOrder:            req.StopOrder,
+		VerifyCronExpression: req.VerifyCronExpression,
 	})
 	if err != nil {
+		if stderrors.Is(err, service.ErrInvalidSchedule) {
+			return scheduleError(err)
+		}
 		if strings.Contains(err.Error(), "invalid cron expression") {
 			return errors.NewValidationError("invalid cron expression", map[string]interface{}{
 				"detail": err.Error(),
@@ -324,6 +416,7 @@ func (h *Handler) GetBackupSchedule(w http.ResponseWriter, r *http.Request) erro
 // @Success 200 {object} BackupScheduleResponse
 // @Failure 400 {object} response.Response "Validation error"
 // @Failure 404 {object} response.Response "Schedule not found"
+// @Failure 403 {object} response.Response "Forbidden - Requires manager role on the backed up nodes"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /backups/schedules/{id}/enable [put]
 func (h *Handler) EnableBackupSchedule(w http.ResponseWriter, r *http.Request) error {
@@ -335,6 +428,10 @@ func (h *Handler) EnableBackupSchedule(w http.ResponseWriter, r *http.Request) e
 		})
 	}
 
+	if err := h.authorizeSchedule(r.Context(), id); err != nil {
+		return err
+	}
+
 	schedule, err := h.service.EnableBackupSchedule(r.Context(), id)
 	if err != nil {
 		if err == service.ErrScheduleNotFound {
@@ -360,6 +457,7 @@ func (h *Handler) EnableBackupSchedule(w http.ResponseWriter, r *http.Request) e
 // @Success 200 {object} BackupScheduleResponse
 // @Failure 400 {object} response.Response "Validation error"
 // @Failure 404 {object} response.Response "Schedule not found"
+// @Failure 403 {object} response.Response "Forbidden - Requires manager role on the backed up nodes"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /backups/schedules/{id}/disable [put]
 func (h *Handler) DisableBackupSchedule(w http.ResponseWriter, r *http.Request) error {
@@ -371,6 +469,10 @@ func (h *Handler) DisableBackupSchedule(w http.ResponseWriter, r *http.Request)
 		})
 	}
 
+	if err := h.authorizeSchedule(r.Context(), id); err != nil {
+		return err
+	}
+
 	schedule, err := h.service.DisableBackupSchedule(r.Context(), id)
 	if err != nil {
 		if err == service.ErrScheduleNotFound {
@@ -396,6 +498,7 @@ func (h *Handler) DisableBackupSchedule(w http.ResponseWriter, r *http.Request)
 // @Success 204 "No Content"
 // @Failure 400 {object} response.Response "Invalid ID format"
 // @Failure 404 {object} response.Response "Schedule not found"
+// @Failure 403 {object} response.Response "Forbidden - Requires manager role on the backed up nodes"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /backups/schedules/{id} [delete]
 func (h *Handler) DeleteBackupSchedule(w http.ResponseWriter, r *http.Request) error {
@@ -407,6 +510,10 @@ func (h *Handler) DeleteBackupSchedule(w http.ResponseWriter, r *http.Request) e
 		})
 	}
 
+	if err := h.authorizeSchedule(r.Context(), id); err != nil {
+		return err
+	}
+
 	if err := h.service.DeleteBackupSchedule(r.Context(), id); err != nil {
 		if err == service.ErrScheduleNotFound {
 			return errors.NewNotFoundError("backup schedule not found", map[string]interface{}{
@@ -453,6 +560,7 @@ func (h *Handler) ListBackups(w http.ResponseWriter, r *http.Request) error {
 // @Param request body CreateBackupRequest true "Backup creation request"
 // @Success 201 {object} BackupResponse
 // @Failure 400 {object} response.Response "Validation error"
+// @Failure 403 {object} response.Response "Forbidden - Requires manager role on the backed up nodes"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /backups [post]
 func (h *Handler) CreateBackup(w http.ResponseWriter, r *http.Request) error {
@@ -476,6 +584,15 @@ func (h *Handler) CreateBackup(w http.ResponseWriter, r *http.Request) error {
 		})
 	}
 
+	// A backup run from a schedule covers what the schedule covers, any other backup the whole instance
+	if req.ScheduleID != nil {
+		if err := h.authorizeSchedule(r.Context(), *req.ScheduleID); err != nil {
+			return err
+		}
+	} else if err := authorizeBackupScope(r.Context(), service.BackupScopeAll, nil, 0); err != nil {
+		return err
+	}
+
 	// Convert metadata to string if present
 	var metadataStr *string
 	if req.Metadata != nil {
@@ -538,6 +655,51 @@ func (h *Handler) GetBackup(w http.ResponseWriter, r *http.Request) error {
 	return response.WriteJSON(w, http.StatusOK, toBackupResponse(backup))
 }
 
+// VerifyBackup godoc
+// @Summary Verify a backup
+// @Description Check the repository of the target of a completed backup and restore the backup to a scratch directory,
+// @Description where the SQLite copy must pass PRAGMA integrity_check and the MSP directories of the Fabric nodes must be intact.
+// @Description The report is recorded on the backup and a failed verification is notified like a failed backup.
+// @Tags Backups
+// @Accept json
+// @Produce json
+// @Param id path int true "Backup ID"
+// @Success 200 {object} VerificationResponse
+// @Failure 400 {object} response.Response "Invalid ID format or backup not completed"
+// @Failure 404 {object} response.Response "Backup not found"
+// @Failure 403 {object} response.Response "Forbidden - Requires manager role"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /backups/{id}/verify [post]
+func (h *Handler) VerifyBackup(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid backup ID", map[string]interface{}{
+			"detail": err.Error(),
+			"code":   "INVALID_ID_FORMAT",
+		})
+	}
+
+	report, err := h.service.VerifyBackup(r.Context(), id)
+	if err != nil {
+		switch {
+		case stderrors.Is(err, service.ErrBackupNotFound):
+			return errors.NewNotFoundError("backup not found", map[string]interface{}{
+				"detail":    "The requested backup does not exist",
+				"code":      "BACKUP_NOT_FOUND",
+				"backup_id": id,
+			})
+		case stderrors.Is(err, service.ErrSnapshotUnavailable):
+			return errors.NewValidationError("backup can't be verified", map[string]interface{}{
+				"detail": err.Error(),
+				"code":   "SNAPSHOT_UNAVAILABLE",
+			})
+		}
+		return errors.NewInternalError("failed to verify backup", err, nil)
+	}
+
+	return response.WriteJSON(w, http.StatusOK, toVerificationResponse(report))
+}
+
 // DeleteBackup godoc
 // @Summary Delete a backup
 // @Description Delete a backup and its associated files
@@ -548,6 +710,7 @@ func (h *Handler) GetBackup(w http.ResponseWriter, r *http.Request) error {
 // @Success 204 "No Content"
 // @Failure 400 {object} response.Response "Invalid ID format"
 // @Failure 404 {object} response.Response "Backup not found"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /backups/{id} [delete]
 func (h *Handler) DeleteBackup(w http.ResponseWriter, r *http.Request) error {
@@ -573,6 +736,83 @@ func (h *Handler) DeleteBackup(w http.ResponseWriter, r *http.Request) error {
 	return response.WriteJSON(w, http.StatusNoContent, nil)
 }
 
+// RestoreBackup godoc
+// @Summary Restore a backup
+// @Description Restore the whole backup, the data directory of a node, the MSP directory and keys of an organization, or the database only.
+// @Description The backup is given by ID, or is the latest completed backup of a target at or before a point in time.
+// @Description Unless skipped, the current state is backed up first and the affected nodes are stopped during the restore.
+// @Description With dryRun the files and rows the restore would change are returned without changing anything.
+// @Tags Backups
+// @Accept json
+// @Produce json
+// @Param request body RestoreBackupRequest true "Restore request"
+// @Success 200 {object} RestoreBackupResponse
+// @Failure 400 {object} response.Response "Validation error or backup not restorable"
+// @Failure 404 {object} response.Response "Backup not found"
+// @Failure 409 {object} response.Response "Another restore is in progress"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /backups/restore [post]
+func (h *Handler) RestoreBackup(w http.ResponseWriter, r *http.Request) error {
+	var req RestoreBackupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", map[string]interface{}{
+			"detail": err.Error(),
+			"code":   "INVALID_REQUEST_BODY",
+		})
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		validationErrors := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			validationErrors[err.Field()] = err.Tag()
+		}
+		return errors.NewValidationError("validation failed", map[string]interface{}{
+			"detail": "Request validation failed",
+			"code":   "VALIDATION_ERROR",
+			"errors": validationErrors,
+		})
+	}
+
+	result, err := h.service.Restore(r.Context(), service.RestoreParams{
+		BackupID:             req.BackupID,
+		TargetID:             req.TargetID,
+		PointInTime:          req.PointInTime,
+		Scope:                service.RestoreScope(req.Scope),
+		NodeID:               req.NodeID,
+		OrganizationID:       req.OrganizationID,
+		DryRun:               req.DryRun,
+		SkipPreRestoreBackup: req.SkipPreRestoreBackup,
+		KeepNodesStopped:     req.KeepNodesStopped,
+	})
+	if err != nil {
+		switch {
+		case stderrors.Is(err, service.ErrBackupNotFound):
+			return errors.NewNotFoundError("backup not found", map[string]interface{}{
+				"detail": err.Error(),
+				"code":   "BACKUP_NOT_FOUND",
+			})
+		case stderrors.Is(err, service.ErrRestoreInProgress):
+			return errors.NewConflictError("a restore is already in progress", map[string]interface{}{
+				"detail": err.Error(),
+				"code":   "RESTORE_IN_PROGRESS",
+			})
+		case stderrors.Is(err, service.ErrInvalidRestoreRequest):
+			return errors.NewValidationError("invalid restore request", map[string]interface{}{
+				"detail": err.Error(),
+				"code":   "INVALID_RESTORE_REQUEST",
+			})
+		case stderrors.Is(err, service.ErrSnapshotUnavailable):
+			return errors.NewValidationError("backup snapshot is not available", map[string]interface{}{
+				"detail": err.Error(),
+				"code":   "SNAPSHOT_UNAVAILABLE",
+			})
+		}
+		return errors.NewInternalError("failed to restore backup", err, nil)
+	}
+
+	return response.WriteJSON(w, http.StatusOK, toRestoreBackupResponse(result))
+}
+
 // UpdateBackupTarget godoc
 // @Summary Update a backup target
 // @Description Update an existing backup target with new configuration
@@ -582,8 +822,9 @@ func (h *Handler) DeleteBackup(w http.ResponseWriter, r *http.Request) error {
 // @Param id path int true "Backup Target ID"
 // @Param request body UpdateBackupTargetRequest true "Backup target update request"
 // @Success 200 {object} BackupTargetResponse
-// @Failure 400 {object} response.Response "Validation error"
+// @Failure 400 {object} response.Response "Validation error or target not reachable"
 // @Failure 404 {object} response.Response "Target not found"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /backups/targets/{id} [put]
 func (h *Handler) UpdateBackupTarget(w http.ResponseWriter, r *http.Request) error {
@@ -619,6 +860,7 @@ func (h *Handler) UpdateBackupTarget(w http.ResponseWriter, r *http.Request) err
 		ID:             id,
 		Name:           req.Name,
 		Type:           service.BackupTargetType(req.Type),
+		Engine:         service.BackupEngine(req.Engine),
 		BucketName:     req.BucketName,
 		Region:         req.Region,
 		Endpoint:       req.Endpoint,
@@ -626,8 +868,18 @@ func (h *Handler) UpdateBackupTarget(w http.ResponseWriter, r *http.Request) err
 		AccessKeyID:    req.AccessKeyID,
 		SecretKey:      req.SecretKey,
 		ForcePathStyle: req.ForcePathStyle,
+		LocalPath:      req.LocalPath,
+		SFTPHost:       req.SFTPHost,
+		SFTPPort:       req.SFTPPort,
+		SFTPUsername:   req.SFTPUsername,
+		SFTPPrivateKey: req.SFTPPrivateKey,
+		SFTPPath:       req.SFTPPath,
+		SFTPHostKey:    req.SFTPHostKey,
 	})
 	if err != nil {
+		if targetErr := backupTargetError(err); targetErr != nil {
+			return targetErr
+		}
 		if strings.Contains(err.Error(), "not found") {
 			return errors.NewNotFoundError("backup target not found", map[string]interface{}{
 				"detail":    "The requested backup target does not exist",
@@ -652,6 +904,7 @@ func (h *Handler) UpdateBackupTarget(w http.ResponseWriter, r *http.Request) err
 // @Success 200 {object} BackupScheduleResponse
 // @Failure 400 {object} response.Response "Validation error"
 // @Failure 404 {object} response.Response "Schedule not found"
+// @Failure 403 {object} response.Response "Forbidden - Requires manager role on the backed up nodes"
 // @Failure 500 {object} response.Response "Internal server error"
 // @Router /backups/schedules/{id} [put]
 func (h *Handler) UpdateBackupSchedule(w http.ResponseWriter, r *http.Request) error {
@@ -683,14 +936,31 @@ func (h *Handler) UpdateBackupSchedule(w http.ResponseWriter, r *http.Request) e
 		})
 	}
 
+	if err := h.authorizeSchedule(r.Context(), id); err != nil {
+		return err
+	}
+	if err := authorizeBackupScope(r.Context(), service.BackupScope(req.Scope), req.NodeIDs, req.NetworkID); err != nil {
+		return err
+	}
+
 	schedule, err := h.service.UpdateBackupSchedule(r.Context(), service.UpdateBackupScheduleParams{
-		ID:             id,
-		Name:           req.Name,
-		Description:    req.Description,
-		CronExpression: req.CronExpression,
-		TargetID:       req.TargetID,
-		RetentionDays:  req.RetentionDays,
-		Enabled:        req.Enabled,
+		ID:                   id,
+		Name:                 req.Name,
+		Description:          req.Description,
+		CronExpression:       req.CronExpression,
+		TargetID:             req.TargetID,
+		RetentionDays:        req.RetentionDays,
+		KeepLast:             req.KeepLast,
+		KeepDaily:            req.KeepDaily,
+		KeepWeekly:           req.KeepWeekly,
+		KeepMonthly:          req.KeepMonthly,
+		Enabled:              req.Enabled,
+		Scope:                service.BackupScope(req.Scope),
+		NodeIDs:              req.NodeIDs,
+		NetworkID:            req.NetworkID,
+		Consistency:          service.ConsistencyStrategy(req.Consistency),
+		StopOrder:            req.StopOrder,
+		VerifyCronExpression: req.VerifyCronExpression,
 	})
 	if err != nil {
 		if err == service.ErrScheduleNotFound {
@@ -700,24 +970,180 @@ func (h *Handler) UpdateBackupSchedule(w http.ResponseWriter, r *http.Request) e
 				"schedule_id": id,
 			})
 		}
+		if stderrors.Is(err, service.ErrInvalidSchedule) {
+			return scheduleError(err)
+		}
 		return errors.NewInternalError("failed to update backup schedule", err, nil)
 	}
 
 	return response.WriteJSON(w, http.StatusOK, toBackupScheduleResponse(schedule))
 }
 
+// PreviewBackupRetention godoc
+// @Summary Preview the retention of a backup schedule
+// @Description List the backups of a schedule with whether its retention policy keeps them, without deleting anything.
+// @Description Every backup of the last retention days is kept, older completed backups only when a keepLast, keepDaily, keepWeekly or keepMonthly rule selects them.
+// @Tags Backup Schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} RetentionResponse
+// @Failure 400 {object} response.Response "Invalid ID format"
+// @Failure 404 {object} response.Response "Schedule not found"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /backups/schedules/{id}/retention [get]
+func (h *Handler) PreviewBackupRetention(w http.ResponseWriter, r *http.Request) error {
+	return h.backupRetention(w, r, true)
+}
+
+// EnforceBackupRetention godoc
+// @Summary Enforce the retention of a backup schedule
+// @Description Delete the snapshots and records of the backups of a schedule its retention policy no longer keeps.
+// @Description Retention is also enforced after every successful scheduled backup.
+// @Tags Backup Schedules
+// @Accept json
+// @Produce json
+// @Param id path int true "Schedule ID"
+// @Success 200 {object} RetentionResponse
+// @Failure 400 {object} response.Response "Invalid ID format"
+// @Failure 404 {object} response.Response "Schedule not found"
+// @Failure 403 {object} response.Response "Forbidden - Requires admin role"
+// @Failure 500 {object} response.Response "Internal server error"
+// @Router /backups/schedules/{id}/retention [post]
+func (h *Handler) EnforceBackupRetention(w http.ResponseWriter, r *http.Request) error {
+	return h.backupRetention(w, r, false)
+}
+
+// authorizeBackupScope requires the manager role on what a backup covers.
+// Node backups may stop the nodes they copy, so they need the role on every
+// node, or on the network for network backups, and whole-instance backups
+// need the global role.
+func authorizeBackupScope(ctx context.Context, scope service.BackupScope, nodeIDs []int64, networkID int64) error {
+	var err error
+	switch scope {
+	case service.BackupScopeNodes:
+		for _, nodeID := range nodeIDs {
+			if err = auth.Authorize(ctx, auth.ResourceNode, nodeID, auth.RoleManager); err != nil {
+				break
+			}
+		}
+	case service.BackupScopeNetwork:
+		err = auth.Authorize(ctx, auth.ResourceNetwork, networkID, auth.RoleManager)
+	default:
+		err = auth.Authorize(ctx, "", 0, auth.RoleManager)
+	}
+	if err != nil {
+		return auth.AuthorizationError(err)
+	}
+	return nil
+}
+
+// authorizeSchedule requires the manager role on what a stored schedule covers
+func (h *Handler) authorizeSchedule(ctx context.Context, id int64) error {
+	schedule, err := h.service.GetBackupSchedule(ctx, id)
+	if err != nil {
+		if err == service.ErrScheduleNotFound {
+			return errors.NewNotFoundError("backup schedule not found", map[string]interface{}{
+				"detail":      "The requested backup schedule does not exist",
+				"code":        "SCHEDULE_NOT_FOUND",
+				"schedule_id": id,
+			})
+		}
+		return errors.NewInternalError("failed to get backup schedule", err, nil)
+	}
+	var networkID int64
+	if schedule.NetworkID != nil {
+		networkID = *schedule.NetworkID
+	}
+	return authorizeBackupScope(ctx, schedule.Scope, schedule.NodeIDs, networkID)
+}
+
+func (h *Handler) backupRetention(w http.ResponseWriter, r *http.Request, dryRun bool) error {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid schedule ID", map[string]interface{}{
+			"detail": err.Error(),
+			"code":   "INVALID_ID_FORMAT",
+		})
+	}
+
+	result, err := h.service.EnforceRetention(r.Context(), id, dryRun)
+	if err != nil {
+		if err == service.ErrScheduleNotFound {
+			return errors.NewNotFoundError("backup schedule not found", map[string]interface{}{
+				"detail":      "The requested backup schedule does not exist",
+				"code":        "SCHEDULE_NOT_FOUND",
+				"schedule_id": id,
+			})
+		}
+		return errors.NewInternalError("failed to enforce backup retention", err, nil)
+	}
+
+	resp := RetentionResponse{
+		ScheduleID: result.ScheduleID,
+		DryRun:     result.DryRun,
+		Kept:       result.Kept,
+		Deleted:    result.Deleted,
+		Backups:    make([]RetentionBackupResponse, len(result.Backups)),
+	}
+	for i, backup := range result.Backups {
+		resp.Backups[i] = RetentionBackupResponse{
+			BackupID:   backup.BackupID,
+			TargetID:   backup.TargetID,
+			SnapshotID: backup.SnapshotID,
+			Status:     string(backup.Status),
+			StartedAt:  backup.StartedAt,
+			Keep:       backup.Keep,
+			Reasons:    backup.Reasons,
+		}
+	}
+	return response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// backupTargetError converts configuration and connectivity errors of a backup target to HTTP errors
+func backupTargetError(err error) error {
+	switch {
+	case stderrors.Is(err, service.ErrInvalidTargetConfig):
+		return errors.NewValidationError("invalid backup target configuration", map[string]interface{}{
+			"detail": err.Error(),
+			"code":   "INVALID_TARGET_CONFIG",
+		})
+	case stderrors.Is(err, service.ErrTargetUnreachable):
+		return errors.NewValidationError("backup target is not reachable", map[string]interface{}{
+			"detail": err.Error(),
+			"code":   "TARGET_UNREACHABLE",
+		})
+	}
+	return nil
+}
+
+// scheduleError converts an invalid node selection of a schedule to a validation error
+func scheduleError(err error) error {
+	return errors.NewValidationError("invalid backup schedule", map[string]interface{}{
+		"detail": err.Error(),
+		"code":   "INVALID_SCHEDULE",
+	})
+}
+
 // Helper functions to convert service DTOs to HTTP responses
 func toBackupTargetResponse(target *service.BackupTargetDTO) BackupTargetResponse {
 	return BackupTargetResponse{
 		ID:             target.ID,
 		Name:           target.Name,
 		Type:           string(target.Type),
+		Engine:         string(target.Engine),
 		BucketName:     target.BucketName,
 		Region:         target.Region,
 		Endpoint:       target.Endpoint,
 		BucketPath:     target.BucketPath,
 		AccessKeyID:    target.AccessKeyID,
 		ForcePathStyle: target.ForcePathStyle,
+		LocalPath:      target.LocalPath,
+		SFTPHost:       target.SFTPHost,
+		SFTPPort:       target.SFTPPort,
+		SFTPUsername:   target.SFTPUsername,
+		SFTPPath:       target.SFTPPath,
+		SFTPHostKey:    target.SFTPHostKey,
 		CreatedAt:      target.CreatedAt,
 		UpdatedAt:      target.UpdatedAt,
 	}
@@ -725,31 +1151,110 @@ func toBackupTargetResponse(target *service.BackupTargetDTO) BackupTargetRespons
 
 func toBackupScheduleResponse(schedule *service.BackupScheduleDTO) BackupScheduleResponse {
 	return BackupScheduleResponse{
-		ID:             schedule.ID,
-		Name:           schedule.Name,
-		Description:    schedule.Description,
-		CronExpression: schedule.CronExpression,
-		TargetID:       schedule.TargetID,
-		RetentionDays:  schedule.RetentionDays,
-		Enabled:        schedule.Enabled,
-		CreatedAt:      schedule.CreatedAt,
-		UpdatedAt:      schedule.UpdatedAt,
-		LastRunAt:      schedule.LastRunAt,
-		NextRunAt:      schedule.NextRunAt,
+		ID:                   schedule.ID,
+		Name:                 schedule.Name,
+		Description:          schedule.Description,
+		CronExpression:       schedule.CronExpression,
+		TargetID:             schedule.TargetID,
+		RetentionDays:        schedule.RetentionDays,
+		KeepLast:             schedule.KeepLast,
+		KeepDaily:            schedule.KeepDaily,
+		KeepWeekly:           schedule.KeepWeekly,
+		KeepMonthly:          schedule.KeepMonthly,
+		Enabled:              schedule.Enabled,
+		CreatedAt:            schedule.CreatedAt,
+		UpdatedAt:            schedule.UpdatedAt,
+		LastRunAt:            schedule.LastRunAt,
+		NextRunAt:            schedule.NextRunAt,
+		Scope:                string(schedule.Scope),
+		NodeIDs:              schedule.NodeIDs,
+		NetworkID:            schedule.NetworkID,
+		Consistency:          string(schedule.Consistency),
+		StopOrder:            schedule.StopOrder,
+		VerifyCronExpression: schedule.VerifyCronExpression,
 	}
 }
 
 func toBackupResponse(backup *service.BackupDTO) BackupResponse {
-	return BackupResponse{
+	resp := BackupResponse{
 		ID:           backup.ID,
 		ScheduleID:   backup.ScheduleID,
 		TargetID:     backup.TargetID,
 		Status:       string(backup.Status),
 		SizeBytes:    backup.SizeBytes,
+		SnapshotID:   backup.SnapshotID,
 		StartedAt:    backup.StartedAt,
 		CompletedAt:  backup.CompletedAt,
 		ErrorMessage: backup.ErrorMessage,
 		Metadata:     backup.Metadata,
 		CreatedAt:    backup.CreatedAt,
 	}
+	for _, node := range backup.Nodes {
+		nodeResp := NodeBackupResponse{
+			NodeID:      node.NodeID,
+			Name:        node.Name,
+			NodeType:    node.NodeType,
+			Mode:        node.Mode,
+			DataDir:     node.DataDir,
+			Running:     node.Running,
+			Strategy:    string(node.Strategy),
+			StoppedAt:   node.StoppedAt,
+			RestartedAt: node.RestartedAt,
+			Error:       node.Error,
+		}
+		for _, snapshot := range node.LedgerSnapshots {
+			nodeResp.LedgerSnapshots = append(nodeResp.LedgerSnapshots, LedgerSnapshotResponse(snapshot))
+		}
+		for _, export := range node.DockerExports {
+			nodeResp.DockerExports = append(nodeResp.DockerExports, DockerExportResponse(export))
+		}
+		resp.Nodes = append(resp.Nodes, nodeResp)
+	}
+	if backup.Verification != nil {
+		verification := toVerificationResponse(backup.Verification)
+		resp.Verification = &verification
+	}
+	return resp
+}
+
+func toVerificationResponse(report *service.VerificationDTO) VerificationResponse {
+	resp := VerificationResponse{
+		BackupID:   report.BackupID,
+		Status:     string(report.Status),
+		VerifiedAt: report.VerifiedAt,
+		Checks:     make([]VerificationCheckResponse, len(report.Checks)),
+	}
+	for i, check := range report.Checks {
+		resp.Checks[i] = VerificationCheckResponse(check)
+	}
+	return resp
+}
+
+func toRestoreBackupResponse(result *service.RestoreResultDTO) RestoreBackupResponse {
+	resp := RestoreBackupResponse{
+		BackupID:           result.BackupID,
+		SnapshotID:         result.SnapshotID,
+		Scope:              string(result.Scope),
+		DryRun:             result.DryRun,
+		Changes:            make([]RestoreChangeResponse, len(result.Changes)),
+		PreRestoreBackupID: result.PreRestoreBackupID,
+		StoppedNodes:       result.StoppedNodes,
+		StartedNodes:       result.StartedNodes,
+		Errors:             result.Errors,
+	}
+	for i, change := range result.Changes {
+		resp.Changes[i] = RestoreChangeResponse{
+			Path:   change.Path,
+			Change: string(change.Change),
+			Size:   change.Size,
+		}
+	}
+	for _, table := range result.Tables {
+		resp.Tables = append(resp.Tables, RestoreTableChangeResponse{
+			Table:        table.Table,
+			CurrentRows:  table.CurrentRows,
+			SnapshotRows: table.SnapshotRows,
+		})
+	}
+	return resp
 }
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// tablesKeptOnRestore are never overwritten by a database restore: the schema
+// version has to match the running binary, and the backup history has to
+// survive the restore that uses it
+var tablesKeptOnRestore = map[string]bool{
+	"schema_migrations": true,
+	"backup_targets":    true,
+	"backup_schedules":  true,
+	"backups":           true,
+}
+
+// tableSelection is a set of rows of a table restored from a snapshot database
+type tableSelection struct {
+	table string
+	// where filters the rows of both databases, all rows when empty
+	where string
+	args  []interface{}
+}
+
+// snapshotDatabase is a connection to the live database with the database of
+// a snapshot attached under the name "snapshot"
+type snapshotDatabase struct {
+	db   *sql.DB
+	conn *sql.Conn
+}
+
+// attachSnapshotDatabase opens the live database and attaches the snapshot database at path
+func (s *BackupService) attachSnapshotDatabase(ctx context.Context, path string) (*snapshotDatabase, error) {
+	database, err := sql.Open("sqlite3", s.databasePath+"?_busy_timeout=10000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	conn, err := database.Conn(ctx)
+	if err != nil {
+		database.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	sdb := &snapshotDatabase{db: database, conn: conn}
+
+	// Rows are replaced table by table, references only hold once all are written
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		sdb.Close()
+		return nil, fmt.Errorf("failed to disable foreign keys: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, "ATTACH DATABASE ? AS snapshot", path); err != nil {
+		sdb.Close()
+		return nil, fmt.Errorf("failed to attach snapshot database: %w", err)
+	}
+	return sdb, nil
+}
+
+// Close detaches the snapshot database and closes the connection
+func (d *snapshotDatabase) Close() {
+	d.conn.ExecContext(context.Background(), "DETACH DATABASE snapshot")
+	d.conn.ExecContext(context.Background(), "PRAGMA foreign_keys = ON")
+	d.conn.Close()
+	d.db.Close()
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// tables lists the tables of a schema
+func (d *snapshotDatabase) tables(ctx context.Context, schema string) ([]string, error) {
+	rows, err := d.conn.QueryContext(ctx, fmt.Sprintf(
+		"SELECT name FROM %s.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%%' ORDER BY name", schema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+	var tables []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tables = append(tables, name)
+	}
+	return tables, rows.Err()
+}
+
+// columns returns the columns a table has in both databases, in the order of the live one
+func (d *snapshotDatabase) columns(ctx context.Context, table string) ([]string, error) {
+	read := func(schema string) ([]string, error) {
+		rows, err := d.conn.QueryContext(ctx, "SELECT name FROM pragma_table_info(?, ?)", table, schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+		}
+		defer rows.Close()
+		var columns []string
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return nil, err
+			}
+			columns = append(columns, name)
+		}
+		return columns, rows.Err()
+	}
+
+	live, err := read("main")
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := read("snapshot")
+	if err != nil {
+		return nil, err
+	}
+	inSnapshot := make(map[string]bool, len(snapshot))
+	for _, column := range snapshot {
+		inSnapshot[column] = true
+	}
+	var columns []string
+	for _, column := range live {
+		if inSnapshot[column] {
+			columns = append(columns, quoteIdentifier(column))
+		}
+	}
+	return columns, nil
+}
+
+// fullSelections selects every row of the tables both databases have, but the kept ones
+func (d *snapshotDatabase) fullSelections(ctx context.Context) ([]tableSelection, error) {
+	live, err := d.tables(ctx, "main")
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := d.tables(ctx, "snapshot")
+	if err != nil {
+		return nil, err
+	}
+	inSnapshot := make(map[string]bool, len(snapshot))
+	for _, table := range snapshot {
+		inSnapshot[table] = true
+	}
+	var selections []tableSelection
+	for _, table := range live {
+		if inSnapshot[table] && !tablesKeptOnRestore[table] {
+			selections = append(selections, tableSelection{table: table})
+		}
+	}
+	return selections, nil
+}
+
+// organizationSelections selects an organization and the keys it references in the snapshot
+func organizationSelections(organizationID int64) []tableSelection {
+	return []tableSelection{
+		{
+			table: "fabric_organizations",
+			where: "id = ?",
+			args:  []interface{}{organizationID},
+		},
+		{
+			table: "keys",
+			where: `id IN (
+				SELECT sign_key_id FROM snapshot.fabric_organizations WHERE id = ?
+				UNION SELECT tls_root_key_id FROM snapshot.fabric_organizations WHERE id = ?
+				UNION SELECT admin_tls_key_id FROM snapshot.fabric_organizations WHERE id = ?
+				UNION SELECT admin_sign_key_id FROM snapshot.fabric_organizations WHERE id = ?
+				UNION SELECT client_sign_key_id FROM snapshot.fabric_organizations WHERE id = ?
+			)`,
+			args: []interface{}{organizationID, organizationID, organizationID, organizationID, organizationID},
+		},
+	}
+}
+
+func (t tableSelection) clause() string {
+	if t.where == "" {
+		return ""
+	}
+	return " WHERE " + t.where
+}
+
+// compare counts the selected rows in the live database and in the snapshot
+func (d *snapshotDatabase) compare(ctx context.Context, selections []tableSelection) ([]RestoreTableChange, error) {
+	changes := make([]RestoreTableChange, 0, len(selections))
+	for _, selection := range selections {
+		change := RestoreTableChange{Table: selection.table}
+		for _, count := range []struct {
+			schema string
+			rows   *int64
+		}{{"main", &change.CurrentRows}, {"snapshot", &change.SnapshotRows}} {
+			query := fmt.Sprintf("SELECT COUNT(*) FROM %s.%s%s", count.schema, quoteIdentifier(selection.table), selection.clause())
+			if err := d.conn.QueryRowContext(ctx, query, selection.args...).Scan(count.rows); err != nil {
+				return nil, fmt.Errorf("failed to count rows of %s: %w", selection.table, err)
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// restore replaces the selected rows of the live database with those of the
+// snapshot in a single transaction
+func (d *snapshotDatabase) restore(ctx context.Context, selections []tableSelection) error {
+	columns := make([][]string, len(selections))
+	for i, selection := range selections {
+		var err error
+		if columns[i], err = d.columns(ctx, selection.table); err != nil {
+			return err
+		}
+	}
+
+	tx, err := d.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for i, selection := range selections {
+		table := quoteIdentifier(selection.table)
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM main.%s%s", table, selection.clause()), selection.args...); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", selection.table, err)
+		}
+		if len(columns[i]) == 0 {
+			continue
+		}
+		list := strings.Join(columns[i], ", ")
+		query := fmt.Sprintf("INSERT INTO main.%s (%s) SELECT %s FROM snapshot.%s%s", table, list, list, table, selection.clause())
+		if _, err := tx.ExecContext(ctx, query, selection.args...); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", selection.table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit restore: %w", err)
+	}
+	return nil
+}
@@ -15,6 +15,24 @@ var (
 	// ErrInvalidBackupType is returned when an invalid backup type is provided
 	ErrInvalidBackupType = errors.New("invalid backup type")
 
+	// ErrInvalidTargetConfig is returned when a backup target misses the configuration of its type
+	ErrInvalidTargetConfig = errors.New("invalid backup target configuration")
+
+	// ErrTargetUnreachable is returned when the connectivity test of a backup target fails
+	ErrTargetUnreachable = errors.New("backup target is not reachable")
+
+	// ErrInvalidSchedule is returned when a backup schedule selects nodes inconsistently
+	ErrInvalidSchedule = errors.New("invalid backup schedule")
+
+	// ErrInvalidRestoreRequest is returned when a restore doesn't say what to restore
+	ErrInvalidRestoreRequest = errors.New("invalid restore request")
+
+	// ErrSnapshotUnavailable is returned when the snapshot of a backup can't be restored
+	ErrSnapshotUnavailable = errors.New("backup snapshot is not available")
+
+	// ErrRestoreInProgress is returned when a restore is requested while another one runs
+	ErrRestoreInProgress = errors.New("a restore is already in progress")
+
 	// ErrScheduleAlreadyEnabled is returned when trying to enable an already enabled schedule
 	ErrScheduleAlreadyEnabled = errors.New("schedule is already enabled")
 
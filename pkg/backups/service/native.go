@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chainlaunch/chainlaunch/pkg/backups/engine"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Names of the sources of the snapshots taken by the native engine
+const (
+	NativeDataSource     = "data"
+	NativeDatabaseSource = "database"
+)
+
+// nativeBackend returns the storage of a native engine repository
+func nativeBackend(target *db.BackupTarget) (engine.Backend, error) {
+	switch BackupTargetType(target.Type) {
+	case BackupTargetTypeLocal:
+		return engine.NewLocalBackend(target.LocalPath.String), nil
+	case BackupTargetTypeS3:
+		return engine.NewS3Backend(engine.S3Config{
+			Endpoint:        target.Endpoint.String,
+			Region:          target.Region.String,
+			Bucket:          target.BucketName.String,
+			Prefix:          target.BucketPath.String,
+			AccessKeyID:     target.AccessKeyID.String,
+			SecretAccessKey: target.SecretKey.String,
+			PathStyle:       target.S3PathStyle.Bool,
+		})
+	default:
+		return nil, fmt.Errorf("the native engine does not support %s targets", target.Type)
+	}
+}
+
+// openNativeRepository opens the repository of a target, creating it on first use
+func (s *BackupService) openNativeRepository(ctx context.Context, target *db.BackupTarget) (*engine.Repository, error) {
+	target, err := s.openTargetSecrets(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	backend, err := nativeBackend(target)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := engine.OpenOrInit(ctx, backend, target.ResticPassword.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	return repo, nil
+}
+
+// copyDatabase writes a transactionally consistent copy of the database to dest
+func (s *BackupService) copyDatabase(ctx context.Context, dest string) error {
+	database, err := sql.Open("sqlite3", s.databasePath)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	os.Remove(dest)
+	if _, err := database.ExecContext(ctx, "VACUUM INTO ?", dest); err != nil {
+		return fmt.Errorf("failed to copy database: %w", err)
+	}
+	return nil
+}
+
+// performNativeBackup snapshots the data directory and a consistent copy of the database
+func (s *BackupService) performNativeBackup(ctx context.Context, backup *db.Backup, target *db.BackupTarget) error {
+	chainlaunchPath := s.configService.GetDataPath()
+	if _, err := os.Stat(chainlaunchPath); os.IsNotExist(err) {
+		return fmt.Errorf("backup source error: .chainlaunch directory does not exist at %s", chainlaunchPath)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "chainlaunch-backup-*")
+	if err != nil {
+		return fmt.Errorf("backup preparation error: failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	dbCopy := filepath.Join(tmpDir, filepath.Base(s.databasePath))
+	if err := s.copyDatabase(ctx, dbCopy); err != nil {
+		return fmt.Errorf("backup preparation error: %w", err)
+	}
+
+	// The live database is replaced by its consistent copy
+	excluded := map[string]bool{}
+	if rel, err := filepath.Rel(chainlaunchPath, s.databasePath); err == nil && !strings.HasPrefix(rel, "..") {
+		rel = filepath.ToSlash(rel)
+		excluded[rel] = true
+		excluded[rel+"-wal"] = true
+		excluded[rel+"-shm"] = true
+	}
+
+	return s.runNativeBackup(ctx, backup, target, []engine.Source{
+		{
+			Name:    NativeDataSource,
+			Path:    chainlaunchPath,
+			Exclude: func(rel string) bool { return excluded[rel] },
+		},
+		{Name: NativeDatabaseSource, Path: dbCopy},
+	})
+}
+
+// runNativeBackup stores a snapshot of the sources in the repository of the target
+// and records it in the backup
+func (s *BackupService) runNativeBackup(ctx context.Context, backup *db.Backup, target *db.BackupTarget, sources []engine.Source) error {
+	repo, err := s.openNativeRepository(ctx, target)
+	if err != nil {
+		s.notifyConnectionIssue(ctx, target, err.Error())
+		return fmt.Errorf("backup configuration error: %w", err)
+	}
+
+	snapshot, err := repo.Backup(ctx, sources, engine.BackupOptions{
+		Tags: []string{fmt.Sprintf("backup:%d", backup.ID)},
+	})
+	if err != nil {
+		return fmt.Errorf("backup process error: %w", err)
+	}
+	s.logger.Infof("Created snapshot %s: %d files, %d bytes, %d bytes added",
+		snapshot.ID, snapshot.Summary.Files, snapshot.Summary.TotalBytes, snapshot.Summary.AddedBytes)
+
+	_, err = s.queries.UpdateBackupSnapshot(ctx, &db.UpdateBackupSnapshotParams{
+		ID:         backup.ID,
+		SnapshotID: sql.NullString{String: snapshot.ID, Valid: true},
+		SizeBytes:  sql.NullInt64{Int64: snapshot.Summary.TotalBytes, Valid: true},
+	})
+	return err
+}
+
+// deleteNativeSnapshot removes the snapshot of a backup and the data only it used
+func (s *BackupService) deleteNativeSnapshot(ctx context.Context, backup *db.Backup, target *db.BackupTarget) error {
+	if !backup.SnapshotID.Valid {
+		s.logger.Infof("Backup %d has no snapshot to delete", backup.ID)
+		return nil
+	}
+	repo, err := s.openNativeRepository(ctx, target)
+	if err != nil {
+		return err
+	}
+	if err := repo.Forget(ctx, backup.SnapshotID.String); err != nil {
+		return err
+	}
+	if _, err := repo.Prune(ctx); err != nil {
+		return fmt.Errorf("failed to prune repository: %w", err)
+	}
+	s.logger.Infof("Deleted snapshot %s", backup.SnapshotID.String)
+	return nil
+}
+
+// CheckBackupTarget verifies the integrity of the repository of a target.
+// With readData every file is downloaded and checked against its hash.
+func (s *BackupService) CheckBackupTarget(ctx context.Context, id int64, readData bool) (*engine.CheckResult, error) {
+	target, err := s.queries.GetBackupTarget(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTargetNotFound
+		}
+		return nil, fmt.Errorf("failed to get backup target: %w", err)
+	}
+
+	if BackupEngine(target.Engine) == BackupEngineNative {
+		repo, err := s.openNativeRepository(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTargetUnreachable, err)
+		}
+		return repo.Check(ctx, engine.CheckOptions{ReadData: readData})
+	}
+
+	repo, err := s.openResticRepo(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Close()
+	args := []string{"check"}
+	if readData {
+		args = append(args, "--read-data")
+	}
+	result := &engine.CheckResult{}
+	if output, err := repo.command(ctx, args...).CombinedOutput(); err != nil {
+		result.Errors = append(result.Errors, strings.TrimSpace(string(output)))
+	}
+	return result, nil
+}
@@ -0,0 +1,670 @@
+package service
+
+import (
+	"archive/tar"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/backups/engine"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	nodeservice "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
+	nodetypes "github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+	dockerclient "github.com/docker/docker/client"
+)
+
+// ledgerDirs are the directories of a peer replaced by its ledger snapshots in a backup
+var ledgerDirs = []string{"data/ledgersData", "data/transientstore", "data/snapshots"}
+
+// stopRank is the position of the node types when nodes are stopped, peers
+// first so they don't lose their orderers, and CAs last
+var stopRank = map[nodetypes.NodeType]int{
+	nodetypes.NodeTypeFabricPeer:    0,
+	nodetypes.NodeTypeBesuFullnode:  1,
+	nodetypes.NodeTypeFabricOrderer: 2,
+	nodetypes.NodeTypeFabricCA:      3,
+}
+
+// nodeSelection is the node selection of a schedule in its stored form
+type nodeSelection struct {
+	scope       BackupScope
+	nodeIDs     sql.NullString
+	networkID   sql.NullInt64
+	consistency ConsistencyStrategy
+	stopOrder   sql.NullString
+}
+
+// newNodeSelection checks the node selection of a schedule and encodes it for storage
+func newNodeSelection(scope BackupScope, nodeIDs []int64, networkID int64, consistency ConsistencyStrategy, stopOrder []int64) (*nodeSelection, error) {
+	selection := &nodeSelection{scope: scope, consistency: consistency}
+	if selection.scope == "" {
+		selection.scope = BackupScopeAll
+	}
+	if selection.consistency == "" {
+		selection.consistency = ConsistencyNone
+	}
+
+	switch selection.scope {
+	case BackupScopeAll:
+	case BackupScopeNodes:
+		if len(nodeIDs) == 0 {
+			return nil, fmt.Errorf("%w: scope NODES requires node IDs", ErrInvalidSchedule)
+		}
+		selection.nodeIDs = encodeNodeIDs(nodeIDs)
+	case BackupScopeNetwork:
+		if networkID == 0 {
+			return nil, fmt.Errorf("%w: scope NETWORK requires a network ID", ErrInvalidSchedule)
+		}
+		selection.networkID = sql.NullInt64{Int64: networkID, Valid: true}
+	default:
+		return nil, fmt.Errorf("%w: unknown scope %s", ErrInvalidSchedule, scope)
+	}
+
+	switch selection.consistency {
+	case ConsistencyNone, ConsistencyLedgerSnapshot, ConsistencyStopStart, ConsistencyDockerExport:
+	default:
+		return nil, fmt.Errorf("%w: unknown consistency strategy %s", ErrInvalidSchedule, consistency)
+	}
+	if len(stopOrder) > 0 && selection.consistency == ConsistencyNone {
+		return nil, fmt.Errorf("%w: a stop order requires a consistency strategy", ErrInvalidSchedule)
+	}
+	selection.stopOrder = encodeNodeIDs(stopOrder)
+	return selection, nil
+}
+
+// encodeNodeIDs stores a list of node IDs as JSON, an empty list as NULL
+func encodeNodeIDs(ids []int64) sql.NullString {
+	if len(ids) == 0 {
+		return sql.NullString{}
+	}
+	encoded, _ := json.Marshal(ids)
+	return sql.NullString{String: string(encoded), Valid: true}
+}
+
+// decodeNodeIDs reads a list of node IDs stored by encodeNodeIDs
+func decodeNodeIDs(stored sql.NullString) []int64 {
+	if !stored.Valid {
+		return nil
+	}
+	var ids []int64
+	if err := json.Unmarshal([]byte(stored.String), &ids); err != nil {
+		return nil
+	}
+	return ids
+}
+
+// toBackupScheduleDTO converts a stored schedule
+func toBackupScheduleDTO(schedule *db.BackupSchedule) *BackupScheduleDTO {
+	dto := &BackupScheduleDTO{
+		ID:                   schedule.ID,
+		Name:                 schedule.Name,
+		Description:          schedule.Description.String,
+		CronExpression:       schedule.CronExpression,
+		TargetID:             schedule.TargetID,
+		RetentionDays:        int(schedule.RetentionDays),
+		KeepLast:             int(schedule.KeepLast),
+		KeepDaily:            int(schedule.KeepDaily),
+		KeepWeekly:           int(schedule.KeepWeekly),
+		KeepMonthly:          int(schedule.KeepMonthly),
+		Enabled:              schedule.Enabled,
+		CreatedAt:            schedule.CreatedAt,
+		UpdatedAt:            &schedule.UpdatedAt.Time,
+		LastRunAt:            &schedule.LastRunAt.Time,
+		NextRunAt:            &schedule.NextRunAt.Time,
+		Scope:                BackupScope(schedule.Scope),
+		NodeIDs:              decodeNodeIDs(schedule.NodeIds),
+		Consistency:          ConsistencyStrategy(schedule.ConsistencyStrategy),
+		StopOrder:            decodeNodeIDs(schedule.StopOrder),
+		VerifyCronExpression: schedule.VerifyCronExpression.String,
+	}
+	if schedule.NetworkID.Valid {
+		dto.NetworkID = &schedule.NetworkID.Int64
+	}
+	return dto
+}
+
+// nodeMetadata is what a node backup stores about its nodes
+type nodeMetadata struct {
+	Scope BackupScope     `json:"scope"`
+	Nodes []NodeBackupDTO `json:"nodes"`
+}
+
+// backupNodeMetadata returns the node metadata of a backup, nil for backups
+// of the whole data directory taken as it is
+func backupNodeMetadata(backup *db.Backup) *nodeMetadata {
+	if !backup.NodeMetadata.Valid {
+		return nil
+	}
+	var metadata nodeMetadata
+	if err := json.Unmarshal([]byte(backup.NodeMetadata.String), &metadata); err != nil {
+		return nil
+	}
+	return &metadata
+}
+
+// backupNodes returns what was done for each node of a node backup
+func backupNodes(backup *db.Backup) []NodeBackupDTO {
+	if metadata := backupNodeMetadata(backup); metadata != nil {
+		return metadata.Nodes
+	}
+	return nil
+}
+
+// isNodeBackup tells whether the backups of a schedule are built node by node
+// instead of copying the whole data directory as it is
+func isNodeBackup(schedule *db.BackupSchedule) bool {
+	return (schedule.Scope != "" && BackupScope(schedule.Scope) != BackupScopeAll) ||
+		(schedule.ConsistencyStrategy != "" && ConsistencyStrategy(schedule.ConsistencyStrategy) != ConsistencyNone)
+}
+
+// scheduleNodes returns the backup sources of the nodes selected by a schedule
+func (s *BackupService) scheduleNodes(ctx context.Context, schedule *db.BackupSchedule) ([]*nodeservice.NodeBackupSource, error) {
+	var ids []int64
+	switch BackupScope(schedule.Scope) {
+	case BackupScopeNodes:
+		ids = decodeNodeIDs(schedule.NodeIds)
+	case BackupScopeNetwork:
+		if !schedule.NetworkID.Valid {
+			return nil, fmt.Errorf("the network of the schedule was deleted")
+		}
+		networkNodes, err := s.queries.ListNetworkNodesByNetwork(ctx, schedule.NetworkID.Int64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list network nodes: %w", err)
+		}
+		for _, networkNode := range networkNodes {
+			ids = append(ids, networkNode.NodeID)
+		}
+	default:
+		nodes, err := s.queries.GetAllNodes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodes: %w", err)
+		}
+		for _, node := range nodes {
+			ids = append(ids, node.ID)
+		}
+	}
+
+	sources := make([]*nodeservice.NodeBackupSource, 0, len(ids))
+	for _, id := range ids {
+		source, err := s.nodeService.GetNodeBackupSource(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get files of node %d: %w", id, err)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// performNodeBackup backs up the nodes selected by a schedule along with the
+// database. The files of the nodes are staged with the layout of the data
+// directory, applying the consistency strategy of the schedule, and the
+// staging directory is backed up by the engine of the target.
+func (s *BackupService) performNodeBackup(ctx context.Context, backup *db.Backup, target *db.BackupTarget, schedule *db.BackupSchedule) error {
+	sources, err := s.scheduleNodes(ctx, schedule)
+	if err != nil {
+		return fmt.Errorf("backup configuration error: %w", err)
+	}
+
+	// The staging directory is on the same file system as the node files
+	dataPath := s.configService.GetDataPath()
+	staging, err := os.MkdirTemp(dataPath, ".backup-*")
+	if err != nil {
+		return fmt.Errorf("backup preparation error: failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	stage := filepath.Join(staging, "data")
+
+	nodes, stageErr := s.stageNodes(ctx, schedule, sources, dataPath, stage)
+	if metadata, err := json.Marshal(nodeMetadata{Scope: BackupScope(schedule.Scope), Nodes: nodes}); err != nil {
+		s.logger.Errorf("Failed to encode node metadata of backup %d: %v", backup.ID, err)
+	} else if err := s.queries.UpdateBackupNodeMetadata(ctx, &db.UpdateBackupNodeMetadataParams{
+		ID:           backup.ID,
+		NodeMetadata: sql.NullString{String: string(metadata), Valid: true},
+	}); err != nil {
+		s.logger.Errorf("Failed to store node metadata of backup %d: %v", backup.ID, err)
+	}
+	if stageErr != nil {
+		return fmt.Errorf("backup preparation error: %w", stageErr)
+	}
+
+	// A backup of all nodes also holds the rest of the data directory, the database is copied below
+	if BackupScope(schedule.Scope) == BackupScopeAll {
+		skipped := make(map[string]bool)
+		for _, p := range s.protectedPaths() {
+			skipped[p] = true
+		}
+		for _, node := range nodes {
+			skipped[node.DataDir] = true
+		}
+		err := copyTree(dataPath, stage, func(rel string) bool {
+			return skipped[rel] || strings.HasPrefix(rel, ".backup-") || strings.HasPrefix(rel, ".restore-")
+		})
+		if err != nil {
+			return fmt.Errorf("backup preparation error: %w", err)
+		}
+	}
+
+	// The database copy is where the restore of both engines looks for it
+	dbsPath := filepath.Join(stage, "dbs")
+	if err := os.MkdirAll(dbsPath, 0755); err != nil {
+		return fmt.Errorf("backup preparation error: failed to create dbs directory: %w", err)
+	}
+	dbCopy := filepath.Join(dbsPath, fmt.Sprintf("chainlaunch-%s.db", time.Now().Format("20060102-150405")))
+	if err := s.copyDatabase(ctx, dbCopy); err != nil {
+		return fmt.Errorf("backup preparation error: %w", err)
+	}
+
+	if BackupEngine(target.Engine) == BackupEngineNative {
+		return s.runNativeBackup(ctx, backup, target, []engine.Source{
+			{Name: NativeDataSource, Path: stage},
+			{Name: NativeDatabaseSource, Path: dbCopy},
+		})
+	}
+	return s.runResticBackup(ctx, backup, target, stage)
+}
+
+// stageNodes copies the files of the nodes into the stage with the consistency
+// strategy of the schedule. Nodes stopped for the copy are always started
+// again. It returns what was done for each node, also when it fails.
+func (s *BackupService) stageNodes(ctx context.Context, schedule *db.BackupSchedule, sources []*nodeservice.NodeBackupSource, dataPath, stage string) ([]NodeBackupDTO, error) {
+	strategy := ConsistencyStrategy(schedule.ConsistencyStrategy)
+	nodes := make([]NodeBackupDTO, len(sources))
+	var stopStart []int
+	for i, source := range sources {
+		node := &nodes[i]
+		*node = NodeBackupDTO{
+			NodeID:   source.NodeID,
+			Name:     source.Name,
+			NodeType: string(source.NodeType),
+			Mode:     source.Mode,
+			DataDir:  source.DataDir,
+			Running:  source.Status == nodetypes.NodeStatusRunning,
+			Strategy: ConsistencyNone,
+		}
+		switch {
+		case !node.Running || strategy == ConsistencyNone:
+		case strategy == ConsistencyLedgerSnapshot && source.NodeType == nodetypes.NodeTypeFabricPeer:
+			node.Strategy = ConsistencyLedgerSnapshot
+		case strategy == ConsistencyDockerExport && source.ContainerName != "":
+			node.Strategy = ConsistencyDockerExport
+		default:
+			// Nodes the strategy doesn't apply to are stopped for the copy
+			node.Strategy = ConsistencyStopStart
+			stopStart = append(stopStart, i)
+		}
+	}
+
+	for i, source := range sources {
+		var err error
+		switch nodes[i].Strategy {
+		case ConsistencyNone:
+			err = copyTree(filepath.Join(dataPath, filepath.FromSlash(source.DataDir)), filepath.Join(stage, filepath.FromSlash(source.DataDir)), nil)
+		case ConsistencyLedgerSnapshot:
+			err = s.stageLedgerSnapshots(ctx, source, dataPath, stage, &nodes[i])
+		case ConsistencyDockerExport:
+			err = s.stageDockerExport(ctx, source, dataPath, stage, &nodes[i])
+		}
+		if err != nil {
+			return nodes, fmt.Errorf("failed to back up node %s: %w", source.Name, err)
+		}
+	}
+
+	if len(stopStart) == 0 {
+		return nodes, nil
+	}
+	order := stopOrder(sources, stopStart, decodeNodeIDs(schedule.StopOrder))
+	var stopped []int
+	var stageErr error
+	for _, i := range order {
+		if _, err := s.nodeService.StopNode(ctx, sources[i].NodeID); err != nil {
+			stageErr = fmt.Errorf("failed to stop node %s: %w", sources[i].Name, err)
+			break
+		}
+		stoppedAt := time.Now()
+		nodes[i].StoppedAt = &stoppedAt
+		stopped = append(stopped, i)
+	}
+	if stageErr == nil {
+		for _, i := range order {
+			dir := filepath.FromSlash(sources[i].DataDir)
+			if err := copyTree(filepath.Join(dataPath, dir), filepath.Join(stage, dir), nil); err != nil {
+				stageErr = fmt.Errorf("failed to back up node %s: %w", sources[i].Name, err)
+				break
+			}
+		}
+	}
+
+	// Nodes are started in the reverse order they were stopped, even when the
+	// backup was cancelled while they were down
+	restartCtx := context.WithoutCancel(ctx)
+	for n := len(stopped) - 1; n >= 0; n-- {
+		i := stopped[n]
+		if _, err := s.nodeService.StartNode(restartCtx, sources[i].NodeID); err != nil {
+			s.logger.Errorf("Failed to restart node %d after backup: %v", sources[i].NodeID, err)
+			nodes[i].Error = err.Error()
+			continue
+		}
+		restartedAt := time.Now()
+		nodes[i].RestartedAt = &restartedAt
+	}
+	return nodes, stageErr
+}
+
+// stopOrder returns the order the nodes at the indexes are stopped in, the
+// nodes of the explicit order first and the others by type
+func stopOrder(sources []*nodeservice.NodeBackupSource, indexes []int, explicit []int64) []int {
+	position := make(map[int64]int, len(explicit))
+	for n, id := range explicit {
+		if _, ok := position[id]; !ok {
+			position[id] = n
+		}
+	}
+	order := append([]int(nil), indexes...)
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := sources[order[a]], sources[order[b]]
+		pa, aListed := position[sa.NodeID]
+		pb, bListed := position[sb.NodeID]
+		switch {
+		case aListed && bListed:
+			return pa < pb
+		case aListed != bListed:
+			return aListed
+		case stopRank[sa.NodeType] != stopRank[sb.NodeType]:
+			return stopRank[sa.NodeType] < stopRank[sb.NodeType]
+		default:
+			return sa.NodeID < sb.NodeID
+		}
+	})
+	return order
+}
+
+// stageLedgerSnapshots stages a running peer with ledger snapshots of its
+// channels in place of its live ledger. The snapshots are removed from the
+// peer once they are staged.
+func (s *BackupService) stageLedgerSnapshots(ctx context.Context, source *nodeservice.NodeBackupSource, dataPath, stage string, node *NodeBackupDTO) error {
+	snapshots, err := s.nodeService.CreateLedgerSnapshots(ctx, source.NodeID)
+	if err != nil {
+		return err
+	}
+
+	nodeDir := filepath.Join(dataPath, filepath.FromSlash(source.DataDir))
+	err = copyTree(nodeDir, filepath.Join(stage, filepath.FromSlash(source.DataDir)), func(rel string) bool {
+		for _, dir := range ledgerDirs {
+			if rel == dir {
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		rel, err := filepath.Rel(dataPath, snapshot.Dir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return fmt.Errorf("snapshot %s is outside the data directory", snapshot.Dir)
+		}
+		if err := copyTree(snapshot.Dir, filepath.Join(stage, rel), nil); err != nil {
+			return err
+		}
+		node.LedgerSnapshots = append(node.LedgerSnapshots, LedgerSnapshotDTO{
+			ChannelID:   snapshot.ChannelID,
+			BlockNumber: snapshot.BlockNumber,
+			Path:        filepath.ToSlash(rel),
+		})
+		if err := os.RemoveAll(snapshot.Dir); err != nil {
+			s.logger.Warnf("Failed to remove ledger snapshot %s: %v", snapshot.Dir, err)
+		}
+	}
+	return nil
+}
+
+// stageDockerExport stages a docker node with the content of its mounts
+// exported from the container while it is paused. Bind mounts in the data
+// directory keep their path, volumes are staged under volumes/<container>.
+func (s *BackupService) stageDockerExport(ctx context.Context, source *nodeservice.NodeBackupSource, dataPath, stage string, node *NodeBackupDTO) error {
+	cli, err := dockerclient.NewClientWithOpts(dockerclient.FromEnv, dockerclient.WithAPIVersionNegotiation())
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer cli.Close()
+
+	info, err := cli.ContainerInspect(ctx, source.ContainerName)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container %s: %w", source.ContainerName, err)
+	}
+
+	// The files of the node outside its mounts are copied as they are
+	nodeDir := filepath.Join(dataPath, filepath.FromSlash(source.DataDir))
+	exports := make([]DockerExportDTO, 0, len(info.Mounts))
+	mounted := make(map[string]bool)
+	for _, mount := range info.Mounts {
+		export := DockerExportDTO{
+			Container:   source.ContainerName,
+			Destination: mount.Destination,
+			Volume:      mount.Name,
+		}
+		if rel, err := filepath.Rel(dataPath, mount.Source); mount.Name == "" && err == nil && !strings.HasPrefix(rel, "..") {
+			export.Path = filepath.ToSlash(rel)
+			if inNode, err := filepath.Rel(nodeDir, mount.Source); err == nil && !strings.HasPrefix(inNode, "..") {
+				mounted[filepath.ToSlash(inNode)] = true
+			}
+		} else {
+			name := mount.Name
+			if name == "" {
+				name = strings.ReplaceAll(strings.Trim(mount.Destination, "/"), "/", "_")
+			}
+			export.Path = path.Join("volumes", source.ContainerName, name)
+		}
+		exports = append(exports, export)
+	}
+	err = copyTree(nodeDir, filepath.Join(stage, filepath.FromSlash(source.DataDir)), func(rel string) bool {
+		return mounted[rel] || mounted["."]
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := cli.ContainerPause(ctx, source.ContainerName); err != nil {
+		return fmt.Errorf("failed to pause container %s: %w", source.ContainerName, err)
+	}
+	pausedAt := time.Now()
+	node.StoppedAt = &pausedAt
+	defer func() {
+		if err := cli.ContainerUnpause(context.Background(), source.ContainerName); err != nil {
+			s.logger.Errorf("Failed to unpause container %s after backup: %v", source.ContainerName, err)
+			node.Error = err.Error()
+			return
+		}
+		unpausedAt := time.Now()
+		node.RestartedAt = &unpausedAt
+	}()
+
+	for _, export := range exports {
+		reader, _, err := cli.CopyFromContainer(ctx, source.ContainerName, export.Destination)
+		if err != nil {
+			return fmt.Errorf("failed to export %s of container %s: %w", export.Destination, source.ContainerName, err)
+		}
+		err = untarStripped(reader, filepath.Join(stage, filepath.FromSlash(export.Path)))
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to export %s of container %s: %w", export.Destination, source.ContainerName, err)
+		}
+		node.DockerExports = append(node.DockerExports, export)
+	}
+	return nil
+}
+
+// copyTree copies a directory keeping the modes, modification times and
+// symbolic links of its entries. Entries for which exclude returns true, given
+// their slash separated path relative to src, are skipped with their content.
+// A missing src copies nothing.
+func copyTree(src, dst string, exclude func(rel string) bool) error {
+	if _, err := os.Lstat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if rel != "." && exclude != nil && exclude(filepath.ToSlash(rel)) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.IsDir():
+			if err := os.MkdirAll(target, info.Mode().Perm()|0700); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return fmt.Errorf("failed to read link: %w", err)
+			}
+			if err := os.Symlink(link, target); err != nil {
+				return fmt.Errorf("failed to create link: %w", err)
+			}
+			return nil
+		case info.Mode().IsRegular():
+			if err := copyFile(p, target, info.Mode().Perm()); err != nil {
+				return err
+			}
+		default:
+			// Sockets and devices are not backed up
+			return nil
+		}
+		return os.Chtimes(target, info.ModTime(), info.ModTime())
+	})
+}
+
+// copyFile copies a regular file
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	return out.Close()
+}
+
+// untarStripped extracts a tar stream from the docker API into dir. The first
+// path component, the name of the exported directory, is removed.
+func untarStripped(reader io.Reader, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		name := path.Clean(header.Name)
+		if i := strings.Index(name, "/"); i >= 0 {
+			name = name[i+1:]
+		} else {
+			name = "."
+		}
+		if strings.HasPrefix(name, "../") || name == ".." {
+			return fmt.Errorf("archive entry %s is outside the export", header.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		mode := os.FileMode(header.Mode).Perm()
+		// Links created by earlier entries must not redirect writes outside dir
+		if err := checkNoSymlinks(dir, name); err != nil {
+			return fmt.Errorf("archive entry %s: %w", header.Name, err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return fmt.Errorf("failed to create file: %w", err)
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return fmt.Errorf("failed to write file: %w", err)
+			}
+			if err := out.Close(); err != nil {
+				return fmt.Errorf("failed to write file: %w", err)
+			}
+		case tar.TypeSymlink:
+			linkname := path.Clean(header.Linkname)
+			resolved := path.Join(path.Dir(name), linkname)
+			if path.IsAbs(header.Linkname) || filepath.IsAbs(header.Linkname) || resolved == ".." || strings.HasPrefix(resolved, "../") {
+				return fmt.Errorf("archive entry %s links outside the export to %s", header.Name, header.Linkname)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+			if err := os.Symlink(filepath.FromSlash(linkname), target); err != nil {
+				return fmt.Errorf("failed to create link: %w", err)
+			}
+			continue
+		default:
+			continue
+		}
+		if err := os.Chtimes(target, header.ModTime, header.ModTime); err != nil {
+			return fmt.Errorf("failed to set modification time: %w", err)
+		}
+	}
+}
+
+// checkNoSymlinks fails if any existing element of the slash separated path
+// name below dir is a symbolic link
+func checkNoSymlinks(dir, name string) error {
+	if name == "." {
+		return nil
+	}
+	current := dir
+	for _, part := range strings.Split(name, "/") {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check path: %w", err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to write through symbolic link %s", current)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     string
+}
+
+func newTestTar(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		header := &tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Linkname: e.linkname,
+			Mode:     0644,
+			Size:     int64(len(e.body)),
+		}
+		if e.typeflag == tar.TypeDir {
+			header.Mode = 0755
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("failed to write header: %v", err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatalf("failed to write body: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close archive: %v", err)
+	}
+	return &buf
+}
+
+func TestUntarStripped(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	archive := newTestTar(t, []tarEntry{
+		{name: "data/", typeflag: tar.TypeDir},
+		{name: "data/ledger/", typeflag: tar.TypeDir},
+		{name: "data/ledger/block", typeflag: tar.TypeReg, body: "block"},
+		{name: "data/current", typeflag: tar.TypeSymlink, linkname: "ledger/block"},
+	})
+	if err := untarStripped(archive, dir); err != nil {
+		t.Fatalf("failed to extract: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "current"))
+	if err != nil {
+		t.Fatalf("failed to read through link: %v", err)
+	}
+	if string(content) != "block" {
+		t.Fatalf("unexpected content %q", content)
+	}
+}
+
+func TestUntarStrippedRejectsEscapes(t *testing.T) {
+	cases := map[string][]tarEntry{
+		"absolute link": {
+			{name: "data/etc", typeflag: tar.TypeSymlink, linkname: "/etc"},
+		},
+		"link outside the export": {
+			{name: "data/ledger/up", typeflag: tar.TypeSymlink, linkname: "../../outside"},
+		},
+		"file written through a link": {
+			{name: "data/dir", typeflag: tar.TypeSymlink, linkname: "."},
+			{name: "data/dir/file", typeflag: tar.TypeReg, body: "x"},
+		},
+		"file replacing a link": {
+			{name: "data/file", typeflag: tar.TypeSymlink, linkname: "other"},
+			{name: "data/file", typeflag: tar.TypeReg, body: "x"},
+		},
+		"path outside the export": {
+			{name: "data/../../../outside", typeflag: tar.TypeReg, body: "x"},
+		},
+	}
+	for name, entries := range cases {
+		root := t.TempDir()
+		dir := filepath.Join(root, "out")
+		err := untarStripped(newTestTar(t, entries), dir)
+		if err == nil {
+			t.Errorf("%s: expected the archive to be rejected", name)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, "outside")); !os.IsNotExist(err) {
+			t.Errorf("%s: file written outside the export", name)
+		}
+		if strings.Contains(err.Error(), "failed to read archive") {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
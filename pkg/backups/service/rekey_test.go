@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/pkg/config"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/kek"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/secrets"
+	keymanagement "github.com/chainlaunch/chainlaunch/pkg/keymanagement/service"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	nodeservice "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/utils"
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+	notificationService "github.com/chainlaunch/chainlaunch/pkg/notifications/service"
+)
+
+// storedSecrets reads the sealed CouchDB password of a peer, the SMTP password
+// of a provider and the restic password of a backup target
+func storedSecrets(t *testing.T, queries *db.Queries, nodeID, providerID, targetID int64) []string {
+	ctx := context.Background()
+	node, err := queries.GetNode(ctx, nodeID)
+	if err != nil {
+		t.Fatalf("failed to get node: %v", err)
+	}
+	nodeConfig, err := utils.LoadNodeConfig([]byte(node.NodeConfig.String))
+	if err != nil {
+		t.Fatalf("failed to load node config: %v", err)
+	}
+	provider, err := queries.GetNotificationProvider(ctx, providerID)
+	if err != nil {
+		t.Fatalf("failed to get provider: %v", err)
+	}
+	var smtp notifications.SMTPConfig
+	if err := json.Unmarshal([]byte(provider.Config), &smtp); err != nil {
+		t.Fatalf("failed to unmarshal provider config: %v", err)
+	}
+	target, err := queries.GetBackupTarget(ctx, targetID)
+	if err != nil {
+		t.Fatalf("failed to get target: %v", err)
+	}
+	return []string{nodeConfig.(*types.FabricPeerConfig).CouchDB.Password, smtp.Password, target.ResticPassword.String}
+}
+
+func TestRekeyResealsStoredSecrets(t *testing.T) {
+	ctx := context.Background()
+	oldKey, newKey := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+	queries := newTestDatabase(t, filepath.Join(t.TempDir(), "test.db"))
+
+	// Secrets sealed before the key encryption key was rotated
+	oldStore := newTestSecretStore(t, oldKey)
+	seal := func(value string) string {
+		sealed, err := oldStore.Seal(ctx, value)
+		if err != nil {
+			t.Fatalf("failed to seal: %v", err)
+		}
+		return sealed
+	}
+
+	// Restart with the new key, the old one as previous
+	t.Setenv(kek.EnvKey, hex.EncodeToString(newKey))
+	t.Setenv(kek.EnvPreviousKeys, hex.EncodeToString(oldKey))
+	t.Setenv(kek.EnvKeyFile, "")
+	store, err := secrets.Load(ctx)
+	if err != nil {
+		t.Fatalf("failed to load secret store: %v", err)
+	}
+	keyService, err := keymanagement.NewKeyManagementService(queries)
+	if err != nil {
+		t.Fatalf("failed to create key management service: %v", err)
+	}
+	if err := keyService.InitializeKeyProviders(ctx); err != nil {
+		t.Fatalf("failed to initialize key providers: %v", err)
+	}
+	keyService.SetSecretStore(store)
+	log := logger.NewDefault()
+	nodeService := nodeservice.NewNodeService(queries, log, keyService, nil, nil, nil, nil, store)
+	notificationSvc := notificationService.NewNotificationService(queries, log, store)
+	backupService := NewBackupService(queries, log, notificationSvc, "", config.NewConfigService(t.TempDir()), nodeService, store)
+	t.Cleanup(backupService.Stop)
+
+	// Stored after the services started, so only rekeying reseals them
+	peerConfig, err := utils.StoreNodeConfig(&types.FabricPeerConfig{
+		BaseNodeConfig: types.BaseNodeConfig{Type: "fabric-peer", Mode: "docker"},
+		CouchDB:        &types.CouchDBConfig{Enabled: true, Username: "admin", Password: seal("couchdb-password")},
+	})
+	if err != nil {
+		t.Fatalf("failed to store node config: %v", err)
+	}
+	node, err := queries.CreateNode(ctx, &db.CreateNodeParams{
+		Name: "peer0", Slug: "peer0", Platform: string(types.PlatformFabric), Status: "RUNNING",
+		NodeType:   sql.NullString{String: string(types.NodeTypeFabricPeer), Valid: true},
+		NodeConfig: sql.NullString{String: string(peerConfig), Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	smtpConfig, err := json.Marshal(notifications.SMTPConfig{Host: "smtp.example.com", Port: 587, Password: seal("smtp-password")})
+	if err != nil {
+		t.Fatalf("failed to marshal SMTP config: %v", err)
+	}
+	provider, err := queries.CreateNotificationProvider(ctx, &db.CreateNotificationProviderParams{
+		Type: string(notifications.ProviderTypeSMTP), Name: "smtp", Config: string(smtpConfig),
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	target, err := queries.CreateBackupTarget(ctx, &db.CreateBackupTargetParams{
+		Name: "local", Type: string(BackupTargetTypeLocal), Engine: string(BackupEngineRestic),
+		ResticPassword: sql.NullString{String: seal("restic-password"), Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create target: %v", err)
+	}
+
+	// A secret sealed with an unknown key fails the rekey, which leaves every secret untouched
+	if err := queries.UpdateBackupTargetSecrets(ctx, &db.UpdateBackupTargetSecretsParams{
+		ID:             target.ID,
+		ResticPassword: sql.NullString{String: "sealed:unknown", Valid: true},
+	}); err != nil {
+		t.Fatalf("failed to update target secrets: %v", err)
+	}
+	before := storedSecrets(t, queries, node.ID, provider.ID, target.ID)
+	if _, err := keyService.RekeyDatabaseKeys(ctx); err == nil {
+		t.Fatal("expected rekeying to fail with a secret it can't open")
+	}
+	for i, value := range storedSecrets(t, queries, node.ID, provider.ID, target.ID) {
+		if value != before[i] {
+			t.Errorf("secret %d was updated by a failed rekey", i)
+		}
+	}
+
+	if err := queries.UpdateBackupTargetSecrets(ctx, &db.UpdateBackupTargetSecretsParams{
+		ID:             target.ID,
+		ResticPassword: sql.NullString{String: seal("restic-password"), Valid: true},
+	}); err != nil {
+		t.Fatalf("failed to update target secrets: %v", err)
+	}
+	resp, err := keyService.RekeyDatabaseKeys(ctx)
+	if err != nil {
+		t.Fatalf("failed to rekey: %v", err)
+	}
+	if resp.ResealedSecrets != 3 {
+		t.Errorf("expected 3 resealed secrets, got %d", resp.ResealedSecrets)
+	}
+
+	// Only the new key is needed to open the stored secrets
+	newStore := newTestSecretStore(t, newKey)
+	expected := []string{"couchdb-password", "smtp-password", "restic-password"}
+	for i, value := range storedSecrets(t, queries, node.ID, provider.ID, target.ID) {
+		opened, err := newStore.Open(ctx, value)
+		if err != nil {
+			t.Errorf("secret %d: failed to open with the new key: %v", i, err)
+			continue
+		}
+		if opened != expected[i] {
+			t.Errorf("secret %d: expected %q, got %q", i, expected[i], opened)
+		}
+	}
+}
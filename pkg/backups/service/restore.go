@@ -0,0 +1,538 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	nodetypes "github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+)
+
+// restorePlan is what a restore writes back
+type restorePlan struct {
+	// prefixes are the data directory entries replaced by their snapshot version
+	prefixes []string
+	// tables restores rows of the snapshot database when set
+	tables func(ctx context.Context, d *snapshotDatabase) ([]tableSelection, error)
+	// nodes are stopped while the files are replaced
+	nodes []*db.Node
+}
+
+// Restore restores a backup, or reports the changes it would make for a dry run.
+// Unless skipped, the current state is backed up to the same target first and
+// the nodes whose files are replaced are stopped during the restore.
+func (s *BackupService) Restore(ctx context.Context, params RestoreParams) (*RestoreResultDTO, error) {
+	s.mu.Lock()
+	if s.restoring {
+		s.mu.Unlock()
+		return nil, ErrRestoreInProgress
+	}
+	s.restoring = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.restoring = false
+		s.mu.Unlock()
+	}()
+
+	backup, err := s.restoreBackup(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.queries.GetBackupTarget(ctx, backup.TargetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTargetNotFound
+		}
+		return nil, fmt.Errorf("failed to get backup target: %w", err)
+	}
+
+	reader, err := s.openSnapshot(ctx, target, backup)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	entries, err := reader.entries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshot: %w", err)
+	}
+
+	plan, err := s.restorePlan(ctx, params, backup, entries)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.restoreChanges(entries, plan.prefixes)
+	if err != nil {
+		return nil, err
+	}
+	result := &RestoreResultDTO{
+		BackupID:   backup.ID,
+		SnapshotID: backup.SnapshotID.String,
+		Scope:      params.Scope,
+		DryRun:     params.DryRun,
+		Changes:    changes,
+	}
+
+	var database *snapshotDatabase
+	var selections []tableSelection
+	if plan.tables != nil {
+		tmpDir, err := os.MkdirTemp("", "chainlaunch-restore-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(tmpDir)
+		dbPath := filepath.Join(tmpDir, "chainlaunch.db")
+		if err := reader.extractDatabase(ctx, dbPath); err != nil {
+			return nil, fmt.Errorf("failed to extract database: %w", err)
+		}
+		if database, err = s.attachSnapshotDatabase(ctx, dbPath); err != nil {
+			return nil, err
+		}
+		defer database.Close()
+		if selections, err = plan.tables(ctx, database); err != nil {
+			return nil, err
+		}
+		if result.Tables, err = database.compare(ctx, selections); err != nil {
+			return nil, err
+		}
+	}
+
+	if params.DryRun {
+		return result, nil
+	}
+
+	if !params.SkipPreRestoreBackup {
+		preRestore, err := s.backupBeforeRestore(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		result.PreRestoreBackupID = &preRestore.ID
+	}
+
+	// Stage in the data directory so the files are moved in place instead of copied
+	dataPath := s.configService.GetDataPath()
+	staging, err := os.MkdirTemp(dataPath, ".restore-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create restore directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+	staged := filepath.Join(staging, "data")
+	if len(plan.prefixes) > 0 {
+		if err := reader.extract(ctx, plan.prefixes, staged); err != nil {
+			return nil, fmt.Errorf("failed to extract snapshot: %w", err)
+		}
+	}
+
+	for _, node := range plan.nodes {
+		if _, err := s.nodeService.StopNode(ctx, node.ID); err != nil {
+			s.restartNodes(ctx, result)
+			return nil, fmt.Errorf("failed to stop node %s: %w", node.Name, err)
+		}
+		result.StoppedNodes = append(result.StoppedNodes, node.ID)
+	}
+
+	restoreErr := replacePaths(dataPath, staged, filepath.Join(staging, "previous"), plan.prefixes)
+	if restoreErr == nil && database != nil {
+		restoreErr = database.restore(ctx, selections)
+	}
+
+	if !params.KeepNodesStopped {
+		s.restartNodes(ctx, result)
+	}
+	if restoreErr != nil {
+		return nil, fmt.Errorf("failed to restore backup %d: %w", backup.ID, restoreErr)
+	}
+
+	s.logger.Infof("Restored %s of backup %d (snapshot %s): %d file changes",
+		params.Scope, backup.ID, backup.SnapshotID.String, len(result.Changes))
+	return result, nil
+}
+
+// restoreBackup returns the backup a restore reads
+func (s *BackupService) restoreBackup(ctx context.Context, params RestoreParams) (*db.Backup, error) {
+	var backup *db.Backup
+	var err error
+	switch {
+	case params.BackupID != nil:
+		backup, err = s.queries.GetBackup(ctx, *params.BackupID)
+		if err == sql.ErrNoRows {
+			return nil, ErrBackupNotFound
+		}
+	case params.TargetID != nil && params.PointInTime != nil:
+		backup, err = s.queries.GetLatestCompletedBackupBefore(ctx, &db.GetLatestCompletedBackupBeforeParams{
+			TargetID:  *params.TargetID,
+			StartedAt: *params.PointInTime,
+		})
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: no completed backup of target %d at or before %s",
+				ErrBackupNotFound, *params.TargetID, params.PointInTime.Format(time.RFC3339))
+		}
+	default:
+		return nil, fmt.Errorf("%w: either a backup or a target and a point in time is required", ErrInvalidRestoreRequest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup: %w", err)
+	}
+	if BackupStatus(backup.Status) != BackupStatusCompleted {
+		return nil, fmt.Errorf("%w: backup %d is %s", ErrSnapshotUnavailable, backup.ID, backup.Status)
+	}
+	return backup, nil
+}
+
+// nodeDataDirs lists the data directory paths where the nodes of every platform keep their files
+func nodeDataDirs(slug string) []string {
+	return []string{
+		path.Join("nodes", slug),
+		path.Join("peers", slug),
+		path.Join("orderers", slug),
+		path.Join("fabric", "peers", slug),
+		path.Join("fabric", "orderers", slug),
+		path.Join("besu", slug),
+		path.Join("besu", "nodes", slug),
+		path.Join("data", "besu", slug),
+		path.Join("cas", slug),
+	}
+}
+
+// restorePlan works out what a restore of the scope replaces
+func (s *BackupService) restorePlan(ctx context.Context, params RestoreParams, backup *db.Backup, entries []snapshotEntry) (*restorePlan, error) {
+	dataPath := s.configService.GetDataPath()
+	inSnapshot := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		inSnapshot[entry.Path] = true
+	}
+	exists := func(p string) bool {
+		if inSnapshot[p] {
+			return true
+		}
+		_, err := os.Lstat(filepath.Join(dataPath, filepath.FromSlash(p)))
+		return err == nil
+	}
+
+	plan := &restorePlan{}
+	switch params.Scope {
+	case RestoreScopeFull:
+		plan.prefixes = fullRestorePrefixes(entries, s.protectedPaths())
+		plan.tables = func(ctx context.Context, d *snapshotDatabase) ([]tableSelection, error) {
+			return d.fullSelections(ctx)
+		}
+		nodes, err := s.queries.GetAllNodes(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodes: %w", err)
+		}
+
+		// A backup of selected nodes only holds their directories, the other nodes are left alone
+		var backedUp map[int64]bool
+		if metadata := backupNodeMetadata(backup); metadata != nil && metadata.Scope != BackupScopeAll {
+			plan.prefixes = nil
+			backedUp = make(map[int64]bool)
+			for _, node := range metadata.Nodes {
+				backedUp[node.NodeID] = true
+				if exists(node.DataDir) {
+					plan.prefixes = append(plan.prefixes, node.DataDir)
+				}
+			}
+		}
+		for _, node := range nodes {
+			if backedUp != nil && !backedUp[node.ID] {
+				continue
+			}
+			if node.Status == string(nodetypes.NodeStatusRunning) {
+				plan.nodes = append(plan.nodes, node)
+			}
+		}
+
+	case RestoreScopeNode:
+		node, err := s.queries.GetNode(ctx, params.NodeID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("%w: node %d not found", ErrInvalidRestoreRequest, params.NodeID)
+			}
+			return nil, fmt.Errorf("failed to get node: %w", err)
+		}
+		for _, dir := range nodeDataDirs(node.Slug) {
+			if exists(dir) {
+				plan.prefixes = append(plan.prefixes, dir)
+			}
+		}
+		if node.Status == string(nodetypes.NodeStatusRunning) {
+			plan.nodes = []*db.Node{node}
+		}
+
+	case RestoreScopeOrganization:
+		org, err := s.queries.GetFabricOrganization(ctx, params.OrganizationID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("%w: organization %d not found", ErrInvalidRestoreRequest, params.OrganizationID)
+			}
+			return nil, fmt.Errorf("failed to get organization: %w", err)
+		}
+		if dir := path.Join("orgs", strings.ToLower(org.MspID)); exists(dir) {
+			plan.prefixes = []string{dir}
+		}
+		plan.tables = func(ctx context.Context, d *snapshotDatabase) ([]tableSelection, error) {
+			return organizationSelections(org.ID), nil
+		}
+
+	case RestoreScopeDatabase:
+		plan.tables = func(ctx context.Context, d *snapshotDatabase) ([]tableSelection, error) {
+			return d.fullSelections(ctx)
+		}
+
+	default:
+		return nil, fmt.Errorf("%w: unsupported scope %s", ErrInvalidRestoreRequest, params.Scope)
+	}
+	return plan, nil
+}
+
+// protectedPaths are the data directory paths a full restore leaves alone:
+// the live database, which is restored through SQL, and the database copies
+// made for the backups
+func (s *BackupService) protectedPaths() []string {
+	protected := []string{"dbs"}
+	dataPath, err := filepath.Abs(s.configService.GetDataPath())
+	if err != nil {
+		return protected
+	}
+	databasePath, err := filepath.Abs(s.databasePath)
+	if err != nil {
+		return protected
+	}
+	if rel, err := filepath.Rel(dataPath, databasePath); err == nil && !strings.HasPrefix(rel, "..") {
+		rel = filepath.ToSlash(rel)
+		protected = append(protected, rel, rel+"-wal", rel+"-shm", rel+"-journal")
+	}
+	return protected
+}
+
+// fullRestorePrefixes lists the top level entries of the snapshot, descending
+// into the directories that hold a protected path so it is never replaced
+func fullRestorePrefixes(entries []snapshotEntry, protected []string) []string {
+	children := make(map[string][]string)
+	for _, entry := range entries {
+		parent := path.Dir(entry.Path)
+		if parent == "." {
+			parent = ""
+		}
+		children[parent] = append(children[parent], entry.Path)
+	}
+	isProtected := make(map[string]bool, len(protected))
+	for _, p := range protected {
+		isProtected[p] = true
+	}
+	holdsProtected := func(dir string) bool {
+		for _, p := range protected {
+			if strings.HasPrefix(p, dir+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	var prefixes []string
+	var walk func(dir string)
+	walk = func(dir string) {
+		names := children[dir]
+		sort.Strings(names)
+		for _, name := range names {
+			switch {
+			case isProtected[name]:
+			case holdsProtected(name):
+				walk(name)
+			default:
+				prefixes = append(prefixes, name)
+			}
+		}
+	}
+	walk("")
+	return prefixes
+}
+
+// restoreChanges compares the files of the snapshot below the prefixes with the data directory
+func (s *BackupService) restoreChanges(entries []snapshotEntry, prefixes []string) ([]RestoreChange, error) {
+	dataPath := s.configService.GetDataPath()
+	files := make(map[string]snapshotEntry)
+	for _, entry := range entries {
+		if !entry.Dir && underPrefixes(entry.Path, prefixes) {
+			files[entry.Path] = entry
+		}
+	}
+
+	changes := []RestoreChange{}
+	seen := make(map[string]bool)
+	for _, prefix := range prefixes {
+		root := filepath.Join(dataPath, filepath.FromSlash(prefix))
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(dataPath, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			entry, ok := files[rel]
+			if !ok {
+				changes = append(changes, RestoreChange{Path: rel, Change: RestoreChangeDelete, Size: info.Size()})
+				return nil
+			}
+			seen[rel] = true
+			if changed(p, info, entry) {
+				changes = append(changes, RestoreChange{Path: rel, Change: RestoreChangeModify, Size: entry.Size})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to compare %s: %w", prefix, err)
+		}
+	}
+	for rel, entry := range files {
+		if !seen[rel] {
+			changes = append(changes, RestoreChange{Path: rel, Change: RestoreChangeAdd, Size: entry.Size})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// changed reports whether a file differs from its snapshot version. Files of
+// the same size and modification time are taken as unchanged, the content is
+// only hashed when the snapshot records its hash.
+func changed(p string, info fs.FileInfo, entry snapshotEntry) bool {
+	if info.Size() != entry.Size {
+		return true
+	}
+	if info.ModTime().Truncate(time.Second).Equal(entry.ModTime.Truncate(time.Second)) {
+		return false
+	}
+	if entry.SHA256 == "" {
+		return true
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return true
+	}
+	return hex.EncodeToString(hash.Sum(nil)) != entry.SHA256
+}
+
+// replacePaths moves the staged version of every prefix in place of the
+// current one, which is moved to previous. A failed move puts back what was
+// moved so far.
+func replacePaths(dataPath, staged, previous string, prefixes []string) error {
+	type move struct {
+		prefix   string
+		replaced bool
+		placed   bool
+	}
+	var moves []move
+	rollback := func() {
+		for i := len(moves) - 1; i >= 0; i-- {
+			current := filepath.Join(dataPath, filepath.FromSlash(moves[i].prefix))
+			if moves[i].placed {
+				os.RemoveAll(current)
+			}
+			if moves[i].replaced {
+				os.Rename(filepath.Join(previous, filepath.FromSlash(moves[i].prefix)), current)
+			}
+		}
+	}
+
+	for _, prefix := range prefixes {
+		rel := filepath.FromSlash(prefix)
+		current := filepath.Join(dataPath, rel)
+		m := move{prefix: prefix}
+
+		if _, err := os.Lstat(current); err == nil {
+			old := filepath.Join(previous, rel)
+			if err := os.MkdirAll(filepath.Dir(old), 0700); err != nil {
+				rollback()
+				return fmt.Errorf("failed to move %s aside: %w", prefix, err)
+			}
+			if err := os.Rename(current, old); err != nil {
+				rollback()
+				return fmt.Errorf("failed to move %s aside: %w", prefix, err)
+			}
+			m.replaced = true
+		}
+
+		if _, err := os.Lstat(filepath.Join(staged, rel)); err == nil {
+			if err := os.MkdirAll(filepath.Dir(current), 0755); err != nil {
+				moves = append(moves, m)
+				rollback()
+				return fmt.Errorf("failed to restore %s: %w", prefix, err)
+			}
+			if err := os.Rename(filepath.Join(staged, rel), current); err != nil {
+				moves = append(moves, m)
+				rollback()
+				return fmt.Errorf("failed to restore %s: %w", prefix, err)
+			}
+			m.placed = true
+		}
+		moves = append(moves, m)
+	}
+	return nil
+}
+
+// backupBeforeRestore backs up the current state to the target of the restored backup
+func (s *BackupService) backupBeforeRestore(ctx context.Context, target *db.BackupTarget) (*db.Backup, error) {
+	backup, err := s.queries.CreateBackup(ctx, &db.CreateBackupParams{
+		TargetID:  target.ID,
+		Status:    string(BackupStatusPending),
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pre-restore backup: %w", err)
+	}
+
+	s.performBackup(backup)
+
+	backup, err = s.queries.GetBackup(ctx, backup.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pre-restore backup: %w", err)
+	}
+	if BackupStatus(backup.Status) != BackupStatusCompleted {
+		return nil, fmt.Errorf("pre-restore backup %d failed: %s", backup.ID, backup.ErrorMessage.String)
+	}
+	s.logger.Infof("Backed up the current state before restoring in backup %d", backup.ID)
+	return backup, nil
+}
+
+// restartNodes starts the nodes stopped for a restore, failures are reported in the result
+func (s *BackupService) restartNodes(ctx context.Context, result *RestoreResultDTO) {
+	for _, id := range result.StoppedNodes {
+		if _, err := s.nodeService.StartNode(ctx, id); err != nil {
+			s.logger.Errorf("Failed to start node %d after restore: %v", id, err)
+			result.Errors = append(result.Errors, fmt.Sprintf("failed to start node %d: %v", id, err))
+			continue
+		}
+		result.StartedNodes = append(result.StartedNodes, id)
+	}
+}
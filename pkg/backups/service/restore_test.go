@@ -0,0 +1,371 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+)
+
+// newTestDatabase creates a migrated database at path
+func newTestDatabase(t *testing.T, path string) *db.Queries {
+	database, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	driver, err := sqlite3.WithInstance(database, &sqlite3.Config{})
+	if err != nil {
+		t.Fatalf("failed to create sqlite driver: %v", err)
+	}
+	source, err := iofs.New(os.DirFS("../../db/migrations"), ".")
+	if err != nil {
+		t.Fatalf("failed to open migrations: %v", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", driver)
+	if err != nil {
+		t.Fatalf("failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return db.New(database)
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// restoreTest is a data directory with its database, backed up once to a
+// local target of the native engine
+type restoreTest struct {
+	s        *BackupService
+	dataPath string
+	backup   *db.Backup
+}
+
+func newRestoreTest(t *testing.T) *restoreTest {
+	ctx := context.Background()
+	s := newTestBackupService(t)
+	dataPath := s.configService.GetDataPath()
+	s.databasePath = filepath.Join(dataPath, "chainlaunch.db")
+	s.queries = newTestDatabase(t, s.databasePath)
+
+	writeTestFile(t, filepath.Join(dataPath, "nodes", "peer0", "config.yaml"), "peer: v1\n")
+	writeTestFile(t, filepath.Join(dataPath, "orgs", "org1msp", "ca.pem"), "ca")
+	createTestProvider(t, s.queries, "in-snapshot")
+
+	target, err := s.queries.CreateBackupTarget(ctx, &db.CreateBackupTargetParams{
+		Name:           "local",
+		Type:           string(BackupTargetTypeLocal),
+		LocalPath:      sql.NullString{String: t.TempDir(), Valid: true},
+		ResticPassword: sql.NullString{String: "password", Valid: true},
+		Engine:         string(BackupEngineNative),
+	})
+	if err != nil {
+		t.Fatalf("failed to create target: %v", err)
+	}
+	backup, err := s.queries.CreateBackup(ctx, &db.CreateBackupParams{
+		TargetID:  target.ID,
+		Status:    string(BackupStatusPending),
+		StartedAt: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	if err := s.performNativeBackup(ctx, backup, target); err != nil {
+		t.Fatalf("failed to back up: %v", err)
+	}
+	if backup, err = s.queries.UpdateBackupCompleted(ctx, &db.UpdateBackupCompletedParams{
+		ID:          backup.ID,
+		Status:      string(BackupStatusCompleted),
+		CompletedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}); err != nil {
+		t.Fatalf("failed to complete backup: %v", err)
+	}
+
+	// Change the state after the backup
+	writeTestFile(t, filepath.Join(dataPath, "nodes", "peer0", "config.yaml"), "peer: v2 changed\n")
+	writeTestFile(t, filepath.Join(dataPath, "nodes", "peer0", "new.txt"), "new")
+	if err := os.RemoveAll(filepath.Join(dataPath, "orgs")); err != nil {
+		t.Fatalf("failed to remove orgs: %v", err)
+	}
+	createTestProvider(t, s.queries, "after-snapshot")
+
+	return &restoreTest{s: s, dataPath: dataPath, backup: backup}
+}
+
+func createTestProvider(t *testing.T, queries *db.Queries, name string) {
+	if _, err := queries.CreateNotificationProvider(context.Background(), &db.CreateNotificationProviderParams{
+		Type:   "WEBHOOK",
+		Name:   name,
+		Config: "{}",
+	}); err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+}
+
+func providerNames(t *testing.T, queries *db.Queries) []string {
+	providers, err := queries.ListNotificationProviders(context.Background())
+	if err != nil {
+		t.Fatalf("failed to list providers: %v", err)
+	}
+	var names []string
+	for _, provider := range providers {
+		names = append(names, provider.Name)
+	}
+	return names
+}
+
+func TestRestoreDryRun(t *testing.T) {
+	rt := newRestoreTest(t)
+
+	result, err := rt.s.Restore(context.Background(), RestoreParams{BackupID: &rt.backup.ID, Scope: RestoreScopeFull, DryRun: true})
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	expected := []RestoreChange{
+		{Path: "nodes/peer0/config.yaml", Change: RestoreChangeModify, Size: int64(len("peer: v1\n"))},
+		{Path: "nodes/peer0/new.txt", Change: RestoreChangeDelete, Size: 3},
+		{Path: "orgs/org1msp/ca.pem", Change: RestoreChangeAdd, Size: 2},
+	}
+	if !reflect.DeepEqual(result.Changes, expected) {
+		t.Errorf("expected changes %+v, got %+v", expected, result.Changes)
+	}
+	found := false
+	for _, table := range result.Tables {
+		if tablesKeptOnRestore[table.Table] {
+			t.Errorf("table %s should be kept on restore", table.Table)
+		}
+		if table.Table == "notification_providers" {
+			found = true
+			if table.CurrentRows != 2 || table.SnapshotRows != 1 {
+				t.Errorf("unexpected provider rows %+v", table)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("notification_providers missing from %+v", result.Tables)
+	}
+
+	// A dry run changes nothing
+	if content := readTestFile(t, filepath.Join(rt.dataPath, "nodes", "peer0", "config.yaml")); content != "peer: v2 changed\n" {
+		t.Errorf("dry run modified a file: %q", content)
+	}
+	if names := providerNames(t, rt.s.queries); len(names) != 2 {
+		t.Errorf("dry run modified the database: %v", names)
+	}
+}
+
+func TestRestoreFull(t *testing.T) {
+	rt := newRestoreTest(t)
+	ctx := context.Background()
+
+	result, err := rt.s.Restore(ctx, RestoreParams{BackupID: &rt.backup.ID, Scope: RestoreScopeFull, SkipPreRestoreBackup: true})
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if result.PreRestoreBackupID != nil || len(result.StoppedNodes) != 0 {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	if content := readTestFile(t, filepath.Join(rt.dataPath, "nodes", "peer0", "config.yaml")); content != "peer: v1\n" {
+		t.Errorf("expected the snapshot version, got %q", content)
+	}
+	if content := readTestFile(t, filepath.Join(rt.dataPath, "orgs", "org1msp", "ca.pem")); content != "ca" {
+		t.Errorf("expected the removed file back, got %q", content)
+	}
+	if _, err := os.Stat(filepath.Join(rt.dataPath, "nodes", "peer0", "new.txt")); !os.IsNotExist(err) {
+		t.Errorf("files added after the snapshot should be removed, got %v", err)
+	}
+	if names := providerNames(t, rt.s.queries); !reflect.DeepEqual(names, []string{"in-snapshot"}) {
+		t.Errorf("expected the snapshot providers, got %v", names)
+	}
+	// The backup history survives the restore
+	if _, err := rt.s.queries.GetBackup(ctx, rt.backup.ID); err != nil {
+		t.Errorf("backup lost by the restore: %v", err)
+	}
+	// Staging directories are cleaned up
+	entries, err := os.ReadDir(rt.dataPath)
+	if err != nil {
+		t.Fatalf("failed to read data directory: %v", err)
+	}
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".db" && entry.Name() != "nodes" && entry.Name() != "orgs" {
+			t.Errorf("unexpected entry %s left in the data directory", entry.Name())
+		}
+	}
+}
+
+func TestRestoreDatabaseOnly(t *testing.T) {
+	rt := newRestoreTest(t)
+
+	result, err := rt.s.Restore(context.Background(), RestoreParams{BackupID: &rt.backup.ID, Scope: RestoreScopeDatabase, SkipPreRestoreBackup: true})
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if len(result.Changes) != 0 {
+		t.Errorf("a database restore should not change files, got %+v", result.Changes)
+	}
+	if content := readTestFile(t, filepath.Join(rt.dataPath, "nodes", "peer0", "config.yaml")); content != "peer: v2 changed\n" {
+		t.Errorf("a database restore should not change files, got %q", content)
+	}
+	if names := providerNames(t, rt.s.queries); !reflect.DeepEqual(names, []string{"in-snapshot"}) {
+		t.Errorf("expected the snapshot providers, got %v", names)
+	}
+}
+
+func TestRestoreErrors(t *testing.T) {
+	rt := newRestoreTest(t)
+	ctx := context.Background()
+
+	failed, err := rt.s.queries.CreateBackup(ctx, &db.CreateBackupParams{
+		TargetID:  rt.backup.TargetID,
+		Status:    string(BackupStatusFailed),
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	unknown := int64(1000)
+	before := rt.backup.StartedAt.Add(-time.Hour)
+
+	cases := map[string]struct {
+		params   RestoreParams
+		expected error
+	}{
+		"nothing to restore":   {RestoreParams{Scope: RestoreScopeFull}, ErrInvalidRestoreRequest},
+		"target without time":  {RestoreParams{TargetID: &rt.backup.TargetID, Scope: RestoreScopeFull}, ErrInvalidRestoreRequest},
+		"unknown backup":       {RestoreParams{BackupID: &unknown, Scope: RestoreScopeFull}, ErrBackupNotFound},
+		"failed backup":        {RestoreParams{BackupID: &failed.ID, Scope: RestoreScopeFull}, ErrSnapshotUnavailable},
+		"before first backup":  {RestoreParams{TargetID: &rt.backup.TargetID, PointInTime: &before, Scope: RestoreScopeFull}, ErrBackupNotFound},
+		"unknown node":         {RestoreParams{BackupID: &rt.backup.ID, Scope: RestoreScopeNode, NodeID: unknown}, ErrInvalidRestoreRequest},
+		"unknown organization": {RestoreParams{BackupID: &rt.backup.ID, Scope: RestoreScopeOrganization, OrganizationID: unknown}, ErrInvalidRestoreRequest},
+		"unsupported scope":    {RestoreParams{BackupID: &rt.backup.ID, Scope: "CHANNEL"}, ErrInvalidRestoreRequest},
+	}
+	for name, c := range cases {
+		c.params.DryRun = true
+		if _, err := rt.s.Restore(ctx, c.params); !errors.Is(err, c.expected) {
+			t.Errorf("%s: expected %v, got %v", name, c.expected, err)
+		}
+	}
+
+	// The latest completed backup at the point in time is restored
+	now := time.Now()
+	result, err := rt.s.Restore(ctx, RestoreParams{TargetID: &rt.backup.TargetID, PointInTime: &now, Scope: RestoreScopeDatabase, DryRun: true})
+	if err != nil || result.BackupID != rt.backup.ID {
+		t.Errorf("expected backup %d, got %+v (%v)", rt.backup.ID, result, err)
+	}
+
+	rt.s.restoring = true
+	if _, err := rt.s.Restore(ctx, RestoreParams{BackupID: &rt.backup.ID, Scope: RestoreScopeFull, DryRun: true}); !errors.Is(err, ErrRestoreInProgress) {
+		t.Errorf("expected ErrRestoreInProgress, got %v", err)
+	}
+}
+
+func TestFullRestorePrefixes(t *testing.T) {
+	entries := []snapshotEntry{
+		{Path: "nodes", Dir: true},
+		{Path: "nodes/peer0", Dir: true},
+		{Path: "config.yaml"},
+		{Path: "dbs", Dir: true},
+		{Path: "state", Dir: true},
+		{Path: "state/chainlaunch.db"},
+		{Path: "state/keys", Dir: true},
+	}
+	prefixes := fullRestorePrefixes(entries, []string{"dbs", "state/chainlaunch.db", "state/chainlaunch.db-wal"})
+	expected := []string{"config.yaml", "nodes", "state/keys"}
+	if !reflect.DeepEqual(prefixes, expected) {
+		t.Errorf("expected %v, got %v", expected, prefixes)
+	}
+
+	s := newTestBackupService(t)
+	s.databasePath = filepath.Join(s.configService.GetDataPath(), "state", "chainlaunch.db")
+	expected = []string{"dbs", "state/chainlaunch.db", "state/chainlaunch.db-wal", "state/chainlaunch.db-shm", "state/chainlaunch.db-journal"}
+	if protected := s.protectedPaths(); !reflect.DeepEqual(protected, expected) {
+		t.Errorf("expected %v, got %v", expected, protected)
+	}
+	s.databasePath = filepath.Join(t.TempDir(), "chainlaunch.db")
+	if protected := s.protectedPaths(); !reflect.DeepEqual(protected, []string{"dbs"}) {
+		t.Errorf("a database outside the data directory is not protected, got %v", protected)
+	}
+}
+
+func TestUnderPrefixes(t *testing.T) {
+	cases := []struct {
+		path     string
+		prefixes []string
+		expected bool
+	}{
+		{"nodes/peer0/config.yaml", []string{"nodes/peer0"}, true},
+		{"nodes/peer0", []string{"nodes/peer0"}, true},
+		{"nodes/peer01/config.yaml", []string{"nodes/peer0"}, false},
+		{"orgs/org1msp", []string{"nodes", "orgs"}, true},
+		{"anything", []string{""}, true},
+		{"anything", nil, false},
+	}
+	for _, c := range cases {
+		if got := underPrefixes(c.path, c.prefixes); got != c.expected {
+			t.Errorf("%s under %v: expected %v, got %v", c.path, c.prefixes, c.expected, got)
+		}
+	}
+}
+
+func TestReplacePathsRollback(t *testing.T) {
+	dir := t.TempDir()
+	dataPath := filepath.Join(dir, "data")
+	staged := filepath.Join(dir, "staged")
+	previous := filepath.Join(dir, "previous")
+	writeTestFile(t, filepath.Join(dataPath, "a", "file"), "current a")
+	writeTestFile(t, filepath.Join(staged, "a", "file"), "staged a")
+	// b/c can't be placed because b is a file in the data directory
+	writeTestFile(t, filepath.Join(dataPath, "b"), "current b")
+	writeTestFile(t, filepath.Join(staged, "b", "c"), "staged c")
+
+	if err := replacePaths(dataPath, staged, previous, []string{"a", "b/c"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if content := readTestFile(t, filepath.Join(dataPath, "a", "file")); content != "current a" {
+		t.Errorf("expected a to be rolled back, got %q", content)
+	}
+	if content := readTestFile(t, filepath.Join(dataPath, "b")); content != "current b" {
+		t.Errorf("expected b to be untouched, got %q", content)
+	}
+
+	// The staged version of a rolled back path is dropped with the staging directory
+	writeTestFile(t, filepath.Join(staged, "a", "file"), "staged a")
+	if err := replacePaths(dataPath, staged, previous, []string{"a"}); err != nil {
+		t.Fatalf("failed to replace: %v", err)
+	}
+	if content := readTestFile(t, filepath.Join(dataPath, "a", "file")); content != "staged a" {
+		t.Errorf("expected the staged version, got %q", content)
+	}
+	if content := readTestFile(t, filepath.Join(previous, "a", "file")); content != "current a" {
+		t.Errorf("expected the previous version to be kept aside, got %q", content)
+	}
+}
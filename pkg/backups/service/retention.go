@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/backups/engine"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+)
+
+// lockTarget serializes the operations writing to the repository of a target,
+// so snapshots are not pruned while a backup adds data. It returns the unlock function.
+func (s *BackupService) lockTarget(id int64) func() {
+	s.mu.Lock()
+	lock, ok := s.targetLocks[id]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.targetLocks[id] = lock
+	}
+	s.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// schedulePolicy returns the retention policy of a schedule. Every backup of
+// the last retention days is kept, older ones only when a keep rule selects them.
+func schedulePolicy(schedule *db.BackupSchedule) engine.RetentionPolicy {
+	return engine.RetentionPolicy{
+		KeepLast:    int(schedule.KeepLast),
+		KeepDaily:   int(schedule.KeepDaily),
+		KeepWeekly:  int(schedule.KeepWeekly),
+		KeepMonthly: int(schedule.KeepMonthly),
+		KeepWithin:  time.Duration(schedule.RetentionDays) * 24 * time.Hour,
+	}
+}
+
+// retentionPlan decides which backups of a schedule are kept. Completed backups
+// are selected by the policy, failed ones are kept for the retention days only
+// and running ones are never removed.
+func retentionPlan(schedule *db.BackupSchedule, backups []*db.Backup, now time.Time) []RetentionBackupDTO {
+	policy := schedulePolicy(schedule)
+
+	var completed []int
+	var times []time.Time
+	plan := make([]RetentionBackupDTO, len(backups))
+	for i, backup := range backups {
+		plan[i] = RetentionBackupDTO{
+			BackupID:   backup.ID,
+			TargetID:   backup.TargetID,
+			SnapshotID: backup.SnapshotID.String,
+			Status:     BackupStatus(backup.Status),
+			StartedAt:  backup.StartedAt,
+		}
+		switch BackupStatus(backup.Status) {
+		case BackupStatusCompleted:
+			completed = append(completed, i)
+			times = append(times, backup.StartedAt)
+		case BackupStatusFailed:
+			if policy.KeepWithin <= 0 || now.Sub(backup.StartedAt) <= policy.KeepWithin {
+				plan[i].Keep = true
+				plan[i].Reasons = []string{"within"}
+			}
+		default:
+			plan[i].Keep = true
+			plan[i].Reasons = []string{"running"}
+		}
+	}
+
+	for n, decision := range policy.Apply(times, now) {
+		plan[completed[n]].Keep = decision.Keep
+		plan[completed[n]].Reasons = decision.Reasons
+	}
+	return plan
+}
+
+// EnforceRetention applies the retention policy of a schedule to its backups.
+// With dryRun nothing is removed and the result only reports the decisions.
+func (s *BackupService) EnforceRetention(ctx context.Context, scheduleID int64, dryRun bool) (*RetentionResultDTO, error) {
+	schedule, err := s.queries.GetBackupSchedule(ctx, scheduleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrScheduleNotFound
+		}
+		return nil, fmt.Errorf("failed to get backup schedule: %w", err)
+	}
+	return s.enforceRetention(ctx, schedule, dryRun)
+}
+
+func (s *BackupService) enforceRetention(ctx context.Context, schedule *db.BackupSchedule, dryRun bool) (*RetentionResultDTO, error) {
+	backups, err := s.queries.ListBackupsBySchedule(ctx, sql.NullInt64{Int64: schedule.ID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups of schedule: %w", err)
+	}
+
+	result := &RetentionResultDTO{
+		ScheduleID: schedule.ID,
+		DryRun:     dryRun,
+		Backups:    retentionPlan(schedule, backups, time.Now()),
+	}
+
+	// Backups are removed per target, a schedule may have changed target over time
+	var targets []int64
+	removals := make(map[int64][]RetentionBackupDTO)
+	for _, backup := range result.Backups {
+		if backup.Keep {
+			result.Kept++
+			continue
+		}
+		if _, ok := removals[backup.TargetID]; !ok {
+			targets = append(targets, backup.TargetID)
+		}
+		removals[backup.TargetID] = append(removals[backup.TargetID], backup)
+	}
+	if dryRun {
+		for _, backup := range result.Backups {
+			if !backup.Keep {
+				result.Deleted++
+			}
+		}
+		return result, nil
+	}
+
+	for _, targetID := range targets {
+		deleted, err := s.removeBackups(ctx, targetID, removals[targetID])
+		result.Deleted += deleted
+		if err != nil {
+			return result, err
+		}
+	}
+	if result.Deleted > 0 {
+		s.logger.Infof("Retention of schedule %d removed %d backups and kept %d", schedule.ID, result.Deleted, result.Kept)
+	}
+	return result, nil
+}
+
+// removeBackups forgets the snapshots of backups of one target, prunes the
+// repository and deletes the backup records. It returns the number of records deleted.
+func (s *BackupService) removeBackups(ctx context.Context, targetID int64, backups []RetentionBackupDTO) (int, error) {
+	var snapshotIDs []string
+	for _, backup := range backups {
+		if backup.SnapshotID != "" {
+			snapshotIDs = append(snapshotIDs, backup.SnapshotID)
+		}
+	}
+
+	if len(snapshotIDs) > 0 {
+		target, err := s.queries.GetBackupTarget(ctx, targetID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get backup target: %w", err)
+		}
+		unlock := s.lockTarget(target.ID)
+		err = s.forgetSnapshots(ctx, target, snapshotIDs)
+		unlock()
+		if err != nil {
+			return 0, fmt.Errorf("failed to remove snapshots: %w", err)
+		}
+	}
+
+	deleted := 0
+	for _, backup := range backups {
+		if err := s.queries.DeleteBackup(ctx, backup.BackupID); err != nil {
+			return deleted, fmt.Errorf("failed to delete backup record: %w", err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// forgetSnapshots removes snapshots from the repository of a target along with
+// the data only they used. Snapshots which are already gone are skipped.
+func (s *BackupService) forgetSnapshots(ctx context.Context, target *db.BackupTarget, snapshotIDs []string) error {
+	if BackupEngine(target.Engine) == BackupEngineNative {
+		repo, err := s.openNativeRepository(ctx, target)
+		if err != nil {
+			return err
+		}
+		for _, id := range snapshotIDs {
+			if err := repo.Forget(ctx, id); err != nil && !errors.Is(err, engine.ErrSnapshotNotFound) {
+				return err
+			}
+		}
+		if _, err := repo.Prune(ctx); err != nil {
+			return fmt.Errorf("failed to prune repository: %w", err)
+		}
+		return nil
+	}
+
+	repo, err := s.openResticRepo(ctx, target)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	args := append([]string{"forget", "--prune"}, snapshotIDs...)
+	if output, err := repo.command(ctx, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("restic forget failed: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+)
+
+func TestRetentionPlan(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)
+	backup := func(id int64, status BackupStatus, age time.Duration) *db.Backup {
+		return &db.Backup{ID: id, TargetID: 1, Status: string(status), StartedAt: now.Add(-age)}
+	}
+	day := 24 * time.Hour
+
+	cases := []struct {
+		name     string
+		schedule db.BackupSchedule
+		backups  []*db.Backup
+		kept     []int64
+	}{
+		{
+			name:     "keep last",
+			schedule: db.BackupSchedule{KeepLast: 2},
+			backups: []*db.Backup{
+				backup(1, BackupStatusCompleted, 3*day),
+				backup(2, BackupStatusCompleted, 2*day),
+				backup(3, BackupStatusCompleted, day),
+			},
+			kept: []int64{2, 3},
+		},
+		{
+			name:     "keep daily and within",
+			schedule: db.BackupSchedule{KeepDaily: 2, RetentionDays: 1},
+			backups: []*db.Backup{
+				backup(1, BackupStatusCompleted, 50*time.Hour),
+				backup(2, BackupStatusCompleted, 36*time.Hour),
+				backup(3, BackupStatusCompleted, 35*time.Hour),
+				backup(4, BackupStatusCompleted, 2*time.Hour),
+				backup(5, BackupStatusCompleted, time.Hour),
+			},
+			kept: []int64{3, 4, 5},
+		},
+		{
+			name:     "failed and running",
+			schedule: db.BackupSchedule{KeepLast: 1, RetentionDays: 2},
+			backups: []*db.Backup{
+				backup(1, BackupStatusFailed, 3*day),
+				backup(2, BackupStatusFailed, day),
+				backup(3, BackupStatusInProgress, 10*day),
+				backup(4, BackupStatusPending, 10*day),
+				backup(5, BackupStatusCompleted, 5*day),
+			},
+			kept: []int64{2, 3, 4, 5},
+		},
+		{
+			name:     "failed without retention days",
+			schedule: db.BackupSchedule{KeepLast: 1},
+			backups: []*db.Backup{
+				backup(1, BackupStatusFailed, 30*day),
+			},
+			kept: []int64{1},
+		},
+	}
+	for _, c := range cases {
+		schedule := c.schedule
+		plan := retentionPlan(&schedule, c.backups, now)
+		if len(plan) != len(c.backups) {
+			t.Fatalf("%s: expected %d decisions, got %d", c.name, len(c.backups), len(plan))
+		}
+		var kept []int64
+		for i, decision := range plan {
+			if decision.BackupID != c.backups[i].ID {
+				t.Errorf("%s: decisions should follow the backups, got %d at %d", c.name, decision.BackupID, i)
+			}
+			if decision.Keep != (len(decision.Reasons) > 0) {
+				t.Errorf("%s: backup %d is kept without reason: %+v", c.name, decision.BackupID, decision)
+			}
+			if decision.Keep {
+				kept = append(kept, decision.BackupID)
+			}
+		}
+		if !reflect.DeepEqual(kept, c.kept) {
+			t.Errorf("%s: expected to keep %v, got %v", c.name, c.kept, kept)
+		}
+	}
+}
+
+func TestEnforceRetention(t *testing.T) {
+	ctx := context.Background()
+	s := newTestBackupService(t)
+	s.targetLocks = make(map[int64]*sync.Mutex)
+	dataPath := s.configService.GetDataPath()
+	s.databasePath = filepath.Join(dataPath, "chainlaunch.db")
+	s.queries = newTestDatabase(t, s.databasePath)
+	writeTestFile(t, filepath.Join(dataPath, "config.yaml"), "config")
+
+	target, err := s.queries.CreateBackupTarget(ctx, &db.CreateBackupTargetParams{
+		Name:           "local",
+		Type:           string(BackupTargetTypeLocal),
+		LocalPath:      sql.NullString{String: t.TempDir(), Valid: true},
+		ResticPassword: sql.NullString{String: "password", Valid: true},
+		Engine:         string(BackupEngineNative),
+	})
+	if err != nil {
+		t.Fatalf("failed to create target: %v", err)
+	}
+	schedule, err := s.queries.CreateBackupSchedule(ctx, &db.CreateBackupScheduleParams{
+		Name:           "daily",
+		CronExpression: "0 0 0 * * *",
+		TargetID:       target.ID,
+		RetentionDays:  1,
+		KeepLast:       1,
+	})
+	if err != nil {
+		t.Fatalf("failed to create schedule: %v", err)
+	}
+
+	createBackup := func(status BackupStatus, age time.Duration) *db.Backup {
+		backup, err := s.queries.CreateBackup(ctx, &db.CreateBackupParams{
+			ScheduleID: sql.NullInt64{Int64: schedule.ID, Valid: true},
+			TargetID:   target.ID,
+			Status:     string(status),
+			StartedAt:  time.Now().Add(-age),
+		})
+		if err != nil {
+			t.Fatalf("failed to create backup: %v", err)
+		}
+		if status == BackupStatusCompleted {
+			if err := s.performNativeBackup(ctx, backup, target); err != nil {
+				t.Fatalf("failed to back up: %v", err)
+			}
+			if backup, err = s.queries.GetBackup(ctx, backup.ID); err != nil {
+				t.Fatalf("failed to get backup: %v", err)
+			}
+		}
+		return backup
+	}
+	oldCompleted := createBackup(BackupStatusCompleted, 72*time.Hour)
+	oldFailed := createBackup(BackupStatusFailed, 48*time.Hour)
+	recentFailed := createBackup(BackupStatusFailed, time.Hour)
+	running := createBackup(BackupStatusInProgress, 96*time.Hour)
+	latest := createBackup(BackupStatusCompleted, time.Minute)
+
+	result, err := s.EnforceRetention(ctx, schedule.ID, true)
+	if err != nil {
+		t.Fatalf("failed to plan retention: %v", err)
+	}
+	if !result.DryRun || result.Kept != 3 || result.Deleted != 2 {
+		t.Errorf("unexpected dry run %+v", result)
+	}
+	if backups, err := s.queries.ListBackupsBySchedule(ctx, sql.NullInt64{Int64: schedule.ID, Valid: true}); err != nil || len(backups) != 5 {
+		t.Errorf("a dry run should not delete backups, got %d (%v)", len(backups), err)
+	}
+
+	result, err = s.EnforceRetention(ctx, schedule.ID, false)
+	if err != nil {
+		t.Fatalf("failed to enforce retention: %v", err)
+	}
+	if result.DryRun || result.Kept != 3 || result.Deleted != 2 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	for _, backup := range []*db.Backup{oldCompleted, oldFailed} {
+		if _, err := s.queries.GetBackup(ctx, backup.ID); err != sql.ErrNoRows {
+			t.Errorf("backup %d should be deleted, got %v", backup.ID, err)
+		}
+	}
+	for _, backup := range []*db.Backup{recentFailed, running, latest} {
+		if _, err := s.queries.GetBackup(ctx, backup.ID); err != nil {
+			t.Errorf("backup %d should be kept: %v", backup.ID, err)
+		}
+	}
+
+	repo, err := s.openNativeRepository(ctx, target)
+	if err != nil {
+		t.Fatalf("failed to open repository: %v", err)
+	}
+	if _, err := repo.Snapshot(ctx, oldCompleted.SnapshotID.String); err == nil {
+		t.Error("the snapshot of a deleted backup should be forgotten")
+	}
+	if _, err := repo.Snapshot(ctx, latest.SnapshotID.String); err != nil {
+		t.Errorf("the snapshot of a kept backup should remain: %v", err)
+	}
+
+	// Snapshots already gone from the repository don't fail a run
+	if err := s.forgetSnapshots(ctx, target, []string{oldCompleted.SnapshotID.String}); err != nil {
+		t.Errorf("forgetting a missing snapshot: %v", err)
+	}
+
+	if _, err := s.EnforceRetention(ctx, 1000, true); !errors.Is(err, ErrScheduleNotFound) {
+		t.Errorf("expected ErrScheduleNotFound, got %v", err)
+	}
+}
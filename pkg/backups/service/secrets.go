@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+)
+
+// openTargetSecrets returns a copy of a target with its secrets decrypted and
+// their references resolved, ready to access the repository
+func (s *BackupService) openTargetSecrets(ctx context.Context, target *db.BackupTarget) (*db.BackupTarget, error) {
+	opened := *target
+	for _, secret := range []*sql.NullString{&opened.SecretKey, &opened.ResticPassword, &opened.SftpPrivateKey} {
+		if !secret.Valid {
+			continue
+		}
+		value, err := s.secrets.Open(ctx, secret.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open secret of backup target %s: %w", target.Name, err)
+		}
+		secret.String = value
+	}
+	return &opened, nil
+}
+
+// sealTargetSecrets encrypts the secrets of a target before it is stored
+func (s *BackupService) sealTargetSecrets(ctx context.Context, target *db.BackupTarget) error {
+	for _, secret := range []*sql.NullString{&target.SecretKey, &target.ResticPassword, &target.SftpPrivateKey} {
+		if !secret.Valid {
+			continue
+		}
+		sealed, err := s.secrets.Seal(ctx, secret.String)
+		if err != nil {
+			return err
+		}
+		secret.String = sealed
+	}
+	return nil
+}
+
+// resealStoredSecrets encrypts the secrets of targets stored in plaintext or
+// with a previous key encryption key, and returns how many were updated
+func (s *BackupService) resealStoredSecrets(ctx context.Context, q *db.Queries) (int, error) {
+	targets, err := q.ListBackupTargets(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list backup targets: %w", err)
+	}
+	resealedCount := 0
+	for _, target := range targets {
+		changed := false
+		for _, secret := range []*sql.NullString{&target.SecretKey, &target.ResticPassword, &target.SftpPrivateKey} {
+			if !secret.Valid {
+				continue
+			}
+			sealed, resealed, err := s.secrets.Reseal(ctx, secret.String)
+			if err != nil {
+				return 0, fmt.Errorf("failed to seal secrets of backup target %d: %w", target.ID, err)
+			}
+			if resealed {
+				secret.String = sealed
+				changed = true
+				resealedCount++
+			}
+		}
+		if !changed {
+			continue
+		}
+		if err := q.UpdateBackupTargetSecrets(ctx, &db.UpdateBackupTargetSecretsParams{
+			ID:             target.ID,
+			SecretKey:      target.SecretKey,
+			ResticPassword: target.ResticPassword,
+			SftpPrivateKey: target.SftpPrivateKey,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to update secrets of backup target %d: %w", target.ID, err)
+		}
+		s.logger.Infof("Encrypted the secrets of backup target %d", target.ID)
+	}
+	return resealedCount, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/database"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/secrets"
+)
+
+func newTestSecretStore(t *testing.T, active []byte, previous ...[]byte) *secrets.Store {
+	ring, err := database.NewKeyRing(active, previous...)
+	if err != nil {
+		t.Fatalf("failed to create key ring: %v", err)
+	}
+	return secrets.NewStore(ring)
+}
+
+func TestSealOpenTargetSecrets(t *testing.T) {
+	ctx := context.Background()
+	s := newTestBackupService(t)
+
+	target := validSFTPParams().record()
+	target.ResticPassword = sql.NullString{String: "restic-password", Valid: true}
+	plain := *target
+	if err := s.sealTargetSecrets(ctx, target); err != nil {
+		t.Fatalf("failed to seal secrets: %v", err)
+	}
+	for name, value := range map[string]string{"restic password": target.ResticPassword.String, "private key": target.SftpPrivateKey.String} {
+		if !secrets.IsSealed(value) {
+			t.Errorf("%s: expected a sealed value, got %q", name, value)
+		}
+	}
+	if target.SecretKey.Valid {
+		t.Error("unset secrets should stay unset")
+	}
+
+	opened, err := s.openTargetSecrets(ctx, target)
+	if err != nil {
+		t.Fatalf("failed to open secrets: %v", err)
+	}
+	if opened.ResticPassword != plain.ResticPassword || opened.SftpPrivateKey != plain.SftpPrivateKey {
+		t.Errorf("unexpected opened secrets %+v", opened)
+	}
+	if !secrets.IsSealed(target.ResticPassword.String) {
+		t.Error("opening secrets should not modify the stored target")
+	}
+
+	other := newTestBackupService(t)
+	other.secrets = newTestSecretStore(t, bytes.Repeat([]byte{2}, 32))
+	if _, err := other.openTargetSecrets(ctx, target); err == nil {
+		t.Error("opening with another key: expected an error")
+	}
+}
+
+func TestResealStoredSecrets(t *testing.T) {
+	ctx := context.Background()
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	s := newTestBackupService(t)
+	s.queries = newTestDatabase(t, filepath.Join(t.TempDir(), "test.db"))
+	s.secrets = newTestSecretStore(t, oldKey)
+
+	sealed, err := s.secrets.Seal(ctx, "old-secret")
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	targets := map[string]*db.CreateBackupTargetParams{
+		"plaintext": {Name: "plaintext", Type: string(BackupTargetTypeS3), Engine: string(BackupEngineRestic),
+			SecretKey: sql.NullString{String: "s3-secret", Valid: true}, ResticPassword: sql.NullString{String: "restic", Valid: true}},
+		"previous key": {Name: "previous", Type: string(BackupTargetTypeSFTP), Engine: string(BackupEngineRestic),
+			SftpPrivateKey: sql.NullString{String: sealed, Valid: true}, ResticPassword: sql.NullString{String: sealed, Valid: true}},
+		"reference": {Name: "reference", Type: string(BackupTargetTypeLocal), Engine: string(BackupEngineRestic),
+			ResticPassword: sql.NullString{String: "env:RESTIC_PASSWORD", Valid: true}},
+	}
+	ids := make(map[string]int64)
+	for name, params := range targets {
+		target, err := s.queries.CreateBackupTarget(ctx, params)
+		if err != nil {
+			t.Fatalf("failed to create %s target: %v", name, err)
+		}
+		ids[name] = target.ID
+	}
+
+	s.secrets = newTestSecretStore(t, newKey, oldKey)
+	if _, err := s.resealStoredSecrets(ctx, s.queries); err != nil {
+		t.Fatalf("failed to reseal secrets: %v", err)
+	}
+
+	// Only the new key is needed to open the stored secrets
+	s.secrets = newTestSecretStore(t, newKey)
+	expected := map[string][]string{
+		"plaintext":    {"s3-secret", "restic", ""},
+		"previous key": {"", "old-secret", "old-secret"},
+	}
+	for name, values := range expected {
+		target, err := s.queries.GetBackupTarget(ctx, ids[name])
+		if err != nil {
+			t.Fatalf("failed to get %s target: %v", name, err)
+		}
+		for i, secret := range []sql.NullString{target.SecretKey, target.ResticPassword, target.SftpPrivateKey} {
+			if values[i] == "" {
+				continue
+			}
+			if !secrets.IsSealed(secret.String) {
+				t.Errorf("%s: expected secret %d to be sealed, got %q", name, i, secret.String)
+			}
+		}
+		opened, err := s.openTargetSecrets(ctx, target)
+		if err != nil {
+			t.Fatalf("%s: failed to open secrets: %v", name, err)
+		}
+		for i, secret := range []sql.NullString{opened.SecretKey, opened.ResticPassword, opened.SftpPrivateKey} {
+			if secret.String != values[i] {
+				t.Errorf("%s: expected secret %d to be %q, got %q", name, i, values[i], secret.String)
+			}
+		}
+	}
+
+	reference, err := s.queries.GetBackupTarget(ctx, ids["reference"])
+	if err != nil {
+		t.Fatalf("failed to get reference target: %v", err)
+	}
+	if reference.ResticPassword.String != "env:RESTIC_PASSWORD" {
+		t.Errorf("references should be stored as is, got %q", reference.ResticPassword.String)
+	}
+
+	// A secret sealed with an unknown key stops the run
+	s.secrets = newTestSecretStore(t, bytes.Repeat([]byte{3}, 32))
+	if err := s.queries.UpdateBackupTargetSecrets(ctx, &db.UpdateBackupTargetSecretsParams{
+		ID:             ids["previous key"],
+		ResticPassword: sql.NullString{String: sealed, Valid: true},
+	}); err != nil {
+		t.Fatalf("failed to update secrets: %v", err)
+	}
+	if _, err := s.resealStoredSecrets(ctx, s.queries); err == nil {
+		t.Error("unknown key: expected an error")
+	}
+}
@@ -7,9 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -20,7 +18,9 @@ import (
 
 	"github.com/chainlaunch/chainlaunch/pkg/config"
 	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/secrets"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	nodeservice "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
 	"github.com/chainlaunch/chainlaunch/pkg/notifications"
 	notificationService "github.com/chainlaunch/chainlaunch/pkg/notifications/service"
 	"github.com/robfig/cron/v3"
@@ -36,10 +36,17 @@ type BackupService struct {
 	logger              *logger.Logger
 	notificationService *notificationService.NotificationService
 	cronEntryIDs        map[int64]cron.EntryID
-	mu                  sync.Mutex
-	stopCh              chan struct{}
-	databasePath        string
-	configService       *config.ConfigService
+	// verifyEntryIDs are the cron entries verifying the backups of the schedules
+	verifyEntryIDs map[int64]cron.EntryID
+	mu             sync.Mutex
+	stopCh         chan struct{}
+	databasePath   string
+	configService  *config.ConfigService
+	nodeService    *nodeservice.NodeService
+	restoring      bool
+	secrets        *secrets.Store
+	// targetLocks serializes the operations writing to a target, see lockTarget
+	targetLocks map[int64]*sync.Mutex
 }
 
 // NewBackupService creates a new backup service
@@ -49,6 +56,8 @@ func NewBackupService(
 	notificationSvc *notificationService.NotificationService,
 	databasePath string,
 	configService *config.ConfigService,
+	nodeService *nodeservice.NodeService,
+	secretStore *secrets.Store,
 ) *BackupService {
 	c := cron.New(cron.WithSeconds())
 	c.Start()
@@ -59,11 +68,22 @@ func NewBackupService(
 		logger:              logger,
 		notificationService: notificationSvc,
 		cronEntryIDs:        make(map[int64]cron.EntryID),
+		verifyEntryIDs:      make(map[int64]cron.EntryID),
 		stopCh:              make(chan struct{}),
 		databasePath:        databasePath,
 		configService:       configService,
+		nodeService:         nodeService,
+		secrets:             secretStore,
+		targetLocks:         make(map[int64]*sync.Mutex),
 	}
 
+	// Encrypt the secrets of targets created before they were sealed, and
+	// again when the key encryption key is rotated
+	if _, err := service.resealStoredSecrets(context.Background(), queries); err != nil {
+		logger.Error("Failed to encrypt backup target secrets", "error", err)
+	}
+	secretStore.RegisterResealer("backup target secrets", service.resealStoredSecrets)
+
 	// Load and schedule existing backup schedules
 	service.loadExistingSchedules()
 
@@ -85,70 +105,85 @@ func (s *BackupService) loadExistingSchedules() {
 	}
 }
 
-// CreateBackupTarget creates a new backup target
+// CreateBackupTarget creates a new backup target after checking that its repository can be reached
 func (s *BackupService) CreateBackupTarget(ctx context.Context, params CreateBackupTargetParams) (*BackupTargetDTO, error) {
-	// Validate config based on type
-	if params.Type == BackupTargetTypeS3 {
-		// Validate required S3 fields
-		if params.BucketName == "" || params.Endpoint == "" ||
-			params.BucketPath == "" || params.AccessKeyID == "" ||
-			params.SecretKey == "" {
-			return nil, fmt.Errorf("missing required S3 configuration fields")
-		}
+	record := params.record()
+	if err := validateBackupTarget(record); err != nil {
+		return nil, err
+	}
 
-		// Validate endpoint URL format
-		_, err := url.Parse(params.Endpoint)
+	// Generate restic password unless one is given
+	resticPassword := params.RepositoryPassword
+	if resticPassword == "" {
+		generated, err := generateSecurePassword()
 		if err != nil {
-			return nil, fmt.Errorf("invalid endpoint URL: %w", err)
+			return nil, fmt.Errorf("failed to generate restic password: %w", err)
 		}
+		resticPassword = generated
 	}
+	record.ResticPassword = sql.NullString{String: resticPassword, Valid: true}
 
-	// Generate restic password
-	resticPassword, err := generateSecurePassword()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate restic password: %w", err)
+	if err := s.testBackupTarget(ctx, record); err != nil {
+		return nil, err
+	}
+	if err := s.sealTargetSecrets(ctx, record); err != nil {
+		return nil, err
 	}
 
 	target, err := s.queries.CreateBackupTarget(ctx, &db.CreateBackupTargetParams{
-		Name:           params.Name,
-		Type:           string(params.Type),
-		BucketName:     sql.NullString{String: params.BucketName, Valid: params.BucketName != ""},
-		Region:         sql.NullString{String: params.Region, Valid: params.Region != ""},
-		BucketPath:     sql.NullString{String: params.BucketPath, Valid: params.BucketPath != ""},
-		AccessKeyID:    sql.NullString{String: params.AccessKeyID, Valid: params.AccessKeyID != ""},
-		SecretKey:      sql.NullString{String: params.SecretKey, Valid: params.SecretKey != ""},
-		S3PathStyle:    sql.NullBool{Bool: params.ForcePathStyle, Valid: true},
-		Endpoint:       sql.NullString{String: params.Endpoint, Valid: params.Endpoint != ""},
-		ResticPassword: sql.NullString{String: resticPassword, Valid: true},
+		Name:           record.Name,
+		Type:           record.Type,
+		BucketName:     record.BucketName,
+		Region:         record.Region,
+		BucketPath:     record.BucketPath,
+		AccessKeyID:    record.AccessKeyID,
+		SecretKey:      record.SecretKey,
+		S3PathStyle:    record.S3PathStyle,
+		Endpoint:       record.Endpoint,
+		ResticPassword: record.ResticPassword,
+		LocalPath:      record.LocalPath,
+		SftpHost:       record.SftpHost,
+		SftpPort:       record.SftpPort,
+		SftpUsername:   record.SftpUsername,
+		SftpPrivateKey: record.SftpPrivateKey,
+		SftpPath:       record.SftpPath,
+		SftpHostKey:    record.SftpHostKey,
+		Engine:         record.Engine,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create backup target: %w", err)
 	}
 
-	return &BackupTargetDTO{
-		ID:             target.ID,
-		Name:           target.Name,
-		Type:           BackupTargetType(target.Type),
-		BucketName:     target.BucketName.String,
-		Region:         target.Region.String,
-		Endpoint:       target.Endpoint.String,
-		BucketPath:     target.BucketPath.String,
-		AccessKeyID:    target.AccessKeyID.String,
-		ForcePathStyle: target.S3PathStyle.Bool,
-		CreatedAt:      target.CreatedAt,
-		UpdatedAt:      &target.UpdatedAt.Time,
-	}, nil
+	return toBackupTargetDTO(target), nil
 }
 
 // CreateBackupSchedule creates a new backup schedule
 func (s *BackupService) CreateBackupSchedule(ctx context.Context, params CreateBackupScheduleParams) (*BackupScheduleDTO, error) {
+	selection, err := newNodeSelection(params.Scope, params.NodeIDs, params.NetworkID, params.Consistency, params.StopOrder)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateVerifyCron(params.VerifyCronExpression); err != nil {
+		return nil, err
+	}
+
 	schedule, err := s.queries.CreateBackupSchedule(ctx, &db.CreateBackupScheduleParams{
-		Name:           params.Name,
-		Description:    sql.NullString{String: params.Description, Valid: params.Description != ""},
-		CronExpression: params.CronExpression,
-		TargetID:       params.TargetID,
-		RetentionDays:  int64(params.RetentionDays),
-		Enabled:        params.Enabled,
+		Name:                 params.Name,
+		Description:          sql.NullString{String: params.Description, Valid: params.Description != ""},
+		CronExpression:       params.CronExpression,
+		TargetID:             params.TargetID,
+		RetentionDays:        int64(params.RetentionDays),
+		KeepLast:             int64(params.KeepLast),
+		KeepDaily:            int64(params.KeepDaily),
+		KeepWeekly:           int64(params.KeepWeekly),
+		KeepMonthly:          int64(params.KeepMonthly),
+		Enabled:              params.Enabled,
+		Scope:                string(selection.scope),
+		NodeIds:              selection.nodeIDs,
+		NetworkID:            selection.networkID,
+		ConsistencyStrategy:  string(selection.consistency),
+		StopOrder:            selection.stopOrder,
+		VerifyCronExpression: sql.NullString{String: params.VerifyCronExpression, Valid: params.VerifyCronExpression != ""},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create backup schedule: %w", err)
@@ -158,19 +193,7 @@ func (s *BackupService) CreateBackupSchedule(ctx context.Context, params CreateB
 		s.scheduleBackup(schedule)
 	}
 
-	return &BackupScheduleDTO{
-		ID:             schedule.ID,
-		Name:           schedule.Name,
-		Description:    schedule.Description.String,
-		CronExpression: schedule.CronExpression,
-		TargetID:       schedule.TargetID,
-		RetentionDays:  int(schedule.RetentionDays),
-		Enabled:        schedule.Enabled,
-		CreatedAt:      schedule.CreatedAt,
-		UpdatedAt:      &schedule.UpdatedAt.Time,
-		LastRunAt:      &schedule.LastRunAt.Time,
-		NextRunAt:      &schedule.NextRunAt.Time,
-	}, nil
+	return toBackupScheduleDTO(schedule), nil
 }
 
 // CreateBackup creates a new backup
@@ -260,10 +283,8 @@ type ResticSnapshot struct {
 }
 
 // Update getBackupSize to use total_bytes_processed from summary
-func (s *BackupService) getBackupSize(env []string) (int64, error) {
-	cmd := exec.Command("restic", "snapshots", "latest", "--json")
-	cmd.Env = append(os.Environ(), env...)
-	output, err := cmd.Output()
+func (s *BackupService) getBackupSize(ctx context.Context, repo *resticRepo) (int64, error) {
+	output, err := repo.command(ctx, "snapshots", "latest", "--json").Output()
 	if err != nil {
 		return 0, fmt.Errorf("restic snapshots failed: %w", err)
 	}
@@ -282,51 +303,8 @@ func (s *BackupService) getBackupSize(env []string) (int64, error) {
 	return snapshots[0].Summary.TotalBytesProcessed, nil
 }
 
-// Update performS3Backup to include S3 connection issue notifications
-func (s *BackupService) performS3Backup(ctx context.Context, backup *db.Backup, target *db.BackupTarget) error {
-	if !target.Endpoint.Valid || target.Endpoint.String == "" {
-		return fmt.Errorf("backup configuration error: endpoint is required")
-	}
-
-	// Parse endpoint URL to get the host
-	customURL, err := url.Parse(target.Endpoint.String)
-	if err != nil {
-		return fmt.Errorf("backup configuration error: invalid endpoint URL: %w", err)
-	}
-
-	// Set up restic environment variables for S3
-	env := []string{
-		fmt.Sprintf("AWS_ACCESS_KEY_ID=%s", target.AccessKeyID.String),
-		fmt.Sprintf("AWS_SECRET_ACCESS_KEY=%s", target.SecretKey.String),
-		fmt.Sprintf("RESTIC_PASSWORD=%s", target.ResticPassword.String),
-		fmt.Sprintf("AWS_ENDPOINT=%s", customURL.Host),
-	}
-
-	// Configure path style
-	if target.S3PathStyle.Bool {
-		env = append(env, "AWS_S3_FORCE_PATH_STYLE=true")
-	}
-
-	// Get repository URL
-	repoURL, err := s.getResticRepoURL(target)
-	if err != nil {
-		return fmt.Errorf("failed to construct repository URL: %w", err)
-	}
-	env = append(env, fmt.Sprintf("RESTIC_REPOSITORY=%s", repoURL))
-
-	// Initialize repository if it doesn't exist
-	if err := s.initResticRepo(env); err != nil {
-		// Check if it's a connection issue
-		if strings.Contains(err.Error(), "connection refused") ||
-			strings.Contains(err.Error(), "timeout") ||
-			strings.Contains(err.Error(), "no such host") ||
-			strings.Contains(err.Error(), "network") {
-			// Send S3 connection issue notification
-			s.notifyS3ConnectionIssue(ctx, target, err.Error())
-		}
-		return fmt.Errorf("failed to initialize restic repository: %w", err)
-	}
-
+// performResticBackup backs up the data directory to the restic repository of the target
+func (s *BackupService) performResticBackup(ctx context.Context, backup *db.Backup, target *db.BackupTarget) error {
 	// Construct .chainlaunch path
 	chainlaunchPath := s.configService.GetDataPath()
 
@@ -346,37 +324,12 @@ func (s *BackupService) performS3Backup(ctx context.Context, backup *db.Backup,
 	dbFileName := fmt.Sprintf("chainlaunch-%s.db", timestamp)
 	dbBackupPath := filepath.Join(dbsPath, dbFileName)
 
-	// Create a copy of the database file
+	// Create a consistent copy of the database, the live file may be mid-transaction
 	s.logger.Infof("Copying database from %s to %s", s.databasePath, dbBackupPath)
-
-	// Open source file
-	sourceFile, err := os.Open(s.databasePath)
-	if err != nil {
-		return fmt.Errorf("backup preparation error: failed to open source database file: %w", err)
-	}
-	defer sourceFile.Close()
-
-	// Create destination file
-	destFile, err := os.Create(dbBackupPath)
-	if err != nil {
-		return fmt.Errorf("backup preparation error: failed to create destination database file: %w", err)
-	}
-	defer destFile.Close()
-
-	// Copy the contents
-	if _, err := io.Copy(destFile, sourceFile); err != nil {
-		return fmt.Errorf("backup preparation error: failed to copy database file: %w", err)
-	}
-
-	// Ensure all data is written to disk
-	if err := destFile.Sync(); err != nil {
-		return fmt.Errorf("backup preparation error: failed to sync database file: %w", err)
+	if err := s.copyDatabase(ctx, dbBackupPath); err != nil {
+		return fmt.Errorf("backup preparation error: %w", err)
 	}
 
-	// Close files explicitly before backup
-	sourceFile.Close()
-	destFile.Close()
-
 	// Set up deferred cleanup to remove the database copy after backup
 	defer func() {
 		s.logger.Infof("Cleaning up database copy at %s", dbBackupPath)
@@ -385,9 +338,33 @@ func (s *BackupService) performS3Backup(ctx context.Context, backup *db.Backup,
 		}
 	}()
 
+	return s.runResticBackup(ctx, backup, target, chainlaunchPath)
+}
+
+// runResticBackup stores a snapshot of a directory in the restic repository of
+// the target and records it in the backup. The directory is the root of the
+// snapshot, it must hold the database copy in its dbs directory.
+func (s *BackupService) runResticBackup(ctx context.Context, backup *db.Backup, target *db.BackupTarget, dir string) error {
+	repo, err := s.openResticRepo(ctx, target)
+	if err != nil {
+		return fmt.Errorf("backup configuration error: %w", err)
+	}
+	defer repo.Close()
+
+	// Initialize repository if it doesn't exist
+	if err := s.initResticRepo(ctx, repo); err != nil {
+		// Check if it's a connection issue
+		if strings.Contains(err.Error(), "connection refused") ||
+			strings.Contains(err.Error(), "timeout") ||
+			strings.Contains(err.Error(), "no such host") ||
+			strings.Contains(err.Error(), "network") {
+			s.notifyConnectionIssue(ctx, target, err.Error())
+		}
+		return fmt.Errorf("failed to initialize restic repository: %w", err)
+	}
+
 	// Perform backup using restic with JSON output
-	cmd := exec.CommandContext(ctx, "restic", "backup", chainlaunchPath, "--json")
-	cmd.Env = append(os.Environ(), env...)
+	cmd := repo.command(ctx, "backup", dir, "--json")
 
 	// Create pipes for stdout and stderr
 	stdout, err := cmd.StdoutPipe()
@@ -407,6 +384,7 @@ func (s *BackupService) performS3Backup(ctx context.Context, backup *db.Backup,
 
 	// Create decoder for JSON output
 	decoder := json.NewDecoder(stdout)
+	var snapshotID string
 
 	// Read JSON messages
 	for {
@@ -415,6 +393,7 @@ func (s *BackupService) performS3Backup(ctx context.Context, backup *db.Backup,
 			DataSize    int64  `json:"data_size,omitempty"`
 			TotalFiles  int    `json:"total_files,omitempty"`
 			TotalBytes  int64  `json:"total_bytes,omitempty"`
+			SnapshotID  string `json:"snapshot_id,omitempty"`
 		}
 
 		if err := decoder.Decode(&message); err != nil {
@@ -429,6 +408,9 @@ func (s *BackupService) performS3Backup(ctx context.Context, backup *db.Backup,
 		if message.MessageType == "status" {
 			s.logger.Infof("Backup progress: %d files, %d bytes", message.TotalFiles, message.TotalBytes)
 		}
+		if message.MessageType == "summary" {
+			snapshotID = message.SnapshotID
+		}
 	}
 	// Read stderr for any errors
 	errBuf := new(bytes.Buffer)
@@ -442,32 +424,32 @@ func (s *BackupService) performS3Backup(ctx context.Context, backup *db.Backup,
 		if strings.Contains(errMsg, "connection refused") ||
 			strings.Contains(errMsg, "timeout") ||
 			strings.Contains(errMsg, "no such host") {
-			// Send S3 connection issue notification
-			s.notifyS3ConnectionIssue(ctx, target, errMsg)
+			s.notifyConnectionIssue(ctx, target, errMsg)
 			return fmt.Errorf("network error: failed to connect to backup storage: %s", errMsg)
 		} else if strings.Contains(errMsg, "permission denied") {
-			s.notifyS3ConnectionIssue(ctx, target, errMsg)
+			s.notifyConnectionIssue(ctx, target, errMsg)
 			return fmt.Errorf("access error: insufficient permissions to access backup storage: %s", errMsg)
 		} else if strings.Contains(errMsg, "no space left") {
 			return fmt.Errorf("storage error: insufficient space in backup storage: %s", errMsg)
 		}
 		return fmt.Errorf("backup process error: %s: %w", errMsg, err)
 	}
-	backupSize, err := s.getBackupSize(env)
+	backupSize, err := s.getBackupSize(ctx, repo)
 	if err != nil {
 		return fmt.Errorf("failed to get backup size: %w", err)
 	}
-	// Update backup size
-	_, err = s.queries.UpdateBackupSize(ctx, &db.UpdateBackupSizeParams{
-		ID:        backup.ID,
-		SizeBytes: sql.NullInt64{Int64: backupSize, Valid: true},
+	// Record the snapshot and its size
+	_, err = s.queries.UpdateBackupSnapshot(ctx, &db.UpdateBackupSnapshotParams{
+		ID:         backup.ID,
+		SnapshotID: sql.NullString{String: snapshotID, Valid: snapshotID != ""},
+		SizeBytes:  sql.NullInt64{Int64: backupSize, Valid: true},
 	})
 
 	return err
 }
 
-// notifyS3ConnectionIssue sends a notification for S3 connection issues
-func (s *BackupService) notifyS3ConnectionIssue(ctx context.Context, target *db.BackupTarget, errorMessage string) {
+// notifyConnectionIssue sends a notification when the storage of a backup target can't be reached
+func (s *BackupService) notifyConnectionIssue(ctx context.Context, target *db.BackupTarget, errorMessage string) {
 	// Skip notification if notification service is not available
 	if s.notificationService == nil {
 		s.logger.Info("Notification service not available, skipping S3 connection issue notification")
@@ -482,6 +464,13 @@ func (s *BackupService) notifyS3ConnectionIssue(ctx context.Context, target *db.
 		DetectedTime: time.Now(),
 		ErrorMessage: errorMessage,
 	}
+	switch BackupTargetType(target.Type) {
+	case BackupTargetTypeLocal:
+		data.Endpoint = target.LocalPath.String
+	case BackupTargetTypeSFTP:
+		data.Endpoint = target.SftpHost.String
+		data.BucketName = target.SftpPath.String
+	}
 
 	// Send notification
 	err := s.notificationService.SendS3ConnectionIssueNotification(ctx, data)
@@ -527,6 +516,8 @@ func (s *BackupService) scheduleBackup(schedule *db.BackupSchedule) {
 
 	s.cronEntryIDs[schedule.ID] = entryID
 	s.logger.Info("Scheduled backup", "scheduleID", schedule.ID, "cronExpression", schedule.CronExpression)
+
+	s.scheduleVerification(schedule)
 }
 
 // createScheduledBackup creates a backup from a schedule
@@ -548,8 +539,19 @@ func (s *BackupService) createScheduledBackup(ctx context.Context, schedule *db.
 		LastRunAt: sql.NullTime{Time: time.Now(), Valid: true},
 	})
 
-	// Start backup process
-	go s.performBackup(backup)
+	// Start backup process, then remove the backups the retention policy no longer keeps
+	go func() {
+		s.performBackup(backup)
+
+		ctx := context.Background()
+		completed, err := s.queries.GetBackup(ctx, backup.ID)
+		if err != nil || BackupStatus(completed.Status) != BackupStatusCompleted {
+			return
+		}
+		if _, err := s.EnforceRetention(ctx, schedule.ID, false); err != nil {
+			s.logger.Error("Failed to enforce backup retention", "error", err, "scheduleID", schedule.ID)
+		}
+	}()
 }
 
 // performBackup executes the actual backup process
@@ -582,14 +584,35 @@ func (s *BackupService) performBackup(backup *db.Backup) {
 		return
 	}
 
+	// Scheduled backups may only cover some nodes, or make the nodes consistent first
+	var schedule *db.BackupSchedule
+	if backup.ScheduleID.Valid {
+		schedule, err = s.queries.GetBackupSchedule(ctx, backup.ScheduleID.Int64)
+		if err != nil {
+			errorMsg := fmt.Sprintf("Database error: Failed to get backup schedule: %v", err)
+			s.markBackupFailed(ctx, backup.ID, errorMsg)
+			s.notifyBackupFailure(ctx, backup, errorMsg)
+			return
+		}
+	}
+
 	// Perform backup based on target type
 	var backupErr error
+	unlock := s.lockTarget(target.ID)
 	switch BackupTargetType(target.Type) {
-	case BackupTargetTypeS3:
-		backupErr = s.performS3Backup(ctx, backup, target)
+	case BackupTargetTypeS3, BackupTargetTypeLocal, BackupTargetTypeSFTP:
+		switch {
+		case schedule != nil && isNodeBackup(schedule):
+			backupErr = s.performNodeBackup(ctx, backup, target, schedule)
+		case BackupEngine(target.Engine) == BackupEngineNative:
+			backupErr = s.performNativeBackup(ctx, backup, target)
+		default:
+			backupErr = s.performResticBackup(ctx, backup, target)
+		}
 	default:
 		backupErr = fmt.Errorf("Configuration error: Unsupported backup target type: %s", target.Type)
 	}
+	unlock()
 
 	if backupErr != nil {
 		s.markBackupFailed(ctx, backup.ID, backupErr.Error())
@@ -623,19 +646,7 @@ func (s *BackupService) ListBackupTargets(ctx context.Context) ([]*BackupTargetD
 
 	dtos := make([]*BackupTargetDTO, len(targets))
 	for i, target := range targets {
-		dtos[i] = &BackupTargetDTO{
-			ID:             target.ID,
-			Name:           target.Name,
-			Type:           BackupTargetType(target.Type),
-			BucketName:     target.BucketName.String,
-			Region:         target.Region.String,
-			Endpoint:       target.Endpoint.String,
-			BucketPath:     target.BucketPath.String,
-			AccessKeyID:    target.AccessKeyID.String,
-			ForcePathStyle: target.S3PathStyle.Bool,
-			CreatedAt:      target.CreatedAt,
-			UpdatedAt:      &target.UpdatedAt.Time,
-		}
+		dtos[i] = toBackupTargetDTO(target)
 	}
 
 	return dtos, nil
@@ -648,19 +659,7 @@ func (s *BackupService) GetBackupTarget(ctx context.Context, id int64) (*BackupT
 		return nil, fmt.Errorf("failed to get backup target: %w", err)
 	}
 
-	return &BackupTargetDTO{
-		ID:             target.ID,
-		Name:           target.Name,
-		Type:           BackupTargetType(target.Type),
-		BucketName:     target.BucketName.String,
-		Region:         target.Region.String,
-		Endpoint:       target.Endpoint.String,
-		BucketPath:     target.BucketPath.String,
-		AccessKeyID:    target.AccessKeyID.String,
-		ForcePathStyle: target.S3PathStyle.Bool,
-		CreatedAt:      target.CreatedAt,
-		UpdatedAt:      &target.UpdatedAt.Time,
-	}, nil
+	return toBackupTargetDTO(target), nil
 }
 
 // DeleteBackupTarget deletes a backup target
@@ -677,19 +676,7 @@ func (s *BackupService) ListBackupSchedules(ctx context.Context) ([]*BackupSched
 
 	dtos := make([]*BackupScheduleDTO, len(schedules))
 	for i, schedule := range schedules {
-		dtos[i] = &BackupScheduleDTO{
-			ID:             schedule.ID,
-			Name:           schedule.Name,
-			Description:    schedule.Description.String,
-			CronExpression: schedule.CronExpression,
-			TargetID:       schedule.TargetID,
-			RetentionDays:  int(schedule.RetentionDays),
-			Enabled:        schedule.Enabled,
-			CreatedAt:      schedule.CreatedAt,
-			UpdatedAt:      &schedule.UpdatedAt.Time,
-			LastRunAt:      &schedule.LastRunAt.Time,
-			NextRunAt:      &schedule.NextRunAt.Time,
-		}
+		dtos[i] = toBackupScheduleDTO(schedule)
 	}
 
 	return dtos, nil
@@ -702,19 +689,7 @@ func (s *BackupService) GetBackupSchedule(ctx context.Context, id int64) (*Backu
 		return nil, fmt.Errorf("failed to get backup schedule: %w", err)
 	}
 
-	return &BackupScheduleDTO{
-		ID:             schedule.ID,
-		Name:           schedule.Name,
-		Description:    schedule.Description.String,
-		CronExpression: schedule.CronExpression,
-		TargetID:       schedule.TargetID,
-		RetentionDays:  int(schedule.RetentionDays),
-		Enabled:        schedule.Enabled,
-		CreatedAt:      schedule.CreatedAt,
-		UpdatedAt:      &schedule.UpdatedAt.Time,
-		LastRunAt:      &schedule.LastRunAt.Time,
-		NextRunAt:      &schedule.NextRunAt.Time,
-	}, nil
+	return toBackupScheduleDTO(schedule), nil
 }
 
 // EnableBackupSchedule enables a backup schedule
@@ -726,19 +701,7 @@ func (s *BackupService) EnableBackupSchedule(ctx context.Context, id int64) (*Ba
 
 	s.scheduleBackup(schedule)
 
-	return &BackupScheduleDTO{
-		ID:             schedule.ID,
-		Name:           schedule.Name,
-		Description:    schedule.Description.String,
-		CronExpression: schedule.CronExpression,
-		TargetID:       schedule.TargetID,
-		RetentionDays:  int(schedule.RetentionDays),
-		Enabled:        schedule.Enabled,
-		CreatedAt:      schedule.CreatedAt,
-		UpdatedAt:      &schedule.UpdatedAt.Time,
-		LastRunAt:      &schedule.LastRunAt.Time,
-		NextRunAt:      &schedule.NextRunAt.Time,
-	}, nil
+	return toBackupScheduleDTO(schedule), nil
 }
 
 // DisableBackupSchedule disables a backup schedule
@@ -755,21 +718,10 @@ func (s *BackupService) DisableBackupSchedule(ctx context.Context, id int64) (*B
 		delete(s.cronEntryIDs, schedule.ID)
 		s.logger.Info("Removed backup schedule from cron scheduler", "scheduleID", schedule.ID)
 	}
+	s.unscheduleVerification(schedule.ID)
 	s.mu.Unlock()
 
-	return &BackupScheduleDTO{
-		ID:             schedule.ID,
-		Name:           schedule.Name,
-		Description:    schedule.Description.String,
-		CronExpression: schedule.CronExpression,
-		TargetID:       schedule.TargetID,
-		RetentionDays:  int(schedule.RetentionDays),
-		Enabled:        schedule.Enabled,
-		CreatedAt:      schedule.CreatedAt,
-		UpdatedAt:      &schedule.UpdatedAt.Time,
-		LastRunAt:      &schedule.LastRunAt.Time,
-		NextRunAt:      &schedule.NextRunAt.Time,
-	}, nil
+	return toBackupScheduleDTO(schedule), nil
 }
 
 // DeleteBackupSchedule deletes a backup schedule
@@ -795,10 +747,13 @@ func (s *BackupService) ListBackups(ctx context.Context) ([]*BackupDTO, error) {
 			TargetID:     backup.TargetID,
 			Status:       BackupStatus(backup.Status),
 			SizeBytes:    &backup.SizeBytes.Int64,
+			SnapshotID:   backup.SnapshotID.String,
 			StartedAt:    backup.StartedAt,
 			CompletedAt:  &backup.CompletedAt.Time,
 			ErrorMessage: &backup.ErrorMessage.String,
 			CreatedAt:    backup.CreatedAt,
+			Nodes:        backupNodes(backup),
+			Verification: backupVerification(backup),
 		}
 	}
 
@@ -821,10 +776,13 @@ func (s *BackupService) GetBackup(ctx context.Context, id int64) (*BackupDTO, er
 		TargetID:     backup.TargetID,
 		Status:       BackupStatus(backup.Status),
 		SizeBytes:    &backup.SizeBytes.Int64,
+		SnapshotID:   backup.SnapshotID.String,
 		StartedAt:    backup.StartedAt,
 		CompletedAt:  &backup.CompletedAt.Time,
 		ErrorMessage: &backup.ErrorMessage.String,
 		CreatedAt:    backup.CreatedAt,
+		Nodes:        backupNodes(backup),
+		Verification: backupVerification(backup),
 	}, nil
 }
 
@@ -858,48 +816,31 @@ func (s *BackupService) DeleteBackup(ctx context.Context, id int64) error {
 	return nil
 }
 
-// deleteBackupFile deletes the actual backup file from storage
+// deleteBackupFile deletes the backup snapshot from the repository of the target
 func (s *BackupService) deleteBackupFile(ctx context.Context, backup *db.Backup, target *db.BackupTarget) error {
-	switch BackupTargetType(target.Type) {
-	case BackupTargetTypeS3:
-		return s.deleteS3BackupFile(ctx, backup, target)
-	default:
-		return fmt.Errorf("unsupported backup target type: %s", target.Type)
-	}
-}
-
-// deleteS3BackupFile deletes a backup file from S3 using restic
-func (s *BackupService) deleteS3BackupFile(ctx context.Context, backup *db.Backup, target *db.BackupTarget) error {
-	// Set up restic environment variables
-	env := []string{
-		fmt.Sprintf("AWS_ACCESS_KEY_ID=%s", target.AccessKeyID.String),
-		fmt.Sprintf("AWS_SECRET_ACCESS_KEY=%s", target.SecretKey.String),
-		fmt.Sprintf("RESTIC_PASSWORD=%s", target.ResticPassword.String),
-		fmt.Sprintf("AWS_ENDPOINT=%s", target.Endpoint.String),
-	}
+	defer s.lockTarget(target.ID)()
 
-	if target.S3PathStyle.Bool {
-		env = append(env, "AWS_S3_FORCE_PATH_STYLE=true")
+	if BackupEngine(target.Engine) == BackupEngineNative {
+		return s.deleteNativeSnapshot(ctx, backup, target)
 	}
 
-	// Get repository URL
-	repoURL, err := s.getResticRepoURL(target)
+	repo, err := s.openResticRepo(ctx, target)
 	if err != nil {
-		return fmt.Errorf("failed to construct repository URL: %w", err)
+		return err
 	}
-	env = append(env, fmt.Sprintf("RESTIC_REPOSITORY=%s", repoURL))
+	defer repo.Close()
 
-	// Get latest snapshot ID
-	snapshotID, err := s.findLatestSnapshot(env)
-	if err != nil {
-		return fmt.Errorf("failed to find snapshot: %w", err)
+	// Backups taken before snapshots were recorded fall back to the latest snapshot
+	snapshotID := backup.SnapshotID.String
+	if !backup.SnapshotID.Valid {
+		snapshotID, err = s.findLatestSnapshot(ctx, repo)
+		if err != nil {
+			return fmt.Errorf("failed to find snapshot: %w", err)
+		}
 	}
 
 	// Delete the snapshot
-	cmd := exec.Command("restic", "forget", "--remove-snapshots", snapshotID)
-	cmd.Env = append(os.Environ(), env...)
-
-	output, err := cmd.CombinedOutput()
+	output, err := repo.command(ctx, "forget", "--remove-snapshots", snapshotID).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("failed to delete snapshot: %s: %w", string(output), err)
 	}
@@ -910,10 +851,8 @@ func (s *BackupService) deleteS3BackupFile(ctx context.Context, backup *db.Backu
 
 // Helper functions for restic operations
 
-func (s *BackupService) initResticRepo(env []string) error {
-	cmd := exec.Command("restic", "init")
-	cmd.Env = append(os.Environ(), env...)
-	output, err := cmd.CombinedOutput()
+func (s *BackupService) initResticRepo(ctx context.Context, repo *resticRepo) error {
+	output, err := repo.command(ctx, "init").CombinedOutput()
 	if err != nil {
 		// Ignore error if repository already exists
 		if strings.Contains(string(output), "already initialized") {
@@ -925,10 +864,8 @@ func (s *BackupService) initResticRepo(env []string) error {
 }
 
 // Update findSnapshotWithFile to use latest snapshot
-func (s *BackupService) findLatestSnapshot(env []string) (string, error) {
-	cmd := exec.Command("restic", "snapshots", "latest", "--json")
-	cmd.Env = append(os.Environ(), env...)
-	output, err := cmd.Output()
+func (s *BackupService) findLatestSnapshot(ctx context.Context, repo *resticRepo) (string, error) {
+	output, err := repo.command(ctx, "snapshots", "latest", "--json").Output()
 	if err != nil {
 		return "", fmt.Errorf("restic snapshots failed: %w", err)
 	}
@@ -955,36 +892,59 @@ func generateSecurePassword() (string, error) {
 	return base64.URLEncoding.EncodeToString(bytes), nil
 }
 
-// UpdateBackupTarget updates an existing backup target
+// UpdateBackupTarget updates an existing backup target after checking that its repository can be reached
 func (s *BackupService) UpdateBackupTarget(ctx context.Context, params UpdateBackupTargetParams) (*BackupTargetDTO, error) {
-	// Validate config based on type
-	if params.Type == BackupTargetTypeS3 {
-		// Validate required S3 fields
-		if params.BucketName == "" || params.Endpoint == "" ||
-			params.BucketPath == "" || params.AccessKeyID == "" ||
-			params.SecretKey == "" {
-			return nil, fmt.Errorf("configuration error: missing required S3 configuration fields")
+	existing, err := s.queries.GetBackupTarget(ctx, params.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("backup target not found with ID: %d", params.ID)
 		}
+		return nil, fmt.Errorf("failed to get backup target: %w", err)
+	}
 
-		// Validate endpoint URL format
-		_, err := url.Parse(params.Endpoint)
-		if err != nil {
-			return nil, fmt.Errorf("configuration error: invalid endpoint URL: %w", err)
-		}
+	record := params.record()
+	record.ResticPassword = existing.ResticPassword
+	// Secrets are never returned, an update without them keeps the stored ones
+	if params.SecretKey == "" && params.Type == BackupTargetType(existing.Type) {
+		record.SecretKey = existing.SecretKey
+	}
+	if params.SFTPPrivateKey == "" && params.Type == BackupTargetType(existing.Type) {
+		record.SftpPrivateKey = existing.SftpPrivateKey
+	}
+	if params.Engine == "" {
+		record.Engine = existing.Engine
+	}
+	if err := validateBackupTarget(record); err != nil {
+		return nil, err
+	}
+
+	if err := s.testBackupTarget(ctx, record); err != nil {
+		return nil, err
+	}
+	if err := s.sealTargetSecrets(ctx, record); err != nil {
+		return nil, err
 	}
 
 	// Update the target
 	target, err := s.queries.UpdateBackupTarget(ctx, &db.UpdateBackupTargetParams{
-		ID:          params.ID,
-		Name:        params.Name,
-		Type:        string(params.Type),
-		BucketName:  sql.NullString{String: params.BucketName, Valid: params.BucketName != ""},
-		Region:      sql.NullString{String: params.Region, Valid: params.Region != ""},
-		BucketPath:  sql.NullString{String: params.BucketPath, Valid: params.BucketPath != ""},
-		AccessKeyID: sql.NullString{String: params.AccessKeyID, Valid: params.AccessKeyID != ""},
-		SecretKey:   sql.NullString{String: params.SecretKey, Valid: params.SecretKey != ""},
-		S3PathStyle: sql.NullBool{Bool: params.ForcePathStyle, Valid: true},
-		Endpoint:    sql.NullString{String: params.Endpoint, Valid: params.Endpoint != ""},
+		ID:             params.ID,
+		Name:           record.Name,
+		Type:           record.Type,
+		BucketName:     record.BucketName,
+		Region:         record.Region,
+		BucketPath:     record.BucketPath,
+		AccessKeyID:    record.AccessKeyID,
+		SecretKey:      record.SecretKey,
+		S3PathStyle:    record.S3PathStyle,
+		Endpoint:       record.Endpoint,
+		LocalPath:      record.LocalPath,
+		SftpHost:       record.SftpHost,
+		SftpPort:       record.SftpPort,
+		SftpUsername:   record.SftpUsername,
+		SftpPrivateKey: record.SftpPrivateKey,
+		SftpPath:       record.SftpPath,
+		SftpHostKey:    record.SftpHostKey,
+		Engine:         record.Engine,
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -993,19 +953,7 @@ func (s *BackupService) UpdateBackupTarget(ctx context.Context, params UpdateBac
 		return nil, fmt.Errorf("failed to update backup target: %w", err)
 	}
 
-	return &BackupTargetDTO{
-		ID:             target.ID,
-		Name:           target.Name,
-		Type:           BackupTargetType(target.Type),
-		BucketName:     target.BucketName.String,
-		Region:         target.Region.String,
-		Endpoint:       target.Endpoint.String,
-		BucketPath:     target.BucketPath.String,
-		AccessKeyID:    target.AccessKeyID.String,
-		ForcePathStyle: target.S3PathStyle.Bool,
-		CreatedAt:      target.CreatedAt,
-		UpdatedAt:      &target.UpdatedAt.Time,
-	}, nil
+	return toBackupTargetDTO(target), nil
 }
 
 // UpdateBackupSchedule updates an existing backup schedule
@@ -1019,15 +967,33 @@ func (s *BackupService) UpdateBackupSchedule(ctx context.Context, params UpdateB
 		return nil, fmt.Errorf("failed to get existing backup schedule: %w", err)
 	}
 
+	selection, err := newNodeSelection(params.Scope, params.NodeIDs, params.NetworkID, params.Consistency, params.StopOrder)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateVerifyCron(params.VerifyCronExpression); err != nil {
+		return nil, err
+	}
+
 	// Update the schedule
 	schedule, err := s.queries.UpdateBackupSchedule(ctx, &db.UpdateBackupScheduleParams{
-		ID:             params.ID,
-		Name:           params.Name,
-		Description:    sql.NullString{String: params.Description, Valid: params.Description != ""},
-		CronExpression: params.CronExpression,
-		TargetID:       params.TargetID,
-		RetentionDays:  int64(params.RetentionDays),
-		Enabled:        params.Enabled,
+		ID:                   params.ID,
+		Name:                 params.Name,
+		Description:          sql.NullString{String: params.Description, Valid: params.Description != ""},
+		CronExpression:       params.CronExpression,
+		TargetID:             params.TargetID,
+		RetentionDays:        int64(params.RetentionDays),
+		KeepLast:             int64(params.KeepLast),
+		KeepDaily:            int64(params.KeepDaily),
+		KeepWeekly:           int64(params.KeepWeekly),
+		KeepMonthly:          int64(params.KeepMonthly),
+		Enabled:              params.Enabled,
+		Scope:                string(selection.scope),
+		NodeIds:              selection.nodeIDs,
+		NetworkID:            selection.networkID,
+		ConsistencyStrategy:  string(selection.consistency),
+		StopOrder:            selection.stopOrder,
+		VerifyCronExpression: sql.NullString{String: params.VerifyCronExpression, Valid: params.VerifyCronExpression != ""},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to update backup schedule: %w", err)
@@ -1047,6 +1013,7 @@ func (s *BackupService) UpdateBackupSchedule(ctx context.Context, params UpdateB
 				delete(s.cronEntryIDs, schedule.ID)
 				s.logger.Info("Disabled backup schedule", "scheduleID", schedule.ID)
 			}
+			s.unscheduleVerification(schedule.ID)
 			s.mu.Unlock()
 		}
 	} else if schedule.Enabled {
@@ -1064,19 +1031,7 @@ func (s *BackupService) UpdateBackupSchedule(ctx context.Context, params UpdateB
 		s.logger.Info("Updated backup schedule", "scheduleID", schedule.ID)
 	}
 
-	return &BackupScheduleDTO{
-		ID:             schedule.ID,
-		Name:           schedule.Name,
-		Description:    schedule.Description.String,
-		CronExpression: schedule.CronExpression,
-		TargetID:       schedule.TargetID,
-		RetentionDays:  int(schedule.RetentionDays),
-		Enabled:        schedule.Enabled,
-		CreatedAt:      schedule.CreatedAt,
-		UpdatedAt:      &schedule.UpdatedAt.Time,
-		LastRunAt:      &schedule.LastRunAt.Time,
-		NextRunAt:      &schedule.NextRunAt.Time,
-	}, nil
+	return toBackupScheduleDTO(schedule), nil
 }
 
 // notifyBackupSuccess sends a notification about a successful backup
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/backups/engine"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+)
+
+// snapshotEntry is a file of the data directory in a snapshot
+type snapshotEntry struct {
+	// Path is slash separated and relative to the data directory
+	Path    string
+	Dir     bool
+	Size    int64
+	ModTime time.Time
+	// SHA256 is the hash of the content when the engine records it
+	SHA256 string
+}
+
+// snapshotReader reads the content of one snapshot of a backup target
+type snapshotReader interface {
+	// entries lists the data directory of the snapshot
+	entries(ctx context.Context) ([]snapshotEntry, error)
+	// extract writes the data directory entries at or below the prefixes to
+	// dest, which must not exist yet. An empty prefix selects everything.
+	extract(ctx context.Context, prefixes []string, dest string) error
+	// extractDatabase writes the database copy of the snapshot to dest
+	extractDatabase(ctx context.Context, dest string) error
+	Close()
+}
+
+// underPrefixes reports whether a data directory path is at or below one of the prefixes
+func underPrefixes(p string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix == "" || p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// openSnapshot opens the snapshot of a backup on its target
+func (s *BackupService) openSnapshot(ctx context.Context, target *db.BackupTarget, backup *db.Backup) (snapshotReader, error) {
+	if !backup.SnapshotID.Valid {
+		return nil, fmt.Errorf("%w: backup %d has no recorded snapshot", ErrSnapshotUnavailable, backup.ID)
+	}
+
+	if BackupEngine(target.Engine) == BackupEngineNative {
+		repo, err := s.openNativeRepository(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSnapshotUnavailable, err)
+		}
+		snapshot, err := repo.Snapshot(ctx, backup.SnapshotID.String)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrSnapshotUnavailable, err)
+		}
+		return &nativeSnapshotReader{repo: repo, snapshot: snapshot}, nil
+	}
+
+	repo, err := s.openResticRepo(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	output, err := repo.command(ctx, "snapshots", backup.SnapshotID.String, "--json").Output()
+	if err != nil {
+		repo.Close()
+		return nil, fmt.Errorf("%w: restic snapshots failed: %v", ErrSnapshotUnavailable, err)
+	}
+	var snapshots []ResticSnapshot
+	if err := json.Unmarshal(output, &snapshots); err != nil {
+		repo.Close()
+		return nil, fmt.Errorf("failed to parse snapshots: %w", err)
+	}
+	if len(snapshots) == 0 || len(snapshots[0].Paths) == 0 {
+		repo.Close()
+		return nil, fmt.Errorf("%w: snapshot %s not found", ErrSnapshotUnavailable, backup.SnapshotID.String)
+	}
+	return &resticSnapshotReader{repo: repo, id: snapshots[0].ID, root: snapshots[0].Paths[0]}, nil
+}
+
+// nativeSnapshotReader reads a snapshot written by the native engine
+type nativeSnapshotReader struct {
+	repo     *engine.Repository
+	snapshot *engine.Snapshot
+}
+
+func (r *nativeSnapshotReader) entries(ctx context.Context) ([]snapshotEntry, error) {
+	var entries []snapshotEntry
+	for _, file := range r.snapshot.Files {
+		rel := strings.TrimPrefix(file.Path, NativeDataSource+"/")
+		if rel == file.Path {
+			continue
+		}
+		entries = append(entries, snapshotEntry{
+			Path:    rel,
+			Dir:     file.Type == engine.FileTypeDir,
+			Size:    file.Size,
+			ModTime: file.ModTime,
+			SHA256:  file.SHA256,
+		})
+	}
+	return entries, nil
+}
+
+func (r *nativeSnapshotReader) extract(ctx context.Context, prefixes []string, dest string) error {
+	if err := os.MkdirAll(dest, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	_, err := r.repo.Restore(ctx, r.snapshot, dest, engine.RestoreOptions{
+		Root: NativeDataSource,
+		Include: func(p string) bool {
+			rel := strings.TrimPrefix(p, NativeDataSource+"/")
+			return rel != p && underPrefixes(rel, prefixes)
+		},
+	})
+	return err
+}
+
+func (r *nativeSnapshotReader) extractDatabase(ctx context.Context, dest string) error {
+	_, err := r.repo.Restore(ctx, r.snapshot, dest, engine.RestoreOptions{Root: NativeDatabaseSource})
+	return err
+}
+
+func (r *nativeSnapshotReader) Close() {}
+
+// resticSnapshotReader reads a restic snapshot of the data directory
+type resticSnapshotReader struct {
+	repo *resticRepo
+	id   string
+	// root is the data directory at the time of the backup
+	root string
+}
+
+func (r *resticSnapshotReader) entries(ctx context.Context) ([]snapshotEntry, error) {
+	cmd := r.repo.command(ctx, "ls", r.id, "--json")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start restic ls: %w", err)
+	}
+
+	var entries []snapshotEntry
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var node struct {
+			Type  string    `json:"type"`
+			Path  string    `json:"path"`
+			Size  int64     `json:"size"`
+			MTime time.Time `json:"mtime"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &node); err != nil || node.Path == "" {
+			continue
+		}
+		rel := strings.TrimPrefix(node.Path, strings.TrimSuffix(r.root, "/")+"/")
+		if rel == node.Path {
+			continue
+		}
+		entries = append(entries, snapshotEntry{
+			Path:    rel,
+			Dir:     node.Type == "dir",
+			Size:    node.Size,
+			ModTime: node.MTime,
+		})
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("restic ls failed: %w", err)
+	}
+	return entries, scanner.Err()
+}
+
+func (r *resticSnapshotReader) extract(ctx context.Context, prefixes []string, dest string) error {
+	// restic restores the absolute paths of the snapshot below the target
+	staging := dest + ".restic"
+	defer os.RemoveAll(staging)
+
+	args := []string{"restore", r.id, "--target", staging}
+	for _, prefix := range prefixes {
+		args = append(args, "--include", path.Join(r.root, prefix))
+	}
+	if output, err := r.repo.command(ctx, args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("restic restore failed: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+
+	restored := filepath.Join(staging, filepath.FromSlash(r.root))
+	if _, err := os.Stat(restored); os.IsNotExist(err) {
+		return os.MkdirAll(dest, 0700)
+	}
+	return os.Rename(restored, dest)
+}
+
+func (r *resticSnapshotReader) extractDatabase(ctx context.Context, dest string) error {
+	entries, err := r.entries(ctx)
+	if err != nil {
+		return err
+	}
+	// The backup stores a timestamped copy of the database in the dbs directory
+	var copies []string
+	for _, entry := range entries {
+		if !entry.Dir && path.Dir(entry.Path) == "dbs" && strings.HasSuffix(entry.Path, ".db") {
+			copies = append(copies, entry.Path)
+		}
+	}
+	if len(copies) == 0 {
+		return fmt.Errorf("%w: the snapshot contains no database copy", ErrSnapshotUnavailable)
+	}
+	sort.Strings(copies)
+
+	staging := dest + ".dir"
+	if err := r.extract(ctx, copies[len(copies)-1:], staging); err != nil {
+		return err
+	}
+	defer os.RemoveAll(staging)
+	return os.Rename(filepath.Join(staging, filepath.FromSlash(copies[len(copies)-1])), dest)
+}
+
+func (r *resticSnapshotReader) Close() {
+	r.repo.Close()
+}
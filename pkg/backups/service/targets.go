@@ -0,0 +1,407 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/backups/engine"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+const (
+	// DefaultSFTPPort is the port used for SFTP targets that don't set one
+	DefaultSFTPPort = 22
+	// targetTestTimeout bounds the connectivity test run before a target is saved
+	targetTestTimeout = 60 * time.Second
+)
+
+var (
+	// sftpHostPattern matches DNS names and IPv4 addresses. Nothing starting
+	// with '-' or containing spaces can reach the ssh command line.
+	sftpHostPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+	// sftpUsernamePattern matches portable user names
+	sftpUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9._][A-Za-z0-9._-]*$`)
+)
+
+// resticRepo holds what restic needs to reach the repository of a backup target
+type resticRepo struct {
+	env     []string
+	options []string
+	cleanup func()
+}
+
+// command returns a restic command running against the repository
+func (r *resticRepo) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "restic", append(append([]string{}, r.options...), args...)...)
+	cmd.Env = append(os.Environ(), r.env...)
+	return cmd
+}
+
+// Close removes the temporary files created to access the repository
+func (r *resticRepo) Close() {
+	if r.cleanup != nil {
+		r.cleanup()
+	}
+}
+
+// SFTPRepositoryURL returns the restic repository URL of a directory on an SFTP server
+func SFTPRepositoryURL(username, host, path string) string {
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return fmt.Sprintf("sftp:%s@%s:%s", username, host, path)
+}
+
+// ValidateSFTPLogin checks that the username and host of an SFTP server are
+// safe to pass on the ssh command line, which restic splits on whitespace
+func ValidateSFTPLogin(username, host string) error {
+	if !sftpUsernamePattern.MatchString(username) {
+		return fmt.Errorf("invalid SFTP username %q", username)
+	}
+	if !sftpHostPattern.MatchString(host) && net.ParseIP(host) == nil {
+		return fmt.Errorf("invalid SFTP host %q", host)
+	}
+	return nil
+}
+
+// ParseSFTPHostKeys parses the pinned host keys of an SFTP server, one per
+// line, either as known_hosts entries or as bare public keys
+func ParseSFTPHostKeys(value string) ([]ssh.PublicKey, error) {
+	var keys []ssh.PublicKey
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "@") {
+			return nil, fmt.Errorf("host key markers are not supported: %s", strings.Fields(line)[0])
+		}
+		if _, _, key, _, _, err := ssh.ParseKnownHosts([]byte(line)); err == nil {
+			keys = append(keys, key)
+			continue
+		}
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("invalid host key %q: %w", line, err)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no host key found")
+	}
+	return keys, nil
+}
+
+// SFTPKnownHosts returns the known_hosts file content pinning the host keys
+// of an SFTP server
+func SFTPKnownHosts(host string, port int, hostKeys string) (string, error) {
+	keys, err := ParseSFTPHostKeys(hostKeys)
+	if err != nil {
+		return "", err
+	}
+	address := knownhosts.Normalize(net.JoinHostPort(host, strconv.Itoa(port)))
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(knownhosts.Line([]string{address}, key))
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// SFTPCommand returns the ssh command restic runs to reach an SFTP server
+// authenticating with a private key. The server must present one of the
+// host keys pinned in knownHostsFile.
+func SFTPCommand(username, host string, port int, keyFile, knownHostsFile string) string {
+	return strings.Join([]string{
+		"ssh",
+		"-i", fmt.Sprintf("%q", keyFile),
+		"-p", strconv.Itoa(port),
+		"-o", "BatchMode=yes",
+		"-o", "StrictHostKeyChecking=yes",
+		"-o", fmt.Sprintf("UserKnownHostsFile=%q", knownHostsFile),
+		"-o", "GlobalKnownHostsFile=/dev/null",
+		fmt.Sprintf("%s@%s", username, host), "-s", "sftp",
+	}, " ")
+}
+
+// validateBackupTarget checks the configuration required by the target type
+func validateBackupTarget(target *db.BackupTarget) error {
+	switch BackupEngine(target.Engine) {
+	case BackupEngineRestic:
+	case BackupEngineNative:
+		if BackupTargetType(target.Type) == BackupTargetTypeSFTP {
+			return fmt.Errorf("%w: the native engine does not support SFTP targets", ErrInvalidTargetConfig)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported backup engine %s", ErrInvalidTargetConfig, target.Engine)
+	}
+
+	switch BackupTargetType(target.Type) {
+	case BackupTargetTypeS3:
+		if target.BucketName.String == "" || target.Endpoint.String == "" ||
+			target.BucketPath.String == "" || target.AccessKeyID.String == "" ||
+			target.SecretKey.String == "" {
+			return fmt.Errorf("%w: missing required S3 configuration fields", ErrInvalidTargetConfig)
+		}
+		if _, err := url.Parse(target.Endpoint.String); err != nil {
+			return fmt.Errorf("%w: invalid endpoint URL: %v", ErrInvalidTargetConfig, err)
+		}
+	case BackupTargetTypeLocal:
+		if target.LocalPath.String == "" {
+			return fmt.Errorf("%w: local path is required", ErrInvalidTargetConfig)
+		}
+		if !filepath.IsAbs(target.LocalPath.String) {
+			return fmt.Errorf("%w: local path must be absolute", ErrInvalidTargetConfig)
+		}
+	case BackupTargetTypeSFTP:
+		if target.SftpHost.String == "" || target.SftpUsername.String == "" ||
+			target.SftpPath.String == "" || target.SftpPrivateKey.String == "" ||
+			target.SftpHostKey.String == "" {
+			return fmt.Errorf("%w: missing required SFTP configuration fields", ErrInvalidTargetConfig)
+		}
+		if err := ValidateSFTPLogin(target.SftpUsername.String, target.SftpHost.String); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTargetConfig, err)
+		}
+		if target.SftpPort.Valid && (target.SftpPort.Int64 < 1 || target.SftpPort.Int64 > 65535) {
+			return fmt.Errorf("%w: invalid SFTP port %d", ErrInvalidTargetConfig, target.SftpPort.Int64)
+		}
+		if _, err := ParseSFTPHostKeys(target.SftpHostKey.String); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTargetConfig, err)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported backup target type %s", ErrInvalidTargetConfig, target.Type)
+	}
+	return nil
+}
+
+// openResticRepo prepares the restic environment and options of a backup target
+func (s *BackupService) openResticRepo(ctx context.Context, target *db.BackupTarget) (*resticRepo, error) {
+	target, err := s.openTargetSecrets(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	repo := &resticRepo{
+		env: []string{fmt.Sprintf("RESTIC_PASSWORD=%s", target.ResticPassword.String)},
+	}
+
+	switch BackupTargetType(target.Type) {
+	case BackupTargetTypeS3:
+		repoURL, err := s.getResticRepoURL(target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct repository URL: %w", err)
+		}
+		customURL, err := url.Parse(target.Endpoint.String)
+		if err != nil {
+			return nil, fmt.Errorf("backup configuration error: invalid endpoint URL: %w", err)
+		}
+		repo.env = append(repo.env,
+			fmt.Sprintf("RESTIC_REPOSITORY=%s", repoURL),
+			fmt.Sprintf("AWS_ACCESS_KEY_ID=%s", target.AccessKeyID.String),
+			fmt.Sprintf("AWS_SECRET_ACCESS_KEY=%s", target.SecretKey.String),
+			fmt.Sprintf("AWS_ENDPOINT=%s", customURL.Host),
+		)
+		if target.S3PathStyle.Bool {
+			repo.env = append(repo.env, "AWS_S3_FORCE_PATH_STYLE=true")
+		}
+
+	case BackupTargetTypeLocal:
+		repo.env = append(repo.env, fmt.Sprintf("RESTIC_REPOSITORY=local:%s", target.LocalPath.String))
+
+	case BackupTargetTypeSFTP:
+		if err := ValidateSFTPLogin(target.SftpUsername.String, target.SftpHost.String); err != nil {
+			return nil, fmt.Errorf("backup configuration error: %w", err)
+		}
+		port := DefaultSFTPPort
+		if target.SftpPort.Valid {
+			port = int(target.SftpPort.Int64)
+		}
+		knownHosts, err := SFTPKnownHosts(target.SftpHost.String, port, target.SftpHostKey.String)
+		if err != nil {
+			return nil, fmt.Errorf("backup configuration error: %w", err)
+		}
+
+		// ssh only reads private keys and host keys from files
+		dir, err := os.MkdirTemp("", "chainlaunch-sftp-*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SFTP key directory: %w", err)
+		}
+		repo.cleanup = func() { os.RemoveAll(dir) }
+		key := target.SftpPrivateKey.String
+		if !strings.HasSuffix(key, "\n") {
+			key += "\n"
+		}
+		keyFile := filepath.Join(dir, "id")
+		if err := os.WriteFile(keyFile, []byte(key), 0600); err != nil {
+			repo.Close()
+			return nil, fmt.Errorf("failed to write SFTP key file: %w", err)
+		}
+		knownHostsFile := filepath.Join(dir, "known_hosts")
+		if err := os.WriteFile(knownHostsFile, []byte(knownHosts), 0600); err != nil {
+			repo.Close()
+			return nil, fmt.Errorf("failed to write SFTP known hosts file: %w", err)
+		}
+
+		repo.env = append(repo.env, fmt.Sprintf("RESTIC_REPOSITORY=%s",
+			SFTPRepositoryURL(target.SftpUsername.String, target.SftpHost.String, target.SftpPath.String)))
+		repo.options = []string{"-o", "sftp.command=" + SFTPCommand(
+			target.SftpUsername.String,
+			target.SftpHost.String,
+			port,
+			keyFile,
+			knownHostsFile,
+		)}
+
+	default:
+		return nil, fmt.Errorf("unsupported backup target type: %s", target.Type)
+	}
+
+	return repo, nil
+}
+
+// testBackupTarget checks that the repository of a target can be reached and
+// opened with its restic password, initializing it when it doesn't exist yet
+func (s *BackupService) testBackupTarget(ctx context.Context, target *db.BackupTarget) error {
+	ctx, cancel := context.WithTimeout(ctx, targetTestTimeout)
+	defer cancel()
+
+	if BackupTargetType(target.Type) == BackupTargetTypeLocal {
+		if err := os.MkdirAll(target.LocalPath.String, 0700); err != nil {
+			return fmt.Errorf("%w: failed to create %s: %v", ErrTargetUnreachable, target.LocalPath.String, err)
+		}
+		probe, err := os.CreateTemp(target.LocalPath.String, ".chainlaunch-probe-*")
+		if err != nil {
+			return fmt.Errorf("%w: %s is not writable: %v", ErrTargetUnreachable, target.LocalPath.String, err)
+		}
+		probe.Close()
+		os.Remove(probe.Name())
+	}
+
+	if BackupEngine(target.Engine) == BackupEngineNative {
+		if _, err := s.openNativeRepository(ctx, target); err != nil {
+			if errors.Is(err, engine.ErrWrongPassword) {
+				return fmt.Errorf("%w: the repository already exists and is encrypted with another password", ErrTargetUnreachable)
+			}
+			return fmt.Errorf("%w: %v", ErrTargetUnreachable, err)
+		}
+		return nil
+	}
+
+	if _, err := exec.LookPath("restic"); err != nil {
+		return fmt.Errorf("%w: the restic binary was not found, install it or use the %s engine", ErrTargetUnreachable, BackupEngineNative)
+	}
+	repo, err := s.openResticRepo(ctx, target)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	if err := s.initResticRepo(ctx, repo); err != nil {
+		return fmt.Errorf("%w: %v", ErrTargetUnreachable, err)
+	}
+
+	// An existing repository must be readable with the password of the target
+	output, err := repo.command(ctx, "cat", "config").CombinedOutput()
+	if err != nil {
+		if strings.Contains(string(output), "wrong password") {
+			return fmt.Errorf("%w: the repository already exists and is encrypted with another password", ErrTargetUnreachable)
+		}
+		return fmt.Errorf("%w: %s", ErrTargetUnreachable, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// record builds the stored form of the backup target
+func (p CreateBackupTargetParams) record() *db.BackupTarget {
+	backupEngine := p.Engine
+	if backupEngine == "" {
+		backupEngine = BackupEngineRestic
+	}
+	return &db.BackupTarget{
+		Name:           p.Name,
+		Type:           string(p.Type),
+		Engine:         string(backupEngine),
+		BucketName:     sql.NullString{String: p.BucketName, Valid: p.BucketName != ""},
+		Region:         sql.NullString{String: p.Region, Valid: p.Region != ""},
+		BucketPath:     sql.NullString{String: p.BucketPath, Valid: p.BucketPath != ""},
+		AccessKeyID:    sql.NullString{String: p.AccessKeyID, Valid: p.AccessKeyID != ""},
+		SecretKey:      sql.NullString{String: p.SecretKey, Valid: p.SecretKey != ""},
+		S3PathStyle:    sql.NullBool{Bool: p.ForcePathStyle, Valid: true},
+		Endpoint:       sql.NullString{String: p.Endpoint, Valid: p.Endpoint != ""},
+		LocalPath:      sql.NullString{String: p.LocalPath, Valid: p.LocalPath != ""},
+		SftpHost:       sql.NullString{String: p.SFTPHost, Valid: p.SFTPHost != ""},
+		SftpPort:       sql.NullInt64{Int64: int64(p.SFTPPort), Valid: p.SFTPPort != 0},
+		SftpUsername:   sql.NullString{String: p.SFTPUsername, Valid: p.SFTPUsername != ""},
+		SftpPrivateKey: sql.NullString{String: p.SFTPPrivateKey, Valid: p.SFTPPrivateKey != ""},
+		SftpPath:       sql.NullString{String: p.SFTPPath, Valid: p.SFTPPath != ""},
+		SftpHostKey:    sql.NullString{String: p.SFTPHostKey, Valid: p.SFTPHostKey != ""},
+	}
+}
+
+// record builds the stored form of the backup target
+func (p UpdateBackupTargetParams) record() *db.BackupTarget {
+	target := CreateBackupTargetParams{
+		Name:           p.Name,
+		Type:           p.Type,
+		Engine:         p.Engine,
+		BucketName:     p.BucketName,
+		BucketPath:     p.BucketPath,
+		Endpoint:       p.Endpoint,
+		AccessKeyID:    p.AccessKeyID,
+		SecretKey:      p.SecretKey,
+		Region:         p.Region,
+		ForcePathStyle: p.ForcePathStyle,
+		LocalPath:      p.LocalPath,
+		SFTPHost:       p.SFTPHost,
+		SFTPPort:       p.SFTPPort,
+		SFTPUsername:   p.SFTPUsername,
+		SFTPPrivateKey: p.SFTPPrivateKey,
+		SFTPPath:       p.SFTPPath,
+		SFTPHostKey:    p.SFTPHostKey,
+	}.record()
+	target.ID = p.ID
+	return target
+}
+
+// toBackupTargetDTO converts a stored backup target, leaving out its secrets
+func toBackupTargetDTO(target *db.BackupTarget) *BackupTargetDTO {
+	dto := &BackupTargetDTO{
+		ID:             target.ID,
+		Name:           target.Name,
+		Type:           BackupTargetType(target.Type),
+		Engine:         BackupEngine(target.Engine),
+		BucketName:     target.BucketName.String,
+		Region:         target.Region.String,
+		Endpoint:       target.Endpoint.String,
+		BucketPath:     target.BucketPath.String,
+		AccessKeyID:    target.AccessKeyID.String,
+		ForcePathStyle: target.S3PathStyle.Bool,
+		LocalPath:      target.LocalPath.String,
+		SFTPHost:       target.SftpHost.String,
+		SFTPUsername:   target.SftpUsername.String,
+		SFTPPath:       target.SftpPath.String,
+		SFTPHostKey:    target.SftpHostKey.String,
+		CreatedAt:      target.CreatedAt,
+		UpdatedAt:      &target.UpdatedAt.Time,
+	}
+	if BackupTargetType(target.Type) == BackupTargetTypeSFTP {
+		dto.SFTPPort = DefaultSFTPPort
+		if target.SftpPort.Valid {
+			dto.SFTPPort = int(target.SftpPort.Int64)
+		}
+	}
+	return dto
+}
@@ -8,7 +8,67 @@ import (
 type BackupTargetType string
 
 const (
-	BackupTargetTypeS3 BackupTargetType = "S3"
+	BackupTargetTypeS3    BackupTargetType = "S3"
+	BackupTargetTypeLocal BackupTargetType = "LOCAL"
+	BackupTargetTypeSFTP  BackupTargetType = "SFTP"
+)
+
+// BackupEngine is the program that writes the snapshots of a backup target
+type BackupEngine string
+
+const (
+	// BackupEngineRestic runs the restic binary
+	BackupEngineRestic BackupEngine = "RESTIC"
+	// BackupEngineNative uses the built-in engine, no external binary is needed
+	BackupEngineNative BackupEngine = "NATIVE"
+)
+
+// BackupScope selects what the backups of a schedule contain
+type BackupScope string
+
+const (
+	// BackupScopeAll backs up the whole data directory
+	BackupScopeAll BackupScope = "ALL"
+	// BackupScopeNodes backs up the nodes listed by the schedule
+	BackupScopeNodes BackupScope = "NODES"
+	// BackupScopeNetwork backs up the nodes of a network
+	BackupScopeNetwork BackupScope = "NETWORK"
+)
+
+// ConsistencyStrategy is how the files of running nodes are made consistent
+// before they are backed up. Nodes a strategy doesn't apply to are stopped.
+type ConsistencyStrategy string
+
+const (
+	// ConsistencyNone copies the files of the nodes while they keep writing
+	ConsistencyNone ConsistencyStrategy = "NONE"
+	// ConsistencyLedgerSnapshot backs up ledger snapshots of Fabric peers
+	// instead of their live ledger, the peers keep running
+	ConsistencyLedgerSnapshot ConsistencyStrategy = "LEDGER_SNAPSHOT"
+	// ConsistencyStopStart stops the nodes while their files are copied
+	ConsistencyStopStart ConsistencyStrategy = "STOP_START"
+	// ConsistencyDockerExport pauses docker nodes while their mounts are exported
+	ConsistencyDockerExport ConsistencyStrategy = "DOCKER_EXPORT"
+)
+
+// VerificationStatus is the outcome of the verification of a backup
+type VerificationStatus string
+
+const (
+	VerificationStatusPassed VerificationStatus = "PASSED"
+	VerificationStatusFailed VerificationStatus = "FAILED"
+)
+
+// Checks of a backup verification
+const (
+	// VerificationCheckRepository checks the integrity of the repository of the target
+	VerificationCheckRepository = "REPOSITORY"
+	// VerificationCheckRestore restores the backup to a scratch directory
+	VerificationCheckRestore = "TEST_RESTORE"
+	// VerificationCheckDatabase runs the SQLite integrity check on the restored database
+	VerificationCheckDatabase = "DATABASE"
+	// VerificationCheckMSP checks the restored MSP directories of the Fabric nodes
+	VerificationCheckMSP = "MSP"
 )
 
 // BackupStatus represents the status of a backup
@@ -43,12 +103,19 @@ type BackupTargetDTO struct {
 	ID             int64            `json:"id"`
 	Name           string           `json:"name"`
 	Type           BackupTargetType `json:"type"`
+	Engine         BackupEngine     `json:"engine"`
 	BucketName     string           `json:"bucketName,omitempty"`
 	Region         string           `json:"region,omitempty"`
 	Endpoint       string           `json:"endpoint,omitempty"`
 	BucketPath     string           `json:"bucketPath,omitempty"`
 	AccessKeyID    string           `json:"accessKeyId,omitempty"`
 	ForcePathStyle bool             `json:"forcePathStyle,omitempty"`
+	LocalPath      string           `json:"localPath,omitempty"`
+	SFTPHost       string           `json:"sftpHost,omitempty"`
+	SFTPPort       int              `json:"sftpPort,omitempty"`
+	SFTPUsername   string           `json:"sftpUsername,omitempty"`
+	SFTPPath       string           `json:"sftpPath,omitempty"`
+	SFTPHostKey    string           `json:"sftpHostKey,omitempty"`
 	CreatedAt      time.Time        `json:"createdAt"`
 	UpdatedAt      *time.Time       `json:"updatedAt,omitempty"`
 }
@@ -61,11 +128,24 @@ type BackupScheduleDTO struct {
 	CronExpression string     `json:"cronExpression"`
 	TargetID       int64      `json:"targetId"`
 	RetentionDays  int        `json:"retentionDays"`
+	KeepLast       int        `json:"keepLast"`
+	KeepDaily      int        `json:"keepDaily"`
+	KeepWeekly     int        `json:"keepWeekly"`
+	KeepMonthly    int        `json:"keepMonthly"`
 	Enabled        bool       `json:"enabled"`
 	CreatedAt      time.Time  `json:"createdAt"`
 	UpdatedAt      *time.Time `json:"updatedAt,omitempty"`
 	LastRunAt      *time.Time `json:"lastRunAt,omitempty"`
 	NextRunAt      *time.Time `json:"nextRunAt,omitempty"`
+	// Scope, NodeIDs and NetworkID select what is backed up, see BackupScope
+	Scope       BackupScope         `json:"scope"`
+	NodeIDs     []int64             `json:"nodeIds,omitempty"`
+	NetworkID   *int64              `json:"networkId,omitempty"`
+	Consistency ConsistencyStrategy `json:"consistency"`
+	// StopOrder lists the nodes stopped first, in order. They are started in reverse order.
+	StopOrder []int64 `json:"stopOrder,omitempty"`
+	// VerifyCronExpression is when the latest backup of the schedule is verified, empty for never
+	VerifyCronExpression string `json:"verifyCronExpression,omitempty"`
 }
 
 // BackupDTO represents a backup
@@ -75,24 +155,96 @@ type BackupDTO struct {
 	TargetID     int64        `json:"targetId"`
 	Status       BackupStatus `json:"status"`
 	SizeBytes    *int64       `json:"sizeBytes,omitempty"`
+	SnapshotID   string       `json:"snapshotId,omitempty"`
 	StartedAt    time.Time    `json:"startedAt"`
 	CompletedAt  *time.Time   `json:"completedAt,omitempty"`
 	ErrorMessage *string      `json:"errorMessage,omitempty"`
 	Metadata     interface{}  `json:"metadata,omitempty"`
 	CreatedAt    time.Time    `json:"createdAt"`
+	// Nodes records what was done for each node of a node backup
+	Nodes []NodeBackupDTO `json:"nodes,omitempty"`
+	// Verification is the report of the last verification, nil when never verified
+	Verification *VerificationDTO `json:"verification,omitempty"`
+}
+
+// VerificationDTO is the report of the verification of a backup
+type VerificationDTO struct {
+	BackupID   int64                  `json:"backupId"`
+	Status     VerificationStatus     `json:"status"`
+	VerifiedAt time.Time              `json:"verifiedAt"`
+	Checks     []VerificationCheckDTO `json:"checks"`
+}
+
+// VerificationCheckDTO is the outcome of one check of a verification
+type VerificationCheckDTO struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	// Detail summarizes what was checked, or why the check failed
+	Detail string `json:"detail,omitempty"`
+}
+
+// NodeBackupDTO records how one node was backed up
+type NodeBackupDTO struct {
+	NodeID   int64  `json:"nodeId"`
+	Name     string `json:"name"`
+	NodeType string `json:"nodeType"`
+	Mode     string `json:"mode"`
+	// DataDir is the directory of the node in the snapshot, relative to the data directory
+	DataDir string `json:"dataDir"`
+	// Running tells whether the node was running when the backup started
+	Running bool `json:"running"`
+	// Strategy is the consistency strategy applied to the node, NONE when it was not running
+	Strategy        ConsistencyStrategy `json:"strategy"`
+	StoppedAt       *time.Time          `json:"stoppedAt,omitempty"`
+	RestartedAt     *time.Time          `json:"restartedAt,omitempty"`
+	LedgerSnapshots []LedgerSnapshotDTO `json:"ledgerSnapshots,omitempty"`
+	DockerExports   []DockerExportDTO   `json:"dockerExports,omitempty"`
+	// Error is set when the node could not be restarted after the backup
+	Error string `json:"error,omitempty"`
+}
+
+// LedgerSnapshotDTO is a ledger snapshot of a Fabric peer in a backup. A peer is
+// restored from it by joining the channel with the snapshot.
+type LedgerSnapshotDTO struct {
+	ChannelID   string `json:"channelId"`
+	BlockNumber uint64 `json:"blockNumber"`
+	// Path is the directory of the snapshot in the backup
+	Path string `json:"path"`
+}
+
+// DockerExportDTO is a mount of a docker node exported in a backup
+type DockerExportDTO struct {
+	Container string `json:"container"`
+	// Destination is the path of the mount in the container
+	Destination string `json:"destination"`
+	// Volume is the name of the docker volume, empty for bind mounts
+	Volume string `json:"volume,omitempty"`
+	// Path is where the content of the mount is in the backup
+	Path string `json:"path"`
 }
 
 // CreateBackupTargetParams represents parameters for creating a backup target
 type CreateBackupTargetParams struct {
 	Name           string           `validate:"required"`
-	Type           BackupTargetType `validate:"required,oneof=S3 LOCAL"`
+	Type           BackupTargetType `validate:"required,oneof=S3 LOCAL SFTP"`
+	Engine         BackupEngine     `validate:"omitempty,oneof=RESTIC NATIVE"`
 	BucketName     string           `validate:"required_if=Type S3"`
 	BucketPath     string           `validate:"required_if=Type S3"`
-	Endpoint       string           `validate:"required_if=Type S3,url"`
+	Endpoint       string           `validate:"required_if=Type S3,omitempty,url"`
 	AccessKeyID    string           `validate:"required_if=Type S3"`
 	SecretKey      string           `validate:"required_if=Type S3"`
 	Region         string           `validate:"required_if=Type S3"`
 	ForcePathStyle bool
+	LocalPath      string `validate:"required_if=Type LOCAL"`
+	SFTPHost       string `validate:"required_if=Type SFTP"`
+	SFTPPort       int    `validate:"omitempty,min=1,max=65535"`
+	SFTPUsername   string `validate:"required_if=Type SFTP"`
+	SFTPPrivateKey string `validate:"required_if=Type SFTP"`
+	SFTPPath       string `validate:"required_if=Type SFTP"`
+	// SFTPHostKey pins the host keys of the SFTP server, in known_hosts or public key format
+	SFTPHostKey string `validate:"required_if=Type SFTP"`
+	// RepositoryPassword encrypts the repository, a random one is generated when empty
+	RepositoryPassword string
 }
 
 // CreateBackupScheduleParams represents parameters for creating a backup schedule
@@ -102,7 +254,18 @@ type CreateBackupScheduleParams struct {
 	CronExpression string `validate:"required"`
 	TargetID       int64  `validate:"required"`
 	RetentionDays  int    `validate:"required,min=1"`
+	KeepLast       int    `validate:"min=0"`
+	KeepDaily      int    `validate:"min=0"`
+	KeepWeekly     int    `validate:"min=0"`
+	KeepMonthly    int    `validate:"min=0"`
 	Enabled        bool
+	Scope          BackupScope         `validate:"omitempty,oneof=ALL NODES NETWORK"`
+	NodeIDs        []int64             `validate:"required_if=Scope NODES"`
+	NetworkID      int64               `validate:"required_if=Scope NETWORK"`
+	Consistency    ConsistencyStrategy `validate:"omitempty,oneof=NONE LEDGER_SNAPSHOT STOP_START DOCKER_EXPORT"`
+	StopOrder      []int64
+	// VerifyCronExpression schedules the verification of the latest backup, empty for none
+	VerifyCronExpression string
 }
 
 // CreateBackupParams represents parameters for creating a backup
@@ -112,18 +275,27 @@ type CreateBackupParams struct {
 	Metadata   interface{} `json:"metadata,omitempty"`
 }
 
-// UpdateBackupTargetParams represents parameters for updating a backup target
+// UpdateBackupTargetParams represents parameters for updating a backup target.
+// SecretKey and SFTPPrivateKey keep their stored value when empty.
 type UpdateBackupTargetParams struct {
 	ID             int64            `validate:"required"`
 	Name           string           `validate:"required"`
-	Type           BackupTargetType `validate:"required,oneof=S3 LOCAL"`
+	Type           BackupTargetType `validate:"required,oneof=S3 LOCAL SFTP"`
+	Engine         BackupEngine     `validate:"omitempty,oneof=RESTIC NATIVE"`
 	BucketName     string           `validate:"required_if=Type S3"`
 	BucketPath     string           `validate:"required_if=Type S3"`
-	Endpoint       string           `validate:"required_if=Type S3,url"`
+	Endpoint       string           `validate:"required_if=Type S3,omitempty,url"`
 	AccessKeyID    string           `validate:"required_if=Type S3"`
-	SecretKey      string           `validate:"required_if=Type S3"`
-	Region         string           `validate:"required_if=Type S3"`
+	SecretKey      string
+	Region         string `validate:"required_if=Type S3"`
 	ForcePathStyle bool
+	LocalPath      string `validate:"required_if=Type LOCAL"`
+	SFTPHost       string `validate:"required_if=Type SFTP"`
+	SFTPPort       int    `validate:"omitempty,min=1,max=65535"`
+	SFTPUsername   string `validate:"required_if=Type SFTP"`
+	SFTPPrivateKey string
+	SFTPPath       string `validate:"required_if=Type SFTP"`
+	SFTPHostKey    string `validate:"required_if=Type SFTP"`
 }
 
 // UpdateBackupScheduleParams represents parameters for updating a backup schedule
@@ -134,5 +306,106 @@ type UpdateBackupScheduleParams struct {
 	CronExpression string `validate:"required"`
 	TargetID       int64  `validate:"required"`
 	RetentionDays  int    `validate:"required,min=1"`
+	KeepLast       int    `validate:"min=0"`
+	KeepDaily      int    `validate:"min=0"`
+	KeepWeekly     int    `validate:"min=0"`
+	KeepMonthly    int    `validate:"min=0"`
 	Enabled        bool
+	Scope          BackupScope         `validate:"omitempty,oneof=ALL NODES NETWORK"`
+	NodeIDs        []int64             `validate:"required_if=Scope NODES"`
+	NetworkID      int64               `validate:"required_if=Scope NETWORK"`
+	Consistency    ConsistencyStrategy `validate:"omitempty,oneof=NONE LEDGER_SNAPSHOT STOP_START DOCKER_EXPORT"`
+	StopOrder      []int64
+	// VerifyCronExpression schedules the verification of the latest backup, empty for none
+	VerifyCronExpression string
+}
+
+// RetentionBackupDTO is the retention decision for one backup of a schedule
+type RetentionBackupDTO struct {
+	BackupID   int64        `json:"backupId"`
+	TargetID   int64        `json:"targetId"`
+	SnapshotID string       `json:"snapshotId,omitempty"`
+	Status     BackupStatus `json:"status"`
+	StartedAt  time.Time    `json:"startedAt"`
+	Keep       bool         `json:"keep"`
+	// Reasons names the rules which keep the backup
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// RetentionResultDTO describes the backups a retention run removed, or would remove for a dry run
+type RetentionResultDTO struct {
+	ScheduleID int64                `json:"scheduleId"`
+	DryRun     bool                 `json:"dryRun"`
+	Kept       int                  `json:"kept"`
+	Deleted    int                  `json:"deleted"`
+	Backups    []RetentionBackupDTO `json:"backups"`
+}
+
+// RestoreScope selects the part of a backup that is restored
+type RestoreScope string
+
+const (
+	// RestoreScopeFull restores the data directory and the database
+	RestoreScopeFull RestoreScope = "FULL"
+	// RestoreScopeNode restores the data directories of one node
+	RestoreScopeNode RestoreScope = "NODE"
+	// RestoreScopeOrganization restores the MSP directory and keys of one organization
+	RestoreScopeOrganization RestoreScope = "ORGANIZATION"
+	// RestoreScopeDatabase restores the database only
+	RestoreScopeDatabase RestoreScope = "DATABASE"
+)
+
+// RestoreChangeType describes what a restore does to a file
+type RestoreChangeType string
+
+const (
+	RestoreChangeAdd    RestoreChangeType = "ADD"
+	RestoreChangeModify RestoreChangeType = "MODIFY"
+	RestoreChangeDelete RestoreChangeType = "DELETE"
+)
+
+// RestoreParams represents parameters for restoring a backup. The backup is
+// either given by ID, or is the latest completed backup of a target taken at
+// or before a point in time.
+type RestoreParams struct {
+	BackupID       *int64
+	TargetID       *int64
+	PointInTime    *time.Time
+	Scope          RestoreScope `validate:"required,oneof=FULL NODE ORGANIZATION DATABASE"`
+	NodeID         int64        `validate:"required_if=Scope NODE"`
+	OrganizationID int64        `validate:"required_if=Scope ORGANIZATION"`
+	// DryRun only reports the changes the restore would make
+	DryRun bool
+	// SkipPreRestoreBackup restores without backing up the current state first
+	SkipPreRestoreBackup bool
+	// KeepNodesStopped leaves the nodes stopped for the restore stopped afterwards
+	KeepNodesStopped bool
+}
+
+// RestoreChange is a file written or removed by a restore
+type RestoreChange struct {
+	Path   string            `json:"path"`
+	Change RestoreChangeType `json:"change"`
+	Size   int64             `json:"size"`
+}
+
+// RestoreTableChange compares the rows of a database table with the snapshot
+type RestoreTableChange struct {
+	Table        string `json:"table"`
+	CurrentRows  int64  `json:"currentRows"`
+	SnapshotRows int64  `json:"snapshotRows"`
+}
+
+// RestoreResultDTO describes a restore, or what it would do for a dry run
+type RestoreResultDTO struct {
+	BackupID           int64                `json:"backupId"`
+	SnapshotID         string               `json:"snapshotId"`
+	Scope              RestoreScope         `json:"scope"`
+	DryRun             bool                 `json:"dryRun"`
+	Changes            []RestoreChange      `json:"changes"`
+	Tables             []RestoreTableChange `json:"tables,omitempty"`
+	PreRestoreBackupID *int64               `json:"preRestoreBackupId,omitempty"`
+	StoppedNodes       []int64              `json:"stoppedNodes,omitempty"`
+	StartedNodes       []int64              `json:"startedNodes,omitempty"`
+	Errors             []string             `json:"errors,omitempty"`
 }
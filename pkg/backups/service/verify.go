@@ -0,0 +1,396 @@
+package service
+
+import (
+	"context"
+	"crypto/x509"
+	"database/sql"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/backups/engine"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+	"github.com/robfig/cron/v3"
+)
+
+// mspNodeDirs are the data directory entries holding one directory per Fabric
+// node, each with its MSP in a config directory
+var mspNodeDirs = []string{"peers", "orderers"}
+
+// verifyCronParser parses the verification schedules like the backup service cron
+var verifyCronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// validateVerifyCron checks the verification cron expression of a schedule, empty disables verification
+func validateVerifyCron(expression string) error {
+	if expression == "" {
+		return nil
+	}
+	if _, err := verifyCronParser.Parse(expression); err != nil {
+		return fmt.Errorf("%w: invalid verification cron expression: %v", ErrInvalidSchedule, err)
+	}
+	return nil
+}
+
+// scheduleVerification adds the verification job of a schedule to the cron
+// scheduler, replacing the previous one. The caller holds s.mu.
+func (s *BackupService) scheduleVerification(schedule *db.BackupSchedule) {
+	s.unscheduleVerification(schedule.ID)
+	if !schedule.VerifyCronExpression.Valid || schedule.VerifyCronExpression.String == "" {
+		return
+	}
+
+	entryID, err := s.cron.AddFunc(schedule.VerifyCronExpression.String, func() {
+		s.verifyScheduledBackup(context.Background(), schedule.ID)
+	})
+	if err != nil {
+		s.logger.Error("Failed to schedule backup verification", "error", err, "scheduleID", schedule.ID)
+		return
+	}
+	s.verifyEntryIDs[schedule.ID] = entryID
+	s.logger.Info("Scheduled backup verification", "scheduleID", schedule.ID, "cronExpression", schedule.VerifyCronExpression.String)
+}
+
+// unscheduleVerification removes the verification job of a schedule. The caller holds s.mu.
+func (s *BackupService) unscheduleVerification(scheduleID int64) {
+	if entryID, exists := s.verifyEntryIDs[scheduleID]; exists {
+		s.cron.Remove(entryID)
+		delete(s.verifyEntryIDs, scheduleID)
+	}
+}
+
+// verifyScheduledBackup verifies the latest completed backup of a schedule
+func (s *BackupService) verifyScheduledBackup(ctx context.Context, scheduleID int64) {
+	backups, err := s.queries.ListBackupsBySchedule(ctx, sql.NullInt64{Int64: scheduleID, Valid: true})
+	if err != nil {
+		s.logger.Error("Failed to list backups to verify", "error", err, "scheduleID", scheduleID)
+		return
+	}
+	for _, backup := range backups {
+		if BackupStatus(backup.Status) != BackupStatusCompleted {
+			continue
+		}
+		if _, err := s.VerifyBackup(ctx, backup.ID); err != nil {
+			s.logger.Error("Failed to verify backup", "error", err, "backupID", backup.ID)
+		}
+		return
+	}
+	s.logger.Info("No completed backup to verify", "scheduleID", scheduleID)
+}
+
+// backupVerification returns the report of the last verification of a backup, nil when never verified
+func backupVerification(backup *db.Backup) *VerificationDTO {
+	if !backup.VerificationReport.Valid {
+		return nil
+	}
+	var report VerificationDTO
+	if err := json.Unmarshal([]byte(backup.VerificationReport.String), &report); err != nil {
+		return nil
+	}
+	return &report
+}
+
+// VerifyBackup checks the repository of the target of a completed backup and
+// restores the backup to a scratch directory, where the database copy and the
+// MSP directories of the Fabric nodes are checked. The report is recorded on
+// the backup and a failure is notified. The error is only set when the
+// verification could not run.
+func (s *BackupService) VerifyBackup(ctx context.Context, backupID int64) (*VerificationDTO, error) {
+	backup, err := s.queries.GetBackup(ctx, backupID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBackupNotFound
+		}
+		return nil, fmt.Errorf("failed to get backup: %w", err)
+	}
+	if BackupStatus(backup.Status) != BackupStatusCompleted {
+		return nil, fmt.Errorf("%w: backup %d is %s", ErrSnapshotUnavailable, backup.ID, backup.Status)
+	}
+	target, err := s.queries.GetBackupTarget(ctx, backup.TargetID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTargetNotFound
+		}
+		return nil, fmt.Errorf("failed to get backup target: %w", err)
+	}
+
+	report := s.verifyBackup(ctx, target, backup)
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode verification report: %w", err)
+	}
+	if err := s.queries.UpdateBackupVerification(ctx, &db.UpdateBackupVerificationParams{
+		ID:                 backup.ID,
+		VerificationStatus: sql.NullString{String: string(report.Status), Valid: true},
+		VerificationReport: sql.NullString{String: string(encoded), Valid: true},
+		VerifiedAt:         sql.NullTime{Time: report.VerifiedAt, Valid: true},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to record verification: %w", err)
+	}
+
+	if report.Status == VerificationStatusFailed {
+		s.logger.Warnf("Verification of backup %d failed", backup.ID)
+		s.notifyVerificationFailure(ctx, backup, target, report)
+	} else {
+		s.logger.Infof("Verified backup %d", backup.ID)
+	}
+	return report, nil
+}
+
+// verifyBackup runs the checks of a verification. The target is locked so
+// the snapshot is not pruned while it is restored.
+func (s *BackupService) verifyBackup(ctx context.Context, target *db.BackupTarget, backup *db.Backup) *VerificationDTO {
+	report := &VerificationDTO{
+		BackupID:   backup.ID,
+		Status:     VerificationStatusPassed,
+		VerifiedAt: time.Now(),
+	}
+	check := func(name, detail string, err error) bool {
+		result := VerificationCheckDTO{Name: name, Passed: err == nil, Detail: detail}
+		if err != nil {
+			result.Detail = err.Error()
+			report.Status = VerificationStatusFailed
+		}
+		report.Checks = append(report.Checks, result)
+		return err == nil
+	}
+
+	unlock := s.lockTarget(target.ID)
+	defer unlock()
+
+	detail, err := s.checkRepository(ctx, target)
+	check(VerificationCheckRepository, detail, err)
+
+	scratch, err := os.MkdirTemp("", "chainlaunch-verify-*")
+	if err != nil {
+		check(VerificationCheckRestore, "", fmt.Errorf("failed to create scratch directory: %w", err))
+		return report
+	}
+	defer os.RemoveAll(scratch)
+	restored := filepath.Join(scratch, "data")
+	dbPath := filepath.Join(scratch, "chainlaunch.db")
+
+	detail, err = s.testRestore(ctx, target, backup, restored, dbPath)
+	if !check(VerificationCheckRestore, detail, err) {
+		return report
+	}
+	detail, err = checkDatabase(ctx, dbPath)
+	check(VerificationCheckDatabase, detail, err)
+	detail, err = checkMSPDirs(restored)
+	check(VerificationCheckMSP, detail, err)
+	return report
+}
+
+// checkRepository checks the structure of the repository of a target, without reading the data
+func (s *BackupService) checkRepository(ctx context.Context, target *db.BackupTarget) (string, error) {
+	if BackupEngine(target.Engine) == BackupEngineNative {
+		repo, err := s.openNativeRepository(ctx, target)
+		if err != nil {
+			return "", err
+		}
+		result, err := repo.Check(ctx, engine.CheckOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to check repository: %w", err)
+		}
+		if !result.OK() {
+			return "", fmt.Errorf("%d problems found, %d chunks missing: %s",
+				len(result.Errors), result.MissingChunks, strings.Join(result.Errors, "; "))
+		}
+		return fmt.Sprintf("%d snapshots, %d files and %d chunks checked", result.Snapshots, result.Files, result.Chunks), nil
+	}
+
+	repo, err := s.openResticRepo(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	defer repo.Close()
+	if output, err := repo.command(ctx, "check").CombinedOutput(); err != nil {
+		return "", fmt.Errorf("restic check failed: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	return "no errors were found", nil
+}
+
+// testRestore restores the whole snapshot of a backup to dir and its database copy to dbPath
+func (s *BackupService) testRestore(ctx context.Context, target *db.BackupTarget, backup *db.Backup, dir, dbPath string) (string, error) {
+	reader, err := s.openSnapshot(ctx, target, backup)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	entries, err := reader.entries(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list snapshot: %w", err)
+	}
+	if err := reader.extract(ctx, []string{""}, dir); err != nil {
+		return "", fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	if err := reader.extractDatabase(ctx, dbPath); err != nil {
+		return "", fmt.Errorf("failed to restore database: %w", err)
+	}
+
+	var files int
+	var size int64
+	for _, entry := range entries {
+		if !entry.Dir {
+			files++
+			size += entry.Size
+		}
+	}
+	return fmt.Sprintf("%d files restored (%d bytes)", files, size), nil
+}
+
+// checkDatabase opens a restored database copy and runs the SQLite integrity check
+func checkDatabase(ctx context.Context, path string) (string, error) {
+	database, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return "", fmt.Errorf("failed to open database: %w", err)
+	}
+	defer database.Close()
+
+	rows, err := database.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return "", fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	defer rows.Close()
+	var problems []string
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return "", fmt.Errorf("failed to read integrity check: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("integrity check failed: %s", strings.Join(problems, "; "))
+	}
+
+	// A database of the wrong application would pass the integrity check as well
+	var nodes int64
+	if err := database.QueryRowContext(ctx, "SELECT COUNT(*) FROM nodes").Scan(&nodes); err != nil {
+		return "", fmt.Errorf("failed to read nodes: %w", err)
+	}
+	return fmt.Sprintf("integrity check passed, %d nodes", nodes), nil
+}
+
+// checkMSPDirs checks the MSP of every restored Fabric node: its signing and
+// CA certificates must parse and its keystore must exist. A node which has
+// ledger data but no MSP is reported as well.
+func checkMSPDirs(dataDir string) (string, error) {
+	checked := 0
+	var problems []string
+	for _, parent := range mspNodeDirs {
+		nodes, err := os.ReadDir(filepath.Join(dataDir, parent))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return "", fmt.Errorf("failed to read %s: %w", parent, err)
+		}
+		for _, node := range nodes {
+			if !node.IsDir() {
+				continue
+			}
+			nodeDir := filepath.Join(dataDir, parent, node.Name())
+			name := parent + "/" + node.Name()
+			mspDir := filepath.Join(nodeDir, "config")
+			if _, err := os.Stat(mspDir); os.IsNotExist(err) {
+				if _, err := os.Stat(filepath.Join(nodeDir, "data")); err == nil {
+					problems = append(problems, fmt.Sprintf("%s has ledger data but no MSP", name))
+				}
+				continue
+			}
+			checked++
+			if err := checkMSP(mspDir); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	if checked == 0 {
+		return "no MSP directories in the backup", nil
+	}
+	return fmt.Sprintf("%d MSP directories checked", checked), nil
+}
+
+// checkMSP checks one MSP directory. The keystore may be empty when the
+// signing key stays in an HSM.
+func checkMSP(dir string) error {
+	for _, certDir := range []string{"signcerts", "cacerts"} {
+		entries, err := os.ReadDir(filepath.Join(dir, certDir))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", certDir, err)
+		}
+		certs := 0
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, certDir, entry.Name()))
+			if err != nil {
+				return fmt.Errorf("failed to read %s/%s: %w", certDir, entry.Name(), err)
+			}
+			block, _ := pem.Decode(data)
+			if block == nil {
+				return fmt.Errorf("%s/%s is not PEM encoded", certDir, entry.Name())
+			}
+			if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+				return fmt.Errorf("%s/%s is not a valid certificate: %w", certDir, entry.Name(), err)
+			}
+			certs++
+		}
+		if certs == 0 {
+			return fmt.Errorf("%s has no certificate", certDir)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(dir, "keystore")); err != nil || !info.IsDir() {
+		return fmt.Errorf("keystore is missing")
+	}
+	return nil
+}
+
+// notifyVerificationFailure sends a notification about a backup failing its verification
+func (s *BackupService) notifyVerificationFailure(ctx context.Context, backup *db.Backup, target *db.BackupTarget, report *VerificationDTO) {
+	if s.notificationService == nil {
+		s.logger.Info("Notification service not available, skipping backup verification failure notification")
+		return
+	}
+
+	var scheduleName string
+	if backup.ScheduleID.Valid {
+		if schedule, err := s.queries.GetBackupSchedule(ctx, backup.ScheduleID.Int64); err == nil {
+			scheduleName = schedule.Name
+		}
+	}
+	var failed []string
+	for _, check := range report.Checks {
+		if !check.Passed {
+			failed = append(failed, fmt.Sprintf("%s: %s", check.Name, check.Detail))
+		}
+	}
+
+	data := notifications.BackupVerificationFailureData{
+		BackupID:     backup.ID,
+		SnapshotID:   backup.SnapshotID.String,
+		ScheduleName: scheduleName,
+		TargetName:   target.Name,
+		TargetType:   target.Type,
+		BackupTime:   backup.StartedAt,
+		VerifiedAt:   report.VerifiedAt,
+		FailedChecks: failed,
+	}
+	if err := s.notificationService.SendBackupVerificationFailureNotification(ctx, data); err != nil {
+		s.logger.Error("Failed to send backup verification failure notification", "error", err)
+	}
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+)
+
+func newTestCertificatePEM(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "peer0.org1.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+// writeTestMSP writes the MSP of a Fabric node below dataDir
+func writeTestMSP(t *testing.T, dataDir, node, signCert string) {
+	mspDir := filepath.Join(dataDir, node, "config")
+	writeTestFile(t, filepath.Join(mspDir, "signcerts", "cert.pem"), signCert)
+	writeTestFile(t, filepath.Join(mspDir, "cacerts", "ca.pem"), newTestCertificatePEM(t))
+	if err := os.MkdirAll(filepath.Join(mspDir, "keystore"), 0700); err != nil {
+		t.Fatalf("failed to create keystore: %v", err)
+	}
+}
+
+func TestValidateVerifyCron(t *testing.T) {
+	cases := map[string]bool{
+		"":              true,
+		"0 0 3 * * *":   true,
+		"@daily":        true,
+		"0 3 * * *":     false,
+		"not a cron":    false,
+		"0 0 25 * * * ": false,
+	}
+	for expression, valid := range cases {
+		err := validateVerifyCron(expression)
+		if valid != (err == nil) {
+			t.Errorf("%q: expected valid %v, got %v", expression, valid, err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidSchedule) {
+			t.Errorf("%q: expected ErrInvalidSchedule, got %v", expression, err)
+		}
+	}
+}
+
+func TestCheckMSPDirs(t *testing.T) {
+	cert := newTestCertificatePEM(t)
+
+	empty := t.TempDir()
+	if detail, err := checkMSPDirs(empty); err != nil || detail != "no MSP directories in the backup" {
+		t.Errorf("unexpected result for an empty backup: %q (%v)", detail, err)
+	}
+
+	valid := t.TempDir()
+	writeTestMSP(t, valid, "peers/peer0", cert)
+	writeTestMSP(t, valid, "orderers/orderer0", cert)
+	writeTestFile(t, filepath.Join(valid, "peers", "README"), "not a node")
+	if detail, err := checkMSPDirs(valid); err != nil || detail != "2 MSP directories checked" {
+		t.Errorf("unexpected result for valid MSPs: %q (%v)", detail, err)
+	}
+
+	cases := map[string]struct {
+		setup    func(dir string)
+		expected string
+	}{
+		"invalid PEM": {func(dir string) {
+			writeTestMSP(t, dir, "peers/peer0", "not a certificate")
+		}, "signcerts/cert.pem is not PEM encoded"},
+		"invalid certificate": {func(dir string) {
+			writeTestMSP(t, dir, "peers/peer0", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("garbage")})))
+		}, "is not a valid certificate"},
+		"no CA certificate": {func(dir string) {
+			writeTestMSP(t, dir, "peers/peer0", cert)
+			os.Remove(filepath.Join(dir, "peers", "peer0", "config", "cacerts", "ca.pem"))
+		}, "cacerts has no certificate"},
+		"missing keystore": {func(dir string) {
+			writeTestMSP(t, dir, "orderers/orderer0", cert)
+			os.Remove(filepath.Join(dir, "orderers", "orderer0", "config", "keystore"))
+		}, "orderers/orderer0: keystore is missing"},
+		"ledger without MSP": {func(dir string) {
+			writeTestFile(t, filepath.Join(dir, "peers", "peer1", "data", "ledger"), "ledger")
+		}, "peers/peer1 has ledger data but no MSP"},
+	}
+	for name, c := range cases {
+		dir := t.TempDir()
+		c.setup(dir)
+		_, err := checkMSPDirs(dir)
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, c.expected, err)
+		}
+	}
+}
+
+func TestCheckDatabase(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "chainlaunch.db")
+	newTestDatabase(t, valid)
+	if detail, err := checkDatabase(ctx, valid); err != nil || detail != "integrity check passed, 0 nodes" {
+		t.Errorf("unexpected result for a valid database: %q (%v)", detail, err)
+	}
+
+	other := filepath.Join(dir, "other.db")
+	database, err := sql.Open("sqlite3", other)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := database.Exec("CREATE TABLE items (id INTEGER)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	database.Close()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	writeTestFile(t, garbage, strings.Repeat("not a database ", 100))
+
+	for name, path := range map[string]string{"other application": other, "not a database": garbage, "missing": filepath.Join(dir, "missing.db")} {
+		if _, err := checkDatabase(ctx, path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// verifyTest is a data directory with its database and a local target of the native engine
+type verifyTest struct {
+	s        *BackupService
+	dataPath string
+	target   *db.BackupTarget
+}
+
+func newVerifyTest(t *testing.T) *verifyTest {
+	s := newTestBackupService(t)
+	s.targetLocks = make(map[int64]*sync.Mutex)
+	s.verifyEntryIDs = make(map[int64]cron.EntryID)
+	dataPath := s.configService.GetDataPath()
+	s.databasePath = filepath.Join(dataPath, "chainlaunch.db")
+	s.queries = newTestDatabase(t, s.databasePath)
+
+	target, err := s.queries.CreateBackupTarget(context.Background(), &db.CreateBackupTargetParams{
+		Name:           "local",
+		Type:           string(BackupTargetTypeLocal),
+		LocalPath:      sql.NullString{String: t.TempDir(), Valid: true},
+		ResticPassword: sql.NullString{String: "password", Valid: true},
+		Engine:         string(BackupEngineNative),
+	})
+	if err != nil {
+		t.Fatalf("failed to create target: %v", err)
+	}
+	return &verifyTest{s: s, dataPath: dataPath, target: target}
+}
+
+// backup takes a completed backup of the data directory
+func (vt *verifyTest) backup(t *testing.T) *db.Backup {
+	ctx := context.Background()
+	backup, err := vt.s.queries.CreateBackup(ctx, &db.CreateBackupParams{
+		TargetID:  vt.target.ID,
+		Status:    string(BackupStatusPending),
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+	if err := vt.s.performNativeBackup(ctx, backup, vt.target); err != nil {
+		t.Fatalf("failed to back up: %v", err)
+	}
+	if backup, err = vt.s.queries.UpdateBackupCompleted(ctx, &db.UpdateBackupCompletedParams{
+		ID:          backup.ID,
+		Status:      string(BackupStatusCompleted),
+		CompletedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}); err != nil {
+		t.Fatalf("failed to complete backup: %v", err)
+	}
+	return backup
+}
+
+func checkResults(report *VerificationDTO) map[string]bool {
+	results := make(map[string]bool)
+	for _, check := range report.Checks {
+		results[check.Name] = check.Passed
+	}
+	return results
+}
+
+func TestVerifyBackup(t *testing.T) {
+	ctx := context.Background()
+	vt := newVerifyTest(t)
+	writeTestMSP(t, vt.dataPath, "peers/peer0", newTestCertificatePEM(t))
+	backup := vt.backup(t)
+
+	report, err := vt.s.VerifyBackup(ctx, backup.ID)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	if report.Status != VerificationStatusPassed || len(report.Checks) != 4 {
+		t.Fatalf("expected every check to pass, got %+v", report)
+	}
+	for _, check := range report.Checks {
+		if !check.Passed || check.Detail == "" {
+			t.Errorf("unexpected check %+v", check)
+		}
+	}
+
+	// The report is recorded on the backup
+	stored, err := vt.s.queries.GetBackup(ctx, backup.ID)
+	if err != nil {
+		t.Fatalf("failed to get backup: %v", err)
+	}
+	if stored.VerificationStatus.String != string(VerificationStatusPassed) || !stored.VerifiedAt.Valid {
+		t.Errorf("verification not recorded: %+v", stored)
+	}
+	if recorded := backupVerification(stored); recorded == nil || recorded.Status != VerificationStatusPassed || len(recorded.Checks) != 4 {
+		t.Errorf("unexpected recorded report %+v", recorded)
+	}
+
+	// A backup of a broken MSP fails its verification
+	writeTestFile(t, filepath.Join(vt.dataPath, "peers", "peer0", "config", "signcerts", "cert.pem"), "corrupted")
+	broken := vt.backup(t)
+	report, err = vt.s.VerifyBackup(ctx, broken.ID)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	results := checkResults(report)
+	if report.Status != VerificationStatusFailed || results[VerificationCheckMSP] || !results[VerificationCheckDatabase] {
+		t.Errorf("expected the MSP check to fail, got %+v", report)
+	}
+	if stored, err := vt.s.queries.GetBackup(ctx, broken.ID); err != nil || stored.VerificationStatus.String != string(VerificationStatusFailed) {
+		t.Errorf("failed verification not recorded: %+v (%v)", stored, err)
+	}
+}
+
+func TestVerifyBackupMissingData(t *testing.T) {
+	ctx := context.Background()
+	vt := newVerifyTest(t)
+	writeTestFile(t, filepath.Join(vt.dataPath, "config.yaml"), "config")
+	backup := vt.backup(t)
+
+	// Remove the stored data of the repository
+	err := filepath.Walk(vt.target.LocalPath.String, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.Contains(filepath.ToSlash(p), "/data/") {
+			return os.Remove(p)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("failed to remove repository data: %v", err)
+	}
+
+	report, err := vt.s.VerifyBackup(ctx, backup.ID)
+	if err != nil {
+		t.Fatalf("failed to verify: %v", err)
+	}
+	results := checkResults(report)
+	if report.Status != VerificationStatusFailed || results[VerificationCheckRepository] || results[VerificationCheckRestore] {
+		t.Errorf("expected the repository and restore checks to fail, got %+v", report)
+	}
+	// Nothing is checked on a failed restore
+	if _, ok := results[VerificationCheckDatabase]; ok {
+		t.Errorf("the database should not be checked after a failed restore: %+v", report)
+	}
+}
+
+func TestVerifyBackupErrors(t *testing.T) {
+	ctx := context.Background()
+	vt := newVerifyTest(t)
+
+	failed, err := vt.s.queries.CreateBackup(ctx, &db.CreateBackupParams{
+		TargetID:  vt.target.ID,
+		Status:    string(BackupStatusFailed),
+		StartedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create backup: %v", err)
+	}
+
+	cases := map[string]struct {
+		backupID int64
+		expected error
+	}{
+		"unknown backup": {1000, ErrBackupNotFound},
+		"failed backup":  {failed.ID, ErrSnapshotUnavailable},
+	}
+	for name, c := range cases {
+		if _, err := vt.s.VerifyBackup(ctx, c.backupID); !errors.Is(err, c.expected) {
+			t.Errorf("%s: expected %v, got %v", name, c.expected, err)
+		}
+	}
+
+	if backupVerification(failed) != nil {
+		t.Error("a backup never verified has no report")
+	}
+}
@@ -16,16 +16,21 @@ import (
 
 const (
 	DefaultVersion = "3.0.0"
+	// DefaultCAVersion is the Fabric CA release used when none is requested
+	DefaultCAVersion = "1.5.15"
 	// Base URL for Hyperledger Fabric binary releases
 	githubReleaseURL = "https://github.com/hyperledger/fabric/releases/download"
+	// Base URL for Hyperledger Fabric CA binary releases
+	githubCAReleaseURL = "https://github.com/hyperledger/fabric-ca/releases/download"
 )
 
-// BinaryType represents the type of binary (peer or orderer)
+// BinaryType represents the type of binary (peer, orderer or fabric-ca-server)
 type BinaryType string
 
 const (
-	PeerBinary    BinaryType = "peer"
-	OrdererBinary BinaryType = "orderer"
+	PeerBinary           BinaryType = "peer"
+	OrdererBinary        BinaryType = "orderer"
+	FabricCAServerBinary BinaryType = "fabric-ca-server"
 )
 
 // BinaryDownloader handles downloading and managing Fabric binaries
@@ -44,17 +49,27 @@ func NewBinaryDownloader(configService *config.ConfigService) (*BinaryDownloader
 
 // GetBinaryPath returns the path to the binary, downloading it if necessary
 func (d *BinaryDownloader) GetBinaryPath(binaryType BinaryType, version string) (string, error) {
-	if version == "" {
-		version = DefaultVersion
-	}
-
 	binDir := filepath.Join(d.configService.GetDataPath(), "bin")
 	binaryName := string(binaryType)
 	if runtime.GOOS == "windows" {
 		binaryName += ".exe"
 	}
 
-	versionDir := filepath.Join(binDir, version)
+	// Fabric CA is released separately and versioned independently of Fabric
+	var versionDir, url string
+	if binaryType == FabricCAServerBinary {
+		if version == "" {
+			version = DefaultCAVersion
+		}
+		versionDir = filepath.Join(binDir, "fabric-ca", version)
+		url = fmt.Sprintf("%s/v%s/hyperledger-fabric-ca-%s-%s-%s.tar.gz", githubCAReleaseURL, version, runtime.GOOS, runtime.GOARCH, version)
+	} else {
+		if version == "" {
+			version = DefaultVersion
+		}
+		versionDir = filepath.Join(binDir, version)
+		url = fmt.Sprintf("%s/v%s/hyperledger-fabric-%s-%s-%s.tar.gz", githubReleaseURL, version, runtime.GOOS, runtime.GOARCH, version)
+	}
 	binaryPath := filepath.Join(versionDir, "bin", binaryName)
 
 	// Check if binary already exists
@@ -68,7 +83,7 @@ func (d *BinaryDownloader) GetBinaryPath(binaryType BinaryType, version string)
 	}
 
 	// Download and extract binaries
-	if err := d.downloadAndExtractBinaries(version, versionDir); err != nil {
+	if err := d.downloadAndExtractBinaries(url, versionDir); err != nil {
 		return "", fmt.Errorf("failed to download and extract binaries: %w", err)
 	}
 
@@ -80,14 +95,8 @@ func (d *BinaryDownloader) GetBinaryPath(binaryType BinaryType, version string)
 	return binaryPath, nil
 }
 
-// downloadAndExtractBinaries downloads and extracts the Fabric binaries
-func (d *BinaryDownloader) downloadAndExtractBinaries(version, destDir string) error {
-	// Construct download URL
-	arch := runtime.GOARCH
-	runtimeOs := runtime.GOOS
-	filename := fmt.Sprintf("hyperledger-fabric-%s-%s-%s.tar.gz", runtimeOs, arch, version)
-	url := fmt.Sprintf("%s/v%s/%s", githubReleaseURL, version, filename)
-
+// downloadAndExtractBinaries downloads and extracts a Fabric release archive
+func (d *BinaryDownloader) downloadAndExtractBinaries(url, destDir string) error {
 	// Create temporary file for download
 	tmpFile, err := os.CreateTemp("", "fabric-*.tar.gz")
 	if err != nil {
@@ -0,0 +1,7 @@
+-- 0012_create_config_proposals.down.sql
+-- Migration: Drop tables for config update proposals and proposal signatures
+
+DROP INDEX IF EXISTS idx_proposal_signatures_proposal_id;
+DROP INDEX IF EXISTS idx_proposals_network_id;
+DROP TABLE IF EXISTS proposal_signatures;
+DROP TABLE IF EXISTS proposals;
@@ -0,0 +1,31 @@
+-- 0012_create_config_proposals.up.sql
+-- Migration: Create tables for channel config update proposals and the signatures collected for them
+
+CREATE TABLE IF NOT EXISTS proposals (
+    id TEXT PRIMARY KEY,
+    network_id INTEGER NOT NULL,
+    channel_name TEXT NOT NULL,
+    status TEXT NOT NULL,          -- 'pending', 'submitted', 'cancelled'
+    operations TEXT NOT NULL,      -- JSON-encoded config update operations
+    preview_json TEXT,             -- JSON rendering of the config update
+    config_update BLOB NOT NULL,   -- marshaled common.ConfigUpdate, without signatures
+    tx_response TEXT,              -- orderer response once submitted
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP,
+    FOREIGN KEY (network_id) REFERENCES networks(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS proposal_signatures (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    proposal_id TEXT NOT NULL,
+    msp_id TEXT NOT NULL,
+    signed_by TEXT NOT NULL,
+    signature BLOB NOT NULL,       -- marshaled common.ConfigSignature
+    signed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(proposal_id, msp_id),
+    FOREIGN KEY (proposal_id) REFERENCES proposals(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_proposals_network_id ON proposals(network_id);
+CREATE INDEX IF NOT EXISTS idx_proposal_signatures_proposal_id ON proposal_signatures(proposal_id);
@@ -0,0 +1,6 @@
+-- 0013_create_certificate_expiry.down.sql
+-- Migration: Drop certificate expiry notifications and node certificate auto-renewal
+
+DROP TABLE IF EXISTS certificate_expiry_alerts;
+DROP TABLE IF EXISTS node_certificate_settings;
+ALTER TABLE notification_providers DROP COLUMN notify_certificate_expiry;
@@ -0,0 +1,21 @@
+-- 0013_create_certificate_expiry.up.sql
+-- Migration: Add certificate expiry notifications and node certificate auto-renewal
+
+ALTER TABLE notification_providers ADD COLUMN notify_certificate_expiry BOOLEAN NOT NULL DEFAULT true;
+
+-- Nodes opted into automatic certificate renewal
+CREATE TABLE node_certificate_settings (
+    node_id INTEGER PRIMARY KEY REFERENCES nodes(id) ON DELETE CASCADE,
+    auto_renew BOOLEAN NOT NULL DEFAULT false,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Expiry alerts already sent, so every threshold is only notified once per certificate
+CREATE TABLE certificate_expiry_alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subject TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    threshold_days INTEGER NOT NULL,
+    notified_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(subject, expires_at, threshold_days)
+);
@@ -0,0 +1,5 @@
+-- 0014_create_besu_validator_changes.down.sql
+-- Migration: Drop table tracking validator set changes voted on Besu networks
+
+DROP INDEX IF EXISTS idx_besu_validator_changes_network_id;
+DROP TABLE IF EXISTS besu_validator_changes;
@@ -0,0 +1,20 @@
+-- 0014_create_besu_validator_changes.up.sql
+-- Migration: Create table tracking validator set changes voted on Besu networks
+
+CREATE TABLE besu_validator_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    network_id INTEGER NOT NULL REFERENCES networks(id) ON DELETE CASCADE,
+    action TEXT NOT NULL, -- 'add' or 'remove'
+    address TEXT NOT NULL,
+    key_id INTEGER REFERENCES keys(id) ON DELETE SET NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    votes TEXT, -- JSON list of the votes cast by the validator nodes
+    votes_required INTEGER NOT NULL DEFAULT 0, -- votes needed for the change to be applied
+    error_message TEXT,
+    created_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    applied_at TIMESTAMP
+);
+
+CREATE INDEX idx_besu_validator_changes_network_id ON besu_validator_changes(network_id);
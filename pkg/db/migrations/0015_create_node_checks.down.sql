@@ -0,0 +1,8 @@
+-- 0015_create_node_checks.down.sql
+-- Migration: Drop node health check history tables
+
+DROP INDEX IF EXISTS idx_node_incidents_node_id_started_at;
+DROP TABLE IF EXISTS node_incidents;
+DROP TABLE IF EXISTS node_check_rollups;
+DROP INDEX IF EXISTS idx_node_checks_node_id_checked_at;
+DROP TABLE IF EXISTS node_checks;
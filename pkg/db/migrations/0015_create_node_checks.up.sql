@@ -0,0 +1,37 @@
+-- 0015_create_node_checks.up.sql
+-- Migration: Persist node health checks, hourly uptime rollups and downtime incidents
+
+-- Raw health check results, kept for a short retention window
+CREATE TABLE node_checks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    node_id INTEGER NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    status TEXT NOT NULL, -- 'up' or 'down'
+    response_time_ms INTEGER NOT NULL DEFAULT 0,
+    error_message TEXT,
+    failure_count INTEGER NOT NULL DEFAULT 0, -- consecutive failures when the check ran
+    checked_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_node_checks_node_id_checked_at ON node_checks(node_id, checked_at);
+
+-- Health checks downsampled to one row per node and hour
+CREATE TABLE node_check_rollups (
+    node_id INTEGER NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    bucket_start TIMESTAMP NOT NULL,
+    total_checks INTEGER NOT NULL DEFAULT 0,
+    up_checks INTEGER NOT NULL DEFAULT 0,
+    avg_response_time_ms INTEGER NOT NULL DEFAULT 0,
+    max_response_time_ms INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (node_id, bucket_start)
+);
+
+-- Periods during which a node was down, open while resolved_at is NULL
+CREATE TABLE node_incidents (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    node_id INTEGER NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    started_at TIMESTAMP NOT NULL,
+    resolved_at TIMESTAMP,
+    error_message TEXT
+);
+
+CREATE INDEX idx_node_incidents_node_id_started_at ON node_incidents(node_id, started_at);
@@ -0,0 +1,7 @@
+-- 0016_create_alert_rules.down.sql
+-- Migration: Drop alert rule tables
+
+DROP INDEX IF EXISTS idx_alerts_state;
+DROP INDEX IF EXISTS idx_alerts_rule_id_state;
+DROP TABLE IF EXISTS alerts;
+DROP TABLE IF EXISTS alert_rules;
@@ -0,0 +1,34 @@
+-- 0016_create_alert_rules.up.sql
+-- Migration: User-defined alert rules evaluated against Prometheus and the alerts they raise
+
+-- PromQL alert rules, every series returned by the expression is an alert
+CREATE TABLE alert_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    expression TEXT NOT NULL,
+    for_seconds INTEGER NOT NULL DEFAULT 0, -- how long a series must be returned before the alert fires
+    severity TEXT NOT NULL DEFAULT 'warning', -- 'info', 'warning' or 'critical'
+    provider_ids TEXT NOT NULL DEFAULT '[]', -- JSON array of notification provider IDs
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    last_evaluated_at TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Alerts raised by a rule, one per label set, active while pending or firing
+CREATE TABLE alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    rule_id INTEGER NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    labels TEXT NOT NULL, -- JSON object of the series labels
+    state TEXT NOT NULL, -- 'pending', 'firing' or 'resolved'
+    value REAL NOT NULL DEFAULT 0,
+    active_at TIMESTAMP NOT NULL,
+    fired_at TIMESTAMP,
+    resolved_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_alerts_rule_id_state ON alerts(rule_id, state);
+CREATE INDEX idx_alerts_state ON alerts(state);
@@ -0,0 +1,6 @@
+-- 0017_create_api_tokens.down.sql
+-- Migration: Drop API tokens and the service account flag
+
+DROP INDEX IF EXISTS idx_api_tokens_user_id;
+DROP TABLE IF EXISTS api_tokens;
+ALTER TABLE users DROP COLUMN service_account;
@@ -0,0 +1,23 @@
+-- 0017_create_api_tokens.up.sql
+-- Migration: Service accounts and long-lived API tokens for automation
+
+-- Service accounts are users that cannot log in and only authenticate with API tokens
+ALTER TABLE users ADD COLUMN service_account BOOLEAN NOT NULL DEFAULT false;
+
+-- API tokens, only the SHA-256 hash of a token is stored
+CREATE TABLE api_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_prefix TEXT NOT NULL, -- first characters of the token, to tell tokens apart
+    token_hash TEXT NOT NULL UNIQUE, -- hex encoded SHA-256 of the token
+    role TEXT NOT NULL, -- never above the role of the user
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    last_used_ip TEXT,
+    revoked_at TIMESTAMP,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
@@ -0,0 +1,5 @@
+-- 0018_create_role_bindings.down.sql
+-- Migration: Drop role bindings
+
+DROP INDEX IF EXISTS idx_role_bindings_user_id;
+DROP TABLE IF EXISTS role_bindings;
@@ -0,0 +1,17 @@
+-- 0018_create_role_bindings.up.sql
+-- Migration: Roles scoped to organizations, networks, nodes and keys
+
+-- A role binding grants a user a role on a single resource and the resources below it,
+-- on top of the global role of the user
+CREATE TABLE role_bindings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resource_type TEXT NOT NULL, -- organization, network, node or key
+    resource_id INTEGER NOT NULL,
+    role TEXT NOT NULL, -- admin or manager
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(user_id, resource_type, resource_id)
+);
+
+CREATE INDEX idx_role_bindings_user_id ON role_bindings(user_id);
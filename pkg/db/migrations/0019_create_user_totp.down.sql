@@ -0,0 +1,7 @@
+-- 0019_create_user_totp.down.sql
+-- Migration: Drop TOTP two-factor authentication and login lockout
+
+DROP TABLE IF EXISTS login_failures;
+DROP INDEX IF EXISTS idx_user_recovery_codes_user_id;
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
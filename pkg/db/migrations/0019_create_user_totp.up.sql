@@ -0,0 +1,31 @@
+-- 0019_create_user_totp.up.sql
+-- Migration: TOTP two-factor authentication and login lockout
+
+-- TOTP secret of a user, the secret is encrypted and only used once enabled is set
+CREATE TABLE user_totp (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT 0,
+    last_used_step INTEGER NOT NULL DEFAULT 0, -- prevents a code from being used twice
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    enabled_at TIMESTAMP
+);
+
+-- Single-use recovery codes, only their hash is stored
+CREATE TABLE user_recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash TEXT NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
+
+-- Failed logins by username, also for unknown usernames so that lockouts don't reveal which users exist
+CREATE TABLE login_failures (
+    username TEXT PRIMARY KEY,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP
+);
@@ -0,0 +1,7 @@
+-- SQLite doesn't support multiple column drops in a single ALTER TABLE statement
+ALTER TABLE backup_targets DROP COLUMN local_path;
+ALTER TABLE backup_targets DROP COLUMN sftp_host;
+ALTER TABLE backup_targets DROP COLUMN sftp_port;
+ALTER TABLE backup_targets DROP COLUMN sftp_username;
+ALTER TABLE backup_targets DROP COLUMN sftp_private_key;
+ALTER TABLE backup_targets DROP COLUMN sftp_path;
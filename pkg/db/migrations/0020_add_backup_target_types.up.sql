@@ -0,0 +1,7 @@
+-- Local filesystem and SFTP backup targets
+ALTER TABLE backup_targets ADD COLUMN local_path TEXT;
+ALTER TABLE backup_targets ADD COLUMN sftp_host TEXT;
+ALTER TABLE backup_targets ADD COLUMN sftp_port INTEGER;
+ALTER TABLE backup_targets ADD COLUMN sftp_username TEXT;
+ALTER TABLE backup_targets ADD COLUMN sftp_private_key TEXT;
+ALTER TABLE backup_targets ADD COLUMN sftp_path TEXT;
@@ -0,0 +1,2 @@
+ALTER TABLE backups DROP COLUMN snapshot_id;
+ALTER TABLE backup_targets DROP COLUMN engine;
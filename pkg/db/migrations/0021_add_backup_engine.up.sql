@@ -0,0 +1,5 @@
+-- Backup engine of a target: RESTIC runs the restic binary, NATIVE the built-in engine
+ALTER TABLE backup_targets ADD COLUMN engine TEXT NOT NULL DEFAULT 'RESTIC';
+
+-- Snapshot holding the data of a backup
+ALTER TABLE backups ADD COLUMN snapshot_id TEXT;
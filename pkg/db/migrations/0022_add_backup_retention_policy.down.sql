@@ -0,0 +1,4 @@
+ALTER TABLE backup_schedules DROP COLUMN keep_monthly;
+ALTER TABLE backup_schedules DROP COLUMN keep_weekly;
+ALTER TABLE backup_schedules DROP COLUMN keep_daily;
+ALTER TABLE backup_schedules DROP COLUMN keep_last;
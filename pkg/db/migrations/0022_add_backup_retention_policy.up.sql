@@ -0,0 +1,5 @@
+-- Grandfather-father-son retention of scheduled backups, on top of retention_days
+ALTER TABLE backup_schedules ADD COLUMN keep_last INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE backup_schedules ADD COLUMN keep_daily INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE backup_schedules ADD COLUMN keep_weekly INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE backup_schedules ADD COLUMN keep_monthly INTEGER NOT NULL DEFAULT 0;
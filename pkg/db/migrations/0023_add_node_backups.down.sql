@@ -0,0 +1,7 @@
+ALTER TABLE backups DROP COLUMN node_metadata;
+
+ALTER TABLE backup_schedules DROP COLUMN stop_order;
+ALTER TABLE backup_schedules DROP COLUMN consistency_strategy;
+ALTER TABLE backup_schedules DROP COLUMN network_id;
+ALTER TABLE backup_schedules DROP COLUMN node_ids;
+ALTER TABLE backup_schedules DROP COLUMN scope;
@@ -0,0 +1,10 @@
+-- Schedules can back up the nodes of a network or a list of nodes, made consistent
+-- with a ledger snapshot, a stop/start of the nodes or a docker export
+ALTER TABLE backup_schedules ADD COLUMN scope TEXT NOT NULL DEFAULT 'ALL';
+ALTER TABLE backup_schedules ADD COLUMN node_ids TEXT;
+ALTER TABLE backup_schedules ADD COLUMN network_id INTEGER REFERENCES networks(id) ON DELETE SET NULL;
+ALTER TABLE backup_schedules ADD COLUMN consistency_strategy TEXT NOT NULL DEFAULT 'NONE';
+ALTER TABLE backup_schedules ADD COLUMN stop_order TEXT;
+
+-- What was done for each node of a backup, as JSON
+ALTER TABLE backups ADD COLUMN node_metadata TEXT;
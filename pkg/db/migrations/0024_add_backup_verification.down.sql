@@ -0,0 +1,5 @@
+ALTER TABLE backup_schedules DROP COLUMN verify_cron_expression;
+
+ALTER TABLE backups DROP COLUMN verified_at;
+ALTER TABLE backups DROP COLUMN verification_report;
+ALTER TABLE backups DROP COLUMN verification_status;
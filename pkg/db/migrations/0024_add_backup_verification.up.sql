@@ -0,0 +1,7 @@
+-- Backups are verified by a repository check and a test restore, the report is JSON
+ALTER TABLE backups ADD COLUMN verification_status TEXT;
+ALTER TABLE backups ADD COLUMN verification_report TEXT;
+ALTER TABLE backups ADD COLUMN verified_at DATETIME;
+
+-- When the latest backup of a schedule is verified, no verification when NULL
+ALTER TABLE backup_schedules ADD COLUMN verify_cron_expression TEXT;
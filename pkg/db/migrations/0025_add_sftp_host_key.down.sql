@@ -0,0 +1 @@
+ALTER TABLE backup_targets DROP COLUMN sftp_host_key;
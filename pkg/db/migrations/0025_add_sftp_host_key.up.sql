@@ -0,0 +1,2 @@
+-- Pinned public host keys of SFTP servers, in known_hosts or authorized_keys format
+ALTER TABLE backup_targets ADD COLUMN sftp_host_key TEXT;
@@ -9,6 +9,48 @@ import (
 	"time"
 )
 
+type Alert struct {
+	ID         int64        `json:"id"`
+	RuleID     int64        `json:"ruleId"`
+	Labels     string       `json:"labels"`
+	State      string       `json:"state"`
+	Value      float64      `json:"value"`
+	ActiveAt   time.Time    `json:"activeAt"`
+	FiredAt    sql.NullTime `json:"firedAt"`
+	ResolvedAt sql.NullTime `json:"resolvedAt"`
+	UpdatedAt  time.Time    `json:"updatedAt"`
+}
+
+type AlertRule struct {
+	ID              int64          `json:"id"`
+	Name            string         `json:"name"`
+	Description     sql.NullString `json:"description"`
+	Expression      string         `json:"expression"`
+	ForSeconds      int64          `json:"forSeconds"`
+	Severity        string         `json:"severity"`
+	ProviderIds     string         `json:"providerIds"`
+	Enabled         bool           `json:"enabled"`
+	LastEvaluatedAt sql.NullTime   `json:"lastEvaluatedAt"`
+	LastError       sql.NullString `json:"lastError"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+}
+
+type ApiToken struct {
+	ID          int64          `json:"id"`
+	UserID      int64          `json:"userId"`
+	Name        string         `json:"name"`
+	TokenPrefix string         `json:"tokenPrefix"`
+	TokenHash   string         `json:"tokenHash"`
+	Role        string         `json:"role"`
+	ExpiresAt   sql.NullTime   `json:"expiresAt"`
+	LastUsedAt  sql.NullTime   `json:"lastUsedAt"`
+	LastUsedIp  sql.NullString `json:"lastUsedIp"`
+	RevokedAt   sql.NullTime   `json:"revokedAt"`
+	CreatedBy   sql.NullInt64  `json:"createdBy"`
+	CreatedAt   time.Time      `json:"createdAt"`
+}
+
 type AuditLog struct {
 	ID               int64          `json:"id"`
 	Timestamp        time.Time      `json:"timestamp"`
@@ -27,30 +69,45 @@ type AuditLog struct {
 }
 
 type Backup struct {
-	ID               int64          `json:"id"`
-	ScheduleID       sql.NullInt64  `json:"scheduleId"`
-	TargetID         int64          `json:"targetId"`
-	Status           string         `json:"status"`
-	SizeBytes        sql.NullInt64  `json:"sizeBytes"`
-	StartedAt        time.Time      `json:"startedAt"`
-	CompletedAt      sql.NullTime   `json:"completedAt"`
-	ErrorMessage     sql.NullString `json:"errorMessage"`
-	CreatedAt        time.Time      `json:"createdAt"`
-	NotificationSent int64          `json:"notificationSent"`
+	ID                 int64          `json:"id"`
+	ScheduleID         sql.NullInt64  `json:"scheduleId"`
+	TargetID           int64          `json:"targetId"`
+	Status             string         `json:"status"`
+	SizeBytes          sql.NullInt64  `json:"sizeBytes"`
+	StartedAt          time.Time      `json:"startedAt"`
+	CompletedAt        sql.NullTime   `json:"completedAt"`
+	ErrorMessage       sql.NullString `json:"errorMessage"`
+	CreatedAt          time.Time      `json:"createdAt"`
+	NotificationSent   int64          `json:"notificationSent"`
+	SnapshotID         sql.NullString `json:"snapshotId"`
+	NodeMetadata       sql.NullString `json:"nodeMetadata"`
+	VerificationStatus sql.NullString `json:"verificationStatus"`
+	VerificationReport sql.NullString `json:"verificationReport"`
+	VerifiedAt         sql.NullTime   `json:"verifiedAt"`
 }
 
 type BackupSchedule struct {
-	ID             int64          `json:"id"`
-	Name           string         `json:"name"`
-	Description    sql.NullString `json:"description"`
-	CronExpression string         `json:"cronExpression"`
-	TargetID       int64          `json:"targetId"`
-	RetentionDays  int64          `json:"retentionDays"`
-	Enabled        bool           `json:"enabled"`
-	CreatedAt      time.Time      `json:"createdAt"`
-	UpdatedAt      sql.NullTime   `json:"updatedAt"`
-	LastRunAt      sql.NullTime   `json:"lastRunAt"`
-	NextRunAt      sql.NullTime   `json:"nextRunAt"`
+	ID                   int64          `json:"id"`
+	Name                 string         `json:"name"`
+	Description          sql.NullString `json:"description"`
+	CronExpression       string         `json:"cronExpression"`
+	TargetID             int64          `json:"targetId"`
+	RetentionDays        int64          `json:"retentionDays"`
+	Enabled              bool           `json:"enabled"`
+	CreatedAt            time.Time      `json:"createdAt"`
+	UpdatedAt            sql.NullTime   `json:"updatedAt"`
+	LastRunAt            sql.NullTime   `json:"lastRunAt"`
+	NextRunAt            sql.NullTime   `json:"nextRunAt"`
+	KeepLast             int64          `json:"keepLast"`
+	KeepDaily            int64          `json:"keepDaily"`
+	KeepWeekly           int64          `json:"keepWeekly"`
+	KeepMonthly          int64          `json:"keepMonthly"`
+	Scope                string         `json:"scope"`
+	NodeIds              sql.NullString `json:"nodeIds"`
+	NetworkID            sql.NullInt64  `json:"networkId"`
+	ConsistencyStrategy  string         `json:"consistencyStrategy"`
+	StopOrder            sql.NullString `json:"stopOrder"`
+	VerifyCronExpression sql.NullString `json:"verifyCronExpression"`
 }
 
 type BackupTarget struct {
@@ -67,12 +124,44 @@ type BackupTarget struct {
 	Type           string         `json:"type"`
 	CreatedAt      time.Time      `json:"createdAt"`
 	UpdatedAt      sql.NullTime   `json:"updatedAt"`
+	LocalPath      sql.NullString `json:"localPath"`
+	SftpHost       sql.NullString `json:"sftpHost"`
+	SftpPort       sql.NullInt64  `json:"sftpPort"`
+	SftpUsername   sql.NullString `json:"sftpUsername"`
+	SftpPrivateKey sql.NullString `json:"sftpPrivateKey"`
+	SftpPath       sql.NullString `json:"sftpPath"`
+	Engine         string         `json:"engine"`
+	SftpHostKey    sql.NullString `json:"sftpHostKey"`
+}
+
+type BesuValidatorChange struct {
+	ID            int64          `json:"id"`
+	NetworkID     int64          `json:"networkId"`
+	Action        string         `json:"action"`
+	Address       string         `json:"address"`
+	KeyID         sql.NullInt64  `json:"keyId"`
+	Status        string         `json:"status"`
+	Votes         sql.NullString `json:"votes"`
+	VotesRequired int64          `json:"votesRequired"`
+	ErrorMessage  sql.NullString `json:"errorMessage"`
+	CreatedBy     sql.NullString `json:"createdBy"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	AppliedAt     sql.NullTime   `json:"appliedAt"`
 }
 
 type BlockchainPlatform struct {
 	Name string `json:"name"`
 }
 
+type CertificateExpiryAlert struct {
+	ID            int64     `json:"id"`
+	Subject       string    `json:"subject"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	ThresholdDays int64     `json:"thresholdDays"`
+	NotifiedAt    time.Time `json:"notifiedAt"`
+}
+
 type FabricChaincode struct {
 	ID        int64        `json:"id"`
 	Name      string       `json:"name"`
@@ -176,6 +265,13 @@ type KeyProviderType struct {
 	Name string `json:"name"`
 }
 
+type LoginFailure struct {
+	Username       string       `json:"username"`
+	FailedAttempts int64        `json:"failedAttempts"`
+	LastFailedAt   time.Time    `json:"lastFailedAt"`
+	LockedUntil    sql.NullTime `json:"lockedUntil"`
+}
+
 type Network struct {
 	ID                    int64          `json:"id"`
 	Name                  string         `json:"name"`
@@ -228,6 +324,31 @@ type Node struct {
 	ErrorMessage         sql.NullString `json:"errorMessage"`
 }
 
+type NodeCertificateSetting struct {
+	NodeID    int64     `json:"nodeId"`
+	AutoRenew bool      `json:"autoRenew"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type NodeCheck struct {
+	ID             int64          `json:"id"`
+	NodeID         int64          `json:"nodeId"`
+	Status         string         `json:"status"`
+	ResponseTimeMs int64          `json:"responseTimeMs"`
+	ErrorMessage   sql.NullString `json:"errorMessage"`
+	FailureCount   int64          `json:"failureCount"`
+	CheckedAt      time.Time      `json:"checkedAt"`
+}
+
+type NodeCheckRollup struct {
+	NodeID            int64     `json:"nodeId"`
+	BucketStart       time.Time `json:"bucketStart"`
+	TotalChecks       int64     `json:"totalChecks"`
+	UpChecks          int64     `json:"upChecks"`
+	AvgResponseTimeMs int64     `json:"avgResponseTimeMs"`
+	MaxResponseTimeMs int64     `json:"maxResponseTimeMs"`
+}
+
 type NodeEvent struct {
 	ID          int64          `json:"id"`
 	NodeID      int64          `json:"nodeId"`
@@ -238,6 +359,14 @@ type NodeEvent struct {
 	CreatedAt   time.Time      `json:"createdAt"`
 }
 
+type NodeIncident struct {
+	ID           int64          `json:"id"`
+	NodeID       int64          `json:"nodeId"`
+	StartedAt    time.Time      `json:"startedAt"`
+	ResolvedAt   sql.NullTime   `json:"resolvedAt"`
+	ErrorMessage sql.NullString `json:"errorMessage"`
+}
+
 type NodeKey struct {
 	ID        int64     `json:"id"`
 	NodeID    int64     `json:"nodeId"`
@@ -274,6 +403,7 @@ type NotificationProvider struct {
 	LastTestAt              sql.NullTime   `json:"lastTestAt"`
 	LastTestStatus          sql.NullString `json:"lastTestStatus"`
 	LastTestMessage         sql.NullString `json:"lastTestMessage"`
+	NotifyCertificateExpiry bool           `json:"notifyCertificateExpiry"`
 }
 
 type Plugin struct {
@@ -305,6 +435,39 @@ type PrometheusConfig struct {
 	UpdatedAt           time.Time      `json:"updatedAt"`
 }
 
+type Proposal struct {
+	ID           string         `json:"id"`
+	NetworkID    int64          `json:"networkId"`
+	ChannelName  string         `json:"channelName"`
+	Status       string         `json:"status"`
+	Operations   string         `json:"operations"`
+	PreviewJson  sql.NullString `json:"previewJson"`
+	ConfigUpdate []byte         `json:"configUpdate"`
+	TxResponse   sql.NullString `json:"txResponse"`
+	CreatedBy    string         `json:"createdBy"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    sql.NullTime   `json:"updatedAt"`
+}
+
+type ProposalSignature struct {
+	ID         int64     `json:"id"`
+	ProposalID string    `json:"proposalId"`
+	MspID      string    `json:"mspId"`
+	SignedBy   string    `json:"signedBy"`
+	Signature  []byte    `json:"signature"`
+	SignedAt   time.Time `json:"signedAt"`
+}
+
+type RoleBinding struct {
+	ID           int64         `json:"id"`
+	UserID       int64         `json:"userId"`
+	ResourceType string        `json:"resourceType"`
+	ResourceID   int64         `json:"resourceId"`
+	Role         string        `json:"role"`
+	CreatedBy    sql.NullInt64 `json:"createdBy"`
+	CreatedAt    time.Time     `json:"createdAt"`
+}
+
 type Session struct {
 	ID             int64          `json:"id"`
 	SessionID      string         `json:"sessionId"`
@@ -326,16 +489,34 @@ type Setting struct {
 }
 
 type User struct {
-	ID          int64          `json:"id"`
-	Username    string         `json:"username"`
-	Password    string         `json:"password"`
-	Name        sql.NullString `json:"name"`
-	Email       sql.NullString `json:"email"`
-	Role        sql.NullString `json:"role"`
-	Provider    sql.NullString `json:"provider"`
-	ProviderID  sql.NullString `json:"providerId"`
-	AvatarUrl   sql.NullString `json:"avatarUrl"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	LastLoginAt sql.NullTime   `json:"lastLoginAt"`
-	UpdatedAt   sql.NullTime   `json:"updatedAt"`
+	ID             int64          `json:"id"`
+	Username       string         `json:"username"`
+	Password       string         `json:"password"`
+	Name           sql.NullString `json:"name"`
+	Email          sql.NullString `json:"email"`
+	Role           sql.NullString `json:"role"`
+	Provider       sql.NullString `json:"provider"`
+	ProviderID     sql.NullString `json:"providerId"`
+	AvatarUrl      sql.NullString `json:"avatarUrl"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	LastLoginAt    sql.NullTime   `json:"lastLoginAt"`
+	UpdatedAt      sql.NullTime   `json:"updatedAt"`
+	ServiceAccount bool           `json:"serviceAccount"`
+}
+
+type UserRecoveryCode struct {
+	ID        int64        `json:"id"`
+	UserID    int64        `json:"userId"`
+	CodeHash  string       `json:"codeHash"`
+	UsedAt    sql.NullTime `json:"usedAt"`
+	CreatedAt time.Time    `json:"createdAt"`
+}
+
+type UserTotp struct {
+	UserID       int64        `json:"userId"`
+	Secret       string       `json:"secret"`
+	Enabled      bool         `json:"enabled"`
+	LastUsedStep int64        `json:"lastUsedStep"`
+	CreatedAt    time.Time    `json:"createdAt"`
+	EnabledAt    sql.NullTime `json:"enabledAt"`
 }
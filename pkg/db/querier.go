@@ -7,6 +7,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 type Querier interface {
@@ -20,11 +21,17 @@ type Querier interface {
 	CountNodeEvents(ctx context.Context, nodeID int64) (int64, error)
 	CountNodes(ctx context.Context) (int64, error)
 	CountNodesByPlatform(ctx context.Context, platform string) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error)
 	CountUsers(ctx context.Context) (int64, error)
+	CreateAPIToken(ctx context.Context, arg *CreateAPITokenParams) (*ApiToken, error)
+	CreateAlert(ctx context.Context, arg *CreateAlertParams) (*Alert, error)
+	CreateAlertRule(ctx context.Context, arg *CreateAlertRuleParams) (*AlertRule, error)
 	CreateAuditLog(ctx context.Context, arg *CreateAuditLogParams) (*AuditLog, error)
 	CreateBackup(ctx context.Context, arg *CreateBackupParams) (*Backup, error)
 	CreateBackupSchedule(ctx context.Context, arg *CreateBackupScheduleParams) (*BackupSchedule, error)
 	CreateBackupTarget(ctx context.Context, arg *CreateBackupTargetParams) (*BackupTarget, error)
+	CreateBesuValidatorChange(ctx context.Context, arg *CreateBesuValidatorChangeParams) (*BesuValidatorChange, error)
+	CreateCertificateExpiryAlert(ctx context.Context, arg *CreateCertificateExpiryAlertParams) (int64, error)
 	CreateChaincode(ctx context.Context, arg *CreateChaincodeParams) (*FabricChaincode, error)
 	CreateChaincodeDefinition(ctx context.Context, arg *CreateChaincodeDefinitionParams) (*FabricChaincodeDefinition, error)
 	CreateFabricOrganization(ctx context.Context, arg *CreateFabricOrganizationParams) (*FabricOrganization, error)
@@ -35,12 +42,22 @@ type Querier interface {
 	// Add queries for CRUD operations
 	CreateNetworkNode(ctx context.Context, arg *CreateNetworkNodeParams) (*NetworkNode, error)
 	CreateNode(ctx context.Context, arg *CreateNodeParams) (*Node, error)
+	CreateNodeCheck(ctx context.Context, arg *CreateNodeCheckParams) (*NodeCheck, error)
 	CreateNodeEvent(ctx context.Context, arg *CreateNodeEventParams) (*NodeEvent, error)
+	CreateNodeIncident(ctx context.Context, arg *CreateNodeIncidentParams) (*NodeIncident, error)
 	CreateNotificationProvider(ctx context.Context, arg *CreateNotificationProviderParams) (*NotificationProvider, error)
+	CreateOIDCUser(ctx context.Context, arg *CreateOIDCUserParams) (*User, error)
 	CreatePlugin(ctx context.Context, arg *CreatePluginParams) (*Plugin, error)
+	CreateProposal(ctx context.Context, arg *CreateProposalParams) (*Proposal, error)
+	CreateRecoveryCode(ctx context.Context, arg *CreateRecoveryCodeParams) error
+	CreateRoleBinding(ctx context.Context, arg *CreateRoleBindingParams) (*RoleBinding, error)
+	CreateServiceAccount(ctx context.Context, arg *CreateServiceAccountParams) (*User, error)
 	CreateSession(ctx context.Context, arg *CreateSessionParams) (*Session, error)
 	CreateSetting(ctx context.Context, config string) (*Setting, error)
 	CreateUser(ctx context.Context, arg *CreateUserParams) (*User, error)
+	DeleteAlert(ctx context.Context, id int64) error
+	DeleteAlertRule(ctx context.Context, id int64) error
+	DeleteAlertsResolvedBefore(ctx context.Context, resolvedAt sql.NullTime) (int64, error)
 	DeleteBackup(ctx context.Context, id int64) error
 	DeleteBackupSchedule(ctx context.Context, id int64) error
 	DeleteBackupTarget(ctx context.Context, id int64) error
@@ -52,19 +69,30 @@ type Querier interface {
 	DeleteFabricOrganization(ctx context.Context, id int64) error
 	DeleteKey(ctx context.Context, id int64) error
 	DeleteKeyProvider(ctx context.Context, id int64) error
+	DeleteLoginFailure(ctx context.Context, username string) error
 	DeleteNetwork(ctx context.Context, id int64) error
 	DeleteNetworkNode(ctx context.Context, arg *DeleteNetworkNodeParams) error
 	DeleteNode(ctx context.Context, id int64) error
+	DeleteNodeCheckRollupsBefore(ctx context.Context, bucketStart time.Time) (int64, error)
+	DeleteNodeChecksBefore(ctx context.Context, checkedAt time.Time) (int64, error)
+	DeleteNodeIncidentsBefore(ctx context.Context, resolvedAt sql.NullTime) (int64, error)
 	DeleteNotificationProvider(ctx context.Context, id int64) error
 	DeleteOldBackups(ctx context.Context, arg *DeleteOldBackupsParams) error
 	DeletePlugin(ctx context.Context, name string) error
+	DeleteRecoveryCodesByUser(ctx context.Context, userID int64) error
 	DeleteRevokedCertificate(ctx context.Context, arg *DeleteRevokedCertificateParams) error
+	DeleteRoleBinding(ctx context.Context, id int64) error
 	DeleteSession(ctx context.Context, token string) error
 	DeleteSetting(ctx context.Context, id int64) error
 	DeleteUser(ctx context.Context, id int64) error
 	DeleteUserSessions(ctx context.Context, userID int64) error
+	DeleteUserTOTP(ctx context.Context, userID int64) error
 	DisableBackupSchedule(ctx context.Context, id int64) (*BackupSchedule, error)
 	EnableBackupSchedule(ctx context.Context, id int64) (*BackupSchedule, error)
+	EnableUserTOTP(ctx context.Context, arg *EnableUserTOTPParams) error
+	GetAPIToken(ctx context.Context, id int64) (*ApiToken, error)
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (*ApiToken, error)
+	GetAlertRule(ctx context.Context, id int64) (*AlertRule, error)
 	GetAllKeys(ctx context.Context, arg *GetAllKeysParams) ([]*GetAllKeysRow, error)
 	GetAllNodes(ctx context.Context) ([]*Node, error)
 	GetAuditLog(ctx context.Context, id int64) (*AuditLog, error)
@@ -74,6 +102,7 @@ type Querier interface {
 	GetBackupsByDateRange(ctx context.Context, arg *GetBackupsByDateRangeParams) ([]*Backup, error)
 	GetBackupsByScheduleAndStatus(ctx context.Context, arg *GetBackupsByScheduleAndStatusParams) ([]*Backup, error)
 	GetBackupsByStatus(ctx context.Context, status string) ([]*Backup, error)
+	GetBesuValidatorChange(ctx context.Context, id int64) (*BesuValidatorChange, error)
 	GetChaincode(ctx context.Context, id int64) (*GetChaincodeRow, error)
 	GetChaincodeDefinition(ctx context.Context, id int64) (*FabricChaincodeDefinition, error)
 	GetDefaultNotificationProvider(ctx context.Context, type_ string) (*NotificationProvider, error)
@@ -95,7 +124,9 @@ type Querier interface {
 	GetKeyProviderByID(ctx context.Context, id int64) (*KeyProvider, error)
 	GetKeysByFilter(ctx context.Context, arg *GetKeysByFilterParams) ([]*GetKeysByFilterRow, error)
 	GetKeysCount(ctx context.Context) (int64, error)
+	GetLatestCompletedBackupBefore(ctx context.Context, arg *GetLatestCompletedBackupBeforeParams) (*Backup, error)
 	GetLatestNodeEvent(ctx context.Context, nodeID int64) (*NodeEvent, error)
+	GetLoginFailure(ctx context.Context, username string) (*LoginFailure, error)
 	GetNetwork(ctx context.Context, id int64) (*Network, error)
 	GetNetworkByName(ctx context.Context, name string) (*Network, error)
 	GetNetworkByNetworkId(ctx context.Context, networkID sql.NullString) (*Network, error)
@@ -104,65 +135,108 @@ type Querier interface {
 	GetNetworkNodes(ctx context.Context, networkID int64) ([]*GetNetworkNodesRow, error)
 	GetNode(ctx context.Context, id int64) (*Node, error)
 	GetNodeBySlug(ctx context.Context, slug string) (*Node, error)
+	GetNodeCertificateSettings(ctx context.Context, nodeID int64) (*NodeCertificateSetting, error)
 	GetNodeEvent(ctx context.Context, id int64) (*NodeEvent, error)
 	GetNotificationProvider(ctx context.Context, id int64) (*NotificationProvider, error)
 	GetOldestBackupByTarget(ctx context.Context, targetID int64) (*Backup, error)
+	GetOpenNodeIncident(ctx context.Context, nodeID int64) (*NodeIncident, error)
 	GetOrdererPorts(ctx context.Context) ([]*GetOrdererPortsRow, error)
 	GetOrganizationCRLInfo(ctx context.Context, id int64) (*GetOrganizationCRLInfoRow, error)
 	GetPeerPorts(ctx context.Context) ([]*GetPeerPortsRow, error)
 	GetPlugin(ctx context.Context, name string) (*Plugin, error)
 	GetPrometheusConfig(ctx context.Context) (*PrometheusConfig, error)
+	GetProposal(ctx context.Context, id string) (*Proposal, error)
 	GetProvidersByNotificationType(ctx context.Context, arg *GetProvidersByNotificationTypeParams) ([]*NotificationProvider, error)
 	GetRecentCompletedBackups(ctx context.Context) ([]*Backup, error)
 	GetRevokedCertificate(ctx context.Context, arg *GetRevokedCertificateParams) (*FabricRevokedCertificate, error)
 	GetRevokedCertificateCount(ctx context.Context, fabricOrganizationID int64) (int64, error)
 	GetRevokedCertificates(ctx context.Context, fabricOrganizationID int64) ([]*FabricRevokedCertificate, error)
+	GetRoleBinding(ctx context.Context, id int64) (*RoleBinding, error)
 	GetSession(ctx context.Context, token string) (*Session, error)
 	GetSessionBySessionID(ctx context.Context, sessionID string) (*Session, error)
 	GetSessionByToken(ctx context.Context, token string) (*Session, error)
 	GetSetting(ctx context.Context, id int64) (*Setting, error)
 	GetUser(ctx context.Context, id int64) (*User, error)
+	GetUserByProvider(ctx context.Context, arg *GetUserByProviderParams) (*User, error)
 	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	GetUserTOTP(ctx context.Context, userID int64) (*UserTotp, error)
+	ListAPITokensByUser(ctx context.Context, userID int64) ([]*ApiToken, error)
+	ListActiveAlerts(ctx context.Context) ([]*ListActiveAlertsRow, error)
+	ListActiveAlertsByRule(ctx context.Context, ruleID int64) ([]*Alert, error)
+	ListAlertRules(ctx context.Context) ([]*AlertRule, error)
+	ListAlertsByRule(ctx context.Context, arg *ListAlertsByRuleParams) ([]*Alert, error)
 	ListAuditLogs(ctx context.Context, arg *ListAuditLogsParams) ([]*AuditLog, error)
+	ListAutoRenewNodeIDs(ctx context.Context) ([]int64, error)
 	ListBackupSchedules(ctx context.Context) ([]*BackupSchedule, error)
 	ListBackupTargets(ctx context.Context) ([]*BackupTarget, error)
 	ListBackups(ctx context.Context, arg *ListBackupsParams) ([]*Backup, error)
 	ListBackupsBySchedule(ctx context.Context, scheduleID sql.NullInt64) ([]*Backup, error)
 	ListBackupsByTarget(ctx context.Context, targetID int64) ([]*Backup, error)
+	ListBesuValidatorChangesByNetwork(ctx context.Context, networkID int64) ([]*BesuValidatorChange, error)
 	ListChaincodeDefinitionEvents(ctx context.Context, definitionID int64) ([]*FabricChaincodeDefinitionEvent, error)
 	ListChaincodeDefinitions(ctx context.Context, chaincodeID int64) ([]*FabricChaincodeDefinition, error)
 	ListChaincodes(ctx context.Context) ([]*FabricChaincode, error)
+	ListDefaultNotificationProvidersForType(ctx context.Context, notificationType interface{}) ([]*NotificationProvider, error)
+	ListEnabledAlertRules(ctx context.Context) ([]*AlertRule, error)
 	ListFabricChaincodes(ctx context.Context) ([]*FabricChaincode, error)
 	ListFabricOrganizations(ctx context.Context) ([]*FabricOrganization, error)
 	ListFabricOrganizationsWithKeys(ctx context.Context, arg *ListFabricOrganizationsWithKeysParams) ([]*ListFabricOrganizationsWithKeysRow, error)
+	ListKeyPrivateKeysByProviderType(ctx context.Context, type_ string) ([]*ListKeyPrivateKeysByProviderTypeRow, error)
 	ListKeyProviders(ctx context.Context) ([]*KeyProvider, error)
 	ListKeys(ctx context.Context, arg *ListKeysParams) ([]*ListKeysRow, error)
+	ListKeysWithCertificateExpiry(ctx context.Context) ([]*ListKeysWithCertificateExpiryRow, error)
+	ListLatestNodeChecks(ctx context.Context) ([]*ListLatestNodeChecksRow, error)
+	ListNetworkIDsByNode(ctx context.Context, nodeID int64) ([]int64, error)
 	ListNetworkNodesByNetwork(ctx context.Context, networkID int64) ([]*NetworkNode, error)
 	ListNetworkNodesByNode(ctx context.Context, nodeID int64) ([]*NetworkNode, error)
 	ListNetworks(ctx context.Context) ([]*Network, error)
 	ListNetworksByPlatform(ctx context.Context, platform string) ([]*Network, error)
+	ListNodeCheckRollups(ctx context.Context, arg *ListNodeCheckRollupsParams) ([]*NodeCheckRollup, error)
+	ListNodeChecksInRange(ctx context.Context, arg *ListNodeChecksInRangeParams) ([]*NodeCheck, error)
 	ListNodeEvents(ctx context.Context, arg *ListNodeEventsParams) ([]*NodeEvent, error)
 	ListNodeEventsByType(ctx context.Context, arg *ListNodeEventsByTypeParams) ([]*NodeEvent, error)
+	ListNodeIncidentsInRange(ctx context.Context, arg *ListNodeIncidentsInRangeParams) ([]*NodeIncident, error)
 	ListNodes(ctx context.Context, arg *ListNodesParams) ([]*Node, error)
 	ListNodesByNetwork(ctx context.Context, arg *ListNodesByNetworkParams) ([]*Node, error)
 	ListNodesByPlatform(ctx context.Context, arg *ListNodesByPlatformParams) ([]*Node, error)
 	ListNotificationProviders(ctx context.Context) ([]*NotificationProvider, error)
+	ListOpenNodeIncidents(ctx context.Context) ([]*NodeIncident, error)
+	ListOrganizationIDsByKey(ctx context.Context, keyID sql.NullInt64) ([]int64, error)
 	ListPeerStatuses(ctx context.Context, definitionID int64) ([]*FabricChaincodeDefinitionPeerStatus, error)
+	ListPendingBesuValidatorChanges(ctx context.Context, networkID int64) ([]*BesuValidatorChange, error)
 	ListPlugins(ctx context.Context) ([]*Plugin, error)
+	ListProposalSignatures(ctx context.Context, proposalID string) ([]*ProposalSignature, error)
+	ListProposalsByNetwork(ctx context.Context, networkID int64) ([]*Proposal, error)
+	ListRoleBindingsByUser(ctx context.Context, userID int64) ([]*RoleBinding, error)
+	ListServiceAccounts(ctx context.Context) ([]*User, error)
 	ListSettings(ctx context.Context) ([]*Setting, error)
 	ListUsers(ctx context.Context) ([]*User, error)
+	LockLogin(ctx context.Context, arg *LockLoginParams) error
 	MarkBackupNotified(ctx context.Context, id int64) error
+	RecordLoginFailure(ctx context.Context, arg *RecordLoginFailureParams) (*LoginFailure, error)
 	ResetPrometheusConfig(ctx context.Context) (*PrometheusConfig, error)
+	ResolveNodeIncident(ctx context.Context, arg *ResolveNodeIncidentParams) (*NodeIncident, error)
+	RevokeAPIToken(ctx context.Context, id int64) error
 	SetPeerStatus(ctx context.Context, arg *SetPeerStatusParams) (*FabricChaincodeDefinitionPeerStatus, error)
 	UnsetDefaultNotificationProvider(ctx context.Context, type_ string) error
 	UnsetDefaultProvider(ctx context.Context) error
+	UpdateAPITokenLastUsed(ctx context.Context, arg *UpdateAPITokenLastUsedParams) error
+	UpdateAlertRule(ctx context.Context, arg *UpdateAlertRuleParams) (*AlertRule, error)
+	UpdateAlertRuleEvaluation(ctx context.Context, arg *UpdateAlertRuleEvaluationParams) error
+	UpdateAlertState(ctx context.Context, arg *UpdateAlertStateParams) error
 	UpdateBackupCompleted(ctx context.Context, arg *UpdateBackupCompletedParams) (*Backup, error)
 	UpdateBackupFailed(ctx context.Context, arg *UpdateBackupFailedParams) (*Backup, error)
+	UpdateBackupNodeMetadata(ctx context.Context, arg *UpdateBackupNodeMetadataParams) error
 	UpdateBackupSchedule(ctx context.Context, arg *UpdateBackupScheduleParams) (*BackupSchedule, error)
 	UpdateBackupScheduleLastRun(ctx context.Context, arg *UpdateBackupScheduleLastRunParams) (*BackupSchedule, error)
 	UpdateBackupSize(ctx context.Context, arg *UpdateBackupSizeParams) (*Backup, error)
+	UpdateBackupSnapshot(ctx context.Context, arg *UpdateBackupSnapshotParams) (*Backup, error)
 	UpdateBackupStatus(ctx context.Context, arg *UpdateBackupStatusParams) (*Backup, error)
 	UpdateBackupTarget(ctx context.Context, arg *UpdateBackupTargetParams) (*BackupTarget, error)
+	UpdateBackupTargetSecrets(ctx context.Context, arg *UpdateBackupTargetSecretsParams) error
+	UpdateBackupVerification(ctx context.Context, arg *UpdateBackupVerificationParams) error
+	UpdateBesuValidatorChangeStatus(ctx context.Context, arg *UpdateBesuValidatorChangeStatusParams) (*BesuValidatorChange, error)
+	UpdateBesuValidatorChangeVotes(ctx context.Context, arg *UpdateBesuValidatorChangeVotesParams) (*BesuValidatorChange, error)
 	UpdateChaincode(ctx context.Context, arg *UpdateChaincodeParams) (*FabricChaincode, error)
 	UpdateChaincodeDefinition(ctx context.Context, arg *UpdateChaincodeDefinitionParams) (*FabricChaincodeDefinition, error)
 	UpdateDeploymentConfig(ctx context.Context, arg *UpdateDeploymentConfigParams) (*Node, error)
@@ -170,7 +244,9 @@ type Querier interface {
 	UpdateDeploymentStatus(ctx context.Context, arg *UpdateDeploymentStatusParams) error
 	UpdateFabricOrganization(ctx context.Context, arg *UpdateFabricOrganizationParams) (*FabricOrganization, error)
 	UpdateKey(ctx context.Context, arg *UpdateKeyParams) (*Key, error)
+	UpdateKeyPrivateKey(ctx context.Context, arg *UpdateKeyPrivateKeyParams) error
 	UpdateKeyProvider(ctx context.Context, arg *UpdateKeyProviderParams) (*KeyProvider, error)
+	UpdateNetworkConfig(ctx context.Context, arg *UpdateNetworkConfigParams) error
 	UpdateNetworkCurrentConfigBlock(ctx context.Context, arg *UpdateNetworkCurrentConfigBlockParams) error
 	UpdateNetworkGenesisBlock(ctx context.Context, arg *UpdateNetworkGenesisBlockParams) (*Network, error)
 	UpdateNetworkNodeRole(ctx context.Context, arg *UpdateNetworkNodeRoleParams) (*NetworkNode, error)
@@ -183,14 +259,23 @@ type Querier interface {
 	UpdateNodeStatus(ctx context.Context, arg *UpdateNodeStatusParams) (*Node, error)
 	UpdateNodeStatusWithError(ctx context.Context, arg *UpdateNodeStatusWithErrorParams) (*Node, error)
 	UpdateNotificationProvider(ctx context.Context, arg *UpdateNotificationProviderParams) (*NotificationProvider, error)
+	UpdateNotificationProviderConfig(ctx context.Context, arg *UpdateNotificationProviderConfigParams) error
+	UpdateOIDCUser(ctx context.Context, arg *UpdateOIDCUserParams) (*User, error)
 	UpdateOrganizationCRL(ctx context.Context, arg *UpdateOrganizationCRLParams) error
 	UpdatePlugin(ctx context.Context, arg *UpdatePluginParams) (*Plugin, error)
 	UpdatePrometheusConfig(ctx context.Context, arg *UpdatePrometheusConfigParams) (*PrometheusConfig, error)
+	UpdateProposalStatus(ctx context.Context, arg *UpdateProposalStatusParams) (*Proposal, error)
 	UpdateProviderTestResults(ctx context.Context, arg *UpdateProviderTestResultsParams) (*NotificationProvider, error)
 	UpdateSetting(ctx context.Context, arg *UpdateSettingParams) (*Setting, error)
 	UpdateUser(ctx context.Context, arg *UpdateUserParams) (*User, error)
 	UpdateUserLastLogin(ctx context.Context, id int64) (*User, error)
 	UpdateUserPassword(ctx context.Context, arg *UpdateUserPasswordParams) (*User, error)
+	UpdateUserTOTPLastUsedStep(ctx context.Context, arg *UpdateUserTOTPLastUsedStepParams) error
+	UpsertNodeCertificateSettings(ctx context.Context, arg *UpsertNodeCertificateSettingsParams) (*NodeCertificateSetting, error)
+	UpsertNodeCheckRollup(ctx context.Context, arg *UpsertNodeCheckRollupParams) error
+	UpsertProposalSignature(ctx context.Context, arg *UpsertProposalSignatureParams) (*ProposalSignature, error)
+	UpsertUserTOTP(ctx context.Context, arg *UpsertUserTOTPParams) (*UserTotp, error)
+	UseRecoveryCode(ctx context.Context, arg *UseRecoveryCodeParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
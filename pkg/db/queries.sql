@@ -219,6 +219,19 @@ SET name = ?,
 WHERE id = ?
 RETURNING *;
 
+-- name: ListKeyPrivateKeysByProviderType :many
+SELECT k.id, k.private_key
+FROM keys k
+JOIN key_providers kp ON k.provider_id = kp.id
+WHERE kp.type = ?
+ORDER BY k.id;
+
+-- name: UpdateKeyPrivateKey :exec
+UPDATE keys
+SET private_key = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
 -- name: UpdateKeyProvider :one
 UPDATE key_providers
 SET name = ?,
@@ -334,6 +347,7 @@ WHERE username = ? LIMIT 1;
 
 -- name: ListUsers :many
 SELECT * FROM users
+WHERE service_account = false
 ORDER BY created_at DESC;
 
 -- name: UpdateUser :one
@@ -584,6 +598,14 @@ INSERT INTO backup_targets (
     secret_key,
     s3_path_style,
     restic_password,
+    local_path,
+    sftp_host,
+    sftp_port,
+    sftp_username,
+    sftp_private_key,
+    sftp_path,
+    sftp_host_key,
+    engine,
     created_at,
     updated_at
 ) VALUES (
@@ -597,6 +619,14 @@ INSERT INTO backup_targets (
     ?,
     ?,
     ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
     CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP
 ) RETURNING *;
@@ -620,9 +650,29 @@ INSERT INTO backup_schedules (
     target_id,
     retention_days,
     enabled,
+    keep_last,
+    keep_daily,
+    keep_weekly,
+    keep_monthly,
+    scope,
+    node_ids,
+    network_id,
+    consistency_strategy,
+    stop_order,
+    verify_cron_expression,
     created_at,
     updated_at
 ) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
     ?,
     ?,
     ?,
@@ -730,6 +780,21 @@ SET size_bytes = ?
 WHERE id = ?
 RETURNING *;
 
+-- name: UpdateBackupSnapshot :one
+UPDATE backups
+SET snapshot_id = ?,
+    size_bytes = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: GetLatestCompletedBackupBefore :one
+SELECT * FROM backups
+WHERE target_id = ?
+  AND status = 'COMPLETED'
+  AND started_at <= ?
+ORDER BY started_at DESC
+LIMIT 1;
+
 -- name: GetBackupsByStatus :many
 SELECT * FROM backups
 WHERE status = ?
@@ -783,6 +848,14 @@ SET name = ?,
     access_key_id = ?,
     secret_key = ?,
     s3_path_style = ?,
+    local_path = ?,
+    sftp_host = ?,
+    sftp_port = ?,
+    sftp_username = ?,
+    sftp_private_key = ?,
+    sftp_path = ?,
+    sftp_host_key = ?,
+    engine = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
 RETURNING *;
@@ -795,6 +868,16 @@ SET name = ?,
     target_id = ?,
     retention_days = ?,
     enabled = ?,
+    keep_last = ?,
+    keep_daily = ?,
+    keep_weekly = ?,
+    keep_monthly = ?,
+    scope = ?,
+    node_ids = ?,
+    network_id = ?,
+    consistency_strategy = ?,
+    stop_order = ?,
+    verify_cron_expression = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
 RETURNING *;
@@ -809,6 +892,7 @@ INSERT INTO notification_providers (
     notify_backup_success,
     notify_backup_failure,
     notify_s3_connection_issue,
+    notify_certificate_expiry,
     created_at,
     updated_at
 ) VALUES (
@@ -820,6 +904,7 @@ INSERT INTO notification_providers (
     ?,
     ?,
     ?,
+    ?,
     CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP
 ) RETURNING *;
@@ -834,6 +919,7 @@ SET type = ?,
     notify_backup_success = ?,
     notify_backup_failure = ?,
     notify_s3_connection_issue = ?,
+    notify_certificate_expiry = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
 RETURNING *;
@@ -899,10 +985,23 @@ WHERE is_default = true
     (:notification_type = 'BACKUP_SUCCESS' AND notify_backup_success = true) OR
     (:notification_type = 'BACKUP_FAILURE' AND notify_backup_failure = true) OR
     (:notification_type = 'NODE_DOWNTIME' AND notify_node_downtime = true) OR
-    (:notification_type = 'S3_CONNECTION_ISSUE' AND notify_s3_connection_issue = true)
+    (:notification_type = 'S3_CONNECTION_ISSUE' AND notify_s3_connection_issue = true) OR
+    (:notification_type = 'CERTIFICATE_EXPIRING' AND notify_certificate_expiry = true)
   )
 LIMIT 1;
 
+-- name: ListDefaultNotificationProvidersForType :many
+SELECT * FROM notification_providers
+WHERE is_default = true
+  AND (
+    (:notification_type = 'BACKUP_SUCCESS' AND notify_backup_success = true) OR
+    (:notification_type = 'BACKUP_FAILURE' AND notify_backup_failure = true) OR
+    (:notification_type = 'NODE_DOWNTIME' AND notify_node_downtime = true) OR
+    (:notification_type = 'S3_CONNECTION_ISSUE' AND notify_s3_connection_issue = true) OR
+    (:notification_type = 'CERTIFICATE_EXPIRING' AND notify_certificate_expiry = true)
+  )
+ORDER BY id;
+
 -- name: AddRevokedCertificate :exec
 INSERT INTO fabric_revoked_certificates (
     fabric_organization_id,
@@ -1171,3 +1270,455 @@ INSERT INTO fabric_chaincode_definition_events (definition_id, event_type, event
 
 -- name: ListChaincodeDefinitionEvents :many
 SELECT id, definition_id, event_type, event_data, created_at FROM fabric_chaincode_definition_events WHERE definition_id = ? ORDER BY created_at ASC;
+
+-- name: CreateProposal :one
+INSERT INTO proposals (
+    id, network_id, channel_name, status, operations, preview_json, config_update, created_by
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?, ?
+) RETURNING *;
+
+-- name: GetProposal :one
+SELECT * FROM proposals WHERE id = ? LIMIT 1;
+
+-- name: ListProposalsByNetwork :many
+SELECT * FROM proposals WHERE network_id = ? ORDER BY created_at DESC;
+
+-- name: UpdateProposalStatus :one
+UPDATE proposals
+SET status = ?, tx_response = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: UpsertProposalSignature :one
+INSERT INTO proposal_signatures (proposal_id, msp_id, signed_by, signature)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(proposal_id, msp_id) DO UPDATE SET
+    signed_by = excluded.signed_by,
+    signature = excluded.signature,
+    signed_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ListProposalSignatures :many
+SELECT * FROM proposal_signatures WHERE proposal_id = ? ORDER BY signed_at ASC;
+
+-- name: ListKeysWithCertificateExpiry :many
+SELECT id, name, certificate, expires_at FROM keys
+WHERE certificate IS NOT NULL AND expires_at IS NOT NULL
+ORDER BY expires_at;
+
+-- name: GetNodeCertificateSettings :one
+SELECT * FROM node_certificate_settings
+WHERE node_id = ?;
+
+-- name: UpsertNodeCertificateSettings :one
+INSERT INTO node_certificate_settings (node_id, auto_renew)
+VALUES (?, ?)
+ON CONFLICT(node_id) DO UPDATE SET
+    auto_renew = excluded.auto_renew,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ListAutoRenewNodeIDs :many
+SELECT node_id FROM node_certificate_settings
+WHERE auto_renew = true;
+
+-- name: CreateCertificateExpiryAlert :execrows
+INSERT INTO certificate_expiry_alerts (subject, expires_at, threshold_days)
+VALUES (?, ?, ?)
+ON CONFLICT(subject, expires_at, threshold_days) DO NOTHING;
+
+-- name: CreateBesuValidatorChange :one
+INSERT INTO besu_validator_changes (network_id, action, address, key_id, votes_required, created_by)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetBesuValidatorChange :one
+SELECT * FROM besu_validator_changes
+WHERE id = ?;
+
+-- name: ListBesuValidatorChangesByNetwork :many
+SELECT * FROM besu_validator_changes
+WHERE network_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: ListPendingBesuValidatorChanges :many
+SELECT * FROM besu_validator_changes
+WHERE network_id = ? AND status = 'pending'
+ORDER BY id;
+
+-- name: UpdateBesuValidatorChangeVotes :one
+UPDATE besu_validator_changes
+SET votes = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateBesuValidatorChangeStatus :one
+UPDATE besu_validator_changes
+SET status = ?,
+    error_message = ?,
+    applied_at = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateNetworkConfig :exec
+UPDATE networks
+SET config = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: CreateNodeCheck :one
+INSERT INTO node_checks (node_id, status, response_time_ms, error_message, failure_count, checked_at)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListNodeChecksInRange :many
+SELECT * FROM node_checks
+WHERE node_id = ? AND checked_at >= ? AND checked_at < ?
+ORDER BY checked_at;
+
+-- name: ListLatestNodeChecks :many
+SELECT nc.*, n.name AS node_name, n.platform AS node_platform, n.endpoint AS node_endpoint
+FROM node_checks nc
+JOIN nodes n ON nc.node_id = n.id
+WHERE nc.id IN (SELECT MAX(id) FROM node_checks GROUP BY node_id);
+
+-- name: DeleteNodeChecksBefore :execrows
+DELETE FROM node_checks
+WHERE checked_at < ?;
+
+-- name: UpsertNodeCheckRollup :exec
+INSERT INTO node_check_rollups (node_id, bucket_start, total_checks, up_checks, avg_response_time_ms, max_response_time_ms)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(node_id, bucket_start) DO UPDATE SET
+    total_checks = excluded.total_checks,
+    up_checks = excluded.up_checks,
+    avg_response_time_ms = excluded.avg_response_time_ms,
+    max_response_time_ms = excluded.max_response_time_ms;
+
+-- name: ListNodeCheckRollups :many
+SELECT * FROM node_check_rollups
+WHERE node_id = ? AND bucket_start >= ? AND bucket_start < ?
+ORDER BY bucket_start;
+
+-- name: DeleteNodeCheckRollupsBefore :execrows
+DELETE FROM node_check_rollups
+WHERE bucket_start < ?;
+
+-- name: CreateNodeIncident :one
+INSERT INTO node_incidents (node_id, started_at, error_message)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetOpenNodeIncident :one
+SELECT * FROM node_incidents
+WHERE node_id = ? AND resolved_at IS NULL
+ORDER BY started_at DESC
+LIMIT 1;
+
+-- name: ListOpenNodeIncidents :many
+SELECT * FROM node_incidents
+WHERE resolved_at IS NULL;
+
+-- name: ResolveNodeIncident :one
+UPDATE node_incidents
+SET resolved_at = ?
+WHERE id = ?
+RETURNING *;
+
+-- name: ListNodeIncidentsInRange :many
+SELECT * FROM node_incidents
+WHERE node_id = ? AND started_at < ? AND (resolved_at IS NULL OR resolved_at > ?)
+ORDER BY started_at;
+
+-- name: DeleteNodeIncidentsBefore :execrows
+DELETE FROM node_incidents
+WHERE resolved_at IS NOT NULL AND resolved_at < ?;
+
+-- name: CreateAlertRule :one
+INSERT INTO alert_rules (name, description, expression, for_seconds, severity, provider_ids, enabled)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetAlertRule :one
+SELECT * FROM alert_rules
+WHERE id = ? LIMIT 1;
+
+-- name: ListAlertRules :many
+SELECT * FROM alert_rules
+ORDER BY name;
+
+-- name: ListEnabledAlertRules :many
+SELECT * FROM alert_rules
+WHERE enabled = true
+ORDER BY id;
+
+-- name: UpdateAlertRule :one
+UPDATE alert_rules
+SET name = ?,
+    description = ?,
+    expression = ?,
+    for_seconds = ?,
+    severity = ?,
+    provider_ids = ?,
+    enabled = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: UpdateAlertRuleEvaluation :exec
+UPDATE alert_rules
+SET last_evaluated_at = ?,
+    last_error = ?
+WHERE id = ?;
+
+-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules
+WHERE id = ?;
+
+-- name: CreateAlert :one
+INSERT INTO alerts (rule_id, labels, state, value, active_at, fired_at)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListActiveAlertsByRule :many
+SELECT * FROM alerts
+WHERE rule_id = ? AND state IN ('pending', 'firing');
+
+-- name: ListActiveAlerts :many
+SELECT a.*, r.name AS rule_name, r.severity AS rule_severity
+FROM alerts a
+JOIN alert_rules r ON r.id = a.rule_id
+WHERE a.state IN ('pending', 'firing')
+ORDER BY a.active_at DESC;
+
+-- name: ListAlertsByRule :many
+SELECT * FROM alerts
+WHERE rule_id = ?
+ORDER BY active_at DESC
+LIMIT ?;
+
+-- name: UpdateAlertState :exec
+UPDATE alerts
+SET state = ?,
+    value = ?,
+    fired_at = ?,
+    resolved_at = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: DeleteAlert :exec
+DELETE FROM alerts
+WHERE id = ?;
+
+-- name: DeleteAlertsResolvedBefore :execrows
+DELETE FROM alerts
+WHERE state = 'resolved' AND resolved_at < ?;
+
+-- name: CreateServiceAccount :one
+INSERT INTO users (
+    username, password, role, service_account, created_at, updated_at
+) VALUES (
+    ?, '', ?, true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+)
+RETURNING *;
+
+-- name: ListServiceAccounts :many
+SELECT * FROM users
+WHERE service_account = true
+ORDER BY username;
+
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (
+    user_id, name, token_prefix, token_hash, role, expires_at, created_by
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetAPIToken :one
+SELECT * FROM api_tokens
+WHERE id = ? LIMIT 1;
+
+-- name: GetAPITokenByHash :one
+SELECT * FROM api_tokens
+WHERE token_hash = ? LIMIT 1;
+
+-- name: ListAPITokensByUser :many
+SELECT * FROM api_tokens
+WHERE user_id = ?
+ORDER BY created_at DESC;
+
+-- name: RevokeAPIToken :exec
+UPDATE api_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL;
+
+-- name: UpdateAPITokenLastUsed :exec
+UPDATE api_tokens
+SET last_used_at = CURRENT_TIMESTAMP,
+    last_used_ip = ?
+WHERE id = ?;
+
+-- name: GetUserByProvider :one
+SELECT * FROM users
+WHERE provider = ? AND provider_id = ? LIMIT 1;
+
+-- name: CreateOIDCUser :one
+INSERT INTO users (
+    username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at
+) VALUES (
+    ?, '', ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+)
+RETURNING *;
+
+-- name: UpdateOIDCUser :one
+UPDATE users
+SET name = ?,
+    email = ?,
+    role = ?,
+    avatar_url = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING *;
+
+-- name: CreateRoleBinding :one
+INSERT INTO role_bindings (
+    user_id, resource_type, resource_id, role, created_by
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+RETURNING *;
+
+-- name: GetRoleBinding :one
+SELECT * FROM role_bindings
+WHERE id = ? LIMIT 1;
+
+-- name: ListRoleBindingsByUser :many
+SELECT * FROM role_bindings
+WHERE user_id = ?
+ORDER BY resource_type, resource_id;
+
+-- name: DeleteRoleBinding :exec
+DELETE FROM role_bindings
+WHERE id = ?;
+
+-- name: ListNetworkIDsByNode :many
+SELECT network_id FROM network_nodes
+WHERE node_id = ?;
+
+-- name: ListOrganizationIDsByKey :many
+SELECT id FROM fabric_organizations
+WHERE sign_key_id = @key_id
+   OR tls_root_key_id = @key_id
+   OR admin_tls_key_id = @key_id
+   OR admin_sign_key_id = @key_id
+   OR client_sign_key_id = @key_id
+   OR crl_key_id = @key_id;
+
+-- name: UpsertUserTOTP :one
+INSERT INTO user_totp (
+    user_id, secret
+) VALUES (
+    ?, ?
+)
+ON CONFLICT(user_id) DO UPDATE SET
+    secret = excluded.secret,
+    enabled = 0,
+    last_used_step = 0,
+    created_at = CURRENT_TIMESTAMP,
+    enabled_at = NULL
+RETURNING *;
+
+-- name: GetUserTOTP :one
+SELECT * FROM user_totp
+WHERE user_id = ? LIMIT 1;
+
+-- name: EnableUserTOTP :exec
+UPDATE user_totp
+SET enabled = 1,
+    last_used_step = ?,
+    enabled_at = CURRENT_TIMESTAMP
+WHERE user_id = ?;
+
+-- name: UpdateUserTOTPLastUsedStep :exec
+UPDATE user_totp
+SET last_used_step = ?
+WHERE user_id = ?;
+
+-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp
+WHERE user_id = ?;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO user_recovery_codes (
+    user_id, code_hash
+) VALUES (
+    ?, ?
+);
+
+-- name: UseRecoveryCode :execrows
+UPDATE user_recovery_codes
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND code_hash = ? AND used_at IS NULL;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*) FROM user_recovery_codes
+WHERE user_id = ? AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodesByUser :exec
+DELETE FROM user_recovery_codes
+WHERE user_id = ?;
+
+-- name: GetLoginFailure :one
+SELECT * FROM login_failures
+WHERE username = ? LIMIT 1;
+
+-- name: RecordLoginFailure :one
+INSERT INTO login_failures (
+    username, failed_attempts, last_failed_at
+) VALUES (
+    @username, 1, CURRENT_TIMESTAMP
+)
+ON CONFLICT(username) DO UPDATE SET
+    failed_attempts = CASE WHEN login_failures.last_failed_at < @window_start THEN 1 ELSE login_failures.failed_attempts + 1 END,
+    last_failed_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: LockLogin :exec
+UPDATE login_failures
+SET failed_attempts = 0,
+    locked_until = ?
+WHERE username = ?;
+
+-- name: DeleteLoginFailure :exec
+DELETE FROM login_failures
+WHERE username = ?;
+
+-- name: UpdateBackupTargetSecrets :exec
+UPDATE backup_targets
+SET secret_key = ?,
+    restic_password = ?,
+    sftp_private_key = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: UpdateNotificationProviderConfig :exec
+UPDATE notification_providers
+SET config = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: UpdateBackupNodeMetadata :exec
+UPDATE backups
+SET node_metadata = ?
+WHERE id = ?;
+
+-- name: UpdateBackupVerification :exec
+UPDATE backups
+SET verification_status = ?,
+    verification_report = ?,
+    verified_at = ?
+WHERE id = ?;
@@ -176,6 +176,18 @@ func (q *Queries) CountNodesByPlatform(ctx context.Context, platform string) (in
 	return count, err
 }
 
+const CountUnusedRecoveryCodes = `-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*) FROM user_recovery_codes
+WHERE user_id = ? AND used_at IS NULL
+`
+
+func (q *Queries) CountUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, CountUnusedRecoveryCodes, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const CountUsers = `-- name: CountUsers :one
 SELECT COUNT(*) FROM users
 `
@@ -187,6 +199,136 @@ func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const CreateAPIToken = `-- name: CreateAPIToken :one
+INSERT INTO api_tokens (
+    user_id, name, token_prefix, token_hash, role, expires_at, created_by
+) VALUES (
+    ?, ?, ?, ?, ?, ?, ?
+)
+RETURNING id, user_id, name, token_prefix, token_hash, role, expires_at, last_used_at, last_used_ip, revoked_at, created_by, created_at
+`
+
+type CreateAPITokenParams struct {
+	UserID      int64         `json:"userId"`
+	Name        string        `json:"name"`
+	TokenPrefix string        `json:"tokenPrefix"`
+	TokenHash   string        `json:"tokenHash"`
+	Role        string        `json:"role"`
+	ExpiresAt   sql.NullTime  `json:"expiresAt"`
+	CreatedBy   sql.NullInt64 `json:"createdBy"`
+}
+
+func (q *Queries) CreateAPIToken(ctx context.Context, arg *CreateAPITokenParams) (*ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, CreateAPIToken,
+		arg.UserID,
+		arg.Name,
+		arg.TokenPrefix,
+		arg.TokenHash,
+		arg.Role,
+		arg.ExpiresAt,
+		arg.CreatedBy,
+	)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenPrefix,
+		&i.TokenHash,
+		&i.Role,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const CreateAlert = `-- name: CreateAlert :one
+INSERT INTO alerts (rule_id, labels, state, value, active_at, fired_at)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING id, rule_id, labels, state, value, active_at, fired_at, resolved_at, updated_at
+`
+
+type CreateAlertParams struct {
+	RuleID   int64        `json:"ruleId"`
+	Labels   string       `json:"labels"`
+	State    string       `json:"state"`
+	Value    float64      `json:"value"`
+	ActiveAt time.Time    `json:"activeAt"`
+	FiredAt  sql.NullTime `json:"firedAt"`
+}
+
+func (q *Queries) CreateAlert(ctx context.Context, arg *CreateAlertParams) (*Alert, error) {
+	row := q.db.QueryRowContext(ctx, CreateAlert,
+		arg.RuleID,
+		arg.Labels,
+		arg.State,
+		arg.Value,
+		arg.ActiveAt,
+		arg.FiredAt,
+	)
+	var i Alert
+	err := row.Scan(
+		&i.ID,
+		&i.RuleID,
+		&i.Labels,
+		&i.State,
+		&i.Value,
+		&i.ActiveAt,
+		&i.FiredAt,
+		&i.ResolvedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const CreateAlertRule = `-- name: CreateAlertRule :one
+INSERT INTO alert_rules (name, description, expression, for_seconds, severity, provider_ids, enabled)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING id, name, description, expression, for_seconds, severity, provider_ids, enabled, last_evaluated_at, last_error, created_at, updated_at
+`
+
+type CreateAlertRuleParams struct {
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	Expression  string         `json:"expression"`
+	ForSeconds  int64          `json:"forSeconds"`
+	Severity    string         `json:"severity"`
+	ProviderIds string         `json:"providerIds"`
+	Enabled     bool           `json:"enabled"`
+}
+
+func (q *Queries) CreateAlertRule(ctx context.Context, arg *CreateAlertRuleParams) (*AlertRule, error) {
+	row := q.db.QueryRowContext(ctx, CreateAlertRule,
+		arg.Name,
+		arg.Description,
+		arg.Expression,
+		arg.ForSeconds,
+		arg.Severity,
+		arg.ProviderIds,
+		arg.Enabled,
+	)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Expression,
+		&i.ForSeconds,
+		&i.Severity,
+		&i.ProviderIds,
+		&i.Enabled,
+		&i.LastEvaluatedAt,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
 const CreateAuditLog = `-- name: CreateAuditLog :one
 INSERT INTO audit_logs (
     timestamp,
@@ -267,7 +409,7 @@ INSERT INTO backups (
     ?,
     ?,
     CURRENT_TIMESTAMP
-) RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent
+) RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at
 `
 
 type CreateBackupParams struct {
@@ -296,6 +438,11 @@ func (q *Queries) CreateBackup(ctx context.Context, arg *CreateBackupParams) (*B
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.NotificationSent,
+		&i.SnapshotID,
+		&i.NodeMetadata,
+		&i.VerificationStatus,
+		&i.VerificationReport,
+		&i.VerifiedAt,
 	)
 	return &i, err
 }
@@ -308,9 +455,29 @@ INSERT INTO backup_schedules (
     target_id,
     retention_days,
     enabled,
+    keep_last,
+    keep_daily,
+    keep_weekly,
+    keep_monthly,
+    scope,
+    node_ids,
+    network_id,
+    consistency_strategy,
+    stop_order,
+    verify_cron_expression,
     created_at,
     updated_at
 ) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
     ?,
     ?,
     ?,
@@ -319,16 +486,26 @@ INSERT INTO backup_schedules (
     ?,
     CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP
-) RETURNING id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at
+) RETURNING id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at, keep_last, keep_daily, keep_weekly, keep_monthly, scope, node_ids, network_id, consistency_strategy, stop_order, verify_cron_expression
 `
 
 type CreateBackupScheduleParams struct {
-	Name           string         `json:"name"`
-	Description    sql.NullString `json:"description"`
-	CronExpression string         `json:"cronExpression"`
-	TargetID       int64          `json:"targetId"`
-	RetentionDays  int64          `json:"retentionDays"`
-	Enabled        bool           `json:"enabled"`
+	Name                 string         `json:"name"`
+	Description          sql.NullString `json:"description"`
+	CronExpression       string         `json:"cronExpression"`
+	TargetID             int64          `json:"targetId"`
+	RetentionDays        int64          `json:"retentionDays"`
+	Enabled              bool           `json:"enabled"`
+	KeepLast             int64          `json:"keepLast"`
+	KeepDaily            int64          `json:"keepDaily"`
+	KeepWeekly           int64          `json:"keepWeekly"`
+	KeepMonthly          int64          `json:"keepMonthly"`
+	Scope                string         `json:"scope"`
+	NodeIds              sql.NullString `json:"nodeIds"`
+	NetworkID            sql.NullInt64  `json:"networkId"`
+	ConsistencyStrategy  string         `json:"consistencyStrategy"`
+	StopOrder            sql.NullString `json:"stopOrder"`
+	VerifyCronExpression sql.NullString `json:"verifyCronExpression"`
 }
 
 func (q *Queries) CreateBackupSchedule(ctx context.Context, arg *CreateBackupScheduleParams) (*BackupSchedule, error) {
@@ -339,6 +516,16 @@ func (q *Queries) CreateBackupSchedule(ctx context.Context, arg *CreateBackupSch
 		arg.TargetID,
 		arg.RetentionDays,
 		arg.Enabled,
+		arg.KeepLast,
+		arg.KeepDaily,
+		arg.KeepWeekly,
+		arg.KeepMonthly,
+		arg.Scope,
+		arg.NodeIds,
+		arg.NetworkID,
+		arg.ConsistencyStrategy,
+		arg.StopOrder,
+		arg.VerifyCronExpression,
 	)
 	var i BackupSchedule
 	err := row.Scan(
@@ -353,6 +540,16 @@ func (q *Queries) CreateBackupSchedule(ctx context.Context, arg *CreateBackupSch
 		&i.UpdatedAt,
 		&i.LastRunAt,
 		&i.NextRunAt,
+		&i.KeepLast,
+		&i.KeepDaily,
+		&i.KeepWeekly,
+		&i.KeepMonthly,
+		&i.Scope,
+		&i.NodeIds,
+		&i.NetworkID,
+		&i.ConsistencyStrategy,
+		&i.StopOrder,
+		&i.VerifyCronExpression,
 	)
 	return &i, err
 }
@@ -369,6 +566,14 @@ INSERT INTO backup_targets (
     secret_key,
     s3_path_style,
     restic_password,
+    local_path,
+    sftp_host,
+    sftp_port,
+    sftp_username,
+    sftp_private_key,
+    sftp_path,
+    sftp_host_key,
+    engine,
     created_at,
     updated_at
 ) VALUES (
@@ -382,9 +587,17 @@ INSERT INTO backup_targets (
     ?,
     ?,
     ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
     CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP
-) RETURNING id, name, bucket_name, region, endpoint, bucket_path, access_key_id, secret_key, s3_path_style, restic_password, type, created_at, updated_at
+) RETURNING id, name, bucket_name, region, endpoint, bucket_path, access_key_id, secret_key, s3_path_style, restic_password, type, created_at, updated_at, local_path, sftp_host, sftp_port, sftp_username, sftp_private_key, sftp_path, engine, sftp_host_key
 `
 
 type CreateBackupTargetParams struct {
@@ -398,6 +611,14 @@ type CreateBackupTargetParams struct {
 	SecretKey      sql.NullString `json:"secretKey"`
 	S3PathStyle    sql.NullBool   `json:"s3PathStyle"`
 	ResticPassword sql.NullString `json:"resticPassword"`
+	LocalPath      sql.NullString `json:"localPath"`
+	SftpHost       sql.NullString `json:"sftpHost"`
+	SftpPort       sql.NullInt64  `json:"sftpPort"`
+	SftpUsername   sql.NullString `json:"sftpUsername"`
+	SftpPrivateKey sql.NullString `json:"sftpPrivateKey"`
+	SftpPath       sql.NullString `json:"sftpPath"`
+	SftpHostKey    sql.NullString `json:"sftpHostKey"`
+	Engine         string         `json:"engine"`
 }
 
 func (q *Queries) CreateBackupTarget(ctx context.Context, arg *CreateBackupTargetParams) (*BackupTarget, error) {
@@ -412,6 +633,14 @@ func (q *Queries) CreateBackupTarget(ctx context.Context, arg *CreateBackupTarge
 		arg.SecretKey,
 		arg.S3PathStyle,
 		arg.ResticPassword,
+		arg.LocalPath,
+		arg.SftpHost,
+		arg.SftpPort,
+		arg.SftpUsername,
+		arg.SftpPrivateKey,
+		arg.SftpPath,
+		arg.SftpHostKey,
+		arg.Engine,
 	)
 	var i BackupTarget
 	err := row.Scan(
@@ -428,10 +657,81 @@ func (q *Queries) CreateBackupTarget(ctx context.Context, arg *CreateBackupTarge
 		&i.Type,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LocalPath,
+		&i.SftpHost,
+		&i.SftpPort,
+		&i.SftpUsername,
+		&i.SftpPrivateKey,
+		&i.SftpPath,
+		&i.Engine,
+		&i.SftpHostKey,
+	)
+	return &i, err
+}
+
+const CreateBesuValidatorChange = `-- name: CreateBesuValidatorChange :one
+INSERT INTO besu_validator_changes (network_id, action, address, key_id, votes_required, created_by)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING id, network_id, action, address, key_id, status, votes, votes_required, error_message, created_by, created_at, updated_at, applied_at
+`
+
+type CreateBesuValidatorChangeParams struct {
+	NetworkID     int64          `json:"networkId"`
+	Action        string         `json:"action"`
+	Address       string         `json:"address"`
+	KeyID         sql.NullInt64  `json:"keyId"`
+	VotesRequired int64          `json:"votesRequired"`
+	CreatedBy     sql.NullString `json:"createdBy"`
+}
+
+func (q *Queries) CreateBesuValidatorChange(ctx context.Context, arg *CreateBesuValidatorChangeParams) (*BesuValidatorChange, error) {
+	row := q.db.QueryRowContext(ctx, CreateBesuValidatorChange,
+		arg.NetworkID,
+		arg.Action,
+		arg.Address,
+		arg.KeyID,
+		arg.VotesRequired,
+		arg.CreatedBy,
+	)
+	var i BesuValidatorChange
+	err := row.Scan(
+		&i.ID,
+		&i.NetworkID,
+		&i.Action,
+		&i.Address,
+		&i.KeyID,
+		&i.Status,
+		&i.Votes,
+		&i.VotesRequired,
+		&i.ErrorMessage,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AppliedAt,
 	)
 	return &i, err
 }
 
+const CreateCertificateExpiryAlert = `-- name: CreateCertificateExpiryAlert :execrows
+INSERT INTO certificate_expiry_alerts (subject, expires_at, threshold_days)
+VALUES (?, ?, ?)
+ON CONFLICT(subject, expires_at, threshold_days) DO NOTHING
+`
+
+type CreateCertificateExpiryAlertParams struct {
+	Subject       string    `json:"subject"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+	ThresholdDays int64     `json:"thresholdDays"`
+}
+
+func (q *Queries) CreateCertificateExpiryAlert(ctx context.Context, arg *CreateCertificateExpiryAlertParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, CreateCertificateExpiryAlert, arg.Subject, arg.ExpiresAt, arg.ThresholdDays)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const CreateChaincode = `-- name: CreateChaincode :one
 INSERT INTO fabric_chaincodes (name, network_id)
 VALUES (?, ?)
@@ -925,6 +1225,43 @@ func (q *Queries) CreateNode(ctx context.Context, arg *CreateNodeParams) (*Node,
 	return &i, err
 }
 
+const CreateNodeCheck = `-- name: CreateNodeCheck :one
+INSERT INTO node_checks (node_id, status, response_time_ms, error_message, failure_count, checked_at)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING id, node_id, status, response_time_ms, error_message, failure_count, checked_at
+`
+
+type CreateNodeCheckParams struct {
+	NodeID         int64          `json:"nodeId"`
+	Status         string         `json:"status"`
+	ResponseTimeMs int64          `json:"responseTimeMs"`
+	ErrorMessage   sql.NullString `json:"errorMessage"`
+	FailureCount   int64          `json:"failureCount"`
+	CheckedAt      time.Time      `json:"checkedAt"`
+}
+
+func (q *Queries) CreateNodeCheck(ctx context.Context, arg *CreateNodeCheckParams) (*NodeCheck, error) {
+	row := q.db.QueryRowContext(ctx, CreateNodeCheck,
+		arg.NodeID,
+		arg.Status,
+		arg.ResponseTimeMs,
+		arg.ErrorMessage,
+		arg.FailureCount,
+		arg.CheckedAt,
+	)
+	var i NodeCheck
+	err := row.Scan(
+		&i.ID,
+		&i.NodeID,
+		&i.Status,
+		&i.ResponseTimeMs,
+		&i.ErrorMessage,
+		&i.FailureCount,
+		&i.CheckedAt,
+	)
+	return &i, err
+}
+
 const CreateNodeEvent = `-- name: CreateNodeEvent :one
 INSERT INTO node_events (
     node_id,
@@ -967,6 +1304,31 @@ func (q *Queries) CreateNodeEvent(ctx context.Context, arg *CreateNodeEventParam
 	return &i, err
 }
 
+const CreateNodeIncident = `-- name: CreateNodeIncident :one
+INSERT INTO node_incidents (node_id, started_at, error_message)
+VALUES (?, ?, ?)
+RETURNING id, node_id, started_at, resolved_at, error_message
+`
+
+type CreateNodeIncidentParams struct {
+	NodeID       int64          `json:"nodeId"`
+	StartedAt    time.Time      `json:"startedAt"`
+	ErrorMessage sql.NullString `json:"errorMessage"`
+}
+
+func (q *Queries) CreateNodeIncident(ctx context.Context, arg *CreateNodeIncidentParams) (*NodeIncident, error) {
+	row := q.db.QueryRowContext(ctx, CreateNodeIncident, arg.NodeID, arg.StartedAt, arg.ErrorMessage)
+	var i NodeIncident
+	err := row.Scan(
+		&i.ID,
+		&i.NodeID,
+		&i.StartedAt,
+		&i.ResolvedAt,
+		&i.ErrorMessage,
+	)
+	return &i, err
+}
+
 const CreateNotificationProvider = `-- name: CreateNotificationProvider :one
 INSERT INTO notification_providers (
     type,
@@ -977,6 +1339,7 @@ INSERT INTO notification_providers (
     notify_backup_success,
     notify_backup_failure,
     notify_s3_connection_issue,
+    notify_certificate_expiry,
     created_at,
     updated_at
 ) VALUES (
@@ -988,9 +1351,10 @@ INSERT INTO notification_providers (
     ?,
     ?,
     ?,
+    ?,
     CURRENT_TIMESTAMP,
     CURRENT_TIMESTAMP
-) RETURNING id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message
+) RETURNING id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message, notify_certificate_expiry
 `
 
 type CreateNotificationProviderParams struct {
@@ -1002,6 +1366,7 @@ type CreateNotificationProviderParams struct {
 	NotifyBackupSuccess     bool   `json:"notifyBackupSuccess"`
 	NotifyBackupFailure     bool   `json:"notifyBackupFailure"`
 	NotifyS3ConnectionIssue bool   `json:"notifyS3ConnectionIssue"`
+	NotifyCertificateExpiry bool   `json:"notifyCertificateExpiry"`
 }
 
 func (q *Queries) CreateNotificationProvider(ctx context.Context, arg *CreateNotificationProviderParams) (*NotificationProvider, error) {
@@ -1014,6 +1379,7 @@ func (q *Queries) CreateNotificationProvider(ctx context.Context, arg *CreateNot
 		arg.NotifyBackupSuccess,
 		arg.NotifyBackupFailure,
 		arg.NotifyS3ConnectionIssue,
+		arg.NotifyCertificateExpiry,
 	)
 	var i NotificationProvider
 	err := row.Scan(
@@ -1032,6 +1398,55 @@ func (q *Queries) CreateNotificationProvider(ctx context.Context, arg *CreateNot
 		&i.LastTestAt,
 		&i.LastTestStatus,
 		&i.LastTestMessage,
+		&i.NotifyCertificateExpiry,
+	)
+	return &i, err
+}
+
+const CreateOIDCUser = `-- name: CreateOIDCUser :one
+INSERT INTO users (
+    username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at
+) VALUES (
+    ?, '', ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+)
+RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account
+`
+
+type CreateOIDCUserParams struct {
+	Username   string         `json:"username"`
+	Name       sql.NullString `json:"name"`
+	Email      sql.NullString `json:"email"`
+	Role       sql.NullString `json:"role"`
+	Provider   sql.NullString `json:"provider"`
+	ProviderID sql.NullString `json:"providerId"`
+	AvatarUrl  sql.NullString `json:"avatarUrl"`
+}
+
+func (q *Queries) CreateOIDCUser(ctx context.Context, arg *CreateOIDCUserParams) (*User, error) {
+	row := q.db.QueryRowContext(ctx, CreateOIDCUser,
+		arg.Username,
+		arg.Name,
+		arg.Email,
+		arg.Role,
+		arg.Provider,
+		arg.ProviderID,
+		arg.AvatarUrl,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Role,
+		&i.Provider,
+		&i.ProviderID,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.LastLoginAt,
+		&i.UpdatedAt,
+		&i.ServiceAccount,
 	)
 	return &i, err
 }
@@ -1081,86 +1496,125 @@ func (q *Queries) CreatePlugin(ctx context.Context, arg *CreatePluginParams) (*P
 	return &i, err
 }
 
-const CreateSession = `-- name: CreateSession :one
-INSERT INTO sessions (
-  token,
-  user_id,
-  expires_at,
-  session_id
+const CreateProposal = `-- name: CreateProposal :one
+INSERT INTO proposals (
+    id, network_id, channel_name, status, operations, preview_json, config_update, created_by
 ) VALUES (
-  ?, ?, ?, ?
-)
-RETURNING id, session_id, user_id, token, ip_address, user_agent, created_at, updated_at, expires_at, last_activity_at
+    ?, ?, ?, ?, ?, ?, ?, ?
+) RETURNING id, network_id, channel_name, status, operations, preview_json, config_update, tx_response, created_by, created_at, updated_at
 `
 
-type CreateSessionParams struct {
-	Token     string    `json:"token"`
-	UserID    int64     `json:"userId"`
-	ExpiresAt time.Time `json:"expiresAt"`
-	SessionID string    `json:"sessionId"`
+type CreateProposalParams struct {
+	ID           string         `json:"id"`
+	NetworkID    int64          `json:"networkId"`
+	ChannelName  string         `json:"channelName"`
+	Status       string         `json:"status"`
+	Operations   string         `json:"operations"`
+	PreviewJson  sql.NullString `json:"previewJson"`
+	ConfigUpdate []byte         `json:"configUpdate"`
+	CreatedBy    string         `json:"createdBy"`
 }
 
-func (q *Queries) CreateSession(ctx context.Context, arg *CreateSessionParams) (*Session, error) {
-	row := q.db.QueryRowContext(ctx, CreateSession,
-		arg.Token,
-		arg.UserID,
-		arg.ExpiresAt,
-		arg.SessionID,
+func (q *Queries) CreateProposal(ctx context.Context, arg *CreateProposalParams) (*Proposal, error) {
+	row := q.db.QueryRowContext(ctx, CreateProposal,
+		arg.ID,
+		arg.NetworkID,
+		arg.ChannelName,
+		arg.Status,
+		arg.Operations,
+		arg.PreviewJson,
+		arg.ConfigUpdate,
+		arg.CreatedBy,
 	)
-	var i Session
+	var i Proposal
 	err := row.Scan(
 		&i.ID,
-		&i.SessionID,
-		&i.UserID,
-		&i.Token,
-		&i.IpAddress,
-		&i.UserAgent,
+		&i.NetworkID,
+		&i.ChannelName,
+		&i.Status,
+		&i.Operations,
+		&i.PreviewJson,
+		&i.ConfigUpdate,
+		&i.TxResponse,
+		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.ExpiresAt,
-		&i.LastActivityAt,
 	)
 	return &i, err
 }
 
-const CreateSetting = `-- name: CreateSetting :one
-INSERT INTO settings (
-    config
+const CreateRecoveryCode = `-- name: CreateRecoveryCode :exec
+INSERT INTO user_recovery_codes (
+    user_id, code_hash
 ) VALUES (
-    ?
+    ?, ?
 )
-RETURNING id, config, created_at, updated_at
 `
 
-func (q *Queries) CreateSetting(ctx context.Context, config string) (*Setting, error) {
-	row := q.db.QueryRowContext(ctx, CreateSetting, config)
-	var i Setting
+type CreateRecoveryCodeParams struct {
+	UserID   int64  `json:"userId"`
+	CodeHash string `json:"codeHash"`
+}
+
+func (q *Queries) CreateRecoveryCode(ctx context.Context, arg *CreateRecoveryCodeParams) error {
+	_, err := q.db.ExecContext(ctx, CreateRecoveryCode, arg.UserID, arg.CodeHash)
+	return err
+}
+
+const CreateRoleBinding = `-- name: CreateRoleBinding :one
+INSERT INTO role_bindings (
+    user_id, resource_type, resource_id, role, created_by
+) VALUES (
+    ?, ?, ?, ?, ?
+)
+RETURNING id, user_id, resource_type, resource_id, role, created_by, created_at
+`
+
+type CreateRoleBindingParams struct {
+	UserID       int64         `json:"userId"`
+	ResourceType string        `json:"resourceType"`
+	ResourceID   int64         `json:"resourceId"`
+	Role         string        `json:"role"`
+	CreatedBy    sql.NullInt64 `json:"createdBy"`
+}
+
+func (q *Queries) CreateRoleBinding(ctx context.Context, arg *CreateRoleBindingParams) (*RoleBinding, error) {
+	row := q.db.QueryRowContext(ctx, CreateRoleBinding,
+		arg.UserID,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.Role,
+		arg.CreatedBy,
+	)
+	var i RoleBinding
 	err := row.Scan(
 		&i.ID,
-		&i.Config,
+		&i.UserID,
+		&i.ResourceType,
+		&i.ResourceID,
+		&i.Role,
+		&i.CreatedBy,
 		&i.CreatedAt,
-		&i.UpdatedAt,
 	)
 	return &i, err
 }
 
-const CreateUser = `-- name: CreateUser :one
+const CreateServiceAccount = `-- name: CreateServiceAccount :one
 INSERT INTO users (
-    username, password, role, created_at, last_login_at, updated_at
+    username, password, role, service_account, created_at, updated_at
 ) VALUES (
-    ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+    ?, '', ?, true, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
 )
-RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at
+RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account
 `
 
-type CreateUserParams struct {
+type CreateServiceAccountParams struct {
 	Username string         `json:"username"`
-	Password string         `json:"password"`
 	Role     sql.NullString `json:"role"`
 }
 
-func (q *Queries) CreateUser(ctx context.Context, arg *CreateUserParams) (*User, error) {
-	row := q.db.QueryRowContext(ctx, CreateUser, arg.Username, arg.Password, arg.Role)
+func (q *Queries) CreateServiceAccount(ctx context.Context, arg *CreateServiceAccountParams) (*User, error) {
+	row := q.db.QueryRowContext(ctx, CreateServiceAccount, arg.Username, arg.Role)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -1175,13 +1629,146 @@ func (q *Queries) CreateUser(ctx context.Context, arg *CreateUserParams) (*User,
 		&i.CreatedAt,
 		&i.LastLoginAt,
 		&i.UpdatedAt,
+		&i.ServiceAccount,
 	)
 	return &i, err
 }
 
-const DeleteBackup = `-- name: DeleteBackup :exec
-DELETE FROM backups WHERE id = ?
-`
+const CreateSession = `-- name: CreateSession :one
+INSERT INTO sessions (
+  token,
+  user_id,
+  expires_at,
+  session_id
+) VALUES (
+  ?, ?, ?, ?
+)
+RETURNING id, session_id, user_id, token, ip_address, user_agent, created_at, updated_at, expires_at, last_activity_at
+`
+
+type CreateSessionParams struct {
+	Token     string    `json:"token"`
+	UserID    int64     `json:"userId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	SessionID string    `json:"sessionId"`
+}
+
+func (q *Queries) CreateSession(ctx context.Context, arg *CreateSessionParams) (*Session, error) {
+	row := q.db.QueryRowContext(ctx, CreateSession,
+		arg.Token,
+		arg.UserID,
+		arg.ExpiresAt,
+		arg.SessionID,
+	)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.SessionID,
+		&i.UserID,
+		&i.Token,
+		&i.IpAddress,
+		&i.UserAgent,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ExpiresAt,
+		&i.LastActivityAt,
+	)
+	return &i, err
+}
+
+const CreateSetting = `-- name: CreateSetting :one
+INSERT INTO settings (
+    config
+) VALUES (
+    ?
+)
+RETURNING id, config, created_at, updated_at
+`
+
+func (q *Queries) CreateSetting(ctx context.Context, config string) (*Setting, error) {
+	row := q.db.QueryRowContext(ctx, CreateSetting, config)
+	var i Setting
+	err := row.Scan(
+		&i.ID,
+		&i.Config,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const CreateUser = `-- name: CreateUser :one
+INSERT INTO users (
+    username, password, role, created_at, last_login_at, updated_at
+) VALUES (
+    ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+)
+RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account
+`
+
+type CreateUserParams struct {
+	Username string         `json:"username"`
+	Password string         `json:"password"`
+	Role     sql.NullString `json:"role"`
+}
+
+func (q *Queries) CreateUser(ctx context.Context, arg *CreateUserParams) (*User, error) {
+	row := q.db.QueryRowContext(ctx, CreateUser, arg.Username, arg.Password, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Role,
+		&i.Provider,
+		&i.ProviderID,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.LastLoginAt,
+		&i.UpdatedAt,
+		&i.ServiceAccount,
+	)
+	return &i, err
+}
+
+const DeleteAlert = `-- name: DeleteAlert :exec
+DELETE FROM alerts
+WHERE id = ?
+`
+
+func (q *Queries) DeleteAlert(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, DeleteAlert, id)
+	return err
+}
+
+const DeleteAlertRule = `-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules
+WHERE id = ?
+`
+
+func (q *Queries) DeleteAlertRule(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, DeleteAlertRule, id)
+	return err
+}
+
+const DeleteAlertsResolvedBefore = `-- name: DeleteAlertsResolvedBefore :execrows
+DELETE FROM alerts
+WHERE state = 'resolved' AND resolved_at < ?
+`
+
+func (q *Queries) DeleteAlertsResolvedBefore(ctx context.Context, resolvedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, DeleteAlertsResolvedBefore, resolvedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const DeleteBackup = `-- name: DeleteBackup :exec
+DELETE FROM backups WHERE id = ?
+`
 
 func (q *Queries) DeleteBackup(ctx context.Context, id int64) error {
 	_, err := q.db.ExecContext(ctx, DeleteBackup, id)
@@ -1280,6 +1867,16 @@ func (q *Queries) DeleteKeyProvider(ctx context.Context, id int64) error {
 	return err
 }
 
+const DeleteLoginFailure = `-- name: DeleteLoginFailure :exec
+DELETE FROM login_failures
+WHERE username = ?
+`
+
+func (q *Queries) DeleteLoginFailure(ctx context.Context, username string) error {
+	_, err := q.db.ExecContext(ctx, DeleteLoginFailure, username)
+	return err
+}
+
 const DeleteNetwork = `-- name: DeleteNetwork :exec
 DELETE FROM networks
 WHERE id = ?
@@ -1314,6 +1911,45 @@ func (q *Queries) DeleteNode(ctx context.Context, id int64) error {
 	return err
 }
 
+const DeleteNodeCheckRollupsBefore = `-- name: DeleteNodeCheckRollupsBefore :execrows
+DELETE FROM node_check_rollups
+WHERE bucket_start < ?
+`
+
+func (q *Queries) DeleteNodeCheckRollupsBefore(ctx context.Context, bucketStart time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, DeleteNodeCheckRollupsBefore, bucketStart)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const DeleteNodeChecksBefore = `-- name: DeleteNodeChecksBefore :execrows
+DELETE FROM node_checks
+WHERE checked_at < ?
+`
+
+func (q *Queries) DeleteNodeChecksBefore(ctx context.Context, checkedAt time.Time) (int64, error) {
+	result, err := q.db.ExecContext(ctx, DeleteNodeChecksBefore, checkedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const DeleteNodeIncidentsBefore = `-- name: DeleteNodeIncidentsBefore :execrows
+DELETE FROM node_incidents
+WHERE resolved_at IS NOT NULL AND resolved_at < ?
+`
+
+func (q *Queries) DeleteNodeIncidentsBefore(ctx context.Context, resolvedAt sql.NullTime) (int64, error) {
+	result, err := q.db.ExecContext(ctx, DeleteNodeIncidentsBefore, resolvedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const DeleteNotificationProvider = `-- name: DeleteNotificationProvider :exec
 DELETE FROM notification_providers
 WHERE id = ?
@@ -1349,6 +1985,16 @@ func (q *Queries) DeletePlugin(ctx context.Context, name string) error {
 	return err
 }
 
+const DeleteRecoveryCodesByUser = `-- name: DeleteRecoveryCodesByUser :exec
+DELETE FROM user_recovery_codes
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteRecoveryCodesByUser(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, DeleteRecoveryCodesByUser, userID)
+	return err
+}
+
 const DeleteRevokedCertificate = `-- name: DeleteRevokedCertificate :exec
 DELETE FROM fabric_revoked_certificates
 WHERE fabric_organization_id = ? AND serial_number = ?
@@ -1364,6 +2010,16 @@ func (q *Queries) DeleteRevokedCertificate(ctx context.Context, arg *DeleteRevok
 	return err
 }
 
+const DeleteRoleBinding = `-- name: DeleteRoleBinding :exec
+DELETE FROM role_bindings
+WHERE id = ?
+`
+
+func (q *Queries) DeleteRoleBinding(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, DeleteRoleBinding, id)
+	return err
+}
+
 const DeleteSession = `-- name: DeleteSession :exec
 DELETE FROM sessions WHERE token = ?
 `
@@ -1402,12 +2058,22 @@ func (q *Queries) DeleteUserSessions(ctx context.Context, userID int64) error {
 	return err
 }
 
+const DeleteUserTOTP = `-- name: DeleteUserTOTP :exec
+DELETE FROM user_totp
+WHERE user_id = ?
+`
+
+func (q *Queries) DeleteUserTOTP(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, DeleteUserTOTP, userID)
+	return err
+}
+
 const DisableBackupSchedule = `-- name: DisableBackupSchedule :one
 UPDATE backup_schedules
 SET enabled = false,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at
+RETURNING id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at, keep_last, keep_daily, keep_weekly, keep_monthly, scope, node_ids, network_id, consistency_strategy, stop_order, verify_cron_expression
 `
 
 func (q *Queries) DisableBackupSchedule(ctx context.Context, id int64) (*BackupSchedule, error) {
@@ -1425,6 +2091,16 @@ func (q *Queries) DisableBackupSchedule(ctx context.Context, id int64) (*BackupS
 		&i.UpdatedAt,
 		&i.LastRunAt,
 		&i.NextRunAt,
+		&i.KeepLast,
+		&i.KeepDaily,
+		&i.KeepWeekly,
+		&i.KeepMonthly,
+		&i.Scope,
+		&i.NodeIds,
+		&i.NetworkID,
+		&i.ConsistencyStrategy,
+		&i.StopOrder,
+		&i.VerifyCronExpression,
 	)
 	return &i, err
 }
@@ -1434,7 +2110,7 @@ UPDATE backup_schedules
 SET enabled = true,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at
+RETURNING id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at, keep_last, keep_daily, keep_weekly, keep_monthly, scope, node_ids, network_id, consistency_strategy, stop_order, verify_cron_expression
 `
 
 func (q *Queries) EnableBackupSchedule(ctx context.Context, id int64) (*BackupSchedule, error) {
@@ -1452,6 +2128,109 @@ func (q *Queries) EnableBackupSchedule(ctx context.Context, id int64) (*BackupSc
 		&i.UpdatedAt,
 		&i.LastRunAt,
 		&i.NextRunAt,
+		&i.KeepLast,
+		&i.KeepDaily,
+		&i.KeepWeekly,
+		&i.KeepMonthly,
+		&i.Scope,
+		&i.NodeIds,
+		&i.NetworkID,
+		&i.ConsistencyStrategy,
+		&i.StopOrder,
+		&i.VerifyCronExpression,
+	)
+	return &i, err
+}
+
+const EnableUserTOTP = `-- name: EnableUserTOTP :exec
+UPDATE user_totp
+SET enabled = 1,
+    last_used_step = ?,
+    enabled_at = CURRENT_TIMESTAMP
+WHERE user_id = ?
+`
+
+type EnableUserTOTPParams struct {
+	LastUsedStep int64 `json:"lastUsedStep"`
+	UserID       int64 `json:"userId"`
+}
+
+func (q *Queries) EnableUserTOTP(ctx context.Context, arg *EnableUserTOTPParams) error {
+	_, err := q.db.ExecContext(ctx, EnableUserTOTP, arg.LastUsedStep, arg.UserID)
+	return err
+}
+
+const GetAPIToken = `-- name: GetAPIToken :one
+SELECT id, user_id, name, token_prefix, token_hash, role, expires_at, last_used_at, last_used_ip, revoked_at, created_by, created_at FROM api_tokens
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetAPIToken(ctx context.Context, id int64) (*ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, GetAPIToken, id)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenPrefix,
+		&i.TokenHash,
+		&i.Role,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const GetAPITokenByHash = `-- name: GetAPITokenByHash :one
+SELECT id, user_id, name, token_prefix, token_hash, role, expires_at, last_used_at, last_used_ip, revoked_at, created_by, created_at FROM api_tokens
+WHERE token_hash = ? LIMIT 1
+`
+
+func (q *Queries) GetAPITokenByHash(ctx context.Context, tokenHash string) (*ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, GetAPITokenByHash, tokenHash)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.TokenPrefix,
+		&i.TokenHash,
+		&i.Role,
+		&i.ExpiresAt,
+		&i.LastUsedAt,
+		&i.LastUsedIp,
+		&i.RevokedAt,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
+const GetAlertRule = `-- name: GetAlertRule :one
+SELECT id, name, description, expression, for_seconds, severity, provider_ids, enabled, last_evaluated_at, last_error, created_at, updated_at FROM alert_rules
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetAlertRule(ctx context.Context, id int64) (*AlertRule, error) {
+	row := q.db.QueryRowContext(ctx, GetAlertRule, id)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Expression,
+		&i.ForSeconds,
+		&i.Severity,
+		&i.ProviderIds,
+		&i.Enabled,
+		&i.LastEvaluatedAt,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 	)
 	return &i, err
 }
@@ -1619,7 +2398,7 @@ func (q *Queries) GetAuditLog(ctx context.Context, id int64) (*AuditLog, error)
 }
 
 const GetBackup = `-- name: GetBackup :one
-SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent FROM backups
+SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at FROM backups
 WHERE id = ? LIMIT 1
 `
 
@@ -1637,12 +2416,17 @@ func (q *Queries) GetBackup(ctx context.Context, id int64) (*Backup, error) {
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.NotificationSent,
+		&i.SnapshotID,
+		&i.NodeMetadata,
+		&i.VerificationStatus,
+		&i.VerificationReport,
+		&i.VerifiedAt,
 	)
 	return &i, err
 }
 
 const GetBackupSchedule = `-- name: GetBackupSchedule :one
-SELECT id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at FROM backup_schedules
+SELECT id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at, keep_last, keep_daily, keep_weekly, keep_monthly, scope, node_ids, network_id, consistency_strategy, stop_order, verify_cron_expression FROM backup_schedules
 WHERE id = ? LIMIT 1
 `
 
@@ -1661,12 +2445,22 @@ func (q *Queries) GetBackupSchedule(ctx context.Context, id int64) (*BackupSched
 		&i.UpdatedAt,
 		&i.LastRunAt,
 		&i.NextRunAt,
+		&i.KeepLast,
+		&i.KeepDaily,
+		&i.KeepWeekly,
+		&i.KeepMonthly,
+		&i.Scope,
+		&i.NodeIds,
+		&i.NetworkID,
+		&i.ConsistencyStrategy,
+		&i.StopOrder,
+		&i.VerifyCronExpression,
 	)
 	return &i, err
 }
 
 const GetBackupTarget = `-- name: GetBackupTarget :one
-SELECT id, name, bucket_name, region, endpoint, bucket_path, access_key_id, secret_key, s3_path_style, restic_password, type, created_at, updated_at FROM backup_targets
+SELECT id, name, bucket_name, region, endpoint, bucket_path, access_key_id, secret_key, s3_path_style, restic_password, type, created_at, updated_at, local_path, sftp_host, sftp_port, sftp_username, sftp_private_key, sftp_path, engine, sftp_host_key FROM backup_targets
 WHERE id = ? LIMIT 1
 `
 
@@ -1687,12 +2481,20 @@ func (q *Queries) GetBackupTarget(ctx context.Context, id int64) (*BackupTarget,
 		&i.Type,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LocalPath,
+		&i.SftpHost,
+		&i.SftpPort,
+		&i.SftpUsername,
+		&i.SftpPrivateKey,
+		&i.SftpPath,
+		&i.Engine,
+		&i.SftpHostKey,
 	)
 	return &i, err
 }
 
 const GetBackupsByDateRange = `-- name: GetBackupsByDateRange :many
-SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent FROM backups
+SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at FROM backups
 WHERE created_at BETWEEN ? AND ?
 ORDER BY created_at DESC
 `
@@ -1722,6 +2524,11 @@ func (q *Queries) GetBackupsByDateRange(ctx context.Context, arg *GetBackupsByDa
 			&i.ErrorMessage,
 			&i.CreatedAt,
 			&i.NotificationSent,
+			&i.SnapshotID,
+			&i.NodeMetadata,
+			&i.VerificationStatus,
+			&i.VerificationReport,
+			&i.VerifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1737,7 +2544,7 @@ func (q *Queries) GetBackupsByDateRange(ctx context.Context, arg *GetBackupsByDa
 }
 
 const GetBackupsByScheduleAndStatus = `-- name: GetBackupsByScheduleAndStatus :many
-SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent FROM backups
+SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at FROM backups
 WHERE schedule_id = ? AND status = ?
 ORDER BY created_at DESC
 `
@@ -1767,6 +2574,11 @@ func (q *Queries) GetBackupsByScheduleAndStatus(ctx context.Context, arg *GetBac
 			&i.ErrorMessage,
 			&i.CreatedAt,
 			&i.NotificationSent,
+			&i.SnapshotID,
+			&i.NodeMetadata,
+			&i.VerificationStatus,
+			&i.VerificationReport,
+			&i.VerifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1782,7 +2594,7 @@ func (q *Queries) GetBackupsByScheduleAndStatus(ctx context.Context, arg *GetBac
 }
 
 const GetBackupsByStatus = `-- name: GetBackupsByStatus :many
-SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent FROM backups
+SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at FROM backups
 WHERE status = ?
 ORDER BY created_at DESC
 `
@@ -1807,6 +2619,11 @@ func (q *Queries) GetBackupsByStatus(ctx context.Context, status string) ([]*Bac
 			&i.ErrorMessage,
 			&i.CreatedAt,
 			&i.NotificationSent,
+			&i.SnapshotID,
+			&i.NodeMetadata,
+			&i.VerificationStatus,
+			&i.VerificationReport,
+			&i.VerifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1821,6 +2638,32 @@ func (q *Queries) GetBackupsByStatus(ctx context.Context, status string) ([]*Bac
 	return items, nil
 }
 
+const GetBesuValidatorChange = `-- name: GetBesuValidatorChange :one
+SELECT id, network_id, action, address, key_id, status, votes, votes_required, error_message, created_by, created_at, updated_at, applied_at FROM besu_validator_changes
+WHERE id = ?
+`
+
+func (q *Queries) GetBesuValidatorChange(ctx context.Context, id int64) (*BesuValidatorChange, error) {
+	row := q.db.QueryRowContext(ctx, GetBesuValidatorChange, id)
+	var i BesuValidatorChange
+	err := row.Scan(
+		&i.ID,
+		&i.NetworkID,
+		&i.Action,
+		&i.Address,
+		&i.KeyID,
+		&i.Status,
+		&i.Votes,
+		&i.VotesRequired,
+		&i.ErrorMessage,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AppliedAt,
+	)
+	return &i, err
+}
+
 const GetChaincode = `-- name: GetChaincode :one
 SELECT fc.id, fc.name, fc.network_id, fc.created_at, n.id as network_id, n.name as network_name, n.platform as network_platform
 FROM fabric_chaincodes fc
@@ -1874,7 +2717,7 @@ func (q *Queries) GetChaincodeDefinition(ctx context.Context, id int64) (*Fabric
 }
 
 const GetDefaultNotificationProvider = `-- name: GetDefaultNotificationProvider :one
-SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message FROM notification_providers
+SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message, notify_certificate_expiry FROM notification_providers
 WHERE is_default = 1 AND type = ?
 LIMIT 1
 `
@@ -1898,18 +2741,20 @@ func (q *Queries) GetDefaultNotificationProvider(ctx context.Context, type_ stri
 		&i.LastTestAt,
 		&i.LastTestStatus,
 		&i.LastTestMessage,
+		&i.NotifyCertificateExpiry,
 	)
 	return &i, err
 }
 
 const GetDefaultNotificationProviderForType = `-- name: GetDefaultNotificationProviderForType :one
-SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message FROM notification_providers
+SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message, notify_certificate_expiry FROM notification_providers
 WHERE is_default = true
   AND (
     (?1 = 'BACKUP_SUCCESS' AND notify_backup_success = true) OR
     (?1 = 'BACKUP_FAILURE' AND notify_backup_failure = true) OR
     (?1 = 'NODE_DOWNTIME' AND notify_node_downtime = true) OR
-    (?1 = 'S3_CONNECTION_ISSUE' AND notify_s3_connection_issue = true)
+    (?1 = 'S3_CONNECTION_ISSUE' AND notify_s3_connection_issue = true) OR
+    (?1 = 'CERTIFICATE_EXPIRING' AND notify_certificate_expiry = true)
   )
 LIMIT 1
 `
@@ -1933,6 +2778,7 @@ func (q *Queries) GetDefaultNotificationProviderForType(ctx context.Context, not
 		&i.LastTestAt,
 		&i.LastTestStatus,
 		&i.LastTestMessage,
+		&i.NotifyCertificateExpiry,
 	)
 	return &i, err
 }
@@ -2578,6 +3424,43 @@ func (q *Queries) GetKeysCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+const GetLatestCompletedBackupBefore = `-- name: GetLatestCompletedBackupBefore :one
+SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at FROM backups
+WHERE target_id = ?
+  AND status = 'COMPLETED'
+  AND started_at <= ?
+ORDER BY started_at DESC
+LIMIT 1
+`
+
+type GetLatestCompletedBackupBeforeParams struct {
+	TargetID  int64     `json:"targetId"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+func (q *Queries) GetLatestCompletedBackupBefore(ctx context.Context, arg *GetLatestCompletedBackupBeforeParams) (*Backup, error) {
+	row := q.db.QueryRowContext(ctx, GetLatestCompletedBackupBefore, arg.TargetID, arg.StartedAt)
+	var i Backup
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduleID,
+		&i.TargetID,
+		&i.Status,
+		&i.SizeBytes,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+		&i.NotificationSent,
+		&i.SnapshotID,
+		&i.NodeMetadata,
+		&i.VerificationStatus,
+		&i.VerificationReport,
+		&i.VerifiedAt,
+	)
+	return &i, err
+}
+
 const GetLatestNodeEvent = `-- name: GetLatestNodeEvent :one
 SELECT id, node_id, event_type, description, data, status, created_at FROM node_events
 WHERE node_id = ?
@@ -2600,14 +3483,31 @@ func (q *Queries) GetLatestNodeEvent(ctx context.Context, nodeID int64) (*NodeEv
 	return &i, err
 }
 
-const GetNetwork = `-- name: GetNetwork :one
-SELECT id, name, network_id, platform, status, description, config, deployment_config, exposed_ports, domain, created_at, created_by, updated_at, genesis_block_b64, current_config_block_b64 FROM networks
-WHERE id = ? LIMIT 1
+const GetLoginFailure = `-- name: GetLoginFailure :one
+SELECT username, failed_attempts, last_failed_at, locked_until FROM login_failures
+WHERE username = ? LIMIT 1
 `
 
-func (q *Queries) GetNetwork(ctx context.Context, id int64) (*Network, error) {
-	row := q.db.QueryRowContext(ctx, GetNetwork, id)
-	var i Network
+func (q *Queries) GetLoginFailure(ctx context.Context, username string) (*LoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, GetLoginFailure, username)
+	var i LoginFailure
+	err := row.Scan(
+		&i.Username,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return &i, err
+}
+
+const GetNetwork = `-- name: GetNetwork :one
+SELECT id, name, network_id, platform, status, description, config, deployment_config, exposed_ports, domain, created_at, created_by, updated_at, genesis_block_b64, current_config_block_b64 FROM networks
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetNetwork(ctx context.Context, id int64) (*Network, error) {
+	row := q.db.QueryRowContext(ctx, GetNetwork, id)
+	var i Network
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
@@ -2878,6 +3778,18 @@ func (q *Queries) GetNodeBySlug(ctx context.Context, slug string) (*Node, error)
 	return &i, err
 }
 
+const GetNodeCertificateSettings = `-- name: GetNodeCertificateSettings :one
+SELECT node_id, auto_renew, updated_at FROM node_certificate_settings
+WHERE node_id = ?
+`
+
+func (q *Queries) GetNodeCertificateSettings(ctx context.Context, nodeID int64) (*NodeCertificateSetting, error) {
+	row := q.db.QueryRowContext(ctx, GetNodeCertificateSettings, nodeID)
+	var i NodeCertificateSetting
+	err := row.Scan(&i.NodeID, &i.AutoRenew, &i.UpdatedAt)
+	return &i, err
+}
+
 const GetNodeEvent = `-- name: GetNodeEvent :one
 SELECT id, node_id, event_type, description, data, status, created_at FROM node_events
 WHERE id = ? LIMIT 1
@@ -2899,7 +3811,7 @@ func (q *Queries) GetNodeEvent(ctx context.Context, id int64) (*NodeEvent, error
 }
 
 const GetNotificationProvider = `-- name: GetNotificationProvider :one
-SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message FROM notification_providers
+SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message, notify_certificate_expiry FROM notification_providers
 WHERE id = ? LIMIT 1
 `
 
@@ -2922,12 +3834,13 @@ func (q *Queries) GetNotificationProvider(ctx context.Context, id int64) (*Notif
 		&i.LastTestAt,
 		&i.LastTestStatus,
 		&i.LastTestMessage,
+		&i.NotifyCertificateExpiry,
 	)
 	return &i, err
 }
 
 const GetOldestBackupByTarget = `-- name: GetOldestBackupByTarget :one
-SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent FROM backups
+SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at FROM backups
 WHERE target_id = ?
 ORDER BY created_at ASC
 LIMIT 1
@@ -2947,6 +3860,31 @@ func (q *Queries) GetOldestBackupByTarget(ctx context.Context, targetID int64) (
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.NotificationSent,
+		&i.SnapshotID,
+		&i.NodeMetadata,
+		&i.VerificationStatus,
+		&i.VerificationReport,
+		&i.VerifiedAt,
+	)
+	return &i, err
+}
+
+const GetOpenNodeIncident = `-- name: GetOpenNodeIncident :one
+SELECT id, node_id, started_at, resolved_at, error_message FROM node_incidents
+WHERE node_id = ? AND resolved_at IS NULL
+ORDER BY started_at DESC
+LIMIT 1
+`
+
+func (q *Queries) GetOpenNodeIncident(ctx context.Context, nodeID int64) (*NodeIncident, error) {
+	row := q.db.QueryRowContext(ctx, GetOpenNodeIncident, nodeID)
+	var i NodeIncident
+	err := row.Scan(
+		&i.ID,
+		&i.NodeID,
+		&i.StartedAt,
+		&i.ResolvedAt,
+		&i.ErrorMessage,
 	)
 	return &i, err
 }
@@ -3086,8 +4024,31 @@ func (q *Queries) GetPrometheusConfig(ctx context.Context) (*PrometheusConfig, e
 	return &i, err
 }
 
+const GetProposal = `-- name: GetProposal :one
+SELECT id, network_id, channel_name, status, operations, preview_json, config_update, tx_response, created_by, created_at, updated_at FROM proposals WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetProposal(ctx context.Context, id string) (*Proposal, error) {
+	row := q.db.QueryRowContext(ctx, GetProposal, id)
+	var i Proposal
+	err := row.Scan(
+		&i.ID,
+		&i.NetworkID,
+		&i.ChannelName,
+		&i.Status,
+		&i.Operations,
+		&i.PreviewJson,
+		&i.ConfigUpdate,
+		&i.TxResponse,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
 const GetProvidersByNotificationType = `-- name: GetProvidersByNotificationType :many
-SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message FROM notification_providers
+SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message, notify_certificate_expiry FROM notification_providers
 WHERE (
     (? = 'NODE_DOWNTIME' AND notify_node_downtime = 1) OR
     (? = 'BACKUP_SUCCESS' AND notify_backup_success = 1) OR
@@ -3134,6 +4095,7 @@ func (q *Queries) GetProvidersByNotificationType(ctx context.Context, arg *GetPr
 			&i.LastTestAt,
 			&i.LastTestStatus,
 			&i.LastTestMessage,
+			&i.NotifyCertificateExpiry,
 		); err != nil {
 			return nil, err
 		}
@@ -3149,7 +4111,7 @@ func (q *Queries) GetProvidersByNotificationType(ctx context.Context, arg *GetPr
 }
 
 const GetRecentCompletedBackups = `-- name: GetRecentCompletedBackups :many
-SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent FROM backups
+SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at FROM backups
 WHERE (status = 'COMPLETED' OR status = 'FAILED')
   AND notification_sent = false
 ORDER BY completed_at DESC
@@ -3176,6 +4138,11 @@ func (q *Queries) GetRecentCompletedBackups(ctx context.Context) ([]*Backup, err
 			&i.ErrorMessage,
 			&i.CreatedAt,
 			&i.NotificationSent,
+			&i.SnapshotID,
+			&i.NodeMetadata,
+			&i.VerificationStatus,
+			&i.VerificationReport,
+			&i.VerifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -3266,6 +4233,26 @@ func (q *Queries) GetRevokedCertificates(ctx context.Context, fabricOrganization
 	return items, nil
 }
 
+const GetRoleBinding = `-- name: GetRoleBinding :one
+SELECT id, user_id, resource_type, resource_id, role, created_by, created_at FROM role_bindings
+WHERE id = ? LIMIT 1
+`
+
+func (q *Queries) GetRoleBinding(ctx context.Context, id int64) (*RoleBinding, error) {
+	row := q.db.QueryRowContext(ctx, GetRoleBinding, id)
+	var i RoleBinding
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ResourceType,
+		&i.ResourceID,
+		&i.Role,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return &i, err
+}
+
 const GetSession = `-- name: GetSession :one
 SELECT id, session_id, user_id, token, ip_address, user_agent, created_at, updated_at, expires_at, last_activity_at FROM sessions WHERE token = ? LIMIT 1
 `
@@ -3352,7 +4339,7 @@ func (q *Queries) GetSetting(ctx context.Context, id int64) (*Setting, error) {
 }
 
 const GetUser = `-- name: GetUser :one
-SELECT id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at FROM users
+SELECT id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account FROM users
 WHERE id = ? LIMIT 1
 `
 
@@ -3372,12 +4359,44 @@ func (q *Queries) GetUser(ctx context.Context, id int64) (*User, error) {
 		&i.CreatedAt,
 		&i.LastLoginAt,
 		&i.UpdatedAt,
+		&i.ServiceAccount,
+	)
+	return &i, err
+}
+
+const GetUserByProvider = `-- name: GetUserByProvider :one
+SELECT id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account FROM users
+WHERE provider = ? AND provider_id = ? LIMIT 1
+`
+
+type GetUserByProviderParams struct {
+	Provider   sql.NullString `json:"provider"`
+	ProviderID sql.NullString `json:"providerId"`
+}
+
+func (q *Queries) GetUserByProvider(ctx context.Context, arg *GetUserByProviderParams) (*User, error) {
+	row := q.db.QueryRowContext(ctx, GetUserByProvider, arg.Provider, arg.ProviderID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Role,
+		&i.Provider,
+		&i.ProviderID,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.LastLoginAt,
+		&i.UpdatedAt,
+		&i.ServiceAccount,
 	)
 	return &i, err
 }
 
 const GetUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at FROM users
+SELECT id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account FROM users
 WHERE username = ? LIMIT 1
 `
 
@@ -3397,10 +4416,253 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (*User
 		&i.CreatedAt,
 		&i.LastLoginAt,
 		&i.UpdatedAt,
+		&i.ServiceAccount,
 	)
 	return &i, err
 }
 
+const GetUserTOTP = `-- name: GetUserTOTP :one
+SELECT user_id, secret, enabled, last_used_step, created_at, enabled_at FROM user_totp
+WHERE user_id = ? LIMIT 1
+`
+
+func (q *Queries) GetUserTOTP(ctx context.Context, userID int64) (*UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, GetUserTOTP, userID)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.Enabled,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+		&i.EnabledAt,
+	)
+	return &i, err
+}
+
+const ListAPITokensByUser = `-- name: ListAPITokensByUser :many
+SELECT id, user_id, name, token_prefix, token_hash, role, expires_at, last_used_at, last_used_ip, revoked_at, created_by, created_at FROM api_tokens
+WHERE user_id = ?
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListAPITokensByUser(ctx context.Context, userID int64) ([]*ApiToken, error) {
+	rows, err := q.db.QueryContext(ctx, ListAPITokensByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ApiToken{}
+	for rows.Next() {
+		var i ApiToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.TokenPrefix,
+			&i.TokenHash,
+			&i.Role,
+			&i.ExpiresAt,
+			&i.LastUsedAt,
+			&i.LastUsedIp,
+			&i.RevokedAt,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListActiveAlerts = `-- name: ListActiveAlerts :many
+SELECT a.id, a.rule_id, a.labels, a.state, a.value, a.active_at, a.fired_at, a.resolved_at, a.updated_at, r.name AS rule_name, r.severity AS rule_severity
+FROM alerts a
+JOIN alert_rules r ON r.id = a.rule_id
+WHERE a.state IN ('pending', 'firing')
+ORDER BY a.active_at DESC
+`
+
+type ListActiveAlertsRow struct {
+	ID           int64        `json:"id"`
+	RuleID       int64        `json:"ruleId"`
+	Labels       string       `json:"labels"`
+	State        string       `json:"state"`
+	Value        float64      `json:"value"`
+	ActiveAt     time.Time    `json:"activeAt"`
+	FiredAt      sql.NullTime `json:"firedAt"`
+	ResolvedAt   sql.NullTime `json:"resolvedAt"`
+	UpdatedAt    time.Time    `json:"updatedAt"`
+	RuleName     string       `json:"ruleName"`
+	RuleSeverity string       `json:"ruleSeverity"`
+}
+
+func (q *Queries) ListActiveAlerts(ctx context.Context) ([]*ListActiveAlertsRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListActiveAlerts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListActiveAlertsRow{}
+	for rows.Next() {
+		var i ListActiveAlertsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RuleID,
+			&i.Labels,
+			&i.State,
+			&i.Value,
+			&i.ActiveAt,
+			&i.FiredAt,
+			&i.ResolvedAt,
+			&i.UpdatedAt,
+			&i.RuleName,
+			&i.RuleSeverity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListActiveAlertsByRule = `-- name: ListActiveAlertsByRule :many
+SELECT id, rule_id, labels, state, value, active_at, fired_at, resolved_at, updated_at FROM alerts
+WHERE rule_id = ? AND state IN ('pending', 'firing')
+`
+
+func (q *Queries) ListActiveAlertsByRule(ctx context.Context, ruleID int64) ([]*Alert, error) {
+	rows, err := q.db.QueryContext(ctx, ListActiveAlertsByRule, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Alert{}
+	for rows.Next() {
+		var i Alert
+		if err := rows.Scan(
+			&i.ID,
+			&i.RuleID,
+			&i.Labels,
+			&i.State,
+			&i.Value,
+			&i.ActiveAt,
+			&i.FiredAt,
+			&i.ResolvedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListAlertRules = `-- name: ListAlertRules :many
+SELECT id, name, description, expression, for_seconds, severity, provider_ids, enabled, last_evaluated_at, last_error, created_at, updated_at FROM alert_rules
+ORDER BY name
+`
+
+func (q *Queries) ListAlertRules(ctx context.Context) ([]*AlertRule, error) {
+	rows, err := q.db.QueryContext(ctx, ListAlertRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*AlertRule{}
+	for rows.Next() {
+		var i AlertRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Expression,
+			&i.ForSeconds,
+			&i.Severity,
+			&i.ProviderIds,
+			&i.Enabled,
+			&i.LastEvaluatedAt,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListAlertsByRule = `-- name: ListAlertsByRule :many
+SELECT id, rule_id, labels, state, value, active_at, fired_at, resolved_at, updated_at FROM alerts
+WHERE rule_id = ?
+ORDER BY active_at DESC
+LIMIT ?
+`
+
+type ListAlertsByRuleParams struct {
+	RuleID int64 `json:"ruleId"`
+	Limit  int64 `json:"limit"`
+}
+
+func (q *Queries) ListAlertsByRule(ctx context.Context, arg *ListAlertsByRuleParams) ([]*Alert, error) {
+	rows, err := q.db.QueryContext(ctx, ListAlertsByRule, arg.RuleID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Alert{}
+	for rows.Next() {
+		var i Alert
+		if err := rows.Scan(
+			&i.ID,
+			&i.RuleID,
+			&i.Labels,
+			&i.State,
+			&i.Value,
+			&i.ActiveAt,
+			&i.FiredAt,
+			&i.ResolvedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListAuditLogs = `-- name: ListAuditLogs :many
 SELECT id, timestamp, event_source, user_identity, source_ip, event_type, event_outcome, affected_resource, request_id, severity, details, created_at, updated_at, session_id FROM audit_logs
 WHERE (? IS NULL OR timestamp >= ?)
@@ -3473,8 +4735,36 @@ func (q *Queries) ListAuditLogs(ctx context.Context, arg *ListAuditLogsParams) (
 	return items, nil
 }
 
+const ListAutoRenewNodeIDs = `-- name: ListAutoRenewNodeIDs :many
+SELECT node_id FROM node_certificate_settings
+WHERE auto_renew = true
+`
+
+func (q *Queries) ListAutoRenewNodeIDs(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, ListAutoRenewNodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var node_id int64
+		if err := rows.Scan(&node_id); err != nil {
+			return nil, err
+		}
+		items = append(items, node_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListBackupSchedules = `-- name: ListBackupSchedules :many
-SELECT id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at FROM backup_schedules
+SELECT id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at, keep_last, keep_daily, keep_weekly, keep_monthly, scope, node_ids, network_id, consistency_strategy, stop_order, verify_cron_expression FROM backup_schedules
 ORDER BY created_at DESC
 `
 
@@ -3499,6 +4789,16 @@ func (q *Queries) ListBackupSchedules(ctx context.Context) ([]*BackupSchedule, e
 			&i.UpdatedAt,
 			&i.LastRunAt,
 			&i.NextRunAt,
+			&i.KeepLast,
+			&i.KeepDaily,
+			&i.KeepWeekly,
+			&i.KeepMonthly,
+			&i.Scope,
+			&i.NodeIds,
+			&i.NetworkID,
+			&i.ConsistencyStrategy,
+			&i.StopOrder,
+			&i.VerifyCronExpression,
 		); err != nil {
 			return nil, err
 		}
@@ -3514,7 +4814,7 @@ func (q *Queries) ListBackupSchedules(ctx context.Context) ([]*BackupSchedule, e
 }
 
 const ListBackupTargets = `-- name: ListBackupTargets :many
-SELECT id, name, bucket_name, region, endpoint, bucket_path, access_key_id, secret_key, s3_path_style, restic_password, type, created_at, updated_at FROM backup_targets
+SELECT id, name, bucket_name, region, endpoint, bucket_path, access_key_id, secret_key, s3_path_style, restic_password, type, created_at, updated_at, local_path, sftp_host, sftp_port, sftp_username, sftp_private_key, sftp_path, engine, sftp_host_key FROM backup_targets
 ORDER BY created_at DESC
 `
 
@@ -3541,6 +4841,14 @@ func (q *Queries) ListBackupTargets(ctx context.Context) ([]*BackupTarget, error
 			&i.Type,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.LocalPath,
+			&i.SftpHost,
+			&i.SftpPort,
+			&i.SftpUsername,
+			&i.SftpPrivateKey,
+			&i.SftpPath,
+			&i.Engine,
+			&i.SftpHostKey,
 		); err != nil {
 			return nil, err
 		}
@@ -3556,7 +4864,7 @@ func (q *Queries) ListBackupTargets(ctx context.Context) ([]*BackupTarget, error
 }
 
 const ListBackups = `-- name: ListBackups :many
-SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent FROM backups
+SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at FROM backups
 ORDER BY created_at DESC
 LIMIT ? OFFSET ?
 `
@@ -3586,6 +4894,11 @@ func (q *Queries) ListBackups(ctx context.Context, arg *ListBackupsParams) ([]*B
 			&i.ErrorMessage,
 			&i.CreatedAt,
 			&i.NotificationSent,
+			&i.SnapshotID,
+			&i.NodeMetadata,
+			&i.VerificationStatus,
+			&i.VerificationReport,
+			&i.VerifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -3601,7 +4914,7 @@ func (q *Queries) ListBackups(ctx context.Context, arg *ListBackupsParams) ([]*B
 }
 
 const ListBackupsBySchedule = `-- name: ListBackupsBySchedule :many
-SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent FROM backups
+SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at FROM backups
 WHERE schedule_id = ?
 ORDER BY created_at DESC
 `
@@ -3626,6 +4939,11 @@ func (q *Queries) ListBackupsBySchedule(ctx context.Context, scheduleID sql.Null
 			&i.ErrorMessage,
 			&i.CreatedAt,
 			&i.NotificationSent,
+			&i.SnapshotID,
+			&i.NodeMetadata,
+			&i.VerificationStatus,
+			&i.VerificationReport,
+			&i.VerifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -3641,7 +4959,7 @@ func (q *Queries) ListBackupsBySchedule(ctx context.Context, scheduleID sql.Null
 }
 
 const ListBackupsByTarget = `-- name: ListBackupsByTarget :many
-SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent FROM backups
+SELECT id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at FROM backups
 WHERE target_id = ?
 ORDER BY created_at DESC
 `
@@ -3666,6 +4984,11 @@ func (q *Queries) ListBackupsByTarget(ctx context.Context, targetID int64) ([]*B
 			&i.ErrorMessage,
 			&i.CreatedAt,
 			&i.NotificationSent,
+			&i.SnapshotID,
+			&i.NodeMetadata,
+			&i.VerificationStatus,
+			&i.VerificationReport,
+			&i.VerifiedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -3680,24 +5003,67 @@ func (q *Queries) ListBackupsByTarget(ctx context.Context, targetID int64) ([]*B
 	return items, nil
 }
 
-const ListChaincodeDefinitionEvents = `-- name: ListChaincodeDefinitionEvents :many
-SELECT id, definition_id, event_type, event_data, created_at FROM fabric_chaincode_definition_events WHERE definition_id = ? ORDER BY created_at ASC
+const ListBesuValidatorChangesByNetwork = `-- name: ListBesuValidatorChangesByNetwork :many
+SELECT id, network_id, action, address, key_id, status, votes, votes_required, error_message, created_by, created_at, updated_at, applied_at FROM besu_validator_changes
+WHERE network_id = ?
+ORDER BY created_at DESC, id DESC
 `
 
-func (q *Queries) ListChaincodeDefinitionEvents(ctx context.Context, definitionID int64) ([]*FabricChaincodeDefinitionEvent, error) {
-	rows, err := q.db.QueryContext(ctx, ListChaincodeDefinitionEvents, definitionID)
+func (q *Queries) ListBesuValidatorChangesByNetwork(ctx context.Context, networkID int64) ([]*BesuValidatorChange, error) {
+	rows, err := q.db.QueryContext(ctx, ListBesuValidatorChangesByNetwork, networkID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []*FabricChaincodeDefinitionEvent{}
+	items := []*BesuValidatorChange{}
 	for rows.Next() {
-		var i FabricChaincodeDefinitionEvent
+		var i BesuValidatorChange
 		if err := rows.Scan(
 			&i.ID,
-			&i.DefinitionID,
-			&i.EventType,
-			&i.EventData,
+			&i.NetworkID,
+			&i.Action,
+			&i.Address,
+			&i.KeyID,
+			&i.Status,
+			&i.Votes,
+			&i.VotesRequired,
+			&i.ErrorMessage,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AppliedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListChaincodeDefinitionEvents = `-- name: ListChaincodeDefinitionEvents :many
+SELECT id, definition_id, event_type, event_data, created_at FROM fabric_chaincode_definition_events WHERE definition_id = ? ORDER BY created_at ASC
+`
+
+func (q *Queries) ListChaincodeDefinitionEvents(ctx context.Context, definitionID int64) ([]*FabricChaincodeDefinitionEvent, error) {
+	rows, err := q.db.QueryContext(ctx, ListChaincodeDefinitionEvents, definitionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*FabricChaincodeDefinitionEvent{}
+	for rows.Next() {
+		var i FabricChaincodeDefinitionEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.DefinitionID,
+			&i.EventType,
+			&i.EventData,
 			&i.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -3781,6 +5147,101 @@ func (q *Queries) ListChaincodes(ctx context.Context) ([]*FabricChaincode, error
 	return items, nil
 }
 
+const ListDefaultNotificationProvidersForType = `-- name: ListDefaultNotificationProvidersForType :many
+SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message, notify_certificate_expiry FROM notification_providers
+WHERE is_default = true
+  AND (
+    (?1 = 'BACKUP_SUCCESS' AND notify_backup_success = true) OR
+    (?1 = 'BACKUP_FAILURE' AND notify_backup_failure = true) OR
+    (?1 = 'NODE_DOWNTIME' AND notify_node_downtime = true) OR
+    (?1 = 'S3_CONNECTION_ISSUE' AND notify_s3_connection_issue = true) OR
+    (?1 = 'CERTIFICATE_EXPIRING' AND notify_certificate_expiry = true)
+  )
+ORDER BY id
+`
+
+func (q *Queries) ListDefaultNotificationProvidersForType(ctx context.Context, notificationType interface{}) ([]*NotificationProvider, error) {
+	rows, err := q.db.QueryContext(ctx, ListDefaultNotificationProvidersForType, notificationType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*NotificationProvider{}
+	for rows.Next() {
+		var i NotificationProvider
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Type,
+			&i.Config,
+			&i.IsDefault,
+			&i.IsEnabled,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.NotifyNodeDowntime,
+			&i.NotifyBackupSuccess,
+			&i.NotifyBackupFailure,
+			&i.NotifyS3ConnectionIssue,
+			&i.LastTestAt,
+			&i.LastTestStatus,
+			&i.LastTestMessage,
+			&i.NotifyCertificateExpiry,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListEnabledAlertRules = `-- name: ListEnabledAlertRules :many
+SELECT id, name, description, expression, for_seconds, severity, provider_ids, enabled, last_evaluated_at, last_error, created_at, updated_at FROM alert_rules
+WHERE enabled = true
+ORDER BY id
+`
+
+func (q *Queries) ListEnabledAlertRules(ctx context.Context) ([]*AlertRule, error) {
+	rows, err := q.db.QueryContext(ctx, ListEnabledAlertRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*AlertRule{}
+	for rows.Next() {
+		var i AlertRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Expression,
+			&i.ForSeconds,
+			&i.Severity,
+			&i.ProviderIds,
+			&i.Enabled,
+			&i.LastEvaluatedAt,
+			&i.LastError,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListFabricChaincodes = `-- name: ListFabricChaincodes :many
 SELECT id, name, network_id, created_at FROM fabric_chaincodes ORDER BY created_at DESC
 `
@@ -3948,6 +5409,45 @@ func (q *Queries) ListFabricOrganizationsWithKeys(ctx context.Context, arg *List
 	return items, nil
 }
 
+const ListKeyPrivateKeysByProviderType = `-- name: ListKeyPrivateKeysByProviderType :many
+SELECT k.id, k.private_key
+FROM keys k
+JOIN key_providers kp ON k.provider_id = kp.id
+WHERE kp.type = ?
+ORDER BY k.id
+`
+
+type ListKeyPrivateKeysByProviderTypeRow struct {
+	ID         int64  `json:"id"`
+	PrivateKey string `json:"privateKey"`
+}
+
+func (q *Queries) ListKeyPrivateKeysByProviderType(ctx context.Context, type_ string) ([]*ListKeyPrivateKeysByProviderTypeRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListKeyPrivateKeysByProviderType, type_)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListKeyPrivateKeysByProviderTypeRow{}
+	for rows.Next() {
+		var i ListKeyPrivateKeysByProviderTypeRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PrivateKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListKeyProviders = `-- name: ListKeyProviders :many
 SELECT id, name, type, is_default, config, created_at, updated_at FROM key_providers
 `
@@ -4071,6 +5571,129 @@ func (q *Queries) ListKeys(ctx context.Context, arg *ListKeysParams) ([]*ListKey
 	return items, nil
 }
 
+const ListKeysWithCertificateExpiry = `-- name: ListKeysWithCertificateExpiry :many
+SELECT id, name, certificate, expires_at FROM keys
+WHERE certificate IS NOT NULL AND expires_at IS NOT NULL
+ORDER BY expires_at
+`
+
+type ListKeysWithCertificateExpiryRow struct {
+	ID          int64          `json:"id"`
+	Name        string         `json:"name"`
+	Certificate sql.NullString `json:"certificate"`
+	ExpiresAt   sql.NullTime   `json:"expiresAt"`
+}
+
+func (q *Queries) ListKeysWithCertificateExpiry(ctx context.Context) ([]*ListKeysWithCertificateExpiryRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListKeysWithCertificateExpiry)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListKeysWithCertificateExpiryRow{}
+	for rows.Next() {
+		var i ListKeysWithCertificateExpiryRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Certificate,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListLatestNodeChecks = `-- name: ListLatestNodeChecks :many
+SELECT nc.id, nc.node_id, nc.status, nc.response_time_ms, nc.error_message, nc.failure_count, nc.checked_at, n.name AS node_name, n.platform AS node_platform, n.endpoint AS node_endpoint
+FROM node_checks nc
+JOIN nodes n ON nc.node_id = n.id
+WHERE nc.id IN (SELECT MAX(id) FROM node_checks GROUP BY node_id)
+`
+
+type ListLatestNodeChecksRow struct {
+	ID             int64          `json:"id"`
+	NodeID         int64          `json:"nodeId"`
+	Status         string         `json:"status"`
+	ResponseTimeMs int64          `json:"responseTimeMs"`
+	ErrorMessage   sql.NullString `json:"errorMessage"`
+	FailureCount   int64          `json:"failureCount"`
+	CheckedAt      time.Time      `json:"checkedAt"`
+	NodeName       string         `json:"nodeName"`
+	NodePlatform   string         `json:"nodePlatform"`
+	NodeEndpoint   sql.NullString `json:"nodeEndpoint"`
+}
+
+func (q *Queries) ListLatestNodeChecks(ctx context.Context) ([]*ListLatestNodeChecksRow, error) {
+	rows, err := q.db.QueryContext(ctx, ListLatestNodeChecks)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ListLatestNodeChecksRow{}
+	for rows.Next() {
+		var i ListLatestNodeChecksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.NodeID,
+			&i.Status,
+			&i.ResponseTimeMs,
+			&i.ErrorMessage,
+			&i.FailureCount,
+			&i.CheckedAt,
+			&i.NodeName,
+			&i.NodePlatform,
+			&i.NodeEndpoint,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListNetworkIDsByNode = `-- name: ListNetworkIDsByNode :many
+SELECT network_id FROM network_nodes
+WHERE node_id = ?
+`
+
+func (q *Queries) ListNetworkIDsByNode(ctx context.Context, nodeID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, ListNetworkIDsByNode, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var network_id int64
+		if err := rows.Scan(&network_id); err != nil {
+			return nil, err
+		}
+		items = append(items, network_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListNetworkNodesByNetwork = `-- name: ListNetworkNodesByNetwork :many
 SELECT id, network_id, node_id, role, status, config, created_at, updated_at FROM network_nodes
 WHERE network_id = ?
@@ -4237,36 +5860,34 @@ func (q *Queries) ListNetworksByPlatform(ctx context.Context, platform string) (
 	return items, nil
 }
 
-const ListNodeEvents = `-- name: ListNodeEvents :many
-SELECT id, node_id, event_type, description, data, status, created_at FROM node_events
-WHERE node_id = ?
-ORDER BY id DESC
-LIMIT ? OFFSET ?
+const ListNodeCheckRollups = `-- name: ListNodeCheckRollups :many
+SELECT node_id, bucket_start, total_checks, up_checks, avg_response_time_ms, max_response_time_ms FROM node_check_rollups
+WHERE node_id = ? AND bucket_start >= ? AND bucket_start < ?
+ORDER BY bucket_start
 `
 
-type ListNodeEventsParams struct {
-	NodeID int64 `json:"nodeId"`
-	Limit  int64 `json:"limit"`
-	Offset int64 `json:"offset"`
+type ListNodeCheckRollupsParams struct {
+	NodeID        int64     `json:"nodeId"`
+	BucketStart   time.Time `json:"bucketStart"`
+	BucketStart_2 time.Time `json:"bucketStart2"`
 }
 
-func (q *Queries) ListNodeEvents(ctx context.Context, arg *ListNodeEventsParams) ([]*NodeEvent, error) {
-	rows, err := q.db.QueryContext(ctx, ListNodeEvents, arg.NodeID, arg.Limit, arg.Offset)
+func (q *Queries) ListNodeCheckRollups(ctx context.Context, arg *ListNodeCheckRollupsParams) ([]*NodeCheckRollup, error) {
+	rows, err := q.db.QueryContext(ctx, ListNodeCheckRollups, arg.NodeID, arg.BucketStart, arg.BucketStart_2)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []*NodeEvent{}
+	items := []*NodeCheckRollup{}
 	for rows.Next() {
-		var i NodeEvent
+		var i NodeCheckRollup
 		if err := rows.Scan(
-			&i.ID,
 			&i.NodeID,
-			&i.EventType,
-			&i.Description,
-			&i.Data,
-			&i.Status,
-			&i.CreatedAt,
+			&i.BucketStart,
+			&i.TotalChecks,
+			&i.UpChecks,
+			&i.AvgResponseTimeMs,
+			&i.MaxResponseTimeMs,
 		); err != nil {
 			return nil, err
 		}
@@ -4281,38 +5902,75 @@ func (q *Queries) ListNodeEvents(ctx context.Context, arg *ListNodeEventsParams)
 	return items, nil
 }
 
-const ListNodeEventsByType = `-- name: ListNodeEventsByType :many
-SELECT id, node_id, event_type, description, data, status, created_at FROM node_events
-WHERE node_id = ? AND event_type = ?
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?
+const ListNodeChecksInRange = `-- name: ListNodeChecksInRange :many
+SELECT id, node_id, status, response_time_ms, error_message, failure_count, checked_at FROM node_checks
+WHERE node_id = ? AND checked_at >= ? AND checked_at < ?
+ORDER BY checked_at
 `
 
-type ListNodeEventsByTypeParams struct {
-	NodeID    int64  `json:"nodeId"`
-	EventType string `json:"eventType"`
-	Limit     int64  `json:"limit"`
-	Offset    int64  `json:"offset"`
+type ListNodeChecksInRangeParams struct {
+	NodeID      int64     `json:"nodeId"`
+	CheckedAt   time.Time `json:"checkedAt"`
+	CheckedAt_2 time.Time `json:"checkedAt2"`
 }
 
-func (q *Queries) ListNodeEventsByType(ctx context.Context, arg *ListNodeEventsByTypeParams) ([]*NodeEvent, error) {
-	rows, err := q.db.QueryContext(ctx, ListNodeEventsByType,
-		arg.NodeID,
-		arg.EventType,
-		arg.Limit,
-		arg.Offset,
-	)
+func (q *Queries) ListNodeChecksInRange(ctx context.Context, arg *ListNodeChecksInRangeParams) ([]*NodeCheck, error) {
+	rows, err := q.db.QueryContext(ctx, ListNodeChecksInRange, arg.NodeID, arg.CheckedAt, arg.CheckedAt_2)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []*NodeEvent{}
+	items := []*NodeCheck{}
 	for rows.Next() {
-		var i NodeEvent
+		var i NodeCheck
 		if err := rows.Scan(
 			&i.ID,
 			&i.NodeID,
-			&i.EventType,
+			&i.Status,
+			&i.ResponseTimeMs,
+			&i.ErrorMessage,
+			&i.FailureCount,
+			&i.CheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListNodeEvents = `-- name: ListNodeEvents :many
+SELECT id, node_id, event_type, description, data, status, created_at FROM node_events
+WHERE node_id = ?
+ORDER BY id DESC
+LIMIT ? OFFSET ?
+`
+
+type ListNodeEventsParams struct {
+	NodeID int64 `json:"nodeId"`
+	Limit  int64 `json:"limit"`
+	Offset int64 `json:"offset"`
+}
+
+func (q *Queries) ListNodeEvents(ctx context.Context, arg *ListNodeEventsParams) ([]*NodeEvent, error) {
+	rows, err := q.db.QueryContext(ctx, ListNodeEvents, arg.NodeID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*NodeEvent{}
+	for rows.Next() {
+		var i NodeEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.NodeID,
+			&i.EventType,
 			&i.Description,
 			&i.Data,
 			&i.Status,
@@ -4331,6 +5989,97 @@ func (q *Queries) ListNodeEventsByType(ctx context.Context, arg *ListNodeEventsB
 	return items, nil
 }
 
+const ListNodeEventsByType = `-- name: ListNodeEventsByType :many
+SELECT id, node_id, event_type, description, data, status, created_at FROM node_events
+WHERE node_id = ? AND event_type = ?
+ORDER BY created_at DESC
+LIMIT ? OFFSET ?
+`
+
+type ListNodeEventsByTypeParams struct {
+	NodeID    int64  `json:"nodeId"`
+	EventType string `json:"eventType"`
+	Limit     int64  `json:"limit"`
+	Offset    int64  `json:"offset"`
+}
+
+func (q *Queries) ListNodeEventsByType(ctx context.Context, arg *ListNodeEventsByTypeParams) ([]*NodeEvent, error) {
+	rows, err := q.db.QueryContext(ctx, ListNodeEventsByType,
+		arg.NodeID,
+		arg.EventType,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*NodeEvent{}
+	for rows.Next() {
+		var i NodeEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.NodeID,
+			&i.EventType,
+			&i.Description,
+			&i.Data,
+			&i.Status,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListNodeIncidentsInRange = `-- name: ListNodeIncidentsInRange :many
+SELECT id, node_id, started_at, resolved_at, error_message FROM node_incidents
+WHERE node_id = ? AND started_at < ? AND (resolved_at IS NULL OR resolved_at > ?)
+ORDER BY started_at
+`
+
+type ListNodeIncidentsInRangeParams struct {
+	NodeID     int64        `json:"nodeId"`
+	StartedAt  time.Time    `json:"startedAt"`
+	ResolvedAt sql.NullTime `json:"resolvedAt"`
+}
+
+func (q *Queries) ListNodeIncidentsInRange(ctx context.Context, arg *ListNodeIncidentsInRangeParams) ([]*NodeIncident, error) {
+	rows, err := q.db.QueryContext(ctx, ListNodeIncidentsInRange, arg.NodeID, arg.StartedAt, arg.ResolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*NodeIncident{}
+	for rows.Next() {
+		var i NodeIncident
+		if err := rows.Scan(
+			&i.ID,
+			&i.NodeID,
+			&i.StartedAt,
+			&i.ResolvedAt,
+			&i.ErrorMessage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListNodes = `-- name: ListNodes :many
 SELECT id, name, slug, platform, status, description, network_id, config, resources, endpoint, public_endpoint, p2p_address, created_at, created_by, updated_at, fabric_organization_id, node_type, node_config, deployment_config, error_message FROM nodes
 ORDER BY created_at DESC
@@ -4501,7 +6250,7 @@ func (q *Queries) ListNodesByPlatform(ctx context.Context, arg *ListNodesByPlatf
 }
 
 const ListNotificationProviders = `-- name: ListNotificationProviders :many
-SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message FROM notification_providers
+SELECT id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message, notify_certificate_expiry FROM notification_providers
 ORDER BY created_at DESC
 `
 
@@ -4530,6 +6279,7 @@ func (q *Queries) ListNotificationProviders(ctx context.Context) ([]*Notificatio
 			&i.LastTestAt,
 			&i.LastTestStatus,
 			&i.LastTestMessage,
+			&i.NotifyCertificateExpiry,
 		); err != nil {
 			return nil, err
 		}
@@ -4544,6 +6294,73 @@ func (q *Queries) ListNotificationProviders(ctx context.Context) ([]*Notificatio
 	return items, nil
 }
 
+const ListOpenNodeIncidents = `-- name: ListOpenNodeIncidents :many
+SELECT id, node_id, started_at, resolved_at, error_message FROM node_incidents
+WHERE resolved_at IS NULL
+`
+
+func (q *Queries) ListOpenNodeIncidents(ctx context.Context) ([]*NodeIncident, error) {
+	rows, err := q.db.QueryContext(ctx, ListOpenNodeIncidents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*NodeIncident{}
+	for rows.Next() {
+		var i NodeIncident
+		if err := rows.Scan(
+			&i.ID,
+			&i.NodeID,
+			&i.StartedAt,
+			&i.ResolvedAt,
+			&i.ErrorMessage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListOrganizationIDsByKey = `-- name: ListOrganizationIDsByKey :many
+SELECT id FROM fabric_organizations
+WHERE sign_key_id = ?1
+   OR tls_root_key_id = ?1
+   OR admin_tls_key_id = ?1
+   OR admin_sign_key_id = ?1
+   OR client_sign_key_id = ?1
+   OR crl_key_id = ?1
+`
+
+func (q *Queries) ListOrganizationIDsByKey(ctx context.Context, keyID sql.NullInt64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, ListOrganizationIDsByKey, keyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListPeerStatuses = `-- name: ListPeerStatuses :many
 SELECT id, definition_id, peer_id, status, last_updated FROM fabric_chaincode_definition_peer_status WHERE definition_id = ?
 `
@@ -4577,6 +6394,49 @@ func (q *Queries) ListPeerStatuses(ctx context.Context, definitionID int64) ([]*
 	return items, nil
 }
 
+const ListPendingBesuValidatorChanges = `-- name: ListPendingBesuValidatorChanges :many
+SELECT id, network_id, action, address, key_id, status, votes, votes_required, error_message, created_by, created_at, updated_at, applied_at FROM besu_validator_changes
+WHERE network_id = ? AND status = 'pending'
+ORDER BY id
+`
+
+func (q *Queries) ListPendingBesuValidatorChanges(ctx context.Context, networkID int64) ([]*BesuValidatorChange, error) {
+	rows, err := q.db.QueryContext(ctx, ListPendingBesuValidatorChanges, networkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*BesuValidatorChange{}
+	for rows.Next() {
+		var i BesuValidatorChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.Action,
+			&i.Address,
+			&i.KeyID,
+			&i.Status,
+			&i.Votes,
+			&i.VotesRequired,
+			&i.ErrorMessage,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.AppliedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListPlugins = `-- name: ListPlugins :many
 SELECT name, api_version, kind, metadata, spec, created_at, updated_at, deployment_metadata, deployment_status FROM plugins ORDER BY name
 `
@@ -4614,6 +6474,159 @@ func (q *Queries) ListPlugins(ctx context.Context) ([]*Plugin, error) {
 	return items, nil
 }
 
+const ListProposalSignatures = `-- name: ListProposalSignatures :many
+SELECT id, proposal_id, msp_id, signed_by, signature, signed_at FROM proposal_signatures WHERE proposal_id = ? ORDER BY signed_at ASC
+`
+
+func (q *Queries) ListProposalSignatures(ctx context.Context, proposalID string) ([]*ProposalSignature, error) {
+	rows, err := q.db.QueryContext(ctx, ListProposalSignatures, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*ProposalSignature{}
+	for rows.Next() {
+		var i ProposalSignature
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProposalID,
+			&i.MspID,
+			&i.SignedBy,
+			&i.Signature,
+			&i.SignedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListProposalsByNetwork = `-- name: ListProposalsByNetwork :many
+SELECT id, network_id, channel_name, status, operations, preview_json, config_update, tx_response, created_by, created_at, updated_at FROM proposals WHERE network_id = ? ORDER BY created_at DESC
+`
+
+func (q *Queries) ListProposalsByNetwork(ctx context.Context, networkID int64) ([]*Proposal, error) {
+	rows, err := q.db.QueryContext(ctx, ListProposalsByNetwork, networkID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*Proposal{}
+	for rows.Next() {
+		var i Proposal
+		if err := rows.Scan(
+			&i.ID,
+			&i.NetworkID,
+			&i.ChannelName,
+			&i.Status,
+			&i.Operations,
+			&i.PreviewJson,
+			&i.ConfigUpdate,
+			&i.TxResponse,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListRoleBindingsByUser = `-- name: ListRoleBindingsByUser :many
+SELECT id, user_id, resource_type, resource_id, role, created_by, created_at FROM role_bindings
+WHERE user_id = ?
+ORDER BY resource_type, resource_id
+`
+
+func (q *Queries) ListRoleBindingsByUser(ctx context.Context, userID int64) ([]*RoleBinding, error) {
+	rows, err := q.db.QueryContext(ctx, ListRoleBindingsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*RoleBinding{}
+	for rows.Next() {
+		var i RoleBinding
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.Role,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const ListServiceAccounts = `-- name: ListServiceAccounts :many
+SELECT id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account FROM users
+WHERE service_account = true
+ORDER BY username
+`
+
+func (q *Queries) ListServiceAccounts(ctx context.Context) ([]*User, error) {
+	rows, err := q.db.QueryContext(ctx, ListServiceAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []*User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Password,
+			&i.Name,
+			&i.Email,
+			&i.Role,
+			&i.Provider,
+			&i.ProviderID,
+			&i.AvatarUrl,
+			&i.CreatedAt,
+			&i.LastLoginAt,
+			&i.UpdatedAt,
+			&i.ServiceAccount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const ListSettings = `-- name: ListSettings :many
 SELECT id, config, created_at, updated_at FROM settings
 ORDER BY created_at DESC
@@ -4648,7 +6661,8 @@ func (q *Queries) ListSettings(ctx context.Context) ([]*Setting, error) {
 }
 
 const ListUsers = `-- name: ListUsers :many
-SELECT id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at FROM users
+SELECT id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account FROM users
+WHERE service_account = false
 ORDER BY created_at DESC
 `
 
@@ -4674,6 +6688,7 @@ func (q *Queries) ListUsers(ctx context.Context) ([]*User, error) {
 			&i.CreatedAt,
 			&i.LastLoginAt,
 			&i.UpdatedAt,
+			&i.ServiceAccount,
 		); err != nil {
 			return nil, err
 		}
@@ -4688,6 +6703,23 @@ func (q *Queries) ListUsers(ctx context.Context) ([]*User, error) {
 	return items, nil
 }
 
+const LockLogin = `-- name: LockLogin :exec
+UPDATE login_failures
+SET failed_attempts = 0,
+    locked_until = ?
+WHERE username = ?
+`
+
+type LockLoginParams struct {
+	LockedUntil sql.NullTime `json:"lockedUntil"`
+	Username    string       `json:"username"`
+}
+
+func (q *Queries) LockLogin(ctx context.Context, arg *LockLoginParams) error {
+	_, err := q.db.ExecContext(ctx, LockLogin, arg.LockedUntil, arg.Username)
+	return err
+}
+
 const MarkBackupNotified = `-- name: MarkBackupNotified :exec
 UPDATE backups
 SET notification_sent = true
@@ -4699,6 +6731,35 @@ func (q *Queries) MarkBackupNotified(ctx context.Context, id int64) error {
 	return err
 }
 
+const RecordLoginFailure = `-- name: RecordLoginFailure :one
+INSERT INTO login_failures (
+    username, failed_attempts, last_failed_at
+) VALUES (
+    ?1, 1, CURRENT_TIMESTAMP
+)
+ON CONFLICT(username) DO UPDATE SET
+    failed_attempts = CASE WHEN login_failures.last_failed_at < ?2 THEN 1 ELSE login_failures.failed_attempts + 1 END,
+    last_failed_at = CURRENT_TIMESTAMP
+RETURNING username, failed_attempts, last_failed_at, locked_until
+`
+
+type RecordLoginFailureParams struct {
+	Username    string    `json:"username"`
+	WindowStart time.Time `json:"windowStart"`
+}
+
+func (q *Queries) RecordLoginFailure(ctx context.Context, arg *RecordLoginFailureParams) (*LoginFailure, error) {
+	row := q.db.QueryRowContext(ctx, RecordLoginFailure, arg.Username, arg.WindowStart)
+	var i LoginFailure
+	err := row.Scan(
+		&i.Username,
+		&i.FailedAttempts,
+		&i.LastFailedAt,
+		&i.LockedUntil,
+	)
+	return &i, err
+}
+
 const ResetPrometheusConfig = `-- name: ResetPrometheusConfig :one
 UPDATE prometheus_config
 SET prometheus_port = 9090,
@@ -4739,6 +6800,42 @@ func (q *Queries) ResetPrometheusConfig(ctx context.Context) (*PrometheusConfig,
 	return &i, err
 }
 
+const ResolveNodeIncident = `-- name: ResolveNodeIncident :one
+UPDATE node_incidents
+SET resolved_at = ?
+WHERE id = ?
+RETURNING id, node_id, started_at, resolved_at, error_message
+`
+
+type ResolveNodeIncidentParams struct {
+	ResolvedAt sql.NullTime `json:"resolvedAt"`
+	ID         int64        `json:"id"`
+}
+
+func (q *Queries) ResolveNodeIncident(ctx context.Context, arg *ResolveNodeIncidentParams) (*NodeIncident, error) {
+	row := q.db.QueryRowContext(ctx, ResolveNodeIncident, arg.ResolvedAt, arg.ID)
+	var i NodeIncident
+	err := row.Scan(
+		&i.ID,
+		&i.NodeID,
+		&i.StartedAt,
+		&i.ResolvedAt,
+		&i.ErrorMessage,
+	)
+	return &i, err
+}
+
+const RevokeAPIToken = `-- name: RevokeAPIToken :exec
+UPDATE api_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND revoked_at IS NULL
+`
+
+func (q *Queries) RevokeAPIToken(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, RevokeAPIToken, id)
+	return err
+}
+
 const SetPeerStatus = `-- name: SetPeerStatus :one
 INSERT INTO fabric_chaincode_definition_peer_status (definition_id, peer_id, status)
 VALUES (?, ?, ?)
@@ -4786,12 +6883,130 @@ func (q *Queries) UnsetDefaultProvider(ctx context.Context) error {
 	return err
 }
 
+const UpdateAPITokenLastUsed = `-- name: UpdateAPITokenLastUsed :exec
+UPDATE api_tokens
+SET last_used_at = CURRENT_TIMESTAMP,
+    last_used_ip = ?
+WHERE id = ?
+`
+
+type UpdateAPITokenLastUsedParams struct {
+	LastUsedIp sql.NullString `json:"lastUsedIp"`
+	ID         int64          `json:"id"`
+}
+
+func (q *Queries) UpdateAPITokenLastUsed(ctx context.Context, arg *UpdateAPITokenLastUsedParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateAPITokenLastUsed, arg.LastUsedIp, arg.ID)
+	return err
+}
+
+const UpdateAlertRule = `-- name: UpdateAlertRule :one
+UPDATE alert_rules
+SET name = ?,
+    description = ?,
+    expression = ?,
+    for_seconds = ?,
+    severity = ?,
+    provider_ids = ?,
+    enabled = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, name, description, expression, for_seconds, severity, provider_ids, enabled, last_evaluated_at, last_error, created_at, updated_at
+`
+
+type UpdateAlertRuleParams struct {
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	Expression  string         `json:"expression"`
+	ForSeconds  int64          `json:"forSeconds"`
+	Severity    string         `json:"severity"`
+	ProviderIds string         `json:"providerIds"`
+	Enabled     bool           `json:"enabled"`
+	ID          int64          `json:"id"`
+}
+
+func (q *Queries) UpdateAlertRule(ctx context.Context, arg *UpdateAlertRuleParams) (*AlertRule, error) {
+	row := q.db.QueryRowContext(ctx, UpdateAlertRule,
+		arg.Name,
+		arg.Description,
+		arg.Expression,
+		arg.ForSeconds,
+		arg.Severity,
+		arg.ProviderIds,
+		arg.Enabled,
+		arg.ID,
+	)
+	var i AlertRule
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.Expression,
+		&i.ForSeconds,
+		&i.Severity,
+		&i.ProviderIds,
+		&i.Enabled,
+		&i.LastEvaluatedAt,
+		&i.LastError,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
+const UpdateAlertRuleEvaluation = `-- name: UpdateAlertRuleEvaluation :exec
+UPDATE alert_rules
+SET last_evaluated_at = ?,
+    last_error = ?
+WHERE id = ?
+`
+
+type UpdateAlertRuleEvaluationParams struct {
+	LastEvaluatedAt sql.NullTime   `json:"lastEvaluatedAt"`
+	LastError       sql.NullString `json:"lastError"`
+	ID              int64          `json:"id"`
+}
+
+func (q *Queries) UpdateAlertRuleEvaluation(ctx context.Context, arg *UpdateAlertRuleEvaluationParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateAlertRuleEvaluation, arg.LastEvaluatedAt, arg.LastError, arg.ID)
+	return err
+}
+
+const UpdateAlertState = `-- name: UpdateAlertState :exec
+UPDATE alerts
+SET state = ?,
+    value = ?,
+    fired_at = ?,
+    resolved_at = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateAlertStateParams struct {
+	State      string       `json:"state"`
+	Value      float64      `json:"value"`
+	FiredAt    sql.NullTime `json:"firedAt"`
+	ResolvedAt sql.NullTime `json:"resolvedAt"`
+	ID         int64        `json:"id"`
+}
+
+func (q *Queries) UpdateAlertState(ctx context.Context, arg *UpdateAlertStateParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateAlertState,
+		arg.State,
+		arg.Value,
+		arg.FiredAt,
+		arg.ResolvedAt,
+		arg.ID,
+	)
+	return err
+}
+
 const UpdateBackupCompleted = `-- name: UpdateBackupCompleted :one
 UPDATE backups
 SET status = ?,
     completed_at = ?
 WHERE id = ?
-RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent
+RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at
 `
 
 type UpdateBackupCompletedParams struct {
@@ -4814,6 +7029,11 @@ func (q *Queries) UpdateBackupCompleted(ctx context.Context, arg *UpdateBackupCo
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.NotificationSent,
+		&i.SnapshotID,
+		&i.NodeMetadata,
+		&i.VerificationStatus,
+		&i.VerificationReport,
+		&i.VerifiedAt,
 	)
 	return &i, err
 }
@@ -4824,7 +7044,7 @@ SET status = ?,
     error_message = ?,
     completed_at = ?
 WHERE id = ?
-RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent
+RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at
 `
 
 type UpdateBackupFailedParams struct {
@@ -4853,10 +7073,31 @@ func (q *Queries) UpdateBackupFailed(ctx context.Context, arg *UpdateBackupFaile
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.NotificationSent,
+		&i.SnapshotID,
+		&i.NodeMetadata,
+		&i.VerificationStatus,
+		&i.VerificationReport,
+		&i.VerifiedAt,
 	)
 	return &i, err
 }
 
+const UpdateBackupNodeMetadata = `-- name: UpdateBackupNodeMetadata :exec
+UPDATE backups
+SET node_metadata = ?
+WHERE id = ?
+`
+
+type UpdateBackupNodeMetadataParams struct {
+	NodeMetadata sql.NullString `json:"nodeMetadata"`
+	ID           int64          `json:"id"`
+}
+
+func (q *Queries) UpdateBackupNodeMetadata(ctx context.Context, arg *UpdateBackupNodeMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateBackupNodeMetadata, arg.NodeMetadata, arg.ID)
+	return err
+}
+
 const UpdateBackupSchedule = `-- name: UpdateBackupSchedule :one
 UPDATE backup_schedules
 SET name = ?,
@@ -4865,19 +7106,39 @@ SET name = ?,
     target_id = ?,
     retention_days = ?,
     enabled = ?,
+    keep_last = ?,
+    keep_daily = ?,
+    keep_weekly = ?,
+    keep_monthly = ?,
+    scope = ?,
+    node_ids = ?,
+    network_id = ?,
+    consistency_strategy = ?,
+    stop_order = ?,
+    verify_cron_expression = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at
+RETURNING id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at, keep_last, keep_daily, keep_weekly, keep_monthly, scope, node_ids, network_id, consistency_strategy, stop_order, verify_cron_expression
 `
 
 type UpdateBackupScheduleParams struct {
-	Name           string         `json:"name"`
-	Description    sql.NullString `json:"description"`
-	CronExpression string         `json:"cronExpression"`
-	TargetID       int64          `json:"targetId"`
-	RetentionDays  int64          `json:"retentionDays"`
-	Enabled        bool           `json:"enabled"`
-	ID             int64          `json:"id"`
+	Name                 string         `json:"name"`
+	Description          sql.NullString `json:"description"`
+	CronExpression       string         `json:"cronExpression"`
+	TargetID             int64          `json:"targetId"`
+	RetentionDays        int64          `json:"retentionDays"`
+	Enabled              bool           `json:"enabled"`
+	KeepLast             int64          `json:"keepLast"`
+	KeepDaily            int64          `json:"keepDaily"`
+	KeepWeekly           int64          `json:"keepWeekly"`
+	KeepMonthly          int64          `json:"keepMonthly"`
+	Scope                string         `json:"scope"`
+	NodeIds              sql.NullString `json:"nodeIds"`
+	NetworkID            sql.NullInt64  `json:"networkId"`
+	ConsistencyStrategy  string         `json:"consistencyStrategy"`
+	StopOrder            sql.NullString `json:"stopOrder"`
+	VerifyCronExpression sql.NullString `json:"verifyCronExpression"`
+	ID                   int64          `json:"id"`
 }
 
 func (q *Queries) UpdateBackupSchedule(ctx context.Context, arg *UpdateBackupScheduleParams) (*BackupSchedule, error) {
@@ -4888,6 +7149,16 @@ func (q *Queries) UpdateBackupSchedule(ctx context.Context, arg *UpdateBackupSch
 		arg.TargetID,
 		arg.RetentionDays,
 		arg.Enabled,
+		arg.KeepLast,
+		arg.KeepDaily,
+		arg.KeepWeekly,
+		arg.KeepMonthly,
+		arg.Scope,
+		arg.NodeIds,
+		arg.NetworkID,
+		arg.ConsistencyStrategy,
+		arg.StopOrder,
+		arg.VerifyCronExpression,
 		arg.ID,
 	)
 	var i BackupSchedule
@@ -4903,6 +7174,16 @@ func (q *Queries) UpdateBackupSchedule(ctx context.Context, arg *UpdateBackupSch
 		&i.UpdatedAt,
 		&i.LastRunAt,
 		&i.NextRunAt,
+		&i.KeepLast,
+		&i.KeepDaily,
+		&i.KeepWeekly,
+		&i.KeepMonthly,
+		&i.Scope,
+		&i.NodeIds,
+		&i.NetworkID,
+		&i.ConsistencyStrategy,
+		&i.StopOrder,
+		&i.VerifyCronExpression,
 	)
 	return &i, err
 }
@@ -4913,7 +7194,7 @@ SET last_run_at = ?,
     next_run_at = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at
+RETURNING id, name, description, cron_expression, target_id, retention_days, enabled, created_at, updated_at, last_run_at, next_run_at, keep_last, keep_daily, keep_weekly, keep_monthly, scope, node_ids, network_id, consistency_strategy, stop_order, verify_cron_expression
 `
 
 type UpdateBackupScheduleLastRunParams struct {
@@ -4937,6 +7218,16 @@ func (q *Queries) UpdateBackupScheduleLastRun(ctx context.Context, arg *UpdateBa
 		&i.UpdatedAt,
 		&i.LastRunAt,
 		&i.NextRunAt,
+		&i.KeepLast,
+		&i.KeepDaily,
+		&i.KeepWeekly,
+		&i.KeepMonthly,
+		&i.Scope,
+		&i.NodeIds,
+		&i.NetworkID,
+		&i.ConsistencyStrategy,
+		&i.StopOrder,
+		&i.VerifyCronExpression,
 	)
 	return &i, err
 }
@@ -4945,7 +7236,7 @@ const UpdateBackupSize = `-- name: UpdateBackupSize :one
 UPDATE backups
 SET size_bytes = ?
 WHERE id = ?
-RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent
+RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at
 `
 
 type UpdateBackupSizeParams struct {
@@ -4967,6 +7258,48 @@ func (q *Queries) UpdateBackupSize(ctx context.Context, arg *UpdateBackupSizePar
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.NotificationSent,
+		&i.SnapshotID,
+		&i.NodeMetadata,
+		&i.VerificationStatus,
+		&i.VerificationReport,
+		&i.VerifiedAt,
+	)
+	return &i, err
+}
+
+const UpdateBackupSnapshot = `-- name: UpdateBackupSnapshot :one
+UPDATE backups
+SET snapshot_id = ?,
+    size_bytes = ?
+WHERE id = ?
+RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at
+`
+
+type UpdateBackupSnapshotParams struct {
+	SnapshotID sql.NullString `json:"snapshotId"`
+	SizeBytes  sql.NullInt64  `json:"sizeBytes"`
+	ID         int64          `json:"id"`
+}
+
+func (q *Queries) UpdateBackupSnapshot(ctx context.Context, arg *UpdateBackupSnapshotParams) (*Backup, error) {
+	row := q.db.QueryRowContext(ctx, UpdateBackupSnapshot, arg.SnapshotID, arg.SizeBytes, arg.ID)
+	var i Backup
+	err := row.Scan(
+		&i.ID,
+		&i.ScheduleID,
+		&i.TargetID,
+		&i.Status,
+		&i.SizeBytes,
+		&i.StartedAt,
+		&i.CompletedAt,
+		&i.ErrorMessage,
+		&i.CreatedAt,
+		&i.NotificationSent,
+		&i.SnapshotID,
+		&i.NodeMetadata,
+		&i.VerificationStatus,
+		&i.VerificationReport,
+		&i.VerifiedAt,
 	)
 	return &i, err
 }
@@ -4975,7 +7308,7 @@ const UpdateBackupStatus = `-- name: UpdateBackupStatus :one
 UPDATE backups
 SET status = ?
 WHERE id = ?
-RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent
+RETURNING id, schedule_id, target_id, status, size_bytes, started_at, completed_at, error_message, created_at, notification_sent, snapshot_id, node_metadata, verification_status, verification_report, verified_at
 `
 
 type UpdateBackupStatusParams struct {
@@ -4997,6 +7330,11 @@ func (q *Queries) UpdateBackupStatus(ctx context.Context, arg *UpdateBackupStatu
 		&i.ErrorMessage,
 		&i.CreatedAt,
 		&i.NotificationSent,
+		&i.SnapshotID,
+		&i.NodeMetadata,
+		&i.VerificationStatus,
+		&i.VerificationReport,
+		&i.VerifiedAt,
 	)
 	return &i, err
 }
@@ -5012,22 +7350,38 @@ SET name = ?,
     access_key_id = ?,
     secret_key = ?,
     s3_path_style = ?,
+    local_path = ?,
+    sftp_host = ?,
+    sftp_port = ?,
+    sftp_username = ?,
+    sftp_private_key = ?,
+    sftp_path = ?,
+    sftp_host_key = ?,
+    engine = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, name, bucket_name, region, endpoint, bucket_path, access_key_id, secret_key, s3_path_style, restic_password, type, created_at, updated_at
+RETURNING id, name, bucket_name, region, endpoint, bucket_path, access_key_id, secret_key, s3_path_style, restic_password, type, created_at, updated_at, local_path, sftp_host, sftp_port, sftp_username, sftp_private_key, sftp_path, engine, sftp_host_key
 `
 
 type UpdateBackupTargetParams struct {
-	Name        string         `json:"name"`
-	Type        string         `json:"type"`
-	BucketName  sql.NullString `json:"bucketName"`
-	Region      sql.NullString `json:"region"`
-	Endpoint    sql.NullString `json:"endpoint"`
-	BucketPath  sql.NullString `json:"bucketPath"`
-	AccessKeyID sql.NullString `json:"accessKeyId"`
-	SecretKey   sql.NullString `json:"secretKey"`
-	S3PathStyle sql.NullBool   `json:"s3PathStyle"`
-	ID          int64          `json:"id"`
+	Name           string         `json:"name"`
+	Type           string         `json:"type"`
+	BucketName     sql.NullString `json:"bucketName"`
+	Region         sql.NullString `json:"region"`
+	Endpoint       sql.NullString `json:"endpoint"`
+	BucketPath     sql.NullString `json:"bucketPath"`
+	AccessKeyID    sql.NullString `json:"accessKeyId"`
+	SecretKey      sql.NullString `json:"secretKey"`
+	S3PathStyle    sql.NullBool   `json:"s3PathStyle"`
+	LocalPath      sql.NullString `json:"localPath"`
+	SftpHost       sql.NullString `json:"sftpHost"`
+	SftpPort       sql.NullInt64  `json:"sftpPort"`
+	SftpUsername   sql.NullString `json:"sftpUsername"`
+	SftpPrivateKey sql.NullString `json:"sftpPrivateKey"`
+	SftpPath       sql.NullString `json:"sftpPath"`
+	SftpHostKey    sql.NullString `json:"sftpHostKey"`
+	Engine         string         `json:"engine"`
+	ID             int64          `json:"id"`
 }
 
 func (q *Queries) UpdateBackupTarget(ctx context.Context, arg *UpdateBackupTargetParams) (*BackupTarget, error) {
@@ -5041,6 +7395,14 @@ func (q *Queries) UpdateBackupTarget(ctx context.Context, arg *UpdateBackupTarge
 		arg.AccessKeyID,
 		arg.SecretKey,
 		arg.S3PathStyle,
+		arg.LocalPath,
+		arg.SftpHost,
+		arg.SftpPort,
+		arg.SftpUsername,
+		arg.SftpPrivateKey,
+		arg.SftpPath,
+		arg.SftpHostKey,
+		arg.Engine,
 		arg.ID,
 	)
 	var i BackupTarget
@@ -5058,6 +7420,142 @@ func (q *Queries) UpdateBackupTarget(ctx context.Context, arg *UpdateBackupTarge
 		&i.Type,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.LocalPath,
+		&i.SftpHost,
+		&i.SftpPort,
+		&i.SftpUsername,
+		&i.SftpPrivateKey,
+		&i.SftpPath,
+		&i.Engine,
+		&i.SftpHostKey,
+	)
+	return &i, err
+}
+
+const UpdateBackupTargetSecrets = `-- name: UpdateBackupTargetSecrets :exec
+UPDATE backup_targets
+SET secret_key = ?,
+    restic_password = ?,
+    sftp_private_key = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateBackupTargetSecretsParams struct {
+	SecretKey      sql.NullString `json:"secretKey"`
+	ResticPassword sql.NullString `json:"resticPassword"`
+	SftpPrivateKey sql.NullString `json:"sftpPrivateKey"`
+	ID             int64          `json:"id"`
+}
+
+func (q *Queries) UpdateBackupTargetSecrets(ctx context.Context, arg *UpdateBackupTargetSecretsParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateBackupTargetSecrets,
+		arg.SecretKey,
+		arg.ResticPassword,
+		arg.SftpPrivateKey,
+		arg.ID,
+	)
+	return err
+}
+
+const UpdateBackupVerification = `-- name: UpdateBackupVerification :exec
+UPDATE backups
+SET verification_status = ?,
+    verification_report = ?,
+    verified_at = ?
+WHERE id = ?
+`
+
+type UpdateBackupVerificationParams struct {
+	VerificationStatus sql.NullString `json:"verificationStatus"`
+	VerificationReport sql.NullString `json:"verificationReport"`
+	VerifiedAt         sql.NullTime   `json:"verifiedAt"`
+	ID                 int64          `json:"id"`
+}
+
+func (q *Queries) UpdateBackupVerification(ctx context.Context, arg *UpdateBackupVerificationParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateBackupVerification,
+		arg.VerificationStatus,
+		arg.VerificationReport,
+		arg.VerifiedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const UpdateBesuValidatorChangeStatus = `-- name: UpdateBesuValidatorChangeStatus :one
+UPDATE besu_validator_changes
+SET status = ?,
+    error_message = ?,
+    applied_at = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, network_id, action, address, key_id, status, votes, votes_required, error_message, created_by, created_at, updated_at, applied_at
+`
+
+type UpdateBesuValidatorChangeStatusParams struct {
+	Status       string         `json:"status"`
+	ErrorMessage sql.NullString `json:"errorMessage"`
+	AppliedAt    sql.NullTime   `json:"appliedAt"`
+	ID           int64          `json:"id"`
+}
+
+func (q *Queries) UpdateBesuValidatorChangeStatus(ctx context.Context, arg *UpdateBesuValidatorChangeStatusParams) (*BesuValidatorChange, error) {
+	row := q.db.QueryRowContext(ctx, UpdateBesuValidatorChangeStatus,
+		arg.Status,
+		arg.ErrorMessage,
+		arg.AppliedAt,
+		arg.ID,
+	)
+	var i BesuValidatorChange
+	err := row.Scan(
+		&i.ID,
+		&i.NetworkID,
+		&i.Action,
+		&i.Address,
+		&i.KeyID,
+		&i.Status,
+		&i.Votes,
+		&i.VotesRequired,
+		&i.ErrorMessage,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AppliedAt,
+	)
+	return &i, err
+}
+
+const UpdateBesuValidatorChangeVotes = `-- name: UpdateBesuValidatorChangeVotes :one
+UPDATE besu_validator_changes
+SET votes = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, network_id, action, address, key_id, status, votes, votes_required, error_message, created_by, created_at, updated_at, applied_at
+`
+
+type UpdateBesuValidatorChangeVotesParams struct {
+	Votes sql.NullString `json:"votes"`
+	ID    int64          `json:"id"`
+}
+
+func (q *Queries) UpdateBesuValidatorChangeVotes(ctx context.Context, arg *UpdateBesuValidatorChangeVotesParams) (*BesuValidatorChange, error) {
+	row := q.db.QueryRowContext(ctx, UpdateBesuValidatorChangeVotes, arg.Votes, arg.ID)
+	var i BesuValidatorChange
+	err := row.Scan(
+		&i.ID,
+		&i.NetworkID,
+		&i.Action,
+		&i.Address,
+		&i.KeyID,
+		&i.Status,
+		&i.Votes,
+		&i.VotesRequired,
+		&i.ErrorMessage,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AppliedAt,
 	)
 	return &i, err
 }
@@ -5329,6 +7827,23 @@ func (q *Queries) UpdateKey(ctx context.Context, arg *UpdateKeyParams) (*Key, er
 	return &i, err
 }
 
+const UpdateKeyPrivateKey = `-- name: UpdateKeyPrivateKey :exec
+UPDATE keys
+SET private_key = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateKeyPrivateKeyParams struct {
+	PrivateKey string `json:"privateKey"`
+	ID         int64  `json:"id"`
+}
+
+func (q *Queries) UpdateKeyPrivateKey(ctx context.Context, arg *UpdateKeyPrivateKeyParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateKeyPrivateKey, arg.PrivateKey, arg.ID)
+	return err
+}
+
 const UpdateKeyProvider = `-- name: UpdateKeyProvider :one
 UPDATE key_providers
 SET name = ?,
@@ -5369,6 +7884,23 @@ func (q *Queries) UpdateKeyProvider(ctx context.Context, arg *UpdateKeyProviderP
 	return &i, err
 }
 
+const UpdateNetworkConfig = `-- name: UpdateNetworkConfig :exec
+UPDATE networks
+SET config = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateNetworkConfigParams struct {
+	Config sql.NullString `json:"config"`
+	ID     int64          `json:"id"`
+}
+
+func (q *Queries) UpdateNetworkConfig(ctx context.Context, arg *UpdateNetworkConfigParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateNetworkConfig, arg.Config, arg.ID)
+	return err
+}
+
 const UpdateNetworkCurrentConfigBlock = `-- name: UpdateNetworkCurrentConfigBlock :exec
 UPDATE networks
 SET current_config_block_b64 = ?,
@@ -5758,9 +8290,10 @@ SET type = ?,
     notify_backup_success = ?,
     notify_backup_failure = ?,
     notify_s3_connection_issue = ?,
+    notify_certificate_expiry = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message
+RETURNING id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message, notify_certificate_expiry
 `
 
 type UpdateNotificationProviderParams struct {
@@ -5772,6 +8305,7 @@ type UpdateNotificationProviderParams struct {
 	NotifyBackupSuccess     bool   `json:"notifyBackupSuccess"`
 	NotifyBackupFailure     bool   `json:"notifyBackupFailure"`
 	NotifyS3ConnectionIssue bool   `json:"notifyS3ConnectionIssue"`
+	NotifyCertificateExpiry bool   `json:"notifyCertificateExpiry"`
 	ID                      int64  `json:"id"`
 }
 
@@ -5785,6 +8319,7 @@ func (q *Queries) UpdateNotificationProvider(ctx context.Context, arg *UpdateNot
 		arg.NotifyBackupSuccess,
 		arg.NotifyBackupFailure,
 		arg.NotifyS3ConnectionIssue,
+		arg.NotifyCertificateExpiry,
 		arg.ID,
 	)
 	var i NotificationProvider
@@ -5804,6 +8339,70 @@ func (q *Queries) UpdateNotificationProvider(ctx context.Context, arg *UpdateNot
 		&i.LastTestAt,
 		&i.LastTestStatus,
 		&i.LastTestMessage,
+		&i.NotifyCertificateExpiry,
+	)
+	return &i, err
+}
+
+const UpdateNotificationProviderConfig = `-- name: UpdateNotificationProviderConfig :exec
+UPDATE notification_providers
+SET config = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateNotificationProviderConfigParams struct {
+	Config string `json:"config"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateNotificationProviderConfig(ctx context.Context, arg *UpdateNotificationProviderConfigParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateNotificationProviderConfig, arg.Config, arg.ID)
+	return err
+}
+
+const UpdateOIDCUser = `-- name: UpdateOIDCUser :one
+UPDATE users
+SET name = ?,
+    email = ?,
+    role = ?,
+    avatar_url = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account
+`
+
+type UpdateOIDCUserParams struct {
+	Name      sql.NullString `json:"name"`
+	Email     sql.NullString `json:"email"`
+	Role      sql.NullString `json:"role"`
+	AvatarUrl sql.NullString `json:"avatarUrl"`
+	ID        int64          `json:"id"`
+}
+
+func (q *Queries) UpdateOIDCUser(ctx context.Context, arg *UpdateOIDCUserParams) (*User, error) {
+	row := q.db.QueryRowContext(ctx, UpdateOIDCUser,
+		arg.Name,
+		arg.Email,
+		arg.Role,
+		arg.AvatarUrl,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Password,
+		&i.Name,
+		&i.Email,
+		&i.Role,
+		&i.Provider,
+		&i.ProviderID,
+		&i.AvatarUrl,
+		&i.CreatedAt,
+		&i.LastLoginAt,
+		&i.UpdatedAt,
+		&i.ServiceAccount,
 	)
 	return &i, err
 }
@@ -5935,6 +8534,38 @@ func (q *Queries) UpdatePrometheusConfig(ctx context.Context, arg *UpdatePrometh
 	return &i, err
 }
 
+const UpdateProposalStatus = `-- name: UpdateProposalStatus :one
+UPDATE proposals
+SET status = ?, tx_response = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+RETURNING id, network_id, channel_name, status, operations, preview_json, config_update, tx_response, created_by, created_at, updated_at
+`
+
+type UpdateProposalStatusParams struct {
+	Status     string         `json:"status"`
+	TxResponse sql.NullString `json:"txResponse"`
+	ID         string         `json:"id"`
+}
+
+func (q *Queries) UpdateProposalStatus(ctx context.Context, arg *UpdateProposalStatusParams) (*Proposal, error) {
+	row := q.db.QueryRowContext(ctx, UpdateProposalStatus, arg.Status, arg.TxResponse, arg.ID)
+	var i Proposal
+	err := row.Scan(
+		&i.ID,
+		&i.NetworkID,
+		&i.ChannelName,
+		&i.Status,
+		&i.Operations,
+		&i.PreviewJson,
+		&i.ConfigUpdate,
+		&i.TxResponse,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return &i, err
+}
+
 const UpdateProviderTestResults = `-- name: UpdateProviderTestResults :one
 UPDATE notification_providers
 SET last_test_at = ?,
@@ -5942,7 +8573,7 @@ SET last_test_at = ?,
     last_test_message = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message
+RETURNING id, name, type, config, is_default, is_enabled, created_at, updated_at, notify_node_downtime, notify_backup_success, notify_backup_failure, notify_s3_connection_issue, last_test_at, last_test_status, last_test_message, notify_certificate_expiry
 `
 
 type UpdateProviderTestResultsParams struct {
@@ -5976,6 +8607,7 @@ func (q *Queries) UpdateProviderTestResults(ctx context.Context, arg *UpdateProv
 		&i.LastTestAt,
 		&i.LastTestStatus,
 		&i.LastTestMessage,
+		&i.NotifyCertificateExpiry,
 	)
 	return &i, err
 }
@@ -6011,7 +8643,7 @@ SET username = ?,
     role = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at
+RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account
 `
 
 type UpdateUserParams struct {
@@ -6036,6 +8668,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg *UpdateUserParams) (*User,
 		&i.CreatedAt,
 		&i.LastLoginAt,
 		&i.UpdatedAt,
+		&i.ServiceAccount,
 	)
 	return &i, err
 }
@@ -6045,7 +8678,7 @@ UPDATE users
 SET last_login_at = CURRENT_TIMESTAMP,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at
+RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account
 `
 
 func (q *Queries) UpdateUserLastLogin(ctx context.Context, id int64) (*User, error) {
@@ -6064,6 +8697,7 @@ func (q *Queries) UpdateUserLastLogin(ctx context.Context, id int64) (*User, err
 		&i.CreatedAt,
 		&i.LastLoginAt,
 		&i.UpdatedAt,
+		&i.ServiceAccount,
 	)
 	return &i, err
 }
@@ -6073,7 +8707,7 @@ UPDATE users
 SET password = ?,
     updated_at = CURRENT_TIMESTAMP
 WHERE id = ?
-RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at
+RETURNING id, username, password, name, email, role, provider, provider_id, avatar_url, created_at, last_login_at, updated_at, service_account
 `
 
 type UpdateUserPasswordParams struct {
@@ -6097,6 +8731,164 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg *UpdateUserPasswor
 		&i.CreatedAt,
 		&i.LastLoginAt,
 		&i.UpdatedAt,
+		&i.ServiceAccount,
+	)
+	return &i, err
+}
+
+const UpdateUserTOTPLastUsedStep = `-- name: UpdateUserTOTPLastUsedStep :exec
+UPDATE user_totp
+SET last_used_step = ?
+WHERE user_id = ?
+`
+
+type UpdateUserTOTPLastUsedStepParams struct {
+	LastUsedStep int64 `json:"lastUsedStep"`
+	UserID       int64 `json:"userId"`
+}
+
+func (q *Queries) UpdateUserTOTPLastUsedStep(ctx context.Context, arg *UpdateUserTOTPLastUsedStepParams) error {
+	_, err := q.db.ExecContext(ctx, UpdateUserTOTPLastUsedStep, arg.LastUsedStep, arg.UserID)
+	return err
+}
+
+const UpsertNodeCertificateSettings = `-- name: UpsertNodeCertificateSettings :one
+INSERT INTO node_certificate_settings (node_id, auto_renew)
+VALUES (?, ?)
+ON CONFLICT(node_id) DO UPDATE SET
+    auto_renew = excluded.auto_renew,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING node_id, auto_renew, updated_at
+`
+
+type UpsertNodeCertificateSettingsParams struct {
+	NodeID    int64 `json:"nodeId"`
+	AutoRenew bool  `json:"autoRenew"`
+}
+
+func (q *Queries) UpsertNodeCertificateSettings(ctx context.Context, arg *UpsertNodeCertificateSettingsParams) (*NodeCertificateSetting, error) {
+	row := q.db.QueryRowContext(ctx, UpsertNodeCertificateSettings, arg.NodeID, arg.AutoRenew)
+	var i NodeCertificateSetting
+	err := row.Scan(&i.NodeID, &i.AutoRenew, &i.UpdatedAt)
+	return &i, err
+}
+
+const UpsertNodeCheckRollup = `-- name: UpsertNodeCheckRollup :exec
+INSERT INTO node_check_rollups (node_id, bucket_start, total_checks, up_checks, avg_response_time_ms, max_response_time_ms)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(node_id, bucket_start) DO UPDATE SET
+    total_checks = excluded.total_checks,
+    up_checks = excluded.up_checks,
+    avg_response_time_ms = excluded.avg_response_time_ms,
+    max_response_time_ms = excluded.max_response_time_ms
+`
+
+type UpsertNodeCheckRollupParams struct {
+	NodeID            int64     `json:"nodeId"`
+	BucketStart       time.Time `json:"bucketStart"`
+	TotalChecks       int64     `json:"totalChecks"`
+	UpChecks          int64     `json:"upChecks"`
+	AvgResponseTimeMs int64     `json:"avgResponseTimeMs"`
+	MaxResponseTimeMs int64     `json:"maxResponseTimeMs"`
+}
+
+func (q *Queries) UpsertNodeCheckRollup(ctx context.Context, arg *UpsertNodeCheckRollupParams) error {
+	_, err := q.db.ExecContext(ctx, UpsertNodeCheckRollup,
+		arg.NodeID,
+		arg.BucketStart,
+		arg.TotalChecks,
+		arg.UpChecks,
+		arg.AvgResponseTimeMs,
+		arg.MaxResponseTimeMs,
+	)
+	return err
+}
+
+const UpsertProposalSignature = `-- name: UpsertProposalSignature :one
+INSERT INTO proposal_signatures (proposal_id, msp_id, signed_by, signature)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(proposal_id, msp_id) DO UPDATE SET
+    signed_by = excluded.signed_by,
+    signature = excluded.signature,
+    signed_at = CURRENT_TIMESTAMP
+RETURNING id, proposal_id, msp_id, signed_by, signature, signed_at
+`
+
+type UpsertProposalSignatureParams struct {
+	ProposalID string `json:"proposalId"`
+	MspID      string `json:"mspId"`
+	SignedBy   string `json:"signedBy"`
+	Signature  []byte `json:"signature"`
+}
+
+func (q *Queries) UpsertProposalSignature(ctx context.Context, arg *UpsertProposalSignatureParams) (*ProposalSignature, error) {
+	row := q.db.QueryRowContext(ctx, UpsertProposalSignature,
+		arg.ProposalID,
+		arg.MspID,
+		arg.SignedBy,
+		arg.Signature,
+	)
+	var i ProposalSignature
+	err := row.Scan(
+		&i.ID,
+		&i.ProposalID,
+		&i.MspID,
+		&i.SignedBy,
+		&i.Signature,
+		&i.SignedAt,
+	)
+	return &i, err
+}
+
+const UpsertUserTOTP = `-- name: UpsertUserTOTP :one
+INSERT INTO user_totp (
+    user_id, secret
+) VALUES (
+    ?, ?
+)
+ON CONFLICT(user_id) DO UPDATE SET
+    secret = excluded.secret,
+    enabled = 0,
+    last_used_step = 0,
+    created_at = CURRENT_TIMESTAMP,
+    enabled_at = NULL
+RETURNING user_id, secret, enabled, last_used_step, created_at, enabled_at
+`
+
+type UpsertUserTOTPParams struct {
+	UserID int64  `json:"userId"`
+	Secret string `json:"secret"`
+}
+
+func (q *Queries) UpsertUserTOTP(ctx context.Context, arg *UpsertUserTOTPParams) (*UserTotp, error) {
+	row := q.db.QueryRowContext(ctx, UpsertUserTOTP, arg.UserID, arg.Secret)
+	var i UserTotp
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.Enabled,
+		&i.LastUsedStep,
+		&i.CreatedAt,
+		&i.EnabledAt,
 	)
 	return &i, err
 }
+
+const UseRecoveryCode = `-- name: UseRecoveryCode :execrows
+UPDATE user_recovery_codes
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+`
+
+type UseRecoveryCodeParams struct {
+	UserID   int64  `json:"userId"`
+	CodeHash string `json:"codeHash"`
+}
+
+func (q *Queries) UseRecoveryCode(ctx context.Context, arg *UseRecoveryCodeParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, UseRecoveryCode, arg.UserID, arg.CodeHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
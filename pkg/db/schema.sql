@@ -154,23 +154,26 @@ CREATE TABLE fabric_org_nodes (
 
 CREATE TABLE proposals (
     id TEXT PRIMARY KEY,
-    network_id INTEGER NOT NULL REFERENCES networks(id),
+    network_id INTEGER NOT NULL REFERENCES networks(id) ON DELETE CASCADE,
     channel_name TEXT NOT NULL,
     status TEXT NOT NULL,
-    operations JSON NOT NULL,
+    operations TEXT NOT NULL,
     preview_json TEXT,
-    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    config_update BLOB NOT NULL,
+    tx_response TEXT,
     created_by TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP
 );
 
 CREATE TABLE proposal_signatures (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
-    proposal_id TEXT NOT NULL REFERENCES proposals(id),
+    proposal_id TEXT NOT NULL REFERENCES proposals(id) ON DELETE CASCADE,
     msp_id TEXT NOT NULL,
     signed_by TEXT NOT NULL,
+    signature BLOB NOT NULL,
     signed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    signature BLOB NOT NULL
+    UNIQUE(proposal_id, msp_id)
 );
 
 CREATE INDEX idx_proposals_network_id ON proposals(network_id);
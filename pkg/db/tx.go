@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// RunInTx runs fn with a Queries bound to a new transaction. The transaction is
+// committed if fn returns nil and rolled back otherwise.
+func (q *Queries) RunInTx(ctx context.Context, fn func(*Queries) error) error {
+	conn, ok := q.db.(*sql.DB)
+	if !ok {
+		return fmt.Errorf("queries are not bound to a database connection")
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := fn(q.WithTx(tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
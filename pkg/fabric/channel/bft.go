@@ -0,0 +1,241 @@
+package channel
+
+import (
+	"fmt"
+
+	"github.com/chainlaunch/chainlaunch/internal/protoutil"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	ob "github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer/smartbft"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// ConsensusTypeEtcdRaft is the Raft consensus type
+	ConsensusTypeEtcdRaft = "etcdraft"
+	// ConsensusTypeBFT is the SmartBFT consensus type introduced in Fabric 3.0
+	ConsensusTypeBFT = "BFT"
+
+	ordererGroupKey       = "Orderer"
+	consensusTypeKey      = "ConsensusType"
+	orderersKey           = "Orderers"
+	capabilitiesKey       = "Capabilities"
+	adminsPolicyKey       = "Admins"
+	channelCapabilityV3_0 = "V3_0"
+)
+
+// DefaultSmartBFTOptions returns the SmartBFT options used by Fabric when none are configured
+func DefaultSmartBFTOptions() *smartbft.Options {
+	return &smartbft.Options{
+		RequestBatchMaxCount:      100,
+		RequestBatchMaxBytes:      10 * 1024 * 1024,
+		RequestBatchMaxInterval:   "50ms",
+		IncomingMessageBufferSize: 200,
+		RequestPoolSize:           100000,
+		RequestForwardTimeout:     "2s",
+		RequestComplainTimeout:    "20s",
+		RequestAutoRemoveTimeout:  "3m0s",
+		ViewChangeResendInterval:  "5s",
+		ViewChangeTimeout:         "20s",
+		LeaderHeartbeatTimeout:    "1m0s",
+		LeaderHeartbeatCount:      10,
+		CollectTimeout:            "1s",
+		SyncOnStart:               true,
+		SpeedUpViewChange:         false,
+		LeaderRotation:            smartbft.Options_ROTATION_OFF,
+		DecisionsPerLeader:        3,
+		RequestMaxBytes:           10 * 1024 * 1024,
+	}
+}
+
+// ordererGroup returns the orderer group of a channel config
+func ordererGroup(config *cb.Config) (*cb.ConfigGroup, error) {
+	if config == nil || config.ChannelGroup == nil {
+		return nil, fmt.Errorf("channel config is empty")
+	}
+	group, ok := config.ChannelGroup.Groups[ordererGroupKey]
+	if !ok {
+		return nil, fmt.Errorf("channel config has no orderer group")
+	}
+	return group, nil
+}
+
+// getConsensusType returns the consensus type value of the orderer group
+func getConsensusType(config *cb.Config) (*ob.ConsensusType, error) {
+	group, err := ordererGroup(config)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := group.Values[consensusTypeKey]
+	if !ok {
+		return nil, fmt.Errorf("orderer group has no consensus type")
+	}
+	consensusType := &ob.ConsensusType{}
+	if err := proto.Unmarshal(value.Value, consensusType); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal consensus type: %w", err)
+	}
+	return consensusType, nil
+}
+
+// setConsensusType sets the consensus type value of the orderer group
+func setConsensusType(config *cb.Config, consensusType *ob.ConsensusType) error {
+	group, err := ordererGroup(config)
+	if err != nil {
+		return err
+	}
+	return setValue(group, consensusTypeKey, consensusType)
+}
+
+// setValue sets a value of a config group, keeping its mod policy if it already exists
+func setValue(group *cb.ConfigGroup, key string, value proto.Message) error {
+	valueBytes, err := proto.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+	if existing, ok := group.Values[key]; ok {
+		existing.Value = valueBytes
+		return nil
+	}
+	if group.Values == nil {
+		group.Values = map[string]*cb.ConfigValue{}
+	}
+	group.Values[key] = &cb.ConfigValue{
+		Value:     valueBytes,
+		ModPolicy: adminsPolicyKey,
+	}
+	return nil
+}
+
+// OrdererConsensusType returns the consensus type of the ordering service of a channel
+func OrdererConsensusType(config *cb.Config) (string, error) {
+	consensusType, err := getConsensusType(config)
+	if err != nil {
+		return "", err
+	}
+	return consensusType.Type, nil
+}
+
+// BFTConsenters returns the consenter mapping of a BFT channel
+func BFTConsenters(config *cb.Config) ([]*cb.Consenter, error) {
+	group, err := ordererGroup(config)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := group.Values[orderersKey]
+	if !ok {
+		return nil, fmt.Errorf("orderer group has no consenter mapping")
+	}
+	orderers := &cb.Orderers{}
+	if err := proto.Unmarshal(value.Value, orderers); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal consenter mapping: %w", err)
+	}
+	return orderers.ConsenterMapping, nil
+}
+
+// SetBFTConsenters sets the consenter mapping of a BFT channel
+func SetBFTConsenters(config *cb.Config, consenters []*cb.Consenter) error {
+	if len(consenters) == 0 {
+		return fmt.Errorf("a BFT channel requires at least one consenter")
+	}
+	group, err := ordererGroup(config)
+	if err != nil {
+		return err
+	}
+	return setValue(group, orderersKey, &cb.Orderers{ConsenterMapping: consenters})
+}
+
+// SmartBFTOptions returns the SmartBFT options of a BFT channel
+func SmartBFTOptions(config *cb.Config) (*smartbft.Options, error) {
+	consensusType, err := getConsensusType(config)
+	if err != nil {
+		return nil, err
+	}
+	if consensusType.Type != ConsensusTypeBFT {
+		return nil, fmt.Errorf("channel consensus type is %s, not %s", consensusType.Type, ConsensusTypeBFT)
+	}
+	options := &smartbft.Options{}
+	if err := proto.Unmarshal(consensusType.Metadata, options); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal SmartBFT options: %w", err)
+	}
+	return options, nil
+}
+
+// SetSmartBFTOptions sets the SmartBFT options of a BFT channel
+func SetSmartBFTOptions(config *cb.Config, options *smartbft.Options) error {
+	consensusType, err := getConsensusType(config)
+	if err != nil {
+		return err
+	}
+	if consensusType.Type != ConsensusTypeBFT {
+		return fmt.Errorf("channel consensus type is %s, not %s", consensusType.Type, ConsensusTypeBFT)
+	}
+	metadata, err := proto.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SmartBFT options: %w", err)
+	}
+	consensusType.Metadata = metadata
+	return setConsensusType(config, consensusType)
+}
+
+// convertToBFT switches the ordering service of a channel config to BFT, with the
+// given consenters and options, and enables the V3_0 channel capability BFT requires
+func convertToBFT(config *cb.Config, consenters []*cb.Consenter, options *smartbft.Options) error {
+	if options == nil {
+		options = DefaultSmartBFTOptions()
+	}
+	metadata, err := proto.Marshal(options)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SmartBFT options: %w", err)
+	}
+	consensusType, err := getConsensusType(config)
+	if err != nil {
+		return err
+	}
+	consensusType.Type = ConsensusTypeBFT
+	consensusType.Metadata = metadata
+	if err := setConsensusType(config, consensusType); err != nil {
+		return err
+	}
+	if err := SetBFTConsenters(config, consenters); err != nil {
+		return err
+	}
+	return setValue(config.ChannelGroup, capabilitiesKey, &cb.Capabilities{
+		Capabilities: map[string]*cb.Capability{channelCapabilityV3_0: {}},
+	})
+}
+
+// convertGenesisBlockToBFT rewrites the config of a genesis block to use BFT consensus
+func convertGenesisBlockToBFT(block *cb.Block, consenters []*cb.Consenter, options *smartbft.Options) error {
+	if block == nil || block.Data == nil || len(block.Data.Data) == 0 {
+		return fmt.Errorf("invalid genesis block")
+	}
+	envelope := &cb.Envelope{}
+	if err := proto.Unmarshal(block.Data.Data[0], envelope); err != nil {
+		return fmt.Errorf("failed to unmarshal envelope: %w", err)
+	}
+	payload := &cb.Payload{}
+	if err := proto.Unmarshal(envelope.Payload, payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+	configEnvelope := &cb.ConfigEnvelope{}
+	if err := proto.Unmarshal(payload.Data, configEnvelope); err != nil {
+		return fmt.Errorf("failed to unmarshal config envelope: %w", err)
+	}
+
+	if err := convertToBFT(configEnvelope.Config, consenters, options); err != nil {
+		return err
+	}
+
+	var err error
+	if payload.Data, err = proto.Marshal(configEnvelope); err != nil {
+		return fmt.Errorf("failed to marshal config envelope: %w", err)
+	}
+	if envelope.Payload, err = proto.Marshal(payload); err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	if block.Data.Data[0], err = proto.Marshal(envelope); err != nil {
+		return fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+	block.Header.DataHash = protoutil.BlockDataHash(block.Data)
+	return nil
+}
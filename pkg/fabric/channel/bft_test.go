@@ -0,0 +1,214 @@
+package channel
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/internal/protoutil"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	ob "github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer/smartbft"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestOrdererConfig returns a channel config whose orderer group only holds the
+// consensus type
+func newTestOrdererConfig(t *testing.T, consensusType string) *cb.Config {
+	value, err := proto.Marshal(&ob.ConsensusType{Type: consensusType, Metadata: []byte("raft")})
+	if err != nil {
+		t.Fatalf("failed to marshal consensus type: %v", err)
+	}
+	return &cb.Config{ChannelGroup: &cb.ConfigGroup{
+		Groups: map[string]*cb.ConfigGroup{
+			ordererGroupKey: {Values: map[string]*cb.ConfigValue{
+				consensusTypeKey: {Value: value, ModPolicy: "Admins"},
+			}},
+		},
+	}}
+}
+
+func newTestConsenters() []*cb.Consenter {
+	return []*cb.Consenter{
+		{Id: 1, Host: "orderer0", Port: 7050, MspId: "OrdererMSP", Identity: []byte("id0")},
+		{Id: 2, Host: "orderer1", Port: 7050, MspId: "OrdererMSP", Identity: []byte("id1")},
+	}
+}
+
+func TestConvertToBFT(t *testing.T) {
+	config := newTestOrdererConfig(t, ConsensusTypeEtcdRaft)
+	if _, err := SmartBFTOptions(config); err == nil {
+		t.Error("expected an error reading SmartBFT options of an etcdraft channel")
+	}
+	if err := SetSmartBFTOptions(config, DefaultSmartBFTOptions()); err == nil {
+		t.Error("expected an error setting SmartBFT options of an etcdraft channel")
+	}
+
+	if err := convertToBFT(config, newTestConsenters(), nil); err != nil {
+		t.Fatalf("failed to convert to BFT: %v", err)
+	}
+	if consensusType, err := OrdererConsensusType(config); err != nil || consensusType != ConsensusTypeBFT {
+		t.Errorf("expected BFT consensus, got %q (%v)", consensusType, err)
+	}
+	options, err := SmartBFTOptions(config)
+	if err != nil {
+		t.Fatalf("failed to read SmartBFT options: %v", err)
+	}
+	if !proto.Equal(options, DefaultSmartBFTOptions()) {
+		t.Errorf("expected the default options, got %v", options)
+	}
+	consenters, err := BFTConsenters(config)
+	if err != nil || len(consenters) != 2 || consenters[1].Host != "orderer1" {
+		t.Errorf("unexpected consenters %v (%v)", consenters, err)
+	}
+	capabilities := &cb.Capabilities{}
+	if err := proto.Unmarshal(config.ChannelGroup.Values[capabilitiesKey].Value, capabilities); err != nil {
+		t.Fatalf("failed to unmarshal capabilities: %v", err)
+	}
+	if _, ok := capabilities.Capabilities[channelCapabilityV3_0]; !ok {
+		t.Errorf("expected the V3_0 capability, got %v", capabilities.Capabilities)
+	}
+	if config.ChannelGroup.Groups[ordererGroupKey].Values[consensusTypeKey].ModPolicy != "Admins" {
+		t.Error("existing mod policy should be kept")
+	}
+
+	// Options and consenters can be updated once the channel is BFT
+	options.RequestBatchMaxCount = 10
+	if err := SetSmartBFTOptions(config, options); err != nil {
+		t.Fatalf("failed to set SmartBFT options: %v", err)
+	}
+	if updated, err := SmartBFTOptions(config); err != nil || updated.RequestBatchMaxCount != 10 {
+		t.Errorf("options not updated: %v (%v)", updated, err)
+	}
+	if err := SetBFTConsenters(config, newTestConsenters()[:1]); err != nil {
+		t.Fatalf("failed to set consenters: %v", err)
+	}
+	if consenters, err := BFTConsenters(config); err != nil || len(consenters) != 1 {
+		t.Errorf("unexpected consenters %v (%v)", consenters, err)
+	}
+}
+
+func TestBFTConfigErrors(t *testing.T) {
+	noConsensus := newTestOrdererConfig(t, ConsensusTypeBFT)
+	delete(noConsensus.ChannelGroup.Groups[ordererGroupKey].Values, consensusTypeKey)
+	corrupt := newTestOrdererConfig(t, ConsensusTypeBFT)
+	corrupt.ChannelGroup.Groups[ordererGroupKey].Values[consensusTypeKey].Value = []byte{0xff}
+
+	configs := map[string]*cb.Config{
+		"nil config":        nil,
+		"empty config":      {},
+		"no orderer group":  {ChannelGroup: &cb.ConfigGroup{}},
+		"no consensus type": noConsensus,
+		"corrupt consensus": corrupt,
+	}
+	for name, config := range configs {
+		if _, err := OrdererConsensusType(config); err == nil {
+			t.Errorf("%s: expected an error reading the consensus type", name)
+		}
+		if _, err := SmartBFTOptions(config); err == nil {
+			t.Errorf("%s: expected an error reading SmartBFT options", name)
+		}
+		if err := convertToBFT(config, newTestConsenters(), nil); err == nil {
+			t.Errorf("%s: expected an error converting to BFT", name)
+		}
+	}
+
+	config := newTestOrdererConfig(t, ConsensusTypeBFT)
+	if _, err := BFTConsenters(config); err == nil {
+		t.Error("expected an error for a channel without consenter mapping")
+	}
+	if err := SetBFTConsenters(config, nil); err == nil {
+		t.Error("expected an error setting no consenters")
+	}
+	if err := convertToBFT(newTestOrdererConfig(t, ConsensusTypeEtcdRaft), nil, nil); err == nil {
+		t.Error("expected an error converting without consenters")
+	}
+}
+
+func TestConvertGenesisBlockToBFT(t *testing.T) {
+	configEnvelope, err := proto.Marshal(&cb.ConfigEnvelope{Config: newTestOrdererConfig(t, ConsensusTypeEtcdRaft)})
+	if err != nil {
+		t.Fatalf("failed to marshal config envelope: %v", err)
+	}
+	payload, err := proto.Marshal(&cb.Payload{Header: &cb.Header{}, Data: configEnvelope})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+	envelope, err := proto.Marshal(&cb.Envelope{Payload: payload})
+	if err != nil {
+		t.Fatalf("failed to marshal envelope: %v", err)
+	}
+	block := protoutil.NewBlock(0, nil)
+	block.Data.Data = [][]byte{envelope}
+	block.Header.DataHash = protoutil.BlockDataHash(block.Data)
+	originalHash := block.Header.DataHash
+
+	options := DefaultSmartBFTOptions()
+	options.LeaderRotation = smartbft.Options_ROTATION_ON
+	if err := convertGenesisBlockToBFT(block, newTestConsenters(), options); err != nil {
+		t.Fatalf("failed to convert genesis block: %v", err)
+	}
+	if bytes.Equal(block.Header.DataHash, originalHash) || !bytes.Equal(block.Header.DataHash, protoutil.BlockDataHash(block.Data)) {
+		t.Error("data hash should be recomputed")
+	}
+
+	converted, err := SmartBFTOptions(readTestBlockConfig(t, block))
+	if err != nil {
+		t.Fatalf("failed to read SmartBFT options: %v", err)
+	}
+	if converted.LeaderRotation != smartbft.Options_ROTATION_ON {
+		t.Errorf("options not applied: %v", converted)
+	}
+
+	invalid := map[string]*cb.Block{
+		"nil block":    nil,
+		"no data":      {Header: &cb.BlockHeader{}},
+		"empty data":   {Header: &cb.BlockHeader{}, Data: &cb.BlockData{}},
+		"not envelope": {Header: &cb.BlockHeader{}, Data: &cb.BlockData{Data: [][]byte{{0xff}}}},
+	}
+	for name, block := range invalid {
+		if err := convertGenesisBlockToBFT(block, newTestConsenters(), nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func readTestBlockConfig(t *testing.T, block *cb.Block) *cb.Config {
+	envelope, err := protoutil.UnmarshalEnvelope(block.Data.Data[0])
+	if err != nil {
+		t.Fatalf("failed to unmarshal envelope: %v", err)
+	}
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	configEnvelope := &cb.ConfigEnvelope{}
+	if err := proto.Unmarshal(payload.Data, configEnvelope); err != nil {
+		t.Fatalf("failed to unmarshal config envelope: %v", err)
+	}
+	return configEnvelope.Config
+}
+
+func TestCreateBFTChannelValidation(t *testing.T) {
+	consenter := AddressWithCerts{Address: HostPort{Host: "orderer0", Port: 7050}, MSPID: "OrdererMSP", Identity: "not a certificate"}
+	cases := map[string]struct {
+		input    CreateChannelInput
+		contains string
+	}{
+		"unknown consensus": {CreateChannelInput{ConsensusType: "solo"}, "unsupported consensus type"},
+		"no msp id": {CreateChannelInput{
+			ConsensusType: ConsensusTypeBFT,
+			Consenters:    []AddressWithCerts{{Address: HostPort{Host: "orderer0", Port: 7050}}},
+		}, "both are required for BFT"},
+		"invalid identity": {CreateChannelInput{
+			ConsensusType: ConsensusTypeBFT,
+			Consenters:    []AddressWithCerts{consenter},
+		}, "failed to parse identity"},
+	}
+	for name, c := range cases {
+		_, err := NewChannelService().parseAndCreateChannel(c.input)
+		if err == nil || !strings.Contains(err.Error(), c.contains) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, c.contains, err)
+		}
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/hyperledger/fabric-config/configtx/orderer"
 	"github.com/hyperledger/fabric-config/protolator"
 	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer/smartbft"
 
 	"github.com/chainlaunch/chainlaunch/internal/protoutil"
 	"google.golang.org/protobuf/proto"
@@ -51,6 +52,9 @@ type AddressWithCerts struct {
 	Address       HostPort `json:"address"`
 	ClientTLSCert string   `json:"clientTLSCert"`
 	ServerTLSCert string   `json:"serverTLSCert"`
+	// MSPID and Identity (PEM signing certificate) of the consenter, required for BFT
+	MSPID    string `json:"mspId,omitempty"`
+	Identity string `json:"identity,omitempty"`
 }
 
 // CreateChannelInput represents the input for creating a new channel
@@ -59,6 +63,9 @@ type CreateChannelInput struct {
 	PeerOrgs    []Organization     `json:"peerOrgs"`
 	OrdererOrgs []Organization     `json:"ordererOrgs"`
 	Consenters  []AddressWithCerts `json:"consenters"`
+	// ConsensusType is either etcdraft (default) or BFT
+	ConsensusType   string            `json:"consensusType,omitempty"`
+	SmartBFTOptions *smartbft.Options `json:"smartBftOptions,omitempty"`
 }
 
 // SetAnchorPeersInput represents the input for setting anchor peers
@@ -229,6 +236,31 @@ func (s *ChannelService) DecodeBlock(blockB64 string) (map[string]interface{}, e
 // Helper functions below...
 
 func (s *ChannelService) parseAndCreateChannel(input CreateChannelInput) ([]byte, error) {
+	var bftConsenters []*cb.Consenter
+	switch input.ConsensusType {
+	case "", ConsensusTypeEtcdRaft:
+	case ConsensusTypeBFT:
+		for i, cons := range input.Consenters {
+			if cons.MSPID == "" || cons.Identity == "" {
+				return nil, fmt.Errorf("consenter %s:%d has no MSP ID or identity, both are required for BFT", cons.Address.Host, cons.Address.Port)
+			}
+			if _, err := parseCertificate(cons.Identity); err != nil {
+				return nil, fmt.Errorf("failed to parse identity for consenter %s: %w", cons.Address.Host, err)
+			}
+			bftConsenters = append(bftConsenters, &cb.Consenter{
+				Id:            uint32(i + 1),
+				Host:          cons.Address.Host,
+				Port:          uint32(cons.Address.Port),
+				MspId:         cons.MSPID,
+				Identity:      []byte(cons.Identity),
+				ClientTlsCert: []byte(cons.ClientTLSCert),
+				ServerTlsCert: []byte(cons.ServerTLSCert),
+			})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported consensus type: %s", input.ConsensusType)
+	}
+
 	// Parse organizations
 	peerOrgs := []configtx.Organization{}
 	for _, org := range input.PeerOrgs {
@@ -497,6 +529,14 @@ func (s *ChannelService) parseAndCreateChannel(input CreateChannelInput) ([]byte
 		return nil, fmt.Errorf("failed to create genesis block: %w", err)
 	}
 
+	// The block is generated with etcdraft and then switched to BFT, replacing the
+	// consensus metadata with the SmartBFT options and adding the consenter mapping
+	if input.ConsensusType == ConsensusTypeBFT {
+		if err := convertGenesisBlockToBFT(block, bftConsenters, input.SmartBFTOptions); err != nil {
+			return nil, fmt.Errorf("failed to configure BFT consensus: %w", err)
+		}
+	}
+
 	// Marshal the block
 	blockBytes, err := proto.Marshal(block)
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
 	"github.com/chainlaunch/chainlaunch/pkg/errors"
 	"github.com/chainlaunch/chainlaunch/pkg/fabric/service"
 	"github.com/chainlaunch/chainlaunch/pkg/http/response"
@@ -59,21 +60,23 @@ type ListOrganizationsQuery struct {
 	Offset int64 `form:"offset" json:"offset" query:"offset" example:"0"`
 }
 
-// RegisterRoutes registers the organization routes
+// RegisterRoutes registers the organization routes. Changing an organization
+// requires the manager role globally or on the organization.
 func (h *OrganizationHandler) RegisterRoutes(r chi.Router) {
+	manageOrg := auth.RequireResourceRole(auth.ResourceOrganization, "id", auth.RoleManager)
 	r.Route("/organizations", func(r chi.Router) {
-		r.Post("/", response.Middleware(h.CreateOrganization))
+		r.With(auth.RequireRole(auth.RoleManager)).Post("/", response.Middleware(h.CreateOrganization))
 		r.Get("/", response.Middleware(h.ListOrganizations))
 		r.Get("/by-mspid/{mspid}", response.Middleware(h.GetOrganizationByMspID))
 		r.Get("/{id}", response.Middleware(h.GetOrganization))
-		r.Put("/{id}", response.Middleware(h.UpdateOrganization))
-		r.Delete("/{id}", response.Middleware(h.DeleteOrganization))
+		r.With(manageOrg).Put("/{id}", response.Middleware(h.UpdateOrganization))
+		r.With(manageOrg).Delete("/{id}", response.Middleware(h.DeleteOrganization))
 
 		// Add CRL-related routes
 		r.Route("/{id}/crl", func(r chi.Router) {
-			r.Post("/revoke/serial", response.Middleware(h.RevokeCertificateBySerial))
-			r.Post("/revoke/pem", response.Middleware(h.RevokeCertificateByPEM))
-			r.Delete("/revoke/serial", response.Middleware(h.DeleteRevokedCertificate))
+			r.With(manageOrg).Post("/revoke/serial", response.Middleware(h.RevokeCertificateBySerial))
+			r.With(manageOrg).Post("/revoke/pem", response.Middleware(h.RevokeCertificateByPEM))
+			r.With(manageOrg).Delete("/revoke/serial", response.Middleware(h.DeleteRevokedCertificate))
 			r.Get("/", response.Middleware(h.GetCRL))
 		})
 		r.Get("/{id}/revoked-certificates", response.Middleware(h.GetRevokedCertificates))
@@ -2,7 +2,6 @@ package service
 
 import (
 	"context"
-	"crypto"
 	"crypto/rand"
 	"crypto/x509"
 	"crypto/x509/pkix"
@@ -498,22 +497,10 @@ func (s *OrganizationService) GetCRL(ctx context.Context, orgID int64) ([]byte,
 		return nil, fmt.Errorf("failed to parse certificate: %w", err)
 	}
 
-	// Get private key from key management service
-	privateKeyPEM, err := s.keyManagement.GetDecryptedPrivateKey(int(org.SignKeyID.Int64))
+	// The CA key signs in its provider, it may not be exportable
+	signer, err := s.keyManagement.GetSigner(ctx, int(org.SignKeyID.Int64))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get private key: %w", err)
-	}
-
-	// Parse the private key
-	priv, err := gwidentity.PrivateKeyFromPEM([]byte(privateKeyPEM))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
-	}
-
-	// Cast private key to crypto.Signer
-	signer, ok := priv.(crypto.Signer)
-	if !ok {
-		return nil, fmt.Errorf("private key does not implement crypto.Signer")
+		return nil, fmt.Errorf("failed to get signer: %w", err)
 	}
 
 	// Create CRL
@@ -71,8 +71,10 @@ func WriteError(w http.ResponseWriter, err error) {
 			statusCode = http.StatusBadRequest
 		case errors.NotFoundError:
 			statusCode = http.StatusNotFound
-		case errors.AuthorizationError:
+		case errors.AuthenticationError:
 			statusCode = http.StatusUnauthorized
+		case errors.AuthorizationError:
+			statusCode = http.StatusForbidden
 		case errors.ConflictError:
 			statusCode = http.StatusConflict
 		case errors.DatabaseError:
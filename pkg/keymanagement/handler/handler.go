@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
 	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/models"
 	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/service"
 	"github.com/go-chi/chi/v5"
@@ -77,23 +79,29 @@ func (h *KeyManagementHandler) CreateKey(w http.ResponseWriter, r *http.Request)
 	render.JSON(w, r, key)
 }
 
-// Register routes
+// Register routes. Creating keys requires the manager role, using or deleting
+// a key requires it globally, on the key or on an organization using the key.
+// Rekeying and managing key providers affect every key and require the admin
+// role.
 func (h *KeyManagementHandler) RegisterRoutes(r chi.Router) {
+	requireManager := auth.RequireRole(auth.RoleManager)
+	requireAdmin := auth.RequireRole(auth.RoleAdmin)
 	r.Route("/keys", func(r chi.Router) {
 		r.Get("/all", h.GetAllKeys)
-		r.Post("/", h.CreateKey)
+		r.With(requireManager).Post("/", h.CreateKey)
 		r.Get("/", h.GetKeys)
 		r.Get("/{id}", h.GetKey)
-		r.Delete("/{id}", h.DeleteKey)
-		r.Post("/{keyID}/sign", h.SignCertificate)
+		r.With(auth.RequireResourceRole(auth.ResourceKey, "id", auth.RoleManager)).Delete("/{id}", h.DeleteKey)
+		r.With(auth.RequireResourceRole(auth.ResourceKey, "keyID", auth.RoleManager)).Post("/{keyID}/sign", h.SignCertificate)
 		r.Get("/filter", h.FilterKeys)
+		r.With(requireAdmin).Post("/rekey", h.RekeyKeys)
 	})
 
 	r.Route("/key-providers", func(r chi.Router) {
-		r.Post("/", h.CreateProvider)
+		r.With(requireAdmin).Post("/", h.CreateProvider)
 		r.Get("/", h.ListProviders)
 		r.Get("/{id}", h.GetProvider)
-		r.Delete("/{id}", h.DeleteProvider)
+		r.With(requireAdmin).Delete("/{id}", h.DeleteProvider)
 	})
 }
 
@@ -196,6 +204,26 @@ func (h *KeyManagementHandler) DeleteKey(w http.ResponseWriter, r *http.Request)
 	render.Status(r, http.StatusNoContent)
 }
 
+// @Summary Re-encrypt private keys
+// @Description Re-encrypt all private keys of database providers, and the secrets stored by other services such as backup target, SMTP and CouchDB passwords, with the active key encryption key. Everything is updated in a single transaction.
+// @Tags Keys
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.RekeyResponse
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /keys/rekey [post]
+// @BasePath /api/v1
+func (h *KeyManagementHandler) RekeyKeys(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.RekeyDatabaseKeys(r.Context())
+	if err != nil {
+		render.Status(r, http.StatusInternalServerError)
+		render.JSON(w, r, map[string]string{"error": err.Error()})
+		return
+	}
+
+	render.JSON(w, r, resp)
+}
+
 // @Summary Create a new key provider
 // @Description Create a new provider for key management
 // @Tags Providers
@@ -307,7 +335,7 @@ func (h *KeyManagementHandler) DeleteProvider(w http.ResponseWriter, r *http.Req
 }
 
 // @Summary Sign a certificate
-// @Description Sign a certificate for a key using a CA key
+// @Description Sign a certificate for a key using a CA key. The manager role is required on both keys.
 // @Tags Keys
 // @Accept json
 // @Produce json
@@ -315,6 +343,7 @@ func (h *KeyManagementHandler) DeleteProvider(w http.ResponseWriter, r *http.Req
 // @Param request body object true "Certificate signing request" SchemaExample({"caKeyId":1,"certificate":{"commonName":"example.com","organization":["Example Org"],"validFor":"8760h"}})
 // @Success 200 {object} models.KeyResponse
 // @Failure 400 {object} map[string]string "Invalid request"
+// @Failure 403 {object} map[string]string "Requires manager role on the key and the CA key"
 // @Failure 404 {object} map[string]string "Key not found"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /keys/{keyID}/sign [post]
@@ -338,6 +367,16 @@ func (h *KeyManagementHandler) SignCertificate(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	// Signing uses the CA key, so the role is required on it as well
+	if err := auth.Authorize(r.Context(), auth.ResourceKey, int64(req.CAKeyID), auth.RoleManager); err != nil {
+		if errors.Is(err, auth.ErrForbidden) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	// Sign certificate
 	key, err := h.service.SignCertificate(r.Context(), keyID, req.CAKeyID, req.Cert)
 	if err != nil {
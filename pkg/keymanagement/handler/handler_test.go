@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/go-chi/chi/v5"
+)
+
+func newTestQueries(t *testing.T) *db.Queries {
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	driver, err := sqlite3.WithInstance(database, &sqlite3.Config{})
+	if err != nil {
+		t.Fatalf("failed to create sqlite driver: %v", err)
+	}
+	source, err := iofs.New(os.DirFS("../../db/migrations"), ".")
+	if err != nil {
+		t.Fatalf("failed to open migrations: %v", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", driver)
+	if err != nil {
+		t.Fatalf("failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return db.New(database)
+}
+
+func TestSignCertificateRequiresRoleOnCAKey(t *testing.T) {
+	ctx := context.Background()
+	authService := auth.NewAuthService(newTestQueries(t))
+	user, err := authService.CreateUser(ctx, &auth.CreateUserRequest{Username: "operator", Password: "operator-password", Role: auth.RoleViewer})
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	// The user manages key 1 only
+	if _, err := authService.CreateRoleBinding(ctx, user.ID, 0, &auth.CreateRoleBindingRequest{
+		ResourceType: auth.ResourceKey, ResourceID: 1, Role: auth.RoleManager,
+	}); err != nil {
+		t.Fatalf("failed to bind role: %v", err)
+	}
+
+	router := chi.NewRouter()
+	router.Use(auth.AuthMiddleware(authService))
+	NewKeyManagementHandler(nil).RegisterRoutes(router)
+
+	sign := func(path, body string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.SetBasicAuth("operator", "operator-password")
+		rec := httptest.NewRecorder()
+		func() {
+			// Authorized requests reach the handler without a service
+			defer func() { recover() }()
+			router.ServeHTTP(rec, req)
+		}()
+		return rec.Code
+	}
+
+	cases := map[string]struct {
+		path      string
+		body      string
+		forbidden bool
+	}{
+		"key not managed":    {"/keys/2/sign", `{"caKeyId":1}`, true},
+		"CA key not managed": {"/keys/1/sign", `{"caKeyId":2}`, true},
+		"both keys managed":  {"/keys/1/sign", `{"caKeyId":1}`, false},
+	}
+	for name, c := range cases {
+		if code := sign(c.path, c.body); (code == http.StatusForbidden) != c.forbidden {
+			t.Errorf("%s: expected forbidden %v, got status %d", name, c.forbidden, code)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Package kek loads the key encryption keys that protect private keys stored
+// by the database key provider.
+//
+// The active key is read from KEY_ENCRYPTION_KEY or, if that is not set, from
+// the file named by KEY_ENCRYPTION_KEY_FILE. Keys that were active before a
+// rotation are listed, comma separated, in KEY_ENCRYPTION_KEY_PREVIOUS so that
+// existing rows keep working until they have been rekeyed.
+//
+// Every value is either a hex encoded AES key or a HashiCorp Vault Transit
+// ciphertext ("vault:v1:...") wrapping the hex encoded key. Wrapped keys are
+// unwrapped at startup with the Transit key KEY_ENCRYPTION_KEY_TRANSIT_KEY
+// (default "chainlaunch-kek", mount KEY_ENCRYPTION_KEY_TRANSIT_MOUNT, default
+// "transit") using the standard VAULT_ADDR, VAULT_TOKEN (or VAULT_ROLE_ID and
+// VAULT_SECRET_ID), VAULT_NAMESPACE and VAULT_CACERT variables.
+package kek
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/database"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/vault"
+)
+
+const (
+	EnvKey          = "KEY_ENCRYPTION_KEY"
+	EnvKeyFile      = "KEY_ENCRYPTION_KEY_FILE"
+	EnvPreviousKeys = "KEY_ENCRYPTION_KEY_PREVIOUS"
+
+	envTransitMount = "KEY_ENCRYPTION_KEY_TRANSIT_MOUNT"
+	envTransitKey   = "KEY_ENCRYPTION_KEY_TRANSIT_KEY"
+)
+
+// Configured reports whether a key encryption key is provided through the environment
+func Configured() bool {
+	return os.Getenv(EnvKey) != "" || os.Getenv(EnvKeyFile) != ""
+}
+
+// Load builds the key ring from the environment
+func Load(ctx context.Context) (*database.KeyRing, error) {
+	value, err := activeValue()
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := resolve(ctx, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key encryption key: %w", err)
+	}
+
+	var previous [][]byte
+	for _, value := range strings.Split(os.Getenv(EnvPreviousKeys), ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		key, err := resolve(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous key encryption key: %w", err)
+		}
+		previous = append(previous, key)
+	}
+
+	return database.NewKeyRing(active, previous...)
+}
+
+// PersistActive writes the active key encryption key, as configured (hex or
+// Vault wrapped), to path. The server falls back to a key file in its data
+// directory when no key is configured in the environment; after a rotation the
+// file must hold the new key, or a restart without the environment variables
+// can't decrypt the rekeyed private keys.
+func PersistActive(path string) error {
+	value, err := activeValue()
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so the key is never left truncated
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.TrimSpace(value)), 0600); err != nil {
+		return fmt.Errorf("failed to write key encryption key file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace key encryption key file: %w", err)
+	}
+	return nil
+}
+
+// activeValue returns the active key as configured in the environment or key file
+func activeValue() (string, error) {
+	if value := os.Getenv(EnvKey); value != "" {
+		return value, nil
+	}
+	path := os.Getenv(EnvKeyFile)
+	if path == "" {
+		return "", fmt.Errorf("%s or %s must be set", EnvKey, EnvKeyFile)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key encryption key file: %w", err)
+	}
+	return string(data), nil
+}
+
+// resolve decodes a hex key, unwrapping it with Vault Transit first if needed
+func resolve(ctx context.Context, value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "vault:") {
+		unwrapped, err := unwrap(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		value = strings.TrimSpace(unwrapped)
+	}
+	key, err := hex.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("key must be hex encoded: %w", err)
+	}
+	return key, nil
+}
+
+func unwrap(ctx context.Context, ciphertext string) (string, error) {
+	cfg, err := vault.ConfigFromEnv()
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap the key encryption key: %w", err)
+	}
+	mount := os.Getenv(envTransitMount)
+	if mount == "" {
+		mount = "transit"
+	}
+	name := os.Getenv(envTransitKey)
+	if name == "" {
+		name = "chainlaunch-kek"
+	}
+
+	client, err := vault.NewClient(cfg)
+	if err != nil {
+		return "", err
+	}
+	plaintextB64, err := client.TransitDecrypt(ctx, mount, name, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap key encryption key with vault transit: %w", err)
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(plaintextB64)
+	if err != nil {
+		return "", fmt.Errorf("invalid transit plaintext: %w", err)
+	}
+	return string(plaintext), nil
+}
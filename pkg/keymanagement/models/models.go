@@ -163,6 +163,19 @@ type ProviderResponse struct {
 	CreatedAt time.Time       `json:"createdAt"`
 }
 
+// RekeyResponse reports the outcome of re-encrypting stored private keys
+// with the active key encryption key
+type RekeyResponse struct {
+	ActiveKeyID string `json:"activeKeyId"`
+	Rekeyed     int    `json:"rekeyed"`
+	Unchanged   int    `json:"unchanged"`
+	// ResealedSecrets counts the secrets stored by other services, such as
+	// backup target, SMTP or CouchDB passwords, that were re-encrypted
+	ResealedSecrets int `json:"resealedSecrets"`
+	// KeyFile is the key file of the data directory updated with the active key
+	KeyFile string `json:"keyFile,omitempty"`
+}
+
 type PaginatedResponse struct {
 	Items      []KeyResponse `json:"items"`
 	TotalItems int64         `json:"totalItems"`
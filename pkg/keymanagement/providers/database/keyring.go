@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+)
+
+// KeyRing holds the key encryption keys (KEKs) used to protect private keys
+// stored in the database. New data is always encrypted with the active key;
+// previous keys are only kept so rows written before a rotation can still be
+// decrypted until they have been rekeyed.
+type KeyRing struct {
+	activeID string
+	keys     map[string][]byte
+}
+
+// NewKeyRing creates a key ring with the given active key and any number of
+// previous keys
+func NewKeyRing(active []byte, previous ...[]byte) (*KeyRing, error) {
+	ring := &KeyRing{keys: make(map[string][]byte)}
+	for i, key := range append([][]byte{active}, previous...) {
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("invalid key encryption key length %d: must be 16, 24 or 32 bytes", len(key))
+		}
+		id := KeyID(key)
+		ring.keys[id] = key
+		if i == 0 {
+			ring.activeID = id
+		}
+	}
+	return ring, nil
+}
+
+// KeyID returns the identifier stored next to data encrypted with key. It is
+// derived from the key itself so no extra bookkeeping is needed.
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ActiveKeyID returns the identifier of the key used for new encryptions
+func (r *KeyRing) ActiveKeyID() string {
+	return r.activeID
+}
+
+// IsActive reports whether a value sealed by the key store of the ring is
+// encrypted with the active key
+func (r *KeyRing) IsActive(sealed string) bool {
+	var data encryptedData
+	return json.Unmarshal([]byte(sealed), &data) == nil && data.KeyID == r.activeID
+}
+
+// RekeyResult summarizes a rekey run
+type RekeyResult struct {
+	ActiveKeyID string
+	Rekeyed     int
+	Unchanged   int
+}
+
+// Rekey re-encrypts every private key of DATABASE providers that isn't
+// encrypted with the active key. The rows are updated with q, which the caller
+// binds to a transaction so a failure leaves the table untouched.
+func (p *DatabaseProvider) Rekey(ctx context.Context, q *db.Queries) (*RekeyResult, error) {
+	store, ok := p.store.(*aesKeyStore)
+	if !ok {
+		return nil, fmt.Errorf("provider does not encrypt keys with a key encryption key")
+	}
+
+	result := &RekeyResult{ActiveKeyID: store.ring.ActiveKeyID()}
+	rows, err := q.ListKeyPrivateKeysByProviderType(ctx, "DATABASE")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list keys: %w", err)
+	}
+	for _, row := range rows {
+		var data encryptedData
+		if err := json.Unmarshal([]byte(row.PrivateKey), &data); err != nil {
+			return nil, fmt.Errorf("failed to parse private key %d: %w", row.ID, err)
+		}
+		if data.KeyID == store.ring.ActiveKeyID() {
+			result.Unchanged++
+			continue
+		}
+		plaintext, err := store.decrypt(row.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt private key %d: %w", row.ID, err)
+		}
+		sealed, err := store.encrypt(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt private key %d: %w", row.ID, err)
+		}
+		if err := q.UpdateKeyPrivateKey(ctx, &db.UpdateKeyPrivateKeyParams{
+			PrivateKey: sealed,
+			ID:         row.ID,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to update private key %d: %w", row.ID, err)
+		}
+		result.Rekeyed++
+	}
+	return result, nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	gocrypto "crypto"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/ecdsa"
@@ -21,7 +22,6 @@ import (
 	"fmt"
 	"io"
 	"math/big"
-	"os"
 	"strings"
 	"time"
 
@@ -33,44 +33,116 @@ import (
 )
 
 type DatabaseProvider struct {
-	queries       *db.Queries
-	encryptionKey []byte
+	queries *db.Queries
+	store   PrivateKeyStore
+}
+
+// PrivateKeyStore seals private key material before it is written to the
+// keys table and opens it again when the key is used. The default store
+// encrypts with KEY_ENCRYPTION_KEY; other providers (e.g. Vault) keep the
+// material outside the database and only persist a reference.
+type PrivateKeyStore interface {
+	// Seal protects the PEM/hex encoded private key and returns the value to persist
+	Seal(ctx context.Context, plaintext string) (string, error)
+	// Open returns the private key for a value previously returned by Seal
+	Open(ctx context.Context, sealed string) (string, error)
+	// Remove deletes any external material referenced by a sealed value
+	Remove(ctx context.Context, sealed string) error
+}
+
+// aesKeyStore encrypts private keys with AES-GCM and stores the ciphertext inline
+type aesKeyStore struct {
+	ring *KeyRing
 }
 
 type encryptedData struct {
+	// KeyID identifies the key encryption key, rows written before key
+	// versioning was introduced don't have it
+	KeyID   string `json:"keyId,omitempty"`
 	IV      string `json:"iv"`
 	Data    string `json:"data"`
 	AuthTag string `json:"authTag"`
 }
 
-func NewDatabaseProvider(queries *db.Queries) (*DatabaseProvider, error) {
-	// Get encryption key from environment
-	keyStr := os.Getenv("KEY_ENCRYPTION_KEY")
-	if keyStr == "" {
-		return nil, fmt.Errorf("KEY_ENCRYPTION_KEY environment variable not set")
+// NewDatabaseProvider creates a provider that encrypts private keys with the
+// active key of the given key ring
+func NewDatabaseProvider(queries *db.Queries, ring *KeyRing) (*DatabaseProvider, error) {
+	if ring == nil {
+		return nil, fmt.Errorf("key encryption key not configured")
 	}
+	return NewDatabaseProviderWithStore(queries, &aesKeyStore{ring: ring}), nil
+}
 
-	key, err := hex.DecodeString(keyStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid encryption key: %w", err)
-	}
+// NewKeyStore returns the store encrypting values with the active key of the
+// ring, for secrets kept in other tables than keys
+func NewKeyStore(ring *KeyRing) PrivateKeyStore {
+	return &aesKeyStore{ring: ring}
+}
 
+// NewDatabaseProviderWithStore creates a provider that keeps key metadata in the
+// database but delegates private key protection to the given store
+func NewDatabaseProviderWithStore(queries *db.Queries, store PrivateKeyStore) *DatabaseProvider {
 	return &DatabaseProvider{
-		queries:       queries,
-		encryptionKey: key,
-	}, nil
+		queries: queries,
+		store:   store,
+	}
 }
+
 func (p *DatabaseProvider) GetDecryptedPrivateKey(id int) (string, error) {
-	key, err := p.queries.GetKey(context.Background(), int64(id))
+	ctx := context.Background()
+	key, err := p.queries.GetKey(ctx, int64(id))
 	if err != nil {
 		return "", err
 	}
-	return p.decrypt(key.PrivateKey)
+	return p.store.Open(ctx, key.PrivateKey)
+}
+
+// GetSigner returns a crypto.Signer for the decrypted private key
+func (p *DatabaseProvider) GetSigner(ctx context.Context, id int) (gocrypto.Signer, error) {
+	key, err := p.queries.GetKey(ctx, int64(id))
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := p.store.Open(ctx, key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open private key: %w", err)
+	}
+
+	// secp256k1 keys are stored hex encoded
+	if key.Curve.Valid && key.Curve.String == string(types.ECCurveSECP256K1) {
+		return crypto.HexToECDSA(privateKey)
+	}
+
+	block, _ := pem.Decode([]byte(privateKey))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	signer, ok := parsed.(gocrypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key of type %T can't be used for signing", parsed)
+	}
+	return signer, nil
+}
+
+func (s *aesKeyStore) Seal(ctx context.Context, plaintext string) (string, error) {
+	return s.encrypt(plaintext)
 }
 
-func (p *DatabaseProvider) encrypt(plaintext string) (string, error) {
+func (s *aesKeyStore) Open(ctx context.Context, sealed string) (string, error) {
+	return s.decrypt(sealed)
+}
+
+func (s *aesKeyStore) Remove(ctx context.Context, sealed string) error {
+	return nil
+}
+
+func (s *aesKeyStore) encrypt(plaintext string) (string, error) {
 	// Create new AES cipher
-	block, err := aes.NewCipher(p.encryptionKey)
+	block, err := aes.NewCipher(s.ring.keys[s.ring.activeID])
 	if err != nil {
 		return "", err
 	}
@@ -92,6 +164,7 @@ func (p *DatabaseProvider) encrypt(plaintext string) (string, error) {
 
 	// Create encrypted data structure
 	data := encryptedData{
+		KeyID:   s.ring.activeID,
 		IV:      base64.StdEncoding.EncodeToString(nonce),
 		Data:    base64.StdEncoding.EncodeToString(ciphertext[:len(ciphertext)-16]),
 		AuthTag: base64.StdEncoding.EncodeToString(ciphertext[len(ciphertext)-16:]),
@@ -106,7 +179,7 @@ func (p *DatabaseProvider) encrypt(plaintext string) (string, error) {
 	return string(jsonData), nil
 }
 
-func (p *DatabaseProvider) decrypt(encryptedStr string) (string, error) {
+func (s *aesKeyStore) decrypt(encryptedStr string) (string, error) {
 	var data encryptedData
 	if err := json.Unmarshal([]byte(encryptedStr), &data); err != nil {
 		return "", err
@@ -131,8 +204,29 @@ func (p *DatabaseProvider) decrypt(encryptedStr string) (string, error) {
 	// Combine ciphertext and auth tag
 	fullCiphertext := append(ciphertext, authTag...)
 
+	if data.KeyID != "" {
+		key, ok := s.ring.keys[data.KeyID]
+		if !ok {
+			return "", fmt.Errorf("private key is encrypted with unknown key encryption key %s", data.KeyID)
+		}
+		return openGCM(key, nonce, fullCiphertext)
+	}
+
+	// Legacy rows don't record the key, try every key in the ring
+	var lastErr error
+	for _, key := range s.ring.keys {
+		plaintext, err := openGCM(key, nonce, fullCiphertext)
+		if err == nil {
+			return plaintext, nil
+		}
+		lastErr = err
+	}
+	return "", lastErr
+}
+
+func openGCM(key, nonce, ciphertext []byte) (string, error) {
 	// Create new AES cipher
-	block, err := aes.NewCipher(p.encryptionKey)
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
 	}
@@ -144,7 +238,7 @@ func (p *DatabaseProvider) decrypt(encryptedStr string) (string, error) {
 	}
 
 	// Decrypt
-	plaintext, err := aesGCM.Open(nil, nonce, fullCiphertext, nil)
+	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
 	if err != nil {
 		return "", err
 	}
@@ -204,7 +298,7 @@ func (p *DatabaseProvider) GenerateKey(ctx context.Context, req types.GenerateKe
 	}
 
 	// Encrypt private key before storing
-	encryptedPrivateKey, err := p.encrypt(keyPair.PrivateKey)
+	encryptedPrivateKey, err := p.store.Seal(ctx, keyPair.PrivateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encrypt private key: %w", err)
 	}
@@ -253,7 +347,7 @@ func (p *DatabaseProvider) GenerateKey(ctx context.Context, req types.GenerateKe
 
 func (p *DatabaseProvider) StoreKey(ctx context.Context, req types.StoreKeyRequest) (*models.KeyResponse, error) {
 	// Encrypt private key before storage
-	encryptedPrivateKey, err := p.encrypt(req.PrivateKey)
+	encryptedPrivateKey, err := p.store.Seal(ctx, req.PrivateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encrypt private key: %w", err)
 	}
@@ -324,7 +418,19 @@ func (p *DatabaseProvider) RetrieveKey(ctx context.Context, id int) (*models.Key
 }
 
 func (p *DatabaseProvider) DeleteKey(ctx context.Context, id int) error {
-	return p.queries.DeleteKey(ctx, int64(id))
+	key, err := p.queries.GetKey(ctx, int64(id))
+	if err != nil {
+		return err
+	}
+	if err := p.queries.DeleteKey(ctx, int64(id)); err != nil {
+		return err
+	}
+	if key.PrivateKey != "" {
+		if err := p.store.Remove(ctx, key.PrivateKey); err != nil {
+			return fmt.Errorf("failed to remove private key material: %w", err)
+		}
+	}
+	return nil
 }
 
 // Helper function to map database key to response
@@ -681,7 +787,7 @@ func (p *DatabaseProvider) SignCertificate(ctx context.Context, req types.SignCe
 	}
 
 	// Decode CA private key
-	decryptedCAKey, err := p.decrypt(caKey.PrivateKey)
+	decryptedCAKey, err := p.store.Open(ctx, caKey.PrivateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt CA private key: %w", err)
 	}
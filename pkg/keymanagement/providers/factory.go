@@ -1,10 +1,15 @@
 package providers
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/kek"
 	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/database"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/hsm"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/vault"
 )
 
 type ProviderType string
@@ -12,23 +17,31 @@ type ProviderType string
 const (
 	ProviderTypeDatabase       ProviderType = "DATABASE"
 	ProviderTypeHSM            ProviderType = "HSM"
-	ProviderTypeHashicorpVault ProviderType = "HASHICORP_VAULT"
+	ProviderTypeHashicorpVault ProviderType = "VAULT"
 )
 
 // ProviderFactory creates and manages key providers
 type ProviderFactory struct {
 	providers map[ProviderType]Provider
 	queries   *db.Queries
+
+	mu         sync.Mutex
+	byConfigID map[int64]Provider
 }
 
 func NewProviderFactory(queries *db.Queries) (*ProviderFactory, error) {
 	factory := &ProviderFactory{
-		providers: make(map[ProviderType]Provider),
-		queries:   queries,
+		providers:  make(map[ProviderType]Provider),
+		queries:    queries,
+		byConfigID: make(map[int64]Provider),
 	}
 
 	// Initialize database provider
-	dbProvider, err := database.NewDatabaseProvider(queries)
+	ring, err := kek.Load(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key encryption key: %w", err)
+	}
+	dbProvider, err := database.NewDatabaseProvider(queries, ring)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize database provider: %w", err)
 	}
@@ -44,3 +57,69 @@ func (f *ProviderFactory) GetProvider(providerType ProviderType) (Provider, erro
 	}
 	return provider, nil
 }
+
+// GetProviderByID returns the provider configured by the key_providers row with the given ID.
+// Providers that need external connections are created on first use and cached.
+func (f *ProviderFactory) GetProviderByID(ctx context.Context, id int64) (Provider, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if provider, ok := f.byConfigID[id]; ok {
+		return provider, nil
+	}
+
+	row, err := f.queries.GetKeyProvider(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key provider %d: %w", id, err)
+	}
+
+	var provider Provider
+	switch ProviderType(row.Type) {
+	case ProviderTypeDatabase:
+		provider = f.providers[ProviderTypeDatabase]
+	case ProviderTypeHashicorpVault:
+		provider, err = vault.NewVaultProvider(ctx, f.queries, row.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize vault provider %d: %w", id, err)
+		}
+	case ProviderTypeHSM:
+		provider, err = hsm.NewHSMProvider(f.queries, row.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize hsm provider %d: %w", id, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported provider type: %s", row.Type)
+	}
+
+	f.byConfigID[id] = provider
+	return provider, nil
+}
+
+// ValidateConfig checks that a provider of the given type can be created from config
+func (f *ProviderFactory) ValidateConfig(ctx context.Context, providerType ProviderType, config string) error {
+	switch providerType {
+	case ProviderTypeDatabase:
+		return nil
+	case ProviderTypeHashicorpVault:
+		_, err := vault.NewVaultProvider(ctx, f.queries, config)
+		return err
+	case ProviderTypeHSM:
+		provider, err := hsm.NewHSMProvider(f.queries, config)
+		if err != nil {
+			return err
+		}
+		return provider.Close()
+	default:
+		return fmt.Errorf("unsupported provider type: %s", providerType)
+	}
+}
+
+// Forget drops a cached provider so that the next lookup reloads its configuration
+func (f *ProviderFactory) Forget(id int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if closer, ok := f.byConfigID[id].(interface{ Close() error }); ok {
+		closer.Close()
+	}
+	delete(f.byConfigID, id)
+}
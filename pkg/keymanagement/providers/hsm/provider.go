@@ -0,0 +1,690 @@
+package hsm
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/models"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/types"
+	"github.com/miekg/pkcs11"
+)
+
+// Config is the JSON configuration stored in key_providers.config for HSM providers
+type Config struct {
+	// Library is the path to the PKCS#11 module, e.g. /usr/lib/softhsm/libsofthsm2.so
+	Library string `json:"library"`
+	// Label of the token that holds the keys
+	Label string `json:"label"`
+	// Pin of the token user
+	Pin string `json:"pin"`
+}
+
+// ParseConfig decodes and validates a provider config
+func ParseConfig(raw string) (*Config, error) {
+	cfg := &Config{}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), cfg); err != nil {
+			return nil, fmt.Errorf("invalid hsm config: %w", err)
+		}
+	}
+	if cfg.Library == "" {
+		return nil, fmt.Errorf("pkcs11 library path is required")
+	}
+	if cfg.Label == "" {
+		return nil, fmt.Errorf("token label is required")
+	}
+	if cfg.Pin == "" {
+		return nil, fmt.Errorf("token pin is required")
+	}
+	return cfg, nil
+}
+
+var (
+	oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveP384 = asn1.ObjectIdentifier{1, 3, 132, 0, 34}
+)
+
+// HSMProvider generates and uses keys inside a PKCS#11 token. Private keys are
+// created non-extractable, only the public key and certificate are stored in
+// the database together with the key's CKA_ID.
+//
+// Keys are identified on the token by CKA_ID = SHA256(EC point), which is the
+// SKI Fabric's PKCS11 BCCSP uses to find the signing key of a local MSP.
+type HSMProvider struct {
+	queries *db.Queries
+	config  *Config
+	ctx     *pkcs11.Ctx
+
+	mu      sync.Mutex
+	session pkcs11.SessionHandle
+}
+
+// hsmKeyRef is the value persisted in keys.private_key for HSM backed keys
+type hsmKeyRef struct {
+	SKI string `json:"hsmKeyId"`
+}
+
+// NewHSMProvider loads the PKCS#11 module and logs into the configured token
+func NewHSMProvider(queries *db.Queries, rawConfig string) (*HSMProvider, error) {
+	cfg, err := ParseConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	p11 := pkcs11.New(cfg.Library)
+	if p11 == nil {
+		return nil, fmt.Errorf("failed to load pkcs11 library %s", cfg.Library)
+	}
+	if err := p11.Initialize(); err != nil && !isPKCS11Error(err, pkcs11.CKR_CRYPTOKI_ALREADY_INITIALIZED) {
+		return nil, fmt.Errorf("failed to initialize pkcs11 library: %w", err)
+	}
+
+	slot, err := findSlot(p11, cfg.Label)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := p11.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pkcs11 session: %w", err)
+	}
+	if err := p11.Login(session, pkcs11.CKU_USER, cfg.Pin); err != nil && !isPKCS11Error(err, pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+		p11.CloseSession(session)
+		return nil, fmt.Errorf("failed to login to token %s: %w", cfg.Label, err)
+	}
+
+	return &HSMProvider{
+		queries: queries,
+		config:  cfg,
+		ctx:     p11,
+		session: session,
+	}, nil
+}
+
+// Close releases the provider's session. The module stays initialized since
+// other providers may share it.
+func (p *HSMProvider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.ctx.CloseSession(p.session)
+}
+
+// PKCS11Settings returns the token settings for Fabric's PKCS11 BCCSP
+func (p *HSMProvider) PKCS11Settings() types.PKCS11Settings {
+	return types.PKCS11Settings{
+		Library: p.config.Library,
+		Label:   p.config.Label,
+		Pin:     p.config.Pin,
+	}
+}
+
+func findSlot(p11 *pkcs11.Ctx, label string) (uint, error) {
+	slots, err := p11.GetSlotList(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pkcs11 slots: %w", err)
+	}
+	for _, slot := range slots {
+		info, err := p11.GetTokenInfo(slot)
+		if err != nil {
+			continue
+		}
+		if info.Label == label {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("no token found with label %s", label)
+}
+
+func isPKCS11Error(err error, code uint) bool {
+	p11Err, ok := err.(pkcs11.Error)
+	return ok && uint(p11Err) == code
+}
+
+func curveParams(curve types.ECCurve) (elliptic.Curve, asn1.ObjectIdentifier, error) {
+	switch curve {
+	case types.ECCurveP256:
+		return elliptic.P256(), oidNamedCurveP256, nil
+	case types.ECCurveP384:
+		return elliptic.P384(), oidNamedCurveP384, nil
+	default:
+		return nil, nil, fmt.Errorf("curve %s is not supported by the HSM provider", curve)
+	}
+}
+
+func (p *HSMProvider) GenerateKey(ctx context.Context, req types.GenerateKeyRequest) (*models.KeyResponse, error) {
+	if req.Algorithm != types.KeyAlgorithmEC || req.Curve == nil {
+		return nil, fmt.Errorf("the HSM provider only supports EC keys")
+	}
+	curve, oid, err := curveParams(*req.Curve)
+	if err != nil {
+		return nil, err
+	}
+	ecParams, err := asn1.Marshal(oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode curve parameters: %w", err)
+	}
+
+	p.mu.Lock()
+	publicKey, ski, err := p.generateECKeyPair(curve, ecParams, req.Name)
+	p.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := p.keyParams(publicKey, ski, string(*req.Curve), req.Name, req.Description, req.Status, *req.ProviderID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if req.IsCA != nil && *req.IsCA == 1 {
+		params.IsCa = 1
+	}
+
+	// Generate self-signed certificate if requested or if isCA is 1
+	if req.Certificate != nil || params.IsCa == 1 {
+		certReq := req.Certificate
+		if certReq == nil {
+			certReq = &types.CertificateRequest{
+				CommonName:   req.Name,
+				Organization: []string{"ChainDeploy"},
+				Country:      []string{"US"},
+				ValidFrom:    time.Now(),
+				KeyUsage:     x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+				ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+			}
+		}
+		if params.IsCa == 1 {
+			certReq.IsCA = true
+			certReq.KeyUsage |= x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		}
+		signer := &tokenSigner{provider: p, ski: ski, public: publicKey}
+		certPEM, err := createCertificate(certReq, nil, publicKey, signer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate certificate: %w", err)
+		}
+		params.Certificate = sql.NullString{String: certPEM, Valid: true}
+	}
+
+	key, err := p.queries.CreateKey(ctx, params)
+	if err != nil {
+		p.mu.Lock()
+		p.destroyObjects(ski)
+		p.mu.Unlock()
+		return nil, err
+	}
+	return mapDBKeyToResponse(key), nil
+}
+
+// generateECKeyPair creates a non-extractable key pair on the token and tags
+// both objects with the SKI. Must be called with p.mu held.
+func (p *HSMProvider) generateECKeyPair(curve elliptic.Curve, ecParams []byte, label string) (*ecdsa.PublicKey, []byte, error) {
+	publicTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+	privateTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+	}
+
+	pubHandle, privHandle, err := p.ctx.GenerateKeyPair(p.session,
+		[]*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)},
+		publicTemplate, privateTemplate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key pair on token: %w", err)
+	}
+
+	attrs, err := p.ctx.GetAttributeValue(p.session, pubHandle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read public key from token: %w", err)
+	}
+	point, err := decodeECPoint(attrs[0].Value)
+	if err != nil {
+		return nil, nil, err
+	}
+	x, y := elliptic.Unmarshal(curve, point)
+	if x == nil {
+		return nil, nil, fmt.Errorf("invalid EC point returned by token")
+	}
+
+	ski := skiForPoint(point)
+	idAttrs := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_ID, ski),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, hex.EncodeToString(ski)),
+	}
+	if err := p.ctx.SetAttributeValue(p.session, pubHandle, idAttrs); err != nil {
+		return nil, nil, fmt.Errorf("failed to set public key id: %w", err)
+	}
+	if err := p.ctx.SetAttributeValue(p.session, privHandle, idAttrs); err != nil {
+		return nil, nil, fmt.Errorf("failed to set private key id: %w", err)
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, ski, nil
+}
+
+// StoreKey imports an existing EC private key into the token as a non-extractable object
+func (p *HSMProvider) StoreKey(ctx context.Context, req types.StoreKeyRequest) (*models.KeyResponse, error) {
+	block, _ := pem.Decode([]byte(req.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	privateKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the HSM provider only supports EC keys")
+	}
+	curveName := types.ECCurve(privateKey.Curve.Params().Name)
+	_, oid, err := curveParams(curveName)
+	if err != nil {
+		return nil, err
+	}
+	ecParams, err := asn1.Marshal(oid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode curve parameters: %w", err)
+	}
+	point := elliptic.Marshal(privateKey.Curve, privateKey.X, privateKey.Y)
+	encodedPoint, err := asn1.Marshal(point)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode EC point: %w", err)
+	}
+	ski := skiForPoint(point)
+
+	p.mu.Lock()
+	_, err = p.ctx.CreateObject(p.session, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, encodedPoint),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, ski),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, hex.EncodeToString(ski)),
+	})
+	if err == nil {
+		_, err = p.ctx.CreateObject(p.session, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+			pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+			pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+			pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+			pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+			pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+			pkcs11.NewAttribute(pkcs11.CKA_VALUE, privateKey.D.Bytes()),
+			pkcs11.NewAttribute(pkcs11.CKA_ID, ski),
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, hex.EncodeToString(ski)),
+		})
+		if err != nil {
+			p.destroyObjects(ski)
+		}
+	}
+	p.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to import key into token: %w", err)
+	}
+
+	params, err := p.keyParams(&privateKey.PublicKey, ski, string(curveName), req.Name, req.Description, req.Status, *req.ProviderID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Certificate != nil {
+		params.Certificate = sql.NullString{String: *req.Certificate, Valid: true}
+	}
+	if req.ExpiresAt != nil {
+		params.ExpiresAt = sql.NullTime{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	key, err := p.queries.CreateKey(ctx, params)
+	if err != nil {
+		p.mu.Lock()
+		p.destroyObjects(ski)
+		p.mu.Unlock()
+		return nil, err
+	}
+	return mapDBKeyToResponse(key), nil
+}
+
+func (p *HSMProvider) keyParams(publicKey *ecdsa.PublicKey, ski []byte, curve, name string, description *string, status string, providerID, userID int) (*db.CreateKeyParams, error) {
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes})
+	sha256Sum := sha256.Sum256(publicKeyBytes)
+	sha1Sum := sha1.Sum(publicKeyBytes)
+
+	ref, err := json.Marshal(hsmKeyRef{SKI: hex.EncodeToString(ski)})
+	if err != nil {
+		return nil, err
+	}
+	if status == "" {
+		status = "active"
+	}
+
+	params := &db.CreateKeyParams{
+		Name:              name,
+		Algorithm:         string(types.KeyAlgorithmEC),
+		Curve:             sql.NullString{String: curve, Valid: true},
+		Format:            "PEM",
+		PublicKey:         string(publicPEM),
+		PrivateKey:        string(ref),
+		Status:            status,
+		Sha256Fingerprint: hex.EncodeToString(sha256Sum[:]),
+		Sha1Fingerprint:   hex.EncodeToString(sha1Sum[:]),
+		ProviderID:        int64(providerID),
+		UserID:            int64(userID),
+	}
+	if description != nil {
+		params.Description = sql.NullString{String: *description, Valid: true}
+	}
+	return params, nil
+}
+
+func (p *HSMProvider) RetrieveKey(ctx context.Context, id int) (*models.KeyResponse, error) {
+	key, err := p.queries.GetKey(ctx, int64(id))
+	if err != nil {
+		return nil, err
+	}
+	response := mapDBKeyToResponse(&db.Key{
+		ID:                key.ID,
+		Name:              key.Name,
+		Description:       key.Description,
+		Algorithm:         key.Algorithm,
+		Curve:             key.Curve,
+		Format:            key.Format,
+		PublicKey:         key.PublicKey,
+		Certificate:       key.Certificate,
+		Status:            key.Status,
+		CreatedAt:         key.CreatedAt,
+		ExpiresAt:         key.ExpiresAt,
+		LastRotatedAt:     key.LastRotatedAt,
+		SigningKeyID:      key.SigningKeyID,
+		Sha256Fingerprint: key.Sha256Fingerprint,
+		Sha1Fingerprint:   key.Sha1Fingerprint,
+		ProviderID:        key.ProviderID,
+	})
+	response.Provider.Name = key.ProviderName
+	return response, nil
+}
+
+func (p *HSMProvider) DeleteKey(ctx context.Context, id int) error {
+	key, err := p.queries.GetKey(ctx, int64(id))
+	if err != nil {
+		return err
+	}
+	ski, err := parseKeyRef(key.PrivateKey)
+	if err != nil {
+		return err
+	}
+	if err := p.queries.DeleteKey(ctx, int64(id)); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.destroyObjects(ski)
+}
+
+// destroyObjects removes every token object tagged with the SKI. Must be called with p.mu held.
+func (p *HSMProvider) destroyObjects(ski []byte) error {
+	handles, err := p.findObjects([]*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_ID, ski)})
+	if err != nil {
+		return err
+	}
+	for _, handle := range handles {
+		if err := p.ctx.DestroyObject(p.session, handle); err != nil {
+			return fmt.Errorf("failed to destroy token object: %w", err)
+		}
+	}
+	return nil
+}
+
+// findObjects returns the handles matching the template. Must be called with p.mu held.
+func (p *HSMProvider) findObjects(template []*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	if err := p.ctx.FindObjectsInit(p.session, template); err != nil {
+		return nil, fmt.Errorf("failed to search token: %w", err)
+	}
+	defer p.ctx.FindObjectsFinal(p.session)
+
+	var handles []pkcs11.ObjectHandle
+	for {
+		batch, _, err := p.ctx.FindObjects(p.session, 16)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search token: %w", err)
+		}
+		if len(batch) == 0 {
+			return handles, nil
+		}
+		handles = append(handles, batch...)
+	}
+}
+
+// GetDecryptedPrivateKey always fails: keys never leave the token
+func (p *HSMProvider) GetDecryptedPrivateKey(id int) (string, error) {
+	return "", types.ErrPrivateKeyNotExportable
+}
+
+// GetSigner returns a signer that performs ECDSA signatures inside the token
+func (p *HSMProvider) GetSigner(ctx context.Context, id int) (gocrypto.Signer, error) {
+	key, err := p.queries.GetKey(ctx, int64(id))
+	if err != nil {
+		return nil, err
+	}
+	ski, err := parseKeyRef(key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode([]byte(key.PublicKey))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode public key")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+	ecPublicKey, ok := publicKey.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("key %d is not an EC key", id)
+	}
+	return &tokenSigner{provider: p, ski: ski, public: ecPublicKey}, nil
+}
+
+func (p *HSMProvider) SignCertificate(ctx context.Context, req types.SignCertificateRequest) (*models.KeyResponse, error) {
+	key, err := p.queries.GetKey(ctx, int64(req.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+	caKey, err := p.queries.GetKey(ctx, int64(req.CAKeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CA key: %w", err)
+	}
+	if !caKey.Certificate.Valid {
+		return nil, fmt.Errorf("CA key %d has no certificate", req.CAKeyID)
+	}
+
+	caCertBlock, _ := pem.Decode([]byte(caKey.Certificate.String))
+	if caCertBlock == nil {
+		return nil, fmt.Errorf("failed to decode CA certificate")
+	}
+	caCert, err := x509.ParseCertificate(caCertBlock.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+	if !caCert.IsCA {
+		return nil, fmt.Errorf("key %d's certificate is not a CA certificate, value: %t", req.CAKeyID, caCert.IsCA)
+	}
+
+	caSigner, err := p.GetSigner(ctx, req.CAKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CA signer: %w", err)
+	}
+
+	block, _ := pem.Decode([]byte(key.PublicKey))
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode public key")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	certReq := req.CertificateRequest
+	certReq.IsCA = false
+	certPEM, err := createCertificate(&certReq, caCert, publicKey, caSigner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	updatedKey, err := p.queries.UpdateKey(ctx, &db.UpdateKeyParams{
+		ID:                int64(req.KeyID),
+		Name:              key.Name,
+		Description:       key.Description,
+		Algorithm:         key.Algorithm,
+		KeySize:           key.KeySize,
+		Curve:             key.Curve,
+		Format:            key.Format,
+		PublicKey:         key.PublicKey,
+		PrivateKey:        key.PrivateKey,
+		Certificate:       sql.NullString{String: certPEM, Valid: true},
+		Status:            key.Status,
+		ExpiresAt:         sql.NullTime{Time: certReq.ValidFrom.Add(time.Hour * 24 * 365), Valid: true},
+		Sha256Fingerprint: key.Sha256Fingerprint,
+		Sha1Fingerprint:   key.Sha1Fingerprint,
+		ProviderID:        key.ProviderID,
+		UserID:            key.UserID,
+		EthereumAddress:   key.EthereumAddress,
+		SigningKeyID:      sql.NullInt64{Int64: int64(req.CAKeyID), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update key with certificate: %w", err)
+	}
+	return mapDBKeyToResponse(updatedKey), nil
+}
+
+// createCertificate issues a certificate for publicKey. A nil parent produces a self-signed certificate.
+func createCertificate(req *types.CertificateRequest, parent *x509.Certificate, publicKey interface{}, signer gocrypto.Signer) (string, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			CommonName:         req.CommonName,
+			Organization:       req.Organization,
+			OrganizationalUnit: req.OrganizationalUnit,
+			Country:            req.Country,
+			Province:           req.Province,
+			Locality:           req.Locality,
+			StreetAddress:      req.StreetAddress,
+			PostalCode:         req.PostalCode,
+		},
+		NotBefore:             req.ValidFrom.Add(-time.Minute * 1),
+		NotAfter:              req.ValidFrom.Add(time.Hour * 24 * 365),
+		KeyUsage:              req.KeyUsage,
+		ExtKeyUsage:           req.ExtKeyUsage,
+		BasicConstraintsValid: true,
+		IsCA:                  req.IsCA,
+		DNSNames:              req.DNSNames,
+		EmailAddresses:        req.EmailAddresses,
+		IPAddresses:           req.IPAddresses,
+		URIs:                  req.URIs,
+	}
+	if parent == nil {
+		parent = template
+	}
+
+	certBytes, err := x509.CreateCertificate(rand.Reader, template, parent, publicKey, signer)
+	if err != nil {
+		return "", err
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes})), nil
+}
+
+func parseKeyRef(value string) ([]byte, error) {
+	var ref hsmKeyRef
+	if err := json.Unmarshal([]byte(value), &ref); err != nil || ref.SKI == "" {
+		return nil, fmt.Errorf("private key is not managed by the HSM provider")
+	}
+	return hex.DecodeString(ref.SKI)
+}
+
+// skiForPoint computes the SKI Fabric uses to look up EC keys on a PKCS#11 token
+func skiForPoint(point []byte) []byte {
+	hash := sha256.Sum256(point)
+	return hash[:]
+}
+
+// decodeECPoint unwraps the DER OCTET STRING most tokens return for CKA_EC_POINT
+func decodeECPoint(value []byte) ([]byte, error) {
+	var point []byte
+	rest, err := asn1.Unmarshal(value, &point)
+	if err == nil && len(rest) == 0 {
+		return point, nil
+	}
+	// Some tokens return the raw uncompressed point
+	if len(value) > 0 && value[0] == 0x04 {
+		return value, nil
+	}
+	return nil, fmt.Errorf("unsupported CKA_EC_POINT encoding")
+}
+
+func mapDBKeyToResponse(key *db.Key) *models.KeyResponse {
+	response := &models.KeyResponse{
+		ID:                int(key.ID),
+		Name:              key.Name,
+		Description:       &key.Description.String,
+		Algorithm:         models.KeyAlgorithm(key.Algorithm),
+		Format:            key.Format,
+		PublicKey:         key.PublicKey,
+		Certificate:       &key.Certificate.String,
+		Status:            key.Status,
+		CreatedAt:         key.CreatedAt,
+		ExpiresAt:         &key.ExpiresAt.Time,
+		LastRotatedAt:     &key.LastRotatedAt.Time,
+		SHA256Fingerprint: key.Sha256Fingerprint,
+		SHA1Fingerprint:   key.Sha1Fingerprint,
+		Provider:          models.KeyProviderInfo{ID: int(key.ProviderID), Name: "HSM"},
+	}
+	if key.Curve.Valid {
+		curve := models.ECCurve(key.Curve.String)
+		response.Curve = &curve
+	}
+	if key.SigningKeyID.Valid {
+		signingKeyID := int(key.SigningKeyID.Int64)
+		response.SigningKeyID = &signingKeyID
+	}
+	return response
+}
@@ -0,0 +1,72 @@
+package hsm
+
+import (
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/miekg/pkcs11"
+)
+
+// tokenSigner implements crypto.Signer with CKM_ECDSA on the token
+type tokenSigner struct {
+	provider *HSMProvider
+	ski      []byte
+	public   *ecdsa.PublicKey
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+func (s *tokenSigner) Public() gocrypto.PublicKey {
+	return s.public
+}
+
+// Sign signs a precomputed digest and returns an ASN.1 encoded low-S signature,
+// the form Fabric requires
+func (s *tokenSigner) Sign(_ io.Reader, digest []byte, _ gocrypto.SignerOpts) ([]byte, error) {
+	p := s.provider
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	handles, err := p.findObjects([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PRIVATE_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, s.ski),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(handles) == 0 {
+		return nil, fmt.Errorf("private key %x not found on token", s.ski)
+	}
+
+	if err := p.ctx.SignInit(p.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, handles[0]); err != nil {
+		return nil, fmt.Errorf("failed to initialize signing: %w", err)
+	}
+	raw, err := p.ctx.Sign(p.session, digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign on token: %w", err)
+	}
+	return encodeSignature(raw, s.public.Curve)
+}
+
+// encodeSignature converts the r||s signature returned by CKM_ECDSA into ASN.1
+// and normalizes s to the lower half of the curve order
+func encodeSignature(raw []byte, curve elliptic.Curve) ([]byte, error) {
+	if len(raw) == 0 || len(raw)%2 != 0 {
+		return nil, fmt.Errorf("unexpected signature length %d", len(raw))
+	}
+
+	r := new(big.Int).SetBytes(raw[:len(raw)/2])
+	s := new(big.Int).SetBytes(raw[len(raw)/2:])
+	halfOrder := new(big.Int).Rsh(curve.Params().N, 1)
+	if s.Cmp(halfOrder) > 0 {
+		s.Sub(curve.Params().N, s)
+	}
+	return asn1.Marshal(ecdsaSignature{R: r, S: s})
+}
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"crypto"
 
 	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/models"
 	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/types"
@@ -19,6 +20,16 @@ type Provider interface {
 	DeleteKey(ctx context.Context, id int) error
 	// SignCertificate signs a certificate for a key using a CA key
 	SignCertificate(ctx context.Context, req types.SignCertificateRequest) (*models.KeyResponse, error)
-	// GetDecryptedPrivateKey retrieves and decrypts the private key for a given key ID
+	// GetDecryptedPrivateKey retrieves and decrypts the private key for a given key ID.
+	// Returns types.ErrPrivateKeyNotExportable for keys that can't leave the provider.
 	GetDecryptedPrivateKey(id int) (string, error)
+	// GetSigner returns a signer for the private key, which works for exportable and non-exportable keys
+	GetSigner(ctx context.Context, id int) (crypto.Signer, error)
+}
+
+// PKCS11Provider is implemented by providers whose keys live in a PKCS#11 token
+type PKCS11Provider interface {
+	Provider
+	// PKCS11Settings returns the settings a Fabric BCCSP needs to reach the token
+	PKCS11Settings() types.PKCS11Settings
 }
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"errors"
 	"net"
 	"net/url"
 	"time"
@@ -8,6 +9,11 @@ import (
 	"crypto/x509"
 )
 
+// ErrPrivateKeyNotExportable is returned when the private key material can't
+// leave its provider (e.g. keys generated inside an HSM). Callers should use
+// the provider's signer instead.
+var ErrPrivateKeyNotExportable = errors.New("private key is not exportable")
+
 type KeyProviderType string
 type KeyAlgorithm string
 type ECCurve string
@@ -100,3 +106,10 @@ type SignCertificateRequest struct {
 	CAKeyID int // ID of the CA key to sign with
 	CertificateRequest
 }
+
+// PKCS11Settings describes how a Fabric node's BCCSP reaches the token holding its keys
+type PKCS11Settings struct {
+	Library string
+	Label   string
+	Pin     string
+}
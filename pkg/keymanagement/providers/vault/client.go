@@ -0,0 +1,261 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Client is a minimal HashiCorp Vault HTTP API client covering the endpoints
+// used by the key provider (token/AppRole auth, KV v2 and Transit)
+type Client struct {
+	address    string
+	namespace  string
+	roleID     string
+	secretID   string
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+// apiError is returned when Vault answers with a non 2xx status code
+type apiError struct {
+	StatusCode int
+	Errors     []string `json:"errors"`
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("vault returned status %d: %s", e.StatusCode, strings.Join(e.Errors, "; "))
+}
+
+// NewClient creates a Vault client from the provider configuration
+func NewClient(cfg *Config) (*Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.TLSSkipVerify}
+	if cfg.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CACert)) {
+			return nil, fmt.Errorf("failed to parse vault CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return &Client{
+		address:   strings.TrimRight(cfg.Address, "/"),
+		namespace: cfg.Namespace,
+		roleID:    cfg.RoleID,
+		secretID:  cfg.SecretID,
+		token:     cfg.Token,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}, nil
+}
+
+// Ping verifies that Vault is reachable and the configured credentials are valid
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.do(ctx, http.MethodGet, "auth/token/lookup-self", nil)
+	return err
+}
+
+// ReadKV reads the latest version of a KV v2 secret
+func (c *Client) ReadKV(ctx context.Context, mount, path string) (map[string]interface{}, error) {
+	resp, err := c.do(ctx, http.MethodGet, fmt.Sprintf("%s/data/%s", mount, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode KV response: %w", err)
+	}
+	if out.Data.Data == nil {
+		return nil, fmt.Errorf("secret %s/%s not found", mount, path)
+	}
+	return out.Data.Data, nil
+}
+
+// WriteKV writes a new version of a KV v2 secret
+func (c *Client) WriteKV(ctx context.Context, mount, path string, data map[string]interface{}) error {
+	_, err := c.do(ctx, http.MethodPost, fmt.Sprintf("%s/data/%s", mount, path), map[string]interface{}{
+		"data": data,
+	})
+	return err
+}
+
+// DeleteKV permanently deletes all versions and metadata of a KV v2 secret
+func (c *Client) DeleteKV(ctx context.Context, mount, path string) error {
+	_, err := c.do(ctx, http.MethodDelete, fmt.Sprintf("%s/metadata/%s", mount, path), nil)
+	return err
+}
+
+// EnsureTransitKey creates the named Transit encryption key if it doesn't exist yet
+func (c *Client) EnsureTransitKey(ctx context.Context, mount, name string) error {
+	_, err := c.do(ctx, http.MethodGet, fmt.Sprintf("%s/keys/%s", mount, name), nil)
+	if err == nil {
+		return nil
+	}
+	if apiErr, ok := err.(*apiError); !ok || apiErr.StatusCode != http.StatusNotFound {
+		return err
+	}
+	_, err = c.do(ctx, http.MethodPost, fmt.Sprintf("%s/keys/%s", mount, name), map[string]interface{}{
+		"type": "aes256-gcm96",
+	})
+	return err
+}
+
+// TransitEncrypt encrypts base64 encoded plaintext with a Transit key and returns the vault:vN: ciphertext
+func (c *Client) TransitEncrypt(ctx context.Context, mount, name, plaintextB64 string) (string, error) {
+	resp, err := c.do(ctx, http.MethodPost, fmt.Sprintf("%s/encrypt/%s", mount, name), map[string]interface{}{
+		"plaintext": plaintextB64,
+	})
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		return "", fmt.Errorf("failed to decode transit response: %w", err)
+	}
+	return out.Data.Ciphertext, nil
+}
+
+// TransitDecrypt decrypts a Transit ciphertext and returns the base64 encoded plaintext
+func (c *Client) TransitDecrypt(ctx context.Context, mount, name, ciphertext string) (string, error) {
+	resp, err := c.do(ctx, http.MethodPost, fmt.Sprintf("%s/decrypt/%s", mount, name), map[string]interface{}{
+		"ciphertext": ciphertext,
+	})
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		return "", fmt.Errorf("failed to decode transit response: %w", err)
+	}
+	return out.Data.Plaintext, nil
+}
+
+// login exchanges the AppRole credentials for a client token
+func (c *Client) login(ctx context.Context) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"role_id":   c.roleID,
+		"secret_id": c.secretID,
+	})
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.send(ctx, http.MethodPost, "auth/approle/login", "", body)
+	if err != nil {
+		return "", fmt.Errorf("approle login failed: %w", err)
+	}
+	var out struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		return "", fmt.Errorf("failed to decode login response: %w", err)
+	}
+	if out.Auth.ClientToken == "" {
+		return "", fmt.Errorf("approle login returned no token")
+	}
+	return out.Auth.ClientToken, nil
+}
+
+// do performs an authenticated request, logging in with AppRole when no token
+// is available or the current one has expired
+func (c *Client) do(ctx context.Context, method, path string, payload interface{}) ([]byte, error) {
+	var body []byte
+	if payload != nil {
+		var err error
+		body, err = json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	token, err := c.currentToken(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(ctx, method, path, token, body)
+	if apiErr, ok := err.(*apiError); ok && apiErr.StatusCode == http.StatusForbidden && c.roleID != "" {
+		token, err = c.currentToken(ctx, true)
+		if err != nil {
+			return nil, err
+		}
+		return c.send(ctx, method, path, token, body)
+	}
+	return resp, err
+}
+
+func (c *Client) currentToken(ctx context.Context, refresh bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && !refresh {
+		return c.token, nil
+	}
+	if c.roleID == "" {
+		return "", fmt.Errorf("no vault token configured")
+	}
+	token, err := c.login(ctx)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	return token, nil
+}
+
+func (c *Client) send(ctx context.Context, method, path, token string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/v1/%s", c.address, path), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.namespace)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &apiError{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(respBody, apiErr)
+		return nil, apiErr
+	}
+	return respBody, nil
+}
@@ -0,0 +1,264 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testToken    = "root-token"
+	testRoleID   = "role"
+	testSecretID = "secret"
+)
+
+// fakeVault implements the parts of the Vault HTTP API used by the client
+type fakeVault struct {
+	mu          sync.Mutex
+	tokens      map[string]bool
+	logins      int
+	kv          map[string]map[string]interface{}
+	transitKeys map[string]bool
+	requests    int
+}
+
+func newFakeVault(t *testing.T) (*fakeVault, *httptest.Server) {
+	f := &fakeVault{
+		tokens:      map[string]bool{testToken: true},
+		kv:          map[string]map[string]interface{}{},
+		transitKeys: map[string]bool{},
+	}
+	server := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeVault) loginCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.logins
+}
+
+func (f *fakeVault) requestCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests
+}
+
+func (f *fakeVault) hasTransitKey(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.transitKeys[name]
+}
+
+// secret returns a KV secret and whether it exists
+func (f *fakeVault) secret(path string) (map[string]interface{}, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.kv[path]
+	return data, ok
+}
+
+func (f *fakeVault) secretCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.kv)
+}
+
+// revokeAll invalidates every issued token, as when they expire
+func (f *fakeVault) revokeAll() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tokens = map[string]bool{}
+}
+
+func writeVaultError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{message}})
+}
+
+func (f *fakeVault) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	var body map[string]interface{}
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	if path == "auth/approle/login" {
+		if body["role_id"] != testRoleID || body["secret_id"] != testSecretID {
+			writeVaultError(w, http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+		f.logins++
+		token := "approle-token-" + strings.Repeat("x", f.logins)
+		f.tokens[token] = true
+		json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]interface{}{"client_token": token}})
+		return
+	}
+	if !f.tokens[r.Header.Get("X-Vault-Token")] {
+		writeVaultError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+
+	switch {
+	case path == "auth/token/lookup-self":
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{}})
+	case path == "sys/fail":
+		writeVaultError(w, http.StatusInternalServerError, "internal error")
+	case strings.HasPrefix(path, "secret/data/"):
+		secretPath := strings.TrimPrefix(path, "secret/data/")
+		switch r.Method {
+		case http.MethodPost:
+			data, _ := body["data"].(map[string]interface{})
+			f.kv[secretPath] = data
+			w.WriteHeader(http.StatusOK)
+		case http.MethodGet:
+			data, ok := f.kv[secretPath]
+			if !ok {
+				writeVaultError(w, http.StatusNotFound, "")
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": data}})
+		}
+	case strings.HasPrefix(path, "secret/metadata/") && r.Method == http.MethodDelete:
+		delete(f.kv, strings.TrimPrefix(path, "secret/metadata/"))
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasPrefix(path, "transit/keys/"):
+		name := strings.TrimPrefix(path, "transit/keys/")
+		if r.Method == http.MethodPost {
+			f.transitKeys[name] = true
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if !f.transitKeys[name] {
+			writeVaultError(w, http.StatusNotFound, "")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"name": name}})
+	case strings.HasPrefix(path, "transit/encrypt/"):
+		if !f.transitKeys[strings.TrimPrefix(path, "transit/encrypt/")] {
+			writeVaultError(w, http.StatusBadRequest, "encryption key not found")
+			return
+		}
+		plaintext, _ := body["plaintext"].(string)
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"ciphertext": "vault:v1:" + reverse(plaintext)}})
+	case strings.HasPrefix(path, "transit/decrypt/"):
+		ciphertext, _ := body["ciphertext"].(string)
+		if !strings.HasPrefix(ciphertext, "vault:v1:") {
+			writeVaultError(w, http.StatusBadRequest, "invalid ciphertext")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"plaintext": reverse(strings.TrimPrefix(ciphertext, "vault:v1:"))}})
+	default:
+		writeVaultError(w, http.StatusNotFound, "no handler for route "+path)
+	}
+}
+
+// reverse stands in for the encryption of the fake transit engine
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+func newTestClient(t *testing.T, cfg *Config) *Client {
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestTokenAuth(t *testing.T) {
+	_, server := newFakeVault(t)
+	ctx := context.Background()
+
+	if err := newTestClient(t, &Config{Address: server.URL, Token: testToken}).Ping(ctx); err != nil {
+		t.Fatalf("ping with a valid token failed: %v", err)
+	}
+
+	err := newTestClient(t, &Config{Address: server.URL, Token: "wrong"}).Ping(ctx)
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a 403 error for an invalid token, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("expected the vault errors in the message, got %q", err.Error())
+	}
+}
+
+func TestAppRoleLogin(t *testing.T) {
+	fake, server := newFakeVault(t)
+	ctx := context.Background()
+	client := newTestClient(t, &Config{Address: server.URL, RoleID: testRoleID, SecretID: testSecretID})
+
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("ping with approle failed: %v", err)
+	}
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("second ping failed: %v", err)
+	}
+	if fake.loginCount() != 1 {
+		t.Fatalf("expected the token to be reused, got %d logins", fake.loginCount())
+	}
+
+	// An expired token is replaced by logging in again
+	fake.revokeAll()
+	if err := client.Ping(ctx); err != nil {
+		t.Fatalf("ping after the token expired failed: %v", err)
+	}
+	if fake.loginCount() != 2 {
+		t.Fatalf("expected a second login, got %d logins", fake.loginCount())
+	}
+}
+
+func TestAppRoleLoginFailure(t *testing.T) {
+	_, server := newFakeVault(t)
+	client := newTestClient(t, &Config{Address: server.URL, RoleID: testRoleID, SecretID: "wrong"})
+
+	err := client.Ping(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "approle login failed") {
+		t.Fatalf("expected an approle login error, got %v", err)
+	}
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected the 400 status of the login to be kept, got %v", err)
+	}
+}
+
+func TestErrorStatuses(t *testing.T) {
+	_, server := newFakeVault(t)
+	ctx := context.Background()
+	client := newTestClient(t, &Config{Address: server.URL, Token: testToken})
+
+	_, err := client.ReadKV(ctx, "secret", "missing")
+	var apiErr *apiError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 error for a missing secret, got %v", err)
+	}
+
+	_, err = client.do(ctx, http.MethodGet, "sys/fail", nil)
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected a 500 error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "internal error") {
+		t.Errorf("expected the vault errors in the message, got %q", err.Error())
+	}
+
+	// A token client without AppRole credentials doesn't retry on 403
+	_, err = newTestClient(t, &Config{Address: server.URL, Token: "wrong"}).ReadKV(ctx, "secret", "missing")
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a 403 error, got %v", err)
+	}
+}
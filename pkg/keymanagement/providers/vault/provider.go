@@ -0,0 +1,238 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/database"
+	"github.com/google/uuid"
+)
+
+// Engine selects which Vault secrets engine protects the private keys
+type Engine string
+
+const (
+	// EngineKV stores private keys in a KV v2 mount, the database only keeps the secret path
+	EngineKV Engine = "kv"
+	// EngineTransit encrypts private keys with a Transit key, the database only keeps the ciphertext
+	EngineTransit Engine = "transit"
+)
+
+// Config is the JSON configuration stored in key_providers.config for VAULT providers
+type Config struct {
+	Address       string `json:"address"`
+	Token         string `json:"token,omitempty"`
+	RoleID        string `json:"roleId,omitempty"`
+	SecretID      string `json:"secretId,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	Engine        Engine `json:"engine,omitempty"`
+	KVMount       string `json:"kvMount,omitempty"`
+	PathPrefix    string `json:"pathPrefix,omitempty"`
+	TransitMount  string `json:"transitMount,omitempty"`
+	TransitKey    string `json:"transitKey,omitempty"`
+	CACert        string `json:"caCert,omitempty"`
+	TLSSkipVerify bool   `json:"tlsSkipVerify,omitempty"`
+}
+
+// ParseConfig decodes and validates a provider config, applying defaults
+func ParseConfig(raw string) (*Config, error) {
+	cfg := &Config{}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), cfg); err != nil {
+			return nil, fmt.Errorf("invalid vault config: %w", err)
+		}
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("vault address is required")
+	}
+	if cfg.Token == "" && (cfg.RoleID == "" || cfg.SecretID == "") {
+		return nil, fmt.Errorf("either token or roleId/secretId is required")
+	}
+	if cfg.Engine == "" {
+		cfg.Engine = EngineKV
+	}
+	switch cfg.Engine {
+	case EngineKV, EngineTransit:
+	default:
+		return nil, fmt.Errorf("unsupported vault engine: %s", cfg.Engine)
+	}
+	if cfg.KVMount == "" {
+		cfg.KVMount = "secret"
+	}
+	if cfg.PathPrefix == "" {
+		cfg.PathPrefix = "chainlaunch/keys"
+	}
+	if cfg.TransitMount == "" {
+		cfg.TransitMount = "transit"
+	}
+	if cfg.TransitKey == "" {
+		cfg.TransitKey = "chainlaunch"
+	}
+	return cfg, nil
+}
+
+// ConfigFromEnv builds a client configuration from the standard VAULT_ADDR,
+// VAULT_TOKEN (or VAULT_ROLE_ID and VAULT_SECRET_ID), VAULT_NAMESPACE and
+// VAULT_CACERT variables
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{
+		Address:   os.Getenv("VAULT_ADDR"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		RoleID:    os.Getenv("VAULT_ROLE_ID"),
+		SecretID:  os.Getenv("VAULT_SECRET_ID"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("VAULT_ADDR is not set")
+	}
+	if path := os.Getenv("VAULT_CACERT"); path != "" {
+		caCert, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read vault CA certificate: %w", err)
+		}
+		cfg.CACert = string(caCert)
+	}
+	return cfg, nil
+}
+
+// VaultProvider keeps key metadata (public key, certificate, fingerprints) in
+// the database like the database provider, but the private key material is
+// held by Vault
+type VaultProvider struct {
+	*database.DatabaseProvider
+}
+
+// NewVaultProvider creates a provider for the given key_providers config and
+// checks that Vault is reachable with the configured credentials
+func NewVaultProvider(ctx context.Context, queries *db.Queries, rawConfig string) (*VaultProvider, error) {
+	cfg, err := ParseConfig(rawConfig)
+	if err != nil {
+		return nil, err
+	}
+	client, err := NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to vault: %w", err)
+	}
+	if cfg.Engine == EngineTransit {
+		if err := client.EnsureTransitKey(ctx, cfg.TransitMount, cfg.TransitKey); err != nil {
+			return nil, fmt.Errorf("failed to prepare transit key: %w", err)
+		}
+	}
+
+	store := &keyStore{client: client, config: cfg}
+	return &VaultProvider{
+		DatabaseProvider: database.NewDatabaseProviderWithStore(queries, store),
+	}, nil
+}
+
+// sealedKey is the value persisted in keys.private_key for Vault backed keys
+type sealedKey struct {
+	Engine     Engine `json:"vaultEngine"`
+	Mount      string `json:"mount"`
+	Path       string `json:"path,omitempty"`
+	Key        string `json:"key,omitempty"`
+	Ciphertext string `json:"ciphertext,omitempty"`
+}
+
+// keyStore implements database.PrivateKeyStore on top of Vault
+type keyStore struct {
+	client *Client
+	config *Config
+}
+
+func (s *keyStore) Seal(ctx context.Context, plaintext string) (string, error) {
+	var sealed sealedKey
+	switch s.config.Engine {
+	case EngineTransit:
+		ciphertext, err := s.client.TransitEncrypt(ctx, s.config.TransitMount, s.config.TransitKey, base64.StdEncoding.EncodeToString([]byte(plaintext)))
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt private key with transit: %w", err)
+		}
+		sealed = sealedKey{
+			Engine:     EngineTransit,
+			Mount:      s.config.TransitMount,
+			Key:        s.config.TransitKey,
+			Ciphertext: ciphertext,
+		}
+	default:
+		secretPath := path.Join(s.config.PathPrefix, uuid.New().String())
+		if err := s.client.WriteKV(ctx, s.config.KVMount, secretPath, map[string]interface{}{
+			"private_key": plaintext,
+		}); err != nil {
+			return "", fmt.Errorf("failed to write private key to vault: %w", err)
+		}
+		sealed = sealedKey{
+			Engine: EngineKV,
+			Mount:  s.config.KVMount,
+			Path:   secretPath,
+		}
+	}
+
+	data, err := json.Marshal(sealed)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (s *keyStore) Open(ctx context.Context, value string) (string, error) {
+	sealed, err := parseSealedKey(value)
+	if err != nil {
+		return "", err
+	}
+	switch sealed.Engine {
+	case EngineTransit:
+		plaintextB64, err := s.client.TransitDecrypt(ctx, sealed.Mount, sealed.Key, sealed.Ciphertext)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt private key with transit: %w", err)
+		}
+		plaintext, err := base64.StdEncoding.DecodeString(plaintextB64)
+		if err != nil {
+			return "", fmt.Errorf("invalid transit plaintext: %w", err)
+		}
+		return string(plaintext), nil
+	case EngineKV:
+		data, err := s.client.ReadKV(ctx, sealed.Mount, sealed.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read private key from vault: %w", err)
+		}
+		privateKey, ok := data["private_key"].(string)
+		if !ok {
+			return "", fmt.Errorf("vault secret %s has no private_key field", sealed.Path)
+		}
+		return privateKey, nil
+	default:
+		return "", fmt.Errorf("unsupported vault engine: %s", sealed.Engine)
+	}
+}
+
+func (s *keyStore) Remove(ctx context.Context, value string) error {
+	sealed, err := parseSealedKey(value)
+	if err != nil {
+		return err
+	}
+	if sealed.Engine != EngineKV {
+		// Transit ciphertext lives in the deleted row, nothing to clean up in Vault
+		return nil
+	}
+	return s.client.DeleteKV(ctx, sealed.Mount, sealed.Path)
+}
+
+func parseSealedKey(value string) (*sealedKey, error) {
+	var sealed sealedKey
+	if err := json.Unmarshal([]byte(value), &sealed); err != nil {
+		return nil, fmt.Errorf("invalid vault key reference: %w", err)
+	}
+	if sealed.Engine == "" {
+		return nil, fmt.Errorf("private key is not managed by vault")
+	}
+	return &sealed, nil
+}
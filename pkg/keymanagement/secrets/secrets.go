@@ -0,0 +1,217 @@
+// Package secrets protects credentials that are stored in the database next to
+// the data they give access to, such as the secret keys and repository
+// passwords of backup targets or the passwords of SMTP notification providers.
+//
+// Values are sealed with the key store of the database key provider, so they
+// are encrypted with the active key encryption key and follow its rotation.
+// Instead of a value, a reference to a secret kept outside the database can be
+// given; references are stored as is and resolved every time the secret is used:
+//
+//	env:NAME                 the environment variable NAME
+//	file:/path/to/secret     the content of a file, without trailing newlines
+//	vault:mount/path#field   a field of a Vault KV v2 secret, read with the
+//	                         VAULT_ADDR, VAULT_TOKEN (or VAULT_ROLE_ID and
+//	                         VAULT_SECRET_ID), VAULT_NAMESPACE and VAULT_CACERT variables
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/kek"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/database"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/vault"
+)
+
+// sealedPrefix marks values encrypted by the store
+const sealedPrefix = "sealed:"
+
+// Prefixes of the references to secrets kept outside the database
+const (
+	RefEnv   = "env:"
+	RefFile  = "file:"
+	RefVault = "vault:"
+)
+
+// Resealer reseals the secrets a service stores in the database with q and
+// returns how many values it updated
+type Resealer func(ctx context.Context, q *db.Queries) (int, error)
+
+// Store seals secrets before they are written to the database and opens them when they are used
+type Store struct {
+	ring *database.KeyRing
+	keys database.PrivateKeyStore
+
+	mu        sync.Mutex
+	vault     *vault.Client
+	resealers []namedResealer
+}
+
+type namedResealer struct {
+	name   string
+	reseal Resealer
+}
+
+// NewStore creates a store encrypting secrets with the active key of the ring
+func NewStore(ring *database.KeyRing) *Store {
+	return &Store{
+		ring: ring,
+		keys: database.NewKeyStore(ring),
+	}
+}
+
+// Load creates a store with the key encryption keys configured in the environment
+func Load(ctx context.Context) (*Store, error) {
+	ring, err := kek.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load key encryption key: %w", err)
+	}
+	return NewStore(ring), nil
+}
+
+// IsReference reports whether a value refers to a secret kept outside the database
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, RefEnv) || strings.HasPrefix(value, RefFile) || strings.HasPrefix(value, RefVault)
+}
+
+// IsSealed reports whether a value was sealed by a store
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// Seal returns the value to persist for a secret. Empty values, references
+// and values which are already sealed are returned unchanged.
+func (s *Store) Seal(ctx context.Context, value string) (string, error) {
+	if value == "" || IsReference(value) || IsSealed(value) {
+		return value, nil
+	}
+	sealed, err := s.keys.Seal(ctx, value)
+	if err != nil {
+		return "", fmt.Errorf("failed to seal secret: %w", err)
+	}
+	return sealedPrefix + sealed, nil
+}
+
+// Open returns the secret for a value previously returned by Seal, resolving
+// references. Values stored before secrets were sealed are returned unchanged.
+func (s *Store) Open(ctx context.Context, value string) (string, error) {
+	switch {
+	case IsSealed(value):
+		secret, err := s.keys.Open(ctx, strings.TrimPrefix(value, sealedPrefix))
+		if err != nil {
+			return "", fmt.Errorf("failed to open secret: %w", err)
+		}
+		return secret, nil
+	case strings.HasPrefix(value, RefEnv):
+		name := strings.TrimPrefix(value, RefEnv)
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s of secret reference is not set", name)
+		}
+		return secret, nil
+	case strings.HasPrefix(value, RefFile):
+		data, err := os.ReadFile(strings.TrimPrefix(value, RefFile))
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	case strings.HasPrefix(value, RefVault):
+		return s.readVault(ctx, strings.TrimPrefix(value, RefVault))
+	}
+	return value, nil
+}
+
+// Reseal returns the value to persist in place of a stored one when it is in
+// plaintext or encrypted with a previous key encryption key, and false when
+// the stored value can be kept
+func (s *Store) Reseal(ctx context.Context, value string) (string, bool, error) {
+	if value == "" || IsReference(value) {
+		return value, false, nil
+	}
+	if IsSealed(value) {
+		if s.ring.IsActive(strings.TrimPrefix(value, sealedPrefix)) {
+			return value, false, nil
+		}
+		secret, err := s.Open(ctx, value)
+		if err != nil {
+			return "", false, err
+		}
+		value = secret
+	}
+	sealed, err := s.Seal(ctx, value)
+	if err != nil {
+		return "", false, err
+	}
+	return sealed, true, nil
+}
+
+// RegisterResealer adds the secrets of a service to the ones resealed by ResealAll
+func (s *Store) RegisterResealer(name string, reseal Resealer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resealers = append(s.resealers, namedResealer{name: name, reseal: reseal})
+}
+
+// ResealAll runs the registered resealers with q, so that rekeying updates
+// every stored secret in the transaction of the private keys. It returns how
+// many values were updated.
+func (s *Store) ResealAll(ctx context.Context, q *db.Queries) (int, error) {
+	s.mu.Lock()
+	resealers := append([]namedResealer(nil), s.resealers...)
+	s.mu.Unlock()
+
+	total := 0
+	for _, r := range resealers {
+		n, err := r.reseal(ctx, q)
+		if err != nil {
+			return 0, fmt.Errorf("failed to reseal %s: %w", r.name, err)
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// readVault reads the field of a KV v2 secret given as mount/path#field
+func (s *Store) readVault(ctx context.Context, ref string) (string, error) {
+	location, field, ok := strings.Cut(ref, "#")
+	mount, secretPath, hasPath := strings.Cut(location, "/")
+	if !ok || !hasPath || field == "" || secretPath == "" {
+		return "", fmt.Errorf("invalid vault secret reference %q, expected vault:mount/path#field", ref)
+	}
+
+	client, err := s.vaultClient()
+	if err != nil {
+		return "", err
+	}
+	data, err := client.ReadKV(ctx, mount, secretPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read vault secret %s: %w", location, err)
+	}
+	secret, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no %s field", location, field)
+	}
+	return secret, nil
+}
+
+func (s *Store) vaultClient() (*vault.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.vault != nil {
+		return s.vault, nil
+	}
+	cfg, err := vault.ConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure vault for secret references: %w", err)
+	}
+	client, err := vault.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.vault = client
+	return client, nil
+}
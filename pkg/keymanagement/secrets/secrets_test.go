@@ -0,0 +1,144 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/database"
+)
+
+func newTestStore(t *testing.T, active []byte, previous ...[]byte) *Store {
+	ring, err := database.NewKeyRing(active, previous...)
+	if err != nil {
+		t.Fatalf("failed to create key ring: %v", err)
+	}
+	return NewStore(ring)
+}
+
+func TestSealOpen(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t, bytes.Repeat([]byte{1}, 32))
+
+	sealed, err := store.Seal(ctx, "password")
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "password") {
+		t.Fatalf("unexpected sealed value %s", sealed)
+	}
+	opened, err := store.Open(ctx, sealed)
+	if err != nil || opened != "password" {
+		t.Fatalf("expected password, got %q (%v)", opened, err)
+	}
+
+	// Sealing is idempotent and leaves empty values and references alone
+	for _, value := range []string{"", sealed, "env:SMTP_PASSWORD", "file:/run/secrets/key", "vault:secret/smtp#password"} {
+		got, err := store.Seal(ctx, value)
+		if err != nil || got != value {
+			t.Errorf("%q: expected the value unchanged, got %q (%v)", value, got, err)
+		}
+	}
+
+	// Values stored before secrets were sealed are returned as is
+	if opened, err := store.Open(ctx, "plaintext"); err != nil || opened != "plaintext" {
+		t.Errorf("expected plaintext, got %q (%v)", opened, err)
+	}
+
+	other := newTestStore(t, bytes.Repeat([]byte{2}, 32))
+	if _, err := other.Open(ctx, sealed); err == nil {
+		t.Error("opening with another key: expected an error")
+	}
+}
+
+func TestOpenReferences(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t, bytes.Repeat([]byte{1}, 32))
+
+	t.Setenv("CHAINLAUNCH_TEST_SECRET", "from-env")
+	file := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(file, []byte("from-file\r\n"), 0600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+
+	cases := map[string]string{
+		"env:CHAINLAUNCH_TEST_SECRET": "from-env",
+		"file:" + file:                "from-file",
+	}
+	for ref, expected := range cases {
+		got, err := store.Open(ctx, ref)
+		if err != nil || got != expected {
+			t.Errorf("%s: expected %q, got %q (%v)", ref, expected, got, err)
+		}
+	}
+
+	invalid := []string{
+		"env:CHAINLAUNCH_TEST_MISSING",
+		"file:" + filepath.Join(t.TempDir(), "missing"),
+		"vault:secret",
+		"vault:secret/smtp",
+		"vault:secret#password",
+		"sealed:not-json",
+	}
+	for _, ref := range invalid {
+		if _, err := store.Open(ctx, ref); err == nil {
+			t.Errorf("%s: expected an error", ref)
+		}
+	}
+}
+
+func TestReseal(t *testing.T) {
+	ctx := context.Background()
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	sealedWithOld, err := newTestStore(t, oldKey).Seal(ctx, "password")
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+
+	store := newTestStore(t, newKey, oldKey)
+	sealedWithNew, err := store.Seal(ctx, "password")
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		value    string
+		resealed bool
+	}{
+		{"empty", "", false},
+		{"reference", "env:SMTP_PASSWORD", false},
+		{"active key", sealedWithNew, false},
+		{"previous key", sealedWithOld, true},
+		{"plaintext", "password", true},
+	}
+	for _, c := range cases {
+		value, resealed, err := store.Reseal(ctx, c.value)
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		if resealed != c.resealed {
+			t.Errorf("%s: expected resealed %v, got %v", c.name, c.resealed, resealed)
+		}
+		if !resealed {
+			if value != c.value {
+				t.Errorf("%s: expected the value unchanged, got %q", c.name, value)
+			}
+			continue
+		}
+		if opened, err := newTestStore(t, newKey).Open(ctx, value); err != nil || opened != "password" {
+			t.Errorf("%s: expected a value sealed with the active key, got %q (%v)", c.name, opened, err)
+		}
+	}
+
+	// A value sealed with a key missing from the ring can't be resealed
+	if _, _, err := newTestStore(t, newKey).Reseal(ctx, sealedWithOld); err == nil {
+		t.Error("unknown key: expected an error")
+	}
+}
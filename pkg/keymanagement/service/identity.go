@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+)
+
+// SigningIdentity is a Fabric signing identity whose signatures are made by the
+// provider of its key, so it works for keys held in an HSM as well as for keys
+// stored in the database. It implements the SigningIdentity interface of the
+// fabric-admin-sdk.
+type SigningIdentity struct {
+	mspID       string
+	credentials []byte
+	certificate *x509.Certificate
+	signer      gocrypto.Signer
+}
+
+// NewSigningIdentity creates a signing identity from a PEM certificate and the
+// signer of its ECDSA key
+func NewSigningIdentity(mspID string, certPEM []byte, signer gocrypto.Signer) (*SigningIdentity, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	if err := checkSignerMatches(cert, signer); err != nil {
+		return nil, err
+	}
+	return &SigningIdentity{
+		mspID:       mspID,
+		credentials: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+		certificate: cert,
+		signer:      signer,
+	}, nil
+}
+
+// MspID returns the ID of the MSP the identity belongs to
+func (id *SigningIdentity) MspID() string {
+	return id.mspID
+}
+
+// Credentials returns the PEM certificate of the identity
+func (id *SigningIdentity) Credentials() []byte {
+	return id.credentials
+}
+
+// Certificate returns the parsed certificate of the identity
+func (id *SigningIdentity) Certificate() *x509.Certificate {
+	return id.certificate
+}
+
+// Sign hashes the message with SHA-256 and signs the digest
+func (id *SigningIdentity) Sign(message []byte) ([]byte, error) {
+	digest := sha256.Sum256(message)
+	return id.SignDigest(digest[:])
+}
+
+// SignDigest signs a precomputed digest and returns an ASN.1 encoded low-S
+// signature, the form Fabric requires. It can be used as the Sign function of
+// a fabric-gateway connection.
+func (id *SigningIdentity) SignDigest(digest []byte) ([]byte, error) {
+	signature, err := id.signer.Sign(rand.Reader, digest, gocrypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return toLowS(id.signer.Public().(*ecdsa.PublicKey), signature)
+}
+
+// TLSCertificate returns the identity as a TLS client certificate
+func (id *SigningIdentity) TLSCertificate() tls.Certificate {
+	return tls.Certificate{
+		Certificate: [][]byte{id.certificate.Raw},
+		PrivateKey:  id.signer,
+		Leaf:        id.certificate,
+	}
+}
+
+// GetSigningIdentity returns a signing identity for a key with a certificate. The
+// private key isn't exported, signatures are made by the key's provider.
+func (s *KeyManagementService) GetSigningIdentity(ctx context.Context, keyID int, mspID string) (*SigningIdentity, error) {
+	key, err := s.GetKey(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+	if key.Certificate == nil {
+		return nil, fmt.Errorf("key %d has no certificate", keyID)
+	}
+	signer, err := s.GetSigner(ctx, keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signer: %w", err)
+	}
+	return NewSigningIdentity(mspID, []byte(*key.Certificate), signer)
+}
+
+// checkSignerMatches fails when the signer isn't an ECDSA key or doesn't belong
+// to the certificate
+func checkSignerMatches(cert *x509.Certificate, signer gocrypto.Signer) error {
+	public, ok := signer.Public().(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("unsupported key type %T, Fabric identities need an ECDSA key", signer.Public())
+	}
+	certPublic, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || !public.Equal(certPublic) {
+		return fmt.Errorf("private key does not match the certificate")
+	}
+	return nil
+}
+
+type ecdsaSignature struct {
+	R, S *big.Int
+}
+
+// toLowS rewrites an ASN.1 ECDSA signature so S is in the lower half of the
+// curve order
+func toLowS(public *ecdsa.PublicKey, signature []byte) ([]byte, error) {
+	sig := ecdsaSignature{}
+	rest, err := asn1.Unmarshal(signature, &sig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signature: %w", err)
+	}
+	if len(rest) != 0 || sig.R == nil || sig.S == nil {
+		return nil, fmt.Errorf("invalid signature encoding")
+	}
+	n := public.Curve.Params().N
+	halfOrder := new(big.Int).Rsh(n, 1)
+	if sig.S.Cmp(halfOrder) <= 0 {
+		return signature, nil
+	}
+	sig.S = new(big.Int).Sub(n, sig.S)
+	return asn1.Marshal(sig)
+}
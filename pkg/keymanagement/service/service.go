@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	gocrypto "crypto"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
@@ -18,16 +19,22 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"os"
 
 	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/kek"
 	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/models"
 	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/database"
 	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/types"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/secrets"
 )
 
 type KeyManagementService struct {
 	queries         *db.Queries
 	providerFactory *providers.ProviderFactory
+	keyFile         string
+	secrets         *secrets.Store
 }
 
 func NewKeyManagementService(queries *db.Queries) (*KeyManagementService, error) {
@@ -42,6 +49,19 @@ func NewKeyManagementService(queries *db.Queries) (*KeyManagementService, error)
 	}, nil
 }
 
+// SetKeyEncryptionKeyFile sets the key file of the data directory the server
+// falls back to when no key encryption key is configured in the environment.
+// Rekeying writes the active key to it, if it exists.
+func (s *KeyManagementService) SetKeyEncryptionKeyFile(path string) {
+	s.keyFile = path
+}
+
+// SetSecretStore sets the store whose secrets are resealed with the private
+// keys when rekeying
+func (s *KeyManagementService) SetSecretStore(store *secrets.Store) {
+	s.secrets = store
+}
+
 func (s *KeyManagementService) InitializeKeyProviders(ctx context.Context) error {
 	// Check if default provider exists
 	_, err := s.queries.GetKeyProviderByDefault(ctx)
@@ -71,8 +91,16 @@ func (s *KeyManagementService) CreateKey(ctx context.Context, req models.CreateK
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
-	// Get provider
-	provider, err := s.providerFactory.GetProvider(providers.ProviderTypeDatabase)
+	// Resolve the provider, falling back to the default one
+	if req.ProviderID == nil {
+		defaultProvider, err := s.queries.GetKeyProviderByDefault(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get default provider: %w", err)
+		}
+		providerID := int(defaultProvider.ID)
+		req.ProviderID = &providerID
+	}
+	provider, err := s.providerFactory.GetProviderByID(ctx, int64(*req.ProviderID))
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +250,11 @@ func (s *KeyManagementService) GetKey(ctx context.Context, id int) (*models.KeyR
 }
 
 func (s *KeyManagementService) DeleteKey(ctx context.Context, id int) error {
-	err := s.queries.DeleteKey(ctx, int64(id))
+	provider, err := s.providerForKey(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = provider.DeleteKey(ctx, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return errors.New("key not found")
@@ -232,6 +264,18 @@ func (s *KeyManagementService) DeleteKey(ctx context.Context, id int) error {
 	return nil
 }
 
+// providerForKey returns the provider that holds the private key of the given key
+func (s *KeyManagementService) providerForKey(ctx context.Context, id int) (providers.Provider, error) {
+	key, err := s.queries.GetKey(ctx, int64(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("key not found")
+		}
+		return nil, err
+	}
+	return s.providerFactory.GetProviderByID(ctx, key.ProviderID)
+}
+
 func (s *KeyManagementService) CreateProvider(ctx context.Context, req models.CreateProviderRequest) (*models.ProviderResponse, error) {
 	if err := req.Validate(); err != nil {
 		return nil, err
@@ -246,9 +290,14 @@ func (s *KeyManagementService) CreateProvider(ctx context.Context, req models.Cr
 	}
 
 	// Convert config to JSON string
-	configJSON, err := json.Marshal(req.Config)
-	if err != nil {
-		return nil, err
+	configJSON := []byte("{}")
+	if len(req.Config) > 0 {
+		configJSON = req.Config
+	}
+
+	// Make sure the provider is usable before persisting it
+	if err := s.providerFactory.ValidateConfig(ctx, providers.ProviderType(req.Type), string(configJSON)); err != nil {
+		return nil, fmt.Errorf("invalid provider configuration: %w", err)
 	}
 
 	provider, err := s.queries.CreateKeyProvider(ctx, &db.CreateKeyProviderParams{
@@ -273,14 +322,7 @@ func (s *KeyManagementService) ListProviders(ctx context.Context) ([]models.Prov
 	// Map DB models to service models
 	providerResponses := make([]models.ProviderResponse, len(providers))
 	for i, provider := range providers {
-		providerResponses[i] = models.ProviderResponse{
-			ID:        int(provider.ID),
-			Name:      provider.Name,
-			Type:      models.KeyProviderType(provider.Type),
-			IsDefault: int(provider.IsDefault),
-			Config:    json.RawMessage(provider.Config),
-			CreatedAt: provider.CreatedAt,
-		}
+		providerResponses[i] = *mapProviderToResponse(provider)
 	}
 
 	return providerResponses, nil
@@ -315,6 +357,7 @@ func (s *KeyManagementService) DeleteProvider(ctx context.Context, id int) error
 		}
 		return err
 	}
+	s.providerFactory.Forget(int64(id))
 	return nil
 }
 
@@ -324,11 +367,33 @@ func mapProviderToResponse(provider *db.KeyProvider) *models.ProviderResponse {
 		Name:      provider.Name,
 		Type:      models.KeyProviderType(provider.Type),
 		IsDefault: int(provider.IsDefault),
-		Config:    json.RawMessage(provider.Config),
+		Config:    redactProviderConfig(provider.Config),
 		CreatedAt: provider.CreatedAt,
 	}
 }
 
+// secretConfigFields are provider config fields that must never be returned by the API:
+// the Vault token and AppRole credentials, and the PKCS#11 user PIN
+var secretConfigFields = []string{"token", "roleId", "secretId", "pin"}
+
+// redactProviderConfig strips credentials from a provider config before returning it
+func redactProviderConfig(config string) json.RawMessage {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(config), &fields); err != nil {
+		return json.RawMessage(config)
+	}
+	for _, field := range secretConfigFields {
+		if _, ok := fields[field]; ok {
+			fields[field] = "********"
+		}
+	}
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return json.RawMessage("{}")
+	}
+	return redacted
+}
+
 // KeyPair represents a public/private key pair
 type KeyPair struct {
 	PublicKey         string
@@ -516,8 +581,8 @@ func (s *KeyManagementService) SignCertificate(ctx context.Context, keyID int, c
 		return nil, fmt.Errorf("key %d is not a CA, value: %d", caKeyID, caKey.IsCa)
 	}
 
-	// Get provider
-	provider, err := s.providerFactory.GetProvider(providers.ProviderTypeDatabase)
+	// The CA key's provider holds the private key used for signing
+	provider, err := s.providerFactory.GetProviderByID(ctx, caKey.ProviderID)
 	if err != nil {
 		return nil, err
 	}
@@ -533,7 +598,7 @@ func (s *KeyManagementService) SignCertificate(ctx context.Context, keyID int, c
 // GetDecryptedPrivateKey retrieves and decrypts the private key for a given key ID
 func (s *KeyManagementService) GetDecryptedPrivateKey(id int) (string, error) {
 	// Get provider
-	provider, err := s.providerFactory.GetProvider(providers.ProviderTypeDatabase)
+	provider, err := s.providerForKey(context.Background(), id)
 	if err != nil {
 		return "", fmt.Errorf("failed to get provider: %w", err)
 	}
@@ -547,6 +612,102 @@ func (s *KeyManagementService) GetDecryptedPrivateKey(id int) (string, error) {
 	return pk, nil
 }
 
+// GetSigner returns a signer for the key. Unlike GetDecryptedPrivateKey it also
+// works for keys whose private material can't leave the provider (HSM).
+func (s *KeyManagementService) GetSigner(ctx context.Context, id int) (gocrypto.Signer, error) {
+	provider, err := s.providerForKey(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+	return provider.GetSigner(ctx, id)
+}
+
+// GetPKCS11Settings returns the token settings for a key held in a PKCS#11
+// token, or nil if the key's private material is exportable
+func (s *KeyManagementService) GetPKCS11Settings(ctx context.Context, id int) (*types.PKCS11Settings, error) {
+	provider, err := s.providerForKey(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get provider: %w", err)
+	}
+	pkcs11Provider, ok := provider.(providers.PKCS11Provider)
+	if !ok {
+		return nil, nil
+	}
+	settings := pkcs11Provider.PKCS11Settings()
+	return &settings, nil
+}
+
+// ExportableProviderID returns providerID if its keys can be exported (needed
+// e.g. for TLS keys written to disk), otherwise the default provider's ID
+func (s *KeyManagementService) ExportableProviderID(ctx context.Context, providerID int) (int, error) {
+	provider, err := s.providerFactory.GetProviderByID(ctx, int64(providerID))
+	if err != nil {
+		return 0, err
+	}
+	if _, ok := provider.(providers.PKCS11Provider); !ok {
+		return providerID, nil
+	}
+	defaultProvider, err := s.queries.GetKeyProviderByDefault(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get default provider: %w", err)
+	}
+	if defaultProvider.Type == string(providers.ProviderTypeHSM) {
+		return 0, fmt.Errorf("no exportable key provider available")
+	}
+	return int(defaultProvider.ID), nil
+}
+
+// RekeyDatabaseKeys re-encrypts all private keys held by the database provider
+// and the secrets of the secret store with the active key encryption key. Both
+// are updated in a single transaction, so a failure leaves them untouched.
+func (s *KeyManagementService) RekeyDatabaseKeys(ctx context.Context) (*models.RekeyResponse, error) {
+	provider, err := s.providerFactory.GetProvider(providers.ProviderTypeDatabase)
+	if err != nil {
+		return nil, err
+	}
+	rekeyer, ok := provider.(interface {
+		Rekey(ctx context.Context, q *db.Queries) (*database.RekeyResult, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("database provider does not support rekeying")
+	}
+	resp := &models.RekeyResponse{}
+	err = s.queries.RunInTx(ctx, func(q *db.Queries) error {
+		result, err := rekeyer.Rekey(ctx, q)
+		if err != nil {
+			return fmt.Errorf("failed to rekey private keys: %w", err)
+		}
+		resp.ActiveKeyID = result.ActiveKeyID
+		resp.Rekeyed = result.Rekeyed
+		resp.Unchanged = result.Unchanged
+		if s.secrets == nil {
+			return nil
+		}
+		resp.ResealedSecrets, err = s.secrets.ResealAll(ctx, q)
+		if err != nil {
+			return fmt.Errorf("failed to reseal secrets: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the fallback key file in sync, otherwise a restart without the
+	// environment variables would load the old key
+	if s.keyFile != "" {
+		if _, err := os.Stat(s.keyFile); err == nil {
+			if err := kek.PersistActive(s.keyFile); err != nil {
+				return nil, fmt.Errorf("private keys were rekeyed but the key file could not be updated: %w", err)
+			}
+			resp.KeyFile = s.keyFile
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to check key file: %w", err)
+		}
+	}
+	return resp, nil
+}
+
 // FilterKeys returns keys filtered by algorithm and/or curve
 func (s *KeyManagementService) FilterKeys(ctx context.Context, algorithm, curve string, page, pageSize int) (*models.PaginatedResponse, error) {
 	var keys []*db.GetKeysByFilterRow
@@ -732,8 +893,8 @@ func (s *KeyManagementService) RenewCertificate(ctx context.Context, keyID int,
 		return nil, fmt.Errorf("key %d is not a CA", caKeyID)
 	}
 
-	// Get provider
-	provider, err := s.providerFactory.GetProvider(providers.ProviderTypeDatabase)
+	// The CA key's provider holds the private key used for signing
+	provider, err := s.providerFactory.GetProviderByID(ctx, caKey.ProviderID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
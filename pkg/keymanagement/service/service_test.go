@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/kek"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/models"
+)
+
+func newTestQueries(t *testing.T) *db.Queries {
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	driver, err := sqlite3.WithInstance(database, &sqlite3.Config{})
+	if err != nil {
+		t.Fatalf("failed to create sqlite driver: %v", err)
+	}
+	source, err := iofs.New(os.DirFS("../../db/migrations"), ".")
+	if err != nil {
+		t.Fatalf("failed to open migrations: %v", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", driver)
+	if err != nil {
+		t.Fatalf("failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return db.New(database)
+}
+
+func newTestKEK(t *testing.T) string {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return hex.EncodeToString(key)
+}
+
+// startService creates the service the way the server does at startup: without
+// KEY_ENCRYPTION_KEY the key is read from the data directory key file
+func startService(t *testing.T, queries *db.Queries, keyFile string) *KeyManagementService {
+	if !kek.Configured() {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			t.Fatalf("failed to read key file: %v", err)
+		}
+		t.Setenv(kek.EnvKey, string(data))
+	}
+	svc, err := NewKeyManagementService(queries)
+	if err != nil {
+		t.Fatalf("failed to create key management service: %v", err)
+	}
+	if err := svc.InitializeKeyProviders(context.Background()); err != nil {
+		t.Fatalf("failed to initialize key providers: %v", err)
+	}
+	svc.SetKeyEncryptionKeyFile(keyFile)
+	return svc
+}
+
+func TestRekeyUpdatesKeyFile(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	oldKey, newKey := newTestKEK(t), newTestKEK(t)
+	keyFile := filepath.Join(t.TempDir(), "encryption_key")
+	if err := os.WriteFile(keyFile, []byte(oldKey), 0600); err != nil {
+		t.Fatalf("failed to write key file: %v", err)
+	}
+	t.Setenv(kek.EnvKeyFile, "")
+	t.Setenv(kek.EnvPreviousKeys, "")
+
+	// Start with the key file and create a key
+	t.Setenv(kek.EnvKey, "")
+	svc := startService(t, queries, keyFile)
+	curve := models.ECCurveP256
+	key, err := svc.CreateKey(ctx, models.CreateKeyRequest{
+		Name:      "admin",
+		Algorithm: models.KeyAlgorithmEC,
+		Curve:     &curve,
+	}, 0)
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	privateKey, err := svc.GetDecryptedPrivateKey(key.ID)
+	if err != nil {
+		t.Fatalf("failed to decrypt key: %v", err)
+	}
+
+	// Rotate: new key in the environment, the old one as previous
+	t.Setenv(kek.EnvKey, newKey)
+	t.Setenv(kek.EnvPreviousKeys, oldKey)
+	svc = startService(t, queries, keyFile)
+	resp, err := svc.RekeyDatabaseKeys(ctx)
+	if err != nil {
+		t.Fatalf("failed to rekey: %v", err)
+	}
+	if resp.Rekeyed != 1 {
+		t.Fatalf("expected 1 rekeyed key, got %d", resp.Rekeyed)
+	}
+	if resp.KeyFile != keyFile {
+		t.Fatalf("expected the key file to be reported as updated, got %q", resp.KeyFile)
+	}
+	info, err := os.Stat(keyFile)
+	if err != nil {
+		t.Fatalf("failed to stat key file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected key file mode 0600, got %o", info.Mode().Perm())
+	}
+
+	// Restart from a clean environment, falling back to the key file
+	t.Setenv(kek.EnvKey, "")
+	t.Setenv(kek.EnvPreviousKeys, "")
+	svc = startService(t, queries, keyFile)
+	reloaded, err := svc.GetDecryptedPrivateKey(key.ID)
+	if err != nil {
+		t.Fatalf("failed to decrypt key after restarting without the environment: %v", err)
+	}
+	if reloaded != privateKey {
+		t.Fatal("decrypted key differs after rekeying")
+	}
+}
+
+func TestRekeyWithoutKeyFile(t *testing.T) {
+	queries := newTestQueries(t)
+	t.Setenv(kek.EnvKey, newTestKEK(t))
+	t.Setenv(kek.EnvKeyFile, "")
+	t.Setenv(kek.EnvPreviousKeys, "")
+
+	keyFile := filepath.Join(t.TempDir(), "encryption_key")
+	svc := startService(t, queries, keyFile)
+	resp, err := svc.RekeyDatabaseKeys(context.Background())
+	if err != nil {
+		t.Fatalf("failed to rekey: %v", err)
+	}
+	if resp.KeyFile != "" {
+		t.Fatalf("expected no key file update, got %q", resp.KeyFile)
+	}
+	if _, err := os.Stat(keyFile); !os.IsNotExist(err) {
+		t.Fatal("rekey must not create a key file that didn't exist")
+	}
+}
@@ -0,0 +1,242 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+// Handler handles HTTP requests for alert rules and alerts
+type Handler struct {
+	service *Service
+	logger  *logger.Logger
+}
+
+// NewHandler creates a new alerts handler
+func NewHandler(service *Service, logger *logger.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers the alerts routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/alerts", func(r chi.Router) {
+		r.Get("/", h.ListActiveAlerts)
+		r.Get("/rules", h.ListRules)
+		r.Post("/rules", h.CreateRule)
+		r.Get("/rules/{id}", h.GetRule)
+		r.Put("/rules/{id}", h.UpdateRule)
+		r.Delete("/rules/{id}", h.DeleteRule)
+		r.Get("/rules/{id}/alerts", h.ListRuleAlerts)
+	})
+}
+
+// ListActiveAlerts returns the pending and firing alerts
+// @Summary List active alerts
+// @Description Returns the pending and firing alerts of every alert rule
+// @Tags Alerts
+// @Produce json
+// @Success 200 {array} Alert
+// @Failure 500 {string} string
+// @Router /alerts [get]
+func (h *Handler) ListActiveAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.service.ListActiveAlerts(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list active alerts", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+// ListRules returns every alert rule
+// @Summary List alert rules
+// @Description Returns every alert rule with the result of its last evaluation
+// @Tags Alerts
+// @Produce json
+// @Success 200 {array} Rule
+// @Failure 500 {string} string
+// @Router /alerts/rules [get]
+func (h *Handler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.service.ListRules(r.Context())
+	if err != nil {
+		h.logger.Error("Failed to list alert rules", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// CreateRule creates an alert rule
+// @Summary Create an alert rule
+// @Description Creates a PromQL alert rule, every series returned by the expression for the for-duration fires an alert
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param request body RuleRequest true "Alert rule"
+// @Success 201 {object} Rule
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /alerts/rules [post]
+func (h *Handler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req RuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.CreateRule(r.Context(), req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to create alert rule", err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// GetRule returns an alert rule
+// @Summary Get an alert rule
+// @Tags Alerts
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Success 200 {object} Rule
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Router /alerts/rules/{id} [get]
+func (h *Handler) GetRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid alert rule ID", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.GetRule(r.Context(), id)
+	if err != nil {
+		h.writeServiceError(w, "Failed to get alert rule", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// UpdateRule updates an alert rule
+// @Summary Update an alert rule
+// @Description Updates an alert rule, disabling it resolves its active alerts without notifying
+// @Tags Alerts
+// @Accept json
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Param request body RuleRequest true "Alert rule"
+// @Success 200 {object} Rule
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /alerts/rules/{id} [put]
+func (h *Handler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid alert rule ID", http.StatusBadRequest)
+		return
+	}
+	var req RuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rule, err := h.service.UpdateRule(r.Context(), id, req)
+	if err != nil {
+		h.writeServiceError(w, "Failed to update alert rule", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// DeleteRule deletes an alert rule
+// @Summary Delete an alert rule
+// @Description Deletes an alert rule and all of its alerts
+// @Tags Alerts
+// @Param id path int true "Alert rule ID"
+// @Success 204
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /alerts/rules/{id} [delete]
+func (h *Handler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid alert rule ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteRule(r.Context(), id); err != nil {
+		h.writeServiceError(w, "Failed to delete alert rule", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRuleAlerts returns the most recent alerts of a rule
+// @Summary List the alerts of a rule
+// @Description Returns the most recent alerts of a rule, including resolved ones
+// @Tags Alerts
+// @Produce json
+// @Param id path int true "Alert rule ID"
+// @Param limit query int false "Maximum number of alerts (default 100)"
+// @Success 200 {array} Alert
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /alerts/rules/{id}/alerts [get]
+func (h *Handler) ListRuleAlerts(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid alert rule ID", http.StatusBadRequest)
+		return
+	}
+	var limit int64
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.ParseInt(limitStr, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	alerts, err := h.service.ListRuleAlerts(r.Context(), id, limit)
+	if err != nil {
+		h.writeServiceError(w, "Failed to list alerts of rule", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, alerts)
+}
+
+// writeServiceError maps the errors of the service to HTTP status codes
+func (h *Handler) writeServiceError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrRuleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrInvalidRule):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error(msg, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
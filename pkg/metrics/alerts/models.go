@@ -0,0 +1,119 @@
+package alerts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrRuleNotFound is returned when an alert rule does not exist
+	ErrRuleNotFound = errors.New("alert rule not found")
+	// ErrInvalidRule is returned when an alert rule fails validation
+	ErrInvalidRule = errors.New("invalid alert rule")
+)
+
+// Severity represents how urgent the alerts of a rule are
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// AlertState represents the state of an alert
+type AlertState string
+
+const (
+	// AlertStatePending indicates the expression returned the series for less than the rule's for-duration
+	AlertStatePending AlertState = "pending"
+	// AlertStateFiring indicates the expression returned the series for at least the rule's for-duration
+	AlertStateFiring AlertState = "firing"
+	// AlertStateResolved indicates a firing alert's series is no longer returned by the expression
+	AlertStateResolved AlertState = "resolved"
+)
+
+// Config represents the configuration for the alerting service
+type Config struct {
+	// EvaluationInterval is how often every enabled rule is evaluated
+	EvaluationInterval time.Duration
+	// ResolvedRetention is how long resolved alerts are kept
+	ResolvedRetention time.Duration
+}
+
+// DefaultConfig returns a Config with sensible default values
+func DefaultConfig() *Config {
+	return &Config{
+		EvaluationInterval: 30 * time.Second,
+		ResolvedRetention:  30 * 24 * time.Hour,
+	}
+}
+
+// Rule represents a PromQL alert rule. Every series returned by the expression
+// is an alert, which fires once it has been returned for the for-duration.
+type Rule struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Expression  string   `json:"expression"`
+	ForSeconds  int64    `json:"forSeconds"`
+	Severity    Severity `json:"severity"`
+	// ProviderIDs are the notification providers alerts of the rule are sent to
+	ProviderIDs     []int64    `json:"providerIds"`
+	Enabled         bool       `json:"enabled"`
+	LastEvaluatedAt *time.Time `json:"lastEvaluatedAt,omitempty"`
+	// LastError is the error of the last evaluation, for instance an invalid expression
+	LastError string    `json:"lastError,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// RuleRequest represents the request to create or update an alert rule
+type RuleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Expression  string   `json:"expression"`
+	ForSeconds  int64    `json:"forSeconds"`
+	Severity    Severity `json:"severity"`
+	ProviderIDs []int64  `json:"providerIds"`
+	// Enabled defaults to true
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// Validate checks the fields of the request that don't require a database lookup
+func (r *RuleRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRule)
+	}
+	if strings.TrimSpace(r.Expression) == "" {
+		return fmt.Errorf("%w: expression is required", ErrInvalidRule)
+	}
+	if r.ForSeconds < 0 {
+		return fmt.Errorf("%w: forSeconds cannot be negative", ErrInvalidRule)
+	}
+	switch r.Severity {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+	default:
+		return fmt.Errorf("%w: severity must be one of info, warning, critical", ErrInvalidRule)
+	}
+	if len(r.ProviderIDs) == 0 {
+		return fmt.Errorf("%w: at least one notification provider is required", ErrInvalidRule)
+	}
+	return nil
+}
+
+// Alert represents a series returned by the expression of a rule
+type Alert struct {
+	ID         int64             `json:"id"`
+	RuleID     int64             `json:"ruleId"`
+	RuleName   string            `json:"ruleName,omitempty"`
+	Severity   Severity          `json:"severity,omitempty"`
+	Labels     map[string]string `json:"labels"`
+	State      AlertState        `json:"state"`
+	Value      float64           `json:"value"`
+	ActiveAt   time.Time         `json:"activeAt"`
+	FiredAt    *time.Time        `json:"firedAt,omitempty"`
+	ResolvedAt *time.Time        `json:"resolvedAt,omitempty"`
+}
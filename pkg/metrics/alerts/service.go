@@ -0,0 +1,534 @@
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	"github.com/chainlaunch/chainlaunch/pkg/metrics/common"
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+)
+
+// defaultAlertHistoryLimit is the number of alerts returned for a rule when no limit is given
+const defaultAlertHistoryLimit = 100
+
+// Service stores alert rules and evaluates them periodically against Prometheus
+type Service struct {
+	logger          *logger.Logger
+	config          *Config
+	queries         *db.Queries
+	metricsService  common.Service
+	notificationSvc notifications.Service
+	stopChan        chan struct{}
+	waitGroup       sync.WaitGroup
+	// evalMutex serializes evaluations with rule changes that clear active alerts
+	evalMutex sync.Mutex
+}
+
+// NewService creates a new alerting service
+func NewService(logger *logger.Logger, config *Config, queries *db.Queries, metricsService common.Service, notificationSvc notifications.Service) *Service {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	return &Service{
+		logger:          logger,
+		config:          config,
+		queries:         queries,
+		metricsService:  metricsService,
+		notificationSvc: notificationSvc,
+		stopChan:        make(chan struct{}),
+	}
+}
+
+// Start begins evaluating alert rules
+func (s *Service) Start(ctx context.Context) error {
+	if s.config.EvaluationInterval <= 0 {
+		return fmt.Errorf("evaluation interval must be positive")
+	}
+
+	s.waitGroup.Add(1)
+	go s.run(ctx)
+	return nil
+}
+
+// Stop stops evaluating alert rules
+func (s *Service) Stop() {
+	close(s.stopChan)
+	s.waitGroup.Wait()
+}
+
+// run evaluates every enabled rule on each tick and deletes expired resolved alerts
+func (s *Service) run(ctx context.Context) {
+	defer s.waitGroup.Done()
+
+	ticker := time.NewTicker(s.config.EvaluationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.evaluateRules(ctx)
+
+			cutoff := time.Now().UTC().Add(-s.config.ResolvedRetention)
+			if _, err := s.queries.DeleteAlertsResolvedBefore(ctx, sql.NullTime{Time: cutoff, Valid: true}); err != nil {
+				s.logger.Errorf("Failed to delete expired alerts: %v", err)
+			}
+		}
+	}
+}
+
+// evaluateRules evaluates every enabled rule
+func (s *Service) evaluateRules(ctx context.Context) {
+	rules, err := s.queries.ListEnabledAlertRules(ctx)
+	if err != nil {
+		s.logger.Errorf("Failed to list alert rules: %v", err)
+		return
+	}
+
+	s.evalMutex.Lock()
+	defer s.evalMutex.Unlock()
+	for _, rule := range rules {
+		s.evaluateRule(ctx, rule)
+	}
+}
+
+// evaluateRule runs the expression of a rule and moves its alerts between the
+// pending, firing and resolved states
+func (s *Service) evaluateRule(ctx context.Context, rule *db.AlertRule) {
+	now := time.Now().UTC()
+
+	series, evalErr := s.querySeries(ctx, rule.Expression)
+	var lastError sql.NullString
+	if evalErr != nil {
+		lastError = sql.NullString{String: evalErr.Error(), Valid: true}
+	}
+	if err := s.queries.UpdateAlertRuleEvaluation(ctx, &db.UpdateAlertRuleEvaluationParams{
+		LastEvaluatedAt: sql.NullTime{Time: now, Valid: true},
+		LastError:       lastError,
+		ID:              rule.ID,
+	}); err != nil {
+		s.logger.Errorf("Failed to update evaluation of alert rule %d: %v", rule.ID, err)
+	}
+	if evalErr != nil {
+		// Keep the alerts as they are, a failed query says nothing about the series
+		s.logger.Warn("Failed to evaluate alert rule", "ruleID", rule.ID, "rule", rule.Name, "error", evalErr)
+		return
+	}
+
+	active, err := s.queries.ListActiveAlertsByRule(ctx, rule.ID)
+	if err != nil {
+		s.logger.Errorf("Failed to list active alerts of rule %d: %v", rule.ID, err)
+		return
+	}
+	activeByLabels := make(map[string]*db.Alert, len(active))
+	for _, alert := range active {
+		activeByLabels[alert.Labels] = alert
+	}
+
+	forDuration := time.Duration(rule.ForSeconds) * time.Second
+	for labels, value := range series {
+		alert, exists := activeByLabels[labels]
+		delete(activeByLabels, labels)
+
+		if !exists {
+			state := AlertStatePending
+			var firedAt sql.NullTime
+			if forDuration == 0 {
+				state = AlertStateFiring
+				firedAt = sql.NullTime{Time: now, Valid: true}
+			}
+			created, err := s.queries.CreateAlert(ctx, &db.CreateAlertParams{
+				RuleID:   rule.ID,
+				Labels:   labels,
+				State:    string(state),
+				Value:    value,
+				ActiveAt: now,
+				FiredAt:  firedAt,
+			})
+			if err != nil {
+				s.logger.Errorf("Failed to create alert for rule %d: %v", rule.ID, err)
+				continue
+			}
+			if state == AlertStateFiring {
+				s.sendAlertNotification(ctx, rule, created)
+			}
+			continue
+		}
+
+		fired := alert.State == string(AlertStatePending) && now.Sub(alert.ActiveAt) >= forDuration
+		if fired {
+			alert.State = string(AlertStateFiring)
+			alert.FiredAt = sql.NullTime{Time: now, Valid: true}
+		}
+		alert.Value = value
+		if err := s.updateAlert(ctx, alert); err != nil {
+			s.logger.Errorf("Failed to update alert %d: %v", alert.ID, err)
+			continue
+		}
+		if fired {
+			s.sendAlertNotification(ctx, rule, alert)
+		}
+	}
+
+	// The series of the remaining alerts are no longer returned by the expression
+	for _, alert := range activeByLabels {
+		if alert.State == string(AlertStatePending) {
+			if err := s.queries.DeleteAlert(ctx, alert.ID); err != nil {
+				s.logger.Errorf("Failed to delete pending alert %d: %v", alert.ID, err)
+			}
+			continue
+		}
+		alert.State = string(AlertStateResolved)
+		alert.ResolvedAt = sql.NullTime{Time: now, Valid: true}
+		if err := s.updateAlert(ctx, alert); err != nil {
+			s.logger.Errorf("Failed to resolve alert %d: %v", alert.ID, err)
+			continue
+		}
+		s.sendAlertNotification(ctx, rule, alert)
+	}
+}
+
+// querySeries runs an expression and returns the value of every series it returned,
+// keyed by the JSON encoding of the series labels
+func (s *Service) querySeries(ctx context.Context, expression string) (map[string]float64, error) {
+	result, err := s.metricsService.QueryExpression(ctx, expression)
+	if err != nil {
+		return nil, err
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("query returned status %s", result.Status)
+	}
+	if result.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("expression must return an instant vector, got %s", result.Data.ResultType)
+	}
+
+	series := make(map[string]float64, len(result.Data.Result))
+	for _, sample := range result.Data.Result {
+		if len(sample.Value) != 2 {
+			continue
+		}
+		valueStr, ok := sample.Value[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil || math.IsNaN(value) {
+			continue
+		}
+		// Map keys are sorted when encoded, so equal label sets give equal keys
+		labels, err := json.Marshal(sample.Metric)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal series labels: %w", err)
+		}
+		series[string(labels)] = value
+	}
+	return series, nil
+}
+
+// updateAlert persists the state, value and timestamps of an alert
+func (s *Service) updateAlert(ctx context.Context, alert *db.Alert) error {
+	return s.queries.UpdateAlertState(ctx, &db.UpdateAlertStateParams{
+		State:      alert.State,
+		Value:      alert.Value,
+		FiredAt:    alert.FiredAt,
+		ResolvedAt: alert.ResolvedAt,
+		ID:         alert.ID,
+	})
+}
+
+// sendAlertNotification sends a firing or resolved notification to the providers of a rule
+func (s *Service) sendAlertNotification(ctx context.Context, rule *db.AlertRule, alert *db.Alert) {
+	var providerIDs []int64
+	if err := json.Unmarshal([]byte(rule.ProviderIds), &providerIDs); err != nil {
+		s.logger.Errorf("Failed to parse notification providers of alert rule %d: %v", rule.ID, err)
+		return
+	}
+
+	data := notifications.AlertData{
+		AlertID:     alert.ID,
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Description: rule.Description.String,
+		Expression:  rule.Expression,
+		Severity:    rule.Severity,
+		Labels:      parseLabels(alert.Labels),
+		Value:       alert.Value,
+		ActiveAt:    alert.ActiveAt,
+		FiredAt:     alert.FiredAt.Time,
+	}
+	if alert.ResolvedAt.Valid {
+		resolvedAt := alert.ResolvedAt.Time
+		data.ResolvedAt = &resolvedAt
+	}
+
+	if err := s.notificationSvc.SendAlertNotification(ctx, providerIDs, data); err != nil {
+		// Just log the error; we don't want to create a notification loop
+		s.logger.Errorf("Failed to send alert notification: %v", err)
+	}
+}
+
+// clearActiveAlerts resolves the firing alerts of a rule and deletes its pending
+// ones without notifying, used when a rule is disabled
+func (s *Service) clearActiveAlerts(ctx context.Context, ruleID int64) error {
+	s.evalMutex.Lock()
+	defer s.evalMutex.Unlock()
+
+	active, err := s.queries.ListActiveAlertsByRule(ctx, ruleID)
+	if err != nil {
+		return fmt.Errorf("failed to list active alerts: %w", err)
+	}
+	now := time.Now().UTC()
+	for _, alert := range active {
+		if alert.State == string(AlertStatePending) {
+			if err := s.queries.DeleteAlert(ctx, alert.ID); err != nil {
+				return fmt.Errorf("failed to delete pending alert %d: %w", alert.ID, err)
+			}
+			continue
+		}
+		alert.State = string(AlertStateResolved)
+		alert.ResolvedAt = sql.NullTime{Time: now, Valid: true}
+		if err := s.updateAlert(ctx, alert); err != nil {
+			return fmt.Errorf("failed to resolve alert %d: %w", alert.ID, err)
+		}
+	}
+	return nil
+}
+
+// CreateRule creates an alert rule
+func (s *Service) CreateRule(ctx context.Context, req RuleRequest) (*Rule, error) {
+	params, err := s.ruleParams(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.queries.CreateAlertRule(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create alert rule: %w", err)
+	}
+	return toRule(rule), nil
+}
+
+// UpdateRule updates an alert rule, disabling it clears its active alerts
+func (s *Service) UpdateRule(ctx context.Context, id int64, req RuleRequest) (*Rule, error) {
+	if _, err := s.getRule(ctx, id); err != nil {
+		return nil, err
+	}
+	params, err := s.ruleParams(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.queries.UpdateAlertRule(ctx, &db.UpdateAlertRuleParams{
+		Name:        params.Name,
+		Description: params.Description,
+		Expression:  params.Expression,
+		ForSeconds:  params.ForSeconds,
+		Severity:    params.Severity,
+		ProviderIds: params.ProviderIds,
+		Enabled:     params.Enabled,
+		ID:          id,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	if !rule.Enabled {
+		if err := s.clearActiveAlerts(ctx, rule.ID); err != nil {
+			return nil, fmt.Errorf("failed to clear alerts of disabled rule: %w", err)
+		}
+	}
+	return toRule(rule), nil
+}
+
+// GetRule returns an alert rule
+func (s *Service) GetRule(ctx context.Context, id int64) (*Rule, error) {
+	rule, err := s.getRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toRule(rule), nil
+}
+
+// ListRules returns every alert rule
+func (s *Service) ListRules(ctx context.Context) ([]*Rule, error) {
+	rules, err := s.queries.ListAlertRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	result := make([]*Rule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, toRule(rule))
+	}
+	return result, nil
+}
+
+// DeleteRule deletes an alert rule and its alerts
+func (s *Service) DeleteRule(ctx context.Context, id int64) error {
+	if _, err := s.getRule(ctx, id); err != nil {
+		return err
+	}
+	if err := s.queries.DeleteAlertRule(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	return nil
+}
+
+// ListActiveAlerts returns the pending and firing alerts of every rule
+func (s *Service) ListActiveAlerts(ctx context.Context) ([]*Alert, error) {
+	rows, err := s.queries.ListActiveAlerts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active alerts: %w", err)
+	}
+	result := make([]*Alert, 0, len(rows))
+	for _, row := range rows {
+		alert := toAlert(&db.Alert{
+			ID:         row.ID,
+			RuleID:     row.RuleID,
+			Labels:     row.Labels,
+			State:      row.State,
+			Value:      row.Value,
+			ActiveAt:   row.ActiveAt,
+			FiredAt:    row.FiredAt,
+			ResolvedAt: row.ResolvedAt,
+		})
+		alert.RuleName = row.RuleName
+		alert.Severity = Severity(row.RuleSeverity)
+		result = append(result, alert)
+	}
+	return result, nil
+}
+
+// ListRuleAlerts returns the most recent alerts of a rule, including resolved ones
+func (s *Service) ListRuleAlerts(ctx context.Context, ruleID int64, limit int64) ([]*Alert, error) {
+	rule, err := s.getRule(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultAlertHistoryLimit
+	}
+
+	alerts, err := s.queries.ListAlertsByRule(ctx, &db.ListAlertsByRuleParams{
+		RuleID: ruleID,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	result := make([]*Alert, 0, len(alerts))
+	for _, a := range alerts {
+		alert := toAlert(a)
+		alert.RuleName = rule.Name
+		alert.Severity = Severity(rule.Severity)
+		result = append(result, alert)
+	}
+	return result, nil
+}
+
+// getRule returns an alert rule, or ErrRuleNotFound
+func (s *Service) getRule(ctx context.Context, id int64) (*db.AlertRule, error) {
+	rule, err := s.queries.GetAlertRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, fmt.Errorf("failed to get alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// ruleParams validates a rule request, including that its providers exist, and
+// converts it to the database representation
+func (s *Service) ruleParams(ctx context.Context, req RuleRequest) (*db.CreateAlertRuleParams, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	for _, providerID := range req.ProviderIDs {
+		if _, err := s.queries.GetNotificationProvider(ctx, providerID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: notification provider %d does not exist", ErrInvalidRule, providerID)
+			}
+			return nil, fmt.Errorf("failed to get notification provider: %w", err)
+		}
+	}
+
+	providerIDs, err := json.Marshal(req.ProviderIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification providers: %w", err)
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &db.CreateAlertRuleParams{
+		Name:        req.Name,
+		Description: sql.NullString{String: req.Description, Valid: req.Description != ""},
+		Expression:  req.Expression,
+		ForSeconds:  req.ForSeconds,
+		Severity:    string(req.Severity),
+		ProviderIds: string(providerIDs),
+		Enabled:     enabled,
+	}, nil
+}
+
+func toRule(rule *db.AlertRule) *Rule {
+	result := &Rule{
+		ID:          rule.ID,
+		Name:        rule.Name,
+		Description: rule.Description.String,
+		Expression:  rule.Expression,
+		ForSeconds:  rule.ForSeconds,
+		Severity:    Severity(rule.Severity),
+		ProviderIDs: []int64{},
+		Enabled:     rule.Enabled,
+		LastError:   rule.LastError.String,
+		CreatedAt:   rule.CreatedAt,
+		UpdatedAt:   rule.UpdatedAt,
+	}
+	// The column is only written by ruleParams, so it's always valid JSON
+	_ = json.Unmarshal([]byte(rule.ProviderIds), &result.ProviderIDs)
+	if rule.LastEvaluatedAt.Valid {
+		lastEvaluatedAt := rule.LastEvaluatedAt.Time
+		result.LastEvaluatedAt = &lastEvaluatedAt
+	}
+	return result
+}
+
+func toAlert(alert *db.Alert) *Alert {
+	result := &Alert{
+		ID:       alert.ID,
+		RuleID:   alert.RuleID,
+		Labels:   parseLabels(alert.Labels),
+		State:    AlertState(alert.State),
+		Value:    alert.Value,
+		ActiveAt: alert.ActiveAt,
+	}
+	if alert.FiredAt.Valid {
+		firedAt := alert.FiredAt.Time
+		result.FiredAt = &firedAt
+	}
+	if alert.ResolvedAt.Valid {
+		resolvedAt := alert.ResolvedAt.Time
+		result.ResolvedAt = &resolvedAt
+	}
+	return result
+}
+
+// parseLabels decodes the labels of an alert, stored as the JSON encoding of the series labels
+func parseLabels(labels string) map[string]string {
+	result := map[string]string{}
+	_ = json.Unmarshal([]byte(labels), &result)
+	return result
+}
@@ -0,0 +1,420 @@
+package alerts
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	"github.com/chainlaunch/chainlaunch/pkg/metrics/common"
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+)
+
+func newTestDatabase(t *testing.T) (*sql.DB, *db.Queries) {
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	driver, err := sqlite3.WithInstance(database, &sqlite3.Config{})
+	if err != nil {
+		t.Fatalf("failed to create sqlite driver: %v", err)
+	}
+	source, err := iofs.New(os.DirFS("../../db/migrations"), ".")
+	if err != nil {
+		t.Fatalf("failed to open migrations: %v", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", driver)
+	if err != nil {
+		t.Fatalf("failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return database, db.New(database)
+}
+
+// fakeMetrics answers expressions with a JSON encoded Prometheus response
+type fakeMetrics struct {
+	common.Service
+
+	mu       sync.Mutex
+	response string
+	err      error
+}
+
+func (f *fakeMetrics) set(response string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.response, f.err = response, err
+}
+
+func (f *fakeMetrics) QueryExpression(ctx context.Context, query string) (*common.QueryResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	result := &common.QueryResult{}
+	if err := json.Unmarshal([]byte(f.response), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// vector returns a Prometheus instant vector response with a series per instance
+func vector(instances ...string) string {
+	var series []string
+	for _, instance := range instances {
+		series = append(series, `{"metric":{"job":"peer","instance":"`+instance+`"},"value":[1700000000,"0.9"]}`)
+	}
+	return `{"status":"success","data":{"resultType":"vector","result":[` + strings.Join(series, ",") + `]}}`
+}
+
+// fakeNotifier records the alerts it was asked to send
+type fakeNotifier struct {
+	notifications.Service
+
+	mu     sync.Mutex
+	alerts []notifications.AlertData
+}
+
+func (f *fakeNotifier) SendAlertNotification(ctx context.Context, providerIDs []int64, data notifications.AlertData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.alerts = append(f.alerts, data)
+	return nil
+}
+
+// sent returns the instance and resolution of the alerts sent since the last call
+func (f *fakeNotifier) sent() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var sent []string
+	for _, alert := range f.alerts {
+		state := "firing"
+		if alert.ResolvedAt != nil {
+			state = "resolved"
+		}
+		sent = append(sent, alert.Labels["instance"]+":"+state)
+	}
+	f.alerts = nil
+	return sent
+}
+
+type testService struct {
+	*Service
+	database *sql.DB
+	metrics  *fakeMetrics
+	notifier *fakeNotifier
+	provider int64
+}
+
+func newTestService(t *testing.T) *testService {
+	database, queries := newTestDatabase(t)
+	provider, err := queries.CreateNotificationProvider(context.Background(), &db.CreateNotificationProviderParams{
+		Type:   "WEBHOOK",
+		Name:   "ops",
+		Config: `{"url":"http://receiver/hook"}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create notification provider: %v", err)
+	}
+	metrics := &fakeMetrics{}
+	notifier := &fakeNotifier{}
+	return &testService{
+		Service:  NewService(logger.NewDefault(), nil, queries, metrics, notifier),
+		database: database,
+		metrics:  metrics,
+		notifier: notifier,
+		provider: provider.ID,
+	}
+}
+
+func (s *testService) createRule(t *testing.T, name string, forSeconds int64) *Rule {
+	rule, err := s.CreateRule(context.Background(), RuleRequest{
+		Name:        name,
+		Expression:  `up{job="peer"} == 0`,
+		ForSeconds:  forSeconds,
+		Severity:    SeverityCritical,
+		ProviderIDs: []int64{s.provider},
+	})
+	if err != nil {
+		t.Fatalf("failed to create rule: %v", err)
+	}
+	return rule
+}
+
+func (s *testService) evaluate(t *testing.T, ruleID int64) {
+	rule, err := s.queries.GetAlertRule(context.Background(), ruleID)
+	if err != nil {
+		t.Fatalf("failed to get rule: %v", err)
+	}
+	s.evaluateRule(context.Background(), rule)
+}
+
+// states returns the instance and state of the alerts of a rule, sorted by instance
+func (s *testService) states(t *testing.T, ruleID int64) string {
+	alerts, err := s.ListRuleAlerts(context.Background(), ruleID, 0)
+	if err != nil {
+		t.Fatalf("failed to list alerts: %v", err)
+	}
+	var states []string
+	for _, alert := range alerts {
+		states = append(states, alert.Labels["instance"]+":"+string(alert.State))
+	}
+	sort.Strings(states)
+	return strings.Join(states, ",")
+}
+
+func TestEvaluateRuleFiresAndResolves(t *testing.T) {
+	s := newTestService(t)
+	rule := s.createRule(t, "peer down", 0)
+
+	steps := []struct {
+		name     string
+		response string
+		sent     string
+		states   string
+	}{
+		{"nothing down", vector(), "", ""},
+		{"peer0 down", vector("peer0"), "peer0:firing", "peer0:firing"},
+		{"still down", vector("peer0"), "", "peer0:firing"},
+		{"peer1 down too", vector("peer0", "peer1"), "peer1:firing", "peer0:firing,peer1:firing"},
+		{"peer0 back", vector("peer1"), "peer0:resolved", "peer0:resolved,peer1:firing"},
+	}
+	for _, step := range steps {
+		s.metrics.set(step.response, nil)
+		s.evaluate(t, rule.ID)
+		if got := strings.Join(s.notifier.sent(), ","); got != step.sent {
+			t.Errorf("%s: expected notifications %q, got %q", step.name, step.sent, got)
+		}
+		if got := s.states(t, rule.ID); got != step.states {
+			t.Errorf("%s: expected alerts %q, got %q", step.name, step.states, got)
+		}
+	}
+}
+
+func TestEvaluateRuleForDuration(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	rule := s.createRule(t, "peer down for 5m", 300)
+
+	s.metrics.set(vector("peer0", "peer1"), nil)
+	s.evaluate(t, rule.ID)
+	if sent := s.notifier.sent(); len(sent) != 0 {
+		t.Errorf("pending alerts should not notify, got %v", sent)
+	}
+	if got := s.states(t, rule.ID); got != "peer0:pending,peer1:pending" {
+		t.Errorf("unexpected alerts %q", got)
+	}
+
+	// peer0 has been returned for longer than the for-duration, peer1 stops being returned
+	if _, err := s.database.ExecContext(ctx, "UPDATE alerts SET active_at = ? WHERE labels LIKE ?",
+		time.Now().UTC().Add(-10*time.Minute), `%peer0%`); err != nil {
+		t.Fatalf("failed to age alert: %v", err)
+	}
+	s.metrics.set(vector("peer0"), nil)
+	s.evaluate(t, rule.ID)
+	if got := strings.Join(s.notifier.sent(), ","); got != "peer0:firing" {
+		t.Errorf("expected peer0 to fire, got %q", got)
+	}
+	// A pending alert whose series disappears is dropped without notification
+	if got := s.states(t, rule.ID); got != "peer0:firing" {
+		t.Errorf("unexpected alerts %q", got)
+	}
+}
+
+func TestEvaluateRuleQueryError(t *testing.T) {
+	s := newTestService(t)
+	rule := s.createRule(t, "peer down", 0)
+
+	s.metrics.set(vector("peer0"), nil)
+	s.evaluate(t, rule.ID)
+	s.notifier.sent()
+
+	// A failed query keeps the alerts as they are and is reported on the rule
+	s.metrics.set("", errors.New("unexpected status code: 400, response: parse error"))
+	s.evaluate(t, rule.ID)
+	if sent := s.notifier.sent(); len(sent) != 0 {
+		t.Errorf("failed query should not notify, got %v", sent)
+	}
+	if got := s.states(t, rule.ID); got != "peer0:firing" {
+		t.Errorf("unexpected alerts %q", got)
+	}
+	updated, err := s.GetRule(context.Background(), rule.ID)
+	if err != nil {
+		t.Fatalf("failed to get rule: %v", err)
+	}
+	if !strings.Contains(updated.LastError, "parse error") || updated.LastEvaluatedAt == nil {
+		t.Errorf("expected the evaluation error on the rule, got %+v", updated)
+	}
+
+	// The error is cleared by the next successful evaluation
+	s.metrics.set(vector("peer0"), nil)
+	s.evaluate(t, rule.ID)
+	if updated, err := s.GetRule(context.Background(), rule.ID); err != nil || updated.LastError != "" {
+		t.Errorf("expected the error to be cleared, got %+v (%v)", updated, err)
+	}
+}
+
+func TestQuerySeries(t *testing.T) {
+	s := newTestService(t)
+
+	s.metrics.set(`{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"job":"peer","instance":"peer0"},"value":[1700000000,"1"]},
+		{"metric":{"instance":"peer1","job":"peer"},"value":[1700000000,"2.5"]},
+		{"metric":{"instance":"nan"},"value":[1700000000,"NaN"]},
+		{"metric":{"instance":"invalid"},"value":[1700000000,"high"]},
+		{"metric":{"instance":"number"},"value":[1700000000,3]},
+		{"metric":{"instance":"short"},"value":[1700000000]}
+	]}}`, nil)
+	series, err := s.querySeries(context.Background(), "up")
+	if err != nil {
+		t.Fatalf("failed to query series: %v", err)
+	}
+	// Labels are keyed in sorted order whatever the order of the response
+	expected := map[string]float64{
+		`{"instance":"peer0","job":"peer"}`: 1,
+		`{"instance":"peer1","job":"peer"}`: 2.5,
+	}
+	if len(series) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, series)
+	}
+	for labels, value := range expected {
+		if series[labels] != value {
+			t.Errorf("%s: expected %v, got %v", labels, value, series[labels])
+		}
+	}
+
+	invalid := map[string]string{
+		"error status": `{"status":"error","data":{"resultType":"vector","result":[]}}`,
+		"range vector": `{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+		"scalar":       `{"status":"success","data":{"resultType":"scalar","result":[]}}`,
+	}
+	for name, response := range invalid {
+		s.metrics.set(response, nil)
+		if _, err := s.querySeries(context.Background(), "up"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDisableRuleClearsAlerts(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	firing := s.createRule(t, "peer down", 0)
+	pending := s.createRule(t, "peer down for 5m", 300)
+
+	s.metrics.set(vector("peer0"), nil)
+	s.evaluate(t, firing.ID)
+	s.evaluate(t, pending.ID)
+	s.notifier.sent()
+
+	active, err := s.ListActiveAlerts(ctx)
+	if err != nil || len(active) != 2 {
+		t.Fatalf("expected 2 active alerts, got %d (%v)", len(active), err)
+	}
+	for _, alert := range active {
+		if !strings.HasPrefix(alert.RuleName, "peer down") || alert.Severity != SeverityCritical {
+			t.Errorf("active alert without its rule: %+v", alert)
+		}
+	}
+
+	disabled := false
+	for _, rule := range []*Rule{firing, pending} {
+		if _, err := s.UpdateRule(ctx, rule.ID, RuleRequest{
+			Name:        rule.Name,
+			Expression:  rule.Expression,
+			ForSeconds:  rule.ForSeconds,
+			Severity:    rule.Severity,
+			ProviderIDs: rule.ProviderIDs,
+			Enabled:     &disabled,
+		}); err != nil {
+			t.Fatalf("failed to disable rule: %v", err)
+		}
+	}
+	if sent := s.notifier.sent(); len(sent) != 0 {
+		t.Errorf("disabling a rule should not notify, got %v", sent)
+	}
+	if got := s.states(t, firing.ID); got != "peer0:resolved" {
+		t.Errorf("expected the firing alert to be resolved, got %q", got)
+	}
+	if got := s.states(t, pending.ID); got != "" {
+		t.Errorf("expected the pending alert to be deleted, got %q", got)
+	}
+	if active, err := s.ListActiveAlerts(ctx); err != nil || len(active) != 0 {
+		t.Errorf("expected no active alerts, got %d (%v)", len(active), err)
+	}
+}
+
+func TestRuleRequestValidate(t *testing.T) {
+	valid := func() RuleRequest {
+		return RuleRequest{Name: "peer down", Expression: "up == 0", Severity: SeverityWarning, ProviderIDs: []int64{1}}
+	}
+	cases := map[string]func(*RuleRequest){
+		"no name":           func(r *RuleRequest) { r.Name = " " },
+		"no expression":     func(r *RuleRequest) { r.Expression = "" },
+		"negative duration": func(r *RuleRequest) { r.ForSeconds = -1 },
+		"unknown severity":  func(r *RuleRequest) { r.Severity = "page" },
+		"no providers":      func(r *RuleRequest) { r.ProviderIDs = nil },
+		"empty severity":    func(r *RuleRequest) { r.Severity = "" },
+	}
+	for name, modify := range cases {
+		req := valid()
+		modify(&req)
+		if err := req.Validate(); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("%s: expected ErrInvalidRule, got %v", name, err)
+		}
+	}
+	req := valid()
+	if err := req.Validate(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestRuleErrors(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	rule := s.createRule(t, "peer down", 0)
+
+	if _, err := s.CreateRule(ctx, RuleRequest{
+		Name: "peer down", Expression: "up == 0", Severity: SeverityInfo, ProviderIDs: []int64{s.provider + 100},
+	}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("expected ErrInvalidRule for an unknown provider, got %v", err)
+	}
+
+	notFound := map[string]func() error{
+		"get":    func() error { _, err := s.GetRule(ctx, rule.ID+100); return err },
+		"update": func() error { _, err := s.UpdateRule(ctx, rule.ID+100, RuleRequest{}); return err },
+		"delete": func() error { return s.DeleteRule(ctx, rule.ID+100) },
+		"alerts": func() error { _, err := s.ListRuleAlerts(ctx, rule.ID+100, 10); return err },
+	}
+	for name, call := range notFound {
+		if err := call(); !errors.Is(err, ErrRuleNotFound) {
+			t.Errorf("%s: expected ErrRuleNotFound, got %v", name, err)
+		}
+	}
+
+	if err := s.DeleteRule(ctx, rule.ID); err != nil {
+		t.Fatalf("failed to delete rule: %v", err)
+	}
+	if rules, err := s.ListRules(ctx); err != nil || len(rules) != 0 {
+		t.Errorf("expected no rules, got %d (%v)", len(rules), err)
+	}
+}
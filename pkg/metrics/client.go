@@ -54,7 +54,9 @@ func (c *Client) Query(ctx context.Context, query string) (*common.QueryResult,
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		// Invalid expressions are rejected with a 400 that explains the error
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("unexpected status code: %d, response: %s", resp.StatusCode, string(body))
 	}
 
 	var result common.QueryResult
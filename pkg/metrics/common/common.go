@@ -52,6 +52,9 @@ type Service interface {
 
 	// GetStatus returns the current status of the Prometheus instance
 	GetStatus(ctx context.Context) (*Status, error)
+
+	// QueryExpression executes a PromQL query as is, across the metrics of all nodes
+	QueryExpression(ctx context.Context, query string) (*QueryResult, error)
 }
 
 // QueryResult represents the result of a Prometheus query
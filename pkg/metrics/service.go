@@ -154,6 +154,11 @@ func (s *service) QueryRange(ctx context.Context, nodeID int64, query string, st
 	return s.manager.QueryRange(ctx, query, start, end, step)
 }
 
+// QueryExpression executes a PromQL query as is, across the metrics of all nodes
+func (s *service) QueryExpression(ctx context.Context, query string) (*common.QueryResult, error) {
+	return s.manager.Query(ctx, query)
+}
+
 // GetStatus returns the current status of the Prometheus instance
 func (s *service) GetStatus(ctx context.Context) (*common.Status, error) {
 	return s.manager.GetStatus(ctx)
@@ -0,0 +1,234 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/certutils"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	nodes "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+)
+
+// CertificateExpiryConfig represents the configuration for the certificate expiry scanner
+type CertificateExpiryConfig struct {
+	// ScanInterval is the interval between certificate scans
+	ScanInterval time.Duration
+	// ThresholdDays are the number of days before expiry at which a notification is sent
+	ThresholdDays []int
+}
+
+// DefaultCertificateExpiryConfig returns a CertificateExpiryConfig with sensible default values
+func DefaultCertificateExpiryConfig() *CertificateExpiryConfig {
+	return &CertificateExpiryConfig{
+		ScanInterval:  1 * time.Hour,
+		ThresholdDays: []int{30, 7, 1},
+	}
+}
+
+// CertificateExpiryScanner periodically checks key and node certificates and
+// notifies when they cross one of the configured expiry thresholds. Nodes opted
+// into auto-renewal get their certificates renewed when that happens.
+type CertificateExpiryScanner struct {
+	logger          *logger.Logger
+	config          *CertificateExpiryConfig
+	queries         *db.Queries
+	notificationSvc notifications.Service
+	nodeService     *nodes.NodeService
+	stopChan        chan struct{}
+	waitGroup       sync.WaitGroup
+}
+
+// NewCertificateExpiryScanner creates a new certificate expiry scanner
+func NewCertificateExpiryScanner(logger *logger.Logger, config *CertificateExpiryConfig, queries *db.Queries, notificationSvc notifications.Service, nodeService *nodes.NodeService) *CertificateExpiryScanner {
+	if config == nil {
+		config = DefaultCertificateExpiryConfig()
+	}
+
+	return &CertificateExpiryScanner{
+		logger:          logger,
+		config:          config,
+		queries:         queries,
+		notificationSvc: notificationSvc,
+		nodeService:     nodeService,
+		stopChan:        make(chan struct{}),
+	}
+}
+
+// Start begins scanning certificates
+func (s *CertificateExpiryScanner) Start(ctx context.Context) error {
+	if s.config.ScanInterval <= 0 {
+		return fmt.Errorf("scan interval must be positive")
+	}
+	for _, days := range s.config.ThresholdDays {
+		if days <= 0 {
+			return fmt.Errorf("invalid certificate expiry threshold: %d days", days)
+		}
+	}
+
+	s.waitGroup.Add(1)
+	go func() {
+		defer s.waitGroup.Done()
+
+		ticker := time.NewTicker(s.config.ScanInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.Scan(ctx); err != nil {
+				s.logger.Error("Certificate expiry scan failed", "error", err)
+			}
+
+			select {
+			case <-s.stopChan:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops scanning certificates
+func (s *CertificateExpiryScanner) Stop() error {
+	close(s.stopChan)
+	s.waitGroup.Wait()
+	return nil
+}
+
+// Scan checks all certificates once
+func (s *CertificateExpiryScanner) Scan(ctx context.Context) error {
+	now := time.Now()
+
+	autoRenewIDs, err := s.queries.ListAutoRenewNodeIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list auto-renew nodes: %w", err)
+	}
+	autoRenew := make(map[int64]bool, len(autoRenewIDs))
+	for _, id := range autoRenewIDs {
+		autoRenew[id] = true
+	}
+
+	nodeCerts, err := s.nodeService.ListNodeCertificates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list node certificates: %w", err)
+	}
+
+	// Keys deployed on a node are reported with the node, not on their own
+	nodeKeyIDs := make(map[int64]bool)
+	// Renewal results per node, so a node is renewed at most once per scan
+	renewals := make(map[int64]error)
+	for _, cert := range nodeCerts {
+		if cert.KeyID != 0 {
+			nodeKeyIDs[cert.KeyID] = true
+		}
+
+		data := notifications.CertificateExpiringData{
+			Subject:         fmt.Sprintf("node:%d:%s", cert.NodeID, strings.ToLower(cert.Type)),
+			CertificateType: cert.Type,
+			CommonName:      cert.CommonName,
+			KeyID:           cert.KeyID,
+			NodeID:          cert.NodeID,
+			NodeName:        cert.NodeName,
+			NodeType:        string(cert.NodeType),
+			ExpiresAt:       cert.NotAfter.UTC(),
+		}
+		if !s.checkExpiry(ctx, now, &data) {
+			continue
+		}
+
+		if autoRenew[cert.NodeID] {
+			renewErr, renewed := renewals[cert.NodeID]
+			if !renewed {
+				s.logger.Info("Renewing node certificates", "nodeID", cert.NodeID, "nodeName", cert.NodeName)
+				_, renewErr = s.nodeService.RenewCertificates(ctx, cert.NodeID)
+				renewals[cert.NodeID] = renewErr
+			}
+			if renewErr != nil {
+				data.AutoRenewalError = renewErr.Error()
+			} else {
+				data.AutoRenewed = true
+			}
+		}
+
+		s.notify(ctx, data)
+	}
+
+	keys, err := s.queries.ListKeysWithCertificateExpiry(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list keys: %w", err)
+	}
+	for _, key := range keys {
+		if nodeKeyIDs[key.ID] {
+			continue
+		}
+
+		data := notifications.CertificateExpiringData{
+			Subject:         fmt.Sprintf("key:%d", key.ID),
+			CertificateType: "KEY",
+			KeyID:           key.ID,
+			KeyName:         key.Name,
+			ExpiresAt:       key.ExpiresAt.Time.UTC(),
+		}
+		if cert, err := certutils.ParseX509Certificate([]byte(key.Certificate.String)); err == nil {
+			data.CommonName = cert.Subject.CommonName
+		}
+		if !s.checkExpiry(ctx, now, &data) {
+			continue
+		}
+
+		s.notify(ctx, data)
+	}
+
+	return nil
+}
+
+// checkExpiry fills in the remaining days and crossed threshold of a certificate.
+// It returns true when a notification is due, i.e. the certificate crossed a
+// threshold that hasn't been notified yet.
+func (s *CertificateExpiryScanner) checkExpiry(ctx context.Context, now time.Time, data *notifications.CertificateExpiringData) bool {
+	remaining := data.ExpiresAt.Sub(now)
+	data.DaysRemaining = int(math.Floor(remaining.Hours() / 24))
+
+	// Expired certificates are notified once more with a zero threshold
+	threshold := -1
+	if remaining <= 0 {
+		threshold = 0
+	} else {
+		for _, days := range s.config.ThresholdDays {
+			if remaining <= time.Duration(days)*24*time.Hour && (threshold == -1 || days < threshold) {
+				threshold = days
+			}
+		}
+	}
+	if threshold == -1 {
+		return false
+	}
+	data.ThresholdDays = threshold
+
+	inserted, err := s.queries.CreateCertificateExpiryAlert(ctx, &db.CreateCertificateExpiryAlertParams{
+		Subject:       data.Subject,
+		ExpiresAt:     data.ExpiresAt,
+		ThresholdDays: int64(threshold),
+	})
+	if err != nil {
+		s.logger.Error("Failed to record certificate expiry alert", "subject", data.Subject, "error", err)
+		return false
+	}
+	return inserted > 0
+}
+
+func (s *CertificateExpiryScanner) notify(ctx context.Context, data notifications.CertificateExpiringData) {
+	if s.notificationSvc == nil {
+		return
+	}
+	if err := s.notificationSvc.SendCertificateExpiringNotification(ctx, data); err != nil {
+		s.logger.Error("Failed to send certificate expiry notification", "subject", data.Subject, "error", err)
+	}
+}
@@ -0,0 +1,261 @@
+package monitoring
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	nodes "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+)
+
+func newTestQueries(t *testing.T) *db.Queries {
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	driver, err := sqlite3.WithInstance(database, &sqlite3.Config{})
+	if err != nil {
+		t.Fatalf("failed to create sqlite driver: %v", err)
+	}
+	source, err := iofs.New(os.DirFS("../db/migrations"), ".")
+	if err != nil {
+		t.Fatalf("failed to open migrations: %v", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", driver)
+	if err != nil {
+		t.Fatalf("failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return db.New(database)
+}
+
+// fakeNotifier records notifications. Methods a test doesn't override panic
+// through the nil embedded interface.
+type fakeNotifier struct {
+	notifications.Service
+
+	mu           sync.Mutex
+	err          error
+	certificates []notifications.CertificateExpiringData
+}
+
+func (f *fakeNotifier) SendCertificateExpiringNotification(ctx context.Context, data notifications.CertificateExpiringData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.certificates = append(f.certificates, data)
+	return f.err
+}
+
+func (f *fakeNotifier) expiring() []notifications.CertificateExpiringData {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result := append([]notifications.CertificateExpiringData(nil), f.certificates...)
+	sort.Slice(result, func(i, j int) bool { return result[i].Subject < result[j].Subject })
+	f.certificates = nil
+	return result
+}
+
+func newTestCertificate(t *testing.T, cn string, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func createTestKey(t *testing.T, queries *db.Queries, name string, notAfter time.Time) int64 {
+	key, err := queries.CreateKey(context.Background(), &db.CreateKeyParams{
+		Name:        name,
+		Algorithm:   "EC",
+		Format:      "PEM",
+		Status:      "active",
+		Certificate: sql.NullString{String: newTestCertificate(t, name, notAfter), Valid: true},
+		ExpiresAt:   sql.NullTime{Time: notAfter, Valid: true},
+		ProviderID:  1,
+	})
+	if err != nil {
+		t.Fatalf("failed to create key: %v", err)
+	}
+	return key.ID
+}
+
+func createTestPeer(t *testing.T, queries *db.Queries, name string, signKeyID int64, signExpiry, tlsExpiry time.Time) int64 {
+	config, err := json.Marshal(&types.FabricPeerDeploymentConfig{
+		BaseDeploymentConfig: types.BaseDeploymentConfig{Type: "fabric-peer", Mode: "service"},
+		SignKeyID:            signKeyID,
+		TLSKeyID:             signKeyID + 1000,
+		SignCert:             newTestCertificate(t, name+"-sign", signExpiry),
+		TLSCert:              newTestCertificate(t, name+"-tls", tlsExpiry),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal deployment config: %v", err)
+	}
+	node, err := queries.CreateNode(context.Background(), &db.CreateNodeParams{
+		Name:     name,
+		Slug:     name,
+		Platform: "FABRIC",
+		Status:   "RUNNING",
+		NodeType: sql.NullString{String: string(types.NodeTypeFabricPeer), Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	if _, err := queries.UpdateDeploymentConfig(context.Background(), &db.UpdateDeploymentConfigParams{
+		ID:               node.ID,
+		DeploymentConfig: sql.NullString{String: string(config), Valid: true},
+	}); err != nil {
+		t.Fatalf("failed to set deployment config: %v", err)
+	}
+	return node.ID
+}
+
+func newTestScanner(t *testing.T, queries *db.Queries, notifier notifications.Service) *CertificateExpiryScanner {
+	log := logger.NewDefault()
+	nodeService := nodes.NewNodeService(queries, log, nil, nil, nil, nil, nil, nil)
+	return NewCertificateExpiryScanner(log, nil, queries, notifier, nodeService)
+}
+
+func TestCertificateExpiryScan(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	now := time.Now()
+
+	peerKeyID := createTestKey(t, queries, "peer0-sign", now.Add(5*24*time.Hour))
+	peerID := createTestPeer(t, queries, "peer0", peerKeyID, now.Add(5*24*time.Hour), now.Add(90*24*time.Hour))
+	createTestKey(t, queries, "ca", now.Add(20*24*time.Hour))
+	createTestKey(t, queries, "expired", now.Add(-time.Hour))
+	createTestKey(t, queries, "long-lived", now.Add(400*24*time.Hour))
+
+	notifier := &fakeNotifier{}
+	scanner := newTestScanner(t, queries, notifier)
+	if err := scanner.Scan(ctx); err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+
+	got := notifier.expiring()
+	expected := []struct {
+		commonName string
+		threshold  int
+		nodeID     int64
+	}{
+		{"ca", 30, 0},
+		{"expired", 0, 0},
+		// The peer's signing key is reported with the node, not on its own
+		{"peer0-sign", 7, peerID},
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d notifications, got %d: %+v", len(expected), len(got), got)
+	}
+	for i, e := range expected {
+		if got[i].CommonName != e.commonName || got[i].ThresholdDays != e.threshold || got[i].NodeID != e.nodeID {
+			t.Errorf("notification %d: expected %s at %d days, got %+v", i, e.commonName, e.threshold, got[i])
+		}
+		if got[i].AutoRenewed || got[i].AutoRenewalError != "" {
+			t.Errorf("%s: node is not opted into auto-renewal", e.commonName)
+		}
+	}
+	if got[2].DaysRemaining != 4 && got[2].DaysRemaining != 5 {
+		t.Errorf("unexpected days remaining %d", got[2].DaysRemaining)
+	}
+
+	// Crossed thresholds are only notified once
+	if err := scanner.Scan(ctx); err != nil {
+		t.Fatalf("failed to scan: %v", err)
+	}
+	if got := notifier.expiring(); len(got) != 0 {
+		t.Errorf("expected no repeated notifications, got %+v", got)
+	}
+}
+
+func TestCertificateExpiryScanNotificationFailure(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	createTestKey(t, queries, "ca", time.Now().Add(24*time.Hour))
+
+	notifier := &fakeNotifier{err: errors.New("smtp unavailable")}
+	if err := newTestScanner(t, queries, notifier).Scan(ctx); err != nil {
+		t.Fatalf("a failed notification should not fail the scan: %v", err)
+	}
+	if got := notifier.expiring(); len(got) != 1 || got[0].ThresholdDays != 1 {
+		t.Errorf("unexpected notifications %+v", got)
+	}
+}
+
+func TestCheckExpiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	scanner := newTestScanner(t, newTestQueries(t), nil)
+
+	cases := []struct {
+		name      string
+		expiresIn time.Duration
+		due       bool
+		threshold int
+	}{
+		{"far away", 60 * 24 * time.Hour, false, 0},
+		{"within 30 days", 29 * 24 * time.Hour, true, 30},
+		{"within 7 days", 6 * 24 * time.Hour, true, 7},
+		{"within a day", 12 * time.Hour, true, 1},
+		{"expired", -time.Hour, true, 0},
+	}
+	for _, c := range cases {
+		data := notifications.CertificateExpiringData{Subject: "key:" + c.name, ExpiresAt: now.Add(c.expiresIn).UTC()}
+		if due := scanner.checkExpiry(ctx, now, &data); due != c.due {
+			t.Errorf("%s: expected due=%v, got %v", c.name, c.due, due)
+			continue
+		}
+		if c.due && data.ThresholdDays != c.threshold {
+			t.Errorf("%s: expected threshold %d, got %d", c.name, c.threshold, data.ThresholdDays)
+		}
+	}
+}
+
+func TestCertificateExpiryScannerStart(t *testing.T) {
+	invalid := map[string]*CertificateExpiryConfig{
+		"zero interval":      {ScanInterval: 0, ThresholdDays: []int{30}},
+		"negative threshold": {ScanInterval: time.Hour, ThresholdDays: []int{30, -1}},
+	}
+	for name, config := range invalid {
+		scanner := NewCertificateExpiryScanner(logger.NewDefault(), config, nil, nil, nil)
+		if err := scanner.Start(context.Background()); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
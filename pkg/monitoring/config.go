@@ -14,6 +14,15 @@ type Config struct {
 	DefaultFailureThreshold int
 	// Workers is the number of concurrent workers checking nodes
 	Workers int
+	// CheckRetention is how long raw check results are kept before only their hourly rollups remain
+	CheckRetention time.Duration
+	// RollupRetention is how long hourly rollups and resolved incidents are kept
+	RollupRetention time.Duration
+	// MaintenanceInterval is how often checks are rolled up and expired history is deleted
+	MaintenanceInterval time.Duration
+	// MaxBlockLag is how many blocks a node may be behind the highest node of its
+	// channel or network before it's reported as degraded
+	MaxBlockLag int64
 }
 
 // DefaultConfig returns a Config with sensible default values
@@ -23,5 +32,9 @@ func DefaultConfig() *Config {
 		DefaultTimeout:          10 * time.Second,
 		DefaultFailureThreshold: 3,
 		Workers:                 5,
+		CheckRetention:          7 * 24 * time.Hour,
+		RollupRetention:         365 * 24 * time.Hour,
+		MaintenanceInterval:     15 * time.Minute,
+		MaxBlockLag:             5,
 	}
 }
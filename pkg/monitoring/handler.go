@@ -0,0 +1,252 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultUptimeWindow is the window used by uptime endpoints when no start is given
+const defaultUptimeWindow = 24 * time.Hour
+
+// Handler handles HTTP requests for node monitoring
+type Handler struct {
+	service Service
+	logger  *logger.Logger
+}
+
+// NewHandler creates a new monitoring handler
+func NewHandler(service Service, logger *logger.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// RegisterRoutes registers the monitoring routes
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Route("/monitoring", func(r chi.Router) {
+		r.Get("/nodes", h.GetAllNodeStatuses)
+		r.Get("/nodes/{id}", h.GetNodeStatus)
+		r.Get("/nodes/{id}/uptime", h.GetNodeUptime)
+		r.Get("/nodes/{id}/uptime/history", h.GetNodeUptimeHistory)
+		r.Get("/networks/{id}/uptime", h.GetNetworkUptime)
+	})
+}
+
+// NodeStatusResponse represents the last check result of a node
+type NodeStatusResponse struct {
+	NodeID           int64      `json:"nodeId"`
+	NodeName         string     `json:"nodeName"`
+	Platform         string     `json:"platform"`
+	Endpoint         string     `json:"endpoint"`
+	Status           NodeStatus `json:"status"`
+	ResponseTimeMs   int64      `json:"responseTimeMs"`
+	Error            string     `json:"error,omitempty"`
+	FailureCount     int        `json:"failureCount"`
+	CheckedAt        time.Time  `json:"checkedAt"`
+	LastStatusChange *time.Time `json:"lastStatusChange,omitempty"`
+}
+
+func toNodeStatusResponse(check *NodeCheck) NodeStatusResponse {
+	resp := NodeStatusResponse{
+		NodeID:         check.Node.ID,
+		NodeName:       check.Node.Name,
+		Platform:       check.Node.Platform,
+		Endpoint:       check.Node.Endpoint,
+		Status:         check.Status,
+		ResponseTimeMs: check.ResponseTime.Milliseconds(),
+		FailureCount:   check.FailureCount,
+		CheckedAt:      check.Timestamp,
+	}
+	if check.Error != nil {
+		resp.Error = check.Error.Error()
+	}
+	if !check.Node.LastStatusChange.IsZero() {
+		lastStatusChange := check.Node.LastStatusChange
+		resp.LastStatusChange = &lastStatusChange
+	}
+	return resp
+}
+
+// GetAllNodeStatuses returns the last check result of every monitored node
+// @Summary Get the status of all monitored nodes
+// @Description Returns the last health check result of every monitored node
+// @Tags Monitoring
+// @Produce json
+// @Success 200 {array} NodeStatusResponse
+// @Router /monitoring/nodes [get]
+func (h *Handler) GetAllNodeStatuses(w http.ResponseWriter, r *http.Request) {
+	checks := h.service.GetAllNodeStatuses()
+	resp := make([]NodeStatusResponse, 0, len(checks))
+	for _, check := range checks {
+		resp = append(resp, toNodeStatusResponse(check))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetNodeStatus returns the last check result of a node
+// @Summary Get the status of a monitored node
+// @Description Returns the last health check result of a node
+// @Tags Monitoring
+// @Produce json
+// @Param id path int true "Node ID"
+// @Success 200 {object} NodeStatusResponse
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Router /monitoring/nodes/{id} [get]
+func (h *Handler) GetNodeStatus(w http.ResponseWriter, r *http.Request) {
+	nodeID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid node ID", http.StatusBadRequest)
+		return
+	}
+
+	check, err := h.service.GetNodeStatus(nodeID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toNodeStatusResponse(check))
+}
+
+// GetNodeUptime returns the uptime report of a node
+// @Summary Get the uptime of a node
+// @Description Returns the uptime percentage, incidents and mean time to recovery of a node over a time window
+// @Tags Monitoring
+// @Produce json
+// @Param id path int true "Node ID"
+// @Param from query string false "Start of the window (RFC3339), defaults to 24 hours before the end"
+// @Param to query string false "End of the window (RFC3339), defaults to now"
+// @Success 200 {object} UptimeReport
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /monitoring/nodes/{id}/uptime [get]
+func (h *Handler) GetNodeUptime(w http.ResponseWriter, r *http.Request) {
+	nodeID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid node ID", http.StatusBadRequest)
+		return
+	}
+	from, to, err := parseWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetNodeUptime(r.Context(), nodeID, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get node uptime", "nodeId", nodeID, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// GetNodeUptimeHistory returns the hourly availability of a node
+// @Summary Get the uptime history of a node
+// @Description Returns the health checks of a node aggregated per hour over a time window
+// @Tags Monitoring
+// @Produce json
+// @Param id path int true "Node ID"
+// @Param from query string false "Start of the window (RFC3339), defaults to 24 hours before the end"
+// @Param to query string false "End of the window (RFC3339), defaults to now"
+// @Success 200 {array} UptimeBucket
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /monitoring/nodes/{id}/uptime/history [get]
+func (h *Handler) GetNodeUptimeHistory(w http.ResponseWriter, r *http.Request) {
+	nodeID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid node ID", http.StatusBadRequest)
+		return
+	}
+	from, to, err := parseWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.service.GetNodeUptimeHistory(r.Context(), nodeID, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get node uptime history", "nodeId", nodeID, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
+
+// GetNetworkUptime returns the uptime report of the nodes of a network
+// @Summary Get the uptime of a network
+// @Description Returns the uptime percentage, incidents and mean time to recovery of every node of a network over a time window
+// @Tags Monitoring
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param from query string false "Start of the window (RFC3339), defaults to 24 hours before the end"
+// @Param to query string false "End of the window (RFC3339), defaults to now"
+// @Success 200 {object} NetworkUptimeReport
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /monitoring/networks/{id}/uptime [get]
+func (h *Handler) GetNetworkUptime(w http.ResponseWriter, r *http.Request) {
+	networkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid network ID", http.StatusBadRequest)
+		return
+	}
+	from, to, err := parseWindow(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.service.GetNetworkUptime(r.Context(), networkID, from, to)
+	if err != nil {
+		h.logger.Error("Failed to get network uptime", "networkId", networkID, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report)
+}
+
+// parseWindow parses the from and to query parameters of uptime endpoints
+func parseWindow(r *http.Request) (time.Time, time.Time, error) {
+	to := time.Now()
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid to time format (use RFC3339)")
+		}
+		to = parsed
+	}
+
+	from := to.Add(-defaultUptimeWindow)
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid from time format (use RFC3339)")
+		}
+		from = parsed
+	}
+
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("to time must be after from time")
+	}
+	return from, to, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
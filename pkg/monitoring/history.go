@@ -0,0 +1,450 @@
+package monitoring
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+)
+
+// recordCheck persists a check result and opens or resolves the downtime incident
+// of the node when its status changed
+func (s *service) recordCheck(ctx context.Context, check *NodeCheck, statusChanged bool) {
+	var errorMessage sql.NullString
+	if check.Error != nil {
+		errorMessage = sql.NullString{String: check.Error.Error(), Valid: true}
+	} else if len(check.Issues) > 0 {
+		messages := make([]string, 0, len(check.Issues))
+		for _, issue := range check.Issues {
+			messages = append(messages, issue.Message)
+		}
+		errorMessage = sql.NullString{String: strings.Join(messages, "; "), Valid: true}
+	}
+
+	_, err := s.queries.CreateNodeCheck(ctx, &db.CreateNodeCheckParams{
+		NodeID:         check.Node.ID,
+		Status:         string(check.Status),
+		ResponseTimeMs: check.ResponseTime.Milliseconds(),
+		ErrorMessage:   errorMessage,
+		FailureCount:   int64(check.FailureCount),
+		CheckedAt:      check.Timestamp.UTC(),
+	})
+	if err != nil {
+		s.logger.Errorf("Failed to persist check result for node %d: %v", check.Node.ID, err)
+		return
+	}
+
+	if !statusChanged {
+		return
+	}
+
+	switch check.Status {
+	case NodeStatusDown:
+		_, err = s.queries.CreateNodeIncident(ctx, &db.CreateNodeIncidentParams{
+			NodeID:       check.Node.ID,
+			StartedAt:    check.Timestamp.UTC(),
+			ErrorMessage: errorMessage,
+		})
+		if err != nil {
+			s.logger.Errorf("Failed to open incident for node %d: %v", check.Node.ID, err)
+		}
+	default:
+		// A degraded node is reachable, so it ends the downtime incident as well
+		incident, err := s.queries.GetOpenNodeIncident(ctx, check.Node.ID)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				s.logger.Errorf("Failed to get open incident for node %d: %v", check.Node.ID, err)
+			}
+			return
+		}
+		_, err = s.queries.ResolveNodeIncident(ctx, &db.ResolveNodeIncidentParams{
+			ID:         incident.ID,
+			ResolvedAt: sql.NullTime{Time: check.Timestamp.UTC(), Valid: true},
+		})
+		if err != nil {
+			s.logger.Errorf("Failed to resolve incident %d for node %d: %v", incident.ID, check.Node.ID, err)
+		}
+	}
+}
+
+// restoreState loads the last check result of every node and the incidents still
+// open, so statuses survive a restart
+func (s *service) restoreState(ctx context.Context) error {
+	latest, err := s.queries.ListLatestNodeChecks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list latest node checks: %w", err)
+	}
+	openIncidents, err := s.queries.ListOpenNodeIncidents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list open incidents: %w", err)
+	}
+	downSince := make(map[int64]time.Time, len(openIncidents))
+	for _, incident := range openIncidents {
+		downSince[incident.NodeID] = incident.StartedAt
+	}
+
+	s.resultsMutex.Lock()
+	defer s.resultsMutex.Unlock()
+	for _, row := range latest {
+		node := &Node{
+			ID:               row.NodeID,
+			Name:             row.NodeName,
+			Endpoint:         row.NodeEndpoint.String,
+			Platform:         row.NodePlatform,
+			Status:           NodeStatus(row.Status),
+			LastChecked:      row.CheckedAt,
+			LastStatusChange: downSince[row.NodeID],
+			FailureCount:     int(row.FailureCount),
+		}
+		var checkErr error
+		if row.ErrorMessage.Valid {
+			checkErr = errors.New(row.ErrorMessage.String)
+		}
+		s.lastCheckResults[row.NodeID] = &NodeCheck{
+			Node:         node,
+			Status:       node.Status,
+			ResponseTime: time.Duration(row.ResponseTimeMs) * time.Millisecond,
+			Error:        checkErr,
+			Timestamp:    row.CheckedAt,
+			FailureCount: node.FailureCount,
+		}
+	}
+
+	return nil
+}
+
+// maintenance periodically rolls up check results into hourly buckets and deletes
+// history past its retention
+func (s *service) maintenance(ctx context.Context) {
+	defer s.workerWaitGroup.Done()
+
+	// The first pass rolls up everything still retained, in case checks were
+	// recorded after the last pass before a restart
+	rollupFrom := time.Now().Add(-s.config.CheckRetention)
+	runPass := func() {
+		now := time.Now()
+		s.nodesMutex.RLock()
+		nodeIDs := make([]int64, 0, len(s.nodes))
+		for id := range s.nodes {
+			nodeIDs = append(nodeIDs, id)
+		}
+		s.nodesMutex.RUnlock()
+
+		for _, nodeID := range nodeIDs {
+			if err := s.rollupChecks(ctx, nodeID, rollupFrom, now); err != nil {
+				s.logger.Errorf("Failed to roll up checks of node %d: %v", nodeID, err)
+			}
+		}
+		rollupFrom = now
+		s.deleteExpiredHistory(ctx, now)
+	}
+
+	ticker := time.NewTicker(s.config.MaintenanceInterval)
+	defer ticker.Stop()
+
+	runPass()
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runPass()
+		}
+	}
+}
+
+// rollupChecks aggregates the check results of a node into hourly buckets for
+// every hour between from and to
+func (s *service) rollupChecks(ctx context.Context, nodeID int64, from, to time.Time) error {
+	from = from.UTC().Truncate(time.Hour)
+	checks, err := s.queries.ListNodeChecksInRange(ctx, &db.ListNodeChecksInRangeParams{
+		NodeID:      nodeID,
+		CheckedAt:   from,
+		CheckedAt_2: to.UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list checks: %w", err)
+	}
+
+	buckets := make(map[time.Time]*db.UpsertNodeCheckRollupParams)
+	var order []time.Time
+	for _, check := range checks {
+		start := check.CheckedAt.UTC().Truncate(time.Hour)
+		bucket, ok := buckets[start]
+		if !ok {
+			bucket = &db.UpsertNodeCheckRollupParams{NodeID: nodeID, BucketStart: start}
+			buckets[start] = bucket
+			order = append(order, start)
+		}
+		bucket.TotalChecks++
+		// Degraded nodes are still serving, they only count against uptime when down
+		if check.Status != string(NodeStatusDown) {
+			bucket.UpChecks++
+		}
+		// Accumulate the total, it's turned into the average below
+		bucket.AvgResponseTimeMs += check.ResponseTimeMs
+		if check.ResponseTimeMs > bucket.MaxResponseTimeMs {
+			bucket.MaxResponseTimeMs = check.ResponseTimeMs
+		}
+	}
+
+	for _, start := range order {
+		bucket := buckets[start]
+		bucket.AvgResponseTimeMs /= bucket.TotalChecks
+		if err := s.queries.UpsertNodeCheckRollup(ctx, bucket); err != nil {
+			return fmt.Errorf("failed to store rollup for %s: %w", start, err)
+		}
+	}
+	return nil
+}
+
+// deleteExpiredHistory deletes raw checks, rollups and resolved incidents past their retention
+func (s *service) deleteExpiredHistory(ctx context.Context, now time.Time) {
+	if _, err := s.queries.DeleteNodeChecksBefore(ctx, now.Add(-s.config.CheckRetention).UTC()); err != nil {
+		s.logger.Errorf("Failed to delete expired node checks: %v", err)
+	}
+	rollupCutoff := now.Add(-s.config.RollupRetention).UTC()
+	if _, err := s.queries.DeleteNodeCheckRollupsBefore(ctx, rollupCutoff); err != nil {
+		s.logger.Errorf("Failed to delete expired node check rollups: %v", err)
+	}
+	if _, err := s.queries.DeleteNodeIncidentsBefore(ctx, sql.NullTime{Time: rollupCutoff, Valid: true}); err != nil {
+		s.logger.Errorf("Failed to delete expired node incidents: %v", err)
+	}
+}
+
+// GetNodeUptime returns the uptime, incidents and MTTR of a node between from and to
+func (s *service) GetNodeUptime(ctx context.Context, nodeID int64, from, to time.Time) (*UptimeReport, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("end of the window must be after its start")
+	}
+	node, err := s.queries.GetNode(ctx, nodeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("node with ID %d not found", nodeID)
+		}
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	now := time.Now()
+	// Refresh the rollup of the current hour, the maintenance loop may not have run yet
+	if err := s.rollupChecks(ctx, nodeID, now, now); err != nil {
+		return nil, err
+	}
+
+	from, to = from.UTC(), to.UTC()
+	report := &UptimeReport{
+		NodeID:    nodeID,
+		NodeName:  node.Name,
+		From:      from,
+		To:        to,
+		Incidents: []Incident{},
+	}
+
+	rollups, err := s.queries.ListNodeCheckRollups(ctx, &db.ListNodeCheckRollupsParams{
+		NodeID:        nodeID,
+		BucketStart:   from.Truncate(time.Hour),
+		BucketStart_2: to,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list check rollups: %w", err)
+	}
+	if len(rollups) == 0 {
+		return report, nil
+	}
+
+	var totalResponseTime int64
+	for _, rollup := range rollups {
+		report.TotalChecks += rollup.TotalChecks
+		report.UpChecks += rollup.UpChecks
+		totalResponseTime += rollup.AvgResponseTimeMs * rollup.TotalChecks
+	}
+	if report.TotalChecks > 0 {
+		report.AvgResponseTimeMs = totalResponseTime / report.TotalChecks
+	}
+
+	// Uptime is only computed over the hours for which check results exist, so
+	// periods during which chainlaunch wasn't running don't count as up or down
+	monitored := monitoredIntervals(rollups, from, minTime(to, now.UTC()))
+	if len(monitored) == 0 {
+		return report, nil
+	}
+	for _, interval := range monitored {
+		report.MonitoredSeconds += interval.end.Sub(interval.start).Seconds()
+	}
+
+	incidents, err := s.queries.ListNodeIncidentsInRange(ctx, &db.ListNodeIncidentsInRangeParams{
+		NodeID:     nodeID,
+		StartedAt:  to,
+		ResolvedAt: sql.NullTime{Time: from, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list incidents: %w", err)
+	}
+
+	var downtime, repairTime time.Duration
+	var resolved int
+	for _, incident := range incidents {
+		end := now.UTC()
+		item := Incident{
+			ID:        incident.ID,
+			NodeID:    incident.NodeID,
+			StartedAt: incident.StartedAt.UTC(),
+			Error:     incident.ErrorMessage.String,
+		}
+		if incident.ResolvedAt.Valid {
+			end = incident.ResolvedAt.Time.UTC()
+			item.ResolvedAt = &end
+			repairTime += end.Sub(item.StartedAt)
+			resolved++
+		}
+		item.DurationSeconds = end.Sub(item.StartedAt).Seconds()
+		report.Incidents = append(report.Incidents, item)
+
+		for _, interval := range monitored {
+			overlapStart := maxTime(item.StartedAt, interval.start)
+			overlapEnd := minTime(end, interval.end)
+			if overlapEnd.After(overlapStart) {
+				downtime += overlapEnd.Sub(overlapStart)
+			}
+		}
+	}
+
+	report.DowntimeSeconds = downtime.Seconds()
+	report.UptimePercent = (report.MonitoredSeconds - report.DowntimeSeconds) / report.MonitoredSeconds * 100
+	if resolved > 0 {
+		report.MTTRSeconds = (repairTime / time.Duration(resolved)).Seconds()
+	}
+
+	return report, nil
+}
+
+// GetNetworkUptime returns the uptime of every node of a network between from and to
+func (s *service) GetNetworkUptime(ctx context.Context, networkID int64, from, to time.Time) (*NetworkUptimeReport, error) {
+	network, err := s.queries.GetNetwork(ctx, networkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("network with ID %d not found", networkID)
+		}
+		return nil, fmt.Errorf("failed to get network: %w", err)
+	}
+	networkNodes, err := s.queries.GetNetworkNodes(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network nodes: %w", err)
+	}
+
+	report := &NetworkUptimeReport{
+		NetworkID:   networkID,
+		NetworkName: network.Name,
+		From:        from.UTC(),
+		To:          to.UTC(),
+		Nodes:       []*UptimeReport{},
+	}
+
+	var monitored, downtime, repairTime float64
+	var resolved int
+	for _, networkNode := range networkNodes {
+		nodeReport, err := s.GetNodeUptime(ctx, networkNode.NodeID, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get uptime of node %d: %w", networkNode.NodeID, err)
+		}
+		report.Nodes = append(report.Nodes, nodeReport)
+
+		monitored += nodeReport.MonitoredSeconds
+		downtime += nodeReport.DowntimeSeconds
+		report.IncidentCount += len(nodeReport.Incidents)
+		for _, incident := range nodeReport.Incidents {
+			if incident.ResolvedAt != nil {
+				repairTime += incident.DurationSeconds
+				resolved++
+			}
+		}
+	}
+
+	if monitored > 0 {
+		report.UptimePercent = (monitored - downtime) / monitored * 100
+	}
+	if resolved > 0 {
+		report.MTTRSeconds = repairTime / float64(resolved)
+	}
+
+	return report, nil
+}
+
+// GetNodeUptimeHistory returns the hourly check rollups of a node between from and to
+func (s *service) GetNodeUptimeHistory(ctx context.Context, nodeID int64, from, to time.Time) ([]UptimeBucket, error) {
+	if !to.After(from) {
+		return nil, fmt.Errorf("end of the window must be after its start")
+	}
+	now := time.Now()
+	if err := s.rollupChecks(ctx, nodeID, now, now); err != nil {
+		return nil, err
+	}
+
+	rollups, err := s.queries.ListNodeCheckRollups(ctx, &db.ListNodeCheckRollupsParams{
+		NodeID:        nodeID,
+		BucketStart:   from.UTC().Truncate(time.Hour),
+		BucketStart_2: to.UTC(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list check rollups: %w", err)
+	}
+
+	buckets := make([]UptimeBucket, 0, len(rollups))
+	for _, rollup := range rollups {
+		bucket := UptimeBucket{
+			Start:             rollup.BucketStart.UTC(),
+			TotalChecks:       rollup.TotalChecks,
+			UpChecks:          rollup.UpChecks,
+			AvgResponseTimeMs: rollup.AvgResponseTimeMs,
+			MaxResponseTimeMs: rollup.MaxResponseTimeMs,
+		}
+		if rollup.TotalChecks > 0 {
+			bucket.UptimePercent = float64(rollup.UpChecks) / float64(rollup.TotalChecks) * 100
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+type timeInterval struct {
+	start, end time.Time
+}
+
+// monitoredIntervals merges the hours covered by rollups into contiguous intervals,
+// clipped to the window between from and to
+func monitoredIntervals(rollups []*db.NodeCheckRollup, from, to time.Time) []timeInterval {
+	var intervals []timeInterval
+	for _, rollup := range rollups {
+		start := maxTime(rollup.BucketStart.UTC(), from)
+		end := minTime(rollup.BucketStart.UTC().Add(time.Hour), to)
+		if !end.After(start) {
+			continue
+		}
+		if last := len(intervals) - 1; last >= 0 && !start.After(intervals[last].end) {
+			intervals[last].end = maxTime(intervals[last].end, end)
+			continue
+		}
+		intervals = append(intervals, timeInterval{start: start, end: end})
+	}
+	return intervals
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,295 @@
+package monitoring
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+)
+
+func newTestService(queries *db.Queries) *service {
+	return NewService(logger.NewDefault(), nil, nil, nil, queries).(*service)
+}
+
+func createTestNode(t *testing.T, queries *db.Queries, name string) *Node {
+	node, err := queries.CreateNode(context.Background(), &db.CreateNodeParams{
+		Name:     name,
+		Slug:     name,
+		Platform: "FABRIC",
+		Status:   "RUNNING",
+		Endpoint: sql.NullString{String: name + ":7051", Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	return &Node{ID: node.ID, Name: node.Name, Platform: node.Platform, Endpoint: node.Endpoint.String}
+}
+
+// recordTestChecks records a check every 10 minutes of the hour starting at start,
+// with the status of each check returned by status
+func recordTestChecks(s *service, node *Node, start time.Time, status func(time.Time) NodeStatus) {
+	previous := NodeStatusUp
+	for i := 0; i < 6; i++ {
+		at := start.Add(time.Duration(i) * 10 * time.Minute)
+		check := &NodeCheck{Node: node, Status: status(at), ResponseTime: 100 * time.Millisecond, Timestamp: at}
+		if check.Status == NodeStatusDown {
+			check.Error = errors.New("connection refused")
+			check.FailureCount = 1
+		}
+		s.recordCheck(context.Background(), check, check.Status != previous)
+		previous = check.Status
+	}
+}
+
+// newTestHistory records 4 hours of checks from base, without checks in the third
+// hour, with the node down from base+10m to base+40m and from base+3h30m on
+func newTestHistory(t *testing.T, s *service, node *Node, base time.Time) {
+	down := func(from, to time.Duration) func(time.Time) NodeStatus {
+		return func(at time.Time) NodeStatus {
+			if offset := at.Sub(base); offset >= from && offset < to {
+				return NodeStatusDown
+			}
+			return NodeStatusUp
+		}
+	}
+	recordTestChecks(s, node, base, down(10*time.Minute, 40*time.Minute))
+	recordTestChecks(s, node, base.Add(time.Hour), down(0, 0))
+	recordTestChecks(s, node, base.Add(3*time.Hour), down(3*time.Hour+30*time.Minute, 4*time.Hour))
+	if err := s.rollupChecks(context.Background(), node.ID, base, base.Add(4*time.Hour)); err != nil {
+		t.Fatalf("failed to roll up checks: %v", err)
+	}
+}
+
+func TestGetNodeUptime(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	s := newTestService(queries)
+	node := createTestNode(t, queries, "peer0")
+	base := time.Now().UTC().Truncate(time.Hour).Add(-48 * time.Hour)
+	newTestHistory(t, s, node, base)
+
+	report, err := s.GetNodeUptime(ctx, node.ID, base, base.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to get uptime: %v", err)
+	}
+	if report.NodeName != "peer0" || report.TotalChecks != 18 || report.UpChecks != 12 || report.AvgResponseTimeMs != 100 {
+		t.Errorf("unexpected report %+v", report)
+	}
+	// The hour without checks isn't monitored
+	if report.MonitoredSeconds != 3*3600 {
+		t.Errorf("expected 3 monitored hours, got %v seconds", report.MonitoredSeconds)
+	}
+	// 30 minutes of the resolved incident plus the end of the last hour of the open one
+	if report.DowntimeSeconds != 3600 {
+		t.Errorf("expected 1 hour of downtime, got %v seconds", report.DowntimeSeconds)
+	}
+	if math.Abs(report.UptimePercent-200.0/3) > 0.001 {
+		t.Errorf("unexpected uptime %v", report.UptimePercent)
+	}
+	if report.MTTRSeconds != 1800 {
+		t.Errorf("MTTR should only count resolved incidents, got %v", report.MTTRSeconds)
+	}
+	if len(report.Incidents) != 2 || report.Incidents[0].ResolvedAt == nil || report.Incidents[1].ResolvedAt != nil ||
+		report.Incidents[0].Error != "connection refused" {
+		t.Errorf("unexpected incidents %+v", report.Incidents)
+	}
+
+	// A window without checks has no uptime
+	empty, err := s.GetNodeUptime(ctx, node.ID, base.Add(-24*time.Hour), base.Add(-23*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to get uptime: %v", err)
+	}
+	if empty.TotalChecks != 0 || empty.MonitoredSeconds != 0 || empty.UptimePercent != 0 || len(empty.Incidents) != 0 {
+		t.Errorf("unexpected report %+v", empty)
+	}
+
+	errorCases := map[string]struct {
+		nodeID   int64
+		from, to time.Time
+	}{
+		"empty window":  {node.ID, base, base},
+		"reverse order": {node.ID, base.Add(time.Hour), base},
+		"unknown node":  {node.ID + 100, base, base.Add(time.Hour)},
+	}
+	for name, c := range errorCases {
+		if _, err := s.GetNodeUptime(ctx, c.nodeID, c.from, c.to); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestGetNodeUptimeHistory(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	s := newTestService(queries)
+	node := createTestNode(t, queries, "peer0")
+	base := time.Now().UTC().Truncate(time.Hour).Add(-48 * time.Hour)
+	newTestHistory(t, s, node, base)
+
+	buckets, err := s.GetNodeUptimeHistory(ctx, node.ID, base, base.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	expected := []struct {
+		start   time.Time
+		up      int64
+		percent float64
+	}{
+		{base, 3, 50},
+		{base.Add(time.Hour), 6, 100},
+		{base.Add(3 * time.Hour), 3, 50},
+	}
+	if len(buckets) != len(expected) {
+		t.Fatalf("expected %d buckets, got %+v", len(expected), buckets)
+	}
+	for i, e := range expected {
+		b := buckets[i]
+		if !b.Start.Equal(e.start) || b.TotalChecks != 6 || b.UpChecks != e.up || b.UptimePercent != e.percent || b.MaxResponseTimeMs != 100 {
+			t.Errorf("bucket %d: unexpected %+v", i, b)
+		}
+	}
+
+	if _, err := s.GetNodeUptimeHistory(ctx, node.ID, base, base.Add(-time.Hour)); err == nil {
+		t.Error("expected an error for a reversed window")
+	}
+}
+
+func TestGetNetworkUptime(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	s := newTestService(queries)
+	base := time.Now().UTC().Truncate(time.Hour).Add(-48 * time.Hour)
+
+	monitored := createTestNode(t, queries, "peer0")
+	unmonitored := createTestNode(t, queries, "peer1")
+	newTestHistory(t, s, monitored, base)
+
+	network, err := queries.CreateNetwork(ctx, &db.CreateNetworkParams{Name: "net", Platform: "FABRIC", Status: "running"})
+	if err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	for _, node := range []*Node{monitored, unmonitored} {
+		if _, err := queries.CreateNetworkNode(ctx, &db.CreateNetworkNodeParams{NetworkID: network.ID, NodeID: node.ID, Status: "joined", Role: "peer"}); err != nil {
+			t.Fatalf("failed to add node to network: %v", err)
+		}
+	}
+
+	report, err := s.GetNetworkUptime(ctx, network.ID, base, base.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("failed to get network uptime: %v", err)
+	}
+	// Nodes without checks don't weigh on the uptime
+	if len(report.Nodes) != 2 || report.IncidentCount != 2 || report.MTTRSeconds != 1800 ||
+		math.Abs(report.UptimePercent-200.0/3) > 0.001 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	if _, err := s.GetNetworkUptime(ctx, network.ID+100, base, base.Add(time.Hour)); err == nil {
+		t.Error("expected an error for an unknown network")
+	}
+}
+
+func TestDeleteExpiredHistory(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	s := newTestService(queries)
+	node := createTestNode(t, queries, "peer0")
+	base := time.Now().UTC().Truncate(time.Hour).Add(-48 * time.Hour)
+	newTestHistory(t, s, node, base)
+	end := base.Add(4 * time.Hour)
+
+	// Raw checks expire first, their rollups remain
+	s.deleteExpiredHistory(ctx, end.Add(s.config.CheckRetention))
+	checks, err := queries.ListNodeChecksInRange(ctx, &db.ListNodeChecksInRangeParams{NodeID: node.ID, CheckedAt: base, CheckedAt_2: end})
+	if err != nil || len(checks) != 0 {
+		t.Errorf("expected expired checks to be deleted, got %d (%v)", len(checks), err)
+	}
+	rollups, err := queries.ListNodeCheckRollups(ctx, &db.ListNodeCheckRollupsParams{NodeID: node.ID, BucketStart: base, BucketStart_2: end})
+	if err != nil || len(rollups) != 3 {
+		t.Errorf("expected rollups to be kept, got %d (%v)", len(rollups), err)
+	}
+
+	s.deleteExpiredHistory(ctx, end.Add(s.config.RollupRetention))
+	rollups, err = queries.ListNodeCheckRollups(ctx, &db.ListNodeCheckRollupsParams{NodeID: node.ID, BucketStart: base, BucketStart_2: end})
+	if err != nil || len(rollups) != 0 {
+		t.Errorf("expected expired rollups to be deleted, got %d (%v)", len(rollups), err)
+	}
+	// Open incidents are kept whatever their age
+	incidents, err := queries.ListNodeIncidentsInRange(ctx, &db.ListNodeIncidentsInRangeParams{
+		NodeID:     node.ID,
+		StartedAt:  end,
+		ResolvedAt: sql.NullTime{Time: base, Valid: true},
+	})
+	if err != nil || len(incidents) != 1 || incidents[0].ResolvedAt.Valid {
+		t.Errorf("expected only the open incident to remain, got %+v (%v)", incidents, err)
+	}
+}
+
+func TestRestoreState(t *testing.T) {
+	queries := newTestQueries(t)
+	node := createTestNode(t, queries, "peer0")
+	base := time.Now().UTC().Truncate(time.Hour).Add(-48 * time.Hour)
+	newTestHistory(t, newTestService(queries), node, base)
+
+	s := newTestService(queries)
+	if err := s.restoreState(context.Background()); err != nil {
+		t.Fatalf("failed to restore state: %v", err)
+	}
+	check, ok := s.lastCheckResults[node.ID]
+	if !ok {
+		t.Fatal("last check of the node not restored")
+	}
+	if check.Status != NodeStatusDown || check.Error == nil || check.FailureCount != 1 ||
+		!check.Timestamp.Equal(base.Add(3*time.Hour+50*time.Minute)) || check.ResponseTime != 100*time.Millisecond {
+		t.Errorf("unexpected check %+v", check)
+	}
+	if check.Node.Name != "peer0" || check.Node.Endpoint != "peer0:7051" ||
+		!check.Node.LastStatusChange.Equal(base.Add(3*time.Hour+30*time.Minute)) {
+		t.Errorf("unexpected node %+v", check.Node)
+	}
+}
+
+func TestMonitoredIntervals(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rollups := func(hours ...int) []*db.NodeCheckRollup {
+		var result []*db.NodeCheckRollup
+		for _, hour := range hours {
+			result = append(result, &db.NodeCheckRollup{BucketStart: base.Add(time.Duration(hour) * time.Hour)})
+		}
+		return result
+	}
+	at := func(minutes int) time.Time {
+		return base.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	cases := []struct {
+		name     string
+		rollups  []*db.NodeCheckRollup
+		from, to time.Time
+		expected []timeInterval
+	}{
+		{"no rollups", nil, at(0), at(240), nil},
+		{"contiguous hours merge", rollups(0, 1, 2), at(0), at(240), []timeInterval{{at(0), at(180)}}},
+		{"gap splits", rollups(0, 2), at(0), at(240), []timeInterval{{at(0), at(60)}, {at(120), at(180)}}},
+		{"clipped to window", rollups(0, 1), at(30), at(90), []timeInterval{{at(30), at(90)}}},
+		{"outside window", rollups(0, 5), at(60), at(240), nil},
+	}
+	for _, c := range cases {
+		got := monitoredIntervals(c.rollups, c.from, c.to)
+		if len(got) != len(c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+			continue
+		}
+		for i := range got {
+			if !got[i].start.Equal(c.expected[i].start) || !got[i].end.Equal(c.expected[i].end) {
+				t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+				break
+			}
+		}
+	}
+}
@@ -12,8 +12,38 @@ const (
 	NodeStatusUp NodeStatus = "up"
 	// NodeStatusDown indicates the node is not responding
 	NodeStatusDown NodeStatus = "down"
+	// NodeStatusDegraded indicates the node is responding but failed a protocol health check
+	NodeStatusDegraded NodeStatus = "degraded"
 )
 
+// DegradedReason identifies the protocol health check a degraded node failed
+type DegradedReason string
+
+const (
+	// DegradedReasonUnhealthy indicates the operations /healthz endpoint reported failed checks
+	DegradedReasonUnhealthy DegradedReason = "unhealthy"
+	// DegradedReasonLedgerLagging indicates a peer's channel height is behind the other peers of the channel
+	DegradedReasonLedgerLagging DegradedReason = "ledger_lagging"
+	// DegradedReasonOrdererNotActive indicates an orderer's channel participation status is not active
+	DegradedReasonOrdererNotActive DegradedReason = "orderer_not_active"
+	// DegradedReasonNotConsenter indicates an orderer is only following or tracking the config of a channel
+	DegradedReasonNotConsenter DegradedReason = "not_consenter"
+	// DegradedReasonNoRaftLeader indicates none of the consenters of a Raft channel is the leader
+	DegradedReasonNoRaftLeader DegradedReason = "no_raft_leader"
+	// DegradedReasonBlockLagging indicates a Besu node's block number is behind the other nodes of the network
+	DegradedReasonBlockLagging DegradedReason = "block_lagging"
+	// DegradedReasonNoPeers indicates a Besu node has no peers while other nodes of its network are running
+	DegradedReasonNoPeers DegradedReason = "no_peers"
+	// DegradedReasonCouchDBUnreachable indicates the CouchDB state database of a peer is down or rejects its credentials
+	DegradedReasonCouchDBUnreachable DegradedReason = "couchdb_unreachable"
+)
+
+// HealthIssue describes a protocol health check a node failed
+type HealthIssue struct {
+	Reason  DegradedReason `json:"reason"`
+	Message string         `json:"message"`
+}
+
 // Node represents a node to be monitored
 type Node struct {
 	// ID is a unique identifier for the node
@@ -38,6 +68,8 @@ type Node struct {
 	FailureCount int
 	// FailureThreshold is how many consecutive failures before alerting
 	FailureThreshold int
+	// Issues are the protocol health checks the node failed on its last check
+	Issues []HealthIssue
 }
 
 // NodeCheck represents the result of a node check
@@ -52,4 +84,60 @@ type NodeCheck struct {
 	Error error
 	// Timestamp is when the check was performed
 	Timestamp time.Time
+	// FailureCount is the number of consecutive failures when the check was performed
+	FailureCount int
+	// Issues are the protocol health checks that failed, they make the status degraded
+	Issues []HealthIssue
+}
+
+// Incident represents a period during which a node was down
+type Incident struct {
+	ID         int64      `json:"id"`
+	NodeID     int64      `json:"nodeId"`
+	StartedAt  time.Time  `json:"startedAt"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+	// DurationSeconds is the downtime so far for incidents that are still open
+	DurationSeconds float64 `json:"durationSeconds"`
+	Error           string  `json:"error,omitempty"`
+}
+
+// UptimeReport represents the availability of a node over a time window
+type UptimeReport struct {
+	NodeID   int64     `json:"nodeId"`
+	NodeName string    `json:"nodeName"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	// MonitoredSeconds is the part of the window for which check results exist,
+	// uptime is computed over this period only
+	MonitoredSeconds  float64    `json:"monitoredSeconds"`
+	DowntimeSeconds   float64    `json:"downtimeSeconds"`
+	UptimePercent     float64    `json:"uptimePercent"`
+	TotalChecks       int64      `json:"totalChecks"`
+	UpChecks          int64      `json:"upChecks"`
+	AvgResponseTimeMs int64      `json:"avgResponseTimeMs"`
+	MTTRSeconds       float64    `json:"mttrSeconds"`
+	Incidents         []Incident `json:"incidents"`
+}
+
+// NetworkUptimeReport represents the availability of the nodes of a network over a time window
+type NetworkUptimeReport struct {
+	NetworkID   int64     `json:"networkId"`
+	NetworkName string    `json:"networkName"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	// UptimePercent is the uptime of all nodes weighted by their monitored time
+	UptimePercent float64         `json:"uptimePercent"`
+	MTTRSeconds   float64         `json:"mttrSeconds"`
+	IncidentCount int             `json:"incidentCount"`
+	Nodes         []*UptimeReport `json:"nodes"`
+}
+
+// UptimeBucket represents the checks of a node aggregated over one hour
+type UptimeBucket struct {
+	Start             time.Time `json:"start"`
+	TotalChecks       int64     `json:"totalChecks"`
+	UpChecks          int64     `json:"upChecks"`
+	UptimePercent     float64   `json:"uptimePercent"`
+	AvgResponseTimeMs int64     `json:"avgResponseTimeMs"`
+	MaxResponseTimeMs int64     `json:"maxResponseTimeMs"`
 }
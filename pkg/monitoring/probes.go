@@ -0,0 +1,389 @@
+package monitoring
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/orderer/osnadmin"
+	nodes "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
+)
+
+// etcdraftMetricPattern matches the per channel Raft gauges exposed by an orderer's
+// operations /metrics endpoint
+var etcdraftMetricPattern = regexp.MustCompile(`^(consensus_etcdraft_is_leader|consensus_etcdraft_cluster_size)\{[^}]*channel="([^"]+)"[^}]*\}\s+(\S+)`)
+
+// observation is the last value a node reported for a channel or network, it's
+// compared against the values reported by the other nodes
+type observation struct {
+	value      int64
+	observedAt time.Time
+}
+
+// observe records the value a node reported under a key and returns the fresh
+// observations of every node for that key
+func (s *service) observe(key string, node *Node, value int64) map[int64]observation {
+	now := time.Now()
+	// A node that stopped reporting is left out after two check intervals
+	freshness := 2 * node.CheckInterval
+
+	s.observationsMutex.Lock()
+	defer s.observationsMutex.Unlock()
+
+	byNode, ok := s.observations[key]
+	if !ok {
+		byNode = make(map[int64]observation)
+		s.observations[key] = byNode
+	}
+	byNode[node.ID] = observation{value: value, observedAt: now}
+
+	fresh := make(map[int64]observation, len(byNode))
+	for nodeID, obs := range byNode {
+		if now.Sub(obs.observedAt) <= freshness {
+			fresh[nodeID] = obs
+		}
+	}
+	return fresh
+}
+
+// forgetObservations drops the observations of a node that is no longer monitored
+func (s *service) forgetObservations(nodeID int64) {
+	s.observationsMutex.Lock()
+	defer s.observationsMutex.Unlock()
+
+	for _, byNode := range s.observations {
+		delete(byNode, nodeID)
+	}
+}
+
+// lagIssue returns an issue when the height of a node is more than MaxBlockLag
+// behind the highest fresh observation
+func (s *service) lagIssue(reason DegradedReason, scope string, height int64, observations map[int64]observation) *HealthIssue {
+	var maxHeight int64
+	for _, obs := range observations {
+		if obs.value > maxHeight {
+			maxHeight = obs.value
+		}
+	}
+	if maxHeight-height <= s.config.MaxBlockLag {
+		return nil
+	}
+	return &HealthIssue{
+		Reason:  reason,
+		Message: fmt.Sprintf("%s is at height %d, %d blocks behind the highest node at %d", scope, height, maxHeight-height, maxHeight),
+	}
+}
+
+// probeNode runs the protocol health checks of a node that accepted connections
+func (s *service) probeNode(ctx context.Context, node *Node, nodeResponse *nodes.NodeResponse) []HealthIssue {
+	switch {
+	case nodeResponse.FabricPeer != nil:
+		return s.probeFabricPeer(ctx, node, nodeResponse.FabricPeer)
+	case nodeResponse.FabricOrderer != nil:
+		return s.probeFabricOrderer(ctx, node, nodeResponse.FabricOrderer)
+	case nodeResponse.BesuNode != nil:
+		return s.probeBesuNode(ctx, node, nodeResponse.BesuNode)
+	}
+	return nil
+}
+
+// probeFabricPeer checks the operations health of a peer and its CouchDB, and compares
+// its channel heights with the other monitored peers of each channel
+func (s *service) probeFabricPeer(ctx context.Context, node *Node, peerProps *nodes.FabricPeerProperties) []HealthIssue {
+	var issues []HealthIssue
+	if issue := s.checkHealthz(ctx, node, peerProps.OperationsAddress); issue != nil {
+		issues = append(issues, *issue)
+	}
+
+	// The CouchDB check authenticates with the password of the peer
+	localPeer, err := s.nodeService.GetFabricPeerWithSecrets(ctx, node.ID)
+	if err != nil {
+		s.logger.Warn("Failed to get peer for health probe", "nodeID", node.ID, "error", err)
+		return issues
+	}
+	if localPeer.UsesCouchDB() {
+		checkCtx, cancel := context.WithTimeout(ctx, node.Timeout)
+		err := localPeer.CheckCouchDB(checkCtx)
+		cancel()
+		if err != nil {
+			issues = append(issues, HealthIssue{
+				Reason:  DegradedReasonCouchDBUnreachable,
+				Message: fmt.Sprintf("CouchDB check failed: %v", err),
+			})
+		}
+	}
+	channels, err := localPeer.GetChannels(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to get peer channels for health probe", "nodeID", node.ID, "error", err)
+		return issues
+	}
+	for _, ch := range channels {
+		// GetChannels reports a zero height when the channel info could not be read
+		if ch.BlockNum == 0 {
+			continue
+		}
+		observations := s.observe("fabric:"+ch.Name, node, ch.BlockNum)
+		if issue := s.lagIssue(DegradedReasonLedgerLagging, fmt.Sprintf("channel %s", ch.Name), ch.BlockNum, observations); issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	return issues
+}
+
+// probeFabricOrderer checks the operations health of an orderer, its participation
+// in each channel and whether the Raft channels it consents on have a leader
+func (s *service) probeFabricOrderer(ctx context.Context, node *Node, ordererProps *nodes.FabricOrdererProperties) []HealthIssue {
+	var issues []HealthIssue
+	if issue := s.checkHealthz(ctx, node, ordererProps.OperationsAddress); issue != nil {
+		issues = append(issues, *issue)
+	}
+
+	localOrderer, err := s.nodeService.GetFabricOrderer(ctx, node.ID)
+	if err != nil {
+		s.logger.Warn("Failed to get orderer for health probe", "nodeID", node.ID, "error", err)
+		return issues
+	}
+	channels, err := localOrderer.GetChannelParticipation(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to get orderer channel participation for health probe", "nodeID", node.ID, "error", err)
+		return issues
+	}
+	for _, ch := range channels {
+		if ch.Status != osnadmin.StatusActive {
+			issues = append(issues, HealthIssue{
+				Reason:  DegradedReasonOrdererNotActive,
+				Message: fmt.Sprintf("orderer status on channel %s is %s", ch.Name, ch.Status),
+			})
+		}
+		switch ch.ConsensusRelation {
+		case osnadmin.ConsensusRelationFollower, osnadmin.ConsensusRelationConfigTracker:
+			issues = append(issues, HealthIssue{
+				Reason:  DegradedReasonNotConsenter,
+				Message: fmt.Sprintf("orderer is a %s of channel %s, not a consenter", ch.ConsensusRelation, ch.Name),
+			})
+		}
+	}
+
+	issues = append(issues, s.checkRaftLeadership(ctx, node, ordererProps.OperationsAddress)...)
+	return issues
+}
+
+// checkRaftLeadership reads the Raft gauges of an orderer and reports the channels
+// for which every consenter has reported in and none of them is the leader
+func (s *service) checkRaftLeadership(ctx context.Context, node *Node, operationsAddress string) []HealthIssue {
+	if operationsAddress == "" {
+		return nil
+	}
+
+	body, err := s.getOperations(ctx, node, operationsAddress, "/metrics")
+	if err != nil {
+		s.logger.Warn("Failed to get orderer metrics for health probe", "nodeID", node.ID, "error", err)
+		return nil
+	}
+
+	isLeader := make(map[string]int64)
+	clusterSize := make(map[string]int64)
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		match := etcdraftMetricPattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		value, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			continue
+		}
+		if match[1] == "consensus_etcdraft_is_leader" {
+			isLeader[match[2]] = int64(value)
+		} else {
+			clusterSize[match[2]] = int64(value)
+		}
+	}
+
+	var issues []HealthIssue
+	for channelName, leader := range isLeader {
+		observations := s.observe("raft:"+channelName, node, leader)
+		size, ok := clusterSize[channelName]
+		if !ok || int64(len(observations)) < size {
+			continue
+		}
+		hasLeader := false
+		for _, obs := range observations {
+			if obs.value == 1 {
+				hasLeader = true
+				break
+			}
+		}
+		if !hasLeader {
+			issues = append(issues, HealthIssue{
+				Reason:  DegradedReasonNoRaftLeader,
+				Message: fmt.Sprintf("none of the %d consenters of channel %s is the Raft leader", size, channelName),
+			})
+		}
+	}
+	return issues
+}
+
+// probeBesuNode compares the block number of a Besu node with the other monitored
+// nodes of its network and checks it's connected to them
+func (s *service) probeBesuNode(ctx context.Context, node *Node, besu *nodes.BesuNodeProperties) []HealthIssue {
+	rpcURL := fmt.Sprintf("http://%s:%d", besu.RPCHost, besu.RPCPort)
+
+	blockNumberHex, err := s.callBesuRPC(ctx, node, rpcURL, "eth_blockNumber")
+	if err != nil {
+		s.logger.Warn("Failed to get Besu block number for health probe", "nodeID", node.ID, "error", err)
+		return nil
+	}
+	blockNumber, err := strconv.ParseInt(strings.TrimPrefix(blockNumberHex, "0x"), 16, 64)
+	if err != nil {
+		s.logger.Warn("Failed to parse Besu block number", "nodeID", node.ID, "value", blockNumberHex, "error", err)
+		return nil
+	}
+
+	var issues []HealthIssue
+	observations := s.observe(fmt.Sprintf("besu:%d", besu.NetworkID), node, blockNumber)
+	if issue := s.lagIssue(DegradedReasonBlockLagging, "node", blockNumber, observations); issue != nil {
+		issues = append(issues, *issue)
+	}
+
+	peerCountHex, err := s.callBesuRPC(ctx, node, rpcURL, "net_peerCount")
+	if err != nil {
+		s.logger.Warn("Failed to get Besu peer count for health probe", "nodeID", node.ID, "error", err)
+		return issues
+	}
+	peerCount, err := strconv.ParseInt(strings.TrimPrefix(peerCountHex, "0x"), 16, 64)
+	if err != nil {
+		s.logger.Warn("Failed to parse Besu peer count", "nodeID", node.ID, "value", peerCountHex, "error", err)
+		return issues
+	}
+	// A single node network has no one to peer with
+	if peerCount == 0 && len(observations) > 1 {
+		issues = append(issues, HealthIssue{
+			Reason:  DegradedReasonNoPeers,
+			Message: fmt.Sprintf("node has no peers while %d other nodes of its network are running", len(observations)-1),
+		})
+	}
+	return issues
+}
+
+// checkHealthz queries the operations /healthz endpoint of a Fabric node
+func (s *service) checkHealthz(ctx context.Context, node *Node, operationsAddress string) *HealthIssue {
+	if operationsAddress == "" {
+		return nil
+	}
+
+	body, err := s.getOperations(ctx, node, operationsAddress, "/healthz")
+	if err == nil {
+		return nil
+	}
+
+	// The failed checks are listed in the body of a 503 response
+	var health struct {
+		FailedChecks []struct {
+			Component string `json:"component"`
+			Reason    string `json:"reason"`
+		} `json:"failed_checks"`
+	}
+	if len(body) > 0 && json.Unmarshal(body, &health) == nil && len(health.FailedChecks) > 0 {
+		failed := make([]string, 0, len(health.FailedChecks))
+		for _, check := range health.FailedChecks {
+			failed = append(failed, fmt.Sprintf("%s: %s", check.Component, check.Reason))
+		}
+		return &HealthIssue{
+			Reason:  DegradedReasonUnhealthy,
+			Message: fmt.Sprintf("health check failed: %s", strings.Join(failed, ", ")),
+		}
+	}
+	return &HealthIssue{
+		Reason:  DegradedReasonUnhealthy,
+		Message: fmt.Sprintf("health check failed: %v", err),
+	}
+}
+
+// getOperations sends a GET request to the operations endpoint of a Fabric node,
+// the body is returned along with the error for non 200 responses
+func (s *service) getOperations(ctx context.Context, node *Node, operationsAddress string, path string) ([]byte, error) {
+	operationsURL := fmt.Sprintf("http://%s%s", strings.Replace(operationsAddress, "0.0.0.0", "127.0.0.1", 1), path)
+
+	ctx, cancel := context.WithTimeout(ctx, node.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, operationsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return body, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+// callBesuRPC calls a JSON-RPC method without parameters on a Besu node and returns its string result
+func (s *service) callBesuRPC(ctx context.Context, node *Node, rpcURL string, method string) (string, error) {
+	client := &http.Client{
+		Timeout: node.Timeout,
+	}
+
+	jsonBody, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  []interface{}{},
+		"id":      1,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JSON-RPC request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rpcURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var response struct {
+		Result string `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if response.Error != nil {
+		return "", fmt.Errorf("RPC error: %s (code: %d)", response.Error.Message, response.Error.Code)
+	}
+	if response.Result == "" {
+		return "", fmt.Errorf("empty %s response", method)
+	}
+	return response.Result, nil
+}
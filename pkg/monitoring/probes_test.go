@@ -0,0 +1,337 @@
+package monitoring
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	nodes "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+)
+
+// fakeNodeNotifier records node status notifications
+type fakeNodeNotifier struct {
+	notifications.Service
+
+	mu        sync.Mutex
+	degraded  []notifications.NodeDegradedData
+	recovered int
+	down      int
+}
+
+func (f *fakeNodeNotifier) SendNodeDegradedNotification(ctx context.Context, data notifications.NodeDegradedData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.degraded = append(f.degraded, data)
+	return nil
+}
+
+func (f *fakeNodeNotifier) SendNodeRecoveryNotification(ctx context.Context, data notifications.NodeUpData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.recovered++
+	return nil
+}
+
+func (f *fakeNodeNotifier) SendNodeDowntimeNotification(ctx context.Context, data notifications.NodeDowntimeData) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down++
+	return nil
+}
+
+// reasons returns the reasons notified since the last call
+func (f *fakeNodeNotifier) reasons() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var reasons []string
+	for _, data := range f.degraded {
+		reasons = append(reasons, data.Reason)
+	}
+	f.degraded = nil
+	return reasons
+}
+
+func newTestProbeNode(id int64) *Node {
+	return &Node{ID: id, Name: "node" + strconv.FormatInt(id, 10), Status: NodeStatusUp, CheckInterval: time.Minute, Timeout: 5 * time.Second, FailureThreshold: 1}
+}
+
+func TestHandleNodeCheckResultDegraded(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	notifier := &fakeNodeNotifier{}
+	s := NewService(logger.NewDefault(), nil, notifier, nil, queries).(*service)
+	node := createTestNode(t, queries, "peer0")
+	node.Status, node.FailureThreshold = NodeStatusUp, 1
+
+	lagging := HealthIssue{Reason: DegradedReasonLedgerLagging, Message: "channel mychannel is behind"}
+	unhealthy := HealthIssue{Reason: DegradedReasonUnhealthy, Message: "health check failed"}
+
+	steps := []struct {
+		name     string
+		status   NodeStatus
+		err      error
+		issues   []HealthIssue
+		notified string
+	}{
+		{"first issue", NodeStatusDegraded, nil, []HealthIssue{lagging}, "ledger_lagging"},
+		{"same issue", NodeStatusDegraded, nil, []HealthIssue{lagging}, ""},
+		{"new issue", NodeStatusDegraded, nil, []HealthIssue{lagging, unhealthy}, "unhealthy"},
+		{"recovered", NodeStatusUp, nil, nil, ""},
+		{"issue again", NodeStatusDegraded, nil, []HealthIssue{lagging}, "ledger_lagging"},
+		{"down", NodeStatusDown, errors.New("connection refused"), nil, ""},
+		{"reachable but degraded", NodeStatusDegraded, nil, []HealthIssue{unhealthy}, "unhealthy"},
+	}
+	for _, step := range steps {
+		s.handleNodeCheckResult(node, step.status, time.Millisecond, step.err, step.issues)
+		if got := strings.Join(notifier.reasons(), ","); got != step.notified {
+			t.Errorf("%s: expected notified reasons %q, got %q", step.name, step.notified, got)
+		}
+		if node.Status != step.status {
+			t.Errorf("%s: expected status %s, got %s", step.name, step.status, node.Status)
+		}
+	}
+
+	// Coming back degraded from down is a recovery and closes the incident
+	notifier.mu.Lock()
+	if notifier.down != 1 || notifier.recovered != 1 {
+		t.Errorf("expected 1 down and 1 recovery notification, got %d and %d", notifier.down, notifier.recovered)
+	}
+	notifier.mu.Unlock()
+	if _, err := queries.GetOpenNodeIncident(ctx, node.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the incident to be resolved, got %v", err)
+	}
+
+	// Issues are stored as the error of the check and degraded checks count as up
+	checks, err := queries.ListNodeChecksInRange(ctx, &db.ListNodeChecksInRangeParams{
+		NodeID:      node.ID,
+		CheckedAt:   time.Now().Add(-time.Hour).UTC(),
+		CheckedAt_2: time.Now().Add(time.Hour).UTC(),
+	})
+	if err != nil || len(checks) != len(steps) {
+		t.Fatalf("expected %d checks, got %d (%v)", len(steps), len(checks), err)
+	}
+	if checks[2].ErrorMessage.String != "channel mychannel is behind; health check failed" {
+		t.Errorf("unexpected error message %q", checks[2].ErrorMessage.String)
+	}
+	if err := s.rollupChecks(ctx, node.ID, time.Now().Add(-time.Hour), time.Now()); err != nil {
+		t.Fatalf("failed to roll up checks: %v", err)
+	}
+	buckets, err := s.GetNodeUptimeHistory(ctx, node.ID, time.Now().Add(-2*time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	var total, up int64
+	for _, bucket := range buckets {
+		total += bucket.TotalChecks
+		up += bucket.UpChecks
+	}
+	if total != int64(len(steps)) || up != total-1 {
+		t.Errorf("expected only the down check to count against uptime, got %d of %d up", up, total)
+	}
+}
+
+func TestLagIssue(t *testing.T) {
+	s := newTestService(nil)
+	s.observe("fabric:mychannel", newTestProbeNode(1), 100)
+	s.observe("fabric:mychannel", newTestProbeNode(2), 97)
+
+	// A node that stopped reporting is left out
+	s.observationsMutex.Lock()
+	s.observations["fabric:mychannel"][3] = observation{value: 500, observedAt: time.Now().Add(-time.Hour)}
+	s.observationsMutex.Unlock()
+
+	cases := []struct {
+		name    string
+		nodeID  int64
+		height  int64
+		lagging bool
+	}{
+		{"highest node", 1, 100, false},
+		{"within the lag", 2, 95, false},
+		{"behind", 4, 90, true},
+	}
+	for _, c := range cases {
+		observations := s.observe("fabric:mychannel", newTestProbeNode(c.nodeID), c.height)
+		if _, ok := observations[3]; ok {
+			t.Errorf("%s: stale observation should be left out", c.name)
+		}
+		issue := s.lagIssue(DegradedReasonLedgerLagging, "channel mychannel", c.height, observations)
+		if (issue != nil) != c.lagging {
+			t.Errorf("%s: expected lagging %v, got %+v", c.name, c.lagging, issue)
+		}
+		if issue != nil && (issue.Reason != DegradedReasonLedgerLagging || !strings.Contains(issue.Message, "10 blocks behind")) {
+			t.Errorf("%s: unexpected issue %+v", c.name, issue)
+		}
+	}
+
+	s.forgetObservations(4)
+	if observations := s.observe("fabric:mychannel", newTestProbeNode(1), 100); len(observations) != 2 {
+		t.Errorf("expected the removed node to be forgotten, got %v", observations)
+	}
+}
+
+func newTestOperationsServer(t *testing.T, handler http.HandlerFunc) string {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestCheckHealthz(t *testing.T) {
+	s := newTestService(nil)
+	node := newTestProbeNode(1)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	cases := []struct {
+		name     string
+		address  string
+		contains string
+	}{
+		{"no operations address", "", ""},
+		{"healthy", newTestOperationsServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/healthz" {
+				http.NotFound(w, r)
+			}
+		}), ""},
+		{"failed checks", newTestOperationsServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":"Service Unavailable","failed_checks":[{"component":"docker","reason":"failed to connect"}]}`))
+		}), "health check failed: docker: failed to connect"},
+		{"error status", newTestOperationsServer(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}), "unexpected status code 500"},
+		{"unreachable", strings.TrimPrefix(closed.URL, "http://"), "failed to send request"},
+	}
+	for _, c := range cases {
+		issue := s.checkHealthz(context.Background(), node, c.address)
+		if c.contains == "" {
+			if issue != nil {
+				t.Errorf("%s: unexpected issue %+v", c.name, issue)
+			}
+			continue
+		}
+		if issue == nil || issue.Reason != DegradedReasonUnhealthy || !strings.Contains(issue.Message, c.contains) {
+			t.Errorf("%s: expected an issue containing %q, got %+v", c.name, c.contains, issue)
+		}
+	}
+}
+
+func TestCheckRaftLeadership(t *testing.T) {
+	s := newTestService(nil)
+	metrics := func(isLeader int) string {
+		return newTestOperationsServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("# HELP consensus_etcdraft_is_leader The leadership status of the current node\n" +
+				`consensus_etcdraft_is_leader{channel="mychannel"} ` + strconv.Itoa(isLeader) + "\n" +
+				`consensus_etcdraft_cluster_size{channel="mychannel"} 2` + "\n" +
+				`consensus_etcdraft_is_leader{channel="other"} 0` + "\n"))
+		})
+	}
+	follower1, follower2, leader := metrics(0), metrics(0), metrics(1)
+
+	// Leadership isn't judged until every consenter reported
+	if issues := s.checkRaftLeadership(context.Background(), newTestProbeNode(1), follower1); len(issues) != 0 {
+		t.Errorf("expected no issue with one of two consenters reporting, got %+v", issues)
+	}
+	issues := s.checkRaftLeadership(context.Background(), newTestProbeNode(2), follower2)
+	if len(issues) != 1 || issues[0].Reason != DegradedReasonNoRaftLeader || !strings.Contains(issues[0].Message, "mychannel") {
+		t.Errorf("expected a missing leader on mychannel, got %+v", issues)
+	}
+	if issues := s.checkRaftLeadership(context.Background(), newTestProbeNode(1), leader); len(issues) != 0 {
+		t.Errorf("expected no issue once a leader is elected, got %+v", issues)
+	}
+	if issues := s.checkRaftLeadership(context.Background(), newTestProbeNode(1), ""); issues != nil {
+		t.Errorf("expected no issue without operations address, got %+v", issues)
+	}
+}
+
+// newTestBesuRPC serves eth_blockNumber and net_peerCount with the given results
+func newTestBesuRPC(t *testing.T, results map[string]string) *nodes.BesuNodeProperties {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		result, ok := results[req.Method]
+		if !ok {
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]interface{}{"code": -32601, "message": "Method not found"}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"result": result})
+	}))
+	t.Cleanup(server.Close)
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("failed to split server address: %v", err)
+	}
+	portNumber, _ := strconv.Atoi(port)
+	return &nodes.BesuNodeProperties{NetworkID: 1, RPCHost: host, RPCPort: uint(portNumber)}
+}
+
+func TestProbeBesuNode(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(nil)
+
+	// A single node network has no one to peer with
+	if issues := s.probeBesuNode(ctx, newTestProbeNode(1), newTestBesuRPC(t, map[string]string{"eth_blockNumber": "0x64", "net_peerCount": "0x0"})); len(issues) != 0 {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+
+	issues := s.probeBesuNode(ctx, newTestProbeNode(2), newTestBesuRPC(t, map[string]string{"eth_blockNumber": "0x32", "net_peerCount": "0x0"}))
+	reasons := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		reasons = append(reasons, string(issue.Reason))
+	}
+	if strings.Join(reasons, ",") != "block_lagging,no_peers" {
+		t.Errorf("expected a lagging node without peers, got %+v", issues)
+	}
+
+	// Probes that fail to read the node don't report issues
+	failing := map[string]map[string]string{
+		"no block number":      {"net_peerCount": "0x1"},
+		"invalid block number": {"eth_blockNumber": "latest", "net_peerCount": "0x1"},
+		"no peer count":        {"eth_blockNumber": "0x64"},
+	}
+	for name, results := range failing {
+		if issues := s.probeBesuNode(ctx, newTestProbeNode(3), newTestBesuRPC(t, results)); len(issues) != 0 {
+			t.Errorf("%s: unexpected issues %+v", name, issues)
+		}
+	}
+}
+
+func TestCallBesuRPCErrors(t *testing.T) {
+	s := newTestService(nil)
+	node := newTestProbeNode(1)
+	notJSON := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>"))
+	}))
+	defer notJSON.Close()
+	besu := newTestBesuRPC(t, map[string]string{"net_version": ""})
+	rpcURL := "http://" + net.JoinHostPort(besu.RPCHost, strconv.Itoa(int(besu.RPCPort)))
+
+	cases := map[string]struct {
+		url, method, contains string
+	}{
+		"rpc error":    {rpcURL, "eth_syncing", "Method not found (code: -32601)"},
+		"empty result": {rpcURL, "net_version", "empty net_version response"},
+		"not json":     {notJSON.URL, "net_version", "failed to parse response"},
+		"invalid url":  {"http://[::1", "net_version", "failed to create request"},
+	}
+	for name, c := range cases {
+		_, err := s.callBesuRPC(context.Background(), node, c.url, c.method)
+		if err == nil || !strings.Contains(err.Error(), c.contains) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, c.contains, err)
+		}
+	}
+}
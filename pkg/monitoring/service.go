@@ -1,19 +1,17 @@
 package monitoring
 
 import (
-	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/chainlaunch/chainlaunch/pkg/certutils"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
 	nodes "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
 	"github.com/chainlaunch/chainlaunch/pkg/notifications"
@@ -36,6 +34,13 @@ type Service interface {
 	GetNodeStatus(nodeID int64) (*NodeCheck, error)
 	// GetAllNodeStatuses returns the current status of all nodes
 	GetAllNodeStatuses() []*NodeCheck
+
+	// GetNodeUptime returns the uptime, incidents and MTTR of a node over a time window
+	GetNodeUptime(ctx context.Context, nodeID int64, from, to time.Time) (*UptimeReport, error)
+	// GetNetworkUptime returns the uptime of the nodes of a network over a time window
+	GetNetworkUptime(ctx context.Context, networkID int64, from, to time.Time) (*NetworkUptimeReport, error)
+	// GetNodeUptimeHistory returns the hourly availability of a node over a time window
+	GetNodeUptimeHistory(ctx context.Context, nodeID int64, from, to time.Time) ([]UptimeBucket, error)
 }
 
 // service implements the Service interface
@@ -51,10 +56,15 @@ type service struct {
 	lastCheckResults map[int64]*NodeCheck
 	resultsMutex     sync.RWMutex
 	nodeService      *nodes.NodeService
+	queries          *db.Queries
+	// observations holds the heights and Raft leadership reported by each node,
+	// keyed by channel or network, to compare nodes with each other
+	observations      map[string]map[int64]observation
+	observationsMutex sync.Mutex
 }
 
 // NewService creates a new monitoring service
-func NewService(logger *logger.Logger, config *Config, notificationSvc notifications.Service, nodeService *nodes.NodeService) Service {
+func NewService(logger *logger.Logger, config *Config, notificationSvc notifications.Service, nodeService *nodes.NodeService, queries *db.Queries) Service {
 	if config == nil {
 		config = DefaultConfig()
 	}
@@ -69,17 +79,31 @@ func NewService(logger *logger.Logger, config *Config, notificationSvc notificat
 		httpClient: &http.Client{
 			Timeout: config.DefaultTimeout,
 		},
-		nodeService: nodeService,
+		nodeService:  nodeService,
+		queries:      queries,
+		observations: make(map[string]map[int64]observation),
 	}
 }
 
 // Start begins monitoring nodes
 func (s *service) Start(ctx context.Context) error {
+	if s.config.MaintenanceInterval <= 0 {
+		return fmt.Errorf("maintenance interval must be positive")
+	}
+
+	// Restore the statuses recorded before the last shutdown
+	if err := s.restoreState(ctx); err != nil {
+		s.logger.Errorf("Failed to restore monitoring state: %v", err)
+	}
+
 	// Create a worker pool to check nodes
 	for i := 0; i < s.config.Workers; i++ {
 		s.workerWaitGroup.Add(1)
 		go s.worker(ctx, i)
 	}
+
+	s.workerWaitGroup.Add(1)
+	go s.maintenance(ctx)
 	return nil
 }
 
@@ -113,6 +137,19 @@ func (s *service) AddNode(node *Node) error {
 		node.FailureThreshold = s.config.DefaultFailureThreshold
 	}
 
+	// Resume from the last recorded check, so a node that was down before a
+	// restart is still reported as recovering instead of newly up
+	s.resultsMutex.Lock()
+	if result, exists := s.lastCheckResults[node.ID]; exists {
+		node.Status = result.Node.Status
+		node.LastChecked = result.Node.LastChecked
+		node.LastStatusChange = result.Node.LastStatusChange
+		node.FailureCount = result.Node.FailureCount
+		node.Issues = result.Node.Issues
+		result.Node = node
+	}
+	s.resultsMutex.Unlock()
+
 	s.nodesMutex.Lock()
 	s.nodes[node.ID] = node
 	s.nodesMutex.Unlock()
@@ -130,6 +167,8 @@ func (s *service) RemoveNode(nodeID int64) error {
 	delete(s.lastCheckResults, nodeID)
 	s.resultsMutex.Unlock()
 
+	s.forgetObservations(nodeID)
+
 	return nil
 }
 
@@ -210,7 +249,7 @@ func (s *service) checkNodes(ctx context.Context) {
 func (s *service) checkNode(ctx context.Context, node *Node) {
 	nodeResponse, err := s.nodeService.GetNode(ctx, node.ID)
 	if err != nil {
-		s.handleNodeCheckResult(node, NodeStatusDown, 0, err)
+		s.handleNodeCheckResult(node, NodeStatusDown, 0, err, nil)
 		return
 	}
 
@@ -231,11 +270,17 @@ func (s *service) checkNode(ctx context.Context, node *Node) {
 	}
 
 	if checkErr != nil {
-		s.handleNodeCheckResult(node, NodeStatusDown, responseTime, checkErr)
+		s.handleNodeCheckResult(node, NodeStatusDown, responseTime, checkErr, nil)
 		return
 	}
 
-	s.handleNodeCheckResult(node, status, responseTime, nil)
+	// The node accepts connections, check it's actually doing its job
+	issues := s.probeNode(ctx, node, nodeResponse)
+	if len(issues) > 0 {
+		status = NodeStatusDegraded
+	}
+
+	s.handleNodeCheckResult(node, status, responseTime, nil, issues)
 }
 
 // checkFabricPeer checks a Fabric peer node using TLS only
@@ -350,74 +395,16 @@ func (s *service) checkFabricOrderer(ctx context.Context, node *Node, orderer *n
 func (s *service) checkBesuNode(ctx context.Context, node *Node, besu *nodes.BesuNodeProperties) (NodeStatus, time.Duration, error) {
 	start := time.Now()
 
-	// Create HTTP client
-	client := &http.Client{
-		Timeout: node.Timeout,
-	}
-
-	// Prepare JSON-RPC request
-	requestBody := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  "net_version",
-		"params":  []interface{}{},
-		"id":      1,
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
-	if err != nil {
-		return NodeStatusDown, time.Since(start), fmt.Errorf("failed to marshal JSON-RPC request: %w", err)
-	}
 	rpcUrl := fmt.Sprintf("http://%s:%d", besu.RPCHost, besu.RPCPort)
-	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", rpcUrl, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return NodeStatusDown, time.Since(start), fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	// Send request
-	resp, err := client.Do(req)
-	if err != nil {
-		return NodeStatusDown, time.Since(start), fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return NodeStatusDown, time.Since(start), fmt.Errorf("failed to read response: %w", err)
-	}
-
-	// Parse JSON-RPC response
-	var response struct {
-		Jsonrpc string `json:"jsonrpc"`
-		ID      int    `json:"id"`
-		Result  string `json:"result"`
-		Error   *struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error"`
-	}
-
-	if err := json.Unmarshal(body, &response); err != nil {
-		return NodeStatusDown, time.Since(start), fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	// Check for RPC error
-	if response.Error != nil {
-		return NodeStatusDown, time.Since(start), fmt.Errorf("RPC error: %s (code: %d)", response.Error.Message, response.Error.Code)
-	}
-
-	// Verify we got a valid network version
-	if response.Result == "" {
-		return NodeStatusDown, time.Since(start), fmt.Errorf("invalid network version response")
+	if _, err := s.callBesuRPC(ctx, node, rpcUrl, "net_version"); err != nil {
+		return NodeStatusDown, time.Since(start), err
 	}
 
 	return NodeStatusUp, time.Since(start), nil
 }
 
 // handleNodeCheckResult processes the result of a node check
-func (s *service) handleNodeCheckResult(node *Node, status NodeStatus, responseTime time.Duration, err error) {
+func (s *service) handleNodeCheckResult(node *Node, status NodeStatus, responseTime time.Duration, err error, issues []HealthIssue) {
 	now := time.Now()
 
 	// Create the check result
@@ -427,6 +414,7 @@ func (s *service) handleNodeCheckResult(node *Node, status NodeStatus, responseT
 		ResponseTime: responseTime,
 		Error:        err,
 		Timestamp:    now,
+		Issues:       issues,
 	}
 
 	// Update the node's status
@@ -434,7 +422,9 @@ func (s *service) handleNodeCheckResult(node *Node, status NodeStatus, responseT
 
 	// Capture the previous status for status change detection
 	previousStatus := node.Status
+	previousStatusChange := node.LastStatusChange
 	wasDown := previousStatus == NodeStatusDown
+	previousIssues := node.Issues
 
 	// Update last checked time
 	node.LastChecked = now
@@ -454,14 +444,16 @@ func (s *service) handleNodeCheckResult(node *Node, status NodeStatus, responseT
 
 	// Update node status
 	node.Status = status
+	node.Issues = issues
 
-	// If node was down but is now up, record the recovery time
+	// If node was down but is now reachable, record the recovery time
 	var recoveryTime time.Time
 	var downtimeDuration time.Duration
-	if wasDown && status == NodeStatusUp {
+	if wasDown && status != NodeStatusDown {
 		recoveryTime = now
-		downtimeDuration = now.Sub(node.LastStatusChange)
+		downtimeDuration = now.Sub(previousStatusChange)
 	}
+	checkResult.FailureCount = node.FailureCount
 
 	s.nodesMutex.Unlock()
 
@@ -470,14 +462,29 @@ func (s *service) handleNodeCheckResult(node *Node, status NodeStatus, responseT
 	s.lastCheckResults[node.ID] = checkResult
 	s.resultsMutex.Unlock()
 
-	// Send notifications if needed
 	ctx := context.Background()
+	s.recordCheck(ctx, checkResult, statusChanged)
+
+	// Send notifications if needed
 	if status == NodeStatusDown && node.FailureCount >= node.FailureThreshold {
 		s.sendNodeDownNotification(ctx, node, err)
-	} else if statusChanged && status == NodeStatusUp && wasDown {
+	} else if statusChanged && status != NodeStatusDown && wasDown {
 		// Node has recovered - send recovery notification
 		s.sendNodeRecoveryNotification(ctx, node, responseTime, recoveryTime, downtimeDuration)
 	}
+
+	// Only notify about reasons that were not already reported on the previous check
+	reported := make(map[DegradedReason]bool, len(previousIssues))
+	for _, issue := range previousIssues {
+		reported[issue.Reason] = true
+	}
+	for _, issue := range issues {
+		if reported[issue.Reason] {
+			continue
+		}
+		reported[issue.Reason] = true
+		s.sendNodeDegradedNotification(ctx, node, issue)
+	}
 }
 
 // sendNodeDownNotification sends a notification that a node is down
@@ -503,6 +510,25 @@ func (s *service) sendNodeDownNotification(ctx context.Context, node *Node, err
 	}
 }
 
+// sendNodeDegradedNotification sends a notification that a node failed a protocol health check
+func (s *service) sendNodeDegradedNotification(ctx context.Context, node *Node, issue HealthIssue) {
+	data := notifications.NodeDegradedData{
+		NodeID:     node.ID,
+		NodeName:   node.Name,
+		NodeType:   node.Platform,
+		Endpoint:   node.Endpoint,
+		Reason:     string(issue.Reason),
+		Message:    issue.Message,
+		DetectedAt: node.LastChecked,
+	}
+
+	// Send the notification
+	if err := s.notificationSvc.SendNodeDegradedNotification(ctx, data); err != nil {
+		// Just log the error; we don't want to create a notification loop
+		s.logger.Errorf("Failed to send node degraded notification: %v", err)
+	}
+}
+
 // sendNodeRecoveryNotification sends a notification that a node has recovered
 func (s *service) sendNodeRecoveryNotification(ctx context.Context, node *Node, responseTime time.Duration, recoveryTime time.Time, downtimeDuration time.Duration) {
 	data := notifications.NodeUpData{
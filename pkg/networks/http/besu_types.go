@@ -11,9 +11,9 @@ type CreateBesuNetworkRequest struct {
 	Description string `json:"description"`
 	// @Description Network configuration
 	Config struct {
-		// @Description Consensus algorithm (e.g. "qbft")
+		// @Description Consensus algorithm: "qbft", "ibft2" or "clique"
 		// @Required
-		Consensus string `json:"consensus" validate:"required"`
+		Consensus string `json:"consensus" validate:"required,oneof=qbft ibft2 clique" enums:"qbft,ibft2,clique"`
 		// @Description Chain ID for the network
 		// @Default 1337
 		// @Required
@@ -26,10 +26,9 @@ type CreateBesuNetworkRequest struct {
 		// @Default 30000
 		// @Required
 		EpochLength int `json:"epochLength" validate:"required" example:"30000"`
-		// @Description Request timeout in seconds
-		// @Required
-		RequestTimeout int `json:"requestTimeout" validate:"required"`
-		// @Description List of initial validator key IDs
+		// @Description Request timeout in seconds, required for qbft and ibft2
+		RequestTimeout int `json:"requestTimeout" validate:"required_unless=Consensus clique"`
+		// @Description List of initial validator key IDs (signers for clique)
 		// @Required
 		// @MinItems 1
 		InitialValidatorKeyIds []int64 `json:"initialValidatorsKeyIds" validate:"required,min=1"`
@@ -0,0 +1,207 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// BesuValidatorChangeRequest represents a validator to add to or remove from a Besu network.
+// The validator is identified by its key or by its address.
+type BesuValidatorChangeRequest struct {
+	Action  string `json:"action" validate:"required,oneof=add remove"`
+	KeyID   int64  `json:"keyId,omitempty"`
+	Address string `json:"address,omitempty" validate:"required_without=KeyID"`
+}
+
+// UpdateBesuValidatorsRequest represents a request to change the validator set of a Besu network
+type UpdateBesuValidatorsRequest struct {
+	Changes []BesuValidatorChangeRequest `json:"changes" validate:"required,min=1,dive"`
+}
+
+// BesuValidatorChangesResponse represents a list of validator changes
+type BesuValidatorChangesResponse struct {
+	Changes []*service.BesuValidatorChange `json:"changes"`
+}
+
+// @Summary Get the validators of a Besu network
+// @Description Get the current validator set of a Besu network, as reported by its nodes, and the validator changes still being voted
+// @Tags Besu Networks
+// @Produce json
+// @Param id path int true "Network ID"
+// @Success 200 {object} service.BesuValidatorSet
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/besu/{id}/validators [get]
+func (h *Handler) BesuGetValidators(w http.ResponseWriter, r *http.Request) {
+	networkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_network_id", "Invalid network ID")
+		return
+	}
+
+	validators, err := h.networkService.GetBesuValidators(r.Context(), networkID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "get_validators_failed", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, validators)
+}
+
+// @Summary Add or remove Besu validators
+// @Description Make the validators managed by this instance vote for adding or removing validators.
+// @Description A change is applied by the network once more than half of the validators voted for it;
+// @Description its progress is tracked until it shows up in the validator set.
+// @Tags Besu Networks
+// @Accept json
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param request body UpdateBesuValidatorsRequest true "Validator changes"
+// @Success 202 {object} BesuValidatorChangesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/besu/{id}/validators [post]
+func (h *Handler) BesuUpdateValidators(w http.ResponseWriter, r *http.Request) {
+	networkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_network_id", "Invalid network ID")
+		return
+	}
+
+	var req UpdateBesuValidatorsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	requests := make([]service.BesuValidatorChangeRequest, len(req.Changes))
+	for i, change := range req.Changes {
+		requests[i] = service.BesuValidatorChangeRequest{
+			Action:  change.Action,
+			KeyID:   change.KeyID,
+			Address: change.Address,
+		}
+	}
+
+	changes, err := h.networkService.ProposeBesuValidatorChanges(r.Context(), networkID, requests, currentUsername(r))
+	if err != nil {
+		writeBesuValidatorError(w, "update_validators_failed", err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, BesuValidatorChangesResponse{Changes: changes})
+}
+
+// @Summary List Besu validator changes
+// @Description List the validator changes voted on a Besu network, newest first
+// @Tags Besu Networks
+// @Produce json
+// @Param id path int true "Network ID"
+// @Success 200 {object} BesuValidatorChangesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/besu/{id}/validators/changes [get]
+func (h *Handler) BesuListValidatorChanges(w http.ResponseWriter, r *http.Request) {
+	networkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_network_id", "Invalid network ID")
+		return
+	}
+
+	changes, err := h.networkService.ListBesuValidatorChanges(r.Context(), networkID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "list_validator_changes_failed", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, BesuValidatorChangesResponse{Changes: changes})
+}
+
+// @Summary Get a Besu validator change
+// @Description Get a validator change with the votes cast for it
+// @Tags Besu Networks
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param changeId path int true "Validator change ID"
+// @Success 200 {object} service.BesuValidatorChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/besu/{id}/validators/changes/{changeId} [get]
+func (h *Handler) BesuGetValidatorChange(w http.ResponseWriter, r *http.Request) {
+	networkID, changeID, ok := parseBesuValidatorChangeParams(w, r)
+	if !ok {
+		return
+	}
+
+	change, err := h.networkService.GetBesuValidatorChange(r.Context(), networkID, changeID)
+	if err != nil {
+		writeBesuValidatorError(w, "get_validator_change_failed", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, change)
+}
+
+// @Summary Cancel a Besu validator change
+// @Description Withdraw the votes of a pending validator change
+// @Tags Besu Networks
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param changeId path int true "Validator change ID"
+// @Success 200 {object} service.BesuValidatorChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/besu/{id}/validators/changes/{changeId}/cancel [post]
+func (h *Handler) BesuCancelValidatorChange(w http.ResponseWriter, r *http.Request) {
+	networkID, changeID, ok := parseBesuValidatorChangeParams(w, r)
+	if !ok {
+		return
+	}
+
+	change, err := h.networkService.CancelBesuValidatorChange(r.Context(), networkID, changeID)
+	if err != nil {
+		writeBesuValidatorError(w, "cancel_validator_change_failed", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, change)
+}
+
+func parseBesuValidatorChangeParams(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	networkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_network_id", "Invalid network ID")
+		return 0, 0, false
+	}
+	changeID, err := strconv.ParseInt(chi.URLParam(r, "changeId"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_change_id", "Invalid validator change ID")
+		return 0, 0, false
+	}
+	return networkID, changeID, true
+}
+
+func writeBesuValidatorError(w http.ResponseWriter, code string, err error) {
+	switch {
+	case errors.Is(err, service.ErrBesuValidatorChangeNotFound):
+		writeError(w, http.StatusNotFound, "validator_change_not_found", err.Error())
+	case errors.Is(err, service.ErrBesuValidatorChangeNotPending):
+		writeError(w, http.StatusConflict, "validator_change_not_pending", err.Error())
+	case errors.Is(err, service.ErrInvalidBesuValidatorChange):
+		writeError(w, http.StatusBadRequest, "invalid_validator_change", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, code, err.Error())
+	}
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 
 	"encoding/base64"
 
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
 	httpchainlaunch "github.com/chainlaunch/chainlaunch/pkg/http"
 	"github.com/chainlaunch/chainlaunch/pkg/networks/service"
 	"github.com/chainlaunch/chainlaunch/pkg/networks/service/fabric"
@@ -36,39 +38,55 @@ func NewHandler(networkService *service.NetworkService, nodeService *nodeservice
 	}
 }
 
-// RegisterRoutes registers the network routes
+// RegisterRoutes registers the network routes. Creating networks requires the
+// manager role, changing a network requires it globally or on the network and
+// joining or removing a node requires it on both the network and the node.
 func (h *Handler) RegisterRoutes(r chi.Router) {
+	createNetwork := auth.RequireRole(auth.RoleManager)
+	manageNetwork := auth.RequireResourceRole(auth.ResourceNetwork, "id", auth.RoleManager)
+	managePeer := auth.RequireResourceRole(auth.ResourceNode, "peerId", auth.RoleManager)
+	manageOrderer := auth.RequireResourceRole(auth.ResourceNode, "ordererId", auth.RoleManager)
+
 	// Fabric network routes with resource middleware
 	r.Route("/networks/fabric", func(r chi.Router) {
 		// Add resource middleware for all Fabric network routes
 		r.Use(httpchainlaunch.ResourceMiddleware("fabric_network"))
 
 		r.Get("/", h.FabricNetworkList)
-		r.Post("/", h.FabricNetworkCreate)
-		r.Delete("/{id}", h.FabricNetworkDelete)
-		r.Post("/{id}/peers/{peerId}/join", h.FabricNetworkJoinPeer)
-		r.Post("/{id}/orderers/{ordererId}/join", h.FabricNetworkJoinOrderer)
-		r.Delete("/{id}/peers/{peerId}", h.FabricNetworkRemovePeer)
-		r.Delete("/{id}/orderers/{ordererId}", h.FabricNetworkRemoveOrderer)
+		r.With(createNetwork).Post("/", h.FabricNetworkCreate)
+		r.With(manageNetwork).Delete("/{id}", h.FabricNetworkDelete)
+		r.With(manageNetwork, managePeer).Post("/{id}/peers/{peerId}/join", h.FabricNetworkJoinPeer)
+		r.With(manageNetwork, manageOrderer).Post("/{id}/orderers/{ordererId}/join", h.FabricNetworkJoinOrderer)
+		r.With(manageNetwork, managePeer).Delete("/{id}/peers/{peerId}", h.FabricNetworkRemovePeer)
+		r.With(manageNetwork, manageOrderer).Delete("/{id}/orderers/{ordererId}", h.FabricNetworkRemoveOrderer)
 		r.Get("/{id}/channel-config", h.FabricNetworkGetChannelConfig)
 		r.Get("/{id}/current-channel-config", h.FabricNetworkGetCurrentChannelConfig)
 		r.Get("/{id}", h.FabricNetworkGet)
-		r.Post("/{id}/reload-block", h.ReloadNetworkBlock)
+		r.With(manageNetwork).Post("/{id}/reload-block", h.ReloadNetworkBlock)
 		r.Get("/{id}/nodes", h.FabricNetworkGetNodes)
-		r.Post("/{id}/nodes", h.FabricNetworkAddNode)
-		r.Post("/{id}/peers/{peerId}/unjoin", h.FabricNetworkUnjoinPeer)
-		r.Post("/{id}/orderers/{ordererId}/unjoin", h.FabricNetworkUnjoinOrderer)
-		r.Post("/{id}/anchor-peers", h.FabricNetworkSetAnchorPeers)
+		r.With(manageNetwork).Post("/{id}/nodes", h.FabricNetworkAddNode)
+		r.With(manageNetwork, managePeer).Post("/{id}/peers/{peerId}/unjoin", h.FabricNetworkUnjoinPeer)
+		r.With(manageNetwork, manageOrderer).Post("/{id}/orderers/{ordererId}/unjoin", h.FabricNetworkUnjoinOrderer)
+		r.With(manageNetwork).Post("/{id}/anchor-peers", h.FabricNetworkSetAnchorPeers)
 		r.Get("/{id}/organizations/{orgId}/network-config", h.FabricNetworkGetOrganizationConfig)
 		r.Get("/by-name/{name}", h.FabricNetworkGetByName)
-		r.Post("/import", h.ImportFabricNetwork)
-		r.Post("/import-with-org", h.ImportFabricNetworkWithOrg)
-		r.Post("/{id}/update-config", h.FabricUpdateChannelConfig)
+		r.With(createNetwork).Post("/import", h.ImportFabricNetwork)
+		r.With(createNetwork).Post("/import-with-org", h.ImportFabricNetworkWithOrg)
+		r.With(manageNetwork).Post("/{id}/update-config", h.FabricUpdateChannelConfig)
 		r.Get("/{id}/blocks", h.FabricGetBlocks)
 		r.Get("/{id}/blocks/{blockNum}", h.FabricGetBlock)
 		r.Get("/{id}/info", h.GetChainInfo)
 		r.Get("/{id}/transactions/{txId}", h.FabricGetTransaction)
-		r.Post("/{id}/organization-crl", h.UpdateOrganizationCRL)
+		r.With(manageNetwork).Post("/{id}/organization-crl", h.UpdateOrganizationCRL)
+		r.Get("/{id}/proposals", h.FabricListProposals)
+		r.With(manageNetwork).Post("/{id}/proposals", h.FabricCreateProposal)
+		r.With(manageNetwork).Post("/{id}/proposals/import", h.FabricImportProposal)
+		r.Get("/{id}/proposals/{proposalId}", h.FabricGetProposal)
+		r.Get("/{id}/proposals/{proposalId}/export", h.FabricExportProposal)
+		r.Post("/{id}/proposals/{proposalId}/sign", h.FabricSignProposal)
+		r.With(manageNetwork).Post("/{id}/proposals/{proposalId}/signatures", h.FabricAddProposalSignature)
+		r.With(manageNetwork).Post("/{id}/proposals/{proposalId}/submit", h.FabricSubmitProposal)
+		r.With(manageNetwork).Post("/{id}/proposals/{proposalId}/cancel", h.FabricCancelProposal)
 	})
 
 	// Besu network routes with resource middleware
@@ -77,10 +95,15 @@ func (h *Handler) RegisterRoutes(r chi.Router) {
 		r.Use(httpchainlaunch.ResourceMiddleware("besu_network"))
 
 		r.Get("/", h.BesuNetworkList)
-		r.Post("/", h.BesuNetworkCreate)
-		r.Post("/import", h.ImportBesuNetwork)
+		r.With(createNetwork).Post("/", h.BesuNetworkCreate)
+		r.With(createNetwork).Post("/import", h.ImportBesuNetwork)
 		r.Get("/{id}", h.BesuNetworkGet)
-		r.Delete("/{id}", h.BesuNetworkDelete)
+		r.With(manageNetwork).Delete("/{id}", h.BesuNetworkDelete)
+		r.Get("/{id}/validators", h.BesuGetValidators)
+		r.With(manageNetwork).Post("/{id}/validators", h.BesuUpdateValidators)
+		r.Get("/{id}/validators/changes", h.BesuListValidatorChanges)
+		r.Get("/{id}/validators/changes/{changeId}", h.BesuGetValidatorChange)
+		r.With(manageNetwork).Post("/{id}/validators/changes/{changeId}/cancel", h.BesuCancelValidatorChange)
 	})
 }
 
@@ -198,6 +221,8 @@ func (h *Handler) FabricNetworkCreate(w http.ResponseWriter, r *http.Request) {
 		ChannelName:          req.Name,
 		PeerOrganizations:    make([]types.Organization, len(req.Config.PeerOrganizations)),
 		OrdererOrganizations: make([]types.Organization, len(req.Config.OrdererOrganizations)),
+		ConsensusType:        types.FabricConsensusType(req.Config.ConsensusType),
+		SmartBFTOptions:      req.Config.SmartBFTOptions,
 	}
 
 	// Convert peer organizations
@@ -573,6 +598,16 @@ func (h *Handler) FabricNetworkAddNode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Adding a node joins it to the channel, so the node has to be managed as well
+	if err := auth.Authorize(r.Context(), auth.ResourceNode, req.NodeID, auth.RoleManager); err != nil {
+		if errors.Is(err, auth.ErrForbidden) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "authorization_failed", err.Error())
+		return
+	}
+
 	err = h.networkService.AddNodeToNetwork(r.Context(), networkID, req.NodeID, req.Role)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "add_node_failed", err.Error())
@@ -1221,8 +1256,8 @@ func (h *Handler) ImportBesuNetwork(w http.ResponseWriter, r *http.Request) {
 // @Description A single configuration update operation
 type ConfigUpdateOperationRequest struct {
 	// Type is the type of configuration update operation
-	// enum: add_org,remove_org,update_org_msp,set_anchor_peers,add_consenter,remove_consenter,update_consenter,update_etcd_raft_options,update_batch_size,update_batch_timeout
-	Type string `json:"type" validate:"required,oneof=add_org remove_org update_org_msp set_anchor_peers add_consenter remove_consenter update_consenter update_etcd_raft_options update_batch_size update_batch_timeout"`
+	// enum: add_org,remove_org,update_org_msp,set_anchor_peers,add_consenter,remove_consenter,update_consenter,update_etcd_raft_options,update_batch_size,update_batch_timeout,add_bft_consenter,remove_bft_consenter,update_smartbft_options
+	Type string `json:"type" validate:"required,oneof=add_org remove_org update_org_msp set_anchor_peers add_consenter remove_consenter update_consenter update_etcd_raft_options update_batch_size update_batch_timeout add_bft_consenter remove_bft_consenter update_smartbft_options"`
 
 	// Payload contains the operation-specific data
 	// The structure depends on the operation type:
@@ -1236,6 +1271,9 @@ type ConfigUpdateOperationRequest struct {
 	// - update_etcd_raft_options: UpdateEtcdRaftOptionsPayload
 	// - update_batch_size: UpdateBatchSizePayload
 	// - update_batch_timeout: UpdateBatchTimeoutPayload
+	// - add_bft_consenter: AddBFTConsenterPayload
+	// - remove_bft_consenter: RemoveBFTConsenterPayload
+	// - update_smartbft_options: UpdateSmartBFTOptionsPayload
 	// @Description The payload for the configuration update operation
 	// @Description Can be one of:
 	// @Description - AddOrgPayload when type is "add_org"
@@ -1248,6 +1286,9 @@ type ConfigUpdateOperationRequest struct {
 	// @Description - UpdateEtcdRaftOptionsPayload when type is "update_etcd_raft_options"
 	// @Description - UpdateBatchSizePayload when type is "update_batch_size"
 	// @Description - UpdateBatchTimeoutPayload when type is "update_batch_timeout"
+	// @Description - AddBFTConsenterPayload when type is "add_bft_consenter"
+	// @Description - RemoveBFTConsenterPayload when type is "remove_bft_consenter"
+	// @Description - UpdateSmartBFTOptionsPayload when type is "update_smartbft_options"
 	Payload json.RawMessage `json:"payload" validate:"required"`
 }
 
@@ -1409,6 +1450,73 @@ type UpdateBatchTimeoutPayload struct {
 	Timeout string `json:"timeout" validate:"required"` // e.g., "2s"
 }
 
+// Example:
+//
+//	{
+//	  "host": "orderer4.example.com",
+//	  "port": 7050,
+//	  "msp_id": "OrdererMSP",
+//	  "identity": "-----BEGIN CERTIFICATE-----\n...\n-----END CERTIFICATE-----",
+//	  "client_tls_cert": "-----BEGIN CERTIFICATE-----\n...\n-----END CERTIFICATE-----",
+//	  "server_tls_cert": "-----BEGIN CERTIFICATE-----\n...\n-----END CERTIFICATE-----"
+//	}
+//
+// AddBFTConsenterPayload represents the payload for adding a consenter to a BFT channel
+type AddBFTConsenterPayload struct {
+	ID            uint32 `json:"id,omitempty"`
+	Host          string `json:"host" validate:"required"`
+	Port          int    `json:"port" validate:"required,min=1,max=65535"`
+	MSPID         string `json:"msp_id" validate:"required"`
+	Identity      string `json:"identity" validate:"required"`
+	ClientTLSCert string `json:"client_tls_cert" validate:"required"`
+	ServerTLSCert string `json:"server_tls_cert" validate:"required"`
+}
+
+// Example:
+//
+//	{
+//	  "host": "orderer4.example.com",
+//	  "port": 7050
+//	}
+//
+// RemoveBFTConsenterPayload represents the payload for removing a consenter from a BFT channel
+type RemoveBFTConsenterPayload struct {
+	Host string `json:"host" validate:"required"`
+	Port int    `json:"port" validate:"required,min=1,max=65535"`
+}
+
+// Example:
+//
+//	{
+//	  "request_batch_max_count": 100,
+//	  "request_batch_max_interval": "50ms",
+//	  "leader_heartbeat_timeout": "1m0s",
+//	  "leader_rotation": false
+//	}
+//
+// UpdateSmartBFTOptionsPayload represents the payload for updating the SmartBFT options
+// of a BFT channel, fields that are omitted keep their current value
+type UpdateSmartBFTOptionsPayload struct {
+	RequestBatchMaxCount      uint64 `json:"request_batch_max_count,omitempty"`
+	RequestBatchMaxBytes      uint64 `json:"request_batch_max_bytes,omitempty"`
+	RequestBatchMaxInterval   string `json:"request_batch_max_interval,omitempty"`
+	IncomingMessageBufferSize uint64 `json:"incoming_message_buffer_size,omitempty"`
+	RequestPoolSize           uint64 `json:"request_pool_size,omitempty"`
+	RequestForwardTimeout     string `json:"request_forward_timeout,omitempty"`
+	RequestComplainTimeout    string `json:"request_complain_timeout,omitempty"`
+	RequestAutoRemoveTimeout  string `json:"request_auto_remove_timeout,omitempty"`
+	ViewChangeResendInterval  string `json:"view_change_resend_interval,omitempty"`
+	ViewChangeTimeout         string `json:"view_change_timeout,omitempty"`
+	LeaderHeartbeatTimeout    string `json:"leader_heartbeat_timeout,omitempty"`
+	LeaderHeartbeatCount      uint64 `json:"leader_heartbeat_count,omitempty"`
+	CollectTimeout            string `json:"collect_timeout,omitempty"`
+	SyncOnStart               *bool  `json:"sync_on_start,omitempty"`
+	SpeedUpViewChange         *bool  `json:"speed_up_view_change,omitempty"`
+	LeaderRotation            *bool  `json:"leader_rotation,omitempty"`
+	DecisionsPerLeader        uint64 `json:"decisions_per_leader,omitempty"`
+	RequestMaxBytes           uint64 `json:"request_max_bytes,omitempty"`
+}
+
 // UpdateFabricNetworkRequest represents a request to update a Fabric network
 type UpdateFabricNetworkRequest struct {
 	Operations []ConfigUpdateOperationRequest `json:"operations" validate:"required,min=1,dive"`
@@ -1431,6 +1539,9 @@ type UpdateFabricNetworkRequest struct {
 // @Success 206 {object} UpdateEtcdRaftOptionsPayload
 // @Success 207 {object} UpdateBatchSizePayload
 // @Success 208 {object} UpdateBatchTimeoutPayload
+// @Success 209 {object} AddBFTConsenterPayload
+// @Success 210 {object} RemoveBFTConsenterPayload
+// @Success 211 {object} UpdateSmartBFTOptionsPayload
 // @Router /dummy [post]
 func (h *Handler) DummyHandler(w http.ResponseWriter, r *http.Request) {
 	writeError(w, http.StatusBadRequest, "dummy_error", "Dummy error")
@@ -1449,6 +1560,9 @@ func (h *Handler) DummyHandler(w http.ResponseWriter, r *http.Request) {
 // @Description - update_etcd_raft_options: Update etcd raft options for the orderer
 // @Description - update_batch_size: Update batch size for the orderer
 // @Description - update_batch_timeout: Update batch timeout for the orderer
+// @Description - add_bft_consenter: Add a new consenter to a BFT orderer
+// @Description - remove_bft_consenter: Remove a consenter from a BFT orderer
+// @Description - update_smartbft_options: Update SmartBFT options for a BFT orderer
 // @Tags Fabric Networks
 // @Accept json
 // @Produce json
@@ -1480,148 +1594,176 @@ func (h *Handler) FabricUpdateChannelConfig(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Validate each operation's payload
+	if err := h.validateConfigUpdateOperations(req.Operations); err != nil {
+		writeError(w, http.StatusBadRequest, err.code, err.message)
+		return
+	}
+
+	// Convert operations to fabric.ConfigUpdateOperation
+	operations := make([]fabric.ConfigUpdateOperation, len(req.Operations))
 	for i, op := range req.Operations {
+		operations[i] = fabric.ConfigUpdateOperation{
+			Type:    fabric.ConfigUpdateOperationType(op.Type),
+			Payload: op.Payload,
+		}
+	}
+
+	// Call service to prepare config update
+	proposal, err := h.networkService.UpdateFabricNetwork(r.Context(), networkID, operations)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "prepare_config_update_failed", err.Error())
+		return
+	}
+
+	// Create response
+	resp := ConfigUpdateResponse{
+		ID:          proposal.ID,
+		NetworkID:   proposal.NetworkID,
+		ChannelName: proposal.ChannelName,
+		Status:      proposal.Status,
+		CreatedAt:   proposal.CreatedAt,
+		CreatedBy:   proposal.CreatedBy,
+		Operations:  req.Operations,
+	}
+
+	// Return response
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// operationValidationError describes why a config update operation was rejected
+type operationValidationError struct {
+	code    string
+	message string
+}
+
+// validateConfigUpdateOperations validates the payload of each config update operation
+func (h *Handler) validateConfigUpdateOperations(operations []ConfigUpdateOperationRequest) *operationValidationError {
+	for i, op := range operations {
 		switch op.Type {
 		case "add_org":
 			var payload AddOrgPayload
 			if err := json.Unmarshal(op.Payload, &payload); err != nil {
-				writeError(w, http.StatusBadRequest, "invalid_payload", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			if err := h.validate.Struct(payload); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 		case "remove_org":
 			var payload RemoveOrgPayload
 			if err := json.Unmarshal(op.Payload, &payload); err != nil {
-				writeError(w, http.StatusBadRequest, "invalid_payload", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			if err := h.validate.Struct(payload); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 		case "update_org_msp":
 			var payload UpdateOrgMSPPayload
 			if err := json.Unmarshal(op.Payload, &payload); err != nil {
-				writeError(w, http.StatusBadRequest, "invalid_payload", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			if err := h.validate.Struct(payload); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 		case "set_anchor_peers":
 			var payload SetAnchorPeersPayload
 			if err := json.Unmarshal(op.Payload, &payload); err != nil {
-				writeError(w, http.StatusBadRequest, "invalid_payload", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			if err := h.validate.Struct(payload); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 		case "add_consenter":
 			var payload AddConsenterPayload
 			if err := json.Unmarshal(op.Payload, &payload); err != nil {
-				writeError(w, http.StatusBadRequest, "invalid_payload", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			if err := h.validate.Struct(payload); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 		case "remove_consenter":
 			var payload RemoveConsenterPayload
 			if err := json.Unmarshal(op.Payload, &payload); err != nil {
-				writeError(w, http.StatusBadRequest, "invalid_payload", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			if err := h.validate.Struct(payload); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 		case "update_consenter":
 			var payload UpdateConsenterPayload
 			if err := json.Unmarshal(op.Payload, &payload); err != nil {
-				writeError(w, http.StatusBadRequest, "invalid_payload", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			if err := h.validate.Struct(payload); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 		case "update_etcd_raft_options":
 			var payload UpdateEtcdRaftOptionsPayload
 			if err := json.Unmarshal(op.Payload, &payload); err != nil {
-				writeError(w, http.StatusBadRequest, "invalid_payload", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			if err := h.validate.Struct(payload); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 		case "update_batch_size":
 			var payload UpdateBatchSizePayload
 			if err := json.Unmarshal(op.Payload, &payload); err != nil {
-				writeError(w, http.StatusBadRequest, "invalid_payload", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			if err := h.validate.Struct(payload); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 		case "update_batch_timeout":
 			var payload UpdateBatchTimeoutPayload
 			if err := json.Unmarshal(op.Payload, &payload); err != nil {
-				writeError(w, http.StatusBadRequest, "invalid_payload", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			if err := h.validate.Struct(payload); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
 			}
 			// Validate that the timeout is a valid duration
 			if _, err := time.ParseDuration(payload.Timeout); err != nil {
-				writeError(w, http.StatusBadRequest, "validation_error", fmt.Sprintf("Invalid timeout for operation %d: %s", i, err.Error()))
-				return
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid timeout for operation %d: %s", i, err.Error())}
+			}
+		case "add_bft_consenter":
+			var payload AddBFTConsenterPayload
+			if err := json.Unmarshal(op.Payload, &payload); err != nil {
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
+			}
+			if err := h.validate.Struct(payload); err != nil {
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
+			}
+		case "remove_bft_consenter":
+			var payload RemoveBFTConsenterPayload
+			if err := json.Unmarshal(op.Payload, &payload); err != nil {
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
+			}
+			if err := h.validate.Struct(payload); err != nil {
+				return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
+			}
+		case "update_smartbft_options":
+			var payload UpdateSmartBFTOptionsPayload
+			if err := json.Unmarshal(op.Payload, &payload); err != nil {
+				return &operationValidationError{code: "invalid_payload", message: fmt.Sprintf("Invalid payload for operation %d: %s", i, err.Error())}
+			}
+			// Validate that the timeouts are valid durations
+			for _, value := range []string{
+				payload.RequestBatchMaxInterval, payload.RequestForwardTimeout, payload.RequestComplainTimeout,
+				payload.RequestAutoRemoveTimeout, payload.ViewChangeResendInterval, payload.ViewChangeTimeout,
+				payload.LeaderHeartbeatTimeout, payload.CollectTimeout,
+			} {
+				if value == "" {
+					continue
+				}
+				if _, err := time.ParseDuration(value); err != nil {
+					return &operationValidationError{code: "validation_error", message: fmt.Sprintf("Invalid duration for operation %d: %s", i, err.Error())}
+				}
 			}
 		default:
-			writeError(w, http.StatusBadRequest, "invalid_operation_type", fmt.Sprintf("Unsupported operation type: %s", op.Type))
-			return
+			return &operationValidationError{code: "invalid_operation_type", message: fmt.Sprintf("Unsupported operation type: %s", op.Type)}
 		}
 	}
 
-	// Convert operations to fabric.ConfigUpdateOperation
-	operations := make([]fabric.ConfigUpdateOperation, len(req.Operations))
-	for i, op := range req.Operations {
-		operations[i] = fabric.ConfigUpdateOperation{
-			Type:    fabric.ConfigUpdateOperationType(op.Type),
-			Payload: op.Payload,
-		}
-	}
-
-	// Call service to prepare config update
-	proposal, err := h.networkService.UpdateFabricNetwork(r.Context(), networkID, operations)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "prepare_config_update_failed", err.Error())
-		return
-	}
-
-	// Create response
-	resp := ConfigUpdateResponse{
-		ID:          proposal.ID,
-		NetworkID:   proposal.NetworkID,
-		ChannelName: proposal.ChannelName,
-		Status:      proposal.Status,
-		CreatedAt:   proposal.CreatedAt,
-		CreatedBy:   proposal.CreatedBy,
-		Operations:  req.Operations,
-	}
-
-	// Return response
-	writeJSON(w, http.StatusOK, resp)
+	return nil
 }
 
 // ConfigUpdateResponse represents the response from preparing a config update
@@ -0,0 +1,450 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/fabric"
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateProposalRequest represents a request to create a config update proposal
+type CreateProposalRequest struct {
+	Operations []ConfigUpdateOperationRequest `json:"operations" validate:"required,min=1,dive"`
+}
+
+// SignProposalRequest represents a request to sign a proposal with a local organization
+type SignProposalRequest struct {
+	MSPID string `json:"msp_id" validate:"required"`
+}
+
+// AddProposalSignatureRequest represents a signature created on another instance
+type AddProposalSignatureRequest struct {
+	// Signature is the marshaled common.ConfigSignature, base64 encoded
+	Signature []byte `json:"signature" validate:"required"`
+	SignedBy  string `json:"signed_by"`
+}
+
+// SubmitProposalRequest represents a request to submit a proposal to the orderer
+type SubmitProposalRequest struct {
+	// SubmitterMSPID is the local organization that signs the envelope. Defaults to
+	// the first local organization that signed the proposal.
+	SubmitterMSPID string `json:"submitter_msp_id"`
+}
+
+// ProposalSignatureResponse represents a signature collected for a proposal
+type ProposalSignatureResponse struct {
+	MSPID    string    `json:"msp_id"`
+	SignedBy string    `json:"signed_by"`
+	SignedAt time.Time `json:"signed_at"`
+}
+
+// ProposalResponse represents a config update proposal
+type ProposalResponse struct {
+	ID          string                         `json:"id"`
+	NetworkID   int64                          `json:"network_id"`
+	ChannelName string                         `json:"channel_name"`
+	Status      string                         `json:"status"`
+	CreatedAt   time.Time                      `json:"created_at"`
+	CreatedBy   string                         `json:"created_by"`
+	UpdatedAt   *time.Time                     `json:"updated_at,omitempty"`
+	Operations  []ConfigUpdateOperationRequest `json:"operations"`
+	PreviewJSON string                         `json:"preview_json,omitempty"`
+	TxResponse  string                         `json:"tx_response,omitempty"`
+	Signatures  []ProposalSignatureResponse    `json:"signatures"`
+	Policies    []fabric.ModPolicyEvaluation   `json:"policies,omitempty"`
+	Ready       bool                           `json:"ready"`
+}
+
+// ListProposalsResponse represents a list of proposals
+type ListProposalsResponse struct {
+	Proposals []ProposalResponse `json:"proposals"`
+}
+
+// @Summary List config update proposals
+// @Description List the config update proposals of a Fabric network, newest first
+// @Tags Fabric Networks
+// @Produce json
+// @Param id path int true "Network ID"
+// @Success 200 {object} ListProposalsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/fabric/{id}/proposals [get]
+func (h *Handler) FabricListProposals(w http.ResponseWriter, r *http.Request) {
+	networkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_network_id", "Invalid network ID")
+		return
+	}
+
+	proposals, err := h.networkService.ListFabricProposals(r.Context(), networkID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "list_proposals_failed", err.Error())
+		return
+	}
+
+	resp := ListProposalsResponse{
+		Proposals: make([]ProposalResponse, len(proposals)),
+	}
+	for i, proposal := range proposals {
+		resp.Proposals[i] = toProposalResponse(proposal)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// @Summary Create a config update proposal
+// @Description Compute a channel config update from the given operations and store it as a proposal.
+// @Description Nothing is signed or submitted: organizations sign the proposal, on this or other instances,
+// @Description and it is submitted once the channel modification policy is satisfied.
+// @Tags Fabric Networks
+// @Accept json
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param request body CreateProposalRequest true "Config update operations"
+// @Success 201 {object} ProposalResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/fabric/{id}/proposals [post]
+func (h *Handler) FabricCreateProposal(w http.ResponseWriter, r *http.Request) {
+	networkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_network_id", "Invalid network ID")
+		return
+	}
+
+	var req CreateProposalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	if err := h.validateConfigUpdateOperations(req.Operations); err != nil {
+		writeError(w, http.StatusBadRequest, err.code, err.message)
+		return
+	}
+
+	operations := make([]fabric.ConfigUpdateOperation, len(req.Operations))
+	for i, op := range req.Operations {
+		operations[i] = fabric.ConfigUpdateOperation{
+			Type:    fabric.ConfigUpdateOperationType(op.Type),
+			Payload: op.Payload,
+		}
+	}
+
+	proposal, err := h.networkService.CreateFabricProposal(r.Context(), networkID, operations, currentUsername(r))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "create_proposal_failed", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, toProposalResponse(proposal))
+}
+
+// @Summary Import a config update proposal
+// @Description Import a proposal exported by another instance. Signatures of an already known proposal are merged.
+// @Tags Fabric Networks
+// @Accept json
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param request body service.ProposalExport true "Exported proposal"
+// @Success 200 {object} ProposalResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /networks/fabric/{id}/proposals/import [post]
+func (h *Handler) FabricImportProposal(w http.ResponseWriter, r *http.Request) {
+	networkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_network_id", "Invalid network ID")
+		return
+	}
+
+	var req service.ProposalExport
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	proposal, err := h.networkService.ImportFabricProposal(r.Context(), networkID, &req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "import_proposal_failed", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toProposalResponse(proposal))
+}
+
+// @Summary Get a config update proposal
+// @Description Get a proposal with its signatures and the modification policies it has to satisfy
+// @Tags Fabric Networks
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param proposalId path string true "Proposal ID"
+// @Success 200 {object} ProposalResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/fabric/{id}/proposals/{proposalId} [get]
+func (h *Handler) FabricGetProposal(w http.ResponseWriter, r *http.Request) {
+	proposal, ok := h.getNetworkProposal(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, toProposalResponse(proposal))
+}
+
+// @Summary Export a config update proposal
+// @Description Export the unsigned config update and the signatures collected so far, to be imported on another instance
+// @Tags Fabric Networks
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param proposalId path string true "Proposal ID"
+// @Success 200 {object} service.ProposalExport
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/fabric/{id}/proposals/{proposalId}/export [get]
+func (h *Handler) FabricExportProposal(w http.ResponseWriter, r *http.Request) {
+	proposal, ok := h.getNetworkProposal(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := h.networkService.ExportFabricProposal(r.Context(), proposal.ID)
+	if err != nil {
+		writeProposalError(w, "export_proposal_failed", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, export)
+}
+
+// @Summary Sign a config update proposal
+// @Description Sign a proposal with the admin identity of an organization managed by this instance
+// @Tags Fabric Networks
+// @Accept json
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param proposalId path string true "Proposal ID"
+// @Param request body SignProposalRequest true "Signing organization"
+// @Success 200 {object} ProposalResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/fabric/{id}/proposals/{proposalId}/sign [post]
+func (h *Handler) FabricSignProposal(w http.ResponseWriter, r *http.Request) {
+	proposal, ok := h.getNetworkProposal(w, r)
+	if !ok {
+		return
+	}
+
+	var req SignProposalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	// Signing acts as the organization, so it requires a role on the organization rather than the network
+	if err := auth.AuthorizeOrganizationMSP(r.Context(), req.MSPID, auth.RoleManager); err != nil {
+		if errors.Is(err, auth.ErrForbidden) {
+			writeError(w, http.StatusForbidden, "forbidden", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "authorization_failed", err.Error())
+		return
+	}
+
+	proposal, err := h.networkService.SignFabricProposal(r.Context(), proposal.ID, req.MSPID, currentUsername(r))
+	if err != nil {
+		writeProposalError(w, "sign_proposal_failed", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toProposalResponse(proposal))
+}
+
+// @Summary Add a signature to a config update proposal
+// @Description Attach a config signature created by an organization on another instance
+// @Tags Fabric Networks
+// @Accept json
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param proposalId path string true "Proposal ID"
+// @Param request body AddProposalSignatureRequest true "Config signature"
+// @Success 200 {object} ProposalResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /networks/fabric/{id}/proposals/{proposalId}/signatures [post]
+func (h *Handler) FabricAddProposalSignature(w http.ResponseWriter, r *http.Request) {
+	proposal, ok := h.getNetworkProposal(w, r)
+	if !ok {
+		return
+	}
+
+	var req AddProposalSignatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+	if err := h.validate.Struct(req); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+
+	proposal, err := h.networkService.AddFabricProposalSignature(r.Context(), proposal.ID, req.Signature, req.SignedBy)
+	if err != nil {
+		if errors.Is(err, service.ErrProposalNotPending) {
+			writeProposalError(w, "add_signature_failed", err)
+			return
+		}
+		writeError(w, http.StatusBadRequest, "add_signature_failed", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, toProposalResponse(proposal))
+}
+
+// @Summary Submit a config update proposal
+// @Description Submit a proposal to the ordering service once every modification policy is satisfied
+// @Tags Fabric Networks
+// @Accept json
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param proposalId path string true "Proposal ID"
+// @Param request body SubmitProposalRequest false "Submitting organization"
+// @Success 200 {object} ProposalResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/fabric/{id}/proposals/{proposalId}/submit [post]
+func (h *Handler) FabricSubmitProposal(w http.ResponseWriter, r *http.Request) {
+	proposal, ok := h.getNetworkProposal(w, r)
+	if !ok {
+		return
+	}
+
+	var req SubmitProposalRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+			return
+		}
+	}
+
+	proposal, err := h.networkService.SubmitFabricProposal(r.Context(), proposal.ID, req.SubmitterMSPID)
+	if err != nil {
+		writeProposalError(w, "submit_proposal_failed", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toProposalResponse(proposal))
+}
+
+// @Summary Cancel a config update proposal
+// @Description Cancel a pending proposal so it can no longer be signed or submitted
+// @Tags Fabric Networks
+// @Produce json
+// @Param id path int true "Network ID"
+// @Param proposalId path string true "Proposal ID"
+// @Success 200 {object} ProposalResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /networks/fabric/{id}/proposals/{proposalId}/cancel [post]
+func (h *Handler) FabricCancelProposal(w http.ResponseWriter, r *http.Request) {
+	proposal, ok := h.getNetworkProposal(w, r)
+	if !ok {
+		return
+	}
+
+	proposal, err := h.networkService.CancelFabricProposal(r.Context(), proposal.ID)
+	if err != nil {
+		writeProposalError(w, "cancel_proposal_failed", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toProposalResponse(proposal))
+}
+
+// getNetworkProposal loads the proposal referenced by the request and checks it
+// belongs to the network in the path
+func (h *Handler) getNetworkProposal(w http.ResponseWriter, r *http.Request) (*service.Proposal, bool) {
+	networkID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_network_id", "Invalid network ID")
+		return nil, false
+	}
+
+	proposal, err := h.networkService.GetFabricProposal(r.Context(), chi.URLParam(r, "proposalId"))
+	if err != nil {
+		writeProposalError(w, "get_proposal_failed", err)
+		return nil, false
+	}
+	if proposal.NetworkID != networkID {
+		writeError(w, http.StatusNotFound, "proposal_not_found", service.ErrProposalNotFound.Error())
+		return nil, false
+	}
+	return proposal, true
+}
+
+func writeProposalError(w http.ResponseWriter, code string, err error) {
+	switch {
+	case errors.Is(err, service.ErrProposalNotFound):
+		writeError(w, http.StatusNotFound, "proposal_not_found", err.Error())
+	case errors.Is(err, service.ErrProposalNotPending):
+		writeError(w, http.StatusConflict, "proposal_not_pending", err.Error())
+	case errors.Is(err, service.ErrProposalNotReady):
+		writeError(w, http.StatusConflict, "proposal_not_ready", err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, code, err.Error())
+	}
+}
+
+func currentUsername(r *http.Request) string {
+	if user, ok := auth.UserFromContext(r.Context()); ok {
+		return user.Username
+	}
+	return ""
+}
+
+func toProposalResponse(proposal *service.Proposal) ProposalResponse {
+	resp := ProposalResponse{
+		ID:          proposal.ID,
+		NetworkID:   proposal.NetworkID,
+		ChannelName: proposal.ChannelName,
+		Status:      proposal.Status,
+		CreatedAt:   proposal.CreatedAt,
+		CreatedBy:   proposal.CreatedBy,
+		UpdatedAt:   proposal.UpdatedAt,
+		Operations:  make([]ConfigUpdateOperationRequest, len(proposal.Operations)),
+		PreviewJSON: proposal.PreviewJSON,
+		TxResponse:  proposal.TxResponse,
+		Signatures:  make([]ProposalSignatureResponse, len(proposal.Signatures)),
+		Policies:    proposal.Policies,
+		Ready:       proposal.Ready,
+	}
+	for i, op := range proposal.Operations {
+		resp.Operations[i] = ConfigUpdateOperationRequest{
+			Type:    op.Type,
+			Payload: op.Payload,
+		}
+	}
+	for i, sig := range proposal.Signatures {
+		resp.Signatures[i] = ProposalSignatureResponse{
+			MSPID:    sig.MSPID,
+			SignedBy: sig.SignedBy,
+			SignedAt: sig.SignedAt,
+		}
+	}
+	return resp
+}
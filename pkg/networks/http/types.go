@@ -5,6 +5,7 @@ import (
 
 	networksservice "github.com/chainlaunch/chainlaunch/pkg/networks/service"
 	"github.com/chainlaunch/chainlaunch/pkg/networks/service/fabric/block"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
 )
 
 // ListNetworksResponse represents the response for listing networks
@@ -59,6 +60,9 @@ type FabricNetworkConfig struct {
 	OrdererOrganizations []OrganizationConfig `json:"ordererOrganizations"`
 	ExternalPeerOrgs     []ExternalOrgConfig  `json:"externalPeerOrgs,omitempty"`
 	ExternalOrdererOrgs  []ExternalOrgConfig  `json:"externalOrdererOrgs,omitempty"`
+	// Consensus of the channel, etcdraft when empty. BFT requires Fabric 3.x orderers.
+	ConsensusType   string                 `json:"consensusType,omitempty" validate:"omitempty,oneof=etcdraft BFT"`
+	SmartBFTOptions *types.SmartBFTOptions `json:"smartBftOptions,omitempty"`
 }
 
 // OrganizationConfig represents an organization in the network
@@ -19,8 +19,13 @@ func (s *NetworkService) importBesuNetwork(ctx context.Context, params ImportNet
 		return nil, fmt.Errorf("invalid deployer type")
 	}
 
+	var chainID int64
+	if params.ChainID != nil {
+		chainID = *params.ChainID
+	}
+
 	// Import the network using the Besu deployer
-	networkID, err := besuDeployer.ImportNetwork(ctx, params.GenesisFile, params.Name, params.Description)
+	networkID, err := besuDeployer.ImportNetwork(ctx, params.GenesisFile, params.Name, params.Description, chainID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to import Besu network: %w", err)
 	}
@@ -0,0 +1,164 @@
+package besu
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	EXTRA_VANITY_LENGTH = 32
+	// Length of the signature appended to the Clique extraData
+	CLIQUE_SEAL_LENGTH = 65
+
+	// Mix hash identifying IBFT 2.0 and QBFT blocks
+	bftMixHash  = "0x63746963616c2062797a616e74696e65206661756c7420746f6c6572616e6365"
+	zeroMixHash = "0x0000000000000000000000000000000000000000000000000000000000000000"
+)
+
+// consensusConfig returns the chain configuration section of the given consensus
+func consensusConfig(config *types.BesuNetworkConfig) (Config, error) {
+	chainConfig := Config{
+		ChainID:     config.ChainID,
+		BerlinBlock: 0,
+	}
+	switch config.Consensus {
+	case types.BesuConsensusTypeQBFT:
+		chainConfig.QBFT = &QBFTConfig{
+			BlockPeriodSeconds:    config.BlockPeriod,
+			EpochLength:           config.EpochLength,
+			RequestTimeoutSeconds: config.RequestTimeout,
+			StartBlock:            0,
+		}
+	case types.BesuConsensusTypeIBFT2:
+		chainConfig.IBFT2 = &IBFT2Config{
+			BlockPeriodSeconds:    config.BlockPeriod,
+			EpochLength:           config.EpochLength,
+			RequestTimeoutSeconds: config.RequestTimeout,
+		}
+	case types.BesuConsensusTypeClique:
+		chainConfig.Clique = &CliqueConfig{
+			BlockPeriodSeconds: config.BlockPeriod,
+			EpochLength:        config.EpochLength,
+			CreateEmptyBlocks:  true,
+		}
+	default:
+		return Config{}, fmt.Errorf("unsupported consensus: %s", config.Consensus)
+	}
+	return chainConfig, nil
+}
+
+// defaultMixHash returns the mix hash expected in the genesis block of the given consensus
+func defaultMixHash(consensus types.BesuConsensusType) string {
+	if consensus == types.BesuConsensusTypeClique {
+		return zeroMixHash
+	}
+	return bftMixHash
+}
+
+// createExtraData creates the extraData field of the genesis block, which holds
+// the initial validators in the encoding of the given consensus
+func (d *BesuDeployer) createExtraData(consensus types.BesuConsensusType, validators []BesuNode) (string, error) {
+	// Convert validator addresses from hex strings to Address type
+	validatorAddresses := make([]common.Address, len(validators))
+	for i, validator := range validators {
+		validatorAddresses[i] = common.HexToAddress(validator.Address)
+	}
+	d.logger.Info("Creating extra data", "consensus", consensus, "validators", validatorAddresses)
+
+	var rlpList []interface{}
+	switch consensus {
+	case types.BesuConsensusTypeQBFT:
+		rlpList = []interface{}{
+			make([]byte, EXTRA_VANITY_LENGTH), // 32 bytes of zeros
+			validatorAddresses,                // List of validators
+			[]interface{}{},                   // Empty vote list
+			uint(0),                           // Round number (0 for genesis)
+			[]interface{}{},                   // Empty seals list
+		}
+	case types.BesuConsensusTypeIBFT2:
+		rlpList = []interface{}{
+			make([]byte, EXTRA_VANITY_LENGTH), // 32 bytes of zeros
+			validatorAddresses,                // List of validators
+			[]byte{},                          // No vote
+			make([]byte, 4),                   // Round number as 4 bytes (0 for genesis)
+			[]interface{}{},                   // Empty seals list
+		}
+	case types.BesuConsensusTypeClique:
+		// Clique doesn't use RLP: vanity, signer addresses and an empty seal are concatenated
+		extraData := make([]byte, 0, EXTRA_VANITY_LENGTH+len(validatorAddresses)*common.AddressLength+CLIQUE_SEAL_LENGTH)
+		extraData = append(extraData, make([]byte, EXTRA_VANITY_LENGTH)...)
+		for _, address := range validatorAddresses {
+			extraData = append(extraData, address.Bytes()...)
+		}
+		extraData = append(extraData, make([]byte, CLIQUE_SEAL_LENGTH)...)
+		return "0x" + hex.EncodeToString(extraData), nil
+	default:
+		return "", fmt.Errorf("unsupported consensus: %s", consensus)
+	}
+
+	// RLP encode the entire structure
+	extraData, err := rlp.EncodeToBytes(rlpList)
+	if err != nil {
+		return "", fmt.Errorf("failed to RLP encode extra data: %v", err)
+	}
+
+	return "0x" + hex.EncodeToString(extraData), nil
+}
+
+// detectConsensus returns the consensus configured in a genesis chain config
+func detectConsensus(config Config) (types.BesuConsensusType, error) {
+	switch {
+	case config.QBFT != nil:
+		return types.BesuConsensusTypeQBFT, nil
+	case config.IBFT2 != nil:
+		return types.BesuConsensusTypeIBFT2, nil
+	case config.Clique != nil:
+		return types.BesuConsensusTypeClique, nil
+	default:
+		return "", fmt.Errorf("genesis file doesn't configure a supported consensus (qbft, ibft2 or clique)")
+	}
+}
+
+// parseExtraData returns the validators encoded in the extraData of a genesis block
+func parseExtraData(consensus types.BesuConsensusType, extraData string) ([]string, error) {
+	data, err := hex.DecodeString(strings.TrimPrefix(extraData, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid extra data: %w", err)
+	}
+
+	var addresses []common.Address
+	switch consensus {
+	case types.BesuConsensusTypeQBFT, types.BesuConsensusTypeIBFT2:
+		var fields []rlp.RawValue
+		if err := rlp.DecodeBytes(data, &fields); err != nil {
+			return nil, fmt.Errorf("failed to RLP decode extra data: %w", err)
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid extra data: expected at least 2 fields, got %d", len(fields))
+		}
+		if err := rlp.DecodeBytes(fields[1], &addresses); err != nil {
+			return nil, fmt.Errorf("failed to decode validators from extra data: %w", err)
+		}
+	case types.BesuConsensusTypeClique:
+		signers := len(data) - EXTRA_VANITY_LENGTH - CLIQUE_SEAL_LENGTH
+		if signers < 0 || signers%common.AddressLength != 0 {
+			return nil, fmt.Errorf("invalid clique extra data length: %d", len(data))
+		}
+		for i := EXTRA_VANITY_LENGTH; i < EXTRA_VANITY_LENGTH+signers; i += common.AddressLength {
+			addresses = append(addresses, common.BytesToAddress(data[i:i+common.AddressLength]))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported consensus: %s", consensus)
+	}
+
+	validators := make([]string, len(addresses))
+	for i, address := range addresses {
+		validators[i] = strings.ToLower(address.Hex())
+	}
+	return validators, nil
+}
@@ -0,0 +1,128 @@
+package besu
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
+)
+
+var testValidators = []BesuNode{
+	{Address: "0x00000000000000000000000000000000000000aa"},
+	{Address: "0x00000000000000000000000000000000000000BB"},
+}
+
+func TestExtraDataRoundTrip(t *testing.T) {
+	d := newTestDeployer()
+	for _, consensus := range []types.BesuConsensusType{
+		types.BesuConsensusTypeQBFT,
+		types.BesuConsensusTypeIBFT2,
+		types.BesuConsensusTypeClique,
+	} {
+		extraData, err := d.createExtraData(consensus, testValidators)
+		if err != nil {
+			t.Fatalf("%s: failed to create extra data: %v", consensus, err)
+		}
+		validators, err := parseExtraData(consensus, extraData)
+		if err != nil {
+			t.Fatalf("%s: failed to parse extra data: %v", consensus, err)
+		}
+		expected := "0x00000000000000000000000000000000000000aa,0x00000000000000000000000000000000000000bb"
+		if strings.Join(validators, ",") != expected {
+			t.Errorf("%s: unexpected validators %v", consensus, validators)
+		}
+	}
+}
+
+func TestCliqueExtraDataLayout(t *testing.T) {
+	extraData, err := newTestDeployer().createExtraData(types.BesuConsensusTypeClique, testValidators)
+	if err != nil {
+		t.Fatalf("failed to create extra data: %v", err)
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(extraData, "0x"))
+	if err != nil {
+		t.Fatalf("extra data is not hex: %v", err)
+	}
+	// 32 bytes vanity, the signers and an empty 65 byte seal
+	if len(data) != EXTRA_VANITY_LENGTH+2*20+CLIQUE_SEAL_LENGTH {
+		t.Errorf("unexpected extra data length %d", len(data))
+	}
+	if data[EXTRA_VANITY_LENGTH+19] != 0xaa || data[EXTRA_VANITY_LENGTH+39] != 0xbb {
+		t.Error("signers are not stored after the vanity")
+	}
+}
+
+func TestParseExtraDataErrors(t *testing.T) {
+	qbft, err := newTestDeployer().createExtraData(types.BesuConsensusTypeQBFT, testValidators)
+	if err != nil {
+		t.Fatalf("failed to create extra data: %v", err)
+	}
+	cases := []struct {
+		name      string
+		consensus types.BesuConsensusType
+		extraData string
+	}{
+		{"not hex", types.BesuConsensusTypeQBFT, "0xzz"},
+		{"not rlp", types.BesuConsensusTypeIBFT2, "0x" + strings.Repeat("ff", 8)},
+		{"rlp without validators", types.BesuConsensusTypeQBFT, "0xc180"},
+		{"clique too short", types.BesuConsensusTypeClique, "0x" + strings.Repeat("00", EXTRA_VANITY_LENGTH)},
+		{"clique partial address", types.BesuConsensusTypeClique, "0x" + strings.Repeat("00", EXTRA_VANITY_LENGTH+10+CLIQUE_SEAL_LENGTH)},
+		{"bft data read as clique", types.BesuConsensusTypeClique, qbft},
+		{"unsupported consensus", "ethash", qbft},
+	}
+	for _, c := range cases {
+		if _, err := parseExtraData(c.consensus, c.extraData); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+
+	if _, err := newTestDeployer().createExtraData("ethash", testValidators); err == nil {
+		t.Error("expected an error creating extra data for an unsupported consensus")
+	}
+}
+
+func TestConsensusConfig(t *testing.T) {
+	network := &types.BesuNetworkConfig{ChainID: 1337, BlockPeriod: 5, EpochLength: 30000, RequestTimeout: 10}
+
+	cases := []struct {
+		consensus types.BesuConsensusType
+		check     func(Config) bool
+		mixHash   string
+	}{
+		{types.BesuConsensusTypeQBFT, func(c Config) bool {
+			return c.QBFT != nil && c.QBFT.BlockPeriodSeconds == 5 && c.QBFT.RequestTimeoutSeconds == 10 && c.IBFT2 == nil && c.Clique == nil
+		}, bftMixHash},
+		{types.BesuConsensusTypeIBFT2, func(c Config) bool {
+			return c.IBFT2 != nil && c.IBFT2.EpochLength == 30000 && c.QBFT == nil && c.Clique == nil
+		}, bftMixHash},
+		{types.BesuConsensusTypeClique, func(c Config) bool {
+			return c.Clique != nil && c.Clique.BlockPeriodSeconds == 5 && c.Clique.CreateEmptyBlocks && c.QBFT == nil && c.IBFT2 == nil
+		}, zeroMixHash},
+	}
+	for _, c := range cases {
+		network.Consensus = c.consensus
+		config, err := consensusConfig(network)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.consensus, err)
+			continue
+		}
+		if config.ChainID != 1337 || !c.check(config) {
+			t.Errorf("%s: unexpected config %+v", c.consensus, config)
+		}
+		if detected, err := detectConsensus(config); err != nil || detected != c.consensus {
+			t.Errorf("%s: detected %s (%v)", c.consensus, detected, err)
+		}
+		if mixHash := defaultMixHash(c.consensus); mixHash != c.mixHash {
+			t.Errorf("%s: unexpected mix hash %s", c.consensus, mixHash)
+		}
+	}
+
+	network.Consensus = "ethash"
+	if _, err := consensusConfig(network); err == nil {
+		t.Error("expected an error for an unsupported consensus")
+	}
+	if _, err := detectConsensus(Config{ChainID: 1337}); err == nil {
+		t.Error("expected an error for a genesis without consensus")
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"strings"
 
-	"encoding/base64"
 	"encoding/hex"
 
 	"github.com/chainlaunch/chainlaunch/pkg/db"
@@ -16,8 +15,6 @@ import (
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
 	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
 	nodeservice "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/google/uuid"
 )
 
@@ -92,8 +89,8 @@ func (d *BesuDeployer) CreateGenesisBlock(networkID int64, config interface{}) (
 		validators = append(validators, besuNode)
 	}
 
-	// Create extraData for QBFT
-	extraData, err := d.createExtraData(validators)
+	// Create extraData with the initial validators
+	extraData, err := d.createExtraData(besuConfig.Consensus, validators)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create extra data: %w", err)
 	}
@@ -120,23 +117,24 @@ func (d *BesuDeployer) CreateGenesisBlock(networkID int64, config interface{}) (
 		}
 	}
 
+	chainConfig, err := consensusConfig(besuConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	mixHash := besuConfig.MixHash
+	if mixHash == "" {
+		mixHash = defaultMixHash(besuConfig.Consensus)
+	}
+
 	// Create genesis parameters
 	genesis := &GenesisParams{
-		Config: Config{
-			ChainID:     besuConfig.ChainID,
-			BerlinBlock: 0,
-			QBFT: QBFTConfig{
-				BlockPeriodSeconds:    besuConfig.BlockPeriod,
-				EpochLength:           besuConfig.EpochLength,
-				RequestTimeoutSeconds: besuConfig.RequestTimeout,
-				StartBlock:            0,
-			},
-		},
+		Config:     chainConfig,
 		Nonce:      besuConfig.Nonce,
 		Timestamp:  besuConfig.Timestamp,
 		GasLimit:   besuConfig.GasLimit,
 		Difficulty: besuConfig.Difficulty,
-		MixHash:    besuConfig.MixHash,
+		MixHash:    mixHash,
 		Coinbase:   besuConfig.Coinbase,
 		Alloc:      alloc,
 		ExtraData:  extraData,
@@ -183,47 +181,6 @@ func (d *BesuDeployer) GetCurrentConfigBlock(ctx context.Context, networkID int6
 	return nil, fmt.Errorf("operation not supported for Besu networks")
 }
 
-// createExtraData creates the extraData field for QBFT consensus
-// func (d *BesuDeployer) createExtraData(validators []BesuNode) (string, error) {
-// 	// Implement QBFT extra data creation logic
-// 	// This should include the validator addresses in the required format
-// 	// The format depends on your specific Besu implementation
-// 	validatorAddresses := make([]string, len(validators))
-// 	for i, validator := range validators {
-// 		validatorAddresses[i] = validator.Address
-// 	}
-
-//		// This is a simplified example - adjust according to your needs
-//		extraData := "0x" + strings.Join(validatorAddresses, "")
-//		return extraData, nil
-//	}
-const EXTRA_VANITY_LENGTH = 32
-
-func (d *BesuDeployer) createExtraData(validators []BesuNode) (string, error) {
-	// Convert validator addresses from hex strings to Address type
-	validatorAddresses := make([]common.Address, len(validators))
-	for i, validator := range validators {
-		validatorAddresses[i] = common.HexToAddress(validator.Address)
-	}
-	d.logger.Info("validatorAddresses: %v", validatorAddresses)
-	// First, RLP encode the main components
-	rlpList := []interface{}{
-		make([]byte, EXTRA_VANITY_LENGTH), // 32 bytes of zeros
-		validatorAddresses,                // List of validators
-		[]interface{}{},                   // Empty vote list
-		uint(0),                           // Round number (0 for genesis)
-		[]interface{}{},                   // Empty seals list
-	}
-
-	// RLP encode the entire structure
-	extraData, err := rlp.EncodeToBytes(rlpList)
-	if err != nil {
-		return "", fmt.Errorf("failed to RLP encode extra data: %v", err)
-	}
-
-	return "0x" + hex.EncodeToString(extraData), nil
-}
-
 // GetStatus retrieves the current status of the Besu network
 func (d *BesuDeployer) GetStatus(networkID int64) (*types.NetworkDeploymentStatus, error) {
 	// ctx := context.Background()
@@ -274,40 +231,81 @@ func (d *BesuDeployer) decodePEMPublicKey(hexStr string) (string, error) {
 	return hexStr, nil
 }
 
-// ImportNetwork imports a Besu network from a genesis file
-func (d *BesuDeployer) ImportNetwork(ctx context.Context, genesisFile []byte, name, description string) (string, error) {
+// ImportNetwork imports a Besu network from a genesis file. The consensus and the
+// initial validators are read from the genesis file so that nodes can join the
+// network. If chainID is not zero, it must match the chain ID of the genesis file.
+func (d *BesuDeployer) ImportNetwork(ctx context.Context, genesisFile []byte, name, description string, chainID int64) (string, error) {
 	// Parse the genesis file
-	var genesisConfig map[string]interface{}
-	if err := json.Unmarshal(genesisFile, &genesisConfig); err != nil {
+	var genesis struct {
+		Config    *Config `json:"config"`
+		ExtraData string  `json:"extraData"`
+	}
+	if err := json.Unmarshal(genesisFile, &genesis); err != nil {
 		return "", fmt.Errorf("failed to parse Besu genesis file: %w", err)
 	}
 
 	// Validate required fields
-	if _, ok := genesisConfig["config"]; !ok {
+	if genesis.Config == nil {
 		return "", fmt.Errorf("invalid Besu genesis file: missing config section")
 	}
+	if genesis.Config.ChainID == 0 {
+		return "", fmt.Errorf("invalid Besu genesis file: missing chainId in config section")
+	}
+	if chainID != 0 && chainID != genesis.Config.ChainID {
+		return "", fmt.Errorf("chain ID %d doesn't match the chain ID of the genesis file (%d)", chainID, genesis.Config.ChainID)
+	}
 
-	// Validate chainId exists
-	config, ok := genesisConfig["config"].(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("invalid Besu genesis file: config section is not an object")
+	consensus, err := detectConsensus(*genesis.Config)
+	if err != nil {
+		return "", fmt.Errorf("invalid Besu genesis file: %w", err)
 	}
-	if _, ok := config["chainId"]; !ok {
-		return "", fmt.Errorf("invalid Besu genesis file: missing chainId in config section")
+	validators, err := parseExtraData(consensus, genesis.ExtraData)
+	if err != nil {
+		return "", fmt.Errorf("invalid Besu genesis file: %w", err)
 	}
 
+	networkConfig := &types.BesuNetworkConfig{
+		BaseNetworkConfig: types.BaseNetworkConfig{
+			Type: types.NetworkTypeBesu,
+		},
+		ChainID:    genesis.Config.ChainID,
+		Consensus:  consensus,
+		Validators: validators,
+	}
+	switch consensus {
+	case types.BesuConsensusTypeQBFT:
+		networkConfig.BlockPeriod = genesis.Config.QBFT.BlockPeriodSeconds
+		networkConfig.EpochLength = genesis.Config.QBFT.EpochLength
+		networkConfig.RequestTimeout = genesis.Config.QBFT.RequestTimeoutSeconds
+	case types.BesuConsensusTypeIBFT2:
+		networkConfig.BlockPeriod = genesis.Config.IBFT2.BlockPeriodSeconds
+		networkConfig.EpochLength = genesis.Config.IBFT2.EpochLength
+		networkConfig.RequestTimeout = genesis.Config.IBFT2.RequestTimeoutSeconds
+	case types.BesuConsensusTypeClique:
+		networkConfig.BlockPeriod = genesis.Config.Clique.BlockPeriodSeconds
+		networkConfig.EpochLength = genesis.Config.Clique.EpochLength
+	}
+	configJSON, err := json.Marshal(networkConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal network config: %w", err)
+	}
+
+	d.logger.Info("Importing Besu network", "name", name, "chainID", genesis.Config.ChainID, "consensus", consensus, "validators", len(validators))
+
 	// Generate a unique network ID
 	networkID := uuid.New().String()
 
-	// Create network in database
-	_, err := d.db.CreateNetworkFull(ctx, &db.CreateNetworkFullParams{
+	// Create network in database. The genesis file is stored as is, like the
+	// genesis files created by CreateGenesisBlock, since nodes write it to disk.
+	_, err = d.db.CreateNetworkFull(ctx, &db.CreateNetworkFullParams{
 		Name:        name,
 		Platform:    "besu",
 		Description: sql.NullString{String: description, Valid: description != ""},
 		Status:      "genesis_block_created",
+		Config:      sql.NullString{String: string(configJSON), Valid: true},
 		NetworkID:   sql.NullString{String: networkID, Valid: true},
 		GenesisBlockB64: sql.NullString{
-			String: base64.StdEncoding.EncodeToString(genesisFile),
+			String: string(genesisFile),
 			Valid:  true,
 		},
 	})
@@ -0,0 +1,161 @@
+package besu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
+)
+
+const rpcTimeout = 10 * time.Second
+
+// RPCClient is a minimal JSON-RPC client for the consensus APIs of a Besu node
+type RPCClient struct {
+	url        string
+	consensus  types.BesuConsensusType
+	httpClient *http.Client
+}
+
+// NewRPCClient creates a client for the JSON-RPC endpoint of a Besu node running
+// the given consensus
+func NewRPCClient(url string, consensus types.BesuConsensusType) *RPCClient {
+	return &RPCClient{
+		url:        url,
+		consensus:  consensus,
+		httpClient: &http.Client{Timeout: rpcTimeout},
+	}
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      int           `json:"id"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call invokes a JSON-RPC method and decodes its result into result
+func (c *RPCClient) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", Method: method, Params: params, ID: 1})
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON-RPC request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var rpcResp rpcResponse
+	if err := json.Unmarshal(respBody, &rpcResp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("RPC error: %s (code: %d)", rpcResp.Error.Message, rpcResp.Error.Code)
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+// validatorMethods returns the JSON-RPC methods used to get the validators, propose
+// a vote and discard a vote for the consensus of the node
+func (c *RPCClient) validatorMethods() (getValidators, propose, discard string, err error) {
+	switch c.consensus {
+	case types.BesuConsensusTypeQBFT, "":
+		return "qbft_getValidatorsByBlockNumber", "qbft_proposeValidatorVote", "qbft_discardValidatorVote", nil
+	case types.BesuConsensusTypeIBFT2:
+		return "ibft_getValidatorsByBlockNumber", "ibft_proposeValidatorVote", "ibft_discardValidatorVote", nil
+	case types.BesuConsensusTypeClique:
+		return "clique_getSigners", "clique_propose", "clique_discard", nil
+	default:
+		return "", "", "", fmt.Errorf("unsupported consensus: %s", c.consensus)
+	}
+}
+
+// GetValidators returns the validators (signers for Clique) at the latest block
+func (c *RPCClient) GetValidators(ctx context.Context) ([]string, error) {
+	method, _, _, err := c.validatorMethods()
+	if err != nil {
+		return nil, err
+	}
+	var validators []string
+	if err := c.call(ctx, method, &validators, "latest"); err != nil {
+		return nil, err
+	}
+	return validators, nil
+}
+
+// ProposeValidatorVote makes the node vote to add (true) or remove (false) a
+// validator in the blocks it proposes
+func (c *RPCClient) ProposeValidatorVote(ctx context.Context, address string, add bool) error {
+	_, method, _, err := c.validatorMethods()
+	if err != nil {
+		return err
+	}
+	var ok bool
+	if err := c.call(ctx, method, &ok, address, add); err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("node rejected the validator vote")
+	}
+	return nil
+}
+
+// DiscardValidatorVote withdraws the pending vote of the node for a validator
+func (c *RPCClient) DiscardValidatorVote(ctx context.Context, address string) error {
+	_, _, method, err := c.validatorMethods()
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, method, nil, address)
+}
+
+// BlockNumber returns the number of the latest block
+func (c *RPCClient) BlockNumber(ctx context.Context) (uint64, error) {
+	var hexNumber string
+	if err := c.call(ctx, "eth_blockNumber", &hexNumber); err != nil {
+		return 0, err
+	}
+	var number uint64
+	if _, err := fmt.Sscanf(hexNumber, "0x%x", &number); err != nil {
+		return 0, fmt.Errorf("invalid block number %q: %w", hexNumber, err)
+	}
+	return number, nil
+}
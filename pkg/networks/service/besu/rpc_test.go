@@ -0,0 +1,206 @@
+package besu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
+)
+
+// fakeBesuNode answers JSON-RPC calls from a table of results per method
+type fakeBesuNode struct {
+	mu      sync.Mutex
+	results map[string]interface{}
+	errors  map[string]string
+	calls   []rpcRequest
+}
+
+func newFakeBesuNode(t *testing.T, results map[string]interface{}) (*fakeBesuNode, *httptest.Server) {
+	node := &fakeBesuNode{results: results, errors: map[string]string{}}
+	server := httptest.NewServer(node)
+	t.Cleanup(server.Close)
+	return node, server
+}
+
+func (f *fakeBesuNode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, req)
+
+	if message, ok := f.errors[req.Method]; ok {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0", "id": req.ID,
+			"error": map[string]interface{}{"code": -32000, "message": message},
+		})
+		return
+	}
+	result, ok := f.results[req.Method]
+	if !ok {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0", "id": req.ID,
+			"error": map[string]interface{}{"code": -32601, "message": "Method not enabled"},
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func (f *fakeBesuNode) methods() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var methods []string
+	for _, call := range f.calls {
+		methods = append(methods, call.Method)
+	}
+	return methods
+}
+
+func (f *fakeBesuNode) lastParams() []interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.calls) == 0 {
+		return nil
+	}
+	return f.calls[len(f.calls)-1].Params
+}
+
+func newTestClient(url string) *RPCClient {
+	return NewRPCClient(url, types.BesuConsensusTypeQBFT)
+}
+
+func TestRPCClientValidatorVotes(t *testing.T) {
+	ctx := context.Background()
+	node, server := newFakeBesuNode(t, map[string]interface{}{
+		"qbft_getValidatorsByBlockNumber": []string{"0xaaaa", "0xbbbb"},
+		"qbft_proposeValidatorVote":       true,
+		"qbft_discardValidatorVote":       true,
+		"eth_blockNumber":                 "0x1a",
+	})
+	client := newTestClient(server.URL)
+
+	validators, err := client.GetValidators(ctx)
+	if err != nil {
+		t.Fatalf("failed to get validators: %v", err)
+	}
+	if strings.Join(validators, ",") != "0xaaaa,0xbbbb" {
+		t.Errorf("unexpected validators %v", validators)
+	}
+	if params := node.lastParams(); len(params) != 1 || params[0] != "latest" {
+		t.Errorf("unexpected params %v", params)
+	}
+
+	if err := client.ProposeValidatorVote(ctx, "0xcccc", false); err != nil {
+		t.Fatalf("failed to propose vote: %v", err)
+	}
+	if params := node.lastParams(); len(params) != 2 || params[0] != "0xcccc" || params[1] != false {
+		t.Errorf("unexpected params %v", params)
+	}
+	if err := client.DiscardValidatorVote(ctx, "0xcccc"); err != nil {
+		t.Fatalf("failed to discard vote: %v", err)
+	}
+
+	number, err := client.BlockNumber(ctx)
+	if err != nil || number != 26 {
+		t.Errorf("expected block 26, got %d (%v)", number, err)
+	}
+}
+
+func TestRPCClientErrors(t *testing.T) {
+	ctx := context.Background()
+	node, server := newFakeBesuNode(t, map[string]interface{}{
+		"qbft_proposeValidatorVote": false,
+		"eth_blockNumber":           "latest",
+	})
+	node.errors["qbft_discardValidatorVote"] = "Invalid params"
+	client := newTestClient(server.URL)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+	garbage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<html>"))
+	}))
+	defer garbage.Close()
+
+	cases := map[string]struct {
+		call     func() error
+		contains string
+	}{
+		"method not enabled": {func() error { _, err := client.GetValidators(ctx); return err }, "Method not enabled"},
+		"vote rejected":      {func() error { return client.ProposeValidatorVote(ctx, "0xcccc", true) }, "rejected"},
+		"rpc error":          {func() error { return client.DiscardValidatorVote(ctx, "0xcccc") }, "Invalid params (code: -32000)"},
+		"bad block number":   {func() error { _, err := client.BlockNumber(ctx); return err }, "invalid block number"},
+		"http status": {func() error {
+			_, err := newTestClient(failing.URL).GetValidators(ctx)
+			return err
+		}, "status 401"},
+		"not json": {func() error {
+			_, err := newTestClient(garbage.URL).GetValidators(ctx)
+			return err
+		}, "failed to parse response"},
+	}
+	for name, c := range cases {
+		err := c.call()
+		if err == nil || !strings.Contains(err.Error(), c.contains) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, c.contains, err)
+		}
+	}
+}
+
+func TestRPCClientConsensusMethods(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		consensus types.BesuConsensusType
+		methods   []string
+	}{
+		{types.BesuConsensusTypeQBFT, []string{"qbft_getValidatorsByBlockNumber", "qbft_proposeValidatorVote", "qbft_discardValidatorVote"}},
+		{types.BesuConsensusTypeIBFT2, []string{"ibft_getValidatorsByBlockNumber", "ibft_proposeValidatorVote", "ibft_discardValidatorVote"}},
+		{types.BesuConsensusTypeClique, []string{"clique_getSigners", "clique_propose", "clique_discard"}},
+		// Networks created before the consensus was stored are QBFT
+		{"", []string{"qbft_getValidatorsByBlockNumber", "qbft_proposeValidatorVote", "qbft_discardValidatorVote"}},
+	}
+	for _, c := range cases {
+		results := map[string]interface{}{}
+		for _, method := range c.methods {
+			results[method] = true
+		}
+		results[c.methods[0]] = []string{"0xaaaa"}
+		node, server := newFakeBesuNode(t, results)
+		client := NewRPCClient(server.URL, c.consensus)
+
+		if _, err := client.GetValidators(ctx); err != nil {
+			t.Errorf("%s: failed to get validators: %v", c.consensus, err)
+		}
+		if err := client.ProposeValidatorVote(ctx, "0xbbbb", true); err != nil {
+			t.Errorf("%s: failed to propose vote: %v", c.consensus, err)
+		}
+		if err := client.DiscardValidatorVote(ctx, "0xbbbb"); err != nil {
+			t.Errorf("%s: failed to discard vote: %v", c.consensus, err)
+		}
+		if got := strings.Join(node.methods(), ","); got != strings.Join(c.methods, ",") {
+			t.Errorf("%s: expected methods %v, got %s", c.consensus, c.methods, got)
+		}
+	}
+
+	client := NewRPCClient("http://127.0.0.1:1", "ethash")
+	if _, err := client.GetValidators(ctx); err == nil {
+		t.Error("expected an error for an unsupported consensus")
+	}
+	if err := client.ProposeValidatorVote(ctx, "0xbbbb", true); err == nil {
+		t.Error("expected an error for an unsupported consensus")
+	}
+	if err := client.DiscardValidatorVote(ctx, "0xbbbb"); err == nil {
+		t.Error("expected an error for an unsupported consensus")
+	}
+}
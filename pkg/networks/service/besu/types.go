@@ -51,11 +51,28 @@ type QBFTConfig struct {
 	StartBlock            int64 `json:"startBlock"`
 }
 
-// Config represents the chain configuration
+// IBFT2Config represents the IBFT 2.0 consensus configuration
+type IBFT2Config struct {
+	BlockPeriodSeconds    int `json:"blockperiodseconds"`
+	EpochLength           int `json:"epochlength"`
+	RequestTimeoutSeconds int `json:"requesttimeoutseconds"`
+}
+
+// CliqueConfig represents the Clique consensus configuration
+type CliqueConfig struct {
+	BlockPeriodSeconds int  `json:"blockperiodseconds"`
+	EpochLength        int  `json:"epochlength"`
+	CreateEmptyBlocks  bool `json:"createemptyblocks"`
+}
+
+// Config represents the chain configuration. Only the section of the consensus
+// used by the network is set.
 type Config struct {
-	ChainID     int64      `json:"chainId"`
-	BerlinBlock int        `json:"berlinBlock"`
-	QBFT        QBFTConfig `json:"qbft"`
+	ChainID     int64         `json:"chainId"`
+	BerlinBlock int           `json:"berlinBlock"`
+	QBFT        *QBFTConfig   `json:"qbft,omitempty"`
+	IBFT2       *IBFT2Config  `json:"ibft2,omitempty"`
+	Clique      *CliqueConfig `json:"clique,omitempty"`
 }
 
 // NetworkConfig represents the configuration for a Besu network
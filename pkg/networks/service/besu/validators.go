@@ -0,0 +1,148 @@
+package besu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
+	nodetypes "github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+)
+
+// NetworkNode is a Besu node managed by ChainLaunch that belongs to a network
+type NetworkNode struct {
+	NodeID    int64                   `json:"nodeId"`
+	NodeName  string                  `json:"nodeName"`
+	Status    string                  `json:"status"`
+	KeyID     int64                   `json:"keyId"`
+	Address   string                  `json:"address"`
+	RPCURL    string                  `json:"rpcUrl"`
+	Consensus types.BesuConsensusType `json:"consensus"`
+}
+
+// ValidatorVote is the vote a node cast for a validator set change
+type ValidatorVote struct {
+	NodeID   int64     `json:"nodeId"`
+	NodeName string    `json:"nodeName"`
+	Address  string    `json:"address"`
+	VotedAt  time.Time `json:"votedAt"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// ListNetworkNodes returns the local Besu nodes of a network
+func (d *BesuDeployer) ListNetworkNodes(ctx context.Context, networkID int64) ([]NetworkNode, error) {
+	network, err := d.db.GetNetwork(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network: %w", err)
+	}
+	var networkConfig types.BesuNetworkConfig
+	if err := json.Unmarshal([]byte(network.Config.String), &networkConfig); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal network config: %w", err)
+	}
+
+	platform := nodetypes.PlatformBesu
+	var networkNodes []NetworkNode
+	for page := 1; ; page++ {
+		nodes, err := d.nodes.ListNodes(ctx, &platform, page, 100)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list nodes: %w", err)
+		}
+		for _, node := range nodes.Items {
+			if node.BesuNode == nil || node.BesuNode.NetworkID != networkID {
+				continue
+			}
+			key, err := d.keyMgmt.GetKey(ctx, int(node.BesuNode.KeyID))
+			if err != nil {
+				return nil, fmt.Errorf("failed to get key of node %s: %w", node.Name, err)
+			}
+			rpcHost := node.BesuNode.RPCHost
+			if rpcHost == "" || rpcHost == "0.0.0.0" {
+				rpcHost = "127.0.0.1"
+			}
+			networkNodes = append(networkNodes, NetworkNode{
+				NodeID:    node.ID,
+				NodeName:  node.Name,
+				Status:    node.Status,
+				KeyID:     node.BesuNode.KeyID,
+				Address:   key.EthereumAddress,
+				RPCURL:    fmt.Sprintf("http://%s:%d", rpcHost, node.BesuNode.RPCPort),
+				Consensus: networkConfig.Consensus,
+			})
+		}
+		if !nodes.HasNextPage {
+			break
+		}
+	}
+	return networkNodes, nil
+}
+
+// GetValidators returns the current validator set, as reported by the first node
+// that answers
+func (d *BesuDeployer) GetValidators(ctx context.Context, nodes []NetworkNode) ([]string, error) {
+	var lastErr error
+	for _, node := range nodes {
+		if node.Status != string(nodetypes.NodeStatusRunning) {
+			continue
+		}
+		validators, err := NewRPCClient(node.RPCURL, node.Consensus).GetValidators(ctx)
+		if err != nil {
+			d.logger.Warn("Failed to get validators from node", "node", node.NodeName, "error", err)
+			lastErr = err
+			continue
+		}
+		return validators, nil
+	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("no node returned the validator set: %w", lastErr)
+	}
+	return nil, fmt.Errorf("no running node in the network")
+}
+
+// ProposeValidatorVote makes every node that is currently a validator vote for
+// adding or removing a validator. The change is applied by the network once more
+// than half of the validators voted for it.
+func (d *BesuDeployer) ProposeValidatorVote(ctx context.Context, nodes []NetworkNode, validators []string, address string, add bool) []ValidatorVote {
+	var votes []ValidatorVote
+	for _, node := range nodes {
+		if node.Status != string(nodetypes.NodeStatusRunning) || !ContainsAddress(validators, node.Address) {
+			continue
+		}
+		vote := ValidatorVote{
+			NodeID:   node.NodeID,
+			NodeName: node.NodeName,
+			Address:  node.Address,
+			VotedAt:  time.Now(),
+		}
+		if err := NewRPCClient(node.RPCURL, node.Consensus).ProposeValidatorVote(ctx, address, add); err != nil {
+			d.logger.Warn("Failed to propose validator vote", "node", node.NodeName, "validator", address, "error", err)
+			vote.Error = err.Error()
+		}
+		votes = append(votes, vote)
+	}
+	return votes
+}
+
+// DiscardValidatorVote withdraws the votes of the given nodes for a validator, so
+// they stop voting once the change is applied or cancelled
+func (d *BesuDeployer) DiscardValidatorVote(ctx context.Context, nodes []NetworkNode, address string) {
+	for _, node := range nodes {
+		if node.Status != string(nodetypes.NodeStatusRunning) {
+			continue
+		}
+		if err := NewRPCClient(node.RPCURL, node.Consensus).DiscardValidatorVote(ctx, address); err != nil {
+			d.logger.Warn("Failed to discard validator vote", "node", node.NodeName, "validator", address, "error", err)
+		}
+	}
+}
+
+// ContainsAddress reports whether addresses contains address, ignoring case
+func ContainsAddress(addresses []string, address string) bool {
+	for _, a := range addresses {
+		if strings.EqualFold(a, address) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package besu
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	nodetypes "github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+)
+
+func newTestDeployer() *BesuDeployer {
+	return &BesuDeployer{logger: logger.NewDefault()}
+}
+
+func newTestNetworkNode(id int64, address, url string, status nodetypes.NodeStatus) NetworkNode {
+	return NetworkNode{
+		NodeID:   id,
+		NodeName: "besu-" + address,
+		Status:   string(status),
+		Address:  address,
+		RPCURL:   url,
+	}
+}
+
+func TestGetValidators(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDeployer()
+	_, healthy := newFakeBesuNode(t, map[string]interface{}{
+		"qbft_getValidatorsByBlockNumber": []string{"0xaaaa", "0xbbbb"},
+	})
+	_, broken := newFakeBesuNode(t, map[string]interface{}{})
+	stopped := httptest.NewServer(nil)
+	stopped.Close()
+
+	cases := []struct {
+		name    string
+		nodes   []NetworkNode
+		wantErr string
+	}{
+		{"first running node answers", []NetworkNode{
+			newTestNetworkNode(1, "0xaaaa", stopped.URL, nodetypes.NodeStatusStopped),
+			newTestNetworkNode(2, "0xbbbb", healthy.URL, nodetypes.NodeStatusRunning),
+		}, ""},
+		{"falls back to the next node", []NetworkNode{
+			newTestNetworkNode(1, "0xaaaa", broken.URL, nodetypes.NodeStatusRunning),
+			newTestNetworkNode(2, "0xbbbb", healthy.URL, nodetypes.NodeStatusRunning),
+		}, ""},
+		{"no node answers", []NetworkNode{
+			newTestNetworkNode(1, "0xaaaa", broken.URL, nodetypes.NodeStatusRunning),
+			newTestNetworkNode(2, "0xbbbb", stopped.URL, nodetypes.NodeStatusRunning),
+		}, "no node returned the validator set"},
+		{"no running node", []NetworkNode{
+			newTestNetworkNode(1, "0xaaaa", healthy.URL, nodetypes.NodeStatusStopped),
+		}, "no running node"},
+		{"no nodes", nil, "no running node"},
+	}
+	for _, c := range cases {
+		validators, err := d.GetValidators(ctx, c.nodes)
+		if c.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("%s: expected an error containing %q, got %v", c.name, c.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if strings.Join(validators, ",") != "0xaaaa,0xbbbb" {
+			t.Errorf("%s: unexpected validators %v", c.name, validators)
+		}
+	}
+}
+
+func TestProposeValidatorVote(t *testing.T) {
+	ctx := context.Background()
+	d := newTestDeployer()
+	validator, validatorServer := newFakeBesuNode(t, map[string]interface{}{"qbft_proposeValidatorVote": true})
+	rejecting, rejectingServer := newFakeBesuNode(t, map[string]interface{}{"qbft_proposeValidatorVote": false})
+	observer, observerServer := newFakeBesuNode(t, map[string]interface{}{"qbft_proposeValidatorVote": true})
+	stopped, stoppedServer := newFakeBesuNode(t, map[string]interface{}{"qbft_proposeValidatorVote": true})
+
+	nodes := []NetworkNode{
+		newTestNetworkNode(1, "0xAAAA", validatorServer.URL, nodetypes.NodeStatusRunning),
+		newTestNetworkNode(2, "0xbbbb", rejectingServer.URL, nodetypes.NodeStatusRunning),
+		newTestNetworkNode(3, "0xcccc", observerServer.URL, nodetypes.NodeStatusRunning),
+		newTestNetworkNode(4, "0xdddd", stoppedServer.URL, nodetypes.NodeStatusStopped),
+	}
+	// Addresses are compared without case
+	votes := d.ProposeValidatorVote(ctx, nodes, []string{"0xaaaa", "0xbbbb", "0xdddd"}, "0xeeee", true)
+
+	if len(votes) != 2 {
+		t.Fatalf("expected votes from the 2 running validators, got %+v", votes)
+	}
+	if votes[0].NodeID != 1 || votes[0].Error != "" {
+		t.Errorf("unexpected vote %+v", votes[0])
+	}
+	if votes[1].NodeID != 2 || !strings.Contains(votes[1].Error, "rejected") {
+		t.Errorf("expected the rejected vote to carry the error, got %+v", votes[1])
+	}
+	if len(validator.methods()) != 1 || len(rejecting.methods()) != 1 {
+		t.Error("expected one vote per validator")
+	}
+	if len(observer.methods()) != 0 || len(stopped.methods()) != 0 {
+		t.Error("non validators and stopped nodes must not vote")
+	}
+}
+
+func TestDiscardValidatorVote(t *testing.T) {
+	d := newTestDeployer()
+	running, runningServer := newFakeBesuNode(t, map[string]interface{}{"qbft_discardValidatorVote": true})
+	failing, failingServer := newFakeBesuNode(t, map[string]interface{}{})
+	stopped, stoppedServer := newFakeBesuNode(t, map[string]interface{}{"qbft_discardValidatorVote": true})
+
+	d.DiscardValidatorVote(context.Background(), []NetworkNode{
+		newTestNetworkNode(1, "0xaaaa", failingServer.URL, nodetypes.NodeStatusRunning),
+		newTestNetworkNode(2, "0xbbbb", runningServer.URL, nodetypes.NodeStatusRunning),
+		newTestNetworkNode(3, "0xcccc", stoppedServer.URL, nodetypes.NodeStatusStopped),
+	}, "0xeeee")
+
+	// A failing node doesn't stop the others from discarding their vote
+	if len(failing.methods()) != 1 || len(running.methods()) != 1 {
+		t.Error("expected every running node to discard its vote")
+	}
+	if len(stopped.methods()) != 0 {
+		t.Error("stopped nodes must not be called")
+	}
+}
+
+func TestContainsAddress(t *testing.T) {
+	addresses := []string{"0xAbCd000000000000000000000000000000000001", "0x0000000000000000000000000000000000000002"}
+	cases := map[string]bool{
+		"0xabcd000000000000000000000000000000000001": true,
+		"0xABCD000000000000000000000000000000000001": true,
+		"0x0000000000000000000000000000000000000002": true,
+		"0x0000000000000000000000000000000000000003": false,
+		"": false,
+	}
+	for address, expected := range cases {
+		if got := ContainsAddress(addresses, address); got != expected {
+			t.Errorf("%q: expected %v, got %v", address, expected, got)
+		}
+	}
+	if ContainsAddress(nil, "0xabcd") {
+		t.Error("expected an empty list not to contain an address")
+	}
+}
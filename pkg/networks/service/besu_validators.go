@@ -0,0 +1,561 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/besu"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Validator change actions
+const (
+	BesuValidatorActionAdd    = "add"
+	BesuValidatorActionRemove = "remove"
+)
+
+// Validator change statuses
+const (
+	BesuValidatorChangeStatusPending   = "pending"
+	BesuValidatorChangeStatusApplied   = "applied"
+	BesuValidatorChangeStatusFailed    = "failed"
+	BesuValidatorChangeStatusCancelled = "cancelled"
+)
+
+const (
+	// besuValidatorChangeTimeout is how long the votes for a change are kept before
+	// it is marked as failed
+	besuValidatorChangeTimeout = 30 * time.Minute
+	// besuValidatorPollInterval is how often pending changes are checked against the
+	// validator set of the network
+	besuValidatorPollInterval = 5 * time.Second
+)
+
+var (
+	// ErrBesuValidatorChangeNotFound is returned when a validator change does not exist
+	ErrBesuValidatorChangeNotFound = errors.New("validator change not found")
+	// ErrBesuValidatorChangeNotPending is returned when cancelling a change that is no longer pending
+	ErrBesuValidatorChangeNotPending = errors.New("validator change is not pending")
+	// ErrInvalidBesuValidatorChange is returned when a requested validator change can't be voted
+	ErrInvalidBesuValidatorChange = errors.New("invalid validator change")
+)
+
+// BesuValidatorChangeRequest describes a validator to add or remove. The validator
+// is identified by the key it signs blocks with or by its address.
+type BesuValidatorChangeRequest struct {
+	Action  string
+	KeyID   int64
+	Address string
+}
+
+// BesuValidatorChange is a validator set change voted on a Besu network
+type BesuValidatorChange struct {
+	ID            int64                `json:"id"`
+	NetworkID     int64                `json:"networkId"`
+	Action        string               `json:"action"`
+	Address       string               `json:"address"`
+	KeyID         *int64               `json:"keyId,omitempty"`
+	Status        string               `json:"status"`
+	Votes         []besu.ValidatorVote `json:"votes"`
+	VotesRequired int                  `json:"votesRequired"`
+	Error         string               `json:"error,omitempty"`
+	CreatedBy     string               `json:"createdBy,omitempty"`
+	CreatedAt     time.Time            `json:"createdAt"`
+	UpdatedAt     time.Time            `json:"updatedAt"`
+	AppliedAt     *time.Time           `json:"appliedAt,omitempty"`
+}
+
+// BesuValidator is a member of the validator set of a Besu network
+type BesuValidator struct {
+	Address  string `json:"address"`
+	NodeID   *int64 `json:"nodeId,omitempty"`
+	NodeName string `json:"nodeName,omitempty"`
+	KeyID    *int64 `json:"keyId,omitempty"`
+}
+
+// BesuValidatorSet is the current validator set of a Besu network with the
+// changes that are still being voted
+type BesuValidatorSet struct {
+	Validators     []BesuValidator        `json:"validators"`
+	PendingChanges []*BesuValidatorChange `json:"pendingChanges"`
+}
+
+// GetBesuValidators returns the current validator set of a Besu network
+func (s *NetworkService) GetBesuValidators(ctx context.Context, networkID int64) (*BesuValidatorSet, error) {
+	besuDeployer, err := s.getBesuDeployerForNetwork(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.refreshBesuValidatorChanges(ctx, besuDeployer, networkID); err != nil {
+		s.logger.Warn("Failed to refresh validator changes", "networkID", networkID, "error", err)
+	}
+
+	nodes, err := besuDeployer.ListNetworkNodes(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+	addresses, err := besuDeployer.GetValidators(ctx, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validators: %w", err)
+	}
+
+	set := &BesuValidatorSet{
+		Validators:     make([]BesuValidator, 0, len(addresses)),
+		PendingChanges: []*BesuValidatorChange{},
+	}
+	for _, address := range addresses {
+		validator := BesuValidator{Address: address}
+		for _, node := range nodes {
+			if strings.EqualFold(node.Address, address) {
+				nodeID, keyID := node.NodeID, node.KeyID
+				validator.NodeID = &nodeID
+				validator.NodeName = node.NodeName
+				validator.KeyID = &keyID
+				break
+			}
+		}
+		set.Validators = append(set.Validators, validator)
+	}
+
+	pending, err := s.db.ListPendingBesuValidatorChanges(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending validator changes: %w", err)
+	}
+	for _, change := range pending {
+		set.PendingChanges = append(set.PendingChanges, mapBesuValidatorChange(change))
+	}
+	return set, nil
+}
+
+// ProposeBesuValidatorChanges makes the local validators of a network vote for
+// adding or removing validators. The changes are tracked in the background until
+// they show up in the validator set or time out.
+func (s *NetworkService) ProposeBesuValidatorChanges(ctx context.Context, networkID int64, requests []BesuValidatorChangeRequest, createdBy string) ([]*BesuValidatorChange, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("%w: no validator changes requested", ErrInvalidBesuValidatorChange)
+	}
+
+	besuDeployer, err := s.getBesuDeployerForNetwork(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := besuDeployer.ListNetworkNodes(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+	validators, err := besuDeployer.GetValidators(ctx, nodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validators: %w", err)
+	}
+
+	pending, err := s.db.ListPendingBesuValidatorChanges(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending validator changes: %w", err)
+	}
+
+	// Resolve and validate every request before casting any vote
+	type resolvedChange struct {
+		action  string
+		address string
+		keyID   sql.NullInt64
+	}
+	resolved := make([]resolvedChange, 0, len(requests))
+	removals := 0
+	for _, req := range requests {
+		address, keyID, err := s.resolveBesuValidatorAddress(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		switch req.Action {
+		case BesuValidatorActionAdd:
+			if besu.ContainsAddress(validators, address) {
+				return nil, fmt.Errorf("%w: %s is already a validator", ErrInvalidBesuValidatorChange, address)
+			}
+		case BesuValidatorActionRemove:
+			if !besu.ContainsAddress(validators, address) {
+				return nil, fmt.Errorf("%w: %s is not a validator", ErrInvalidBesuValidatorChange, address)
+			}
+			removals++
+		default:
+			return nil, fmt.Errorf("%w: unknown action %q", ErrInvalidBesuValidatorChange, req.Action)
+		}
+
+		for _, change := range pending {
+			if strings.EqualFold(change.Address, address) {
+				return nil, fmt.Errorf("%w: validator change %d for %s is still pending", ErrInvalidBesuValidatorChange, change.ID, address)
+			}
+		}
+		for _, change := range resolved {
+			if change.address == address {
+				return nil, fmt.Errorf("%w: %s is requested more than once", ErrInvalidBesuValidatorChange, address)
+			}
+		}
+		resolved = append(resolved, resolvedChange{action: req.Action, address: address, keyID: keyID})
+	}
+	if removals >= len(validators) {
+		return nil, fmt.Errorf("%w: a network needs at least one validator", ErrInvalidBesuValidatorChange)
+	}
+
+	hasLocalValidator := false
+	for _, node := range nodes {
+		if besu.ContainsAddress(validators, node.Address) {
+			hasLocalValidator = true
+			break
+		}
+	}
+	if !hasLocalValidator {
+		return nil, fmt.Errorf("%w: none of the current validators is managed by this instance", ErrInvalidBesuValidatorChange)
+	}
+
+	// More than half of the validators have to vote for a change
+	votesRequired := len(validators)/2 + 1
+
+	changes := make([]*BesuValidatorChange, 0, len(resolved))
+	for _, change := range resolved {
+		dbChange, err := s.db.CreateBesuValidatorChange(ctx, &db.CreateBesuValidatorChangeParams{
+			NetworkID:     networkID,
+			Action:        change.action,
+			Address:       change.address,
+			KeyID:         change.keyID,
+			VotesRequired: int64(votesRequired),
+			CreatedBy:     sql.NullString{String: createdBy, Valid: createdBy != ""},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create validator change: %w", err)
+		}
+
+		votes := besuDeployer.ProposeValidatorVote(ctx, nodes, validators, change.address, change.action == BesuValidatorActionAdd)
+		votesJSON, err := json.Marshal(votes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal votes: %w", err)
+		}
+		dbChange, err = s.db.UpdateBesuValidatorChangeVotes(ctx, &db.UpdateBesuValidatorChangeVotesParams{
+			Votes: sql.NullString{String: string(votesJSON), Valid: true},
+			ID:    dbChange.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store votes: %w", err)
+		}
+
+		if countBesuVotes(votes) == 0 {
+			dbChange, err = s.setBesuValidatorChangeStatus(ctx, dbChange.ID, BesuValidatorChangeStatusFailed, "no validator accepted the vote", nil)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		s.logger.Info("Proposed validator change", "networkID", networkID, "action", change.action, "validator", change.address, "votes", countBesuVotes(votes), "votesRequired", votesRequired)
+		changes = append(changes, mapBesuValidatorChange(dbChange))
+	}
+
+	s.trackBesuValidatorChanges(networkID)
+	return changes, nil
+}
+
+// ListBesuValidatorChanges returns the validator changes of a network, newest first
+func (s *NetworkService) ListBesuValidatorChanges(ctx context.Context, networkID int64) ([]*BesuValidatorChange, error) {
+	if _, err := s.getBesuDeployerForNetwork(ctx, networkID); err != nil {
+		return nil, err
+	}
+
+	dbChanges, err := s.db.ListBesuValidatorChangesByNetwork(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list validator changes: %w", err)
+	}
+
+	changes := make([]*BesuValidatorChange, 0, len(dbChanges))
+	for _, change := range dbChanges {
+		changes = append(changes, mapBesuValidatorChange(change))
+	}
+	return changes, nil
+}
+
+// GetBesuValidatorChange returns a validator change, checking the validator set
+// first when it is still pending
+func (s *NetworkService) GetBesuValidatorChange(ctx context.Context, networkID, changeID int64) (*BesuValidatorChange, error) {
+	dbChange, err := s.getBesuValidatorChange(ctx, networkID, changeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dbChange.Status == BesuValidatorChangeStatusPending {
+		besuDeployer, err := s.getBesuDeployerForNetwork(ctx, networkID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.refreshBesuValidatorChanges(ctx, besuDeployer, networkID); err != nil {
+			s.logger.Warn("Failed to refresh validator changes", "networkID", networkID, "error", err)
+		}
+		if dbChange, err = s.getBesuValidatorChange(ctx, networkID, changeID); err != nil {
+			return nil, err
+		}
+	}
+
+	return mapBesuValidatorChange(dbChange), nil
+}
+
+// CancelBesuValidatorChange withdraws the votes of a pending validator change
+func (s *NetworkService) CancelBesuValidatorChange(ctx context.Context, networkID, changeID int64) (*BesuValidatorChange, error) {
+	dbChange, err := s.getBesuValidatorChange(ctx, networkID, changeID)
+	if err != nil {
+		return nil, err
+	}
+	if dbChange.Status != BesuValidatorChangeStatusPending {
+		return nil, ErrBesuValidatorChangeNotPending
+	}
+
+	besuDeployer, err := s.getBesuDeployerForNetwork(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := besuDeployer.ListNetworkNodes(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+	besuDeployer.DiscardValidatorVote(ctx, nodes, dbChange.Address)
+
+	dbChange, err = s.setBesuValidatorChangeStatus(ctx, changeID, BesuValidatorChangeStatusCancelled, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return mapBesuValidatorChange(dbChange), nil
+}
+
+// trackBesuValidatorChanges polls the validator set of a network until none of its
+// changes is pending anymore. Only one tracker runs per network.
+func (s *NetworkService) trackBesuValidatorChanges(networkID int64) {
+	if _, running := s.besuValidatorTrackers.LoadOrStore(networkID, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer s.besuValidatorTrackers.Delete(networkID)
+
+		ticker := time.NewTicker(besuValidatorPollInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			remaining, err := s.refreshPendingBesuValidatorChanges(ctx, networkID)
+			cancel()
+			if err != nil {
+				s.logger.Warn("Failed to refresh validator changes", "networkID", networkID, "error", err)
+			}
+			if remaining == 0 {
+				return
+			}
+		}
+	}()
+}
+
+func (s *NetworkService) refreshPendingBesuValidatorChanges(ctx context.Context, networkID int64) (int, error) {
+	besuDeployer, err := s.getBesuDeployerForNetwork(ctx, networkID)
+	if err != nil {
+		return 0, err
+	}
+	return s.refreshBesuValidatorChanges(ctx, besuDeployer, networkID)
+}
+
+// refreshBesuValidatorChanges marks the pending changes of a network that show up in
+// its validator set as applied, and the ones that waited too long as failed. It
+// returns the number of changes still pending.
+func (s *NetworkService) refreshBesuValidatorChanges(ctx context.Context, besuDeployer *besu.BesuDeployer, networkID int64) (int, error) {
+	pending, err := s.db.ListPendingBesuValidatorChanges(ctx, networkID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list pending validator changes: %w", err)
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	nodes, err := besuDeployer.ListNetworkNodes(ctx, networkID)
+	if err != nil {
+		return len(pending), err
+	}
+	validators, validatorsErr := besuDeployer.GetValidators(ctx, nodes)
+
+	remaining := 0
+	applied := false
+	for _, change := range pending {
+		inSet := validatorsErr == nil && besu.ContainsAddress(validators, change.Address)
+		done := validatorsErr == nil && (change.Action == BesuValidatorActionAdd) == inSet
+
+		switch {
+		case done:
+			besuDeployer.DiscardValidatorVote(ctx, nodes, change.Address)
+			now := time.Now()
+			if _, err := s.setBesuValidatorChangeStatus(ctx, change.ID, BesuValidatorChangeStatusApplied, "", &now); err != nil {
+				return remaining, err
+			}
+			s.logger.Info("Validator change applied", "networkID", networkID, "action", change.Action, "validator", change.Address)
+			applied = true
+		case time.Since(change.CreatedAt) > besuValidatorChangeTimeout:
+			besuDeployer.DiscardValidatorVote(ctx, nodes, change.Address)
+			if _, err := s.setBesuValidatorChangeStatus(ctx, change.ID, BesuValidatorChangeStatusFailed, "timed out waiting for the validator votes", nil); err != nil {
+				return remaining, err
+			}
+		default:
+			remaining++
+		}
+	}
+
+	if validatorsErr != nil {
+		return remaining, fmt.Errorf("failed to get validators: %w", validatorsErr)
+	}
+	if applied {
+		if err := s.updateBesuNetworkValidators(ctx, networkID, validators); err != nil {
+			return remaining, err
+		}
+	}
+	return remaining, nil
+}
+
+// updateBesuNetworkValidators stores the current validator set in the network config
+func (s *NetworkService) updateBesuNetworkValidators(ctx context.Context, networkID int64, validators []string) error {
+	network, err := s.db.GetNetwork(ctx, networkID)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	var config types.BesuNetworkConfig
+	if network.Config.Valid && network.Config.String != "" {
+		if err := json.Unmarshal([]byte(network.Config.String), &config); err != nil {
+			return fmt.Errorf("failed to unmarshal network config: %w", err)
+		}
+	}
+	config.Validators = validators
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal network config: %w", err)
+	}
+	if err := s.db.UpdateNetworkConfig(ctx, &db.UpdateNetworkConfigParams{
+		Config: sql.NullString{String: string(configJSON), Valid: true},
+		ID:     networkID,
+	}); err != nil {
+		return fmt.Errorf("failed to update network config: %w", err)
+	}
+	return nil
+}
+
+// resolveBesuValidatorAddress returns the address of the validator a change refers to
+func (s *NetworkService) resolveBesuValidatorAddress(ctx context.Context, req BesuValidatorChangeRequest) (string, sql.NullInt64, error) {
+	var keyID sql.NullInt64
+	address := req.Address
+	if req.KeyID != 0 {
+		key, err := s.keyMgmt.GetKey(ctx, int(req.KeyID))
+		if err != nil {
+			return "", keyID, fmt.Errorf("failed to get key %d: %w", req.KeyID, err)
+		}
+		if key.EthereumAddress == "" {
+			return "", keyID, fmt.Errorf("%w: key %d has no ethereum address", ErrInvalidBesuValidatorChange, req.KeyID)
+		}
+		if address != "" && !strings.EqualFold(address, key.EthereumAddress) {
+			return "", keyID, fmt.Errorf("%w: address %s does not match key %d", ErrInvalidBesuValidatorChange, address, req.KeyID)
+		}
+		address = key.EthereumAddress
+		keyID = sql.NullInt64{Int64: req.KeyID, Valid: true}
+	}
+
+	if !common.IsHexAddress(address) {
+		return "", keyID, fmt.Errorf("%w: invalid validator address %q", ErrInvalidBesuValidatorChange, address)
+	}
+	return strings.ToLower(common.HexToAddress(address).Hex()), keyID, nil
+}
+
+func (s *NetworkService) getBesuValidatorChange(ctx context.Context, networkID, changeID int64) (*db.BesuValidatorChange, error) {
+	dbChange, err := s.db.GetBesuValidatorChange(ctx, changeID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBesuValidatorChangeNotFound
+		}
+		return nil, fmt.Errorf("failed to get validator change: %w", err)
+	}
+	if dbChange.NetworkID != networkID {
+		return nil, ErrBesuValidatorChangeNotFound
+	}
+	return dbChange, nil
+}
+
+func (s *NetworkService) setBesuValidatorChangeStatus(ctx context.Context, changeID int64, status, errorMessage string, appliedAt *time.Time) (*db.BesuValidatorChange, error) {
+	params := &db.UpdateBesuValidatorChangeStatusParams{
+		Status:       status,
+		ErrorMessage: sql.NullString{String: errorMessage, Valid: errorMessage != ""},
+		ID:           changeID,
+	}
+	if appliedAt != nil {
+		params.AppliedAt = sql.NullTime{Time: *appliedAt, Valid: true}
+	}
+	dbChange, err := s.db.UpdateBesuValidatorChangeStatus(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update validator change status: %w", err)
+	}
+	return dbChange, nil
+}
+
+// getBesuDeployerForNetwork returns the Besu deployer for a network, making sure
+// the network is a Besu network
+func (s *NetworkService) getBesuDeployerForNetwork(ctx context.Context, networkID int64) (*besu.BesuDeployer, error) {
+	network, err := s.db.GetNetwork(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network: %w", err)
+	}
+	deployer, err := s.deployerFactory.GetDeployer(network.Platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployer: %w", err)
+	}
+
+	besuDeployer, ok := deployer.(*besu.BesuDeployer)
+	if !ok {
+		return nil, fmt.Errorf("network %d is not a Besu network", networkID)
+	}
+	return besuDeployer, nil
+}
+
+func countBesuVotes(votes []besu.ValidatorVote) int {
+	count := 0
+	for _, vote := range votes {
+		if vote.Error == "" {
+			count++
+		}
+	}
+	return count
+}
+
+func mapBesuValidatorChange(c *db.BesuValidatorChange) *BesuValidatorChange {
+	change := &BesuValidatorChange{
+		ID:            c.ID,
+		NetworkID:     c.NetworkID,
+		Action:        c.Action,
+		Address:       c.Address,
+		Status:        c.Status,
+		Votes:         []besu.ValidatorVote{},
+		VotesRequired: int(c.VotesRequired),
+		Error:         c.ErrorMessage.String,
+		CreatedBy:     c.CreatedBy.String,
+		CreatedAt:     c.CreatedAt,
+		UpdatedAt:     c.UpdatedAt,
+	}
+	if c.KeyID.Valid {
+		keyID := c.KeyID.Int64
+		change.KeyID = &keyID
+	}
+	if c.Votes.Valid && c.Votes.String != "" {
+		_ = json.Unmarshal([]byte(c.Votes.String), &change.Votes)
+	}
+	if c.AppliedAt.Valid {
+		appliedAt := c.AppliedAt.Time
+		change.AppliedAt = &appliedAt
+	}
+	return change
+}
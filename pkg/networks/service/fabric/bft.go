@@ -0,0 +1,369 @@
+package fabric
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/certutils"
+	"github.com/chainlaunch/chainlaunch/pkg/fabric/channel"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
+	nodeservice "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
+	"github.com/hyperledger/fabric-config/configtx"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer/smartbft"
+)
+
+// AddBFTConsenterOperation represents an operation to add a consenter to a BFT channel
+type AddBFTConsenterOperation struct {
+	// ID of the consenter, the highest existing ID plus one when zero
+	ID            uint32 `json:"id"`
+	Host          string `json:"host"`
+	Port          int    `json:"port"`
+	MSPID         string `json:"msp_id"`
+	Identity      string `json:"identity"`
+	ClientTLSCert string `json:"client_tls_cert"`
+	ServerTLSCert string `json:"server_tls_cert"`
+}
+
+// Type returns the type of the operation
+func (op *AddBFTConsenterOperation) Type() ConfigUpdateOperationType {
+	return OpAddBFTConsenter
+}
+
+// Validate validates the operation
+func (op *AddBFTConsenterOperation) Validate() error {
+	if op.Host == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	if op.Port <= 0 {
+		return fmt.Errorf("invalid port: %d", op.Port)
+	}
+	if op.MSPID == "" {
+		return fmt.Errorf("MSP ID cannot be empty")
+	}
+	if op.Identity == "" {
+		return fmt.Errorf("identity cannot be empty")
+	}
+	if op.ClientTLSCert == "" {
+		return fmt.Errorf("client TLS certificate cannot be empty")
+	}
+	if op.ServerTLSCert == "" {
+		return fmt.Errorf("server TLS certificate cannot be empty")
+	}
+	return nil
+}
+
+// Modify applies the operation to the given config
+func (op *AddBFTConsenterOperation) Modify(ctx context.Context, c *configtx.ConfigTx) error {
+	for name, cert := range map[string]string{"identity": op.Identity, "client TLS certificate": op.ClientTLSCert, "server TLS certificate": op.ServerTLSCert} {
+		if _, err := certutils.ParseX509Certificate([]byte(cert)); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+	}
+	if err := requireConsensusType(c, channel.ConsensusTypeBFT); err != nil {
+		return err
+	}
+
+	config := c.UpdatedConfig()
+	consenters, err := channel.BFTConsenters(config)
+	if err != nil {
+		return fmt.Errorf("failed to get consenters: %w", err)
+	}
+
+	id := op.ID
+	var maxID uint32
+	for _, consenter := range consenters {
+		if consenter.Host == op.Host && int(consenter.Port) == op.Port {
+			return fmt.Errorf("consenter %s:%d already exists", op.Host, op.Port)
+		}
+		if id != 0 && consenter.Id == id {
+			return fmt.Errorf("consenter ID %d is already used", id)
+		}
+		if consenter.Id > maxID {
+			maxID = consenter.Id
+		}
+	}
+	if id == 0 {
+		id = maxID + 1
+	}
+
+	consenters = append(consenters, &cb.Consenter{
+		Id:            id,
+		Host:          op.Host,
+		Port:          uint32(op.Port),
+		MspId:         op.MSPID,
+		Identity:      []byte(op.Identity),
+		ClientTlsCert: []byte(op.ClientTLSCert),
+		ServerTlsCert: []byte(op.ServerTLSCert),
+	})
+	if err := channel.SetBFTConsenters(config, consenters); err != nil {
+		return fmt.Errorf("failed to set consenters: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveBFTConsenterOperation represents an operation to remove a consenter from a BFT channel
+type RemoveBFTConsenterOperation struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+// Type returns the type of the operation
+func (op *RemoveBFTConsenterOperation) Type() ConfigUpdateOperationType {
+	return OpRemoveBFTConsenter
+}
+
+// Validate validates the operation
+func (op *RemoveBFTConsenterOperation) Validate() error {
+	if op.Host == "" {
+		return fmt.Errorf("host cannot be empty")
+	}
+	if op.Port <= 0 {
+		return fmt.Errorf("invalid port: %d", op.Port)
+	}
+	return nil
+}
+
+// Modify applies the operation to the given config
+func (op *RemoveBFTConsenterOperation) Modify(ctx context.Context, c *configtx.ConfigTx) error {
+	if err := requireConsensusType(c, channel.ConsensusTypeBFT); err != nil {
+		return err
+	}
+
+	config := c.UpdatedConfig()
+	consenters, err := channel.BFTConsenters(config)
+	if err != nil {
+		return fmt.Errorf("failed to get consenters: %w", err)
+	}
+
+	remaining := make([]*cb.Consenter, 0, len(consenters))
+	for _, consenter := range consenters {
+		if consenter.Host == op.Host && int(consenter.Port) == op.Port {
+			continue
+		}
+		remaining = append(remaining, consenter)
+	}
+	if len(remaining) == len(consenters) {
+		return fmt.Errorf("consenter not found")
+	}
+
+	if err := channel.SetBFTConsenters(config, remaining); err != nil {
+		return fmt.Errorf("failed to remove consenter: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateSmartBFTOptionsOperation represents an operation to update the SmartBFT
+// options of a BFT channel. Fields left empty keep their current value.
+type UpdateSmartBFTOptionsOperation struct {
+	RequestBatchMaxCount      uint64 `json:"request_batch_max_count"`
+	RequestBatchMaxBytes      uint64 `json:"request_batch_max_bytes"`
+	RequestBatchMaxInterval   string `json:"request_batch_max_interval"`
+	IncomingMessageBufferSize uint64 `json:"incoming_message_buffer_size"`
+	RequestPoolSize           uint64 `json:"request_pool_size"`
+	RequestForwardTimeout     string `json:"request_forward_timeout"`
+	RequestComplainTimeout    string `json:"request_complain_timeout"`
+	RequestAutoRemoveTimeout  string `json:"request_auto_remove_timeout"`
+	ViewChangeResendInterval  string `json:"view_change_resend_interval"`
+	ViewChangeTimeout         string `json:"view_change_timeout"`
+	LeaderHeartbeatTimeout    string `json:"leader_heartbeat_timeout"`
+	LeaderHeartbeatCount      uint64 `json:"leader_heartbeat_count"`
+	CollectTimeout            string `json:"collect_timeout"`
+	SyncOnStart               *bool  `json:"sync_on_start"`
+	SpeedUpViewChange         *bool  `json:"speed_up_view_change"`
+	LeaderRotation            *bool  `json:"leader_rotation"`
+	DecisionsPerLeader        uint64 `json:"decisions_per_leader"`
+	RequestMaxBytes           uint64 `json:"request_max_bytes"`
+}
+
+// Type returns the type of the operation
+func (op *UpdateSmartBFTOptionsOperation) Type() ConfigUpdateOperationType {
+	return OpUpdateSmartBFTOptions
+}
+
+// Validate validates the operation
+func (op *UpdateSmartBFTOptionsOperation) Validate() error {
+	durations := map[string]string{
+		"request batch max interval":  op.RequestBatchMaxInterval,
+		"request forward timeout":     op.RequestForwardTimeout,
+		"request complain timeout":    op.RequestComplainTimeout,
+		"request auto remove timeout": op.RequestAutoRemoveTimeout,
+		"view change resend interval": op.ViewChangeResendInterval,
+		"view change timeout":         op.ViewChangeTimeout,
+		"leader heartbeat timeout":    op.LeaderHeartbeatTimeout,
+		"collect timeout":             op.CollectTimeout,
+	}
+	for name, value := range durations {
+		if value == "" {
+			continue
+		}
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Modify applies the operation to the given config
+func (op *UpdateSmartBFTOptionsOperation) Modify(ctx context.Context, c *configtx.ConfigTx) error {
+	config := c.UpdatedConfig()
+	options, err := channel.SmartBFTOptions(config)
+	if err != nil {
+		return fmt.Errorf("failed to get SmartBFT options: %w", err)
+	}
+
+	mergeSmartBFTOptions(options, &types.SmartBFTOptions{
+		RequestBatchMaxCount:      op.RequestBatchMaxCount,
+		RequestBatchMaxBytes:      op.RequestBatchMaxBytes,
+		RequestBatchMaxInterval:   op.RequestBatchMaxInterval,
+		IncomingMessageBufferSize: op.IncomingMessageBufferSize,
+		RequestPoolSize:           op.RequestPoolSize,
+		RequestForwardTimeout:     op.RequestForwardTimeout,
+		RequestComplainTimeout:    op.RequestComplainTimeout,
+		RequestAutoRemoveTimeout:  op.RequestAutoRemoveTimeout,
+		ViewChangeResendInterval:  op.ViewChangeResendInterval,
+		ViewChangeTimeout:         op.ViewChangeTimeout,
+		LeaderHeartbeatTimeout:    op.LeaderHeartbeatTimeout,
+		LeaderHeartbeatCount:      op.LeaderHeartbeatCount,
+		CollectTimeout:            op.CollectTimeout,
+		DecisionsPerLeader:        op.DecisionsPerLeader,
+		RequestMaxBytes:           op.RequestMaxBytes,
+	})
+	if op.SyncOnStart != nil {
+		options.SyncOnStart = *op.SyncOnStart
+	}
+	if op.SpeedUpViewChange != nil {
+		options.SpeedUpViewChange = *op.SpeedUpViewChange
+	}
+	if op.LeaderRotation != nil {
+		options.LeaderRotation = leaderRotation(*op.LeaderRotation)
+	}
+
+	if err := channel.SetSmartBFTOptions(config, options); err != nil {
+		return fmt.Errorf("failed to update SmartBFT options: %w", err)
+	}
+
+	return nil
+}
+
+// requireConsensusType fails when the channel doesn't use the given consensus, so
+// etcdraft operations aren't applied to BFT channels and vice versa
+func requireConsensusType(c *configtx.ConfigTx, consensusType string) error {
+	current, err := channel.OrdererConsensusType(c.UpdatedConfig())
+	if err != nil {
+		return fmt.Errorf("failed to get consensus type: %w", err)
+	}
+	if current != consensusType {
+		return fmt.Errorf("operation requires %s consensus, channel uses %s", consensusType, current)
+	}
+	return nil
+}
+
+// smartBFTOptionsFromConfig returns the Fabric default SmartBFT options overridden
+// by the options set in the network config
+func smartBFTOptionsFromConfig(config *types.SmartBFTOptions) *smartbft.Options {
+	options := channel.DefaultSmartBFTOptions()
+	if config == nil {
+		return options
+	}
+	mergeSmartBFTOptions(options, config)
+	if config.SyncOnStart != nil {
+		options.SyncOnStart = *config.SyncOnStart
+	}
+	options.SpeedUpViewChange = config.SpeedUpViewChange
+	options.LeaderRotation = leaderRotation(config.LeaderRotation)
+	return options
+}
+
+// mergeSmartBFTOptions overrides options with the non-zero numeric and duration
+// values of update
+func mergeSmartBFTOptions(options *smartbft.Options, update *types.SmartBFTOptions) {
+	setUint := func(dst *uint64, value uint64) {
+		if value != 0 {
+			*dst = value
+		}
+	}
+	setString := func(dst *string, value string) {
+		if value != "" {
+			*dst = value
+		}
+	}
+	setUint(&options.RequestBatchMaxCount, update.RequestBatchMaxCount)
+	setUint(&options.RequestBatchMaxBytes, update.RequestBatchMaxBytes)
+	setString(&options.RequestBatchMaxInterval, update.RequestBatchMaxInterval)
+	setUint(&options.IncomingMessageBufferSize, update.IncomingMessageBufferSize)
+	setUint(&options.RequestPoolSize, update.RequestPoolSize)
+	setString(&options.RequestForwardTimeout, update.RequestForwardTimeout)
+	setString(&options.RequestComplainTimeout, update.RequestComplainTimeout)
+	setString(&options.RequestAutoRemoveTimeout, update.RequestAutoRemoveTimeout)
+	setString(&options.ViewChangeResendInterval, update.ViewChangeResendInterval)
+	setString(&options.ViewChangeTimeout, update.ViewChangeTimeout)
+	setString(&options.LeaderHeartbeatTimeout, update.LeaderHeartbeatTimeout)
+	setUint(&options.LeaderHeartbeatCount, update.LeaderHeartbeatCount)
+	setString(&options.CollectTimeout, update.CollectTimeout)
+	setUint(&options.DecisionsPerLeader, update.DecisionsPerLeader)
+	setUint(&options.RequestMaxBytes, update.RequestMaxBytes)
+}
+
+func leaderRotation(enabled bool) smartbft.Options_Rotation {
+	if enabled {
+		return smartbft.Options_ROTATION_ON
+	}
+	return smartbft.Options_ROTATION_OFF
+}
+
+// getOrdererSignCert returns the PEM signing certificate of an orderer node, its
+// identity in the consenter mapping of BFT channels
+func (d *FabricDeployer) getOrdererSignCert(ctx context.Context, node *nodeservice.NodeResponse) (string, error) {
+	if node.FabricOrderer.SignCert != "" {
+		return node.FabricOrderer.SignCert, nil
+	}
+	signKey, err := d.keyMgmt.GetKey(ctx, int(node.FabricOrderer.SignKeyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get orderer sign key: %w", err)
+	}
+	if signKey.Certificate == nil {
+		return "", fmt.Errorf("orderer node %s has no signing certificate", node.Name)
+	}
+	return *signKey.Certificate, nil
+}
+
+// orderersFromConfig returns the consenters of a channel config, for both etcdraft
+// and BFT channels
+func orderersFromConfig(config *cb.Config) ([]*OrdererInfo, error) {
+	consensusType, err := channel.OrdererConsensusType(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consensus type: %w", err)
+	}
+
+	var orderers []*OrdererInfo
+	if consensusType == channel.ConsensusTypeBFT {
+		consenters, err := channel.BFTConsenters(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get consenters: %w", err)
+		}
+		for _, consenter := range consenters {
+			orderers = append(orderers, &OrdererInfo{
+				URL:     fmt.Sprintf("grpcs://%s:%d", consenter.Host, consenter.Port),
+				TLSCert: string(consenter.ServerTlsCert),
+			})
+		}
+		return orderers, nil
+	}
+
+	c := configtx.New(config)
+	ordererConf, err := c.Orderer().Configuration()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orderer configuration: %w", err)
+	}
+	for _, consenter := range ordererConf.EtcdRaft.Consenters {
+		orderers = append(orderers, &OrdererInfo{
+			URL:     fmt.Sprintf("grpcs://%s:%d", consenter.Address.Host, consenter.Address.Port),
+			TLSCert: string(certutils.EncodeX509Certificate(consenter.ServerTLSCert)),
+		})
+	}
+	return orderers, nil
+}
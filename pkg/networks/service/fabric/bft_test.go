@@ -0,0 +1,240 @@
+package fabric
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/pkg/fabric/channel"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
+	"github.com/hyperledger/fabric-config/configtx"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	ob "github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"github.com/hyperledger/fabric-protos-go-apiv2/orderer/smartbft"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestBFTConfig returns a channel config whose orderer group holds the consensus
+// type and, for BFT, the default options and a consenter orderer0.example.com:7050
+func newTestBFTConfig(t *testing.T, consensusType string) *cb.Config {
+	metadata, err := proto.Marshal(channel.DefaultSmartBFTOptions())
+	if err != nil {
+		t.Fatalf("failed to marshal options: %v", err)
+	}
+	value, err := proto.Marshal(&ob.ConsensusType{Type: consensusType, Metadata: metadata})
+	if err != nil {
+		t.Fatalf("failed to marshal consensus type: %v", err)
+	}
+	config := &cb.Config{ChannelGroup: &cb.ConfigGroup{
+		Groups: map[string]*cb.ConfigGroup{
+			"Orderer": {Values: map[string]*cb.ConfigValue{"ConsensusType": {Value: value}}},
+		},
+	}}
+	if consensusType == channel.ConsensusTypeBFT {
+		if err := channel.SetBFTConsenters(config, []*cb.Consenter{
+			{Id: 3, Host: "orderer0.example.com", Port: 7050, MspId: "OrdererMSP", ServerTlsCert: []byte("server-tls")},
+		}); err != nil {
+			t.Fatalf("failed to set consenters: %v", err)
+		}
+	}
+	return config
+}
+
+func newTestConfigTx(t *testing.T, consensusType string) *configtx.ConfigTx {
+	c := configtx.New(newTestBFTConfig(t, consensusType))
+	return &c
+}
+
+func newTestAddConsenter(t *testing.T, host string) *AddBFTConsenterOperation {
+	cert := string(newSelfSignedCertificate(t, newTestKey(t), host, ""))
+	return &AddBFTConsenterOperation{
+		Host:          host,
+		Port:          7050,
+		MSPID:         "OrdererMSP",
+		Identity:      cert,
+		ClientTLSCert: cert,
+		ServerTLSCert: cert,
+	}
+}
+
+func TestAddBFTConsenterOperation(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConfigTx(t, channel.ConsensusTypeBFT)
+
+	op := newTestAddConsenter(t, "orderer1.example.com")
+	if err := op.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+	if err := op.Modify(ctx, c); err != nil {
+		t.Fatalf("failed to add consenter: %v", err)
+	}
+	consenters, err := channel.BFTConsenters(c.UpdatedConfig())
+	if err != nil {
+		t.Fatalf("failed to get consenters: %v", err)
+	}
+	// IDs follow the highest existing ID
+	if len(consenters) != 2 || consenters[1].Id != 4 || consenters[1].Host != "orderer1.example.com" {
+		t.Errorf("unexpected consenters %v", consenters)
+	}
+
+	cases := map[string]struct {
+		op       *AddBFTConsenterOperation
+		config   *configtx.ConfigTx
+		contains string
+	}{
+		"existing address": {newTestAddConsenter(t, "orderer0.example.com"), c, "already exists"},
+		"used id": {func() *AddBFTConsenterOperation {
+			op := newTestAddConsenter(t, "orderer2.example.com")
+			op.ID = 3
+			return op
+		}(), c, "ID 3 is already used"},
+		"invalid identity": {func() *AddBFTConsenterOperation {
+			op := newTestAddConsenter(t, "orderer2.example.com")
+			op.Identity = "identity"
+			return op
+		}(), c, "failed to parse identity"},
+		"etcdraft channel": {newTestAddConsenter(t, "orderer2.example.com"), newTestConfigTx(t, channel.ConsensusTypeEtcdRaft), "requires BFT consensus"},
+	}
+	for name, tc := range cases {
+		err := tc.op.Modify(ctx, tc.config)
+		if err == nil || !strings.Contains(err.Error(), tc.contains) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, tc.contains, err)
+		}
+	}
+}
+
+func TestRemoveBFTConsenterOperation(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConfigTx(t, channel.ConsensusTypeBFT)
+	if err := newTestAddConsenter(t, "orderer1.example.com").Modify(ctx, c); err != nil {
+		t.Fatalf("failed to add consenter: %v", err)
+	}
+
+	if err := (&RemoveBFTConsenterOperation{Host: "orderer0.example.com", Port: 7050}).Modify(ctx, c); err != nil {
+		t.Fatalf("failed to remove consenter: %v", err)
+	}
+	consenters, err := channel.BFTConsenters(c.UpdatedConfig())
+	if err != nil || len(consenters) != 1 || consenters[0].Host != "orderer1.example.com" {
+		t.Errorf("unexpected consenters %v (%v)", consenters, err)
+	}
+
+	cases := map[string]struct {
+		op     *RemoveBFTConsenterOperation
+		config *configtx.ConfigTx
+	}{
+		"unknown consenter": {&RemoveBFTConsenterOperation{Host: "orderer0.example.com", Port: 7051}, c},
+		"last consenter":    {&RemoveBFTConsenterOperation{Host: "orderer1.example.com", Port: 7050}, c},
+		"etcdraft channel":  {&RemoveBFTConsenterOperation{Host: "orderer0.example.com", Port: 7050}, newTestConfigTx(t, channel.ConsensusTypeEtcdRaft)},
+	}
+	for name, tc := range cases {
+		if err := tc.op.Modify(ctx, tc.config); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestBFTOperationValidate(t *testing.T) {
+	valid := newTestAddConsenter(t, "orderer1.example.com")
+	cases := map[string]ConfigModifier{
+		"add without host": &AddBFTConsenterOperation{Port: 7050},
+		"add without port": &AddBFTConsenterOperation{Host: "orderer1"},
+		"add without msp":  &AddBFTConsenterOperation{Host: "orderer1", Port: 7050},
+		"add without identity": &AddBFTConsenterOperation{
+			Host: "orderer1", Port: 7050, MSPID: "OrdererMSP",
+		},
+		"add without client tls": &AddBFTConsenterOperation{
+			Host: "orderer1", Port: 7050, MSPID: "OrdererMSP", Identity: valid.Identity,
+		},
+		"add without server tls": &AddBFTConsenterOperation{
+			Host: "orderer1", Port: 7050, MSPID: "OrdererMSP", Identity: valid.Identity, ClientTLSCert: valid.ClientTLSCert,
+		},
+		"remove without host":  &RemoveBFTConsenterOperation{Port: 7050},
+		"remove invalid port":  &RemoveBFTConsenterOperation{Host: "orderer1", Port: -1},
+		"invalid duration":     &UpdateSmartBFTOptionsOperation{ViewChangeTimeout: "20"},
+		"invalid batch period": &UpdateSmartBFTOptionsOperation{RequestBatchMaxInterval: "soon"},
+	}
+	for name, op := range cases {
+		if err := op.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if err := (&UpdateSmartBFTOptionsOperation{}).Validate(); err != nil {
+		t.Errorf("empty update should be valid: %v", err)
+	}
+}
+
+func TestUpdateSmartBFTOptionsOperation(t *testing.T) {
+	ctx := context.Background()
+	c := newTestConfigTx(t, channel.ConsensusTypeBFT)
+	enabled, disabled := true, false
+
+	op := &UpdateSmartBFTOptionsOperation{
+		RequestBatchMaxCount: 500,
+		ViewChangeTimeout:    "30s",
+		SyncOnStart:          &disabled,
+		LeaderRotation:       &enabled,
+	}
+	if err := op.Modify(ctx, c); err != nil {
+		t.Fatalf("failed to update options: %v", err)
+	}
+	options, err := channel.SmartBFTOptions(c.UpdatedConfig())
+	if err != nil {
+		t.Fatalf("failed to get options: %v", err)
+	}
+	defaults := channel.DefaultSmartBFTOptions()
+	if options.RequestBatchMaxCount != 500 || options.ViewChangeTimeout != "30s" ||
+		options.SyncOnStart || options.LeaderRotation != smartbft.Options_ROTATION_ON {
+		t.Errorf("options not updated: %v", options)
+	}
+	// Fields left empty keep their value
+	if options.RequestBatchMaxBytes != defaults.RequestBatchMaxBytes || options.CollectTimeout != defaults.CollectTimeout ||
+		options.SpeedUpViewChange != defaults.SpeedUpViewChange {
+		t.Errorf("unset options changed: %v", options)
+	}
+
+	if err := op.Modify(ctx, newTestConfigTx(t, channel.ConsensusTypeEtcdRaft)); err == nil {
+		t.Error("expected an error updating SmartBFT options of an etcdraft channel")
+	}
+}
+
+func TestSmartBFTOptionsFromConfig(t *testing.T) {
+	if options := smartBFTOptionsFromConfig(nil); !proto.Equal(options, channel.DefaultSmartBFTOptions()) {
+		t.Errorf("expected the default options, got %v", options)
+	}
+
+	disabled := false
+	options := smartBFTOptionsFromConfig(&types.SmartBFTOptions{
+		RequestPoolSize:   10,
+		CollectTimeout:    "2s",
+		SyncOnStart:       &disabled,
+		SpeedUpViewChange: true,
+		LeaderRotation:    true,
+	})
+	defaults := channel.DefaultSmartBFTOptions()
+	if options.RequestPoolSize != 10 || options.CollectTimeout != "2s" || options.SyncOnStart ||
+		!options.SpeedUpViewChange || options.LeaderRotation != smartbft.Options_ROTATION_ON {
+		t.Errorf("options not applied: %v", options)
+	}
+	if options.RequestBatchMaxCount != defaults.RequestBatchMaxCount || options.LeaderHeartbeatTimeout != defaults.LeaderHeartbeatTimeout {
+		t.Errorf("unset options should keep their default: %v", options)
+	}
+
+	// SyncOnStart defaults to on when not set
+	if options := smartBFTOptionsFromConfig(&types.SmartBFTOptions{}); !options.SyncOnStart {
+		t.Error("expected SyncOnStart to keep its default")
+	}
+}
+
+func TestOrderersFromBFTConfig(t *testing.T) {
+	orderers, err := orderersFromConfig(newTestBFTConfig(t, channel.ConsensusTypeBFT))
+	if err != nil {
+		t.Fatalf("failed to get orderers: %v", err)
+	}
+	if len(orderers) != 1 || orderers[0].URL != "grpcs://orderer0.example.com:7050" || orderers[0].TLSCert != "server-tls" {
+		t.Errorf("unexpected orderers %+v", orderers)
+	}
+
+	if _, err := orderersFromConfig(&cb.Config{ChannelGroup: &cb.ConfigGroup{}}); err == nil {
+		t.Error("expected an error for a config without orderer group")
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"github.com/chainlaunch/chainlaunch/pkg/networks/service/fabric/org"
 	fabricorg "github.com/chainlaunch/chainlaunch/pkg/networks/service/fabric/org"
 	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
+	ordererservice "github.com/chainlaunch/chainlaunch/pkg/nodes/orderer"
 	nodeservice "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
 	nodetypes "github.com/chainlaunch/chainlaunch/pkg/nodes/types"
 
@@ -63,6 +64,10 @@ const (
 	OpUpdateConsenter    ConfigUpdateOperationType = "update_consenter"
 	OpUpdateBatchSize    ConfigUpdateOperationType = "update_batch_size"
 	OpUpdateBatchTimeout ConfigUpdateOperationType = "update_batch_timeout"
+	// BFT orderer config update operations
+	OpAddBFTConsenter       ConfigUpdateOperationType = "add_bft_consenter"
+	OpRemoveBFTConsenter    ConfigUpdateOperationType = "remove_bft_consenter"
+	OpUpdateSmartBFTOptions ConfigUpdateOperationType = "update_smartbft_options"
 )
 
 // ConfigUpdateOperation represents a configuration update operation with its associated data
@@ -279,6 +284,9 @@ func (op *AddConsenterOperation) Validate() error {
 
 // Modify applies the operation to the given config
 func (op *AddConsenterOperation) Modify(ctx context.Context, c *configtx.ConfigTx) error {
+	if err := requireConsensusType(c, channel.ConsensusTypeEtcdRaft); err != nil {
+		return err
+	}
 
 	// Parse TLS certificates
 	clientTLSCert, err := certutils.ParseX509Certificate([]byte(op.ClientTLSCert))
@@ -334,6 +342,9 @@ func (op *RemoveConsenterOperation) Validate() error {
 
 // Modify applies the operation to the given config
 func (op *RemoveConsenterOperation) Modify(ctx context.Context, c *configtx.ConfigTx) error {
+	if err := requireConsensusType(c, channel.ConsensusTypeEtcdRaft); err != nil {
+		return err
+	}
 	// Get orderer group
 	ordConfig, err := c.Orderer().Configuration()
 	if err != nil {
@@ -394,6 +405,9 @@ func (op *UpdateConsenterOperation) Validate() error {
 
 // Modify applies the operation to the given config
 func (op *UpdateConsenterOperation) Modify(ctx context.Context, c *configtx.ConfigTx) error {
+	if err := requireConsensusType(c, channel.ConsensusTypeEtcdRaft); err != nil {
+		return err
+	}
 	// Get orderer group
 	ordConfig, err := c.Orderer().Configuration()
 	if err != nil {
@@ -472,6 +486,9 @@ func (op *UpdateEtcdRaftOptionsOperation) Validate() error {
 
 // Modify applies the operation to the given config
 func (op *UpdateEtcdRaftOptionsOperation) Modify(ctx context.Context, c *configtx.ConfigTx) error {
+	if err := requireConsensusType(c, channel.ConsensusTypeEtcdRaft); err != nil {
+		return err
+	}
 	// Get orderer configuration
 	ordConfig, err := c.Orderer().Configuration()
 	if err != nil {
@@ -638,6 +655,24 @@ func CreateConfigModifier(operation ConfigUpdateOperation) (ConfigModifier, erro
 			return nil, fmt.Errorf("failed to unmarshal update batch timeout payload: %w", err)
 		}
 		modifier = &op
+	case OpAddBFTConsenter:
+		var op AddBFTConsenterOperation
+		if err := json.Unmarshal(operation.Payload, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal add BFT consenter payload: %w", err)
+		}
+		modifier = &op
+	case OpRemoveBFTConsenter:
+		var op RemoveBFTConsenterOperation
+		if err := json.Unmarshal(operation.Payload, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal remove BFT consenter payload: %w", err)
+		}
+		modifier = &op
+	case OpUpdateSmartBFTOptions:
+		var op UpdateSmartBFTOptionsOperation
+		if err := json.Unmarshal(operation.Payload, &op); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal update SmartBFT options payload: %w", err)
+		}
+		modifier = &op
 	default:
 		return nil, fmt.Errorf("unsupported operation type: %s", operation.Type)
 	}
@@ -813,9 +848,11 @@ organizations:
           cert: 
             pem: |
 {{.AdminKey.Certificate | indent 16}}
+{{- if .AdminKey.PrivateKey}}
           key:
             pem: |
 {{.AdminKey.PrivateKey | indent 16}}
+{{- end}}
 
 orderers:
   {{- range $name, $orderer := .Orderers}}
@@ -1078,23 +1115,39 @@ func (d *FabricDeployer) CreateGenesisBlock(networkID int64, config interface{})
 				if err != nil {
 					return nil, fmt.Errorf("failed to parse port number %s: %w", portStr, err)
 				}
-				consenters = append(consenters, channel.AddressWithCerts{
+				consenter := channel.AddressWithCerts{
 					Address: channel.HostPort{
 						Host: host,
 						Port: port,
 					},
 					ClientTLSCert: nodeTlsCert,
 					ServerTLSCert: nodeTlsCert,
-				})
+				}
+				if fabricConfig.ConsensusType == types.FabricConsensusTypeBFT {
+					if !ordererservice.SupportsBFT(ordererNode.FabricOrderer.Version) {
+						return nil, fmt.Errorf("orderer node %s runs Fabric %s, BFT consensus requires Fabric 3.x", ordererNode.Name, ordererNode.FabricOrderer.Version)
+					}
+					identity, err := d.getOrdererSignCert(ctx, ordererNode)
+					if err != nil {
+						return nil, err
+					}
+					consenter.MSPID = fabricOrgDB.MspID
+					consenter.Identity = identity
+				}
+				consenters = append(consenters, consenter)
 			}
 		}
 	}
 
 	createReq := channel.CreateChannelInput{
-		Name:        fabricConfig.ChannelName,
-		Consenters:  consenters,
-		PeerOrgs:    peerOrgs,
-		OrdererOrgs: ordererOrgs,
+		Name:          fabricConfig.ChannelName,
+		Consenters:    consenters,
+		PeerOrgs:      peerOrgs,
+		OrdererOrgs:   ordererOrgs,
+		ConsensusType: string(fabricConfig.ConsensusType),
+	}
+	if fabricConfig.ConsensusType == types.FabricConsensusTypeBFT {
+		createReq.SmartBFTOptions = smartBFTOptionsFromConfig(fabricConfig.SmartBFTOptions)
 	}
 	d.logger.Debug("Creating channel with request: %+v", createReq)
 	channel, err := d.channelService.CreateChannel(createReq)
@@ -1674,9 +1727,18 @@ func (d *FabricDeployer) GenerateNetworkConfig(ctx context.Context, networkID in
 	if err != nil {
 		return "", fmt.Errorf("failed to get admin key: %w", err)
 	}
-	adminPrivateKey, err := d.keyMgmt.GetDecryptedPrivateKey(int(org.AdminSignKeyID.Int64))
+	// A key held in a PKCS#11 token can't be exported, clients of the network
+	// config then sign with the token themselves
+	adminKeySettings, err := d.keyMgmt.GetPKCS11Settings(ctx, int(org.AdminSignKeyID.Int64))
 	if err != nil {
-		return "", fmt.Errorf("failed to get admin private key: %w", err)
+		return "", fmt.Errorf("failed to get admin key provider: %w", err)
+	}
+	adminPrivateKey := ""
+	if adminKeySettings == nil {
+		adminPrivateKey, err = d.keyMgmt.GetDecryptedPrivateKey(int(org.AdminSignKeyID.Int64))
+		if err != nil {
+			return "", fmt.Errorf("failed to get admin private key: %w", err)
+		}
 	}
 	// Get network nodes
 	networkNodes, err := d.db.GetNetworkNodes(ctx, networkID)
@@ -2155,25 +2217,9 @@ func (d *FabricDeployer) GetOrderersFromConfigBlock(ctx context.Context, blockBy
 		return nil, fmt.Errorf("failed to extract config from block: %w", err)
 	}
 
-	cfgtx := configtx.New(cmnConfig)
-	ordererConf, err := cfgtx.Orderer().Configuration()
+	orderers, err := orderersFromConfig(cmnConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get orderer configuration: %w", err)
-	}
-
-	consenters := ordererConf.EtcdRaft.Consenters
-
-	var orderers []*OrdererInfo
-	for _, consent := range consenters {
-		pemPk := pem.EncodeToMemory(&pem.Block{
-			Type:  "CERTIFICATE",
-			Bytes: consent.ServerTLSCert.Raw,
-		})
-		ordererAddress := fmt.Sprintf("%s:%d", consent.Address.Host, consent.Address.Port)
-		orderers = append(orderers, &OrdererInfo{
-			URL:     fmt.Sprintf("grpcs://%s", ordererAddress),
-			TLSCert: string(pemPk),
-		})
+		return nil, err
 	}
 
 	return orderers, nil
@@ -2209,25 +2255,9 @@ func (d *FabricDeployer) GetOrderersFromGenesisBlock(ctx context.Context, networ
 		return nil, fmt.Errorf("failed to extract config from block: %w", err)
 	}
 
-	cfgtx := configtx.New(cmnConfig)
-	ordererConf, err := cfgtx.Orderer().Configuration()
+	orderers, err := orderersFromConfig(cmnConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get orderer configuration: %w", err)
-	}
-
-	consenters := ordererConf.EtcdRaft.Consenters
-
-	var orderers []*OrdererInfo
-	for _, consent := range consenters {
-		pemPk := pem.EncodeToMemory(&pem.Block{
-			Type:  "CERTIFICATE",
-			Bytes: consent.ServerTLSCert.Raw,
-		})
-		ordererAddress := fmt.Sprintf("%s:%d", consent.Address.Host, consent.Address.Port)
-		orderers = append(orderers, &OrdererInfo{
-			URL:     fmt.Sprintf("grpcs://%s", ordererAddress),
-			TLSCert: string(pemPk),
-		})
+		return nil, err
 	}
 
 	if len(orderers) == 0 {
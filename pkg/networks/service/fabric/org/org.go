@@ -2,7 +2,6 @@ package org
 
 import (
 	"context"
-	"crypto"
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
@@ -77,43 +76,16 @@ func (s *FabricOrg) GetConfigBlockWithNetworkConfig(ctx context.Context, channel
 		return nil, fmt.Errorf("failed to get organization: %w", err)
 	}
 
-	// Get signing key
-	var privateKeyPEM string
+	// Get signing identity
 	if !org.AdminSignKeyID.Valid {
 		return nil, fmt.Errorf("organization has no admin sign key")
 	}
-	adminSignKey, err := s.keyMgmtService.GetKey(ctx, int(org.AdminSignKeyID.Int64))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get admin sign key: %w", err)
-	}
-	if adminSignKey.Certificate == nil {
-		return nil, fmt.Errorf("admin sign key has no certificate")
-	}
-	// Get private key from key management service
-	privateKeyPEM, err = s.keyMgmtService.GetDecryptedPrivateKey(int(org.AdminSignKeyID.Int64))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get private key: %w", err)
-	}
-
-	cert, err := gwidentity.CertificateFromPEM([]byte(*adminSignKey.Certificate))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read certificate: %w", err)
-	}
-
-	priv, err := gwidentity.PrivateKeyFromPEM([]byte(privateKeyPEM))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read private key: %w", err)
-	}
-
-	ordererMSP, err := identity.NewPrivateKeySigningIdentity(s.mspID, cert, priv)
+	ordererMSP, err := s.keyMgmtService.GetSigningIdentity(ctx, int(org.AdminSignKeyID.Int64), s.mspID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create orderer msp: %w", err)
 	}
-	// Parse the orderer TLS certificate
-	ordererTLSCertParsed, err := tls.X509KeyPair([]byte(*adminSignKey.Certificate), []byte(privateKeyPEM))
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse orderer TLS certificate: %w", err)
-	}
+	// The admin certificate is also the TLS client certificate
+	ordererTLSCertParsed := ordererMSP.TLSCertificate()
 
 	ordererBlock, err := channel.GetConfigBlockFromOrderer(ctx, ordererConn, ordererMSP, channelID, ordererTLSCertParsed)
 	if err != nil {
@@ -135,32 +107,8 @@ func (s *FabricOrg) getAdminIdentity(ctx context.Context) (identity.SigningIdent
 		return nil, fmt.Errorf("organization has no signing key")
 	}
 
-	// Get admin signing key
-	adminSignKey, err := s.keyMgmtService.GetKey(ctx, int(org.AdminSignKeyID.Int64))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get admin sign key: %w", err)
-	}
-	if adminSignKey.Certificate == nil {
-		return nil, fmt.Errorf("admin sign key has no certificate")
-	}
-
-	// Get private key from key management service
-	privateKeyPEM, err := s.keyMgmtService.GetDecryptedPrivateKey(int(org.AdminSignKeyID.Int64))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get private key: %w", err)
-	}
-
-	cert, err := gwidentity.CertificateFromPEM([]byte(*adminSignKey.Certificate))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read certificate: %w", err)
-	}
-
-	priv, err := gwidentity.PrivateKeyFromPEM([]byte(privateKeyPEM))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read private key: %w", err)
-	}
-
-	signingIdentity, err := identity.NewPrivateKeySigningIdentity(s.mspID, cert, priv)
+	// The private key stays in the key provider, which signs for the identity
+	signingIdentity, err := s.keyMgmtService.GetSigningIdentity(ctx, int(org.AdminSignKeyID.Int64), s.mspID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create signing identity: %w", err)
 	}
@@ -170,46 +118,10 @@ func (s *FabricOrg) getAdminIdentity(ctx context.Context) (identity.SigningIdent
 
 // getOrdererMSP creates a signing identity for interacting with the orderer
 func (s *FabricOrg) getOrdererMSP(ctx context.Context) (identity.SigningIdentity, error) {
-	// Get organization details
-	org, err := s.orgService.GetOrganizationByMspID(ctx, s.mspID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get organization: %w", err)
-	}
-
-	if !org.AdminSignKeyID.Valid {
-		return nil, fmt.Errorf("organization has no signing key")
-	}
-
-	// Get admin signing key
-	adminSignKey, err := s.keyMgmtService.GetKey(ctx, int(org.AdminSignKeyID.Int64))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get admin sign key: %w", err)
-	}
-	if adminSignKey.Certificate == nil {
-		return nil, fmt.Errorf("admin sign key has no certificate")
-	}
-
-	// Get private key from key management service
-	privateKeyPEM, err := s.keyMgmtService.GetDecryptedPrivateKey(int(org.AdminSignKeyID.Int64))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get private key: %w", err)
-	}
-
-	cert, err := gwidentity.CertificateFromPEM([]byte(*adminSignKey.Certificate))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read certificate: %w", err)
-	}
-
-	priv, err := gwidentity.PrivateKeyFromPEM([]byte(privateKeyPEM))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read private key: %w", err)
-	}
-
-	ordererMSP, err := identity.NewPrivateKeySigningIdentity(s.mspID, cert, priv)
+	ordererMSP, err := s.getAdminIdentity(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create orderer msp: %w", err)
 	}
-
 	return ordererMSP, nil
 }
 
@@ -228,33 +140,6 @@ func (s *FabricOrg) getOrdererConnection(ctx context.Context, ordererURL string,
 	return ordererConn, nil
 }
 
-// getOrdererTLSKeyPair creates a TLS key pair for secure communication with the orderer
-func (s *FabricOrg) getOrdererTLSKeyPair(ctx context.Context, ordererTLSCert string) (tls.Certificate, error) {
-	// Get organization details
-	org, err := s.orgService.GetOrganizationByMspID(ctx, s.mspID)
-	if err != nil {
-		return tls.Certificate{}, fmt.Errorf("failed to get organization: %w", err)
-	}
-
-	if !org.AdminSignKeyID.Valid {
-		return tls.Certificate{}, fmt.Errorf("organization has no admin sign key")
-	}
-
-	// Get private key from key management service
-	privateKeyPEM, err := s.keyMgmtService.GetDecryptedPrivateKey(int(org.AdminSignKeyID.Int64))
-	if err != nil {
-		return tls.Certificate{}, fmt.Errorf("failed to get private key: %w", err)
-	}
-
-	// Parse the orderer TLS certificate
-	ordererTLSCertParsed, err := tls.X509KeyPair([]byte(ordererTLSCert), []byte(privateKeyPEM))
-	if err != nil {
-		return tls.Certificate{}, fmt.Errorf("failed to parse orderer TLS certificate: %w", err)
-	}
-
-	return ordererTLSCertParsed, nil
-}
-
 // GetGenesisBlock fetches the genesis block for a channel from the orderer
 func (s *FabricOrg) GetGenesisBlock(ctx context.Context, channelID string, ordererURL string, ordererTLSCert []byte) ([]byte, error) {
 	s.logger.Info("Fetching genesis block with network config",
@@ -332,6 +217,22 @@ func (s *FabricOrg) CreateConfigSignature(ctx context.Context, channelID string,
 	return signature, nil
 }
 
+// CreateConfigUpdateEnvelope wraps a config update envelope, including the signatures
+// already collected for it, in an envelope signed by the organization's admin so it
+// can be broadcast to the ordering service
+func (s *FabricOrg) CreateConfigUpdateEnvelope(ctx context.Context, channelID string, configUpdateEnv *cb.ConfigUpdateEnvelope) (*cb.Envelope, error) {
+	signingIdentity, err := s.getAdminIdentity(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signing identity: %w", err)
+	}
+
+	envelope, err := protoutil.CreateSignedEnvelope(cb.HeaderType_CONFIG_UPDATE, channelID, signingIdentity, configUpdateEnv, msgVersion, epoch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signed envelope: %w", err)
+	}
+	return envelope, nil
+}
+
 const (
 	msgVersion = int32(0)
 	epoch      = 0
@@ -477,22 +378,10 @@ func (s *FabricOrg) GetCRL(ctx context.Context) ([]byte, error) {
 		return nil, fmt.Errorf("failed to parse certificate: %w", err)
 	}
 
-	// Get private key from key management service
-	privateKeyPEM, err := s.keyMgmtService.GetDecryptedPrivateKey(int(org.SignKeyID.Int64))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get private key: %w", err)
-	}
-
-	// Parse the private key
-	priv, err := gwidentity.PrivateKeyFromPEM([]byte(privateKeyPEM))
+	// The CA key signs in its provider, it may not be exportable
+	signer, err := s.keyMgmtService.GetSigner(ctx, int(org.SignKeyID.Int64))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %w", err)
-	}
-
-	// Cast private key to crypto.Signer
-	signer, ok := priv.(crypto.Signer)
-	if !ok {
-		return nil, fmt.Errorf("private key does not implement crypto.Signer")
+		return nil, fmt.Errorf("failed to get signer: %w", err)
 	}
 
 	// Create CRL
@@ -0,0 +1,575 @@
+package fabric
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/internal/protoutil"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/fabric/org"
+	"github.com/hyperledger/fabric-config/protolator"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	mspproto "github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	ordererapi "github.com/hyperledger/fabric-protos-go-apiv2/orderer"
+	"google.golang.org/protobuf/proto"
+)
+
+// ExtractConfigUpdate returns the marshaled config update carried by a config update envelope
+func ExtractConfigUpdate(envelopeBytes []byte) ([]byte, error) {
+	envelope := &cb.Envelope{}
+	if err := proto.Unmarshal(envelopeBytes, envelope); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal envelope: %w", err)
+	}
+	payload, err := protoutil.UnmarshalPayload(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+	configUpdateEnv, err := protoutil.UnmarshalConfigUpdateEnvelope(payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config update envelope: %w", err)
+	}
+	return configUpdateEnv.ConfigUpdate, nil
+}
+
+// ConfigUpdateToJSON renders a marshaled config update as JSON
+func ConfigUpdateToJSON(configUpdateBytes []byte) (string, error) {
+	configUpdate := &cb.ConfigUpdate{}
+	if err := proto.Unmarshal(configUpdateBytes, configUpdate); err != nil {
+		return "", fmt.Errorf("failed to unmarshal config update: %w", err)
+	}
+	buffer := &bytes.Buffer{}
+	if err := protolator.DeepMarshalJSON(buffer, configUpdate); err != nil {
+		return "", fmt.Errorf("failed to marshal config update to JSON: %w", err)
+	}
+	return buffer.String(), nil
+}
+
+// SignConfigUpdate signs a config update with the admin identity of a local organization
+// and returns the marshaled config signature, which can be shared with other parties
+func (d *FabricDeployer) SignConfigUpdate(ctx context.Context, channelName string, mspID string, configUpdateBytes []byte) ([]byte, error) {
+	configUpdate := &cb.ConfigUpdate{}
+	if err := proto.Unmarshal(configUpdateBytes, configUpdate); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config update: %w", err)
+	}
+	envelopeBytes, err := CreateConfigUpdateEnvelope(channelName, configUpdate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config update envelope: %w", err)
+	}
+	envelope := &cb.Envelope{}
+	if err := proto.Unmarshal(envelopeBytes, envelope); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config update envelope: %w", err)
+	}
+
+	orgService := org.NewOrganizationService(d.orgService, d.keyMgmt, d.logger, mspID, d.db)
+	signed, err := orgService.CreateConfigSignature(ctx, channelName, envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign config update for org %s: %w", mspID, err)
+	}
+
+	payload, err := protoutil.UnmarshalPayload(signed.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal signed payload: %w", err)
+	}
+	configUpdateEnv, err := protoutil.UnmarshalConfigUpdateEnvelope(payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal signed config update envelope: %w", err)
+	}
+	if len(configUpdateEnv.Signatures) == 0 {
+		return nil, fmt.Errorf("no signature was added to the config update")
+	}
+
+	signature, err := proto.Marshal(configUpdateEnv.Signatures[len(configUpdateEnv.Signatures)-1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config signature: %w", err)
+	}
+	return signature, nil
+}
+
+// VerifyConfigSignature checks that a marshaled config signature is a valid signature over
+// the config update by an admin of an organization of the channel, and returns the MSP
+// ID of the signer. The signer certificate has to chain to the root or intermediate CAs
+// of the MSP it claims in the channel config, must not be revoked, and has to be one of
+// the MSP admins or carry the admin OU when node OUs are enabled.
+func VerifyConfigSignature(config *cb.Config, configUpdateBytes []byte, signatureBytes []byte) (string, error) {
+	configSig := &cb.ConfigSignature{}
+	if err := proto.Unmarshal(signatureBytes, configSig); err != nil {
+		return "", fmt.Errorf("failed to unmarshal config signature: %w", err)
+	}
+	sigHeader, err := protoutil.UnmarshalSignatureHeader(configSig.SignatureHeader)
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal signature header: %w", err)
+	}
+	creator := &mspproto.SerializedIdentity{}
+	if err := proto.Unmarshal(sigHeader.Creator, creator); err != nil {
+		return "", fmt.Errorf("failed to unmarshal signer identity: %w", err)
+	}
+
+	block, _ := pem.Decode(creator.IdBytes)
+	if block == nil {
+		return "", fmt.Errorf("signer identity of %s is not a PEM certificate", creator.Mspid)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse signer certificate: %w", err)
+	}
+
+	message := append(append([]byte{}, configSig.SignatureHeader...), configUpdateBytes...)
+	switch pub := cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(message)
+		if !ecdsa.VerifyASN1(pub, digest[:], configSig.Signature) {
+			return "", fmt.Errorf("invalid signature from %s", creator.Mspid)
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(pub, message, configSig.Signature) {
+			return "", fmt.Errorf("invalid signature from %s", creator.Mspid)
+		}
+	default:
+		return "", fmt.Errorf("unsupported signer key type %T", cert.PublicKey)
+	}
+
+	mspConfig, err := findMSPConfig(config, creator.Mspid)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyMSPAdmin(mspConfig, cert); err != nil {
+		return "", fmt.Errorf("signer is not an admin of %s: %w", creator.Mspid, err)
+	}
+
+	return creator.Mspid, nil
+}
+
+// VerifiedConfigSignature is a config update signature verified against a channel config
+type VerifiedConfigSignature struct {
+	MSPID     string
+	Signature []byte
+}
+
+// VerifyConfigSignatures verifies the signatures of a config update against the channel
+// config and keeps the first valid signature of each MSP, so that policies are evaluated
+// and the update is submitted with neither invalid nor repeated signatures. reject is
+// called with the index and the reason of each signature that is left out.
+func VerifyConfigSignatures(config *cb.Config, configUpdateBytes []byte, signatures [][]byte, reject func(i int, err error)) []VerifiedConfigSignature {
+	verified := make([]VerifiedConfigSignature, 0, len(signatures))
+	seen := make(map[string]bool, len(signatures))
+	for i, signature := range signatures {
+		mspID, err := VerifyConfigSignature(config, configUpdateBytes, signature)
+		if err == nil && seen[mspID] {
+			err = fmt.Errorf("%s already signed", mspID)
+		}
+		if err != nil {
+			if reject != nil {
+				reject(i, err)
+			}
+			continue
+		}
+		seen[mspID] = true
+		verified = append(verified, VerifiedConfigSignature{MSPID: mspID, Signature: signature})
+	}
+	return verified
+}
+
+// findMSPConfig returns the definition of an MSP from the organizations of the channel
+// config
+func findMSPConfig(config *cb.Config, mspID string) (*mspproto.FabricMSPConfig, error) {
+	if config == nil || config.ChannelGroup == nil {
+		return nil, fmt.Errorf("channel config is empty")
+	}
+	var orgGroups []*cb.ConfigGroup
+	for _, name := range []string{"Application", "Orderer"} {
+		if group, ok := config.ChannelGroup.Groups[name]; ok {
+			for _, orgGroup := range group.Groups {
+				orgGroups = append(orgGroups, orgGroup)
+			}
+		}
+	}
+	if consortiums, ok := config.ChannelGroup.Groups["Consortiums"]; ok {
+		for _, consortium := range consortiums.Groups {
+			for _, orgGroup := range consortium.Groups {
+				orgGroups = append(orgGroups, orgGroup)
+			}
+		}
+	}
+
+	for _, orgGroup := range orgGroups {
+		value, ok := orgGroup.Values["MSP"]
+		if !ok {
+			continue
+		}
+		mspConfig := &mspproto.MSPConfig{}
+		if err := proto.Unmarshal(value.Value, mspConfig); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal MSP config: %w", err)
+		}
+		// Only FABRIC MSPs (type 0) are defined by certificates
+		if mspConfig.Type != 0 {
+			continue
+		}
+		fabricConfig := &mspproto.FabricMSPConfig{}
+		if err := proto.Unmarshal(mspConfig.Config, fabricConfig); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal fabric MSP config: %w", err)
+		}
+		if fabricConfig.Name == mspID {
+			return fabricConfig, nil
+		}
+	}
+	return nil, fmt.Errorf("MSP %s is not an organization of the channel", mspID)
+}
+
+// verifyMSPAdmin validates a certificate against an MSP the way the peers do: it has to
+// chain to one of the MSP CAs and not be revoked, and it has to be an admin
+func verifyMSPAdmin(mspConfig *mspproto.FabricMSPConfig, cert *x509.Certificate) error {
+	roots := x509.NewCertPool()
+	for _, rootPEM := range mspConfig.RootCerts {
+		rootCert, err := parsePEMCertificate(rootPEM)
+		if err != nil {
+			return fmt.Errorf("invalid root certificate: %w", err)
+		}
+		roots.AddCert(rootCert)
+	}
+	intermediates := x509.NewCertPool()
+	for _, intermediatePEM := range mspConfig.IntermediateCerts {
+		intermediateCert, err := parsePEMCertificate(intermediatePEM)
+		if err != nil {
+			return fmt.Errorf("invalid intermediate certificate: %w", err)
+		}
+		intermediates.AddCert(intermediateCert)
+	}
+
+	// Like the MSP, validate as of the start of the validity period, expiration
+	// isn't part of identity validation
+	chains, err := cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   cert.NotBefore.Add(time.Second),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("certificate is not issued by the MSP: %w", err)
+	}
+
+	for _, crlPEM := range mspConfig.RevocationList {
+		block, _ := pem.Decode(crlPEM)
+		if block == nil {
+			continue
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("invalid revocation list: %w", err)
+		}
+		for _, chain := range chains {
+			if len(chain) < 2 || !bytes.Equal(crl.RawIssuer, chain[1].RawSubject) {
+				continue
+			}
+			if crl.CheckSignatureFrom(chain[1]) != nil {
+				continue
+			}
+			for _, entry := range crl.RevokedCertificateEntries {
+				if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+					return fmt.Errorf("certificate has been revoked")
+				}
+			}
+		}
+	}
+
+	for _, adminPEM := range mspConfig.Admins {
+		adminCert, err := parsePEMCertificate(adminPEM)
+		if err == nil && bytes.Equal(adminCert.Raw, cert.Raw) {
+			return nil
+		}
+	}
+	nodeOUs := mspConfig.FabricNodeOus
+	if nodeOUs != nil && nodeOUs.Enable && nodeOUs.AdminOuIdentifier != nil {
+		for _, ou := range cert.Subject.OrganizationalUnit {
+			if ou == nodeOUs.AdminOuIdentifier.OrganizationalUnitIdentifier {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("certificate is neither an admin certificate nor has the admin OU")
+}
+
+func parsePEMCertificate(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode certificate PEM")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// SubmitConfigUpdate broadcasts a config update together with the signatures collected
+// for it. The envelope itself is signed by the admin of submitterMSPID.
+func (d *FabricDeployer) SubmitConfigUpdate(ctx context.Context, channelName string, configUpdateBytes []byte, signatures [][]byte, submitterMSPID string, ordererAddress string, ordererTLSCert string) (string, error) {
+	configUpdateEnv := &cb.ConfigUpdateEnvelope{ConfigUpdate: configUpdateBytes}
+	for _, signatureBytes := range signatures {
+		configSig := &cb.ConfigSignature{}
+		if err := proto.Unmarshal(signatureBytes, configSig); err != nil {
+			return "", fmt.Errorf("failed to unmarshal config signature: %w", err)
+		}
+		configUpdateEnv.Signatures = append(configUpdateEnv.Signatures, configSig)
+	}
+
+	orgService := org.NewOrganizationService(d.orgService, d.keyMgmt, d.logger, submitterMSPID, d.db)
+	envelope, err := orgService.CreateConfigUpdateEnvelope(ctx, channelName, configUpdateEnv)
+	if err != nil {
+		return "", fmt.Errorf("failed to create envelope for org %s: %w", submitterMSPID, err)
+	}
+
+	ordererConn, err := d.createOrdererConnection(ordererAddress, ordererTLSCert)
+	if err != nil {
+		return "", fmt.Errorf("failed to create orderer connection: %w", err)
+	}
+	defer ordererConn.Close()
+	ordererClient, err := ordererapi.NewAtomicBroadcastClient(ordererConn).Broadcast(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to create orderer client: %w", err)
+	}
+	if err := ordererClient.Send(envelope); err != nil {
+		return "", fmt.Errorf("failed to send envelope: %w", err)
+	}
+	response, err := ordererClient.Recv()
+	if err != nil {
+		return "", fmt.Errorf("failed to receive response: %w", err)
+	}
+	if response.Status != cb.Status_SUCCESS {
+		return "", fmt.Errorf("orderer rejected config update: %s %s", response.Status, response.Info)
+	}
+	return response.String(), nil
+}
+
+// ModPolicyEvaluation is the outcome of evaluating one of the modification policies a
+// config update has to satisfy
+type ModPolicyEvaluation struct {
+	// Path is the fully qualified policy path, e.g. /Channel/Application/Admins
+	Path string `json:"path"`
+	// Rule describes the policy, e.g. "MAJORITY Admins" or "SIGNATURE"
+	Rule string `json:"rule"`
+	// MSPIDs are the organizations whose signatures count towards the policy
+	MSPIDs    []string `json:"mspIds"`
+	Satisfied bool     `json:"satisfied"`
+}
+
+// EvaluateConfigUpdatePolicies determines the modification policies that guard the
+// elements changed by a config update, the same way the orderer does, and evaluates
+// each of them assuming the admins of the organizations in signers have signed.
+// Signatures are counted per organization and, as VerifyConfigSignature only accepts
+// admins, satisfy admin and member roles; other principals are ignored.
+func EvaluateConfigUpdatePolicies(config *cb.Config, configUpdateBytes []byte, signers []string) ([]ModPolicyEvaluation, error) {
+	configUpdate := &cb.ConfigUpdate{}
+	if err := proto.Unmarshal(configUpdateBytes, configUpdate); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config update: %w", err)
+	}
+	if config == nil || config.ChannelGroup == nil {
+		return nil, fmt.Errorf("channel config is empty")
+	}
+	if configUpdate.WriteSet == nil {
+		return nil, fmt.Errorf("config update has no write set")
+	}
+
+	e := &policyEvaluator{
+		root:    config.ChannelGroup,
+		signers: make(map[string]bool),
+	}
+	for _, mspID := range signers {
+		e.signers[mspID] = true
+	}
+
+	paths := make(map[string]bool)
+	e.collectModPolicies([]string{"Channel"}, configUpdate.ReadSet, configUpdate.WriteSet, config.ChannelGroup, paths)
+
+	sortedPaths := make([]string, 0, len(paths))
+	for path := range paths {
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.Strings(sortedPaths)
+
+	evaluations := make([]ModPolicyEvaluation, 0, len(sortedPaths))
+	for _, path := range sortedPaths {
+		parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+		mspIDs := make(map[string]bool)
+		satisfied, rule := e.evaluate(parts[:len(parts)-1], parts[len(parts)-1], mspIDs)
+		evaluation := ModPolicyEvaluation{
+			Path:      path,
+			Rule:      rule,
+			MSPIDs:    []string{},
+			Satisfied: satisfied,
+		}
+		for mspID := range mspIDs {
+			evaluation.MSPIDs = append(evaluation.MSPIDs, mspID)
+		}
+		sort.Strings(evaluation.MSPIDs)
+		evaluations = append(evaluations, evaluation)
+	}
+	return evaluations, nil
+}
+
+type policyEvaluator struct {
+	root    *cb.ConfigGroup
+	signers map[string]bool
+}
+
+// collectModPolicies walks the write set and records the mod policy of every existing
+// element whose version changes. New elements are covered by the version bump of
+// their parent group.
+func (e *policyEvaluator) collectModPolicies(path []string, read, write, current *cb.ConfigGroup, paths map[string]bool) {
+	if current == nil {
+		return
+	}
+	if read == nil || read.Version != write.Version {
+		paths[resolvePolicyPath(path, current.ModPolicy)] = true
+	}
+
+	for name, value := range write.Values {
+		existing, ok := current.Values[name]
+		if !ok {
+			continue
+		}
+		if read != nil {
+			if readValue, ok := read.Values[name]; ok && readValue.Version == value.Version {
+				continue
+			}
+		}
+		paths[resolvePolicyPath(path, existing.ModPolicy)] = true
+	}
+
+	for name, policy := range write.Policies {
+		existing, ok := current.Policies[name]
+		if !ok {
+			continue
+		}
+		if read != nil {
+			if readPolicy, ok := read.Policies[name]; ok && readPolicy.Version == policy.Version {
+				continue
+			}
+		}
+		paths[resolvePolicyPath(path, existing.ModPolicy)] = true
+	}
+
+	for name, group := range write.Groups {
+		var readGroup *cb.ConfigGroup
+		if read != nil {
+			readGroup = read.Groups[name]
+		}
+		e.collectModPolicies(append(append([]string{}, path...), name), readGroup, group, current.Groups[name], paths)
+	}
+}
+
+// resolvePolicyPath turns a mod policy into an absolute path. Relative policies are
+// resolved against the group the element belongs to.
+func resolvePolicyPath(groupPath []string, modPolicy string) string {
+	if strings.HasPrefix(modPolicy, "/") {
+		return modPolicy
+	}
+	return "/" + strings.Join(append(append([]string{}, groupPath...), modPolicy), "/")
+}
+
+// groupAt returns the config group at path, where path[0] is the channel group
+func (e *policyEvaluator) groupAt(path []string) *cb.ConfigGroup {
+	if len(path) == 0 {
+		return nil
+	}
+	group := e.root
+	for _, name := range path[1:] {
+		if group == nil {
+			return nil
+		}
+		group = group.Groups[name]
+	}
+	return group
+}
+
+// evaluate evaluates the policy name defined in the group at groupPath and records the
+// MSP IDs that can contribute to it
+func (e *policyEvaluator) evaluate(groupPath []string, name string, mspIDs map[string]bool) (bool, string) {
+	group := e.groupAt(groupPath)
+	if group == nil {
+		return false, "UNKNOWN"
+	}
+	configPolicy, ok := group.Policies[name]
+	if !ok || configPolicy.Policy == nil {
+		return false, "UNKNOWN"
+	}
+
+	switch configPolicy.Policy.Type {
+	case int32(cb.Policy_IMPLICIT_META):
+		implicitMeta := &cb.ImplicitMetaPolicy{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, implicitMeta); err != nil {
+			return false, "UNKNOWN"
+		}
+		total, satisfied := 0, 0
+		for childName, child := range group.Groups {
+			if _, ok := child.Policies[implicitMeta.SubPolicy]; !ok {
+				continue
+			}
+			total++
+			childPath := append(append([]string{}, groupPath...), childName)
+			if ok, _ := e.evaluate(childPath, implicitMeta.SubPolicy, mspIDs); ok {
+				satisfied++
+			}
+		}
+		var threshold int
+		switch implicitMeta.Rule {
+		case cb.ImplicitMetaPolicy_ANY:
+			threshold = 1
+		case cb.ImplicitMetaPolicy_ALL:
+			threshold = total
+		case cb.ImplicitMetaPolicy_MAJORITY:
+			threshold = total/2 + 1
+		}
+		return satisfied >= threshold, fmt.Sprintf("%s %s", implicitMeta.Rule, implicitMeta.SubPolicy)
+	case int32(cb.Policy_SIGNATURE):
+		envelope := &cb.SignaturePolicyEnvelope{}
+		if err := proto.Unmarshal(configPolicy.Policy.Value, envelope); err != nil {
+			return false, "UNKNOWN"
+		}
+		principals := make([]string, len(envelope.Identities))
+		for i, identity := range envelope.Identities {
+			if identity.PrincipalClassification != mspproto.MSPPrincipal_ROLE {
+				continue
+			}
+			role := &mspproto.MSPRole{}
+			if err := proto.Unmarshal(identity.Principal, role); err != nil {
+				continue
+			}
+			// Only admin signatures are accepted, they satisfy admin and member principals
+			if role.Role != mspproto.MSPRole_ADMIN && role.Role != mspproto.MSPRole_MEMBER {
+				continue
+			}
+			principals[i] = role.MspIdentifier
+			mspIDs[role.MspIdentifier] = true
+		}
+		return e.evaluateSignaturePolicy(envelope.Rule, principals), "SIGNATURE"
+	default:
+		return false, "UNKNOWN"
+	}
+}
+
+func (e *policyEvaluator) evaluateSignaturePolicy(rule *cb.SignaturePolicy, principals []string) bool {
+	if rule == nil {
+		return false
+	}
+	switch t := rule.Type.(type) {
+	case *cb.SignaturePolicy_SignedBy:
+		if int(t.SignedBy) >= len(principals) || principals[t.SignedBy] == "" {
+			return false
+		}
+		return e.signers[principals[t.SignedBy]]
+	case *cb.SignaturePolicy_NOutOf_:
+		satisfied := 0
+		for _, subRule := range t.NOutOf.Rules {
+			if e.evaluateSignaturePolicy(subRule, principals) {
+				satisfied++
+			}
+		}
+		return satisfied >= int(t.NOutOf.N)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,316 @@
+package fabric
+
+import (
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/internal/protoutil"
+	keymanagement "github.com/chainlaunch/chainlaunch/pkg/keymanagement/service"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/fabric/org"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	mspproto "github.com/hyperledger/fabric-protos-go-apiv2/msp"
+	"google.golang.org/protobuf/proto"
+)
+
+// tokenSigner stands in for a key held in an HSM: the private key can't be
+// read, and like raw PKCS#11 signatures its S values aren't normalized
+type tokenSigner struct {
+	key   *ecdsa.PrivateKey
+	signs int
+}
+
+func (s *tokenSigner) Public() gocrypto.PublicKey {
+	return &s.key.PublicKey
+}
+
+func (s *tokenSigner) Sign(_ io.Reader, digest []byte, _ gocrypto.SignerOpts) ([]byte, error) {
+	s.signs++
+	r, sv, err := ecdsa.Sign(rand.Reader, s.key, digest)
+	if err != nil {
+		return nil, err
+	}
+	// Always return the high-S form of the signature
+	n := s.key.Curve.Params().N
+	if sv.Cmp(new(big.Int).Rsh(n, 1)) <= 0 {
+		sv = new(big.Int).Sub(n, sv)
+	}
+	return asn1.Marshal(struct{ R, S *big.Int }{r, sv})
+}
+
+type testCA struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+	pem  []byte
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func newTestCA(t *testing.T, cn string) *testCA {
+	key := newTestKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create CA certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse CA certificate: %v", err)
+	}
+	return &testCA{key: key, cert: cert, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue creates a certificate for key, with ou as organizational unit when set
+func (ca *testCA) issue(t *testing.T, key *ecdsa.PrivateKey, cn string, ou string) []byte {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if ou != "" {
+		template.Subject.OrganizationalUnit = []string{ou}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newSelfSignedCertificate(t *testing.T, key *ecdsa.PrivateKey, cn string, ou string) []byte {
+	return (&testCA{key: key, cert: &x509.Certificate{Subject: pkix.Name{CommonName: cn}}}).issue(t, key, cn, ou)
+}
+
+// newTestChannelConfig returns a channel config with an application organization for
+// each MSP, with node OUs enabled and an "admin" admin OU
+func newTestChannelConfig(t *testing.T, msps map[string]*mspproto.FabricMSPConfig) *cb.Config {
+	orgs := map[string]*cb.ConfigGroup{}
+	for mspID, fabricConfig := range msps {
+		fabricConfig.Name = mspID
+		fabricConfig.FabricNodeOus = &mspproto.FabricNodeOUs{
+			Enable:             true,
+			AdminOuIdentifier:  &mspproto.FabricOUIdentifier{OrganizationalUnitIdentifier: "admin"},
+			ClientOuIdentifier: &mspproto.FabricOUIdentifier{OrganizationalUnitIdentifier: "client"},
+		}
+		fabricConfigBytes, err := proto.Marshal(fabricConfig)
+		if err != nil {
+			t.Fatalf("failed to marshal MSP config: %v", err)
+		}
+		mspConfigBytes, err := proto.Marshal(&mspproto.MSPConfig{Type: 0, Config: fabricConfigBytes})
+		if err != nil {
+			t.Fatalf("failed to marshal MSP config: %v", err)
+		}
+		orgs[mspID] = &cb.ConfigGroup{Values: map[string]*cb.ConfigValue{"MSP": {Value: mspConfigBytes}}}
+	}
+	return &cb.Config{ChannelGroup: &cb.ConfigGroup{
+		Groups: map[string]*cb.ConfigGroup{"Application": {Groups: orgs}},
+	}}
+}
+
+// signTestConfigUpdate signs a config update with the identity and returns the
+// marshaled config update and config signature
+func signTestConfigUpdate(t *testing.T, signingIdentity *keymanagement.SigningIdentity) ([]byte, []byte) {
+	configUpdate := &cb.ConfigUpdate{
+		ChannelId: "mychannel",
+		ReadSet:   &cb.ConfigGroup{Version: 1},
+		WriteSet:  &cb.ConfigGroup{Version: 2},
+	}
+	envelopeBytes, err := CreateConfigUpdateEnvelope("mychannel", configUpdate)
+	if err != nil {
+		t.Fatalf("failed to create config update envelope: %v", err)
+	}
+	envelope := &cb.Envelope{}
+	if err := proto.Unmarshal(envelopeBytes, envelope); err != nil {
+		t.Fatalf("failed to unmarshal envelope: %v", err)
+	}
+
+	signed, err := org.SignConfigTx("mychannel", envelope, signingIdentity)
+	if err != nil {
+		t.Fatalf("failed to sign config update: %v", err)
+	}
+	payload, err := protoutil.UnmarshalPayload(signed.Payload)
+	if err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	configUpdateEnv, err := protoutil.UnmarshalConfigUpdateEnvelope(payload.Data)
+	if err != nil {
+		t.Fatalf("failed to unmarshal config update envelope: %v", err)
+	}
+	if len(configUpdateEnv.Signatures) != 1 {
+		t.Fatalf("expected 1 signature, got %d", len(configUpdateEnv.Signatures))
+	}
+	signatureBytes, err := proto.Marshal(configUpdateEnv.Signatures[0])
+	if err != nil {
+		t.Fatalf("failed to marshal config signature: %v", err)
+	}
+	return configUpdateEnv.ConfigUpdate, signatureBytes
+}
+
+func newTestSigningIdentity(t *testing.T, mspID string, certPEM []byte, key *ecdsa.PrivateKey) *keymanagement.SigningIdentity {
+	signingIdentity, err := keymanagement.NewSigningIdentity(mspID, certPEM, key)
+	if err != nil {
+		t.Fatalf("failed to create signing identity: %v", err)
+	}
+	return signingIdentity
+}
+
+func TestSignConfigUpdateWithHSMKey(t *testing.T) {
+	ca := newTestCA(t, "ca.org1")
+	config := newTestChannelConfig(t, map[string]*mspproto.FabricMSPConfig{
+		"Org1MSP": {RootCerts: [][]byte{ca.pem}},
+	})
+	key := newTestKey(t)
+	signer := &tokenSigner{key: key}
+	signingIdentity, err := keymanagement.NewSigningIdentity("Org1MSP", ca.issue(t, key, "admin", "admin"), signer)
+	if err != nil {
+		t.Fatalf("failed to create signing identity: %v", err)
+	}
+
+	configUpdateBytes, signatureBytes := signTestConfigUpdate(t, signingIdentity)
+	if signer.signs == 0 {
+		t.Fatal("config update was not signed by the token")
+	}
+
+	configSig := &cb.ConfigSignature{}
+	if err := proto.Unmarshal(signatureBytes, configSig); err != nil {
+		t.Fatalf("failed to unmarshal config signature: %v", err)
+	}
+	var sig struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(configSig.Signature, &sig); err != nil {
+		t.Fatalf("failed to parse signature: %v", err)
+	}
+	if sig.S.Cmp(new(big.Int).Rsh(key.Curve.Params().N, 1)) > 0 {
+		t.Fatal("signature is not in low-S form")
+	}
+
+	mspID, err := VerifyConfigSignature(config, configUpdateBytes, signatureBytes)
+	if err != nil {
+		t.Fatalf("signature of the HSM key was rejected: %v", err)
+	}
+	if mspID != "Org1MSP" {
+		t.Fatalf("expected signer Org1MSP, got %s", mspID)
+	}
+}
+
+func TestVerifyConfigSignatureRejectsForgedMSP(t *testing.T) {
+	org1CA, org2CA := newTestCA(t, "ca.org1"), newTestCA(t, "ca.org2")
+	config := newTestChannelConfig(t, map[string]*mspproto.FabricMSPConfig{
+		"Org1MSP": {RootCerts: [][]byte{org1CA.pem}},
+		"Org2MSP": {RootCerts: [][]byte{org2CA.pem}},
+	})
+	key := newTestKey(t)
+
+	// An admin of Org2 is accepted for its own MSP
+	org2Admin := org2CA.issue(t, key, "admin", "admin")
+	configUpdateBytes, signatureBytes := signTestConfigUpdate(t, newTestSigningIdentity(t, "Org2MSP", org2Admin, key))
+	if mspID, err := VerifyConfigSignature(config, configUpdateBytes, signatureBytes); err != nil || mspID != "Org2MSP" {
+		t.Fatalf("expected a valid Org2MSP signature, got %q, %v", mspID, err)
+	}
+
+	forged := map[string][]byte{
+		"certificate of another MSP": org2Admin,
+		"self-signed certificate":    newSelfSignedCertificate(t, key, "admin", "admin"),
+	}
+	for name, certPEM := range forged {
+		configUpdateBytes, signatureBytes := signTestConfigUpdate(t, newTestSigningIdentity(t, "Org1MSP", certPEM, key))
+		if mspID, err := VerifyConfigSignature(config, configUpdateBytes, signatureBytes); err == nil {
+			t.Errorf("%s: signature claiming Org1MSP was accepted for %s", name, mspID)
+		}
+	}
+
+	// An MSP that isn't part of the channel
+	configUpdateBytes, signatureBytes = signTestConfigUpdate(t, newTestSigningIdentity(t, "Org3MSP", org2Admin, key))
+	if _, err := VerifyConfigSignature(config, configUpdateBytes, signatureBytes); err == nil {
+		t.Error("signature of an unknown MSP was accepted")
+	}
+}
+
+func TestVerifyConfigSignatureRequiresAdmin(t *testing.T) {
+	ca := newTestCA(t, "ca.org1")
+	key := newTestKey(t)
+	client := ca.issue(t, key, "user", "client")
+	listedAdmin := ca.issue(t, key, "listed-admin", "")
+
+	config := newTestChannelConfig(t, map[string]*mspproto.FabricMSPConfig{
+		"Org1MSP": {RootCerts: [][]byte{ca.pem}, Admins: [][]byte{listedAdmin}},
+	})
+
+	configUpdateBytes, signatureBytes := signTestConfigUpdate(t, newTestSigningIdentity(t, "Org1MSP", client, key))
+	if _, err := VerifyConfigSignature(config, configUpdateBytes, signatureBytes); err == nil {
+		t.Error("signature of a client identity was accepted")
+	}
+
+	configUpdateBytes, signatureBytes = signTestConfigUpdate(t, newTestSigningIdentity(t, "Org1MSP", listedAdmin, key))
+	if _, err := VerifyConfigSignature(config, configUpdateBytes, signatureBytes); err != nil {
+		t.Errorf("signature of an admin certificate of the MSP was rejected: %v", err)
+	}
+}
+
+func TestSigningIdentityRejectsOtherKey(t *testing.T) {
+	key, other := newTestKey(t), newTestKey(t)
+	if _, err := keymanagement.NewSigningIdentity("Org1MSP", newSelfSignedCertificate(t, key, "admin", ""), &tokenSigner{key: other}); err == nil {
+		t.Fatal("expected a signer that doesn't match the certificate to be rejected")
+	}
+}
+
+func TestVerifyConfigSignaturesKeepsOneValidSignaturePerMSP(t *testing.T) {
+	org1CA, org2CA := newTestCA(t, "ca.org1"), newTestCA(t, "ca.org2")
+	config := newTestChannelConfig(t, map[string]*mspproto.FabricMSPConfig{
+		"Org1MSP": {RootCerts: [][]byte{org1CA.pem}},
+		"Org2MSP": {RootCerts: [][]byte{org2CA.pem}},
+	})
+	sign := func(mspID string, ca *testCA, ou string) ([]byte, []byte) {
+		key := newTestKey(t)
+		return signTestConfigUpdate(t, newTestSigningIdentity(t, mspID, ca.issue(t, key, "signer", ou), key))
+	}
+
+	configUpdateBytes, org1Admin := sign("Org1MSP", org1CA, "admin")
+	_, otherOrg1Admin := sign("Org1MSP", org1CA, "admin")
+	_, org2Client := sign("Org2MSP", org2CA, "client")
+	_, org2Admin := sign("Org2MSP", org2CA, "admin")
+	signatures := [][]byte{org1Admin, otherOrg1Admin, org2Client, []byte("not a signature"), org2Admin}
+
+	rejected := map[int]bool{}
+	verified := VerifyConfigSignatures(config, configUpdateBytes, signatures, func(i int, err error) {
+		rejected[i] = true
+	})
+	if len(verified) != 2 || verified[0].MSPID != "Org1MSP" || verified[1].MSPID != "Org2MSP" {
+		t.Fatalf("expected one signature of Org1MSP and Org2MSP, got %+v", verified)
+	}
+	if string(verified[0].Signature) != string(org1Admin) || string(verified[1].Signature) != string(org2Admin) {
+		t.Error("expected the first valid signature of each MSP to be kept")
+	}
+	for _, i := range []int{1, 2, 3} {
+		if !rejected[i] {
+			t.Errorf("expected signature %d to be rejected", i)
+		}
+	}
+	if len(rejected) != 3 {
+		t.Errorf("expected 3 rejected signatures, got %d", len(rejected))
+	}
+}
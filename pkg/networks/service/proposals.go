@@ -0,0 +1,551 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/fabric"
+	"github.com/google/uuid"
+	cb "github.com/hyperledger/fabric-protos-go-apiv2/common"
+	"google.golang.org/protobuf/proto"
+)
+
+// Proposal statuses
+const (
+	ProposalStatusPending   = "pending"
+	ProposalStatusSubmitted = "submitted"
+	ProposalStatusCancelled = "cancelled"
+)
+
+var (
+	// ErrProposalNotFound is returned when a proposal does not exist
+	ErrProposalNotFound = errors.New("proposal not found")
+	// ErrProposalNotPending is returned when a proposal can no longer be signed or submitted
+	ErrProposalNotPending = errors.New("proposal is not pending")
+	// ErrProposalNotReady is returned when submitting a proposal whose modification
+	// policies are not satisfied yet
+	ErrProposalNotReady = errors.New("proposal does not satisfy the channel modification policy")
+)
+
+// ProposalExport is the portable form of a proposal. It is exchanged between
+// ChainLaunch instances so organizations managed elsewhere can review and sign the
+// config update.
+type ProposalExport struct {
+	ID           string                         `json:"id"`
+	ChannelName  string                         `json:"channel_name"`
+	CreatedBy    string                         `json:"created_by"`
+	Operations   []ConfigUpdateOperationRequest `json:"operations"`
+	ConfigUpdate []byte                         `json:"config_update"`
+	Signatures   []ProposalSignature            `json:"signatures"`
+}
+
+// CreateFabricProposal computes the config update for the given operations and stores
+// it as a proposal waiting for signatures. Nothing is signed or submitted.
+func (s *NetworkService) CreateFabricProposal(ctx context.Context, networkID int64, operations []fabric.ConfigUpdateOperation, createdBy string) (*Proposal, error) {
+	fabricDeployer, err := s.getFabricDeployerForNetwork(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	update, err := fabricDeployer.PrepareConfigUpdate(ctx, networkID, operations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare config update: %w", err)
+	}
+
+	configUpdate, err := fabric.ExtractConfigUpdate(update.ConfigUpdateEnvelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract config update: %w", err)
+	}
+
+	operationRequests := make([]ConfigUpdateOperationRequest, len(operations))
+	for i, op := range operations {
+		operationRequests[i] = ConfigUpdateOperationRequest{
+			Type:    string(op.Type),
+			Payload: op.Payload,
+		}
+	}
+
+	dbProposal, err := s.storeProposal(ctx, update.ID, networkID, update.ChannelName, createdBy, operationRequests, configUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapProposal(ctx, fabricDeployer, dbProposal)
+}
+
+// GetFabricProposal returns a proposal with its signatures and the current state of
+// the modification policies it has to satisfy
+func (s *NetworkService) GetFabricProposal(ctx context.Context, proposalID string) (*Proposal, error) {
+	dbProposal, err := s.getProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+
+	fabricDeployer, err := s.getFabricDeployerForNetwork(ctx, dbProposal.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapProposal(ctx, fabricDeployer, dbProposal)
+}
+
+// ListFabricProposals returns the proposals of a network, newest first
+func (s *NetworkService) ListFabricProposals(ctx context.Context, networkID int64) ([]*Proposal, error) {
+	fabricDeployer, err := s.getFabricDeployerForNetwork(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	dbProposals, err := s.db.ListProposalsByNetwork(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proposals: %w", err)
+	}
+
+	proposals := make([]*Proposal, 0, len(dbProposals))
+	for _, dbProposal := range dbProposals {
+		proposal, err := s.mapProposal(ctx, fabricDeployer, dbProposal)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, proposal)
+	}
+	return proposals, nil
+}
+
+// SignFabricProposal signs a proposal with the admin identity of a local organization
+func (s *NetworkService) SignFabricProposal(ctx context.Context, proposalID string, mspID string, signedBy string) (*Proposal, error) {
+	dbProposal, err := s.getProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if dbProposal.Status != ProposalStatusPending {
+		return nil, ErrProposalNotPending
+	}
+
+	fabricDeployer, err := s.getFabricDeployerForNetwork(ctx, dbProposal.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := fabricDeployer.SignConfigUpdate(ctx, dbProposal.ChannelName, mspID, dbProposal.ConfigUpdate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign proposal: %w", err)
+	}
+
+	if err := s.addProposalSignature(ctx, fabricDeployer, dbProposal, ProposalSignature{
+		MSPID:     mspID,
+		SignedBy:  signedBy,
+		Signature: signature,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.mapProposal(ctx, fabricDeployer, dbProposal)
+}
+
+// AddFabricProposalSignature attaches a signature produced by another instance. The
+// signature is verified against the config update and the MSP definitions of the
+// current channel config, and recorded for the MSP of the signing identity.
+func (s *NetworkService) AddFabricProposalSignature(ctx context.Context, proposalID string, signature []byte, signedBy string) (*Proposal, error) {
+	dbProposal, err := s.getProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if dbProposal.Status != ProposalStatusPending {
+		return nil, ErrProposalNotPending
+	}
+
+	fabricDeployer, err := s.getFabricDeployerForNetwork(ctx, dbProposal.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.addProposalSignature(ctx, fabricDeployer, dbProposal, ProposalSignature{
+		SignedBy:  signedBy,
+		Signature: signature,
+	}); err != nil {
+		return nil, err
+	}
+	return s.mapProposal(ctx, fabricDeployer, dbProposal)
+}
+
+// ExportFabricProposal returns the unsigned config update of a proposal together with
+// the signatures collected so far
+func (s *NetworkService) ExportFabricProposal(ctx context.Context, proposalID string) (*ProposalExport, error) {
+	dbProposal, err := s.getProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+
+	var operations []ConfigUpdateOperationRequest
+	if err := json.Unmarshal([]byte(dbProposal.Operations), &operations); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal operations: %w", err)
+	}
+
+	signatures, err := s.getProposalSignatures(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProposalExport{
+		ID:           dbProposal.ID,
+		ChannelName:  dbProposal.ChannelName,
+		CreatedBy:    dbProposal.CreatedBy,
+		Operations:   operations,
+		ConfigUpdate: dbProposal.ConfigUpdate,
+		Signatures:   signatures,
+	}, nil
+}
+
+// ImportFabricProposal imports a proposal exported by another instance. If the
+// proposal already exists its signatures are merged, so exports can be passed back
+// and forth until every organization has signed.
+func (s *NetworkService) ImportFabricProposal(ctx context.Context, networkID int64, export *ProposalExport) (*Proposal, error) {
+	fabricDeployer, err := s.getFabricDeployerForNetwork(ctx, networkID)
+	if err != nil {
+		return nil, err
+	}
+	network, err := s.db.GetNetwork(ctx, networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network: %w", err)
+	}
+	if export.ChannelName != network.Name {
+		return nil, fmt.Errorf("proposal targets channel %s, network %d is channel %s", export.ChannelName, networkID, network.Name)
+	}
+	if len(export.ConfigUpdate) == 0 {
+		return nil, fmt.Errorf("proposal has no config update")
+	}
+	configUpdate := &cb.ConfigUpdate{}
+	if err := proto.Unmarshal(export.ConfigUpdate, configUpdate); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config update: %w", err)
+	}
+	if configUpdate.ChannelId != network.Name {
+		return nil, fmt.Errorf("config update targets channel %s, network %d is channel %s", configUpdate.ChannelId, networkID, network.Name)
+	}
+
+	dbProposal, err := s.db.GetProposal(ctx, export.ID)
+	switch {
+	case err == nil:
+		if dbProposal.NetworkID != networkID {
+			return nil, fmt.Errorf("proposal %s belongs to network %d", export.ID, dbProposal.NetworkID)
+		}
+		if !bytes.Equal(dbProposal.ConfigUpdate, export.ConfigUpdate) {
+			return nil, fmt.Errorf("proposal %s already exists with a different config update", export.ID)
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		id := export.ID
+		if id == "" {
+			id = uuid.New().String()
+		}
+		dbProposal, err = s.storeProposal(ctx, id, networkID, network.Name, export.CreatedBy, export.Operations, export.ConfigUpdate)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("failed to get proposal: %w", err)
+	}
+
+	if dbProposal.Status == ProposalStatusPending {
+		for _, signature := range export.Signatures {
+			if err := s.addProposalSignature(ctx, fabricDeployer, dbProposal, signature); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s.mapProposal(ctx, fabricDeployer, dbProposal)
+}
+
+// SubmitFabricProposal sends a proposal to the ordering service once every
+// modification policy is satisfied. The envelope is signed by submitterMSPID, or by
+// the first local organization that signed the proposal when it's empty.
+func (s *NetworkService) SubmitFabricProposal(ctx context.Context, proposalID string, submitterMSPID string) (*Proposal, error) {
+	dbProposal, err := s.getProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if dbProposal.Status != ProposalStatusPending {
+		return nil, ErrProposalNotPending
+	}
+
+	fabricDeployer, err := s.getFabricDeployerForNetwork(ctx, dbProposal.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the signatures that are valid against the current channel config, one per
+	// MSP, are evaluated and submitted
+	config, err := s.getCurrentChannelConfig(dbProposal.NetworkID, fabricDeployer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel config: %w", err)
+	}
+	storedSignatures, err := s.getProposalSignatures(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	verified := s.verifyProposalSignatures(config, dbProposal, storedSignatures)
+	policies, err := fabric.EvaluateConfigUpdatePolicies(config, dbProposal.ConfigUpdate, signerMSPIDs(verified))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate proposal policies: %w", err)
+	}
+	if !policiesSatisfied(policies) {
+		var pending []string
+		for _, policy := range policies {
+			if !policy.Satisfied {
+				pending = append(pending, fmt.Sprintf("%s (%s)", policy.Path, policy.Rule))
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", ErrProposalNotReady, strings.Join(pending, ", "))
+	}
+
+	if submitterMSPID == "" {
+		for _, signature := range verified {
+			if _, err := s.orgService.GetOrganizationByMspID(ctx, signature.MSPID); err == nil {
+				submitterMSPID = signature.MSPID
+				break
+			}
+		}
+		if submitterMSPID == "" {
+			return nil, fmt.Errorf("none of the signing organizations is managed by this instance, a submitter MSP ID is required")
+		}
+	}
+
+	signatures := make([][]byte, len(verified))
+	for i, signature := range verified {
+		signatures[i] = signature.Signature
+	}
+
+	ordererAddress, ordererTLSCert, err := s.getOrdererAddressAndCertForNetwork(ctx, dbProposal.NetworkID, fabricDeployer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orderer address and TLS certificate: %w", err)
+	}
+
+	res, err := fabricDeployer.SubmitConfigUpdate(ctx, dbProposal.ChannelName, dbProposal.ConfigUpdate, signatures, submitterMSPID, ordererAddress, ordererTLSCert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit config update: %w", err)
+	}
+	s.logger.Info("Channel config updated from proposal", "proposalID", proposalID, "response", res)
+
+	dbProposal, err = s.db.UpdateProposalStatus(ctx, &db.UpdateProposalStatusParams{
+		Status:     ProposalStatusSubmitted,
+		TxResponse: sql.NullString{String: res, Valid: true},
+		ID:         proposalID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update proposal status: %w", err)
+	}
+
+	s.logger.Info("Reloading network block after submitting proposal, waiting 3 seconds")
+	time.Sleep(3 * time.Second)
+	if err := s.ReloadFabricNetworkBlock(ctx, dbProposal.NetworkID); err != nil {
+		s.logger.Error("Failed to reload network block after submitting proposal", "error", err)
+	}
+
+	return s.mapProposal(ctx, fabricDeployer, dbProposal)
+}
+
+// CancelFabricProposal marks a pending proposal as cancelled
+func (s *NetworkService) CancelFabricProposal(ctx context.Context, proposalID string) (*Proposal, error) {
+	dbProposal, err := s.getProposal(ctx, proposalID)
+	if err != nil {
+		return nil, err
+	}
+	if dbProposal.Status != ProposalStatusPending {
+		return nil, ErrProposalNotPending
+	}
+
+	dbProposal, err = s.db.UpdateProposalStatus(ctx, &db.UpdateProposalStatusParams{
+		Status: ProposalStatusCancelled,
+		ID:     proposalID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update proposal status: %w", err)
+	}
+
+	fabricDeployer, err := s.getFabricDeployerForNetwork(ctx, dbProposal.NetworkID)
+	if err != nil {
+		return nil, err
+	}
+	return s.mapProposal(ctx, fabricDeployer, dbProposal)
+}
+
+func (s *NetworkService) storeProposal(ctx context.Context, id string, networkID int64, channelName string, createdBy string, operations []ConfigUpdateOperationRequest, configUpdate []byte) (*db.Proposal, error) {
+	operationsJSON, err := json.Marshal(operations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal operations: %w", err)
+	}
+
+	previewJSON, err := fabric.ConfigUpdateToJSON(configUpdate)
+	if err != nil {
+		s.logger.Warn("Failed to render config update preview", "error", err)
+	}
+
+	dbProposal, err := s.db.CreateProposal(ctx, &db.CreateProposalParams{
+		ID:           id,
+		NetworkID:    networkID,
+		ChannelName:  channelName,
+		Status:       ProposalStatusPending,
+		Operations:   string(operationsJSON),
+		PreviewJson:  sql.NullString{String: previewJSON, Valid: previewJSON != ""},
+		ConfigUpdate: configUpdate,
+		CreatedBy:    createdBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create proposal: %w", err)
+	}
+	return dbProposal, nil
+}
+
+// addProposalSignature stores a signature once it is verified to come from an admin of
+// one of the organizations of the channel
+func (s *NetworkService) addProposalSignature(ctx context.Context, fabricDeployer *fabric.FabricDeployer, dbProposal *db.Proposal, signature ProposalSignature) error {
+	config, err := s.getCurrentChannelConfig(dbProposal.NetworkID, fabricDeployer)
+	if err != nil {
+		return fmt.Errorf("failed to get channel config to verify signature: %w", err)
+	}
+	mspID, err := fabric.VerifyConfigSignature(config, dbProposal.ConfigUpdate, signature.Signature)
+	if err != nil {
+		return fmt.Errorf("failed to verify signature: %w", err)
+	}
+	if signature.MSPID != "" && signature.MSPID != mspID {
+		return fmt.Errorf("signature is declared for %s but was created by %s", signature.MSPID, mspID)
+	}
+
+	signedBy := signature.SignedBy
+	if signedBy == "" {
+		signedBy = mspID
+	}
+	if _, err := s.db.UpsertProposalSignature(ctx, &db.UpsertProposalSignatureParams{
+		ProposalID: dbProposal.ID,
+		MspID:      mspID,
+		SignedBy:   signedBy,
+		Signature:  signature.Signature,
+	}); err != nil {
+		return fmt.Errorf("failed to store signature: %w", err)
+	}
+	return nil
+}
+
+func (s *NetworkService) getProposal(ctx context.Context, proposalID string) (*db.Proposal, error) {
+	dbProposal, err := s.db.GetProposal(ctx, proposalID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrProposalNotFound
+		}
+		return nil, fmt.Errorf("failed to get proposal: %w", err)
+	}
+	return dbProposal, nil
+}
+
+func (s *NetworkService) getProposalSignatures(ctx context.Context, proposalID string) ([]ProposalSignature, error) {
+	dbSignatures, err := s.db.ListProposalSignatures(ctx, proposalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list proposal signatures: %w", err)
+	}
+	signatures := make([]ProposalSignature, len(dbSignatures))
+	for i, sig := range dbSignatures {
+		signatures[i] = ProposalSignature{
+			ID:        sig.ID,
+			MSPID:     sig.MspID,
+			SignedBy:  sig.SignedBy,
+			SignedAt:  sig.SignedAt,
+			Signature: sig.Signature,
+		}
+	}
+	return signatures, nil
+}
+
+// mapProposal converts a stored proposal and evaluates its modification policies
+// against the latest channel config known to this instance
+func (s *NetworkService) mapProposal(ctx context.Context, fabricDeployer *fabric.FabricDeployer, dbProposal *db.Proposal) (*Proposal, error) {
+	proposal := &Proposal{
+		ID:                dbProposal.ID,
+		NetworkID:         dbProposal.NetworkID,
+		ChannelName:       dbProposal.ChannelName,
+		Status:            dbProposal.Status,
+		CreatedAt:         dbProposal.CreatedAt,
+		CreatedBy:         dbProposal.CreatedBy,
+		PreviewJSON:       dbProposal.PreviewJson.String,
+		ConfigUpdateBytes: dbProposal.ConfigUpdate,
+		TxResponse:        dbProposal.TxResponse.String,
+	}
+	if dbProposal.UpdatedAt.Valid {
+		proposal.UpdatedAt = &dbProposal.UpdatedAt.Time
+	}
+	if err := json.Unmarshal([]byte(dbProposal.Operations), &proposal.Operations); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal operations: %w", err)
+	}
+
+	signatures, err := s.getProposalSignatures(ctx, dbProposal.ID)
+	if err != nil {
+		return nil, err
+	}
+	proposal.Signatures = signatures
+
+	if dbProposal.Status != ProposalStatusPending {
+		return proposal, nil
+	}
+
+	config, err := s.getCurrentChannelConfig(dbProposal.NetworkID, fabricDeployer)
+	if err != nil {
+		s.logger.Warn("Failed to get channel config to evaluate proposal", "proposalID", dbProposal.ID, "error", err)
+		return proposal, nil
+	}
+	verified := s.verifyProposalSignatures(config, dbProposal, signatures)
+	policies, err := fabric.EvaluateConfigUpdatePolicies(config, dbProposal.ConfigUpdate, signerMSPIDs(verified))
+	if err != nil {
+		s.logger.Warn("Failed to evaluate proposal policies", "proposalID", dbProposal.ID, "error", err)
+		return proposal, nil
+	}
+	proposal.Policies = policies
+	proposal.Ready = policiesSatisfied(policies)
+	return proposal, nil
+}
+
+// verifyProposalSignatures checks the stored signatures again, as the MSPs of the
+// channel may have changed since they were collected, and keeps one per MSP
+func (s *NetworkService) verifyProposalSignatures(config *cb.Config, dbProposal *db.Proposal, signatures []ProposalSignature) []fabric.VerifiedConfigSignature {
+	signatureBytes := make([][]byte, len(signatures))
+	for i, sig := range signatures {
+		signatureBytes[i] = sig.Signature
+	}
+	return fabric.VerifyConfigSignatures(config, dbProposal.ConfigUpdate, signatureBytes, func(i int, err error) {
+		s.logger.Warn("Ignoring proposal signature that is no longer valid", "proposalID", dbProposal.ID, "mspID", signatures[i].MSPID, "error", err)
+	})
+}
+
+func signerMSPIDs(signatures []fabric.VerifiedConfigSignature) []string {
+	mspIDs := make([]string, len(signatures))
+	for i, sig := range signatures {
+		mspIDs[i] = sig.MSPID
+	}
+	return mspIDs
+}
+
+// policiesSatisfied reports whether a proposal can be submitted
+func policiesSatisfied(policies []fabric.ModPolicyEvaluation) bool {
+	for _, policy := range policies {
+		if !policy.Satisfied {
+			return false
+		}
+	}
+	return len(policies) > 0
+}
+
+func (s *NetworkService) getCurrentChannelConfig(networkID int64, fabricDeployer *fabric.FabricDeployer) (*cb.Config, error) {
+	blockBytes, err := fabricDeployer.GetCurrentChannelConfig(networkID)
+	if err != nil {
+		return nil, err
+	}
+	block := &cb.Block{}
+	if err := proto.Unmarshal(blockBytes, block); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config block: %w", err)
+	}
+	return fabric.ExtractConfigFromBlock(block)
+}
@@ -6,12 +6,14 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/chainlaunch/chainlaunch/pkg/db"
 	orgservicefabric "github.com/chainlaunch/chainlaunch/pkg/fabric/service"
 	keymanagement "github.com/chainlaunch/chainlaunch/pkg/keymanagement/service"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	"github.com/chainlaunch/chainlaunch/pkg/networks/service/fabric"
 	"github.com/chainlaunch/chainlaunch/pkg/networks/service/types"
 	nodeservice "github.com/chainlaunch/chainlaunch/pkg/nodes/service"
 	nodetypes "github.com/chainlaunch/chainlaunch/pkg/nodes/types"
@@ -83,17 +85,26 @@ type Proposal struct {
 	Status            string                         `json:"status"`
 	CreatedAt         time.Time                      `json:"created_at"`
 	CreatedBy         string                         `json:"created_by"`
+	UpdatedAt         *time.Time                     `json:"updated_at,omitempty"`
 	Operations        []ConfigUpdateOperationRequest `json:"operations"`
 	PreviewJSON       string                         `json:"preview_json,omitempty"`
 	ConfigUpdateBytes []byte                         `json:"config_update_bytes,omitempty"`
+	TxResponse        string                         `json:"tx_response,omitempty"`
+	Signatures        []ProposalSignature            `json:"signatures"`
+	// Policies are the modification policies the config update must satisfy,
+	// evaluated against the signatures collected so far
+	Policies []fabric.ModPolicyEvaluation `json:"policies,omitempty"`
+	// Ready is true once every modification policy is satisfied
+	Ready bool `json:"ready"`
 }
 
 // ProposalSignature represents a signature on a proposal
 type ProposalSignature struct {
-	ID       int64     `json:"id"`
-	MSPID    string    `json:"msp_id"`
-	SignedBy string    `json:"signed_by"`
-	SignedAt time.Time `json:"signed_at"`
+	ID        int64     `json:"id"`
+	MSPID     string    `json:"msp_id"`
+	SignedBy  string    `json:"signed_by"`
+	SignedAt  time.Time `json:"signed_at"`
+	Signature []byte    `json:"signature,omitempty"`
 }
 
 // FabricNetworkService handles network operations
@@ -104,6 +115,9 @@ type NetworkService struct {
 	keyMgmt         *keymanagement.KeyManagementService
 	logger          *logger.Logger
 	orgService      *orgservicefabric.OrganizationService
+	// besuValidatorTrackers holds the IDs of the networks whose validator changes
+	// are being tracked
+	besuValidatorTrackers sync.Map
 }
 
 // NewNetworkService creates a new NetworkService
@@ -46,12 +46,47 @@ type ExternalNodeRef struct {
 	Port int    `json:"port"`
 }
 
+// FabricConsensusType represents the consensus of the ordering service of a Fabric channel
+type FabricConsensusType string
+
+const (
+	FabricConsensusTypeEtcdRaft FabricConsensusType = "etcdraft"
+	// FabricConsensusTypeBFT is the SmartBFT consensus, which requires Fabric 3.x orderers
+	FabricConsensusTypeBFT FabricConsensusType = "BFT"
+)
+
 // FabricNetworkConfig represents the configuration for a Fabric network
 type FabricNetworkConfig struct {
 	BaseNetworkConfig
 	ChannelName          string         `json:"channelName"`
 	PeerOrganizations    []Organization `json:"peerOrganizations"`
 	OrdererOrganizations []Organization `json:"ordererOrganizations"`
+	// Consensus of the channel, etcdraft when empty
+	ConsensusType   FabricConsensusType `json:"consensusType,omitempty"`
+	SmartBFTOptions *SmartBFTOptions    `json:"smartBftOptions,omitempty"`
+}
+
+// SmartBFTOptions represents the SmartBFT consensus options of a BFT channel.
+// Zero values are replaced by the Fabric defaults.
+type SmartBFTOptions struct {
+	RequestBatchMaxCount      uint64 `json:"requestBatchMaxCount,omitempty"`
+	RequestBatchMaxBytes      uint64 `json:"requestBatchMaxBytes,omitempty"`
+	RequestBatchMaxInterval   string `json:"requestBatchMaxInterval,omitempty"`
+	IncomingMessageBufferSize uint64 `json:"incomingMessageBufferSize,omitempty"`
+	RequestPoolSize           uint64 `json:"requestPoolSize,omitempty"`
+	RequestForwardTimeout     string `json:"requestForwardTimeout,omitempty"`
+	RequestComplainTimeout    string `json:"requestComplainTimeout,omitempty"`
+	RequestAutoRemoveTimeout  string `json:"requestAutoRemoveTimeout,omitempty"`
+	ViewChangeResendInterval  string `json:"viewChangeResendInterval,omitempty"`
+	ViewChangeTimeout         string `json:"viewChangeTimeout,omitempty"`
+	LeaderHeartbeatTimeout    string `json:"leaderHeartbeatTimeout,omitempty"`
+	LeaderHeartbeatCount      uint64 `json:"leaderHeartbeatCount,omitempty"`
+	CollectTimeout            string `json:"collectTimeout,omitempty"`
+	SyncOnStart               *bool  `json:"syncOnStart,omitempty"`
+	SpeedUpViewChange         bool   `json:"speedUpViewChange,omitempty"`
+	LeaderRotation            bool   `json:"leaderRotation,omitempty"`
+	DecisionsPerLeader        uint64 `json:"decisionsPerLeader,omitempty"`
+	RequestMaxBytes           uint64 `json:"requestMaxBytes,omitempty"`
 }
 
 // Organization represents a Fabric organization configuration
@@ -76,7 +111,9 @@ type Consenter struct {
 type BesuConsensusType string
 
 const (
-	BesuConsensusTypeQBFT BesuConsensusType = "qbft"
+	BesuConsensusTypeQBFT   BesuConsensusType = "qbft"
+	BesuConsensusTypeIBFT2  BesuConsensusType = "ibft2"
+	BesuConsensusTypeClique BesuConsensusType = "clique"
 )
 
 // AccountBalance represents the balance configuration for an account
@@ -102,6 +139,8 @@ type BesuNetworkConfig struct {
 	MixHash                string                    `json:"mixHash"`
 	Coinbase               string                    `json:"coinbase"`
 	Alloc                  map[string]AccountBalance `json:"alloc,omitempty"`
+	// Current validator set, updated when a validator change is applied
+	Validators []string `json:"validators,omitempty"`
 	// Metrics configuration
 	MetricsEnabled  bool   `json:"metricsEnabled"`
 	MetricsHost     string `json:"metricsHost"`
@@ -147,6 +186,15 @@ func (c *FabricNetworkConfig) Validate() error {
 	if ordererOrgLen == 0 {
 		return fmt.Errorf("at least one orderer organization is required")
 	}
+	switch c.ConsensusType {
+	case "", FabricConsensusTypeEtcdRaft:
+		if c.SmartBFTOptions != nil {
+			return fmt.Errorf("SmartBFT options are only supported with BFT consensus")
+		}
+	case FabricConsensusTypeBFT:
+	default:
+		return fmt.Errorf("unsupported consensus type: %s", c.ConsensusType)
+	}
 	return nil
 }
 
@@ -160,8 +208,19 @@ func (c *BesuNetworkConfig) Validate() error {
 	if c.ChainID == 0 {
 		return fmt.Errorf("chain ID is required")
 	}
-	if c.Consensus == "" {
+	switch c.Consensus {
+	case "":
 		return fmt.Errorf("consensus mechanism is required")
+	case BesuConsensusTypeQBFT, BesuConsensusTypeIBFT2:
+		if c.RequestTimeout <= 0 {
+			return fmt.Errorf("request timeout is required for %s consensus", c.Consensus)
+		}
+	case BesuConsensusTypeClique:
+	default:
+		return fmt.Errorf("unsupported consensus mechanism: %s", c.Consensus)
+	}
+	if len(c.InitialValidatorKeyIds) == 0 {
+		return fmt.Errorf("at least one initial validator key is required")
 	}
 	return nil
 }
@@ -0,0 +1,33 @@
+package types
+
+import "testing"
+
+func TestFabricNetworkConfigValidateConsensus(t *testing.T) {
+	newConfig := func(consensusType FabricConsensusType, options *SmartBFTOptions) *FabricNetworkConfig {
+		return &FabricNetworkConfig{
+			ChannelName:          "mychannel",
+			PeerOrganizations:    []Organization{{ID: 1}},
+			OrdererOrganizations: []Organization{{ID: 2}},
+			ConsensusType:        consensusType,
+			SmartBFTOptions:      options,
+		}
+	}
+	cases := []struct {
+		name    string
+		config  *FabricNetworkConfig
+		wantErr bool
+	}{
+		{"default consensus", newConfig("", nil), false},
+		{"etcdraft", newConfig(FabricConsensusTypeEtcdRaft, nil), false},
+		{"bft", newConfig(FabricConsensusTypeBFT, nil), false},
+		{"bft with options", newConfig(FabricConsensusTypeBFT, &SmartBFTOptions{RequestBatchMaxCount: 10}), false},
+		{"etcdraft with bft options", newConfig(FabricConsensusTypeEtcdRaft, &SmartBFTOptions{}), true},
+		{"default with bft options", newConfig("", &SmartBFTOptions{}), true},
+		{"unknown consensus", newConfig("solo", nil), true},
+	}
+	for _, c := range cases {
+		if err := c.config.Validate(); (err != nil) != c.wantErr {
+			t.Errorf("%s: expected error %v, got %v", c.name, c.wantErr, err)
+		}
+	}
+}
@@ -178,7 +178,7 @@ func (b *LocalBesu) buildCommand(dataDir string, genesisPath string, configDir s
 		fmt.Sprintf("--data-path=%s", dataDir),
 		fmt.Sprintf("--genesis-file=%s", genesisPath),
 		"--rpc-http-enabled",
-		"--rpc-http-api=ETH,NET,QBFT",
+		fmt.Sprintf("--rpc-http-api=%s", b.rpcAPIs()),
 		"--rpc-http-cors-origins=all",
 		"--rpc-http-host=0.0.0.0",
 		fmt.Sprintf("--rpc-http-port=%s", b.opts.RPCPort),
@@ -207,6 +207,19 @@ func (b *LocalBesu) buildCommand(dataDir string, genesisPath string, configDir s
 	return strings.Join(cmd, " ")
 }
 
+// rpcAPIs returns the JSON-RPC APIs to enable, including the one used to vote on
+// validators with the consensus of the network
+func (b *LocalBesu) rpcAPIs() string {
+	switch types.BesuConsensusType(b.opts.ConsensusType) {
+	case types.BesuConsensusTypeIBFT2:
+		return "ETH,NET,IBFT"
+	case types.BesuConsensusTypeClique:
+		return "ETH,NET,CLIQUE"
+	default:
+		return "ETH,NET,QBFT"
+	}
+}
+
 // buildEnvironment builds the environment variables for Besu
 func (b *LocalBesu) buildEnvironment() map[string]string {
 	env := make(map[string]string)
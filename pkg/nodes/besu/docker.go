@@ -220,7 +220,7 @@ func (b *LocalBesu) buildDockerBesuArgs(dataPath, configPath string) []string {
 		fmt.Sprintf("--data-path=%s", dataPath),
 		fmt.Sprintf("--genesis-file=%s", filepath.Join(configPath, "genesis.json")),
 		"--rpc-http-enabled",
-		"--rpc-http-api=ETH,NET,QBFT",
+		fmt.Sprintf("--rpc-http-api=%s", b.rpcAPIs()),
 		"--rpc-http-cors-origins=all",
 		"--rpc-http-host=0.0.0.0",
 		fmt.Sprintf("--rpc-http-port=%s", b.opts.RPCPort),
@@ -0,0 +1,515 @@
+package ca
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/binaries"
+	"github.com/chainlaunch/chainlaunch/pkg/config"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	fabricservice "github.com/chainlaunch/chainlaunch/pkg/fabric/service"
+	kmodels "github.com/chainlaunch/chainlaunch/pkg/keymanagement/models"
+	keymanagement "github.com/chainlaunch/chainlaunch/pkg/keymanagement/service"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+)
+
+// LocalCA represents a local Fabric CA server bootstrapped from the CA keys
+// of an organization
+type LocalCA struct {
+	mspID          string
+	db             *db.Queries
+	opts           StartCAOpts
+	mode           string
+	org            *fabricservice.OrganizationDTO
+	organizationID int64
+	orgService     *fabricservice.OrganizationService
+	keyService     *keymanagement.KeyManagementService
+	nodeID         int64
+	logger         *logger.Logger
+	configService  *config.ConfigService
+}
+
+// NewLocalCA creates a new LocalCA instance
+func NewLocalCA(
+	mspID string,
+	db *db.Queries,
+	opts StartCAOpts,
+	mode string,
+	org *fabricservice.OrganizationDTO,
+	organizationID int64,
+	orgService *fabricservice.OrganizationService,
+	keyService *keymanagement.KeyManagementService,
+	nodeID int64,
+	logger *logger.Logger,
+	configService *config.ConfigService,
+) *LocalCA {
+	return &LocalCA{
+		mspID:          mspID,
+		db:             db,
+		opts:           opts,
+		mode:           mode,
+		org:            org,
+		organizationID: organizationID,
+		orgService:     orgService,
+		keyService:     keyService,
+		nodeID:         nodeID,
+		logger:         logger,
+		configService:  configService,
+	}
+}
+
+// getServiceName returns the systemd service name
+func (c *LocalCA) getServiceName() string {
+	return fmt.Sprintf("fabric-ca-%s", strings.ReplaceAll(strings.ToLower(c.opts.ID), " ", "-"))
+}
+
+// getLaunchdServiceName returns the launchd service name
+func (c *LocalCA) getLaunchdServiceName() string {
+	return fmt.Sprintf("dev.chainlaunch.ca.%s.%s",
+		strings.ToLower(c.org.MspID),
+		strings.ReplaceAll(strings.ToLower(c.opts.ID), " ", "-"))
+}
+
+// getServiceFilePath returns the systemd service file path
+func (c *LocalCA) getServiceFilePath() string {
+	return fmt.Sprintf("/etc/systemd/system/%s.service", c.getServiceName())
+}
+
+// getLaunchdPlistPath returns the launchd plist file path
+func (c *LocalCA) getLaunchdPlistPath() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, "Library/LaunchAgents", c.getLaunchdServiceName()+".plist")
+}
+
+// getDirPath returns the CA server home directory
+func (c *LocalCA) getDirPath() string {
+	return filepath.Join(c.configService.GetDataPath(), "cas",
+		strings.ReplaceAll(strings.ToLower(c.opts.ID), " ", "-"))
+}
+
+// GetStdOutPath returns the path to the stdout log file
+func (c *LocalCA) GetStdOutPath() string {
+	return filepath.Join(c.getDirPath(), c.getServiceName()+".log")
+}
+
+// GetURL returns the URL clients use to reach the CA server
+func (c *LocalCA) GetURL() string {
+	return fmt.Sprintf("https://%s", c.opts.ExternalEndpoint)
+}
+
+// findCABinary finds the fabric-ca-server binary, downloading it if needed
+func (c *LocalCA) findCABinary() (string, error) {
+	downloader, err := binaries.NewBinaryDownloader(c.configService)
+	if err != nil {
+		return "", fmt.Errorf("failed to create binary downloader: %w", err)
+	}
+
+	return downloader.GetBinaryPath(binaries.FabricCAServerBinary, c.opts.Version)
+}
+
+// Init creates the CA server home directory. The default CA signs with the
+// organization's sign CA key and a second CA, "tlsca", with its TLS CA key, so
+// identities enrolled through the server chain to the same roots as the
+// certificates chainlaunch issues itself.
+func (c *LocalCA) Init() (interface{}, error) {
+	ctx := context.Background()
+
+	c.logger.Info("Initializing CA",
+		"opts", c.opts,
+		"orgID", c.organizationID,
+		"nodeID", c.nodeID,
+	)
+
+	// Get organization
+	org, err := c.orgService.GetOrganization(ctx, c.organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	signCAKeyDB, err := c.keyService.GetKey(ctx, int(org.SignKeyID.Int64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve sign CA cert: %w", err)
+	}
+	tlsCAKeyDB, err := c.keyService.GetKey(ctx, int(org.TlsRootKeyID.Int64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve TLS CA cert: %w", err)
+	}
+	if signCAKeyDB.Certificate == nil || tlsCAKeyDB.Certificate == nil {
+		return nil, fmt.Errorf("organization %s has no CA certificates", org.MspID)
+	}
+
+	signCAKey, err := c.caPrivateKey(ctx, signCAKeyDB.ID)
+	if err != nil {
+		return nil, err
+	}
+	tlsCAKey, err := c.caPrivateKey(ctx, tlsCAKeyDB.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	providerID := 1
+	if org.ProviderID != 0 {
+		providerID = int(org.ProviderID)
+	}
+	tlsProviderID, err := c.keyService.ExportableProviderID(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve TLS key provider: %w", err)
+	}
+
+	// Create the server TLS key
+	isCA := 0
+	description := "TLS key for " + c.opts.ID
+	curveP256 := kmodels.ECCurveP256
+	tlsKeyDB, err := c.keyService.CreateKey(ctx, kmodels.CreateKeyRequest{
+		Algorithm:   kmodels.KeyAlgorithmEC,
+		Name:        c.opts.ID,
+		IsCA:        &isCA,
+		Description: &description,
+		Curve:       &curveP256,
+		ProviderID:  &tlsProviderID,
+	}, int(org.SignKeyID.Int64))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TLS key: %w", err)
+	}
+
+	domains, ipAddresses := splitDomainNames(c.opts.DomainNames)
+	c.opts.DomainNames = domains
+
+	tlsKeyDB, err = c.keyService.SignCertificate(ctx, tlsKeyDB.ID, tlsCAKeyDB.ID, kmodels.CertificateRequest{
+		CommonName:         c.opts.ID,
+		Organization:       []string{org.MspID},
+		OrganizationalUnit: []string{"ca"},
+		DNSNames:           domains,
+		IPAddresses:        ipAddresses,
+		ValidFor:           kmodels.Duration(time.Hour * 24 * 365),
+		KeyUsage:           x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:        []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign TLS certificate: %w", err)
+	}
+
+	tlsKey, err := c.keyService.GetDecryptedPrivateKey(tlsKeyDB.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get TLS private key: %w", err)
+	}
+
+	dirPath := c.getDirPath()
+	files := map[string]struct {
+		content string
+		perm    os.FileMode
+	}{
+		filepath.Join(DefaultCAName, "cert.pem"): {*signCAKeyDB.Certificate, 0644},
+		filepath.Join(DefaultCAName, "key.pem"):  {signCAKey, 0600},
+		filepath.Join(TLSCAName, "cert.pem"):     {*tlsCAKeyDB.Certificate, 0644},
+		filepath.Join(TLSCAName, "key.pem"):      {tlsCAKey, 0600},
+		filepath.Join("tls", "cert.pem"):         {*tlsKeyDB.Certificate, 0644},
+		filepath.Join("tls", "key.pem"):          {tlsKey, 0600},
+	}
+	for name, file := range files {
+		path := filepath.Join(dirPath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for %s: %w", name, err)
+		}
+		if err := os.WriteFile(path, []byte(file.content), file.perm); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	adminSecret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dirPath, adminSecretFile), []byte(adminSecret), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write admin secret: %w", err)
+	}
+
+	if err := c.writeConfigFiles(dirPath, adminSecret); err != nil {
+		return nil, fmt.Errorf("failed to write config files: %w", err)
+	}
+
+	return &types.FabricCADeploymentConfig{
+		BaseDeploymentConfig: types.BaseDeploymentConfig{
+			Type:        "fabric-ca",
+			Mode:        c.mode,
+			ServiceName: c.getServiceName(),
+		},
+		OrganizationID:          c.organizationID,
+		MSPID:                   c.mspID,
+		SignCAKeyID:             int64(signCAKeyDB.ID),
+		TLSCAKeyID:              int64(tlsCAKeyDB.ID),
+		TLSKeyID:                int64(tlsKeyDB.ID),
+		TLSCert:                 *tlsKeyDB.Certificate,
+		CACert:                  *signCAKeyDB.Certificate,
+		TLSCACert:               *tlsCAKeyDB.Certificate,
+		AdminUser:               AdminUser,
+		ListenAddress:           c.opts.ListenAddress,
+		OperationsListenAddress: c.opts.OperationsListenAddress,
+		ExternalEndpoint:        c.opts.ExternalEndpoint,
+		DomainNames:             c.opts.DomainNames,
+		Version:                 c.opts.Version,
+	}, nil
+}
+
+// caPrivateKey returns the PEM encoded CA key. fabric-ca-server reads its
+// signing keys from disk, so CA keys held in a PKCS#11 token can't be used.
+func (c *LocalCA) caPrivateKey(ctx context.Context, keyID int) (string, error) {
+	settings, err := c.keyService.GetPKCS11Settings(ctx, keyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get CA key provider: %w", err)
+	}
+	if settings != nil {
+		return "", fmt.Errorf("CA key %d is held in an HSM and can't be used by fabric-ca-server", keyID)
+	}
+	key, err := c.keyService.GetDecryptedPrivateKey(keyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get CA private key %d: %w", keyID, err)
+	}
+	return key, nil
+}
+
+// splitDomainNames separates IP addresses from DNS names and makes sure
+// localhost and 127.0.0.1 are always present
+func splitDomainNames(domainNames []string) ([]string, []net.IP) {
+	hasLocalhost := false
+	hasLoopback := false
+	var ipAddresses []net.IP
+	var domains []string
+	for _, domain := range domainNames {
+		if ip := net.ParseIP(domain); ip != nil {
+			hasLoopback = hasLoopback || ip.IsLoopback()
+			ipAddresses = append(ipAddresses, ip)
+			continue
+		}
+		hasLocalhost = hasLocalhost || domain == "localhost"
+		domains = append(domains, domain)
+	}
+	if !hasLocalhost {
+		domains = append(domains, "localhost")
+	}
+	if !hasLoopback {
+		ipAddresses = append(ipAddresses, net.ParseIP("127.0.0.1"))
+	}
+	return domains, ipAddresses
+}
+
+// generateSecret returns a random secret for the bootstrap admin
+func generateSecret() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+const adminSecretFile = "admin.secret"
+
+// readAdminSecret returns the bootstrap admin secret written by Init
+func (c *LocalCA) readAdminSecret() (string, error) {
+	secret, err := os.ReadFile(filepath.Join(c.getDirPath(), adminSecretFile))
+	if err != nil {
+		return "", fmt.Errorf("failed to read admin secret: %w", err)
+	}
+	return strings.TrimSpace(string(secret)), nil
+}
+
+// caConfigTemplate is shared by the default CA and the TLS CA. Paths are
+// relative to the file, so the same home directory works both on the host and
+// mounted into a container.
+const caConfigTemplate = `version: {{ .Version }}
+{{- if .Server }}
+port: {{ .Port }}
+address: {{ .Address }}
+debug: false
+crlsizelimit: 512000
+tls:
+  enabled: true
+  certfile: tls/cert.pem
+  keyfile: tls/key.pem
+  clientauth:
+    type: noclientcert
+cafiles:
+  - {{ .TLSCAName }}/fabric-ca-server-config.yaml
+operations:
+  listenAddress: {{ .OperationsListenAddress }}
+  tls:
+    enabled: false
+metrics:
+  provider: prometheus
+{{- end }}
+ca:
+  name: {{ .CAName }}
+  keyfile: {{ .KeyFile }}
+  certfile: {{ .CertFile }}
+crl:
+  expiry: 24h
+registry:
+  maxenrollments: -1
+  identities:
+    - name: {{ .AdminUser }}
+      pass: {{ .AdminSecret }}
+      type: admin
+      affiliation: ""
+      attrs:
+        hf.Registrar.Roles: "*"
+        hf.Registrar.DelegateRoles: "*"
+        hf.Registrar.Attributes: "*"
+        hf.Revoker: true
+        hf.GenCRL: true
+        hf.IntermediateCA: false
+        hf.AffiliationMgr: true
+db:
+  type: sqlite3
+  datasource: fabric-ca-server.db
+affiliations: {}
+signing:
+  default:
+    usage:
+      - digital signature
+    expiry: 8760h
+  profiles:
+    tls:
+      usage:
+        - digital signature
+        - key encipherment
+        - server auth
+        - client auth
+        - key agreement
+      expiry: 8760h
+bccsp:
+  default: SW
+  sw:
+    hash: SHA2
+    security: 256
+    filekeystore:
+      keystore: msp/keystore
+`
+
+// writeConfigFiles writes fabric-ca-server-config.yaml for the default CA and
+// the TLS CA
+func (c *LocalCA) writeConfigFiles(dirPath, adminSecret string) error {
+	host, port, err := net.SplitHostPort(c.opts.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %s: %w", c.opts.ListenAddress, err)
+	}
+	operationsAddress := c.opts.OperationsListenAddress
+	if c.mode == "docker" {
+		// Bind every interface inside the container so the published ports work
+		host = "0.0.0.0"
+		if _, opsPort, err := net.SplitHostPort(operationsAddress); err == nil {
+			operationsAddress = net.JoinHostPort("0.0.0.0", opsPort)
+		}
+	}
+	version := c.opts.Version
+	if version == "" {
+		version = binaries.DefaultCAVersion
+	}
+
+	tmpl := template.Must(template.New("fabric-ca-server-config.yaml").Parse(caConfigTemplate))
+	configs := []struct {
+		path string
+		data map[string]interface{}
+	}{
+		{
+			path: filepath.Join(dirPath, "fabric-ca-server-config.yaml"),
+			data: map[string]interface{}{
+				"Server":                  true,
+				"Port":                    port,
+				"Address":                 host,
+				"OperationsListenAddress": operationsAddress,
+				"TLSCAName":               TLSCAName,
+				"CAName":                  DefaultCAName,
+				"KeyFile":                 filepath.Join(DefaultCAName, "key.pem"),
+				"CertFile":                filepath.Join(DefaultCAName, "cert.pem"),
+			},
+		},
+		{
+			path: filepath.Join(dirPath, TLSCAName, "fabric-ca-server-config.yaml"),
+			data: map[string]interface{}{
+				"Server":   false,
+				"CAName":   TLSCAName,
+				"KeyFile":  "key.pem",
+				"CertFile": "cert.pem",
+			},
+		},
+	}
+	for _, cfg := range configs {
+		cfg.data["Version"] = version
+		cfg.data["AdminUser"] = AdminUser
+		cfg.data["AdminSecret"] = adminSecret
+
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, cfg.data); err != nil {
+			return fmt.Errorf("failed to execute fabric-ca-server-config.yaml template: %w", err)
+		}
+		if err := os.WriteFile(cfg.path, buf.Bytes(), 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", cfg.path, err)
+		}
+	}
+
+	return nil
+}
+
+// Start starts the CA server
+func (c *LocalCA) Start() (interface{}, error) {
+	c.logger.Info("Starting CA", "opts", c.opts)
+	dirPath := c.getDirPath()
+
+	switch c.mode {
+	case "service":
+		caBinary, err := c.findCABinary()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find fabric-ca-server binary: %w", err)
+		}
+		cmd := fmt.Sprintf("%s start --home %s", caBinary, dirPath)
+		return c.startService(cmd, c.buildEnvironment(dirPath), dirPath)
+	case "docker":
+		return c.startDocker(c.buildEnvironment("/etc/hyperledger/fabric-ca-server"), dirPath)
+	default:
+		return nil, fmt.Errorf("invalid mode: %s", c.mode)
+	}
+}
+
+// Stop stops the CA server
+func (c *LocalCA) Stop() error {
+	c.logger.Info("Stopping CA", "opts", c.opts)
+
+	switch c.mode {
+	case "service":
+		platform := runtime.GOOS
+		switch platform {
+		case "linux":
+			return c.stopSystemdService()
+		case "darwin":
+			return c.stopLaunchdService()
+		default:
+			return fmt.Errorf("unsupported platform for service mode: %s", platform)
+		}
+	case "docker":
+		return c.stopDocker()
+	default:
+		return fmt.Errorf("invalid mode: %s", c.mode)
+	}
+}
+
+// buildEnvironment builds the environment variables for the CA server
+func (c *LocalCA) buildEnvironment(homeDir string) map[string]string {
+	env := make(map[string]string)
+	for k, v := range c.opts.Env {
+		env[k] = v
+	}
+	env["FABRIC_CA_SERVER_HOME"] = homeDir
+	return env
+}
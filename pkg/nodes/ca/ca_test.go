@@ -0,0 +1,151 @@
+package ca
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/pkg/config"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+)
+
+func newTestCA(t *testing.T, mode string, opts StartCAOpts) *LocalCA {
+	if opts.ID == "" {
+		opts.ID = "ca-org1"
+	}
+	return NewLocalCA("Org1MSP", nil, opts, mode, nil, 1, nil, nil, 1, logger.NewDefault(), config.NewConfigService(t.TempDir()))
+}
+
+func TestSplitDomainNames(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   []string
+		domains []string
+		ips     []string
+	}{
+		{"empty", nil, []string{"localhost"}, []string{"127.0.0.1"}},
+		{"names and addresses", []string{"ca.org1.example.com", "10.0.0.5"}, []string{"ca.org1.example.com", "localhost"}, []string{"10.0.0.5", "127.0.0.1"}},
+		{"localhost present", []string{"localhost", "127.0.0.1"}, []string{"localhost"}, []string{"127.0.0.1"}},
+		{"ipv6 loopback", []string{"::1"}, []string{"localhost"}, []string{"::1"}},
+	}
+	for _, c := range cases {
+		domains, ips := splitDomainNames(c.input)
+		if strings.Join(domains, ",") != strings.Join(c.domains, ",") {
+			t.Errorf("%s: expected domains %v, got %v", c.name, c.domains, domains)
+		}
+		var got []string
+		for _, ip := range ips {
+			got = append(got, ip.String())
+		}
+		if strings.Join(got, ",") != strings.Join(c.ips, ",") {
+			t.Errorf("%s: expected addresses %v, got %v", c.name, c.ips, got)
+		}
+	}
+}
+
+func TestWriteConfigFiles(t *testing.T) {
+	cases := []struct {
+		mode              string
+		address           string
+		operationsAddress string
+	}{
+		{"service", "127.0.0.1", "127.0.0.1:9443"},
+		{"docker", "0.0.0.0", "0.0.0.0:9443"},
+	}
+	for _, c := range cases {
+		ca := newTestCA(t, c.mode, StartCAOpts{
+			ListenAddress:           "127.0.0.1:7054",
+			OperationsListenAddress: "127.0.0.1:9443",
+		})
+		dir := ca.getDirPath()
+		if err := os.MkdirAll(filepath.Join(dir, TLSCAName), 0755); err != nil {
+			t.Fatalf("failed to create home: %v", err)
+		}
+		if err := ca.writeConfigFiles(dir, "s3cret"); err != nil {
+			t.Fatalf("%s: failed to write config files: %v", c.mode, err)
+		}
+
+		for _, path := range []string{
+			filepath.Join(dir, "fabric-ca-server-config.yaml"),
+			filepath.Join(dir, TLSCAName, "fabric-ca-server-config.yaml"),
+		} {
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("%s: %v", c.mode, err)
+			}
+			// The files hold the bootstrap admin secret
+			if info.Mode().Perm() != 0600 {
+				t.Errorf("%s: %s has mode %v", c.mode, path, info.Mode().Perm())
+			}
+			data, _ := os.ReadFile(path)
+			if !strings.Contains(string(data), "pass: s3cret") {
+				t.Errorf("%s: %s has no admin identity", c.mode, path)
+			}
+		}
+
+		data, _ := os.ReadFile(filepath.Join(dir, "fabric-ca-server-config.yaml"))
+		config := string(data)
+		for _, expected := range []string{
+			"port: 7054",
+			"address: " + c.address,
+			"listenAddress: " + c.operationsAddress,
+			"name: " + DefaultCAName,
+			"- " + TLSCAName + "/fabric-ca-server-config.yaml",
+		} {
+			if !strings.Contains(config, expected) {
+				t.Errorf("%s: config is missing %q", c.mode, expected)
+			}
+		}
+
+		data, _ = os.ReadFile(filepath.Join(dir, TLSCAName, "fabric-ca-server-config.yaml"))
+		if strings.Contains(string(data), "port:") || !strings.Contains(string(data), "name: "+TLSCAName) {
+			t.Errorf("%s: TLS CA config should only configure the CA", c.mode)
+		}
+	}
+}
+
+func TestWriteConfigFilesInvalidAddress(t *testing.T) {
+	ca := newTestCA(t, "service", StartCAOpts{ListenAddress: "7054"})
+	if err := ca.writeConfigFiles(t.TempDir(), "s3cret"); err == nil {
+		t.Fatal("expected an error for a listen address without a port")
+	}
+}
+
+func TestBuildEnvironment(t *testing.T) {
+	ca := newTestCA(t, "service", StartCAOpts{Env: map[string]string{
+		"FABRIC_CA_SERVER_DEBUG": "true",
+		"FABRIC_CA_SERVER_HOME":  "/overridden",
+	}})
+	env := ca.buildEnvironment("/var/lib/ca")
+	if env["FABRIC_CA_SERVER_HOME"] != "/var/lib/ca" {
+		t.Errorf("home directory not set, got %q", env["FABRIC_CA_SERVER_HOME"])
+	}
+	if env["FABRIC_CA_SERVER_DEBUG"] != "true" {
+		t.Error("custom environment was dropped")
+	}
+}
+
+func TestModes(t *testing.T) {
+	ca := newTestCA(t, "kubernetes", StartCAOpts{})
+	if _, err := ca.Start(); err == nil {
+		t.Error("expected start to fail for an unknown mode")
+	}
+	if err := ca.Stop(); err == nil {
+		t.Error("expected stop to fail for an unknown mode")
+	}
+}
+
+func TestServiceNames(t *testing.T) {
+	ca := newTestCA(t, "service", StartCAOpts{ID: "CA Org1", ExternalEndpoint: net.JoinHostPort("ca.org1.example.com", "7054")})
+	if name := ca.getServiceName(); name != "fabric-ca-ca-org1" {
+		t.Errorf("unexpected service name %s", name)
+	}
+	if url := ca.GetURL(); url != "https://ca.org1.example.com:7054" {
+		t.Errorf("unexpected URL %s", url)
+	}
+	if !strings.HasSuffix(ca.getDirPath(), filepath.Join("cas", "ca-org1")) {
+		t.Errorf("unexpected home directory %s", ca.getDirPath())
+	}
+}
@@ -0,0 +1,352 @@
+package ca
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// caResponse is the envelope returned by every fabric-ca-server endpoint
+type caResponse struct {
+	Success bool            `json:"success"`
+	Result  json.RawMessage `json:"result"`
+	Errors  []struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// authFunc adds authentication to a request given its body
+type authFunc func(req *http.Request, body []byte) error
+
+// Enroll enrolls an identity with the CA server and returns the issued certificate
+func (c *LocalCA) Enroll(ctx context.Context, req EnrollRequest) (*EnrollResponse, error) {
+	if req.EnrollmentID == "" || req.Secret == "" {
+		return nil, fmt.Errorf("enrollment ID and secret are required")
+	}
+	if req.CSR == "" {
+		return nil, fmt.Errorf("certificate signing request is required")
+	}
+
+	body := map[string]interface{}{
+		"certificate_request": req.CSR,
+		"caname":              caName(req.CAName),
+		"profile":             req.Profile,
+	}
+	var result struct {
+		Cert       string `json:"Cert"`
+		ServerInfo struct {
+			CAChain string `json:"CAChain"`
+		} `json:"ServerInfo"`
+	}
+	basicAuth := func(r *http.Request, _ []byte) error {
+		r.SetBasicAuth(req.EnrollmentID, req.Secret)
+		return nil
+	}
+	if err := c.call(ctx, "/api/v1/enroll", body, basicAuth, &result); err != nil {
+		return nil, fmt.Errorf("failed to enroll %s: %w", req.EnrollmentID, err)
+	}
+
+	cert, err := base64.StdEncoding.DecodeString(result.Cert)
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate in enroll response: %w", err)
+	}
+	chain, err := base64.StdEncoding.DecodeString(result.ServerInfo.CAChain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CA chain in enroll response: %w", err)
+	}
+	return &EnrollResponse{
+		Certificate: string(cert),
+		CAChain:     string(chain),
+	}, nil
+}
+
+// Register registers a new identity using the bootstrap admin as registrar
+func (c *LocalCA) Register(ctx context.Context, req RegisterRequest) (*RegisterResponse, error) {
+	if req.EnrollmentID == "" {
+		return nil, fmt.Errorf("enrollment ID is required")
+	}
+	if req.Type == "" {
+		req.Type = "client"
+	}
+	auth, err := c.registrarAuth(ctx, caName(req.CAName))
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := make([]map[string]interface{}, 0, len(req.Attributes))
+	for _, attr := range req.Attributes {
+		attrs = append(attrs, map[string]interface{}{
+			"name":  attr.Name,
+			"value": attr.Value,
+			"ecert": attr.ECert,
+		})
+	}
+	body := map[string]interface{}{
+		"id":              req.EnrollmentID,
+		"type":            req.Type,
+		"secret":          req.Secret,
+		"max_enrollments": req.MaxEnrollments,
+		"affiliation":     req.Affiliation,
+		"attrs":           attrs,
+		"caname":          caName(req.CAName),
+	}
+	var result struct {
+		Secret string `json:"secret"`
+	}
+	if err := c.call(ctx, "/api/v1/register", body, auth, &result); err != nil {
+		return nil, fmt.Errorf("failed to register %s: %w", req.EnrollmentID, err)
+	}
+	return &RegisterResponse{Secret: result.Secret}, nil
+}
+
+// Revoke revokes all certificates of an identity, or a single certificate
+// identified by its serial number and authority key identifier
+func (c *LocalCA) Revoke(ctx context.Context, req RevokeRequest) (*RevokeResponse, error) {
+	if req.EnrollmentID == "" && (req.Serial == "" || req.AKI == "") {
+		return nil, fmt.Errorf("either enrollment ID or serial and AKI are required")
+	}
+	auth, err := c.registrarAuth(ctx, caName(req.CAName))
+	if err != nil {
+		return nil, err
+	}
+
+	body := map[string]interface{}{
+		"id":     req.EnrollmentID,
+		"serial": req.Serial,
+		"aki":    req.AKI,
+		"reason": req.Reason,
+		"caname": caName(req.CAName),
+	}
+	var result struct {
+		RevokedCerts []struct {
+			Serial string `json:"Serial"`
+			AKI    string `json:"AKI"`
+		} `json:"RevokedCerts"`
+	}
+	if err := c.call(ctx, "/api/v1/revoke", body, auth, &result); err != nil {
+		return nil, fmt.Errorf("failed to revoke: %w", err)
+	}
+
+	response := &RevokeResponse{RevokedCertificates: []RevokedCertificate{}}
+	for _, cert := range result.RevokedCerts {
+		response.RevokedCertificates = append(response.RevokedCertificates, RevokedCertificate{
+			Serial: cert.Serial,
+			AKI:    cert.AKI,
+		})
+	}
+	return response, nil
+}
+
+// registrarAuth returns token authentication for the bootstrap admin of the
+// given CA, enrolling it on first use. The enrollment is cached in the CA home
+// directory.
+func (c *LocalCA) registrarAuth(ctx context.Context, name string) (authFunc, error) {
+	dir := filepath.Join(c.getDirPath(), "registrar", name)
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+
+	certPEM, key, err := loadRegistrar(certPath, keyPath)
+	if err != nil {
+		c.logger.Info("Enrolling CA registrar", "ca", name)
+
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate registrar key: %w", err)
+		}
+		csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+			Subject: pkix.Name{CommonName: AdminUser},
+		}, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create registrar CSR: %w", err)
+		}
+		secret, err := c.readAdminSecret()
+		if err != nil {
+			return nil, err
+		}
+		enrollment, err := c.Enroll(ctx, EnrollRequest{
+			EnrollmentID: AdminUser,
+			Secret:       secret,
+			CSR:          string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+			CAName:       name,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to enroll registrar: %w", err)
+		}
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal registrar key: %w", err)
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create registrar directory: %w", err)
+		}
+		if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+			return nil, fmt.Errorf("failed to write registrar key: %w", err)
+		}
+		certPEM = []byte(enrollment.Certificate)
+		if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write registrar certificate: %w", err)
+		}
+	}
+
+	return func(r *http.Request, body []byte) error {
+		token, err := createToken(certPEM, key, r.Method, r.URL.RequestURI(), body)
+		if err != nil {
+			return err
+		}
+		r.Header.Set("Authorization", token)
+		return nil
+	}, nil
+}
+
+// loadRegistrar loads a cached registrar enrollment that is still valid
+func loadRegistrar(certPath, keyPath string) ([]byte, *ecdsa.PrivateKey, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, nil, fmt.Errorf("invalid registrar certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if time.Now().After(cert.NotAfter) {
+		return nil, nil, fmt.Errorf("registrar certificate expired")
+	}
+
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ = pem.Decode(keyPEM)
+	if block == nil {
+		return nil, nil, fmt.Errorf("invalid registrar key")
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certPEM, key, nil
+}
+
+// createToken builds the token fabric-ca-server expects from enrolled
+// identities: the base64 certificate and a low-S ECDSA signature over the
+// method, URI, body and certificate
+func createToken(certPEM []byte, key *ecdsa.PrivateKey, method, uri string, body []byte) (string, error) {
+	b64Cert := base64.StdEncoding.EncodeToString(certPEM)
+	payload := method + "." +
+		base64.StdEncoding.EncodeToString([]byte(uri)) + "." +
+		base64.StdEncoding.EncodeToString(body) + "." +
+		b64Cert
+	digest := sha256.Sum256([]byte(payload))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	halfOrder := new(big.Int).Rsh(key.Curve.Params().N, 1)
+	if s.Cmp(halfOrder) > 0 {
+		s.Sub(key.Curve.Params().N, s)
+	}
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{r, s})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode token signature: %w", err)
+	}
+	return b64Cert + "." + base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// call posts a JSON request to the CA server and decodes the result
+func (c *LocalCA) call(ctx context.Context, path string, body interface{}, auth authFunc, result interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.GetURL()+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := auth(req, payload); err != nil {
+		return err
+	}
+
+	client, err := c.httpClient()
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach CA server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	var envelope caResponse
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("unexpected response from CA server (HTTP %d): %s", resp.StatusCode, string(data))
+	}
+	if !envelope.Success {
+		var messages []string
+		for _, e := range envelope.Errors {
+			messages = append(messages, fmt.Sprintf("code %d: %s", e.Code, e.Message))
+		}
+		return fmt.Errorf("CA server returned an error: %s", strings.Join(messages, "; "))
+	}
+	if err := json.Unmarshal(envelope.Result, result); err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+	return nil
+}
+
+// httpClient returns an HTTP client that trusts the organization TLS CA
+func (c *LocalCA) httpClient() (*http.Client, error) {
+	tlsCACert, err := os.ReadFile(filepath.Join(c.getDirPath(), TLSCAName, "cert.pem"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TLS CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(tlsCACert) {
+		return nil, fmt.Errorf("invalid TLS CA certificate")
+	}
+	return &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    pool,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}, nil
+}
+
+// caName returns the CA to address, defaulting to the sign CA
+func caName(name string) string {
+	if name == "" {
+		return DefaultCAName
+	}
+	return name
+}
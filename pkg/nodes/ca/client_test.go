@@ -0,0 +1,341 @@
+package ca
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCAServer implements the parts of the fabric-ca-server API the client uses
+type fakeCAServer struct {
+	t      *testing.T
+	key    *ecdsa.PrivateKey
+	cert   *x509.Certificate
+	secret string
+
+	mu       sync.Mutex
+	enrolls  int
+	requests map[string]map[string]interface{}
+}
+
+func newFakeCAServer(t *testing.T, secret string) *fakeCAServer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca.org1"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &fakeCAServer{t: t, key: key, cert: cert, secret: secret, requests: map[string]map[string]interface{}{}}
+}
+
+func (f *fakeCAServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var req map[string]interface{}
+	json.Unmarshal(body, &req)
+	f.mu.Lock()
+	f.requests[r.URL.Path] = req
+	f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/api/v1/enroll":
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != AdminUser || pass != f.secret {
+			writeCAError(w, 20, "Authentication failure")
+			return
+		}
+		f.mu.Lock()
+		f.enrolls++
+		f.mu.Unlock()
+		writeCAResult(w, map[string]interface{}{
+			"Cert":       base64.StdEncoding.EncodeToString(f.sign(req["certificate_request"].(string))),
+			"ServerInfo": map[string]string{"CAChain": base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.cert.Raw}))},
+		})
+	case "/api/v1/register", "/api/v1/revoke":
+		if err := f.verifyToken(r, body); err != nil {
+			writeCAError(w, 20, err.Error())
+			return
+		}
+		if r.URL.Path == "/api/v1/register" {
+			writeCAResult(w, map[string]string{"secret": "generated"})
+			return
+		}
+		writeCAResult(w, map[string]interface{}{"RevokedCerts": []map[string]string{{"Serial": "0a", "AKI": "0b"}}})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeCAServer) request(path string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[path]
+}
+
+func (f *fakeCAServer) enrollments() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.enrolls
+}
+
+func (f *fakeCAServer) sign(csrPEM string) []byte {
+	block, _ := pem.Decode([]byte(csrPEM))
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		f.t.Errorf("invalid CSR: %v", err)
+		return nil
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      csr.Subject,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}, f.cert, csr.PublicKey, f.key)
+	if err != nil {
+		f.t.Errorf("failed to sign CSR: %v", err)
+		return nil
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+// verifyToken checks the token the way fabric-ca-server does
+func (f *fakeCAServer) verifyToken(r *http.Request, body []byte) error {
+	parts := strings.Split(r.Header.Get("Authorization"), ".")
+	if len(parts) != 2 {
+		return errString("invalid token")
+	}
+	certPEM, err := base64.StdEncoding.DecodeString(parts[0])
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return errString("invalid token certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+	if err := cert.CheckSignatureFrom(f.cert); err != nil {
+		return err
+	}
+	sig, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	payload := r.Method + "." + base64.StdEncoding.EncodeToString([]byte(r.URL.RequestURI())) + "." +
+		base64.StdEncoding.EncodeToString(body) + "." + parts[0]
+	digest := sha256.Sum256([]byte(payload))
+	if !ecdsa.VerifyASN1(cert.PublicKey.(*ecdsa.PublicKey), digest[:], sig) {
+		return errString("invalid token signature")
+	}
+	return nil
+}
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
+
+func writeCAResult(w http.ResponseWriter, result interface{}) {
+	data, _ := json.Marshal(result)
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "result": json.RawMessage(data)})
+}
+
+func writeCAError(w http.ResponseWriter, code int, message string) {
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"errors":  []map[string]interface{}{{"code": code, "message": message}},
+	})
+}
+
+// newClientCA returns a CA whose home directory trusts the fake server
+func newClientCA(t *testing.T, handler http.Handler, secret string) *LocalCA {
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	ca := newTestCA(t, "service", StartCAOpts{ExternalEndpoint: server.Listener.Addr().String()})
+	dir := ca.getDirPath()
+	if err := os.MkdirAll(filepath.Join(dir, TLSCAName), 0755); err != nil {
+		t.Fatalf("failed to create home: %v", err)
+	}
+	tlsCA := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(filepath.Join(dir, TLSCAName, "cert.pem"), tlsCA, 0644); err != nil {
+		t.Fatalf("failed to write TLS CA: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, adminSecretFile), []byte(secret+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write admin secret: %v", err)
+	}
+	return ca
+}
+
+func newTestCSR(t *testing.T, cn string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{Subject: pkix.Name{CommonName: cn}}, key)
+	if err != nil {
+		t.Fatalf("failed to create CSR: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr}))
+}
+
+func TestEnroll(t *testing.T) {
+	fake := newFakeCAServer(t, "s3cret")
+	ca := newClientCA(t, fake, "s3cret")
+	ctx := context.Background()
+
+	resp, err := ca.Enroll(ctx, EnrollRequest{EnrollmentID: AdminUser, Secret: "s3cret", CSR: newTestCSR(t, "user1"), Profile: "tls"})
+	if err != nil {
+		t.Fatalf("failed to enroll: %v", err)
+	}
+	if !strings.Contains(resp.Certificate, "BEGIN CERTIFICATE") || !strings.Contains(resp.CAChain, "BEGIN CERTIFICATE") {
+		t.Errorf("unexpected enrollment %+v", resp)
+	}
+	if req := fake.request("/api/v1/enroll"); req["caname"] != DefaultCAName || req["profile"] != "tls" {
+		t.Errorf("unexpected enroll request %v", req)
+	}
+
+	invalid := map[string]EnrollRequest{
+		"no enrollment id": {Secret: "s3cret", CSR: "csr"},
+		"no secret":        {EnrollmentID: AdminUser, CSR: "csr"},
+		"no csr":           {EnrollmentID: AdminUser, Secret: "s3cret"},
+		"wrong secret":     {EnrollmentID: AdminUser, Secret: "wrong", CSR: newTestCSR(t, "user1")},
+	}
+	for name, req := range invalid {
+		if _, err := ca.Enroll(ctx, req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRegisterEnrollsRegistrarOnce(t *testing.T) {
+	fake := newFakeCAServer(t, "s3cret")
+	ca := newClientCA(t, fake, "s3cret")
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		resp, err := ca.Register(ctx, RegisterRequest{
+			EnrollmentID: "user1",
+			Attributes:   []Attribute{{Name: "role", Value: "auditor", ECert: true}},
+		})
+		if err != nil {
+			t.Fatalf("failed to register: %v", err)
+		}
+		if resp.Secret != "generated" {
+			t.Errorf("unexpected secret %q", resp.Secret)
+		}
+	}
+	if n := fake.enrollments(); n != 1 {
+		t.Errorf("expected the registrar to be enrolled once, got %d enrollments", n)
+	}
+	if req := fake.request("/api/v1/register"); req["type"] != "client" || req["caname"] != DefaultCAName {
+		t.Errorf("unexpected register request %v", req)
+	}
+	info, err := os.Stat(filepath.Join(ca.getDirPath(), "registrar", DefaultCAName, "key.pem"))
+	if err != nil {
+		t.Fatalf("registrar key not cached: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("registrar key has mode %v", info.Mode().Perm())
+	}
+
+	if _, err := ca.Register(ctx, RegisterRequest{}); err == nil {
+		t.Error("expected an error without an enrollment ID")
+	}
+}
+
+func TestRegisterWrongAdminSecret(t *testing.T) {
+	ca := newClientCA(t, newFakeCAServer(t, "s3cret"), "stale")
+	if _, err := ca.Register(context.Background(), RegisterRequest{EnrollmentID: "user1"}); err == nil {
+		t.Fatal("expected registrar enrollment to fail")
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	fake := newFakeCAServer(t, "s3cret")
+	ca := newClientCA(t, fake, "s3cret")
+	ctx := context.Background()
+
+	resp, err := ca.Revoke(ctx, RevokeRequest{EnrollmentID: "user1", Reason: "keycompromise", CAName: TLSCAName})
+	if err != nil {
+		t.Fatalf("failed to revoke: %v", err)
+	}
+	if len(resp.RevokedCertificates) != 1 || resp.RevokedCertificates[0].Serial != "0a" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if req := fake.request("/api/v1/revoke"); req["caname"] != TLSCAName {
+		t.Errorf("unexpected revoke request %v", req)
+	}
+
+	invalid := map[string]RevokeRequest{
+		"empty":       {},
+		"serial only": {Serial: "0a"},
+		"aki only":    {AKI: "0b"},
+		"reason only": {Reason: "keycompromise"},
+	}
+	for name, req := range invalid {
+		if _, err := ca.Revoke(ctx, req); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestCallErrors(t *testing.T) {
+	cases := map[string]http.HandlerFunc{
+		"ca error": func(w http.ResponseWriter, r *http.Request) {
+			writeCAError(w, 63, "Failed to get user")
+		},
+		"not json": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+		},
+		"bad result": func(w http.ResponseWriter, r *http.Request) {
+			writeCAResult(w, map[string]string{"Cert": "not base64!"})
+		},
+	}
+	for name, handler := range cases {
+		ca := newClientCA(t, handler, "s3cret")
+		_, err := ca.Enroll(context.Background(), EnrollRequest{EnrollmentID: AdminUser, Secret: "s3cret", CSR: newTestCSR(t, "user1")})
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// Without the TLS CA the client can't trust the server
+	ca := newClientCA(t, newFakeCAServer(t, "s3cret"), "s3cret")
+	if err := os.Remove(filepath.Join(ca.getDirPath(), TLSCAName, "cert.pem")); err != nil {
+		t.Fatalf("failed to remove TLS CA: %v", err)
+	}
+	if _, err := ca.Enroll(context.Background(), EnrollRequest{EnrollmentID: AdminUser, Secret: "s3cret", CSR: newTestCSR(t, "user1")}); err == nil {
+		t.Error("expected an error without the TLS CA certificate")
+	}
+}
@@ -0,0 +1,101 @@
+package ca
+
+const (
+	// DefaultCAName is the name of the CA that issues enrollment certificates
+	// with the organization's sign CA key
+	DefaultCAName = "ca"
+	// TLSCAName is the name of the CA that issues TLS certificates with the
+	// organization's TLS CA key
+	TLSCAName = "tlsca"
+	// AdminUser is the bootstrap identity used as registrar by chainlaunch
+	AdminUser = "admin"
+)
+
+// StartCAOpts represents the options for starting a Fabric CA server
+type StartCAOpts struct {
+	ID                      string            `json:"id"`
+	ListenAddress           string            `json:"listenAddress"`
+	OperationsListenAddress string            `json:"operationsListenAddress"`
+	ExternalEndpoint        string            `json:"externalEndpoint"`
+	DomainNames             []string          `json:"domainNames"`
+	Env                     map[string]string `json:"env"`
+	Version                 string            `json:"version"` // Fabric CA version to use
+}
+
+// StartServiceResponse represents the response when starting a CA as a service
+type StartServiceResponse struct {
+	Mode        string `json:"mode"`
+	Type        string `json:"type"`
+	ServiceName string `json:"serviceName"`
+}
+
+// StartDockerResponse represents the response when starting a CA as a docker container
+type StartDockerResponse struct {
+	Mode          string `json:"mode"`
+	ContainerName string `json:"containerName"`
+}
+
+// Attribute represents an attribute attached to a registered identity
+type Attribute struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// ECert adds the attribute to enrollment certificates by default
+	ECert bool `json:"ecert,omitempty"`
+}
+
+// EnrollRequest represents a request to enroll an identity
+type EnrollRequest struct {
+	EnrollmentID string `json:"enrollmentId" validate:"required"`
+	Secret       string `json:"secret" validate:"required"`
+	// CSR is the PEM encoded certificate signing request. The private key never
+	// leaves the caller.
+	CSR string `json:"csr" validate:"required"`
+	// CAName selects the issuing CA: "ca" (default) or "tlsca"
+	CAName string `json:"caName,omitempty"`
+	// Profile is the signing profile, e.g. "tls"
+	Profile string `json:"profile,omitempty"`
+}
+
+// EnrollResponse represents the certificate issued by an enrollment
+type EnrollResponse struct {
+	Certificate string `json:"certificate"`
+	CAChain     string `json:"caChain"`
+}
+
+// RegisterRequest represents a request to register a new identity
+type RegisterRequest struct {
+	EnrollmentID string `json:"enrollmentId" validate:"required"`
+	// Secret is generated by the CA when empty
+	Secret         string      `json:"secret,omitempty"`
+	Type           string      `json:"type" validate:"required" example:"client"`
+	Affiliation    string      `json:"affiliation,omitempty"`
+	MaxEnrollments int         `json:"maxEnrollments,omitempty"`
+	Attributes     []Attribute `json:"attributes,omitempty"`
+	CAName         string      `json:"caName,omitempty"`
+}
+
+// RegisterResponse contains the enrollment secret of a registered identity
+type RegisterResponse struct {
+	Secret string `json:"secret"`
+}
+
+// RevokeRequest represents a request to revoke an identity or a single
+// certificate. Either EnrollmentID or Serial and AKI must be set.
+type RevokeRequest struct {
+	EnrollmentID string `json:"enrollmentId,omitempty"`
+	Serial       string `json:"serial,omitempty"`
+	AKI          string `json:"aki,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	CAName       string `json:"caName,omitempty"`
+}
+
+// RevokedCertificate identifies a revoked certificate
+type RevokedCertificate struct {
+	Serial string `json:"serial"`
+	AKI    string `json:"aki"`
+}
+
+// RevokeResponse lists the certificates that were revoked
+type RevokeResponse struct {
+	RevokedCertificates []RevokedCertificate `json:"revokedCertificates"`
+}
@@ -16,6 +16,8 @@ type CreateNodeRequest struct {
 	FabricPeer *types.FabricPeerConfig `json:"fabricPeer,omitempty"`
 	// @Description Fabric orderer configuration, required when creating a Fabric orderer node
 	FabricOrderer *types.FabricOrdererConfig `json:"fabricOrderer,omitempty"`
+	// @Description Fabric CA configuration, required when creating a Fabric CA node
+	FabricCA *types.FabricCAConfig `json:"fabricCA,omitempty"`
 	// @Description Besu node configuration, required when creating a Besu node
 	BesuNode *types.BesuNodeConfig `json:"besuNode,omitempty"`
 }
@@ -7,9 +7,11 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/chainlaunch/chainlaunch/pkg/auth"
 	"github.com/chainlaunch/chainlaunch/pkg/errors"
 	"github.com/chainlaunch/chainlaunch/pkg/http/response"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/ca"
 	"github.com/chainlaunch/chainlaunch/pkg/nodes/service"
 	"github.com/chainlaunch/chainlaunch/pkg/nodes/types"
 	"github.com/go-chi/chi/v5"
@@ -42,8 +44,10 @@ type PaginatedNodeEventsResponse struct {
 	Page  int                 `json:"page"`
 }
 
-// RegisterRoutes registers the node routes
+// RegisterRoutes registers the node routes. Operating a node requires the
+// manager role globally or on the node, its organization or one of its networks.
 func (h *NodeHandler) RegisterRoutes(r chi.Router) {
+	manageNode := auth.RequireResourceRole(auth.ResourceNode, "id", auth.RoleManager)
 	r.Route("/nodes", func(r chi.Router) {
 		r.Post("/", response.Middleware(h.CreateNode))
 		r.Get("/", response.Middleware(h.ListNodes))
@@ -53,16 +57,21 @@ func (h *NodeHandler) RegisterRoutes(r chi.Router) {
 		r.Get("/defaults/fabric", response.Middleware(h.GetFabricNodesDefaults))
 		r.Get("/defaults/besu-node", response.Middleware(h.GetBesuNodeDefaults))
 		r.Get("/{id}", response.Middleware(h.GetNode))
-		r.Post("/{id}/start", response.Middleware(h.StartNode))
-		r.Post("/{id}/stop", response.Middleware(h.StopNode))
-		r.Post("/{id}/restart", response.Middleware(h.RestartNode))
-		r.Delete("/{id}", response.Middleware(h.DeleteNode))
+		r.With(manageNode).Post("/{id}/start", response.Middleware(h.StartNode))
+		r.With(manageNode).Post("/{id}/stop", response.Middleware(h.StopNode))
+		r.With(manageNode).Post("/{id}/restart", response.Middleware(h.RestartNode))
+		r.With(manageNode).Delete("/{id}", response.Middleware(h.DeleteNode))
 		r.Get("/{id}/logs", h.TailLogs)
 		r.Get("/{id}/events", response.Middleware(h.GetNodeEvents))
 		r.Get("/{id}/channels", response.Middleware(h.GetNodeChannels))
 		r.Get("/{id}/channels/{channelID}/chaincodes", response.Middleware(h.GetNodeChaincodes))
-		r.Post("/{id}/certificates/renew", response.Middleware(h.RenewCertificates))
-		r.Put("/{id}", response.Middleware(h.UpdateNode))
+		r.With(manageNode).Post("/{id}/certificates/renew", response.Middleware(h.RenewCertificates))
+		r.Get("/{id}/certificates/settings", response.Middleware(h.GetCertificateSettings))
+		r.With(manageNode).Put("/{id}/certificates/settings", response.Middleware(h.UpdateCertificateSettings))
+		r.With(manageNode).Post("/{id}/ca/enroll", response.Middleware(h.EnrollCAIdentity))
+		r.With(manageNode).Post("/{id}/ca/register", response.Middleware(h.RegisterCAIdentity))
+		r.With(manageNode).Post("/{id}/ca/revoke", response.Middleware(h.RevokeCAIdentity))
+		r.With(manageNode).Put("/{id}", response.Middleware(h.UpdateNode))
 	})
 }
 
@@ -100,11 +109,16 @@ func (h *NodeHandler) CreateNode(w http.ResponseWriter, r *http.Request) error {
 		})
 	}
 
+	if err := authorizeCreateNode(r, &req); err != nil {
+		return err
+	}
+
 	serviceReq := service.CreateNodeRequest{
 		Name:               req.Name,
 		BlockchainPlatform: req.BlockchainPlatform,
 		FabricPeer:         req.FabricPeer,
 		FabricOrderer:      req.FabricOrderer,
+		FabricCA:           req.FabricCA,
 		BesuNode:           req.BesuNode,
 	}
 
@@ -116,6 +130,31 @@ func (h *NodeHandler) CreateNode(w http.ResponseWriter, r *http.Request) error {
 	return response.WriteJSON(w, http.StatusCreated, toNodeResponse(node))
 }
 
+// authorizeCreateNode requires the manager role on the organization of a
+// Fabric node, Besu nodes don't belong to an organization and require it globally
+func authorizeCreateNode(r *http.Request, req *CreateNodeRequest) error {
+	var orgID int64
+	switch {
+	case req.FabricPeer != nil:
+		orgID = req.FabricPeer.OrganizationID
+	case req.FabricOrderer != nil:
+		orgID = req.FabricOrderer.OrganizationID
+	case req.FabricCA != nil:
+		orgID = req.FabricCA.OrganizationID
+	}
+
+	var err error
+	if orgID != 0 {
+		err = auth.Authorize(r.Context(), auth.ResourceOrganization, orgID, auth.RoleManager)
+	} else {
+		err = auth.Authorize(r.Context(), "", 0, auth.RoleManager)
+	}
+	if err != nil {
+		return auth.AuthorizationError(err)
+	}
+	return nil
+}
+
 // GetNode godoc
 // @Summary Get a node
 // @Description Get a node by ID
@@ -688,6 +727,7 @@ func toNodeResponse(node *service.NodeResponse) NodeResponse {
 		UpdatedAt:          node.UpdatedAt,
 		FabricPeer:         node.FabricPeer,
 		FabricOrderer:      node.FabricOrderer,
+		FabricCA:           node.FabricCA,
 		BesuNode:           node.BesuNode,
 	}
 }
@@ -742,6 +782,206 @@ func (h *NodeHandler) RenewCertificates(w http.ResponseWriter, r *http.Request)
 	return response.WriteJSON(w, http.StatusOK, toNodeResponse(node))
 }
 
+// GetCertificateSettings godoc
+// @Summary Get node certificate settings
+// @Description Returns whether the node's certificates are renewed automatically before they expire
+// @Tags Nodes
+// @Produce json
+// @Param id path int true "Node ID"
+// @Success 200 {object} service.CertificateSettings
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 404 {object} response.ErrorResponse "Node not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /nodes/{id}/certificates/settings [get]
+func (h *NodeHandler) GetCertificateSettings(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid node ID", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	settings, err := h.service.GetCertificateSettings(r.Context(), id)
+	if err != nil {
+		if errors.IsType(err, errors.NotFoundError) {
+			return errors.NewNotFoundError("node not found", nil)
+		}
+		return errors.NewInternalError("failed to get certificate settings", err, nil)
+	}
+
+	return response.WriteJSON(w, http.StatusOK, settings)
+}
+
+// UpdateCertificateSettings godoc
+// @Summary Update node certificate settings
+// @Description Opts a Fabric node in or out of automatic certificate renewal by the certificate expiry scanner
+// @Tags Nodes
+// @Accept json
+// @Produce json
+// @Param id path int true "Node ID"
+// @Param request body UpdateCertificateSettingsRequest true "Certificate settings"
+// @Success 200 {object} service.CertificateSettings
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 404 {object} response.ErrorResponse "Node not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /nodes/{id}/certificates/settings [put]
+func (h *NodeHandler) UpdateCertificateSettings(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid node ID", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	var req UpdateCertificateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	settings, err := h.service.UpdateCertificateSettings(r.Context(), id, req.AutoRenew)
+	if err != nil {
+		if errors.IsType(err, errors.NotFoundError) {
+			return errors.NewNotFoundError("node not found", nil)
+		}
+		if errors.IsType(err, errors.ValidationError) {
+			return err
+		}
+		return errors.NewInternalError("failed to update certificate settings", err, nil)
+	}
+
+	return response.WriteJSON(w, http.StatusOK, settings)
+}
+
+// EnrollCAIdentity godoc
+// @Summary Enroll an identity with a Fabric CA node
+// @Description Sends a certificate signing request to the CA server and returns the issued certificate. The private key never leaves the caller.
+// @Tags Nodes
+// @Accept json
+// @Produce json
+// @Param id path int true "Node ID"
+// @Param request body ca.EnrollRequest true "Enrollment request"
+// @Success 200 {object} ca.EnrollResponse
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 404 {object} response.ErrorResponse "Node not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /nodes/{id}/ca/enroll [post]
+func (h *NodeHandler) EnrollCAIdentity(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid node ID", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	var req ca.EnrollRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if req.EnrollmentID == "" || req.Secret == "" || req.CSR == "" {
+		return errors.NewValidationError("enrollmentId, secret and csr are required", nil)
+	}
+
+	enrollment, err := h.service.EnrollCAIdentity(r.Context(), id, req)
+	if err != nil {
+		return caError(err, "failed to enroll identity")
+	}
+
+	return response.WriteJSON(w, http.StatusOK, enrollment)
+}
+
+// RegisterCAIdentity godoc
+// @Summary Register an identity with a Fabric CA node
+// @Description Registers a new identity using the CA bootstrap admin as registrar and returns its enrollment secret
+// @Tags Nodes
+// @Accept json
+// @Produce json
+// @Param id path int true "Node ID"
+// @Param request body ca.RegisterRequest true "Registration request"
+// @Success 201 {object} ca.RegisterResponse
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 404 {object} response.ErrorResponse "Node not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /nodes/{id}/ca/register [post]
+func (h *NodeHandler) RegisterCAIdentity(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid node ID", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	var req ca.RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if req.EnrollmentID == "" {
+		return errors.NewValidationError("enrollmentId is required", nil)
+	}
+
+	registration, err := h.service.RegisterCAIdentity(r.Context(), id, req)
+	if err != nil {
+		return caError(err, "failed to register identity")
+	}
+
+	return response.WriteJSON(w, http.StatusCreated, registration)
+}
+
+// RevokeCAIdentity godoc
+// @Summary Revoke an identity or certificate issued by a Fabric CA node
+// @Description Revokes every certificate of an identity, or a single certificate identified by serial and AKI
+// @Tags Nodes
+// @Accept json
+// @Produce json
+// @Param id path int true "Node ID"
+// @Param request body ca.RevokeRequest true "Revocation request"
+// @Success 200 {object} ca.RevokeResponse
+// @Failure 400 {object} response.ErrorResponse "Validation error"
+// @Failure 404 {object} response.ErrorResponse "Node not found"
+// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Router /nodes/{id}/ca/revoke [post]
+func (h *NodeHandler) RevokeCAIdentity(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		return errors.NewValidationError("invalid node ID", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	var req ca.RevokeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return errors.NewValidationError("invalid request body", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if req.EnrollmentID == "" && (req.Serial == "" || req.AKI == "") {
+		return errors.NewValidationError("either enrollmentId or serial and aki are required", nil)
+	}
+
+	revoked, err := h.service.RevokeCAIdentity(r.Context(), id, req)
+	if err != nil {
+		return caError(err, "failed to revoke identity")
+	}
+
+	return response.WriteJSON(w, http.StatusOK, revoked)
+}
+
+// caError maps errors returned by the CA endpoints to HTTP errors
+func caError(err error, msg string) error {
+	if errors.IsType(err, errors.NotFoundError) {
+		return errors.NewNotFoundError("node not found", nil)
+	}
+	if errors.IsType(err, errors.ValidationError) {
+		return err
+	}
+	return errors.NewInternalError(msg, err, nil)
+}
+
 // UpdateNode godoc
 // @Summary Update a node
 // @Description Updates an existing node's configuration based on its type
@@ -868,6 +1108,9 @@ func (h *NodeHandler) updateFabricPeer(w http.ResponseWriter, r *http.Request, n
 	if req.Version != nil {
 		opts.Version = *req.Version
 	}
+	if req.CouchDB != nil {
+		opts.CouchDB = req.CouchDB
+	}
 
 	updatedNode, err := h.service.UpdateFabricPeer(r.Context(), opts)
 	if err != nil {
@@ -160,6 +160,7 @@ type NodeResponse struct {
 	UpdatedAt          time.Time                        `json:"updatedAt"`
 	FabricPeer         *service.FabricPeerProperties    `json:"fabricPeer,omitempty"`
 	FabricOrderer      *service.FabricOrdererProperties `json:"fabricOrderer,omitempty"`
+	FabricCA           *service.FabricCAProperties      `json:"fabricCA,omitempty"`
 	BesuNode           *service.BesuNodeProperties      `json:"besuNode,omitempty"`
 }
 
@@ -202,6 +203,7 @@ type UpdateFabricPeerRequest struct {
 	Env                     map[string]string       `json:"env,omitempty"`
 	AddressOverrides        []types.AddressOverride `json:"addressOverrides,omitempty"`
 	Version                 *string                 `json:"version,omitempty"`
+	CouchDB                 *types.CouchDBConfig    `json:"couchDB,omitempty"`
 }
 
 // UpdateFabricOrdererRequest represents the configuration for updating a Fabric orderer node
@@ -234,3 +236,8 @@ type BesuNodeDefaultsResponse struct {
 	NodeCount int                        `json:"nodeCount"`
 	Defaults  []service.BesuNodeDefaults `json:"defaults"`
 }
+
+// UpdateCertificateSettingsRequest represents the request to update a node's certificate settings
+type UpdateCertificateSettingsRequest struct {
+	AutoRenew bool `json:"autoRenew"`
+}
@@ -6,13 +6,16 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -22,8 +25,10 @@ import (
 	"github.com/chainlaunch/chainlaunch/pkg/db"
 	fabricservice "github.com/chainlaunch/chainlaunch/pkg/fabric/service"
 	kmodels "github.com/chainlaunch/chainlaunch/pkg/keymanagement/models"
+	kmtypes "github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/types"
 	keymanagement "github.com/chainlaunch/chainlaunch/pkg/keymanagement/service"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/orderer/osnadmin"
 	"github.com/chainlaunch/chainlaunch/pkg/nodes/types"
 	settingsservice "github.com/chainlaunch/chainlaunch/pkg/settings/service"
 	"github.com/docker/docker/api/types/container"
@@ -31,7 +36,6 @@ import (
 	"github.com/hyperledger/fabric-admin-sdk/pkg/channel"
 	"github.com/hyperledger/fabric-admin-sdk/pkg/identity"
 	"github.com/hyperledger/fabric-admin-sdk/pkg/network"
-	gwidentity "github.com/hyperledger/fabric-gateway/pkg/identity"
 	"google.golang.org/grpc"
 )
 
@@ -82,6 +86,16 @@ func NewLocalOrderer(
 	}
 }
 
+// SupportsBFT reports whether orderers of the given Fabric version can run BFT
+// (SmartBFT) channels, which were introduced in Fabric 3.0
+func SupportsBFT(version string) bool {
+	major, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(version, "v"), ".", 2)[0])
+	if err != nil {
+		return false
+	}
+	return major >= 3
+}
+
 // getServiceName returns the systemd service name
 func (o *LocalOrderer) getServiceName() string {
 	return fmt.Sprintf("fabric-orderer-%s", strings.ReplaceAll(strings.ToLower(o.opts.ID), " ", "-"))
@@ -151,6 +165,9 @@ func (o *LocalOrderer) Start() (interface{}, error) {
 	switch o.mode {
 	case "service":
 		env := o.buildOrdererEnvironment(mspConfigPath)
+		if err := o.setPKCS11Environment(context.Background(), env); err != nil {
+			return nil, err
+		}
 		return o.startService(cmd, env, dirPath)
 	case "docker":
 		env := o.buildDockerOrdererEnvironment(mspConfigPath)
@@ -214,7 +231,10 @@ func (o *LocalOrderer) buildOrdererEnvironment(mspConfigPath string) map[string]
 	env["ORDERER_ADMIN_TLS_ENABLED"] = "true"
 	env["ORDERER_CHANNELPARTICIPATION_ENABLED"] = "true"
 	env["ORDERER_GENERAL_BOOTSTRAPMETHOD"] = "none"
-	env["ORDERER_GENERAL_GENESISPROFILE"] = "initial"
+	if !SupportsBFT(o.opts.Version) {
+		// Removed in Fabric 3.x along with the system channel
+		env["ORDERER_GENERAL_GENESISPROFILE"] = "initial"
+	}
 	env["ORDERER_GENERAL_LEDGERTYPE"] = "file"
 	env["FABRIC_LOGGING_SPEC"] = "info"
 	env["ORDERER_GENERAL_TLS_CLIENTAUTHREQUIRED"] = "false"
@@ -257,7 +277,10 @@ func (o *LocalOrderer) buildDockerOrdererEnvironment(mspConfigPath string) map[s
 	env["ORDERER_ADMIN_TLS_ENABLED"] = "true"
 	env["ORDERER_CHANNELPARTICIPATION_ENABLED"] = "true"
 	env["ORDERER_GENERAL_BOOTSTRAPMETHOD"] = "none"
-	env["ORDERER_GENERAL_GENESISPROFILE"] = "initial"
+	if !SupportsBFT(o.opts.Version) {
+		// Removed in Fabric 3.x along with the system channel
+		env["ORDERER_GENERAL_GENESISPROFILE"] = "initial"
+	}
 	env["ORDERER_GENERAL_LEDGERTYPE"] = "file"
 	env["FABRIC_LOGGING_SPEC"] = "info"
 	env["ORDERER_GENERAL_TLS_CLIENTAUTHREQUIRED"] = "false"
@@ -417,7 +440,16 @@ func (o *LocalOrderer) Init() (interface{}, error) {
 	isCA := 0
 	description := "Sign key for " + o.opts.ID
 	curveP256 := kmodels.ECCurveP256
+	// The sign key lives in the organization's key provider; TLS keys have to be
+	// written to disk so they always come from a provider that can export them
 	providerID := 1
+	if org.ProviderID != 0 {
+		providerID = int(org.ProviderID)
+	}
+	tlsProviderID, err := o.keyService.ExportableProviderID(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve TLS key provider: %w", err)
+	}
 
 	// Create Sign Key
 	signKeyDB, err := o.keyService.CreateKey(ctx, kmodels.CreateKeyRequest{
@@ -446,9 +478,9 @@ func (o *LocalOrderer) Init() (interface{}, error) {
 		return nil, fmt.Errorf("failed to sign sign key: %w", err)
 	}
 
-	signKey, err := o.keyService.GetDecryptedPrivateKey(int(signKeyDB.ID))
+	signKey, err := o.signPrivateKey(ctx, int(signKeyDB.ID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sign private key: %w", err)
+		return nil, err
 	}
 
 	// Create TLS key
@@ -458,7 +490,7 @@ func (o *LocalOrderer) Init() (interface{}, error) {
 		IsCA:        &isCA,
 		Description: &description,
 		Curve:       &curveP256,
-		ProviderID:  &providerID,
+		ProviderID:  &tlsProviderID,
 	}, int(org.SignKeyID.Int64))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TLS key: %w", err)
@@ -618,6 +650,13 @@ func (o *LocalOrderer) writeCertificatesAndKeys(
 	if err := os.MkdirAll(keystorePath, 0755); err != nil {
 		return fmt.Errorf("failed to create keystore directory: %w", err)
 	}
+	if signKey == "" {
+		// The signing key stays in the HSM, the orderer reaches it through the PKCS11 BCCSP
+		if err := os.Remove(filepath.Join(keystorePath, "key.pem")); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove signing key: %w", err)
+		}
+		return nil
+	}
 	if err := os.WriteFile(filepath.Join(keystorePath, "key.pem"), []byte(signKey), 0600); err != nil {
 		return fmt.Errorf("failed to write signing key: %w", err)
 	}
@@ -625,6 +664,71 @@ func (o *LocalOrderer) writeCertificatesAndKeys(
 	return nil
 }
 
+// signPrivateKey returns the PEM encoded signing key to write to the keystore,
+// or an empty string if the key is held in a PKCS#11 token
+func (o *LocalOrderer) signPrivateKey(ctx context.Context, keyID int) (string, error) {
+	settings, err := o.keyService.GetPKCS11Settings(ctx, keyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sign key provider: %w", err)
+	}
+	if settings != nil {
+		if o.mode == "docker" {
+			return "", fmt.Errorf("HSM backed sign keys are not supported in docker mode")
+		}
+		return "", nil
+	}
+	signKey, err := o.keyService.GetDecryptedPrivateKey(keyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sign private key: %w", err)
+	}
+	return signKey, nil
+}
+
+// signKeyPKCS11Settings returns the token settings of the orderer signing key,
+// or nil if the key isn't held in a PKCS#11 token
+func (o *LocalOrderer) signKeyPKCS11Settings(ctx context.Context) (*kmtypes.PKCS11Settings, error) {
+	node, err := o.db.GetNode(ctx, o.nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	if !node.DeploymentConfig.Valid || node.DeploymentConfig.String == "" {
+		return nil, nil
+	}
+	var deployment struct {
+		SignKeyID int64 `json:"signKeyId"`
+	}
+	if err := json.Unmarshal([]byte(node.DeploymentConfig.String), &deployment); err != nil {
+		return nil, fmt.Errorf("failed to decode deployment config: %w", err)
+	}
+	if deployment.SignKeyID == 0 {
+		return nil, nil
+	}
+	settings, err := o.keyService.GetPKCS11Settings(ctx, int(deployment.SignKeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sign key provider: %w", err)
+	}
+	return settings, nil
+}
+
+// setPKCS11Environment switches the orderer BCCSP to PKCS11 when its signing
+// key is held in a PKCS#11 token. The PIN isn't part of the environment, which
+// ends up in service files and logs, it is read from orderer.yaml.
+func (o *LocalOrderer) setPKCS11Environment(ctx context.Context, env map[string]string) error {
+	settings, err := o.signKeyPKCS11Settings(ctx)
+	if err != nil {
+		return err
+	}
+	if settings == nil {
+		return nil
+	}
+	env["ORDERER_GENERAL_BCCSP_DEFAULT"] = "PKCS11"
+	env["ORDERER_GENERAL_BCCSP_PKCS11_LIBRARY"] = settings.Library
+	env["ORDERER_GENERAL_BCCSP_PKCS11_LABEL"] = settings.Label
+	env["ORDERER_GENERAL_BCCSP_PKCS11_HASH"] = "SHA2"
+	env["ORDERER_GENERAL_BCCSP_PKCS11_SECURITY"] = "256"
+	return nil
+}
+
 // writeConfigFiles writes the config.yaml and orderer.yaml files
 func (o *LocalOrderer) writeConfigFiles(mspConfigPath, dataConfigPath string) error {
 	// Write config.yaml
@@ -716,6 +820,12 @@ General:
         # Consensus messages are dropped if the buffer is full, and transaction
         # messages are waiting for space to be freed.
         SendBufferSize: 100
+{{- if .SupportsBFT }}
+
+        # ReplicationPolicy defines how BFT orderers pull blocks from each other:
+        # "consensus" verifies blocks against the consenters, "simple" trusts a single one.
+        ReplicationPolicy: consensus
+{{- end }}
 
         # ClientCertificate governs the file location of the client TLS certificate
         # used to establish mutual TLS connections with other ordering service nodes.
@@ -745,7 +855,7 @@ General:
     # system channel is specified. The option can be one of:
     #   "file" - path to a file containing the genesis block or config block of system channel
     #   "none" - allows an orderer to start without a system channel configuration
-    BootstrapMethod: file
+    BootstrapMethod: {{ if .SupportsBFT }}none{{ else }}file{{ end }}
 
     # Bootstrap file: The file containing the bootstrap block to use when
     # initializing the orderer system channel and BootstrapMethod is set to
@@ -803,7 +913,7 @@ General:
             # Token Label
             Label:
             # User PIN
-            Pin:
+            Pin: {{if .PKCS11Pin}}{{printf "%q" .PKCS11Pin}}{{end}}
             Hash:
             Security:
             FileKeyStore:
@@ -981,6 +1091,8 @@ Consensus:
 		AdminAddress            string
 		DataPath                string
 		MSPID                   string
+		SupportsBFT             bool
+		PKCS11Pin               string
 	}{
 		ListenAddress:           strings.Split(o.opts.ListenAddress, ":")[0],
 		ListenPort:              strings.Split(o.opts.ListenAddress, ":")[1],
@@ -988,6 +1100,14 @@ Consensus:
 		AdminAddress:            o.opts.AdminListenAddress,
 		DataPath:                dataConfigPath,
 		MSPID:                   o.mspID,
+		SupportsBFT:             SupportsBFT(o.opts.Version),
+	}
+	settings, err := o.signKeyPKCS11Settings(context.Background())
+	if err != nil {
+		return err
+	}
+	if settings != nil {
+		data.PKCS11Pin = settings.Pin
 	}
 
 	var buf bytes.Buffer
@@ -996,9 +1116,15 @@ Consensus:
 		return fmt.Errorf("failed to execute orderer.yaml template: %w", err)
 	}
 
-	if err := os.WriteFile(filepath.Join(mspConfigPath, "orderer.yaml"), buf.Bytes(), 0644); err != nil {
+	// Only readable by its owner, as it may hold the PKCS#11 user PIN
+	ordererYamlPath := filepath.Join(mspConfigPath, "orderer.yaml")
+	if err := os.WriteFile(ordererYamlPath, buf.Bytes(), 0600); err != nil {
 		return fmt.Errorf("failed to write orderer.yaml: %w", err)
 	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(ordererYamlPath, 0600); err != nil {
+		return fmt.Errorf("failed to set orderer.yaml permissions: %w", err)
+	}
 
 	return nil
 }
@@ -1146,33 +1272,8 @@ func (o *LocalOrderer) GetAdminIdentity(ctx context.Context) (identity.SigningId
 		return nil, fmt.Errorf("failed to get organization: %w", err)
 	}
 
-	// Get admin signing key
-	adminSignKeyDB, err := o.keyService.GetKey(ctx, int(org.AdminSignKeyID.Int64))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get admin signing key: %w", err)
-	}
-	adminSignCert := adminSignKeyDB.Certificate
-	if adminSignCert == nil {
-		return nil, fmt.Errorf("admin signing certificate is nil")
-	}
-
-	// Get private key from key management service
-	privateKeyPEM, err := o.keyService.GetDecryptedPrivateKey(int(org.AdminSignKeyID.Int64))
-	if err != nil {
-		return nil, fmt.Errorf("failed to get private key: %w", err)
-	}
-
-	cert, err := gwidentity.CertificateFromPEM([]byte(*adminSignCert))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read certificate: %w", err)
-	}
-
-	privateKey, err := gwidentity.PrivateKeyFromPEM([]byte(privateKeyPEM))
-	if err != nil {
-		return nil, fmt.Errorf("failed to read private key: %w", err)
-	}
-
-	id, err := identity.NewPrivateKeySigningIdentity(org.MspID, cert, privateKey)
+	// The private key stays in its key provider, so HSM keys can be used
+	id, err := o.keyService.GetSigningIdentity(ctx, int(org.AdminSignKeyID.Int64), org.MspID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create identity: %w", err)
 	}
@@ -1180,42 +1281,53 @@ func (o *LocalOrderer) GetAdminIdentity(ctx context.Context) (identity.SigningId
 	return id, nil
 }
 
-// GetChannels returns a list of channels the orderer is participating in
-func (o *LocalOrderer) GetChannels(ctx context.Context) ([]OrdererChannel, error) {
+// adminTLSCredentials returns the CA pool and client certificate used to call the
+// channel participation API of the orderer
+func (o *LocalOrderer) adminTLSCredentials(ctx context.Context) (*x509.CertPool, tls.Certificate, error) {
 	// Get organization
 	org, err := o.orgService.GetOrganization(ctx, o.organizationID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get organization: %w", err)
+		return nil, tls.Certificate{}, fmt.Errorf("failed to get organization: %w", err)
 	}
 
 	// Get admin TLS credentials
 	adminTlsKeyDB, err := o.keyService.GetKey(ctx, int(org.AdminTlsKeyID.Int64))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get admin TLS key: %w", err)
+		return nil, tls.Certificate{}, fmt.Errorf("failed to get admin TLS key: %w", err)
 	}
 	adminTlsCert := adminTlsKeyDB.Certificate
 	if adminTlsCert == nil {
-		return nil, fmt.Errorf("admin TLS certificate is nil")
+		return nil, tls.Certificate{}, fmt.Errorf("admin TLS certificate is nil")
 	}
 	if *adminTlsCert == "" {
-		return nil, fmt.Errorf("admin TLS certificate is empty")
+		return nil, tls.Certificate{}, fmt.Errorf("admin TLS certificate is empty")
 	}
 	adminTlsPK, err := o.keyService.GetDecryptedPrivateKey(int(org.AdminTlsKeyID.Int64))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get admin TLS private key: %w", err)
+		return nil, tls.Certificate{}, fmt.Errorf("failed to get admin TLS private key: %w", err)
 	}
 
 	// Create client certificate
 	cert, err := tls.X509KeyPair([]byte(*adminTlsCert), []byte(adminTlsPK))
 	if err != nil {
-		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		return nil, tls.Certificate{}, fmt.Errorf("failed to load client certificate: %w", err)
 	}
 
 	// Create CA cert pool
 	certPool := x509.NewCertPool()
 	ok := certPool.AppendCertsFromPEM([]byte(org.TlsCertificate))
 	if !ok {
-		return nil, fmt.Errorf("failed to append TLS root certificate to CA cert pool")
+		return nil, tls.Certificate{}, fmt.Errorf("failed to append TLS root certificate to CA cert pool")
+	}
+
+	return certPool, cert, nil
+}
+
+// GetChannels returns a list of channels the orderer is participating in
+func (o *LocalOrderer) GetChannels(ctx context.Context) ([]OrdererChannel, error) {
+	certPool, cert, err := o.adminTLSCredentials(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Call osnadmin List API
@@ -1242,6 +1354,53 @@ func (o *LocalOrderer) GetChannels(ctx context.Context) ([]OrdererChannel, error
 	return channels, nil
 }
 
+// GetChannelParticipation returns the consensus relation, status and height of the
+// orderer for every channel it is a member of
+func (o *LocalOrderer) GetChannelParticipation(ctx context.Context) ([]osnadmin.ChannelInfo, error) {
+	certPool, cert, err := o.adminTLSCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	osnURL := fmt.Sprintf("https://%s", strings.Replace(o.opts.AdminListenAddress, "0.0.0.0", "127.0.0.1", 1))
+
+	resp, err := osnadmin.ListAllChannels(osnURL, certPool, cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channels: %w", err)
+	}
+	var channelList osnadmin.ChannelList
+	if err := decodeOSNAdminResponse(resp, &channelList); err != nil {
+		return nil, fmt.Errorf("failed to list channels: %w", err)
+	}
+
+	channels := make([]osnadmin.ChannelInfo, 0, len(channelList.Channels))
+	for _, ch := range channelList.Channels {
+		resp, err := osnadmin.ListSingleChannel(osnURL, ch.Name, certPool, cert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get channel %s: %w", ch.Name, err)
+		}
+		var info osnadmin.ChannelInfo
+		if err := decodeOSNAdminResponse(resp, &info); err != nil {
+			return nil, fmt.Errorf("failed to get channel %s: %w", ch.Name, err)
+		}
+		channels = append(channels, info)
+	}
+
+	return channels, nil
+}
+
+// decodeOSNAdminResponse decodes the JSON body of a channel participation API response
+func decodeOSNAdminResponse(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var errResp osnadmin.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Error != "" {
+			return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, errResp.Error)
+		}
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
 // RenewCertificates renews the orderer's TLS and signing certificates
 func (o *LocalOrderer) RenewCertificates(ordererDeploymentConfig *types.FabricOrdererDeploymentConfig) error {
 	ctx := context.Background()
@@ -1364,9 +1523,9 @@ func (o *LocalOrderer) RenewCertificates(ordererDeploymentConfig *types.FabricOr
 	}
 
 	// Get the private keys
-	signKey, err := o.keyService.GetDecryptedPrivateKey(int(ordererDeploymentConfig.SignKeyID))
+	signKey, err := o.signPrivateKey(ctx, int(ordererDeploymentConfig.SignKeyID))
 	if err != nil {
-		return fmt.Errorf("failed to get sign private key: %w", err)
+		return err
 	}
 
 	tlsKey, err := o.keyService.GetDecryptedPrivateKey(int(ordererDeploymentConfig.TLSKeyID))
@@ -0,0 +1,261 @@
+package peer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	dockernetwork "github.com/docker/docker/api/types/network"
+	dockerclient "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+)
+
+// couchDBPort is the port CouchDB listens on inside its container
+const couchDBPort = "5984"
+
+// UsesCouchDB reports whether the peer stores its world state in CouchDB
+func (p *LocalPeer) UsesCouchDB() bool {
+	return p.opts.CouchDB != nil
+}
+
+// stateDatabase returns the value of the ledger.state.stateDatabase setting of the peer
+func (p *LocalPeer) stateDatabase() string {
+	if p.UsesCouchDB() {
+		return "CouchDB"
+	}
+	return "goleveldb"
+}
+
+// getCouchDBContainerName returns the name of the CouchDB container run next to the peer in docker mode
+func (p *LocalPeer) getCouchDBContainerName() (string, error) {
+	containerName, err := p.getContainerName()
+	if err != nil {
+		return "", err
+	}
+	return containerName + "-couchdb", nil
+}
+
+// getDockerNetworkName returns the network shared by the peer and CouchDB containers
+func (p *LocalPeer) getDockerNetworkName() (string, error) {
+	containerName, err := p.getContainerName()
+	if err != nil {
+		return "", err
+	}
+	return containerName + "-net", nil
+}
+
+// getCouchDBAddress returns the host:port the peer connects to CouchDB on
+func (p *LocalPeer) getCouchDBAddress() (string, error) {
+	if p.mode == "docker" {
+		containerName, err := p.getCouchDBContainerName()
+		if err != nil {
+			return "", fmt.Errorf("failed to get CouchDB container name: %w", err)
+		}
+		return fmt.Sprintf("%s:%s", containerName, couchDBPort), nil
+	}
+	u, err := url.Parse(p.opts.CouchDB.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid CouchDB URL: %w", err)
+	}
+	return u.Host, nil
+}
+
+// getCouchDBURL returns the URL ChainLaunch reaches the CouchDB of the peer on
+func (p *LocalPeer) getCouchDBURL() string {
+	if p.mode == "docker" {
+		return fmt.Sprintf("http://127.0.0.1:%d", p.opts.CouchDB.Port)
+	}
+	return p.opts.CouchDB.URL
+}
+
+// setCouchDBEnvironment points the peer to its state database. The password isn't
+// part of the environment, which ends up in service files and logs, it is read
+// from core.yaml.
+func (p *LocalPeer) setCouchDBEnvironment(env map[string]string) error {
+	env["CORE_LEDGER_STATE_STATEDATABASE"] = p.stateDatabase()
+	if !p.UsesCouchDB() {
+		return nil
+	}
+	address, err := p.getCouchDBAddress()
+	if err != nil {
+		return err
+	}
+	env["CORE_LEDGER_STATE_COUCHDBCONFIG_COUCHDBADDRESS"] = address
+	env["CORE_LEDGER_STATE_COUCHDBCONFIG_USERNAME"] = p.opts.CouchDB.Username
+	return nil
+}
+
+// setCouchDBTemplateData fills the state database settings of core.yaml. The
+// password must have been opened, see CouchDBOpts.
+func (p *LocalPeer) setCouchDBTemplateData(data *CoreTemplateData) error {
+	data.StateDatabase = p.stateDatabase()
+	data.CouchDBAddress = "127.0.0.1:" + couchDBPort
+	if !p.UsesCouchDB() {
+		return nil
+	}
+	address, err := p.getCouchDBAddress()
+	if err != nil {
+		return err
+	}
+	if p.opts.CouchDB.Password == "" {
+		return fmt.Errorf("the CouchDB password of the peer is not loaded")
+	}
+	data.CouchDBAddress = address
+	data.CouchDBUsername = p.opts.CouchDB.Username
+	data.CouchDBPassword = p.opts.CouchDB.Password
+	return nil
+}
+
+// startCouchDB runs the CouchDB container of the peer on the network shared with the
+// peer container. Its data is kept in the couchdb directory of the peer.
+func (p *LocalPeer) startCouchDB(ctx context.Context, cli *dockerclient.Client) (string, error) {
+	if p.opts.CouchDB.Password == "" {
+		return "", fmt.Errorf("the CouchDB password of the peer is not loaded")
+	}
+	networkName, err := p.getDockerNetworkName()
+	if err != nil {
+		return "", fmt.Errorf("failed to get network name: %w", err)
+	}
+	containerName, err := p.getCouchDBContainerName()
+	if err != nil {
+		return "", fmt.Errorf("failed to get CouchDB container name: %w", err)
+	}
+
+	if _, err := cli.NetworkInspect(ctx, networkName, dockernetwork.InspectOptions{}); err != nil {
+		if !dockerclient.IsErrNotFound(err) {
+			return "", fmt.Errorf("failed to inspect network %s: %w", networkName, err)
+		}
+		if _, err := cli.NetworkCreate(ctx, networkName, dockernetwork.CreateOptions{Driver: "bridge"}); err != nil {
+			return "", fmt.Errorf("failed to create network %s: %w", networkName, err)
+		}
+	}
+
+	imageName := p.opts.CouchDB.Image
+	reader, err := cli.ImagePull(ctx, imageName, image.PullOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to pull image %s: %w", imageName, err)
+	}
+	defer reader.Close()
+	io.Copy(io.Discard, reader) // Wait for pull to complete
+
+	dataPath := filepath.Join(p.getPeerPath(), "couchdb")
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to create CouchDB data directory: %w", err)
+	}
+
+	// Remove a container left behind by a peer that wasn't stopped cleanly
+	if err := cli.ContainerRemove(ctx, containerName, container.RemoveOptions{Force: true}); err != nil && !dockerclient.IsErrNotFound(err) {
+		return "", fmt.Errorf("failed to remove CouchDB container: %w", err)
+	}
+
+	port := nat.Port(couchDBPort + "/tcp")
+	resp, err := cli.ContainerCreate(ctx,
+		&container.Config{
+			Image: imageName,
+			Env: []string{
+				"COUCHDB_USER=" + p.opts.CouchDB.Username,
+				"COUCHDB_PASSWORD=" + p.opts.CouchDB.Password,
+			},
+			ExposedPorts: nat.PortSet{port: struct{}{}},
+		},
+		&container.HostConfig{
+			// Only published on localhost, for the health checks
+			PortBindings: nat.PortMap{
+				port: {{HostIP: "127.0.0.1", HostPort: strconv.Itoa(p.opts.CouchDB.Port)}},
+			},
+			Mounts: []mount.Mount{
+				{
+					Type:   mount.TypeBind,
+					Source: dataPath,
+					Target: "/opt/couchdb/data",
+				},
+			},
+		},
+		&dockernetwork.NetworkingConfig{
+			EndpointsConfig: map[string]*dockernetwork.EndpointSettings{
+				networkName: {},
+			},
+		},
+		nil,
+		containerName,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create CouchDB container: %w", err)
+	}
+	if err := cli.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return "", fmt.Errorf("failed to start CouchDB container: %w", err)
+	}
+
+	p.logger.Info("Started CouchDB container", "container", containerName, "network", networkName)
+	return networkName, nil
+}
+
+// stopCouchDB removes the CouchDB container of the peer and its network, the data is kept
+func (p *LocalPeer) stopCouchDB(ctx context.Context, cli *dockerclient.Client) {
+	containerName, err := p.getCouchDBContainerName()
+	if err != nil {
+		p.logger.Warn("Failed to get CouchDB container name", "error", err)
+		return
+	}
+	if err := cli.ContainerRemove(ctx, containerName, container.RemoveOptions{Force: true}); err != nil && !dockerclient.IsErrNotFound(err) {
+		p.logger.Warn("Failed to remove CouchDB container", "container", containerName, "error", err)
+	}
+
+	networkName, err := p.getDockerNetworkName()
+	if err != nil {
+		p.logger.Warn("Failed to get network name", "error", err)
+		return
+	}
+	if err := cli.NetworkRemove(ctx, networkName); err != nil && !dockerclient.IsErrNotFound(err) {
+		p.logger.Warn("Failed to remove docker network", "network", networkName, "error", err)
+	}
+}
+
+// RemoveCouchDB removes the CouchDB container of a docker peer, whether or not the peer is running
+func (p *LocalPeer) RemoveCouchDB() error {
+	if p.mode != "docker" || !p.UsesCouchDB() {
+		return nil
+	}
+	cli, err := dockerclient.NewClientWithOpts(
+		dockerclient.FromEnv,
+		dockerclient.WithAPIVersionNegotiation(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create docker client: %w", err)
+	}
+	defer cli.Close()
+
+	p.stopCouchDB(context.Background(), cli)
+	return nil
+}
+
+// CheckCouchDB checks that the CouchDB of the peer is up and accepts its credentials
+func (p *LocalPeer) CheckCouchDB(ctx context.Context) error {
+	if !p.UsesCouchDB() {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.getCouchDBURL()+"/_up", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(p.opts.CouchDB.Username, p.opts.CouchDB.Password)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach CouchDB: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("CouchDB returned status %d: %s", resp.StatusCode, body)
+	}
+	return nil
+}
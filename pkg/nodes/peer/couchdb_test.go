@@ -0,0 +1,197 @@
+package peer
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/chainlaunch/chainlaunch/pkg/config"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	fabricservice "github.com/chainlaunch/chainlaunch/pkg/fabric/service"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+)
+
+func newTestQueries(t *testing.T) *db.Queries {
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	driver, err := sqlite3.WithInstance(database, &sqlite3.Config{})
+	if err != nil {
+		t.Fatalf("failed to create sqlite driver: %v", err)
+	}
+	source, err := iofs.New(os.DirFS("../../db/migrations"), ".")
+	if err != nil {
+		t.Fatalf("failed to open migrations: %v", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", driver)
+	if err != nil {
+		t.Fatalf("failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return db.New(database)
+}
+
+// newTestPeer returns a peer of Org1MSP named "Peer0 Org1"
+func newTestPeer(t *testing.T, mode string, couchDB *CouchDBOpts) *LocalPeer {
+	queries := newTestQueries(t)
+	org, err := queries.CreateFabricOrganization(context.Background(), &db.CreateFabricOrganizationParams{MspID: "Org1MSP"})
+	if err != nil {
+		t.Fatalf("failed to create organization: %v", err)
+	}
+	configService := config.NewConfigService(t.TempDir())
+	return NewLocalPeer(
+		"Org1MSP",
+		queries,
+		StartPeerOpts{ID: "Peer0 Org1", CouchDB: couchDB},
+		mode,
+		nil,
+		org.ID,
+		fabricservice.NewOrganizationService(queries, nil, configService),
+		nil,
+		1,
+		logger.NewDefault(),
+		configService,
+		nil,
+	)
+}
+
+func TestCouchDBCompanionContainer(t *testing.T) {
+	p := newTestPeer(t, "docker", &CouchDBOpts{Image: "couchdb:3.3.3", Port: 15984, Username: "admin", Password: "secret"})
+
+	containerName, err := p.getCouchDBContainerName()
+	if err != nil {
+		t.Fatalf("failed to get container name: %v", err)
+	}
+	if containerName != "org1msp-peer0-org1-couchdb" {
+		t.Errorf("unexpected container name %q", containerName)
+	}
+	networkName, err := p.getDockerNetworkName()
+	if err != nil {
+		t.Fatalf("failed to get network name: %v", err)
+	}
+	if networkName != "org1msp-peer0-org1-net" {
+		t.Errorf("unexpected network name %q", networkName)
+	}
+	// The peer reaches CouchDB on the shared network, ChainLaunch on the published port
+	address, err := p.getCouchDBAddress()
+	if err != nil {
+		t.Fatalf("failed to get address: %v", err)
+	}
+	if address != "org1msp-peer0-org1-couchdb:5984" {
+		t.Errorf("unexpected address %q", address)
+	}
+	if url := p.getCouchDBURL(); url != "http://127.0.0.1:15984" {
+		t.Errorf("unexpected URL %q", url)
+	}
+
+	// The container isn't started without the password
+	p.opts.CouchDB.Password = ""
+	if _, err := p.startCouchDB(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "password") {
+		t.Errorf("expected a missing password error, got %v", err)
+	}
+}
+
+func TestCouchDBExternalAddress(t *testing.T) {
+	p := newTestPeer(t, "service", &CouchDBOpts{URL: "http://couchdb.example.com:5984", Username: "admin"})
+	address, err := p.getCouchDBAddress()
+	if err != nil {
+		t.Fatalf("failed to get address: %v", err)
+	}
+	if address != "couchdb.example.com:5984" {
+		t.Errorf("unexpected address %q", address)
+	}
+	if url := p.getCouchDBURL(); url != "http://couchdb.example.com:5984" {
+		t.Errorf("unexpected URL %q", url)
+	}
+}
+
+func TestSetCouchDBEnvironment(t *testing.T) {
+	env := map[string]string{}
+	if err := newTestPeer(t, "service", nil).setCouchDBEnvironment(env); err != nil {
+		t.Fatalf("failed to set environment: %v", err)
+	}
+	if env["CORE_LEDGER_STATE_STATEDATABASE"] != "goleveldb" || len(env) != 1 {
+		t.Errorf("unexpected goleveldb environment %v", env)
+	}
+
+	env = map[string]string{}
+	p := newTestPeer(t, "docker", &CouchDBOpts{Port: 15984, Username: "admin", Password: "secret"})
+	if err := p.setCouchDBEnvironment(env); err != nil {
+		t.Fatalf("failed to set environment: %v", err)
+	}
+	expected := map[string]string{
+		"CORE_LEDGER_STATE_STATEDATABASE":                "CouchDB",
+		"CORE_LEDGER_STATE_COUCHDBCONFIG_COUCHDBADDRESS": "org1msp-peer0-org1-couchdb:5984",
+		"CORE_LEDGER_STATE_COUCHDBCONFIG_USERNAME":       "admin",
+	}
+	for key, value := range expected {
+		if env[key] != value {
+			t.Errorf("%s: expected %q, got %q", key, value, env[key])
+		}
+	}
+	// The environment ends up in service files and logs
+	for key, value := range env {
+		if strings.Contains(key, "PASSWORD") || strings.Contains(value, "secret") {
+			t.Errorf("the password is part of the environment: %s=%s", key, value)
+		}
+	}
+}
+
+func TestCoreYamlCouchDBPassword(t *testing.T) {
+	p := newTestPeer(t, "service", &CouchDBOpts{URL: "http://couchdb.example.com:5984", Username: "admin", Password: `pa"ss`})
+	var data CoreTemplateData
+	if err := p.setCouchDBTemplateData(&data); err != nil {
+		t.Fatalf("failed to set template data: %v", err)
+	}
+	tmpl, err := template.New("core.yaml").Parse(coreYamlTemplate)
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatalf("failed to execute template: %v", err)
+	}
+	for _, line := range []string{
+		"stateDatabase: CouchDB",
+		"couchDBAddress: couchdb.example.com:5984",
+		"username: admin",
+		`password: "pa\"ss"`,
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("core.yaml is missing %q", line)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := writeCoreYaml(dir, buf.Bytes()); err != nil {
+		t.Fatalf("failed to write core.yaml: %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "core.yaml"))
+	if err != nil {
+		t.Fatalf("failed to stat core.yaml: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected core.yaml to be readable by its owner only, got %v", info.Mode().Perm())
+	}
+
+	// core.yaml isn't written without the password
+	p.opts.CouchDB.Password = ""
+	if err := p.setCouchDBTemplateData(&CoreTemplateData{}); err == nil {
+		t.Error("expected an error when the password is not loaded")
+	}
+}
@@ -4,8 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -40,6 +40,7 @@ import (
 	"github.com/chainlaunch/chainlaunch/pkg/db"
 	fabricservice "github.com/chainlaunch/chainlaunch/pkg/fabric/service"
 	kmodels "github.com/chainlaunch/chainlaunch/pkg/keymanagement/models"
+	kmtypes "github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/types"
 	keymanagement "github.com/chainlaunch/chainlaunch/pkg/keymanagement/service"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
 	nodetypes "github.com/chainlaunch/chainlaunch/pkg/nodes/types"
@@ -47,6 +48,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/api/types/mount"
+	dockernetwork "github.com/docker/docker/api/types/network"
 	dockerclient "github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
 )
@@ -374,7 +376,7 @@ peer:
       # Token Label
       Label:
       # User PIN
-      Pin:
+      Pin: {{if .PKCS11Pin}}{{printf "%q" .PKCS11Pin}}{{end}}
       Hash:
       Security:
 
@@ -669,7 +671,7 @@ ledger:
     # stateDatabase - options are "goleveldb", "CouchDB"
     # goleveldb - default state database stored in goleveldb.
     # CouchDB - store state database in CouchDB
-    stateDatabase: goleveldb
+    stateDatabase: {{.StateDatabase}}
     # Limit on the number of records to return per query
     totalQueryLimit: 100000
     couchDBConfig:
@@ -677,14 +679,14 @@ ledger:
       # not map the CouchDB container port to a server port in docker-compose.
       # Otherwise proper security must be provided on the connection between
       # CouchDB client (on the peer) and server.
-      couchDBAddress: 127.0.0.1:5984
+      couchDBAddress: {{.CouchDBAddress}}
       # This username must have read and write authority on CouchDB
-      username:
+      username: {{.CouchDBUsername}}
       # The password is recommended to pass as an environment variable
       # during start up (eg CORE_LEDGER_STATE_COUCHDBCONFIG_PASSWORD).
       # If it is stored here, the file must be access control protected
       # to prevent unintended users from discovering the password.
-      password:
+      password: {{if .CouchDBPassword}}{{printf "%q" .CouchDBPassword}}{{end}}
       # Number of retries for CouchDB errors
       maxRetries: 3
       # Number of retries for CouchDB errors during peer startup.
@@ -928,7 +930,16 @@ func (p *LocalPeer) Init() (nodetypes.NodeDeploymentConfig, error) {
 	isCA := 0
 	description := "Sign key for " + p.opts.ID
 	curveP256 := kmodels.ECCurveP256
+	// The sign key lives in the organization's key provider; TLS keys have to be
+	// written to disk so they always come from a provider that can export them
 	providerID := 1
+	if org.ProviderID != 0 {
+		providerID = int(org.ProviderID)
+	}
+	tlsProviderID, err := p.keyService.ExportableProviderID(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve TLS key provider: %w", err)
+	}
 
 	// Create Sign Key
 	signKeyDB, err := p.keyService.CreateKey(ctx, kmodels.CreateKeyRequest{
@@ -957,9 +968,9 @@ func (p *LocalPeer) Init() (nodetypes.NodeDeploymentConfig, error) {
 		return nil, fmt.Errorf("failed to sign sign key: %w", err)
 	}
 
-	signKey, err := p.keyService.GetDecryptedPrivateKey(int(signKeyDB.ID))
+	signKey, err := p.signPrivateKey(ctx, int(signKeyDB.ID))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sign private key: %w", err)
+		return nil, err
 	}
 
 	// Create TLS key
@@ -969,7 +980,7 @@ func (p *LocalPeer) Init() (nodetypes.NodeDeploymentConfig, error) {
 		IsCA:        &isCA,
 		Description: &description,
 		Curve:       &curveP256,
-		ProviderID:  &providerID,
+		ProviderID:  &tlsProviderID,
 	}, int(org.SignKeyID.Int64))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create sign key: %w", err)
@@ -1118,6 +1129,12 @@ func (p *LocalPeer) Start() (interface{}, error) {
 	}
 	cmd := cmdBuf.String()
 	env := p.buildPeerEnvironment(mspConfigPath)
+	if err := p.setPKCS11Environment(context.Background(), env); err != nil {
+		return nil, err
+	}
+	if err := p.setCouchDBEnvironment(env); err != nil {
+		return nil, err
+	}
 
 	p.logger.Debug("Starting peer",
 		"mode", p.mode,
@@ -1183,7 +1200,6 @@ func (p *LocalPeer) buildPeerEnvironment(mspConfigPath string) map[string]string
 	env["CORE_METRICS_PROVIDER"] = "prometheus"
 	env["CORE_LOGGING_CAUTHDSL"] = "info"
 	env["CORE_LOGGING_POLICIES"] = "info"
-	env["CORE_LEDGER_STATE_STATEDATABASE"] = "goleveldb"
 	env["CORE_PEER_TLS_ENABLED"] = "true"
 	env["CORE_LOGGING_GRPC"] = "info"
 	env["CORE_LOGGING_PEER"] = "info"
@@ -1273,6 +1289,20 @@ func (p *LocalPeer) startDocker(env map[string]string, mspConfigPath, dataConfig
 		containerConfig.ExposedPorts[port] = struct{}{}
 	}
 
+	// The peer reaches its CouchDB container by name on a network they share
+	var networkingConfig *dockernetwork.NetworkingConfig
+	if p.UsesCouchDB() {
+		networkName, err := p.startCouchDB(context.Background(), cli)
+		if err != nil {
+			return nil, err
+		}
+		networkingConfig = &dockernetwork.NetworkingConfig{
+			EndpointsConfig: map[string]*dockernetwork.EndpointSettings{
+				networkName: {},
+			},
+		}
+	}
+
 	// Create container
 	resp, err := cli.ContainerCreate(context.Background(),
 		containerConfig,
@@ -1280,7 +1310,7 @@ func (p *LocalPeer) startDocker(env map[string]string, mspConfigPath, dataConfig
 			PortBindings: portBindings,
 			Mounts:       mounts,
 		},
-		nil,
+		networkingConfig,
 		nil,
 		containerName,
 	)
@@ -1367,6 +1397,9 @@ func (p *LocalPeer) stopDocker() error {
 		p.logger.Warn("Failed to remove docker container", "error", err)
 		// Don't return error as container might not exist
 	}
+	if p.UsesCouchDB() {
+		p.stopCouchDB(ctx, cli)
+	}
 
 	return nil
 }
@@ -1560,9 +1593,9 @@ func (p *LocalPeer) RenewCertificates(peerDeploymentConfig *nodetypes.FabricPeer
 	}
 
 	// Get the private keys
-	signKey, err := p.keyService.GetDecryptedPrivateKey(int(peerDeploymentConfig.SignKeyID))
+	signKey, err := p.signPrivateKey(ctx, int(peerDeploymentConfig.SignKeyID))
 	if err != nil {
-		return fmt.Errorf("failed to get sign private key: %w", err)
+		return err
 	}
 
 	tlsKey, err := p.keyService.GetDecryptedPrivateKey(int(peerDeploymentConfig.TLSKeyID))
@@ -1887,6 +1920,13 @@ func (p *LocalPeer) writeCertificatesAndKeys(
 	if err := os.MkdirAll(keystorePath, 0755); err != nil {
 		return fmt.Errorf("failed to create keystore directory: %w", err)
 	}
+	if signKey == "" {
+		// The signing key stays in the HSM, the peer reaches it through the PKCS11 BCCSP
+		if err := os.Remove(filepath.Join(keystorePath, "key.pem")); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove signing key: %w", err)
+		}
+		return nil
+	}
 	if err := os.WriteFile(filepath.Join(keystorePath, "key.pem"), []byte(signKey), 0600); err != nil {
 		return fmt.Errorf("failed to write signing key: %w", err)
 	}
@@ -1894,6 +1934,98 @@ func (p *LocalPeer) writeCertificatesAndKeys(
 	return nil
 }
 
+// signPrivateKey returns the PEM encoded signing key to write to the keystore,
+// or an empty string if the key is held in a PKCS#11 token
+func (p *LocalPeer) signPrivateKey(ctx context.Context, keyID int) (string, error) {
+	settings, err := p.keyService.GetPKCS11Settings(ctx, keyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sign key provider: %w", err)
+	}
+	if settings != nil {
+		if p.mode == "docker" {
+			return "", fmt.Errorf("HSM backed sign keys are not supported in docker mode")
+		}
+		return "", nil
+	}
+	signKey, err := p.keyService.GetDecryptedPrivateKey(keyID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sign private key: %w", err)
+	}
+	return signKey, nil
+}
+
+// signKeyPKCS11Settings returns the token settings of the peer signing key, or
+// nil if the key isn't held in a PKCS#11 token
+func (p *LocalPeer) signKeyPKCS11Settings(ctx context.Context) (*kmtypes.PKCS11Settings, error) {
+	node, err := p.db.GetNode(ctx, p.nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+	if !node.DeploymentConfig.Valid || node.DeploymentConfig.String == "" {
+		return nil, nil
+	}
+	var deployment struct {
+		SignKeyID int64 `json:"signKeyId"`
+	}
+	if err := json.Unmarshal([]byte(node.DeploymentConfig.String), &deployment); err != nil {
+		return nil, fmt.Errorf("failed to decode deployment config: %w", err)
+	}
+	if deployment.SignKeyID == 0 {
+		return nil, nil
+	}
+	settings, err := p.keyService.GetPKCS11Settings(ctx, int(deployment.SignKeyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sign key provider: %w", err)
+	}
+	return settings, nil
+}
+
+// setPKCS11Environment switches the peer BCCSP to PKCS11 when its signing key
+// is held in a PKCS#11 token. The PIN isn't part of the environment, which ends
+// up in service files and logs, it is read from core.yaml.
+func (p *LocalPeer) setPKCS11Environment(ctx context.Context, env map[string]string) error {
+	settings, err := p.signKeyPKCS11Settings(ctx)
+	if err != nil {
+		return err
+	}
+	if settings == nil {
+		return nil
+	}
+	env["CORE_PEER_BCCSP_DEFAULT"] = "PKCS11"
+	env["CORE_PEER_BCCSP_PKCS11_LIBRARY"] = settings.Library
+	env["CORE_PEER_BCCSP_PKCS11_LABEL"] = settings.Label
+	env["CORE_PEER_BCCSP_PKCS11_HASH"] = "SHA2"
+	env["CORE_PEER_BCCSP_PKCS11_SECURITY"] = "256"
+	return nil
+}
+
+// setPKCS11TemplateData sets the PKCS#11 user PIN of core.yaml when the peer
+// signing key is held in a PKCS#11 token
+func (p *LocalPeer) setPKCS11TemplateData(data *CoreTemplateData) error {
+	settings, err := p.signKeyPKCS11Settings(context.Background())
+	if err != nil {
+		return err
+	}
+	if settings != nil {
+		data.PKCS11Pin = settings.Pin
+	}
+	return nil
+}
+
+// writeCoreYaml writes core.yaml readable by its owner only, as it may hold the
+// PKCS#11 user PIN or the CouchDB password
+func writeCoreYaml(mspConfigPath string, content []byte) error {
+	path := filepath.Join(mspConfigPath, "core.yaml")
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return fmt.Errorf("failed to write core.yaml: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("failed to set core.yaml permissions: %w", err)
+	}
+	return nil
+}
+
 // setupExternalBuilders creates and configures the external builders for chaincode
 func (p *LocalPeer) setupExternalBuilders(mspConfigPath string) error {
 	// Create external builder directory structure
@@ -2001,6 +2133,11 @@ type CoreTemplateData struct {
 	ExternalBuilderPath     string
 	OperationsListenAddress string
 	AddressOverrides        []AddressOverridePath
+	StateDatabase           string
+	CouchDBAddress          string
+	CouchDBUsername         string
+	CouchDBPassword         string
+	PKCS11Pin               string
 }
 
 // writeConfigFiles writes the config.yaml and core.yaml files
@@ -2040,6 +2177,12 @@ func (p *LocalPeer) writeConfigFiles(mspConfigPath, dataConfigPath string) error
 			AddressOverrides:        convertedOverrides,
 		}
 	}
+	if err := p.setCouchDBTemplateData(&data); err != nil {
+		return err
+	}
+	if err := p.setPKCS11TemplateData(&data); err != nil {
+		return err
+	}
 	// Create template
 	tmpl, err := template.New("core.yaml").Parse(coreYamlTemplate)
 	if err != nil {
@@ -2053,8 +2196,8 @@ func (p *LocalPeer) writeConfigFiles(mspConfigPath, dataConfigPath string) error
 	}
 
 	// Write core.yaml
-	if err := os.WriteFile(filepath.Join(mspConfigPath, "core.yaml"), buf.Bytes(), 0644); err != nil {
-		return fmt.Errorf("failed to write core.yaml: %w", err)
+	if err := writeCoreYaml(mspConfigPath, buf.Bytes()); err != nil {
+		return err
 	}
 
 	return nil
@@ -2232,9 +2375,18 @@ func (p *LocalPeer) PrepareAdminCertMSP(mspID string) (string, error) {
 	if adminSignKeyDB.Certificate == nil {
 		return "", fmt.Errorf("admin sign key is not set")
 	}
-	adminSignKey, err := p.keyService.GetDecryptedPrivateKey(int(org.AdminSignKeyID.Int64))
+	// Keys held in a PKCS#11 token aren't written to the keystore, the PKCS11
+	// BCCSP finds them on the token by their SKI
+	adminSignKeySettings, err := p.keyService.GetPKCS11Settings(ctx, int(org.AdminSignKeyID.Int64))
 	if err != nil {
-		return "", fmt.Errorf("failed to get decrypted admin sign key: %w", err)
+		return "", fmt.Errorf("failed to get admin sign key provider: %w", err)
+	}
+	adminSignKey := ""
+	if adminSignKeySettings == nil {
+		adminSignKey, err = p.keyService.GetDecryptedPrivateKey(int(org.AdminSignKeyID.Int64))
+		if err != nil {
+			return "", fmt.Errorf("failed to get decrypted admin sign key: %w", err)
+		}
 	}
 	adminCert := *adminSignKeyDB.Certificate
 	signCAKeyDB, err := p.keyService.GetKey(ctx, int(org.SignKeyID.Int64))
@@ -2263,6 +2415,10 @@ func (p *LocalPeer) PrepareAdminCertMSP(mspID string) (string, error) {
 		filepath.Join(adminMspPath, "tlscacerts", "cacert.pem"): tlsCACert,
 	}
 
+	if adminSignKey == "" {
+		delete(files, filepath.Join(adminMspPath, "keystore", "priv_sk"))
+	}
+
 	for path, content := range files {
 		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
 			return "", fmt.Errorf("failed to write file %s: %w", path, err)
@@ -2507,40 +2663,16 @@ func (p *LocalPeer) CreatePeerConnection(ctx context.Context, peerURL string, pe
 func (p *LocalPeer) GetMSPID() string {
 	return p.mspID
 }
-func (p *LocalPeer) GetAdminIdentity(ctx context.Context) (identity.SigningIdentity, gwidentity.Sign, error) {
-	adminSignKeyDB, err := p.keyService.GetKey(ctx, int(p.org.AdminSignKeyID.Int64))
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get TLS CA key: %w", err)
-	}
-	if adminSignKeyDB.Certificate == nil {
-		return nil, nil, fmt.Errorf("TLS CA key is not set")
-	}
-	certificate := *adminSignKeyDB.Certificate
-	privateKey, err := p.keyService.GetDecryptedPrivateKey(int(p.org.AdminSignKeyID.Int64))
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get decrypted private key: %w", err)
-	}
-
-	cert, err := gwidentity.CertificateFromPEM([]byte(certificate))
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read certificate: %w", err)
-	}
 
-	priv, err := gwidentity.PrivateKeyFromPEM([]byte(privateKey))
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read private key: %w", err)
-	}
-
-	signingIdentity, err := identity.NewPrivateKeySigningIdentity(p.mspID, cert, priv)
+// GetAdminIdentity returns the admin identity of the peer organization and its
+// sign function for gateway connections. The private key stays in its key
+// provider, so HSM keys can be used.
+func (p *LocalPeer) GetAdminIdentity(ctx context.Context) (identity.SigningIdentity, gwidentity.Sign, error) {
+	signingIdentity, err := p.keyService.GetSigningIdentity(ctx, int(p.org.AdminSignKeyID.Int64), p.mspID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create signing identity: %w", err)
 	}
-
-	signer, err := gwidentity.NewPrivateKeySign(priv)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create signer: %w", err)
-	}
-	return signingIdentity, signer, nil
+	return signingIdentity, signingIdentity.SignDigest, nil
 }
 
 // Add this struct near the top with other type definitions
@@ -2579,33 +2711,6 @@ func (p *LocalPeer) GetChannelBlock(ctx context.Context, channelID string, order
 
 }
 
-// getOrdererTLSKeyPair creates a TLS key pair for secure communication with the orderer
-func (p *LocalPeer) getOrdererTLSKeyPair(ctx context.Context, ordererTLSCert string) (tls.Certificate, error) {
-	// Get organization details
-	org, err := p.orgService.GetOrganizationByMspID(ctx, p.mspID)
-	if err != nil {
-		return tls.Certificate{}, fmt.Errorf("failed to get organization: %w", err)
-	}
-
-	if !org.AdminSignKeyID.Valid {
-		return tls.Certificate{}, fmt.Errorf("organization has no admin sign key")
-	}
-
-	// Get private key from key management service
-	privateKeyPEM, err := p.keyService.GetDecryptedPrivateKey(int(org.AdminSignKeyID.Int64))
-	if err != nil {
-		return tls.Certificate{}, fmt.Errorf("failed to get private key: %w", err)
-	}
-
-	// Parse the orderer TLS certificate
-	ordererTLSCertParsed, err := tls.X509KeyPair([]byte(ordererTLSCert), []byte(privateKeyPEM))
-	if err != nil {
-		return tls.Certificate{}, fmt.Errorf("failed to parse orderer TLS certificate: %w", err)
-	}
-
-	return ordererTLSCertParsed, nil
-}
-
 // Add this new method to the LocalPeer struct
 func (p *LocalPeer) GetChannelConfig(ctx context.Context, channelID string, ordererUrl string, ordererTlsCACert string) (*GetChannelConfigResponse, error) {
 
@@ -3078,6 +3183,12 @@ func (p *LocalPeer) SynchronizeConfig(deployConfig *nodetypes.FabricPeerDeployme
 			AddressOverrides:        convertedOverrides,
 		}
 	}
+	if err := p.setCouchDBTemplateData(&data); err != nil {
+		return err
+	}
+	if err := p.setPKCS11TemplateData(&data); err != nil {
+		return err
+	}
 
 	// Create template
 	tmpl, err := template.New("core.yaml").Parse(coreYamlTemplate)
@@ -3092,8 +3203,8 @@ func (p *LocalPeer) SynchronizeConfig(deployConfig *nodetypes.FabricPeerDeployme
 	}
 
 	// Write core.yaml
-	if err := os.WriteFile(filepath.Join(mspConfigPath, "core.yaml"), buf.Bytes(), 0644); err != nil {
-		return fmt.Errorf("failed to write core.yaml: %w", err)
+	if err := writeCoreYaml(mspConfigPath, buf.Bytes()); err != nil {
+		return err
 	}
 
 	// Stop the peer if it's running
@@ -0,0 +1,108 @@
+package peer
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/chainlaunch/chainlaunch/internal/protoutil"
+	"github.com/hyperledger/fabric-admin-sdk/pkg/identity"
+	pb "github.com/hyperledger/fabric-protos-go-apiv2/peer"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// GetSnapshotsPath returns the directory where the peer writes the completed
+// ledger snapshots of a channel, one subdirectory per block number
+func (p *LocalPeer) GetSnapshotsPath(channelID string) string {
+	return filepath.Join(p.getPeerPath(), "data", "snapshots", "completed", channelID)
+}
+
+// GenerateLedgerSnapshot asks the peer to snapshot the ledger of a channel at
+// the last committed block. The snapshot is written asynchronously, it is
+// complete once PendingLedgerSnapshots no longer returns it.
+func (p *LocalPeer) GenerateLedgerSnapshot(ctx context.Context, channelID string) error {
+	client, signer, conn, err := p.snapshotClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	header, err := protoutil.NewSignatureHeader(signer)
+	if err != nil {
+		return fmt.Errorf("failed to create signature header: %w", err)
+	}
+	request, err := signSnapshotRequest(signer, &pb.SnapshotRequest{
+		SignatureHeader: header,
+		ChannelId:       channelID,
+		// Block 0 snapshots the last committed block
+		BlockNumber: 0,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := client.Generate(ctx, request); err != nil {
+		return fmt.Errorf("failed to request snapshot of channel %s: %w", channelID, err)
+	}
+	return nil
+}
+
+// PendingLedgerSnapshots returns the block numbers of the snapshots of a channel
+// the peer has not finished writing
+func (p *LocalPeer) PendingLedgerSnapshots(ctx context.Context, channelID string) ([]uint64, error) {
+	client, signer, conn, err := p.snapshotClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	header, err := protoutil.NewSignatureHeader(signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signature header: %w", err)
+	}
+	request, err := signSnapshotRequest(signer, &pb.SnapshotQuery{
+		SignatureHeader: header,
+		ChannelId:       channelID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	response, err := client.QueryPendings(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending snapshots of channel %s: %w", channelID, err)
+	}
+	return response.BlockNumbers, nil
+}
+
+// snapshotClient connects to the snapshot service of the peer with the admin identity of its organization
+func (p *LocalPeer) snapshotClient(ctx context.Context) (pb.SnapshotClient, identity.SigningIdentity, *grpc.ClientConn, error) {
+	tlsCACert, err := p.GetTLSRootCACert(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get TLS CA cert: %w", err)
+	}
+	adminIdentity, _, err := p.GetAdminIdentity(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get admin identity: %w", err)
+	}
+	conn, err := p.CreatePeerConnection(ctx, p.GetPeerAddress(), tlsCACert)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create peer connection: %w", err)
+	}
+	return pb.NewSnapshotClient(conn), adminIdentity, conn, nil
+}
+
+// signSnapshotRequest wraps a snapshot request or query with the signature of the admin
+func signSnapshotRequest(signer identity.SigningIdentity, request proto.Message) (*pb.SignedSnapshotRequest, error) {
+	requestBytes, err := proto.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal snapshot request: %w", err)
+	}
+	signature, err := signer.Sign(requestBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign snapshot request: %w", err)
+	}
+	return &pb.SignedSnapshotRequest{
+		Request:   requestBytes,
+		Signature: signature,
+	}, nil
+}
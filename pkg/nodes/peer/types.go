@@ -14,6 +14,21 @@ type StartPeerOpts struct {
 	Env                     map[string]string       `json:"env"`
 	Version                 string                  `json:"version"` // Fabric version to use
 	AddressOverrides        []types.AddressOverride `json:"addressOverrides,omitempty"`
+	// CouchDB is the state database of the peer, goleveldb is used when nil
+	CouchDB *CouchDBOpts `json:"couchDB,omitempty"`
+}
+
+// CouchDBOpts represents the CouchDB state database of a peer
+type CouchDBOpts struct {
+	// Image and Port are the image and host port of the container run in docker mode
+	Image string `json:"image,omitempty"`
+	Port  int    `json:"port,omitempty"`
+	// URL is the external CouchDB used in service mode
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username"`
+	// Password is only opened to start the peer or write its configuration, and
+	// is left out of the options written to the logs
+	Password string `json:"-"`
 }
 
 // PeerConfig represents the configuration for a peer node
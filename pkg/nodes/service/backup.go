@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/errors"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/utils"
+)
+
+// ledgerSnapshotPollInterval is how often a peer is asked whether its ledger snapshots are written
+const ledgerSnapshotPollInterval = 2 * time.Second
+
+// NodeBackupSource describes where a node keeps its files, for backups
+type NodeBackupSource struct {
+	NodeID   int64            `json:"nodeId"`
+	Name     string           `json:"name"`
+	NodeType types.NodeType   `json:"nodeType"`
+	Status   types.NodeStatus `json:"status"`
+	// Mode is the deployment mode, service or docker
+	Mode string `json:"mode"`
+	// DataDir is the directory of the node, relative to the data path and slash separated
+	DataDir string `json:"dataDir"`
+	// ContainerName is the container running the node in docker mode
+	ContainerName string `json:"containerName,omitempty"`
+}
+
+// LedgerSnapshot is a ledger snapshot written by a peer
+type LedgerSnapshot struct {
+	ChannelID   string `json:"channelId"`
+	BlockNumber uint64 `json:"blockNumber"`
+	// Dir is the directory of the snapshot
+	Dir string `json:"dir"`
+}
+
+// slugify returns the directory and container name component the nodes derive from a name
+func slugify(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "-")
+}
+
+// GetNodeBackupSource returns the directory and container of a node
+func (s *NodeService) GetNodeBackupSource(ctx context.Context, id int64) (*NodeBackupSource, error) {
+	node, err := s.db.GetNode(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("node not found", nil)
+		}
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	source := &NodeBackupSource{
+		NodeID:   node.ID,
+		Name:     node.Name,
+		NodeType: types.NodeType(node.NodeType.String),
+		Status:   types.NodeStatus(node.Status),
+		Mode:     "service",
+	}
+	var deploymentConfig types.NodeDeploymentConfig
+	if node.DeploymentConfig.Valid {
+		deploymentConfig, err = utils.DeserializeDeploymentConfig(node.DeploymentConfig.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to deserialize deployment config: %w", err)
+		}
+		if mode := deploymentConfig.GetMode(); mode != "" {
+			source.Mode = mode
+		}
+	}
+
+	// The paths and container names follow the ones of the peer, orderer, ca and besu packages
+	switch source.NodeType {
+	case types.NodeTypeFabricPeer:
+		source.DataDir = path.Join("peers", slugify(node.Slug))
+		if deploymentConfig != nil {
+			if config := deploymentConfig.ToFabricPeerConfig(); config != nil {
+				source.ContainerName = fmt.Sprintf("%s-%s", strings.ToLower(config.MSPID), slugify(node.Slug))
+			}
+		}
+	case types.NodeTypeFabricOrderer:
+		source.DataDir = path.Join("orderers", slugify(node.Name))
+		source.ContainerName = slugify(node.Name)
+	case types.NodeTypeFabricCA:
+		source.DataDir = path.Join("cas", slugify(node.Name))
+		source.ContainerName = slugify(node.Name)
+	case types.NodeTypeBesuFullnode:
+		source.DataDir = path.Join("besu", slugify(node.Slug))
+		source.ContainerName = "besu-" + slugify(node.Slug)
+	default:
+		return nil, fmt.Errorf("unsupported node type: %s", node.NodeType.String)
+	}
+	if source.Mode != "docker" {
+		source.ContainerName = ""
+	}
+	return source, nil
+}
+
+// CreateLedgerSnapshots snapshots the ledger of every channel joined by a peer
+// at its last committed block, and waits until the peer has written them
+func (s *NodeService) CreateLedgerSnapshots(ctx context.Context, peerID int64) ([]LedgerSnapshot, error) {
+	localPeer, err := s.GetFabricPeer(ctx, peerID)
+	if err != nil {
+		return nil, err
+	}
+	channels, err := localPeer.GetChannels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get peer channels: %w", err)
+	}
+
+	for _, channel := range channels {
+		if err := localPeer.GenerateLedgerSnapshot(ctx, channel.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	var snapshots []LedgerSnapshot
+	for _, channel := range channels {
+		for {
+			pending, err := localPeer.PendingLedgerSnapshots(ctx, channel.Name)
+			if err != nil {
+				return nil, err
+			}
+			if len(pending) == 0 {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return nil, fmt.Errorf("snapshot of channel %s was not written: %w", channel.Name, ctx.Err())
+			case <-time.After(ledgerSnapshotPollInterval):
+			}
+		}
+
+		// The newest snapshot is the one just written, or an identical one when no block was committed since
+		dir := localPeer.GetSnapshotsPath(channel.Name)
+		blocks, err := snapshotBlocks(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(blocks) == 0 {
+			return nil, fmt.Errorf("peer wrote no snapshot of channel %s, check the peer logs", channel.Name)
+		}
+		block := blocks[len(blocks)-1]
+		snapshots = append(snapshots, LedgerSnapshot{
+			ChannelID:   channel.Name,
+			BlockNumber: block,
+			Dir:         filepath.Join(dir, strconv.FormatUint(block, 10)),
+		})
+		s.logger.Info("Created ledger snapshot", "nodeID", peerID, "channel", channel.Name, "block", block)
+	}
+	return snapshots, nil
+}
+
+// snapshotBlocks lists the block numbers of the completed snapshots in dir, in increasing order
+func snapshotBlocks(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshots directory: %w", err)
+	}
+	var blocks []uint64
+	for _, entry := range entries {
+		if block, err := strconv.ParseUint(entry.Name(), 10, 64); err == nil && entry.IsDir() {
+			blocks = append(blocks, block)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+	return blocks, nil
+}
@@ -145,7 +145,7 @@ func (s *NodeService) getBesuFromConfig(ctx context.Context, dbNode *db.Node, co
 			RPCPort:         fmt.Sprintf("%d", deployConfig.RPCPort),
 			ListenAddress:   deployConfig.P2PHost,
 			MinerAddress:    key.EthereumAddress,
-			ConsensusType:   "qbft", // TODO: get consensus type from network
+			ConsensusType:   string(networkConfig.Consensus),
 			BootNodes:       config.BootNodes,
 			Version:         config.Version,
 			NodePrivateKey:  strings.TrimPrefix(privateKeyDecrypted, "0x"),
@@ -255,7 +255,7 @@ func (s *NodeService) startBesuNode(ctx context.Context, dbNode *db.Node) error
 			RPCPort:         fmt.Sprintf("%d", besuDeployConfig.RPCPort),
 			ListenAddress:   besuDeployConfig.P2PHost,
 			MinerAddress:    key.EthereumAddress,
-			ConsensusType:   "qbft", // TODO: get consensus type from network
+			ConsensusType:   string(networkConfig.Consensus),
 			BootNodes:       besuNodeConfig.BootNodes,
 			Version:         "25.4.1", // TODO: get version from network
 			NodePrivateKey:  strings.TrimPrefix(privateKeyDecrypted, "0x"),
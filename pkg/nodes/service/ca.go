@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/errors"
+	fabricservice "github.com/chainlaunch/chainlaunch/pkg/fabric/service"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/ca"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/utils"
+)
+
+// GetFabricCA returns the CA server of a Fabric CA node
+func (s *NodeService) GetFabricCA(ctx context.Context, id int64) (*ca.LocalCA, error) {
+	node, err := s.db.GetNode(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("node not found", map[string]interface{}{
+				"id": id,
+			})
+		}
+		return nil, fmt.Errorf("failed to get CA node: %w", err)
+	}
+
+	if types.NodeType(node.NodeType.String) != types.NodeTypeFabricCA {
+		return nil, errors.NewValidationError(fmt.Sprintf("node %d is not a Fabric CA", id), nil)
+	}
+
+	nodeConfig, err := utils.LoadNodeConfig([]byte(node.NodeConfig.String))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CA config: %w", err)
+	}
+	caConfig, ok := nodeConfig.(*types.FabricCAConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid CA config type")
+	}
+
+	org, err := s.orgService.GetOrganization(ctx, caConfig.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return s.getCAFromConfig(node, org, caConfig), nil
+}
+
+// EnrollCAIdentity enrolls an identity against a Fabric CA node
+func (s *NodeService) EnrollCAIdentity(ctx context.Context, nodeID int64, req ca.EnrollRequest) (*ca.EnrollResponse, error) {
+	localCA, err := s.GetFabricCA(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	return localCA.Enroll(ctx, req)
+}
+
+// RegisterCAIdentity registers an identity with a Fabric CA node
+func (s *NodeService) RegisterCAIdentity(ctx context.Context, nodeID int64, req ca.RegisterRequest) (*ca.RegisterResponse, error) {
+	localCA, err := s.GetFabricCA(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	return localCA.Register(ctx, req)
+}
+
+// RevokeCAIdentity revokes an identity or certificate issued by a Fabric CA node
+func (s *NodeService) RevokeCAIdentity(ctx context.Context, nodeID int64, req ca.RevokeRequest) (*ca.RevokeResponse, error) {
+	localCA, err := s.GetFabricCA(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	return localCA.Revoke(ctx, req)
+}
+
+// getCAFromConfig creates a LocalCA instance from configuration
+func (s *NodeService) getCAFromConfig(dbNode *db.Node, org *fabricservice.OrganizationDTO, config *types.FabricCAConfig) *ca.LocalCA {
+	return ca.NewLocalCA(
+		org.MspID,
+		s.db,
+		ca.StartCAOpts{
+			ID:                      dbNode.Name,
+			ListenAddress:           config.ListenAddress,
+			OperationsListenAddress: config.OperationsListenAddress,
+			ExternalEndpoint:        config.ExternalEndpoint,
+			DomainNames:             config.DomainNames,
+			Env:                     config.Env,
+			Version:                 config.Version,
+		},
+		config.Mode,
+		org,
+		config.OrganizationID,
+		s.orgService,
+		s.keymanagementService,
+		dbNode.ID,
+		s.logger,
+		s.configService,
+	)
+}
+
+// initializeFabricCA initializes a Fabric CA node
+func (s *NodeService) initializeFabricCA(ctx context.Context, dbNode *db.Node, req *types.FabricCAConfig) (*types.FabricCADeploymentConfig, error) {
+	org, err := s.orgService.GetOrganization(ctx, req.OrganizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	localCA := s.getCAFromConfig(dbNode, org, req)
+
+	config, err := localCA.Init()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize CA: %w", err)
+	}
+
+	caConfig, ok := config.(*types.FabricCADeploymentConfig)
+	if !ok {
+		return nil, fmt.Errorf("invalid CA config type")
+	}
+
+	return caConfig, nil
+}
+
+// startFabricCA starts a Fabric CA node
+func (s *NodeService) startFabricCA(ctx context.Context, dbNode *db.Node) error {
+	localCA, err := s.GetFabricCA(ctx, dbNode.ID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := localCA.Start(); err != nil {
+		return fmt.Errorf("failed to start CA: %w", err)
+	}
+
+	return nil
+}
+
+// stopFabricCA stops a Fabric CA node
+func (s *NodeService) stopFabricCA(ctx context.Context, dbNode *db.Node) error {
+	localCA, err := s.GetFabricCA(ctx, dbNode.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := localCA.Stop(); err != nil {
+		return fmt.Errorf("failed to stop CA: %w", err)
+	}
+
+	return nil
+}
+
+// cleanupCAResources cleans up resources specific to a Fabric CA node
+func (s *NodeService) cleanupCAResources(ctx context.Context, node *db.Node) error {
+	dir := filepath.Join(s.configService.GetDataPath(), "cas", node.Slug)
+	if err := os.RemoveAll(dir); err != nil {
+		s.logger.Warn("Failed to remove CA directory",
+			"path", dir,
+			"error", err)
+	} else {
+		s.logger.Info("Successfully removed CA directory",
+			"path", dir)
+	}
+
+	return nil
+}
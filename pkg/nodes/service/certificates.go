@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/certutils"
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/errors"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/utils"
+)
+
+// Certificate types of a node
+const (
+	NodeCertificateTypeSign = "SIGN"
+	NodeCertificateTypeTLS  = "TLS"
+)
+
+// CertificateSettings holds the certificate lifecycle settings of a node
+type CertificateSettings struct {
+	NodeID    int64      `json:"nodeId"`
+	AutoRenew bool       `json:"autoRenew"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// NodeCertificate describes a certificate deployed on a node
+type NodeCertificate struct {
+	NodeID     int64
+	NodeName   string
+	NodeType   types.NodeType
+	Type       string
+	KeyID      int64
+	CommonName string
+	NotAfter   time.Time
+}
+
+// supportsCertificateRenewal reports whether RenewCertificates handles the node type
+func supportsCertificateRenewal(nodeType types.NodeType) bool {
+	return nodeType == types.NodeTypeFabricPeer || nodeType == types.NodeTypeFabricOrderer
+}
+
+// GetCertificateSettings returns the certificate settings of a node
+func (s *NodeService) GetCertificateSettings(ctx context.Context, id int64) (*CertificateSettings, error) {
+	if _, err := s.db.GetNode(ctx, id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("node not found", nil)
+		}
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	settings, err := s.db.GetNodeCertificateSettings(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &CertificateSettings{NodeID: id}, nil
+		}
+		return nil, fmt.Errorf("failed to get certificate settings: %w", err)
+	}
+	return toCertificateSettings(settings), nil
+}
+
+// UpdateCertificateSettings opts a node in or out of automatic certificate renewal
+func (s *NodeService) UpdateCertificateSettings(ctx context.Context, id int64, autoRenew bool) (*CertificateSettings, error) {
+	node, err := s.db.GetNode(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.NewNotFoundError("node not found", nil)
+		}
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	if autoRenew && !supportsCertificateRenewal(types.NodeType(node.NodeType.String)) {
+		return nil, errors.NewValidationError(fmt.Sprintf("certificate renewal not supported for node type: %s", node.NodeType.String), nil)
+	}
+
+	settings, err := s.db.UpsertNodeCertificateSettings(ctx, &db.UpsertNodeCertificateSettingsParams{
+		NodeID:    id,
+		AutoRenew: autoRenew,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update certificate settings: %w", err)
+	}
+	return toCertificateSettings(settings), nil
+}
+
+// ListNodeCertificates returns the TLS and signing certificates deployed on all Fabric nodes
+func (s *NodeService) ListNodeCertificates(ctx context.Context) ([]NodeCertificate, error) {
+	nodes, err := s.db.GetAllNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var certs []NodeCertificate
+	for _, node := range nodes {
+		nodeType := types.NodeType(node.NodeType.String)
+		if !supportsCertificateRenewal(nodeType) || !node.DeploymentConfig.Valid {
+			continue
+		}
+
+		deploymentConfig, err := utils.DeserializeDeploymentConfig(node.DeploymentConfig.String)
+		if err != nil {
+			s.logger.Warn("Failed to deserialize deployment config", "nodeID", node.ID, "error", err)
+			continue
+		}
+
+		var signKeyID, tlsKeyID int64
+		var signCert, tlsCert string
+		switch config := deploymentConfig.(type) {
+		case *types.FabricPeerDeploymentConfig:
+			signKeyID, tlsKeyID = config.SignKeyID, config.TLSKeyID
+			signCert, tlsCert = config.SignCert, config.TLSCert
+		case *types.FabricOrdererDeploymentConfig:
+			signKeyID, tlsKeyID = config.SignKeyID, config.TLSKeyID
+			signCert, tlsCert = config.SignCert, config.TLSCert
+		default:
+			continue
+		}
+
+		for _, c := range []struct {
+			certType string
+			keyID    int64
+			pem      string
+		}{
+			{NodeCertificateTypeSign, signKeyID, signCert},
+			{NodeCertificateTypeTLS, tlsKeyID, tlsCert},
+		} {
+			if c.pem == "" {
+				continue
+			}
+			cert, err := certutils.ParseX509Certificate([]byte(c.pem))
+			if err != nil {
+				s.logger.Warn("Failed to parse node certificate", "nodeID", node.ID, "type", c.certType, "error", err)
+				continue
+			}
+			certs = append(certs, NodeCertificate{
+				NodeID:     node.ID,
+				NodeName:   node.Name,
+				NodeType:   nodeType,
+				Type:       c.certType,
+				KeyID:      c.keyID,
+				CommonName: cert.Subject.CommonName,
+				NotAfter:   cert.NotAfter,
+			})
+		}
+	}
+	return certs, nil
+}
+
+func toCertificateSettings(settings *db.NodeCertificateSetting) *CertificateSettings {
+	updatedAt := settings.UpdatedAt
+	return &CertificateSettings{
+		NodeID:    settings.NodeID,
+		AutoRenew: settings.AutoRenew,
+		UpdatedAt: &updatedAt,
+	}
+}
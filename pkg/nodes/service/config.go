@@ -47,6 +47,7 @@ type NodeResponse struct {
 	// Type-specific fields
 	FabricPeer    *FabricPeerProperties    `json:"fabricPeer,omitempty"`
 	FabricOrderer *FabricOrdererProperties `json:"fabricOrderer,omitempty"`
+	FabricCA      *FabricCAProperties      `json:"fabricCA,omitempty"`
 	BesuNode      *BesuNodeProperties      `json:"besuNode,omitempty"`
 }
 
@@ -72,6 +73,17 @@ type FabricPeerProperties struct {
 
 	AddressOverrides []types.AddressOverride `json:"addressOverrides,omitempty"`
 	Version          string                  `json:"version"`
+	// StateDatabase is goleveldb or CouchDB
+	StateDatabase string             `json:"stateDatabase"`
+	CouchDB       *CouchDBProperties `json:"couchDB,omitempty"`
+}
+
+// CouchDBProperties represents the CouchDB state database of a Fabric peer
+type CouchDBProperties struct {
+	Image    string `json:"image,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Username string `json:"username"`
 }
 
 // FabricOrdererProperties represents the properties specific to a Fabric orderer node
@@ -95,6 +107,25 @@ type FabricOrdererProperties struct {
 	Version    string `json:"version"`
 }
 
+// FabricCAProperties represents the properties specific to a Fabric CA node
+type FabricCAProperties struct {
+	MSPID             string   `json:"mspId"`
+	OrganizationID    int64    `json:"organizationId"`
+	ExternalEndpoint  string   `json:"externalEndpoint"`
+	ListenAddress     string   `json:"listenAddress"`
+	OperationsAddress string   `json:"operationsAddress"`
+	DomainNames       []string `json:"domainNames"`
+	Mode              string   `json:"mode"`
+	// Add deployment config fields
+	TLSKeyID  int64  `json:"tlsKeyId"`
+	AdminUser string `json:"adminUser,omitempty"`
+	// Add certificate information
+	TLSCert    string `json:"tlsCert,omitempty"`
+	SignCACert string `json:"signCaCert,omitempty"`
+	TLSCACert  string `json:"tlsCaCert,omitempty"`
+	Version    string `json:"version"`
+}
+
 // BesuNodeProperties represents the properties specific to a Besu node
 type BesuNodeProperties struct {
 	NetworkID  int64  `json:"networkId"`
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/peer"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/utils"
+)
+
+// defaultCouchDBUsername is the CouchDB admin user when none is given
+const defaultCouchDBUsername = "admin"
+
+// prepareCouchDB fills the defaults of the CouchDB configuration of a peer, validates
+// it and seals its password. Passwords which are already sealed are left unchanged.
+func (s *NodeService) prepareCouchDB(ctx context.Context, mode string, config *types.CouchDBConfig) error {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	if config.Username == "" {
+		config.Username = defaultCouchDBUsername
+	}
+	if mode == "docker" {
+		if config.Image == "" {
+			config.Image = types.DefaultCouchDBImage
+		}
+		if config.Port == 0 {
+			ports, err := findConsecutivePorts(5984, 1, 0)
+			if err != nil {
+				return fmt.Errorf("failed to find a port for CouchDB: %w", err)
+			}
+			config.Port = ports[0]
+		}
+	}
+	if err := config.Validate(mode); err != nil {
+		return err
+	}
+
+	if config.Password == "" {
+		password := make([]byte, 24)
+		if _, err := rand.Read(password); err != nil {
+			return fmt.Errorf("failed to generate CouchDB password: %w", err)
+		}
+		config.Password = hex.EncodeToString(password)
+	}
+	sealed, err := s.secrets.Seal(ctx, config.Password)
+	if err != nil {
+		return fmt.Errorf("failed to seal CouchDB password: %w", err)
+	}
+	config.Password = sealed
+	return nil
+}
+
+// toPeerCouchDBOpts returns the CouchDB options of a peer without its password, or nil
+// when the peer uses goleveldb
+func toPeerCouchDBOpts(config *types.CouchDBConfig) *peer.CouchDBOpts {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	return &peer.CouchDBOpts{
+		Image:    config.Image,
+		Port:     config.Port,
+		URL:      config.URL,
+		Username: config.Username,
+	}
+}
+
+// openPeerCouchDBOpts returns the CouchDB options of a peer with its password opened, or nil
+// when the peer uses goleveldb
+func (s *NodeService) openPeerCouchDBOpts(ctx context.Context, config *types.CouchDBConfig) (*peer.CouchDBOpts, error) {
+	opts := toPeerCouchDBOpts(config)
+	if opts == nil {
+		return nil, nil
+	}
+	password, err := s.secrets.Open(ctx, config.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open CouchDB password: %w", err)
+	}
+	opts.Password = password
+	return opts, nil
+}
+
+// toCouchDBProperties returns the CouchDB settings of a peer shown in node responses, without the password
+func toCouchDBProperties(config *types.CouchDBConfig) *CouchDBProperties {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+	return &CouchDBProperties{
+		Image:    config.Image,
+		Port:     config.Port,
+		URL:      config.URL,
+		Username: config.Username,
+	}
+}
+
+// resealCouchDBPasswords encrypts the CouchDB passwords of peers sealed with a
+// previous key encryption key, and returns how many were updated
+func (s *NodeService) resealCouchDBPasswords(ctx context.Context, q *db.Queries) (int, error) {
+	nodes, err := q.GetAllNodes(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	resealedCount := 0
+	for _, node := range nodes {
+		if types.NodeType(node.NodeType.String) != types.NodeTypeFabricPeer || !node.NodeConfig.Valid {
+			continue
+		}
+		nodeConfig, err := utils.LoadNodeConfig([]byte(node.NodeConfig.String))
+		if err != nil {
+			return 0, fmt.Errorf("failed to load config of node %d: %w", node.ID, err)
+		}
+		peerConfig, ok := nodeConfig.(*types.FabricPeerConfig)
+		if !ok || peerConfig.CouchDB == nil || !peerConfig.CouchDB.Enabled {
+			continue
+		}
+		sealed, resealed, err := s.secrets.Reseal(ctx, peerConfig.CouchDB.Password)
+		if err != nil {
+			return 0, fmt.Errorf("failed to seal CouchDB password of node %d: %w", node.ID, err)
+		}
+		if !resealed {
+			continue
+		}
+		peerConfig.CouchDB.Password = sealed
+		configBytes, err := utils.StoreNodeConfig(peerConfig)
+		if err != nil {
+			return 0, fmt.Errorf("failed to store config of node %d: %w", node.ID, err)
+		}
+		if _, err := q.UpdateNodeConfig(ctx, &db.UpdateNodeConfigParams{
+			ID:         node.ID,
+			NodeConfig: sql.NullString{String: string(configBytes), Valid: true},
+		}); err != nil {
+			return 0, fmt.Errorf("failed to update config of node %d: %w", node.ID, err)
+		}
+		resealedCount++
+	}
+	return resealedCount, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	fabricservice "github.com/chainlaunch/chainlaunch/pkg/fabric/service"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/database"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/secrets"
+	"github.com/chainlaunch/chainlaunch/pkg/nodes/types"
+)
+
+func newTestSecretStore(t *testing.T, active []byte, previous ...[]byte) *secrets.Store {
+	ring, err := database.NewKeyRing(active, previous...)
+	if err != nil {
+		t.Fatalf("failed to create key ring: %v", err)
+	}
+	return secrets.NewStore(ring)
+}
+
+func TestPrepareCouchDB(t *testing.T) {
+	ctx := context.Background()
+	s := &NodeService{secrets: newTestSecretStore(t, bytes.Repeat([]byte{1}, 32))}
+
+	// A password is generated and sealed when none is given
+	generated := &types.CouchDBConfig{Enabled: true, Port: 15984}
+	if err := s.prepareCouchDB(ctx, "docker", generated); err != nil {
+		t.Fatalf("failed to prepare CouchDB: %v", err)
+	}
+	if generated.Username != defaultCouchDBUsername || generated.Image != types.DefaultCouchDBImage {
+		t.Errorf("expected the defaults to be filled, got %+v", generated)
+	}
+	if !secrets.IsSealed(generated.Password) {
+		t.Fatalf("expected a sealed password, got %q", generated.Password)
+	}
+	password, err := s.secrets.Open(ctx, generated.Password)
+	if err != nil {
+		t.Fatalf("failed to open password: %v", err)
+	}
+	if b, err := hex.DecodeString(password); err != nil || len(b) != 24 {
+		t.Errorf("expected 24 random bytes hex encoded, got %q", password)
+	}
+
+	other := &types.CouchDBConfig{Enabled: true, Port: 15985}
+	if err := s.prepareCouchDB(ctx, "docker", other); err != nil {
+		t.Fatalf("failed to prepare CouchDB: %v", err)
+	}
+	if otherPassword, _ := s.secrets.Open(ctx, other.Password); otherPassword == password {
+		t.Error("expected each peer to get its own password")
+	}
+
+	// A given password is sealed, and kept when it already is
+	given := &types.CouchDBConfig{Enabled: true, URL: "http://couchdb.example.com:5984", Password: "given-password"}
+	if err := s.prepareCouchDB(ctx, "service", given); err != nil {
+		t.Fatalf("failed to prepare CouchDB: %v", err)
+	}
+	if opened, err := s.secrets.Open(ctx, given.Password); err != nil || opened != "given-password" {
+		t.Errorf("expected the given password to be sealed, got %q (%v)", opened, err)
+	}
+	sealed := given.Password
+	if err := s.prepareCouchDB(ctx, "service", given); err != nil {
+		t.Fatalf("failed to prepare CouchDB: %v", err)
+	}
+	if given.Password != sealed {
+		t.Error("expected a sealed password to be kept")
+	}
+
+	if err := s.prepareCouchDB(ctx, "service", &types.CouchDBConfig{Enabled: true}); err == nil {
+		t.Error("expected an error without a CouchDB URL in service mode")
+	}
+	disabled := &types.CouchDBConfig{}
+	if err := s.prepareCouchDB(ctx, "docker", disabled); err != nil || disabled.Password != "" {
+		t.Errorf("expected a disabled CouchDB to be left unchanged, got %+v (%v)", disabled, err)
+	}
+}
+
+func TestPeerCouchDBPasswordOpening(t *testing.T) {
+	ctx := context.Background()
+	s := &NodeService{secrets: newTestSecretStore(t, bytes.Repeat([]byte{1}, 32))}
+	sealed, err := s.secrets.Seal(ctx, "couchdb-password")
+	if err != nil {
+		t.Fatalf("failed to seal password: %v", err)
+	}
+	config := &types.FabricPeerConfig{
+		BaseNodeConfig: types.BaseNodeConfig{Mode: "docker"},
+		CouchDB:        &types.CouchDBConfig{Enabled: true, Port: 15984, Username: "admin", Password: sealed},
+	}
+
+	opts, err := s.openPeerCouchDBOpts(ctx, config.CouchDB)
+	if err != nil {
+		t.Fatalf("failed to open CouchDB options: %v", err)
+	}
+	if opts.Password != "couchdb-password" || opts.Username != "admin" || opts.Port != 15984 {
+		t.Errorf("unexpected CouchDB options %+v", opts)
+	}
+	if opts := toPeerCouchDBOpts(config.CouchDB); opts.Password != "" {
+		t.Error("expected the password to be left out")
+	}
+	if toPeerCouchDBOpts(&types.CouchDBConfig{}) != nil {
+		t.Error("expected no options when CouchDB is disabled")
+	}
+
+	// Peers are still loaded to stop them or read their logs when the password can't be opened
+	s.secrets = newTestSecretStore(t, bytes.Repeat([]byte{2}, 32))
+	dbNode := &db.Node{ID: 1, Slug: "peer0-org1"}
+	org := &fabricservice.OrganizationDTO{ID: 1, MspID: "Org1MSP"}
+	if p := s.getPeerFromConfig(dbNode, org, config); !p.UsesCouchDB() {
+		t.Error("expected the peer to use CouchDB")
+	}
+	if _, err := s.getPeerWithSecrets(ctx, dbNode, org, config); err == nil {
+		t.Error("expected an error when the password can't be opened")
+	}
+}
@@ -192,6 +192,16 @@ func (s *NodeService) GetFabricNodesDefaults(params NodesDefaultsParams) (*Nodes
 
 // GetFabricPeer gets a Fabric peer node configuration
 func (s *NodeService) GetFabricPeer(ctx context.Context, id int64) (*peer.LocalPeer, error) {
+	return s.getFabricPeer(ctx, id, false)
+}
+
+// GetFabricPeerWithSecrets gets a Fabric peer like GetFabricPeer, with the
+// password of its CouchDB opened
+func (s *NodeService) GetFabricPeerWithSecrets(ctx context.Context, id int64) (*peer.LocalPeer, error) {
+	return s.getFabricPeer(ctx, id, true)
+}
+
+func (s *NodeService) getFabricPeer(ctx context.Context, id int64, withSecrets bool) (*peer.LocalPeer, error) {
 	// Get the node from database
 	node, err := s.db.GetNode(ctx, id)
 	if err != nil {
@@ -239,8 +249,10 @@ func (s *NodeService) GetFabricPeer(ctx context.Context, id int64) (*peer.LocalP
 	}
 
 	// Create and return local peer
-	localPeer := s.getPeerFromConfig(node, org, peerConfig)
-	return localPeer, nil
+	if withSecrets {
+		return s.getPeerWithSecrets(ctx, node, org, peerConfig)
+	}
+	return s.getPeerFromConfig(node, org, peerConfig), nil
 }
 
 // GetFabricOrderer gets a Fabric orderer node configuration
@@ -352,7 +364,10 @@ func (s *NodeService) startFabricPeer(ctx context.Context, dbNode *db.Node) erro
 		return fmt.Errorf("failed to get organization: %w", err)
 	}
 
-	localPeer := s.getPeerFromConfig(dbNode, org, peerNodeConfig)
+	localPeer, err := s.getPeerWithSecrets(ctx, dbNode, org, peerNodeConfig)
+	if err != nil {
+		return err
+	}
 
 	_, err = localPeer.Start()
 	if err != nil {
@@ -524,6 +539,21 @@ func (s *NodeService) UpdateFabricPeer(ctx context.Context, opts UpdateFabricPee
 		peerConfig.Version = opts.Version
 		deployPeerConfig.Version = opts.Version
 	}
+	if opts.CouchDB != nil {
+		// Keep the current password and port when none is given
+		if current := peerConfig.CouchDB; current != nil {
+			if opts.CouchDB.Password == "" {
+				opts.CouchDB.Password = current.Password
+			}
+			if opts.CouchDB.Port == 0 {
+				opts.CouchDB.Port = current.Port
+			}
+		}
+		if err := s.prepareCouchDB(ctx, peerConfig.Mode, opts.CouchDB); err != nil {
+			return nil, fmt.Errorf("invalid CouchDB configuration: %w", err)
+		}
+		peerConfig.CouchDB = opts.CouchDB
+	}
 
 	// Validate all addresses together for port conflicts
 	if err := s.validateFabricPeerAddresses(peerConfig); err != nil {
@@ -707,8 +737,11 @@ func (s *NodeService) SynchronizePeerConfig(ctx context.Context, nodeID int64) e
 		return fmt.Errorf("failed to get organization: %w", err)
 	}
 
-	// Get local peer instance
-	localPeer := s.getPeerFromConfig(node, org, peerConfig)
+	// Get local peer instance, core.yaml holds the CouchDB password
+	localPeer, err := s.getPeerWithSecrets(ctx, node, org, peerConfig)
+	if err != nil {
+		return err
+	}
 
 	// Get deployment config
 	deploymentConfig, err := utils.DeserializeDeploymentConfig(node.DeploymentConfig.String)
@@ -816,7 +849,10 @@ func (s *NodeService) initializeFabricPeer(ctx context.Context, dbNode *db.Node,
 		return nil, fmt.Errorf("failed to get organization: %w", err)
 	}
 
-	localPeer := s.getPeerFromConfig(dbNode, org, req)
+	localPeer, err := s.getPeerWithSecrets(ctx, dbNode, org, req)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get deployment config from initialization
 	peerConfig, err := localPeer.Init()
@@ -879,8 +915,23 @@ func (s *NodeService) initializeFabricOrderer(ctx context.Context, dbNode *db.No
 	return ordererConfig, nil
 }
 
-// getPeerFromConfig creates a peer instance from the given configuration and database node
+// getPeerFromConfig creates a peer instance from the given configuration and database node.
+// Its CouchDB password isn't opened, see getPeerWithSecrets.
 func (s *NodeService) getPeerFromConfig(dbNode *db.Node, org *fabricservice.OrganizationDTO, config *types.FabricPeerConfig) *peer.LocalPeer {
+	return s.newLocalPeer(dbNode, org, config, toPeerCouchDBOpts(config.CouchDB))
+}
+
+// getPeerWithSecrets creates a peer instance like getPeerFromConfig, with the
+// password of its CouchDB opened to start the peer or write its configuration
+func (s *NodeService) getPeerWithSecrets(ctx context.Context, dbNode *db.Node, org *fabricservice.OrganizationDTO, config *types.FabricPeerConfig) (*peer.LocalPeer, error) {
+	couchDB, err := s.openPeerCouchDBOpts(ctx, config.CouchDB)
+	if err != nil {
+		return nil, err
+	}
+	return s.newLocalPeer(dbNode, org, config, couchDB), nil
+}
+
+func (s *NodeService) newLocalPeer(dbNode *db.Node, org *fabricservice.OrganizationDTO, config *types.FabricPeerConfig, couchDB *peer.CouchDBOpts) *peer.LocalPeer {
 	return peer.NewLocalPeer(
 		org.MspID,
 		s.db,
@@ -895,6 +946,7 @@ func (s *NodeService) getPeerFromConfig(dbNode *db.Node, org *fabricservice.Orga
 			Env:                     config.Env,
 			Version:                 config.Version,
 			AddressOverrides:        config.AddressOverrides,
+			CouchDB:                 couchDB,
 		},
 		config.Mode,
 		org,
@@ -940,7 +992,11 @@ func (s *NodeService) renewPeerCertificates(ctx context.Context, dbNode *db.Node
 		return fmt.Errorf("failed to get organization: %w", err)
 	}
 
-	localPeer := s.getPeerFromConfig(dbNode, org, peerConfig)
+	// The peer is restarted with its new certificates, which starts its CouchDB
+	localPeer, err := s.getPeerWithSecrets(ctx, dbNode, org, peerConfig)
+	if err != nil {
+		return err
+	}
 	err = localPeer.RenewCertificates(peerDeployConfig)
 	if err != nil {
 		return fmt.Errorf("failed to renew peer certificates: %w", err)
@@ -1012,6 +1068,13 @@ func (s *NodeService) renewOrdererCertificates(ctx context.Context, dbNode *db.N
 
 // cleanupPeerResources cleans up resources specific to a Fabric peer node
 func (s *NodeService) cleanupPeerResources(ctx context.Context, node *db.Node) error {
+	// Remove the CouchDB container, which is left running when the peer failed
+	if localPeer, err := s.GetFabricPeer(ctx, node.ID); err != nil {
+		s.logger.Warn("Failed to get peer to remove its CouchDB container", "error", err)
+	} else if err := localPeer.RemoveCouchDB(); err != nil {
+		s.logger.Warn("Failed to remove CouchDB container", "error", err)
+	}
+
 	// Clean up peer-specific directories
 	dirsToClean := []string{
 		filepath.Join(s.configService.GetDataPath(), "nodes", node.Slug),
@@ -18,6 +18,7 @@ import (
 	"github.com/chainlaunch/chainlaunch/pkg/db"
 	"github.com/chainlaunch/chainlaunch/pkg/errors"
 	fabricservice "github.com/chainlaunch/chainlaunch/pkg/fabric/service"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/secrets"
 	keymanagement "github.com/chainlaunch/chainlaunch/pkg/keymanagement/service"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
 	metricscommon "github.com/chainlaunch/chainlaunch/pkg/metrics/common"
@@ -37,6 +38,7 @@ type NodeService struct {
 	configService        *config.ConfigService
 	settingsService      *settingsservice.SettingsService
 	metricsService       metricscommon.Service
+	secrets              *secrets.Store
 }
 
 // CreateNodeRequest represents the service-layer request to create a node
@@ -46,6 +48,7 @@ type CreateNodeRequest struct {
 	BlockchainPlatform types.BlockchainPlatform
 	FabricPeer         *types.FabricPeerConfig
 	FabricOrderer      *types.FabricOrdererConfig
+	FabricCA           *types.FabricCAConfig
 	BesuNode           *types.BesuNodeConfig
 }
 
@@ -58,8 +61,9 @@ func NewNodeService(
 	eventService *NodeEventService,
 	configService *config.ConfigService,
 	settingsService *settingsservice.SettingsService,
+	secretStore *secrets.Store,
 ) *NodeService {
-	return &NodeService{
+	service := &NodeService{
 		db:                   db,
 		logger:               logger,
 		keymanagementService: keymanagementService,
@@ -67,7 +71,13 @@ func NewNodeService(
 		eventService:         eventService,
 		configService:        configService,
 		settingsService:      settingsService,
+		secrets:              secretStore,
 	}
+	// The CouchDB passwords of peers follow the rotation of the key encryption key
+	if secretStore != nil {
+		secretStore.RegisterResealer("CouchDB passwords", service.resealCouchDBPasswords)
+	}
+	return service
 }
 
 func (s *NodeService) SetMetricsService(metricsService metricscommon.Service) {
@@ -81,11 +91,17 @@ func (s *NodeService) validateCreateNodeRequest(req CreateNodeRequest) error {
 
 	switch req.BlockchainPlatform {
 	case types.PlatformFabric:
-		if req.FabricPeer == nil && req.FabricOrderer == nil {
+		configs := 0
+		for _, set := range []bool{req.FabricPeer != nil, req.FabricOrderer != nil, req.FabricCA != nil} {
+			if set {
+				configs++
+			}
+		}
+		if configs == 0 {
 			return fmt.Errorf("fabric configuration is required")
 		}
-		if req.FabricPeer != nil && req.FabricOrderer != nil {
-			return fmt.Errorf("cannot specify both peer and orderer configurations")
+		if configs > 1 {
+			return fmt.Errorf("only one of peer, orderer or CA configuration can be specified")
 		}
 	case types.PlatformBesu:
 		if req.BesuNode == nil {
@@ -104,6 +120,9 @@ func (s *NodeService) determineNodeType(req CreateNodeRequest) types.NodeType {
 		if req.FabricPeer != nil {
 			return types.NodeTypeFabricPeer
 		}
+		if req.FabricCA != nil {
+			return types.NodeTypeFabricCA
+		}
 		return types.NodeTypeFabricOrderer
 	case types.PlatformBesu:
 		return types.NodeTypeBesuFullnode
@@ -226,6 +245,11 @@ func (s *NodeService) CreateNode(ctx context.Context, req CreateNodeRequest) (*N
 	if err != nil {
 		return nil, fmt.Errorf("failed to create node config: %w", err)
 	}
+	if peerConfig, ok := nodeConfig.(*types.FabricPeerConfig); ok {
+		if err := s.prepareCouchDB(ctx, peerConfig.Mode, peerConfig.CouchDB); err != nil {
+			return nil, fmt.Errorf("invalid CouchDB configuration: %w", err)
+		}
+	}
 
 	// Store node config
 	configBytes, err := utils.StoreNodeConfig(nodeConfig)
@@ -248,6 +272,11 @@ func (s *NodeService) CreateNode(ctx context.Context, req CreateNodeRequest) (*N
 			String: nodeConfig.ExternalEndpoint, // Use ExternalEndpoint instead of ListenAddress
 			Valid:  true,
 		}
+	case *types.FabricCAConfig:
+		endpoint = sql.NullString{
+			String: nodeConfig.ExternalEndpoint,
+			Valid:  true,
+		}
 	case *types.BesuNodeConfig:
 		endpoint = sql.NullString{
 			String: fmt.Sprintf("%s:%d", nodeConfig.ExternalIP, nodeConfig.P2PPort), // Use ExternalIP instead of P2PHost
@@ -330,6 +359,7 @@ func (s *NodeService) createNodeConfig(req CreateNodeRequest) (types.NodeConfig,
 				DomainNames:             req.FabricPeer.DomainNames,
 				Env:                     req.FabricPeer.Env,
 				Version:                 req.FabricPeer.Version,
+				CouchDB:                 req.FabricPeer.CouchDB,
 			}, nil
 		} else if req.FabricOrderer != nil {
 			return &types.FabricOrdererConfig{
@@ -348,6 +378,22 @@ func (s *NodeService) createNodeConfig(req CreateNodeRequest) (types.NodeConfig,
 				Env:                     req.FabricOrderer.Env,
 				Version:                 req.FabricOrderer.Version,
 			}, nil
+		} else if req.FabricCA != nil {
+			return &types.FabricCAConfig{
+				BaseNodeConfig: types.BaseNodeConfig{
+					Type: "fabric-ca",
+					Mode: req.FabricCA.Mode,
+				},
+				Name:                    req.FabricCA.Name,
+				OrganizationID:          req.FabricCA.OrganizationID,
+				MSPID:                   req.FabricCA.MSPID,
+				ListenAddress:           req.FabricCA.ListenAddress,
+				OperationsListenAddress: req.FabricCA.OperationsListenAddress,
+				ExternalEndpoint:        req.FabricCA.ExternalEndpoint,
+				DomainNames:             req.FabricCA.DomainNames,
+				Env:                     req.FabricCA.Env,
+				Version:                 req.FabricCA.Version,
+			}, nil
 		}
 	case types.PlatformBesu:
 		if req.BesuNode != nil {
@@ -388,6 +434,12 @@ func (s *NodeService) initializeNode(ctx context.Context, dbNode *db.Node, req C
 				return nil, fmt.Errorf("failed to initialize fabric orderer: %w", err)
 			}
 			return config, nil
+		} else if req.FabricCA != nil {
+			config, err := s.initializeFabricCA(ctx, dbNode, req.FabricCA)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize fabric CA: %w", err)
+			}
+			return config, nil
 		}
 	case types.PlatformBesu:
 		if req.BesuNode != nil {
@@ -574,6 +626,11 @@ func (s *NodeService) mapDBNodeToServiceNode(dbNode *db.Node) (*Node, *NodeRespo
 				ListenAddress:     config.ListenAddress,
 				DomainNames:       config.DomainNames,
 				Version:           config.Version,
+				StateDatabase:     "goleveldb",
+				CouchDB:           toCouchDBProperties(config.CouchDB),
+			}
+			if nodeResponse.FabricPeer.CouchDB != nil {
+				nodeResponse.FabricPeer.StateDatabase = "CouchDB"
 			}
 			// Enrich with deployment config if available
 			if peerDeployConfig, ok := deploymentConfig.(*types.FabricPeerDeploymentConfig); ok {
@@ -679,6 +736,25 @@ func (s *NodeService) mapDBNodeToServiceNode(dbNode *db.Node) (*Node, *NodeRespo
 					}
 				}
 			}
+		case *types.FabricCAConfig:
+			node.MSPID = config.MSPID
+			nodeResponse.FabricCA = &FabricCAProperties{
+				MSPID:             config.MSPID,
+				OrganizationID:    config.OrganizationID,
+				ExternalEndpoint:  config.ExternalEndpoint,
+				ListenAddress:     config.ListenAddress,
+				OperationsAddress: config.OperationsListenAddress,
+				DomainNames:       config.DomainNames,
+				Mode:              config.Mode,
+				Version:           config.Version,
+			}
+			if caDeployConfig, ok := deploymentConfig.(*types.FabricCADeploymentConfig); ok {
+				nodeResponse.FabricCA.TLSKeyID = caDeployConfig.TLSKeyID
+				nodeResponse.FabricCA.AdminUser = caDeployConfig.AdminUser
+				nodeResponse.FabricCA.TLSCert = caDeployConfig.TLSCert
+				nodeResponse.FabricCA.SignCACert = caDeployConfig.CACert
+				nodeResponse.FabricCA.TLSCACert = caDeployConfig.TLSCACert
+			}
 		case *types.BesuNodeConfig:
 			nodeResponse.BesuNode = &BesuNodeProperties{
 				NetworkID:  config.NetworkID,
@@ -752,6 +828,8 @@ func (s *NodeService) StopNode(ctx context.Context, id int64) (*NodeResponse, er
 		stopErr = s.stopFabricPeer(ctx, node)
 	case types.NodeTypeFabricOrderer:
 		stopErr = s.stopFabricOrderer(ctx, node)
+	case types.NodeTypeFabricCA:
+		stopErr = s.stopFabricCA(ctx, node)
 	case types.NodeTypeBesuFullnode:
 		stopErr = s.stopBesuNode(ctx, node)
 	default:
@@ -813,6 +891,8 @@ func (s *NodeService) startNode(ctx context.Context, dbNode *db.Node) error {
 		startErr = s.startFabricPeer(ctx, dbNode)
 	case types.NodeTypeFabricOrderer:
 		startErr = s.startFabricOrderer(ctx, dbNode)
+	case types.NodeTypeFabricCA:
+		startErr = s.startFabricCA(ctx, dbNode)
 	case types.NodeTypeBesuFullnode:
 		startErr = s.startBesuNode(ctx, dbNode)
 	default:
@@ -938,6 +1018,10 @@ func (s *NodeService) cleanupNodeResources(ctx context.Context, node *db.Node) e
 		if err := s.cleanupOrdererResources(ctx, node); err != nil {
 			s.logger.Warn("Failed to cleanup orderer resources", "error", err)
 		}
+	case types.NodeTypeFabricCA:
+		if err := s.cleanupCAResources(ctx, node); err != nil {
+			s.logger.Warn("Failed to cleanup CA resources", "error", err)
+		}
 	case types.NodeTypeBesuFullnode:
 		if err := s.cleanupBesuResources(ctx, node); err != nil {
 			s.logger.Warn("Failed to cleanup besu resources", "error", err)
@@ -1003,6 +1087,12 @@ func (s *NodeService) GetNodeLogPath(ctx context.Context, node *NodeResponse) (s
 		localOrderer := s.getOrdererFromConfig(dbNode, org, ordererNodeConfig)
 		// Tail logs from orderer
 		return localOrderer.GetStdOutPath(), nil
+	case types.NodeTypeFabricCA:
+		localCA, err := s.GetFabricCA(ctx, dbNode.ID)
+		if err != nil {
+			return "", err
+		}
+		return localCA.GetStdOutPath(), nil
 	case types.NodeTypeBesuFullnode:
 		nodeConfig, err := utils.LoadNodeConfig([]byte(dbNode.NodeConfig.String))
 		if err != nil {
@@ -16,6 +16,7 @@ type UpdateFabricPeerOpts struct {
 	Env                     map[string]string
 	AddressOverrides        []types.AddressOverride
 	Version                 string
+	CouchDB                 *types.CouchDBConfig
 }
 
 // UpdateFabricOrdererOpts represents the options for updating a Fabric orderer node
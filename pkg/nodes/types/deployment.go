@@ -3,13 +3,15 @@ package types
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 )
 
 // NodeDeploymentConfig represents the deployment configuration for different types of nodes
-// @Description Node deployment configuration interface that can be one of: FabricPeerDeploymentConfig, FabricOrdererDeploymentConfig, or BesuNodeDeploymentConfig
+// @Description Node deployment configuration interface that can be one of: FabricPeerDeploymentConfig, FabricOrdererDeploymentConfig, FabricCADeploymentConfig, or BesuNodeDeploymentConfig
 // @discriminator type
 // @discriminatorMapping fabric-peer FabricPeerDeploymentConfig
 // @discriminatorMapping fabric-orderer FabricOrdererDeploymentConfig
+// @discriminatorMapping fabric-ca FabricCADeploymentConfig
 // @discriminatorMapping besu BesuNodeDeploymentConfig
 // @model NodeDeploymentConfig
 type NodeDeploymentConfig interface {
@@ -22,13 +24,14 @@ type NodeDeploymentConfig interface {
 	GetOrganizationID() int64
 	ToFabricPeerConfig() *FabricPeerDeploymentConfig
 	ToFabricOrdererConfig() *FabricOrdererDeploymentConfig
+	ToFabricCAConfig() *FabricCADeploymentConfig
 	ToBesuNodeConfig() *BesuNodeDeploymentConfig
 }
 
 // BaseDeploymentConfig contains common deployment fields
 // @Description Base configuration fields shared by all node deployment types
 type BaseDeploymentConfig struct {
-	// @Description The type of the node deployment (fabric-peer, fabric-orderer, besu)
+	// @Description The type of the node deployment (fabric-peer, fabric-orderer, fabric-ca, besu)
 	Type string `json:"type" example:"fabric-peer"`
 	// @Description The deployment mode (service or docker)
 	Mode string `json:"mode" example:"service"`
@@ -119,6 +122,9 @@ func (c *FabricPeerDeploymentConfig) ToFabricPeerConfig() *FabricPeerDeploymentC
 func (c *FabricPeerDeploymentConfig) ToFabricOrdererConfig() *FabricOrdererDeploymentConfig {
 	return nil
 }
+func (c *FabricPeerDeploymentConfig) ToFabricCAConfig() *FabricCADeploymentConfig {
+	return nil
+}
 func (c *FabricPeerDeploymentConfig) ToBesuNodeConfig() *BesuNodeDeploymentConfig {
 	return nil
 }
@@ -180,6 +186,7 @@ func (c *FabricOrdererDeploymentConfig) Validate() error {
 func (c *FabricOrdererDeploymentConfig) GetServiceName() string                          { return c.ServiceName }
 func (c *FabricOrdererDeploymentConfig) GetOrganizationID() int64                        { return c.OrganizationID }
 func (c *FabricOrdererDeploymentConfig) ToFabricPeerConfig() *FabricPeerDeploymentConfig { return nil }
+func (c *FabricOrdererDeploymentConfig) ToFabricCAConfig() *FabricCADeploymentConfig     { return nil }
 func (c *FabricOrdererDeploymentConfig) ToBesuNodeConfig() *BesuNodeDeploymentConfig {
 	return nil
 }
@@ -203,6 +210,81 @@ func (c *FabricOrdererDeploymentConfig) ToFabricOrdererConfig() *FabricOrdererDe
 	}
 }
 
+// FabricCADeploymentConfig represents the computed deployment configuration for a Fabric CA
+// @Description Deployment configuration specific to Fabric CA nodes
+// @model FabricCADeploymentConfig
+type FabricCADeploymentConfig struct {
+	BaseDeploymentConfig
+	// @Description Organization ID that owns this CA
+	OrganizationID int64 `json:"organizationId" validate:"required" example:"1"`
+	// @Description MSP ID for the organization
+	MSPID string `json:"mspId" validate:"required" example:"Org1MSP"`
+	// Identity and security
+	// @Description ID of the organization sign CA key the CA issues enrollment certificates with
+	SignCAKeyID int64 `json:"signCaKeyId" example:"1"`
+	// @Description ID of the organization TLS CA key the CA issues TLS certificates with
+	TLSCAKeyID int64 `json:"tlsCaKeyId" example:"2"`
+	// @Description ID of the key used for the CA server TLS certificate
+	TLSKeyID int64 `json:"tlsKeyId" example:"3"`
+	// @Description PEM encoded TLS certificate of the CA server
+	TLSCert string `json:"tlsCert"`
+	// @Description PEM encoded CA certificate
+	CACert string `json:"caCert"`
+	// @Description PEM encoded TLS CA certificate
+	TLSCACert string `json:"tlsCaCert"`
+	// @Description Name of the bootstrap admin identity
+	AdminUser string `json:"adminUser" example:"admin"`
+
+	// Network configuration
+	// @Description Listen address for the CA server
+	ListenAddress string `json:"listenAddress" example:"0.0.0.0:7054"`
+	// @Description Operations listen address
+	OperationsListenAddress string `json:"operationsListenAddress" example:"0.0.0.0:9443"`
+	// @Description External endpoint for the CA server
+	ExternalEndpoint string `json:"externalEndpoint" example:"ca.org1.example.com:7054"`
+	// @Description Domain names for the CA server
+	DomainNames []string `json:"domainNames,omitempty"`
+	// @Description Fabric CA version to use
+	Version string `json:"version" example:"1.5.15"`
+}
+
+func (c *FabricCADeploymentConfig) GetURL() string {
+	return fmt.Sprintf("https://%s", c.ExternalEndpoint)
+}
+
+func (c *FabricCADeploymentConfig) GetMode() string { return c.Mode }
+func (c *FabricCADeploymentConfig) Validate() error {
+	if c.Mode != "service" && c.Mode != "docker" {
+		return fmt.Errorf("invalid mode: %s", c.Mode)
+	}
+	return nil
+}
+
+func (c *FabricCADeploymentConfig) GetServiceName() string                                { return c.ServiceName }
+func (c *FabricCADeploymentConfig) GetOrganizationID() int64                              { return c.OrganizationID }
+func (c *FabricCADeploymentConfig) ToFabricPeerConfig() *FabricPeerDeploymentConfig       { return nil }
+func (c *FabricCADeploymentConfig) ToFabricOrdererConfig() *FabricOrdererDeploymentConfig { return nil }
+func (c *FabricCADeploymentConfig) ToBesuNodeConfig() *BesuNodeDeploymentConfig           { return nil }
+func (c *FabricCADeploymentConfig) ToFabricCAConfig() *FabricCADeploymentConfig {
+	return &FabricCADeploymentConfig{
+		BaseDeploymentConfig:    BaseDeploymentConfig{Type: "fabric-ca", Mode: c.Mode},
+		OrganizationID:          c.OrganizationID,
+		MSPID:                   c.MSPID,
+		SignCAKeyID:             c.SignCAKeyID,
+		TLSCAKeyID:              c.TLSCAKeyID,
+		TLSKeyID:                c.TLSKeyID,
+		TLSCert:                 c.TLSCert,
+		CACert:                  c.CACert,
+		TLSCACert:               c.TLSCACert,
+		AdminUser:               c.AdminUser,
+		ListenAddress:           c.ListenAddress,
+		OperationsListenAddress: c.OperationsListenAddress,
+		ExternalEndpoint:        c.ExternalEndpoint,
+		DomainNames:             c.DomainNames,
+		Version:                 c.Version,
+	}
+}
+
 // BesuNodeDeploymentConfig represents the computed deployment configuration for a Besu node
 // @Description Deployment configuration specific to Besu nodes
 // @model BesuNodeDeploymentConfig
@@ -246,6 +328,7 @@ func (c *BesuNodeDeploymentConfig) GetServiceName() string
 func (c *BesuNodeDeploymentConfig) GetOrganizationID() int64                              { return 0 }
 func (c *BesuNodeDeploymentConfig) ToFabricPeerConfig() *FabricPeerDeploymentConfig       { return nil }
 func (c *BesuNodeDeploymentConfig) ToFabricOrdererConfig() *FabricOrdererDeploymentConfig { return nil }
+func (c *BesuNodeDeploymentConfig) ToFabricCAConfig() *FabricCADeploymentConfig           { return nil }
 func (c *BesuNodeDeploymentConfig) ToBesuNodeConfig() *BesuNodeDeploymentConfig {
 	return &BesuNodeDeploymentConfig{
 		BaseDeploymentConfig: BaseDeploymentConfig{Type: "besu", Mode: c.Mode},
@@ -270,7 +353,7 @@ type NodeConfig interface {
 // BaseNodeConfig contains common fields for all node configurations
 // @Description Base configuration shared by all node types
 type BaseNodeConfig struct {
-	// @Description The type of node (fabric-peer, fabric-orderer, besu)
+	// @Description The type of node (fabric-peer, fabric-orderer, fabric-ca, besu)
 	Type string `json:"type" example:"fabric-peer"`
 	// @Description The deployment mode (service or docker)
 	Mode string `json:"mode" example:"service"`
@@ -308,6 +391,55 @@ type FabricPeerConfig struct {
 	OrdererAddressOverrides []OrdererAddressOverride `json:"ordererAddressOverrides,omitempty"`
 	// @Description Address overrides for the peer
 	AddressOverrides []AddressOverride `json:"addressOverrides,omitempty"`
+	// @Description CouchDB state database of the peer, goleveldb is used when not enabled
+	CouchDB *CouchDBConfig `json:"couchDB,omitempty"`
+}
+
+// DefaultCouchDBImage is the CouchDB image run next to docker peers
+const DefaultCouchDBImage = "couchdb:3.3.3"
+
+// CouchDBConfig represents the CouchDB state database of a Fabric peer. In docker
+// mode a CouchDB container is run next to the peer, in service mode the peer
+// connects to an external CouchDB.
+type CouchDBConfig struct {
+	// @Description Store the world state in CouchDB instead of goleveldb
+	Enabled bool `json:"enabled"`
+	// @Description CouchDB image to run in docker mode
+	Image string `json:"image,omitempty" example:"couchdb:3.3.3"`
+	// @Description Host port the CouchDB container is published on in docker mode, bound to localhost
+	Port int `json:"port,omitempty" example:"5984"`
+	// @Description URL of the external CouchDB in service mode
+	URL string `json:"url,omitempty" example:"http://couchdb.example.com:5984"`
+	// @Description CouchDB admin user
+	Username string `json:"username,omitempty" example:"admin"`
+	// @Description CouchDB admin password, generated when empty. It can also be a reference: env:NAME, file:/path or vault:mount/path#field
+	Password string `json:"password,omitempty"`
+}
+
+// Validate checks the CouchDB configuration for the deployment mode of the peer
+func (c *CouchDBConfig) Validate(mode string) error {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+	switch mode {
+	case "docker":
+		if c.Port < 0 || c.Port > 65535 {
+			return fmt.Errorf("CouchDB port %d out of range (1-65535)", c.Port)
+		}
+	case "service":
+		if c.URL == "" {
+			return fmt.Errorf("CouchDB URL is required in service mode")
+		}
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			return fmt.Errorf("invalid CouchDB URL: %w", err)
+		}
+		// The peer only talks plain HTTP to CouchDB
+		if u.Scheme != "http" || u.Host == "" {
+			return fmt.Errorf("invalid CouchDB URL %s, expected http://host:port", c.URL)
+		}
+	}
+	return nil
 }
 
 // FabricOrdererConfig represents the parameters needed to create a Fabric orderer node
@@ -327,6 +459,30 @@ type FabricOrdererConfig struct {
 	AddressOverrides []AddressOverride `json:"addressOverrides,omitempty"`
 }
 
+// FabricCAConfig represents the parameters needed to create a Fabric CA node
+// @Description Configuration for creating a new Fabric CA node
+type FabricCAConfig struct {
+	BaseNodeConfig
+	// @Description Name of the CA node
+	Name string `json:"name" validate:"required" example:"ca-org1"`
+	// @Description Organization ID whose CA keys the node serves
+	OrganizationID int64 `json:"organizationId" validate:"required" example:"1"`
+	// @Description MSP ID for the organization
+	MSPID string `json:"mspId" validate:"required" example:"Org1MSP"`
+	// @Description External endpoint for the CA server
+	ExternalEndpoint string `json:"externalEndpoint" example:"ca.org1.example.com:7054"`
+	// @Description Listen address for the CA server
+	ListenAddress string `json:"listenAddress" example:"0.0.0.0:7054"`
+	// @Description Operations listen address
+	OperationsListenAddress string `json:"operationsListenAddress" example:"0.0.0.0:9443"`
+	// @Description Domain names for the CA server
+	DomainNames []string `json:"domainNames,omitempty"`
+	// @Description Environment variables for the CA server
+	Env map[string]string `json:"env,omitempty"`
+	// @Description Fabric CA version to use
+	Version string `json:"version" example:"1.5.15"`
+}
+
 // BesuNodeConfig represents the parameters needed to create a Besu node
 type BesuNodeConfig struct {
 	BaseNodeConfig
@@ -350,7 +506,7 @@ type BesuNodeConfig struct {
 // StoredNodeConfig represents the configuration as stored in the database
 // @Description Node configuration as stored in the database
 type StoredNodeConfig struct {
-	// @Description Type of the node (fabric-peer, fabric-orderer, besu)
+	// @Description Type of the node (fabric-peer, fabric-orderer, fabric-ca, besu)
 	Type string `json:"type" example:"fabric-peer"`
 	// @Description Raw JSON configuration data
 	Config json.RawMessage `json:"config"`
@@ -385,6 +541,12 @@ func DeserializeNodeConfig(stored *StoredNodeConfig) (NodeConfig, error) {
 			return nil, fmt.Errorf("failed to unmarshal fabric orderer config: %w", err)
 		}
 		config = &c
+	case "fabric-ca":
+		var c FabricCAConfig
+		if err := json.Unmarshal(stored.Config, &c); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal fabric ca config: %w", err)
+		}
+		config = &c
 	case "besu":
 		var c BesuNodeConfig
 		if err := json.Unmarshal(stored.Config, &c); err != nil {
@@ -413,6 +575,9 @@ func (c *FabricPeerConfig) Validate() error {
 	if c.MSPID == "" {
 		return fmt.Errorf("MSPID is required")
 	}
+	if err := c.CouchDB.Validate(c.Mode); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -429,6 +594,19 @@ func (c *FabricOrdererConfig) Validate() error {
 	return nil
 }
 
+func (c *FabricCAConfig) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.OrganizationID == 0 {
+		return fmt.Errorf("organization ID is required")
+	}
+	if c.MSPID == "" {
+		return fmt.Errorf("MSPID is required")
+	}
+	return nil
+}
+
 func (c *BesuNodeConfig) Validate() error {
 
 	return nil
@@ -449,6 +627,12 @@ func MapToNodeConfig(deploymentConfig NodeDeploymentConfig) (NodeConfig, error)
 		}
 		return nil, fmt.Errorf("failed to convert to fabric orderer config")
 
+	case "fabric-ca":
+		if caConfig := deploymentConfig.ToFabricCAConfig(); caConfig != nil {
+			return caConfig, nil
+		}
+		return nil, fmt.Errorf("failed to convert to fabric ca config")
+
 	case "besu":
 		if besuConfig, ok := deploymentConfig.(*BesuNodeDeploymentConfig); ok {
 			return &BesuNodeConfig{
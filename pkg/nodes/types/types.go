@@ -24,6 +24,7 @@ const (
 	// Fabric node types
 	NodeTypeFabricPeer    NodeType = "FABRIC_PEER"
 	NodeTypeFabricOrderer NodeType = "FABRIC_ORDERER"
+	NodeTypeFabricCA      NodeType = "FABRIC_CA"
 
 	// Besu node types
 	NodeTypeBesuFullnode NodeType = "BESU_FULLNODE"
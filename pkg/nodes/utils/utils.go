@@ -33,6 +33,13 @@ func LoadNodeConfig(data []byte) (types.NodeConfig, error) {
 		}
 		return &config, nil
 
+	case "fabric-ca":
+		var config types.FabricCAConfig
+		if err := json.Unmarshal(stored.Config, &config); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal fabric ca config: %w", err)
+		}
+		return &config, nil
+
 	case "besu":
 		var config types.BesuNodeConfig
 		if err := json.Unmarshal(stored.Config, &config); err != nil {
@@ -70,6 +77,12 @@ func DeserializeDeploymentConfig(configJSON string) (types.NodeDeploymentConfig,
 			return nil, fmt.Errorf("failed to unmarshal fabric orderer config: %w", err)
 		}
 		config = &c
+	case "fabric-ca":
+		var c types.FabricCADeploymentConfig
+		if err := json.Unmarshal([]byte(configJSON), &c); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal fabric ca config: %w", err)
+		}
+		config = &c
 	case "besu":
 		var c types.BesuNodeDeploymentConfig
 		if err := json.Unmarshal([]byte(configJSON), &c); err != nil {
@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -67,9 +68,10 @@ func (h *NotificationHandler) CreateProvider(w http.ResponseWriter, r *http.Requ
 		NotifyBackupSuccess: req.NotifyBackupSuccess,
 		NotifyBackupFailure: req.NotifyBackupFailure,
 		NotifyS3ConnIssue:   req.NotifyS3ConnIssue,
+		NotifyCertExpiry:    req.NotifyCertExpiry,
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), providerErrorStatus(err))
 		return
 	}
 
@@ -165,9 +167,10 @@ func (h *NotificationHandler) UpdateProvider(w http.ResponseWriter, r *http.Requ
 		NotifyBackupSuccess: req.NotifyBackupSuccess,
 		NotifyBackupFailure: req.NotifyBackupFailure,
 		NotifyS3ConnIssue:   req.NotifyS3ConnIssue,
+		NotifyCertExpiry:    req.NotifyCertExpiry,
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), providerErrorStatus(err))
 		return
 	}
 
@@ -202,7 +205,7 @@ func (h *NotificationHandler) DeleteProvider(w http.ResponseWriter, r *http.Requ
 }
 
 // @Summary Test a notification provider
-// @Description Test a notification provider
+// @Description Send a test message through a notification provider. testEmail is required for SMTP providers
 // @Tags Notifications
 // @Accept json
 // @Produce json
@@ -234,10 +237,18 @@ func (h *NotificationHandler) TestProvider(w http.ResponseWriter, r *http.Reques
 		TestEmail: req.TestEmail,
 	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), providerErrorStatus(err))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
+
+// providerErrorStatus maps a provider service error to an HTTP status code
+func providerErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidProviderConfig) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -33,7 +33,7 @@ type ErrorResponse struct {
 }
 
 type CreateProviderRequest struct {
-	Type                notifications.ProviderType `json:"type" validate:"required,oneof=SMTP"`
+	Type                notifications.ProviderType `json:"type" validate:"required,oneof=SMTP SLACK TEAMS WEBHOOK"`
 	Name                string                     `json:"name" validate:"required,min=1,max=255"`
 	Config              interface{}                `json:"config" validate:"required"`
 	IsDefault           bool                       `json:"isDefault"`
@@ -41,10 +41,11 @@ type CreateProviderRequest struct {
 	NotifyBackupSuccess bool                       `json:"notifyBackupSuccess"`
 	NotifyBackupFailure bool                       `json:"notifyBackupFailure"`
 	NotifyS3ConnIssue   bool                       `json:"notifyS3ConnIssue"`
+	NotifyCertExpiry    bool                       `json:"notifyCertExpiry"`
 }
 
 type UpdateProviderRequest struct {
-	Type                notifications.ProviderType `json:"type" validate:"required,oneof=SMTP"`
+	Type                notifications.ProviderType `json:"type" validate:"required,oneof=SMTP SLACK TEAMS WEBHOOK"`
 	Name                string                     `json:"name" validate:"required,min=1,max=255"`
 	Config              interface{}                `json:"config" validate:"required"`
 	IsDefault           bool                       `json:"isDefault"`
@@ -52,6 +53,7 @@ type UpdateProviderRequest struct {
 	NotifyBackupSuccess bool                       `json:"notifyBackupSuccess"`
 	NotifyBackupFailure bool                       `json:"notifyBackupFailure"`
 	NotifyS3ConnIssue   bool                       `json:"notifyS3ConnIssue"`
+	NotifyCertExpiry    bool                       `json:"notifyCertExpiry"`
 }
 
 type ProviderResponse struct {
@@ -64,6 +66,7 @@ type ProviderResponse struct {
 	NotifyBackupSuccess bool                       `json:"notifyBackupSuccess"`
 	NotifyBackupFailure bool                       `json:"notifyBackupFailure"`
 	NotifyS3ConnIssue   bool                       `json:"notifyS3ConnIssue"`
+	NotifyCertExpiry    bool                       `json:"notifyCertExpiry"`
 	LastTestAt          *time.Time                 `json:"lastTestAt,omitempty"`
 	LastTestStatus      string                     `json:"lastTestStatus,omitempty"`
 	LastTestMessage     string                     `json:"lastTestMessage,omitempty"`
@@ -73,7 +76,8 @@ type ProviderResponse struct {
 
 // TestProviderRequest represents the request to test a provider
 type TestProviderRequest struct {
-	TestEmail string `json:"testEmail" validate:"required,email"`
+	// TestEmail is the recipient of the test message, required for SMTP providers
+	TestEmail string `json:"testEmail" validate:"omitempty,email"`
 }
 
 // TestProviderResponse represents the response from testing a provider
@@ -12,6 +12,9 @@ type Service interface {
 	// SendBackupFailureNotification sends a notification about a failed backup
 	SendBackupFailureNotification(ctx context.Context, data BackupFailureData) error
 
+	// SendBackupVerificationFailureNotification sends a notification about a backup failing its verification
+	SendBackupVerificationFailureNotification(ctx context.Context, data BackupVerificationFailureData) error
+
 	// SendS3ConnectionIssueNotification sends a notification about S3 connection issues
 	SendS3ConnectionIssueNotification(ctx context.Context, data S3ConnectionIssueData) error
 
@@ -20,4 +23,13 @@ type Service interface {
 
 	// SendNodeRecoveryNotification sends a notification about a node that has recovered
 	SendNodeRecoveryNotification(ctx context.Context, data NodeUpData) error
+
+	// SendNodeDegradedNotification sends a notification about a node failing a protocol health check
+	SendNodeDegradedNotification(ctx context.Context, data NodeDegradedData) error
+
+	// SendCertificateExpiringNotification sends a notification about a certificate that is about to expire
+	SendCertificateExpiringNotification(ctx context.Context, data CertificateExpiringData) error
+
+	// SendAlertNotification sends a notification about an alert that fired or resolved to the given providers
+	SendAlertNotification(ctx context.Context, providerIDs []int64, data AlertData) error
 }
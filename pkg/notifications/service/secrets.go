@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+)
+
+// smtpPasswordField is the key of the password in the stored config of SMTP providers
+const smtpPasswordField = "password"
+
+// sealConfig encodes a provider config for storage with the SMTP password
+// encrypted. An SMTP config without password keeps the stored password of the
+// provider with ID existingID, when it is not 0.
+func (s *NotificationService) sealConfig(ctx context.Context, providerType notifications.ProviderType, config interface{}, existingID int64) (string, error) {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if providerType != notifications.ProviderTypeSMTP {
+		return string(configJSON), nil
+	}
+
+	// The config is kept as a map so fields unknown to SMTPConfig are preserved
+	var fields map[string]interface{}
+	if err := json.Unmarshal(configJSON, &fields); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidProviderConfig, err)
+	}
+	password, _ := fields[smtpPasswordField].(string)
+	if password == "" && existingID != 0 {
+		existing, err := s.queries.GetNotificationProvider(ctx, existingID)
+		if err != nil {
+			return "", fmt.Errorf("failed to get provider: %w", err)
+		}
+		var stored map[string]interface{}
+		if err := json.Unmarshal([]byte(existing.Config), &stored); err == nil {
+			password, _ = stored[smtpPasswordField].(string)
+		}
+	}
+	if fields[smtpPasswordField], err = s.secrets.Seal(ctx, password); err != nil {
+		return "", err
+	}
+
+	sealed, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return string(sealed), nil
+}
+
+// redactConfig removes the secrets of a stored provider config before it is returned
+func redactConfig(providerType string, config interface{}) interface{} {
+	if fields, ok := config.(map[string]interface{}); ok && notifications.ProviderType(providerType) == notifications.ProviderTypeSMTP {
+		delete(fields, smtpPasswordField)
+	}
+	return config
+}
+
+// smtpConfig decodes the config of an SMTP provider with its password decrypted
+func (s *NotificationService) smtpConfig(ctx context.Context, provider *db.NotificationProvider) (notifications.SMTPConfig, error) {
+	var config notifications.SMTPConfig
+	if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
+		return config, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	password, err := s.secrets.Open(ctx, config.Password)
+	if err != nil {
+		return config, fmt.Errorf("failed to open SMTP password: %w", err)
+	}
+	config.Password = password
+	return config, nil
+}
+
+// resealStoredSecrets encrypts the SMTP passwords stored in plaintext or with
+// a previous key encryption key, and returns how many were updated
+func (s *NotificationService) resealStoredSecrets(ctx context.Context, q *db.Queries) (int, error) {
+	providers, err := q.ListNotificationProviders(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list providers: %w", err)
+	}
+	resealedCount := 0
+	for _, provider := range providers {
+		if notifications.ProviderType(provider.Type) != notifications.ProviderTypeSMTP {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(provider.Config), &fields); err != nil {
+			return 0, fmt.Errorf("failed to unmarshal config of provider %d: %w", provider.ID, err)
+		}
+		password, _ := fields[smtpPasswordField].(string)
+		sealed, resealed, err := s.secrets.Reseal(ctx, password)
+		if err != nil {
+			return 0, fmt.Errorf("failed to seal password of provider %d: %w", provider.ID, err)
+		}
+		if !resealed {
+			continue
+		}
+		fields[smtpPasswordField] = sealed
+		config, err := json.Marshal(fields)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal config: %w", err)
+		}
+		if err := q.UpdateNotificationProviderConfig(ctx, &db.UpdateNotificationProviderConfigParams{
+			ID:     provider.ID,
+			Config: string(config),
+		}); err != nil {
+			return 0, fmt.Errorf("failed to update config of provider %d: %w", provider.ID, err)
+		}
+		s.logger.Info("Encrypted the password of notification provider", "providerID", provider.ID)
+		resealedCount++
+	}
+	return resealedCount, nil
+}
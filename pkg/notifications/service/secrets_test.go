@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/providers/database"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/secrets"
+	"github.com/chainlaunch/chainlaunch/pkg/logger"
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+)
+
+func newTestQueries(t *testing.T) *db.Queries {
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	driver, err := sqlite3.WithInstance(database, &sqlite3.Config{})
+	if err != nil {
+		t.Fatalf("failed to create sqlite driver: %v", err)
+	}
+	source, err := iofs.New(os.DirFS("../../db/migrations"), ".")
+	if err != nil {
+		t.Fatalf("failed to open migrations: %v", err)
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite3", driver)
+	if err != nil {
+		t.Fatalf("failed to create migrate instance: %v", err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatalf("failed to run migrations: %v", err)
+	}
+	return db.New(database)
+}
+
+func newTestSecretStore(t *testing.T, active []byte, previous ...[]byte) *secrets.Store {
+	ring, err := database.NewKeyRing(active, previous...)
+	if err != nil {
+		t.Fatalf("failed to create key ring: %v", err)
+	}
+	return secrets.NewStore(ring)
+}
+
+func testSMTPConfig(password string) notifications.SMTPConfig {
+	return notifications.SMTPConfig{
+		Host:     "smtp.example.com",
+		Port:     587,
+		Username: "chainlaunch",
+		Password: password,
+		From:     "chainlaunch@example.com",
+		TLS:      true,
+	}
+}
+
+// storedConfig returns the config of a provider as it is stored in the database
+func storedConfig(t *testing.T, queries *db.Queries, id int64) map[string]interface{} {
+	provider, err := queries.GetNotificationProvider(context.Background(), id)
+	if err != nil {
+		t.Fatalf("failed to get provider: %v", err)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
+		t.Fatalf("failed to unmarshal config: %v", err)
+	}
+	return config
+}
+
+func TestSMTPPasswordSealed(t *testing.T) {
+	ctx := context.Background()
+	queries := newTestQueries(t)
+	s := NewNotificationService(queries, logger.NewDefault(), newTestSecretStore(t, bytes.Repeat([]byte{1}, 32)))
+
+	provider, err := s.CreateProvider(ctx, notifications.CreateProviderParams{
+		Type:   notifications.ProviderTypeSMTP,
+		Name:   "smtp",
+		Config: testSMTPConfig("smtp-password"),
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	if _, ok := provider.Config.(map[string]interface{})[smtpPasswordField]; ok {
+		t.Error("the password should not be returned")
+	}
+
+	stored := storedConfig(t, queries, provider.ID)
+	password, _ := stored[smtpPasswordField].(string)
+	if !secrets.IsSealed(password) {
+		t.Fatalf("expected a sealed password, got %q", password)
+	}
+	if stored["host"] != "smtp.example.com" {
+		t.Errorf("unexpected stored config %v", stored)
+	}
+
+	// Updating without a password keeps the stored one
+	if _, err := s.UpdateProvider(ctx, notifications.UpdateProviderParams{
+		ID:     provider.ID,
+		Type:   notifications.ProviderTypeSMTP,
+		Name:   "smtp",
+		Config: testSMTPConfig(""),
+	}); err != nil {
+		t.Fatalf("failed to update provider: %v", err)
+	}
+	dbProvider, err := queries.GetNotificationProvider(ctx, provider.ID)
+	if err != nil {
+		t.Fatalf("failed to get provider: %v", err)
+	}
+	config, err := s.smtpConfig(ctx, dbProvider)
+	if err != nil || config.Password != "smtp-password" {
+		t.Errorf("expected the stored password to be kept, got %q (%v)", config.Password, err)
+	}
+
+	listed, err := s.ListProviders(ctx)
+	if err != nil || len(listed) != 1 {
+		t.Fatalf("failed to list providers: %v", err)
+	}
+	if _, ok := listed[0].Config.(map[string]interface{})[smtpPasswordField]; ok {
+		t.Error("the password should not be listed")
+	}
+
+	other := &NotificationService{queries: queries, logger: logger.NewDefault(), secrets: newTestSecretStore(t, bytes.Repeat([]byte{2}, 32))}
+	if _, err := other.smtpConfig(ctx, dbProvider); err == nil {
+		t.Error("opening with another key: expected an error")
+	}
+}
+
+func TestResealSMTPPasswords(t *testing.T) {
+	ctx := context.Background()
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+	queries := newTestQueries(t)
+
+	sealed, err := newTestSecretStore(t, oldKey).Seal(ctx, "old-password")
+	if err != nil {
+		t.Fatalf("failed to seal: %v", err)
+	}
+	providers := map[string]struct {
+		providerType notifications.ProviderType
+		password     string
+	}{
+		"plaintext":    {notifications.ProviderTypeSMTP, "plain-password"},
+		"previous key": {notifications.ProviderTypeSMTP, sealed},
+		"reference":    {notifications.ProviderTypeSMTP, "env:SMTP_PASSWORD"},
+		"webhook":      {notifications.ProviderTypeWebhook, "not-a-secret"},
+	}
+	ids := make(map[string]int64)
+	for name, p := range providers {
+		config, _ := json.Marshal(map[string]interface{}{"host": "smtp.example.com", smtpPasswordField: p.password})
+		provider, err := queries.CreateNotificationProvider(ctx, &db.CreateNotificationProviderParams{
+			Type:   string(p.providerType),
+			Name:   name,
+			Config: string(config),
+		})
+		if err != nil {
+			t.Fatalf("failed to create %s provider: %v", name, err)
+		}
+		ids[name] = provider.ID
+	}
+
+	// Stored passwords are resealed when the service starts
+	s := NewNotificationService(queries, logger.NewDefault(), newTestSecretStore(t, newKey, oldKey))
+	s.secrets = newTestSecretStore(t, newKey)
+
+	expected := map[string]string{
+		"plaintext":    "plain-password",
+		"previous key": "old-password",
+	}
+	for name, password := range expected {
+		stored := storedConfig(t, queries, ids[name])
+		if value, _ := stored[smtpPasswordField].(string); !secrets.IsSealed(value) {
+			t.Errorf("%s: expected a sealed password, got %q", name, value)
+		}
+		if stored["host"] != "smtp.example.com" {
+			t.Errorf("%s: other fields should be kept, got %v", name, stored)
+		}
+		provider, err := queries.GetNotificationProvider(ctx, ids[name])
+		if err != nil {
+			t.Fatalf("failed to get provider: %v", err)
+		}
+		config, err := s.smtpConfig(ctx, provider)
+		if err != nil || config.Password != password {
+			t.Errorf("%s: expected %q, got %q (%v)", name, password, config.Password, err)
+		}
+	}
+
+	for name, value := range map[string]string{"reference": "env:SMTP_PASSWORD", "webhook": "not-a-secret"} {
+		if stored := storedConfig(t, queries, ids[name]); stored[smtpPasswordField] != value {
+			t.Errorf("%s: expected the config unchanged, got %v", name, stored)
+		}
+	}
+
+	// A password sealed with an unknown key stops the run
+	s.secrets = newTestSecretStore(t, bytes.Repeat([]byte{3}, 32))
+	config, _ := json.Marshal(map[string]interface{}{smtpPasswordField: sealed})
+	if err := queries.UpdateNotificationProviderConfig(ctx, &db.UpdateNotificationProviderConfigParams{
+		ID:     ids["plaintext"],
+		Config: string(config),
+	}); err != nil {
+		t.Fatalf("failed to update config: %v", err)
+	}
+	if _, err := s.resealStoredSecrets(ctx, s.queries); err == nil {
+		t.Error("unknown key: expected an error")
+	}
+}
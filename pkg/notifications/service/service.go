@@ -4,27 +4,45 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"crypto/tls"
 
 	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/keymanagement/secrets"
 	"github.com/chainlaunch/chainlaunch/pkg/logger"
 	"github.com/chainlaunch/chainlaunch/pkg/notifications"
 	"gopkg.in/mail.v2"
 )
 
+// ErrInvalidProviderConfig is returned when a provider configuration is rejected
+var ErrInvalidProviderConfig = errors.New("invalid provider config")
+
 type NotificationService struct {
 	queries *db.Queries
 	logger  *logger.Logger
+	secrets *secrets.Store
 }
 
-func NewNotificationService(queries *db.Queries, logger *logger.Logger) *NotificationService {
-	return &NotificationService{
+func NewNotificationService(queries *db.Queries, logger *logger.Logger, secretStore *secrets.Store) *NotificationService {
+	service := &NotificationService{
 		queries: queries,
 		logger:  logger,
+		secrets: secretStore,
+	}
+
+	// Encrypt the SMTP passwords of providers created before they were sealed,
+	// and again when the key encryption key is rotated
+	if _, err := service.resealStoredSecrets(context.Background(), queries); err != nil {
+		logger.Error("Failed to encrypt notification provider secrets", "error", err)
 	}
+	secretStore.RegisterResealer("SMTP passwords", service.resealStoredSecrets)
+
+	return service
 }
 
 // EmailContent represents the content of an email with both HTML and plain text versions
@@ -35,9 +53,13 @@ type EmailContent struct {
 }
 
 func (s *NotificationService) CreateProvider(ctx context.Context, params notifications.CreateProviderParams) (*notifications.NotificationProvider, error) {
-	configJSON, err := json.Marshal(params.Config)
+	if err := validateProviderConfig(params.Type, params.Config); err != nil {
+		return nil, err
+	}
+
+	configJSON, err := s.sealConfig(ctx, params.Type, params.Config, 0)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal config: %w", err)
+		return nil, err
 	}
 
 	if params.IsDefault {
@@ -57,6 +79,7 @@ func (s *NotificationService) CreateProvider(ctx context.Context, params notific
 		NotifyBackupSuccess:     params.NotifyBackupSuccess,
 		NotifyBackupFailure:     params.NotifyBackupFailure,
 		NotifyS3ConnectionIssue: params.NotifyS3ConnIssue,
+		NotifyCertificateExpiry: params.NotifyCertExpiry,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create provider: %w", err)
@@ -71,9 +94,13 @@ func (s *NotificationService) CreateProvider(ctx context.Context, params notific
 }
 
 func (s *NotificationService) UpdateProvider(ctx context.Context, params notifications.UpdateProviderParams) (*notifications.NotificationProvider, error) {
-	configJSON, err := json.Marshal(params.Config)
+	if err := validateProviderConfig(params.Type, params.Config); err != nil {
+		return nil, err
+	}
+
+	configJSON, err := s.sealConfig(ctx, params.Type, params.Config, params.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal config: %w", err)
+		return nil, err
 	}
 
 	if params.IsDefault {
@@ -94,6 +121,7 @@ func (s *NotificationService) UpdateProvider(ctx context.Context, params notific
 		NotifyBackupSuccess:     params.NotifyBackupSuccess,
 		NotifyBackupFailure:     params.NotifyBackupFailure,
 		NotifyS3ConnectionIssue: params.NotifyS3ConnIssue,
+		NotifyCertificateExpiry: params.NotifyCertExpiry,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to update provider: %w", err)
@@ -164,20 +192,42 @@ func (s *NotificationService) TestProvider(ctx context.Context, id int64, params
 		return nil, fmt.Errorf("failed to get provider: %w", err)
 	}
 
-	var config notifications.SMTPConfig
-	if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
-	}
+	var testStatus, testMessage string
+	switch notifications.ProviderType(provider.Type) {
+	case notifications.ProviderTypeSMTP:
+		if params.TestEmail == "" {
+			return nil, fmt.Errorf("%w: testEmail is required to test SMTP providers", ErrInvalidProviderConfig)
+		}
 
-	s.logger.Info("Sending test email", "from", config.From, "to", params.TestEmail)
+		config, err := s.smtpConfig(ctx, provider)
+		if err != nil {
+			return nil, err
+		}
 
-	// Test email sending
-	err = s.sendTestEmail(config, params.TestEmail)
-	testStatus := "success"
-	testMessage := "Email sent successfully"
-	if err != nil {
-		testStatus = "failure"
-		testMessage = fmt.Sprintf("Failed to send email: %v", err)
+		s.logger.Info("Sending test email", "from", config.From, "to", params.TestEmail)
+
+		// Test email sending
+		err = s.sendTestEmail(config, params.TestEmail)
+		testStatus = "success"
+		testMessage = "Email sent successfully"
+		if err != nil {
+			testStatus = "failure"
+			testMessage = fmt.Sprintf("Failed to send email: %v", err)
+		}
+	default:
+		s.logger.Info("Sending test notification", "provider", provider.Name, "type", provider.Type)
+
+		content := EmailContent{
+			Subject:   "Test notification from ChainDeploy",
+			PlainText: "This is a test message to verify your notification provider is working correctly.",
+		}
+		err = s.deliver(ctx, provider, "TEST", content, nil)
+		testStatus = "success"
+		testMessage = "Notification sent successfully"
+		if err != nil {
+			testStatus = "failure"
+			testMessage = fmt.Sprintf("Failed to send notification: %v", err)
+		}
 	}
 
 	// Update provider with test results
@@ -276,12 +326,13 @@ func (s *NotificationService) providerToDTO(provider *db.NotificationProvider, c
 		ID:                  provider.ID,
 		Type:                notifications.ProviderType(provider.Type),
 		Name:                provider.Name,
-		Config:              config,
+		Config:              redactConfig(provider.Type, config),
 		IsDefault:           provider.IsDefault,
 		NotifyNodeDowntime:  provider.NotifyNodeDowntime,
 		NotifyBackupSuccess: provider.NotifyBackupSuccess,
 		NotifyBackupFailure: provider.NotifyBackupFailure,
 		NotifyS3ConnIssue:   provider.NotifyS3ConnectionIssue,
+		NotifyCertExpiry:    provider.NotifyCertificateExpiry,
 		LastTestAt: func() *time.Time {
 			if provider.LastTestAt.Valid {
 				return &provider.LastTestAt.Time
@@ -297,28 +348,7 @@ func (s *NotificationService) providerToDTO(provider *db.NotificationProvider, c
 
 // SendBackupSuccessNotification sends a notification for a successful backup
 func (s *NotificationService) SendBackupSuccessNotification(ctx context.Context, data notifications.BackupSuccessData) error {
-	// Get default notification provider for backup successes
-	provider, err := s.queries.GetDefaultNotificationProviderForType(ctx, "BACKUP_SUCCESS")
-	if err != nil {
-		s.logger.Warn("Failed to get default notification provider for backup successes", "error", err)
-		return nil
-	}
-
-	if !provider.NotifyBackupSuccess {
-		// Provider is configured to not notify for backup successes
-		return nil
-	}
-
-	var config notifications.SMTPConfig
-	if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
-	}
-
-	// Create notification content
-	content := s.createBackupSuccessContent(data)
-
-	// Send the email
-	if err := s.sendEmail(config, config.From, []string{config.From}, content); err != nil {
+	if err := s.notify(ctx, notifications.NotificationTypeBackupSuccess, data); err != nil {
 		return fmt.Errorf("failed to send backup success notification: %w", err)
 	}
 
@@ -328,125 +358,213 @@ func (s *NotificationService) SendBackupSuccessNotification(ctx context.Context,
 
 // SendBackupFailureNotification sends a notification for a failed backup
 func (s *NotificationService) SendBackupFailureNotification(ctx context.Context, data notifications.BackupFailureData) error {
-	// Get default notification provider for backup failures
-	provider, err := s.queries.GetDefaultNotificationProviderForType(ctx, "BACKUP_FAILURE")
-	if err != nil {
-		s.logger.Warn("Failed to get default notification provider for backup failures", "error", err)
-		return nil
-	}
-
-	if !provider.NotifyBackupFailure {
-		// Provider is configured to not notify for backup failures
-		return nil
-	}
-
-	var config notifications.SMTPConfig
-	if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+	if err := s.notify(ctx, notifications.NotificationTypeBackupFailure, data); err != nil {
+		return fmt.Errorf("failed to send backup failure notification: %w", err)
 	}
 
-	// Create notification content
-	content := s.createBackupFailureContent(data)
+	s.logger.Info("Sent backup failure notification", "backupID", data.BackupID)
+	return nil
+}
 
-	// Send the email
-	if err := s.sendEmail(config, config.From, []string{config.From}, content); err != nil {
-		return fmt.Errorf("failed to send backup failure notification: %w", err)
+// SendBackupVerificationFailureNotification sends a notification for a backup failing its verification
+func (s *NotificationService) SendBackupVerificationFailureNotification(ctx context.Context, data notifications.BackupVerificationFailureData) error {
+	if err := s.notify(ctx, notifications.NotificationTypeBackupVerificationFailure, data); err != nil {
+		return fmt.Errorf("failed to send backup verification failure notification: %w", err)
 	}
 
-	s.logger.Info("Sent backup failure notification", "backupID", data.BackupID)
+	s.logger.Info("Sent backup verification failure notification", "backupID", data.BackupID)
 	return nil
 }
 
 // SendS3ConnectionIssueNotification sends a notification for S3 connection issues
 func (s *NotificationService) SendS3ConnectionIssueNotification(ctx context.Context, data notifications.S3ConnectionIssueData) error {
-	// Get default notification provider for S3 connection issues
-	provider, err := s.queries.GetDefaultNotificationProviderForType(ctx, "S3_CONNECTION_ISSUE")
-	if err != nil {
-		s.logger.Warn("Failed to get default notification provider for S3 connection issues", "error", err)
-		return nil
+	if err := s.notify(ctx, notifications.NotificationTypeS3ConnIssue, data); err != nil {
+		return fmt.Errorf("failed to send S3 connection issue notification: %w", err)
 	}
 
-	if !provider.NotifyS3ConnectionIssue {
-		// Provider is configured to not notify for S3 connection issues
-		return nil
-	}
+	s.logger.Info("Sent S3 connection issue notification", "targetName", data.TargetName)
+	return nil
+}
 
-	var config notifications.SMTPConfig
-	if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+// SendNodeDowntimeNotification sends a notification for node downtime
+func (s *NotificationService) SendNodeDowntimeNotification(ctx context.Context, data notifications.NodeDowntimeData) error {
+	if err := s.notify(ctx, notifications.NotificationTypeNodeDowntime, data); err != nil {
+		return fmt.Errorf("failed to send node downtime notification: %w", err)
 	}
 
-	// Create notification content
-	content := s.createS3ConnIssueContent(data)
+	s.logger.Info("Sent node downtime notification", "nodeID", data.NodeID, "nodeName", data.NodeName)
+	return nil
+}
 
-	// Send the email
-	if err := s.sendEmail(config, config.From, []string{config.From}, content); err != nil {
-		return fmt.Errorf("failed to send S3 connection issue notification: %w", err)
+// SendNodeRecoveryNotification sends a notification for node recovery
+func (s *NotificationService) SendNodeRecoveryNotification(ctx context.Context, data notifications.NodeUpData) error {
+	if err := s.notify(ctx, notifications.NotificationTypeNodeRecovery, data); err != nil {
+		return fmt.Errorf("failed to send node recovery notification: %w", err)
 	}
 
-	s.logger.Info("Sent S3 connection issue notification", "targetName", data.TargetName)
+	s.logger.Info("Sent node recovery notification", "nodeID", data.NodeID, "nodeName", data.NodeName)
 	return nil
 }
 
-// SendNodeDowntimeNotification sends a notification for node downtime
-func (s *NotificationService) SendNodeDowntimeNotification(ctx context.Context, data notifications.NodeDowntimeData) error {
-	// Get default notification provider for node downtime
-	provider, err := s.queries.GetDefaultNotificationProviderForType(ctx, "NODE_DOWNTIME")
-	if err != nil {
-		s.logger.Warn("Failed to get default notification provider for node downtime", "error", err)
-		return nil
+// SendNodeDegradedNotification sends a notification for a node failing a protocol health check
+func (s *NotificationService) SendNodeDegradedNotification(ctx context.Context, data notifications.NodeDegradedData) error {
+	if err := s.notify(ctx, notifications.NotificationTypeNodeDegraded, data); err != nil {
+		return fmt.Errorf("failed to send node degraded notification: %w", err)
 	}
 
-	if !provider.NotifyNodeDowntime {
-		// Provider is configured to not notify for node downtime
-		return nil
-	}
+	s.logger.Info("Sent node degraded notification", "nodeID", data.NodeID, "nodeName", data.NodeName, "reason", data.Reason)
+	return nil
+}
 
-	var config notifications.SMTPConfig
-	if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+// SendCertificateExpiringNotification sends a notification for a certificate that is about to expire
+func (s *NotificationService) SendCertificateExpiringNotification(ctx context.Context, data notifications.CertificateExpiringData) error {
+	if err := s.notify(ctx, notifications.NotificationTypeCertExpiring, data); err != nil {
+		return fmt.Errorf("failed to send certificate expiry notification: %w", err)
 	}
 
-	// Create notification content
-	content := s.createNodeDowntimeContent(data)
+	s.logger.Info("Sent certificate expiry notification", "subject", data.Subject, "expiresAt", data.ExpiresAt)
+	return nil
+}
 
-	// Send the email
-	if err := s.sendEmail(config, config.From, []string{config.From}, content); err != nil {
-		return fmt.Errorf("failed to send node downtime notification: %w", err)
+// SendAlertNotification sends a notification for an alert that fired or resolved to the given providers
+func (s *NotificationService) SendAlertNotification(ctx context.Context, providerIDs []int64, data notifications.AlertData) error {
+	notificationType := notifications.NotificationTypeAlertFiring
+	if data.ResolvedAt != nil {
+		notificationType = notifications.NotificationTypeAlertResolved
 	}
+	content := s.createNotificationContent(notificationType, data)
 
-	s.logger.Info("Sent node downtime notification", "nodeID", data.NodeID, "nodeName", data.NodeName)
+	var errs []error
+	for _, providerID := range providerIDs {
+		provider, err := s.queries.GetNotificationProvider(ctx, providerID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("provider %d: failed to get provider: %w", providerID, err))
+			continue
+		}
+		if err := s.deliver(ctx, provider, notificationType, content, data); err != nil {
+			errs = append(errs, fmt.Errorf("provider %s: %w", provider.Name, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to send alert notification: %w", err)
+	}
+
+	s.logger.Info("Sent alert notification", "alertID", data.AlertID, "rule", data.RuleName, "type", notificationType)
 	return nil
 }
 
-// SendNodeRecoveryNotification sends a notification for node recovery
-func (s *NotificationService) SendNodeRecoveryNotification(ctx context.Context, data notifications.NodeUpData) error {
-	// Get default notification provider for node downtime (same provider handles recovery)
-	provider, err := s.queries.GetDefaultNotificationProviderForType(ctx, "NODE_DOWNTIME")
+// notify sends a notification through every default provider that is configured
+// for its type. A failing provider doesn't prevent delivery through the others.
+func (s *NotificationService) notify(ctx context.Context, notificationType notifications.NotificationType, data interface{}) error {
+	// Node recoveries and degradations go to the providers that handle node downtime,
+	// backup verification failures to the ones handling backup failures
+	routingType := notificationType
+	if notificationType == notifications.NotificationTypeNodeRecovery || notificationType == notifications.NotificationTypeNodeDegraded {
+		routingType = notifications.NotificationTypeNodeDowntime
+	}
+	if notificationType == notifications.NotificationTypeBackupVerificationFailure {
+		routingType = notifications.NotificationTypeBackupFailure
+	}
+
+	providers, err := s.queries.ListDefaultNotificationProvidersForType(ctx, string(routingType))
 	if err != nil {
-		s.logger.Warn("Failed to get default notification provider for node recovery", "error", err)
+		s.logger.Warn("Failed to get default notification providers", "type", routingType, "error", err)
 		return nil
 	}
 
-	if !provider.NotifyNodeDowntime {
-		// Provider is configured to not notify for node events
-		return nil
+	content := s.createNotificationContent(notificationType, data)
+
+	var errs []error
+	for _, provider := range providers {
+		if err := s.deliver(ctx, provider, notificationType, content, data); err != nil {
+			errs = append(errs, fmt.Errorf("provider %s: %w", provider.Name, err))
+		}
 	}
+	return errors.Join(errs...)
+}
 
-	var config notifications.SMTPConfig
-	if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
+// deliver sends a notification through a single provider
+func (s *NotificationService) deliver(ctx context.Context, provider *db.NotificationProvider, notificationType notifications.NotificationType, content EmailContent, data interface{}) error {
+	switch notifications.ProviderType(provider.Type) {
+	case notifications.ProviderTypeSMTP:
+		config, err := s.smtpConfig(ctx, provider)
+		if err != nil {
+			return err
+		}
+		return s.sendEmail(config, config.From, []string{config.From}, content)
+	case notifications.ProviderTypeSlack:
+		var config notifications.SlackConfig
+		if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
+			return fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		msg, err := newWebhookMessage(config.Templates, notificationType, content, data)
+		if err != nil {
+			return err
+		}
+		return s.sendSlack(ctx, config, msg)
+	case notifications.ProviderTypeTeams:
+		var config notifications.TeamsConfig
+		if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
+			return fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		msg, err := newWebhookMessage(config.Templates, notificationType, content, data)
+		if err != nil {
+			return err
+		}
+		return s.sendTeams(ctx, config, msg)
+	case notifications.ProviderTypeWebhook:
+		var config notifications.WebhookConfig
+		if err := json.Unmarshal([]byte(provider.Config), &config); err != nil {
+			return fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		msg, err := newWebhookMessage(config.Templates, notificationType, content, data)
+		if err != nil {
+			return err
+		}
+		return s.sendWebhook(ctx, config, msg)
+	default:
+		return fmt.Errorf("unsupported provider type: %s", provider.Type)
 	}
+}
 
-	// Create notification content
-	content := s.createNodeRecoveryContent(data)
+// validateProviderConfig checks the configuration of webhook based providers
+func validateProviderConfig(providerType notifications.ProviderType, config interface{}) error {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
 
-	// Send the email
-	if err := s.sendEmail(config, config.From, []string{config.From}, content); err != nil {
-		return fmt.Errorf("failed to send node recovery notification: %w", err)
+	var webhookURL string
+	var templates notifications.MessageTemplates
+	switch providerType {
+	case notifications.ProviderTypeSlack:
+		var c notifications.SlackConfig
+		if err := json.Unmarshal(configJSON, &c); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProviderConfig, err)
+		}
+		webhookURL, templates = c.WebhookURL, c.Templates
+	case notifications.ProviderTypeTeams:
+		var c notifications.TeamsConfig
+		if err := json.Unmarshal(configJSON, &c); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProviderConfig, err)
+		}
+		webhookURL, templates = c.WebhookURL, c.Templates
+	case notifications.ProviderTypeWebhook:
+		var c notifications.WebhookConfig
+		if err := json.Unmarshal(configJSON, &c); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProviderConfig, err)
+		}
+		webhookURL, templates = c.URL, c.Templates
+	default:
+		return nil
 	}
 
-	s.logger.Info("Sent node recovery notification", "nodeID", data.NodeID, "nodeName", data.NodeName)
+	if err := validateWebhookURL(webhookURL); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProviderConfig, err)
+	}
+	if err := validateTemplates(templates); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProviderConfig, err)
+	}
 	return nil
 }
 
@@ -505,6 +623,261 @@ Your node is now functioning normally.`,
 	}
 }
 
+// createNodeDegradedContent creates the email content for node degraded notifications
+func (s *NotificationService) createNodeDegradedContent(data notifications.NodeDegradedData) EmailContent {
+	// Create plain text content
+	plainText := fmt.Sprintf(`Node Degraded
+
+A node in your infrastructure is reachable but failed a health check.
+
+Details:
+- Node ID: %d
+- Node Name: %s
+- Node Type: %s
+- Endpoint: %s
+- Reason: %s
+- Detected At: %s
+
+%s`,
+		data.NodeID, data.NodeName, data.NodeType, data.Endpoint,
+		data.Reason, data.DetectedAt.Format(time.RFC3339), data.Message)
+
+	// Create HTML content
+	html := fmt.Sprintf(`
+	<html>
+		<body>
+			<h2 style="color: #ff9900;">Node Degraded</h2>
+			<p>A node in your infrastructure is reachable but failed a health check.</p>
+
+			<h3>Details:</h3>
+			<ul>
+				<li><strong>Node ID:</strong> %d</li>
+				<li><strong>Node Name:</strong> %s</li>
+				<li><strong>Node Type:</strong> %s</li>
+				<li><strong>Endpoint:</strong> %s</li>
+				<li><strong>Reason:</strong> %s</li>
+				<li><strong>Detected At:</strong> %s</li>
+			</ul>
+
+			<p>%s</p>
+			<hr>
+			<small>Sent from ChainDeploy</small>
+		</body>
+	</html>`,
+		data.NodeID, data.NodeName, data.NodeType, data.Endpoint,
+		data.Reason, data.DetectedAt.Format(time.RFC3339), data.Message)
+
+	return EmailContent{
+		Subject:   fmt.Sprintf("Node Degraded: %s (%s)", data.NodeName, data.Reason),
+		PlainText: plainText,
+		HTML:      html,
+	}
+}
+
+// createBackupVerificationFailureContent creates the email content for a backup failing its verification
+func (s *NotificationService) createBackupVerificationFailureContent(data notifications.BackupVerificationFailureData) EmailContent {
+	// Create plain text content
+	plainText := fmt.Sprintf(`Backup Verification Failed
+
+A completed backup could not be verified and may not be restorable.
+
+Details:
+- Backup ID: %d
+- Snapshot: %s
+- Schedule: %s
+- Target: %s (%s)
+- Backup Time: %s
+- Verified At: %s
+
+Failed checks:
+- %s
+
+Take a new backup and check the storage of the target.`,
+		data.BackupID, data.SnapshotID, data.ScheduleName, data.TargetName, data.TargetType,
+		data.BackupTime.Format(time.RFC3339), data.VerifiedAt.Format(time.RFC3339),
+		strings.Join(data.FailedChecks, "\n- "))
+
+	items := make([]string, len(data.FailedChecks))
+	for i, check := range data.FailedChecks {
+		items[i] = "<li>" + check + "</li>"
+	}
+
+	// Create HTML content
+	html := fmt.Sprintf(`
+	<html>
+		<body>
+			<h2 style="color: #dc3545;">Backup Verification Failed</h2>
+			<p>A completed backup could not be verified and may not be restorable.</p>
+
+			<h3>Details:</h3>
+			<ul>
+				<li><strong>Backup ID:</strong> %d</li>
+				<li><strong>Snapshot:</strong> %s</li>
+				<li><strong>Schedule:</strong> %s</li>
+				<li><strong>Target:</strong> %s (%s)</li>
+				<li><strong>Backup Time:</strong> %s</li>
+				<li><strong>Verified At:</strong> %s</li>
+			</ul>
+
+			<h3>Failed checks:</h3>
+			<ul>%s</ul>
+
+			<p>Take a new backup and check the storage of the target.</p>
+			<hr>
+			<small>Sent from ChainDeploy</small>
+		</body>
+	</html>`,
+		data.BackupID, data.SnapshotID, data.ScheduleName, data.TargetName, data.TargetType,
+		data.BackupTime.Format(time.RFC3339), data.VerifiedAt.Format(time.RFC3339),
+		strings.Join(items, ""))
+
+	return EmailContent{
+		Subject:   fmt.Sprintf("Backup Verification Failed: backup %d of %s", data.BackupID, data.TargetName),
+		PlainText: plainText,
+		HTML:      html,
+	}
+}
+
+// createAlertContent creates the email content for alert firing and resolved notifications
+func (s *NotificationService) createAlertContent(data notifications.AlertData) EmailContent {
+	title := "Alert Firing"
+	color := "#ff0000"
+	if data.Severity == "info" {
+		color = "#0066cc"
+	} else if data.Severity == "warning" {
+		color = "#ff9900"
+	}
+	status := fmt.Sprintf("Firing Since: %s", data.FiredAt.Format(time.RFC3339))
+	if data.ResolvedAt != nil {
+		title = "Alert Resolved"
+		color = "#00cc00"
+		status = fmt.Sprintf("Resolved At: %s", data.ResolvedAt.Format(time.RFC3339))
+	}
+
+	labelNames := make([]string, 0, len(data.Labels))
+	for name := range data.Labels {
+		labelNames = append(labelNames, name)
+	}
+	sort.Strings(labelNames)
+	var plainLabels, htmlLabels strings.Builder
+	for _, name := range labelNames {
+		fmt.Fprintf(&plainLabels, "\n  - %s: %s", name, data.Labels[name])
+		fmt.Fprintf(&htmlLabels, "<li>%s: %s</li>", name, data.Labels[name])
+	}
+
+	// Create plain text content
+	plainText := fmt.Sprintf(`%s
+
+%s
+
+Details:
+- Rule: %s
+- Severity: %s
+- Expression: %s
+- Value: %g
+- %s
+- Labels:%s`,
+		title, data.Description, data.RuleName, data.Severity, data.Expression,
+		data.Value, status, plainLabels.String())
+
+	// Create HTML content
+	html := fmt.Sprintf(`
+	<html>
+		<body>
+			<h2 style="color: %s;">%s</h2>
+			<p>%s</p>
+
+			<h3>Details:</h3>
+			<ul>
+				<li><strong>Rule:</strong> %s</li>
+				<li><strong>Severity:</strong> %s</li>
+				<li><strong>Expression:</strong> <code>%s</code></li>
+				<li><strong>Value:</strong> %g</li>
+				<li><strong>%s</strong></li>
+				<li><strong>Labels:</strong><ul>%s</ul></li>
+			</ul>
+
+			<hr>
+			<small>Sent from ChainDeploy</small>
+		</body>
+	</html>`,
+		color, title, data.Description, data.RuleName, data.Severity, data.Expression,
+		data.Value, status, htmlLabels.String())
+
+	return EmailContent{
+		Subject:   fmt.Sprintf("%s: %s (%s)", title, data.RuleName, data.Severity),
+		PlainText: plainText,
+		HTML:      html,
+	}
+}
+
+// createCertExpiringContent creates the email content for certificate expiry notifications
+func (s *NotificationService) createCertExpiringContent(data notifications.CertificateExpiringData) EmailContent {
+	owner := data.KeyName
+	if data.NodeName != "" {
+		owner = fmt.Sprintf("%s (%s)", data.NodeName, data.NodeType)
+	}
+
+	headline := fmt.Sprintf("expires in %d day(s)", data.DaysRemaining)
+	if data.DaysRemaining < 0 {
+		headline = "has expired"
+	}
+
+	renewal := "Automatic renewal is not enabled for this certificate."
+	switch {
+	case data.AutoRenewed:
+		renewal = "The certificate was renewed automatically."
+	case data.AutoRenewalError != "":
+		renewal = fmt.Sprintf("Automatic renewal failed: %s", data.AutoRenewalError)
+	}
+
+	// Create plain text content
+	plainText := fmt.Sprintf(`Certificate Expiry Warning
+
+A %s certificate of %s %s.
+
+Details:
+- Certificate: %s
+- Common Name: %s
+- Expires At: %s
+- Days Remaining: %d
+
+%s`,
+		data.CertificateType, owner, headline,
+		data.Subject, data.CommonName, data.ExpiresAt.Format(time.RFC3339), data.DaysRemaining,
+		renewal)
+
+	// Create HTML content
+	html := fmt.Sprintf(`
+	<html>
+		<body>
+			<h2>Certificate Expiry Warning</h2>
+			<p>A %s certificate of %s %s.</p>
+
+			<h3>Details:</h3>
+			<ul>
+				<li><strong>Certificate:</strong> %s</li>
+				<li><strong>Common Name:</strong> %s</li>
+				<li><strong>Expires At:</strong> %s</li>
+				<li><strong>Days Remaining:</strong> %d</li>
+			</ul>
+
+			<p>%s</p>
+			<hr>
+			<small>Sent from ChainDeploy</small>
+		</body>
+	</html>`,
+		data.CertificateType, owner, headline,
+		data.Subject, data.CommonName, data.ExpiresAt.Format(time.RFC3339), data.DaysRemaining,
+		renewal)
+
+	return EmailContent{
+		Subject:   fmt.Sprintf("Certificate Expiry: %s %s", owner, headline),
+		PlainText: plainText,
+		HTML:      html,
+	}
+}
+
 func (s *NotificationService) createNotificationContent(notificationType notifications.NotificationType, data interface{}) EmailContent {
 	switch notificationType {
 	case notifications.NotificationTypeNodeDowntime:
@@ -519,10 +892,30 @@ func (s *NotificationService) createNotificationContent(notificationType notific
 		if backupData, ok := data.(notifications.BackupFailureData); ok {
 			return s.createBackupFailureContent(backupData)
 		}
+	case notifications.NotificationTypeBackupVerificationFailure:
+		if backupData, ok := data.(notifications.BackupVerificationFailureData); ok {
+			return s.createBackupVerificationFailureContent(backupData)
+		}
 	case notifications.NotificationTypeS3ConnIssue:
 		if s3Data, ok := data.(notifications.S3ConnectionIssueData); ok {
 			return s.createS3ConnIssueContent(s3Data)
 		}
+	case notifications.NotificationTypeNodeRecovery:
+		if nodeData, ok := data.(notifications.NodeUpData); ok {
+			return s.createNodeRecoveryContent(nodeData)
+		}
+	case notifications.NotificationTypeNodeDegraded:
+		if nodeData, ok := data.(notifications.NodeDegradedData); ok {
+			return s.createNodeDegradedContent(nodeData)
+		}
+	case notifications.NotificationTypeCertExpiring:
+		if certData, ok := data.(notifications.CertificateExpiringData); ok {
+			return s.createCertExpiringContent(certData)
+		}
+	case notifications.NotificationTypeAlertFiring, notifications.NotificationTypeAlertResolved:
+		if alertData, ok := data.(notifications.AlertData); ok {
+			return s.createAlertContent(alertData)
+		}
 	}
 
 	// Fallback for invalid data type
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+)
+
+const webhookTimeout = 15 * time.Second
+
+// WebhookMessage is the provider independent content of a webhook notification
+type WebhookMessage struct {
+	Type  notifications.NotificationType
+	Title string
+	Text  string
+	Data  interface{}
+}
+
+// newWebhookMessage builds the message for a notification, rendering the provider
+// template for the notification type when one is configured
+func newWebhookMessage(templates notifications.MessageTemplates, notificationType notifications.NotificationType, content EmailContent, data interface{}) (WebhookMessage, error) {
+	msg := WebhookMessage{
+		Type:  notificationType,
+		Title: content.Subject,
+		Text:  content.PlainText,
+		Data:  data,
+	}
+
+	tmpl, ok := templates[notificationType]
+	if !ok || tmpl == "" {
+		return msg, nil
+	}
+	t, err := template.New(string(notificationType)).Parse(tmpl)
+	if err != nil {
+		return msg, fmt.Errorf("failed to parse %s template: %w", notificationType, err)
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return msg, fmt.Errorf("failed to render %s template: %w", notificationType, err)
+	}
+	msg.Text = buf.String()
+	return msg, nil
+}
+
+// validateTemplates checks that every template of a provider parses
+func validateTemplates(templates notifications.MessageTemplates) error {
+	for notificationType, tmpl := range templates {
+		if _, err := template.New(string(notificationType)).Parse(tmpl); err != nil {
+			return fmt.Errorf("invalid %s template: %w", notificationType, err)
+		}
+	}
+	return nil
+}
+
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("webhook URL must use http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("webhook URL has no host")
+	}
+	return nil
+}
+
+// sendSlack posts a message to a Slack incoming webhook
+func (s *NotificationService) sendSlack(ctx context.Context, config notifications.SlackConfig, msg WebhookMessage) error {
+	payload := map[string]interface{}{
+		"text": fmt.Sprintf("*%s*\n%s", msg.Title, msg.Text),
+	}
+	if config.Channel != "" {
+		payload["channel"] = config.Channel
+	}
+	if config.Username != "" {
+		payload["username"] = config.Username
+	}
+	if config.IconEmoji != "" {
+		payload["icon_emoji"] = config.IconEmoji
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+	return postWebhook(ctx, config.WebhookURL, body, nil)
+}
+
+// sendTeams posts an adaptive card to a Microsoft Teams incoming webhook
+func (s *NotificationService) sendTeams(ctx context.Context, config notifications.TeamsConfig, msg WebhookMessage) error {
+	payload := map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body": []map[string]interface{}{
+						{
+							"type":   "TextBlock",
+							"text":   msg.Title,
+							"weight": "Bolder",
+							"size":   "Medium",
+							"wrap":   true,
+						},
+						{
+							"type": "TextBlock",
+							"text": msg.Text,
+							"wrap": true,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Teams message: %w", err)
+	}
+	return postWebhook(ctx, config.WebhookURL, body, nil)
+}
+
+// sendWebhook posts a JSON payload to a generic webhook, signing it when a secret
+// is configured
+func (s *NotificationService) sendWebhook(ctx context.Context, config notifications.WebhookConfig, msg WebhookMessage) error {
+	body, err := json.Marshal(notifications.WebhookPayload{
+		Type:      msg.Type,
+		Title:     msg.Title,
+		Message:   msg.Text,
+		Data:      msg.Data,
+		Timestamp: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	headers := make(map[string]string, len(config.Headers)+2)
+	for k, v := range config.Headers {
+		headers[k] = v
+	}
+	if config.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		headers["X-ChainLaunch-Timestamp"] = timestamp
+		headers["X-ChainLaunch-Signature"] = "sha256=" + signWebhookPayload(config.Secret, timestamp, body)
+	}
+	return postWebhook(ctx, config.URL, body, headers)
+}
+
+// signWebhookPayload returns the hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+func signWebhookPayload(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func postWebhook(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/chainlaunch/chainlaunch/pkg/db"
+	"github.com/chainlaunch/chainlaunch/pkg/notifications"
+)
+
+// webhookReceiver records the requests posted to it
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	headers  http.Header
+	body     []byte
+	requests int
+}
+
+func newWebhookReceiver(t *testing.T, status int) (*webhookReceiver, *httptest.Server) {
+	receiver := &webhookReceiver{status: status}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		receiver.mu.Lock()
+		receiver.headers = r.Header.Clone()
+		receiver.body = body
+		receiver.requests++
+		receiver.mu.Unlock()
+		w.WriteHeader(receiver.status)
+		if receiver.status >= 300 {
+			w.Write([]byte("invalid_token\n"))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return receiver, server
+}
+
+func (r *webhookReceiver) last(t *testing.T) (http.Header, map[string]interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.requests == 0 {
+		t.Fatal("no request received")
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(r.body, &payload); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	return r.headers, payload
+}
+
+func testMessage() WebhookMessage {
+	return WebhookMessage{
+		Type:  notifications.NotificationTypeNodeDowntime,
+		Title: "Node peer0 is down",
+		Text:  "peer0 stopped responding",
+		Data:  map[string]string{"nodeName": "peer0"},
+	}
+}
+
+func TestSendWebhookSigned(t *testing.T) {
+	receiver, server := newWebhookReceiver(t, http.StatusNoContent)
+	s := &NotificationService{}
+	config := notifications.WebhookConfig{
+		URL:     server.URL,
+		Secret:  "whsec",
+		Headers: map[string]string{"X-Team": "ops"},
+	}
+	if err := s.sendWebhook(context.Background(), config, testMessage()); err != nil {
+		t.Fatalf("failed to send webhook: %v", err)
+	}
+
+	headers, payload := receiver.last(t)
+	if headers.Get("Content-Type") != "application/json" || headers.Get("X-Team") != "ops" {
+		t.Errorf("unexpected headers %v", headers)
+	}
+	if payload["type"] != string(notifications.NotificationTypeNodeDowntime) || payload["title"] != "Node peer0 is down" ||
+		payload["message"] != "peer0 stopped responding" || payload["timestamp"] == nil {
+		t.Errorf("unexpected payload %v", payload)
+	}
+
+	// A receiver verifies the signature over "<timestamp>.<body>"
+	timestamp := headers.Get("X-ChainLaunch-Timestamp")
+	signature := headers.Get("X-ChainLaunch-Signature")
+	if timestamp == "" || !strings.HasPrefix(signature, "sha256=") {
+		t.Fatalf("request is not signed: %v", headers)
+	}
+	receiver.mu.Lock()
+	expected := "sha256=" + signWebhookPayload("whsec", timestamp, receiver.body)
+	receiver.mu.Unlock()
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		t.Errorf("signature %s doesn't match the body", signature)
+	}
+}
+
+func TestSendWebhookUnsigned(t *testing.T) {
+	receiver, server := newWebhookReceiver(t, http.StatusOK)
+	s := &NotificationService{}
+	if err := s.sendWebhook(context.Background(), notifications.WebhookConfig{URL: server.URL}, testMessage()); err != nil {
+		t.Fatalf("failed to send webhook: %v", err)
+	}
+	headers, _ := receiver.last(t)
+	if headers.Get("X-ChainLaunch-Signature") != "" || headers.Get("X-ChainLaunch-Timestamp") != "" {
+		t.Errorf("request without secret should not be signed: %v", headers)
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"type":"NODE_DOWNTIME"}`)
+	signature := signWebhookPayload("whsec", "1700000000", body)
+	if len(signature) != 64 {
+		t.Fatalf("expected a hex encoded SHA256 HMAC, got %q", signature)
+	}
+	cases := map[string]string{
+		"other secret":    signWebhookPayload("other", "1700000000", body),
+		"other timestamp": signWebhookPayload("whsec", "1700000001", body),
+		"other body":      signWebhookPayload("whsec", "1700000000", []byte(`{"type":"BACKUP_FAILURE"}`)),
+	}
+	for name, other := range cases {
+		if other == signature {
+			t.Errorf("%s: signature should change", name)
+		}
+	}
+}
+
+func TestSendSlack(t *testing.T) {
+	receiver, server := newWebhookReceiver(t, http.StatusOK)
+	s := &NotificationService{}
+	config := notifications.SlackConfig{WebhookURL: server.URL, Channel: "#alerts", Username: "chainlaunch"}
+	if err := s.sendSlack(context.Background(), config, testMessage()); err != nil {
+		t.Fatalf("failed to send Slack message: %v", err)
+	}
+	_, payload := receiver.last(t)
+	if payload["text"] != "*Node peer0 is down*\npeer0 stopped responding" {
+		t.Errorf("unexpected text %v", payload["text"])
+	}
+	if payload["channel"] != "#alerts" || payload["username"] != "chainlaunch" {
+		t.Errorf("unexpected payload %v", payload)
+	}
+	if _, ok := payload["icon_emoji"]; ok {
+		t.Error("empty icon should be omitted")
+	}
+}
+
+func TestSendTeams(t *testing.T) {
+	receiver, server := newWebhookReceiver(t, http.StatusOK)
+	s := &NotificationService{}
+	if err := s.sendTeams(context.Background(), notifications.TeamsConfig{WebhookURL: server.URL}, testMessage()); err != nil {
+		t.Fatalf("failed to send Teams message: %v", err)
+	}
+	_, payload := receiver.last(t)
+	attachments, ok := payload["attachments"].([]interface{})
+	if payload["type"] != "message" || !ok || len(attachments) != 1 {
+		t.Fatalf("unexpected payload %v", payload)
+	}
+	card := attachments[0].(map[string]interface{})["content"].(map[string]interface{})
+	body := card["body"].([]interface{})
+	if card["type"] != "AdaptiveCard" || len(body) != 2 ||
+		body[0].(map[string]interface{})["text"] != "Node peer0 is down" ||
+		body[1].(map[string]interface{})["text"] != "peer0 stopped responding" {
+		t.Errorf("unexpected card %v", card)
+	}
+}
+
+func TestPostWebhookErrors(t *testing.T) {
+	_, failing := newWebhookReceiver(t, http.StatusForbidden)
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := map[string]struct {
+		ctx      context.Context
+		url      string
+		contains string
+	}{
+		"error status":    {context.Background(), failing.URL, "status 403: invalid_token"},
+		"unreachable":     {context.Background(), closed.URL, "failed to post webhook"},
+		"invalid url":     {context.Background(), "http://[::1", "failed to create request"},
+		"context expired": {canceled, failing.URL, "failed to post webhook"},
+	}
+	for name, c := range cases {
+		err := postWebhook(c.ctx, c.url, []byte(`{}`), nil)
+		if err == nil || !strings.Contains(err.Error(), c.contains) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, c.contains, err)
+		}
+	}
+}
+
+func TestNewWebhookMessage(t *testing.T) {
+	content := EmailContent{Subject: "Node down", PlainText: "default text"}
+	data := notifications.NodeDowntimeData{NodeName: "peer0"}
+
+	cases := []struct {
+		name      string
+		templates notifications.MessageTemplates
+		text      string
+		wantErr   bool
+	}{
+		{"no templates", nil, "default text", false},
+		{"other type", notifications.MessageTemplates{notifications.NotificationTypeBackupFailure: "backup"}, "default text", false},
+		{"custom template", notifications.MessageTemplates{notifications.NotificationTypeNodeDowntime: "{{.NodeName}} is down"}, "peer0 is down", false},
+		{"parse error", notifications.MessageTemplates{notifications.NotificationTypeNodeDowntime: "{{.NodeName"}, "", true},
+		{"unknown field", notifications.MessageTemplates{notifications.NotificationTypeNodeDowntime: "{{.Missing}}"}, "", true},
+	}
+	for _, c := range cases {
+		msg, err := newWebhookMessage(c.templates, notifications.NotificationTypeNodeDowntime, content, data)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if msg.Text != c.text || msg.Title != "Node down" || msg.Type != notifications.NotificationTypeNodeDowntime {
+			t.Errorf("%s: unexpected message %+v", c.name, msg)
+		}
+	}
+}
+
+func TestValidateProviderConfig(t *testing.T) {
+	cases := []struct {
+		name         string
+		providerType notifications.ProviderType
+		config       interface{}
+		wantErr      bool
+	}{
+		{"slack", notifications.ProviderTypeSlack, notifications.SlackConfig{WebhookURL: "https://hooks.slack.com/services/x"}, false},
+		{"teams", notifications.ProviderTypeTeams, notifications.TeamsConfig{WebhookURL: "https://example.webhook.office.com/x"}, false},
+		{"webhook", notifications.ProviderTypeWebhook, notifications.WebhookConfig{URL: "http://receiver:8080/hook", Secret: "s"}, false},
+		{"smtp is not a webhook", notifications.ProviderTypeSMTP, map[string]interface{}{"host": "smtp"}, false},
+		{"no url", notifications.ProviderTypeSlack, notifications.SlackConfig{}, true},
+		{"ftp scheme", notifications.ProviderTypeTeams, notifications.TeamsConfig{WebhookURL: "ftp://example.com/x"}, true},
+		{"no host", notifications.ProviderTypeWebhook, notifications.WebhookConfig{URL: "https:///hook"}, true},
+		{"bad template", notifications.ProviderTypeWebhook, notifications.WebhookConfig{
+			URL:       "https://receiver/hook",
+			Templates: notifications.MessageTemplates{notifications.NotificationTypeNodeDowntime: "{{if}}"},
+		}, true},
+		{"wrong config shape", notifications.ProviderTypeSlack, map[string]interface{}{"webhookUrl": 42}, true},
+	}
+	for _, c := range cases {
+		err := validateProviderConfig(c.providerType, c.config)
+		if c.wantErr != (err != nil) {
+			t.Errorf("%s: expected error %v, got %v", c.name, c.wantErr, err)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidProviderConfig) {
+			t.Errorf("%s: expected ErrInvalidProviderConfig, got %v", c.name, err)
+		}
+	}
+}
+
+func TestDeliverWebhookProviders(t *testing.T) {
+	receiver, server := newWebhookReceiver(t, http.StatusOK)
+	s := &NotificationService{}
+	content := EmailContent{Subject: "Node down", PlainText: "default text"}
+	data := notifications.NodeDowntimeData{NodeName: "peer0"}
+
+	// The generic webhook goes last so its payload can be checked
+	providers := []*db.NotificationProvider{
+		{Name: "slack", Type: string(notifications.ProviderTypeSlack), Config: `{"webhookUrl":"` + server.URL + `"}`},
+		{Name: "teams", Type: string(notifications.ProviderTypeTeams), Config: `{"webhookUrl":"` + server.URL + `"}`},
+		{Name: "webhook", Type: string(notifications.ProviderTypeWebhook), Config: `{"url":"` + server.URL + `","templates":{"NODE_DOWNTIME":"{{.NodeName}} is down"}}`},
+	}
+	for _, provider := range providers {
+		if err := s.deliver(context.Background(), provider, notifications.NotificationTypeNodeDowntime, content, data); err != nil {
+			t.Errorf("%s: failed to deliver: %v", provider.Name, err)
+		}
+	}
+	if _, payload := receiver.last(t); payload["message"] != "peer0 is down" {
+		t.Errorf("webhook template not applied: %v", payload)
+	}
+
+	invalid := map[string]*db.NotificationProvider{
+		"unknown type": {Type: "PAGER", Config: `{}`},
+		"bad config":   {Type: string(notifications.ProviderTypeWebhook), Config: `{`},
+		"bad template": {Type: string(notifications.ProviderTypeSlack), Config: `{"webhookUrl":"` + server.URL + `","templates":{"NODE_DOWNTIME":"{{.Missing}}"}}`},
+	}
+	for name, provider := range invalid {
+		if err := s.deliver(context.Background(), provider, notifications.NotificationTypeNodeDowntime, content, data); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -9,8 +9,22 @@ const (
 	NotificationTypeNodeDowntime  NotificationType = "NODE_DOWNTIME"
 	NotificationTypeBackupSuccess NotificationType = "BACKUP_SUCCESS"
 	NotificationTypeBackupFailure NotificationType = "BACKUP_FAILURE"
-	
-	NotificationTypeS3ConnIssue   NotificationType = "S3_CONNECTION_ISSUE"
+
+	NotificationTypeS3ConnIssue NotificationType = "S3_CONNECTION_ISSUE"
+	// NotificationTypeNodeRecovery is routed like NODE_DOWNTIME. It only exists
+	// so providers can define a separate message template for recoveries.
+	NotificationTypeNodeRecovery NotificationType = "NODE_RECOVERY"
+	// NotificationTypeNodeDegraded is routed like NODE_DOWNTIME as well, it's sent
+	// when a reachable node fails one of its protocol health checks
+	NotificationTypeNodeDegraded NotificationType = "NODE_DEGRADED"
+	NotificationTypeCertExpiring NotificationType = "CERTIFICATE_EXPIRING"
+	// NotificationTypeBackupVerificationFailure is routed like BACKUP_FAILURE, it's
+	// sent when a completed backup fails its integrity check or test restore
+	NotificationTypeBackupVerificationFailure NotificationType = "BACKUP_VERIFICATION_FAILURE"
+	// NotificationTypeAlertFiring and NotificationTypeAlertResolved are not routed by
+	// provider flags, each alert rule lists the providers it notifies
+	NotificationTypeAlertFiring   NotificationType = "ALERT_FIRING"
+	NotificationTypeAlertResolved NotificationType = "ALERT_RESOLVED"
 )
 
 // NotificationDeliveryType represents different notification providers
@@ -24,7 +38,10 @@ const (
 type ProviderType string
 
 const (
-	ProviderTypeSMTP ProviderType = "SMTP"
+	ProviderTypeSMTP    ProviderType = "SMTP"
+	ProviderTypeSlack   ProviderType = "SLACK"
+	ProviderTypeTeams   ProviderType = "TEAMS"
+	ProviderTypeWebhook ProviderType = "WEBHOOK"
 )
 
 // NotificationProvider represents a notification provider configuration
@@ -38,6 +55,7 @@ type NotificationProvider struct {
 	NotifyBackupSuccess bool         `json:"notifyBackupSuccess"`
 	NotifyBackupFailure bool         `json:"notifyBackupFailure"`
 	NotifyS3ConnIssue   bool         `json:"notifyS3ConnIssue"`
+	NotifyCertExpiry    bool         `json:"notifyCertExpiry"`
 	LastTestAt          *time.Time   `json:"lastTestAt,omitempty"`
 	LastTestStatus      string       `json:"lastTestStatus,omitempty"`
 	LastTestMessage     string       `json:"lastTestMessage,omitempty"`
@@ -47,7 +65,7 @@ type NotificationProvider struct {
 
 // CreateProviderParams represents parameters for creating a provider
 type CreateProviderParams struct {
-	Type                ProviderType `validate:"required,oneof=SMTP"`
+	Type                ProviderType `validate:"required,oneof=SMTP SLACK TEAMS WEBHOOK"`
 	Name                string       `validate:"required,min=1,max=255"`
 	Config              interface{}  `validate:"required"`
 	IsDefault           bool
@@ -55,12 +73,13 @@ type CreateProviderParams struct {
 	NotifyBackupSuccess bool
 	NotifyBackupFailure bool
 	NotifyS3ConnIssue   bool
+	NotifyCertExpiry    bool
 }
 
 // UpdateProviderParams represents parameters for updating a provider
 type UpdateProviderParams struct {
 	ID                  int64        `validate:"required"`
-	Type                ProviderType `validate:"required,oneof=SMTP"`
+	Type                ProviderType `validate:"required,oneof=SMTP SLACK TEAMS WEBHOOK"`
 	Name                string       `validate:"required,min=1,max=255"`
 	Config              interface{}  `validate:"required"`
 	IsDefault           bool
@@ -68,6 +87,7 @@ type UpdateProviderParams struct {
 	NotifyBackupSuccess bool
 	NotifyBackupFailure bool
 	NotifyS3ConnIssue   bool
+	NotifyCertExpiry    bool
 }
 
 // SMTPConfig represents SMTP provider configuration
@@ -80,9 +100,50 @@ type SMTPConfig struct {
 	TLS      bool   `json:"tls"`
 }
 
+// MessageTemplates maps a notification type (e.g. NODE_DOWNTIME, NODE_RECOVERY,
+// BACKUP_FAILURE) to a Go text/template rendered with the notification data.
+// Types without a template use the default plain text message.
+type MessageTemplates map[NotificationType]string
+
+// SlackConfig represents Slack incoming webhook provider configuration
+type SlackConfig struct {
+	WebhookURL string           `json:"webhookUrl" validate:"required,url"`
+	Channel    string           `json:"channel,omitempty"`
+	Username   string           `json:"username,omitempty"`
+	IconEmoji  string           `json:"iconEmoji,omitempty"`
+	Templates  MessageTemplates `json:"templates,omitempty"`
+}
+
+// TeamsConfig represents Microsoft Teams incoming webhook provider configuration
+type TeamsConfig struct {
+	WebhookURL string           `json:"webhookUrl" validate:"required,url"`
+	Templates  MessageTemplates `json:"templates,omitempty"`
+}
+
+// WebhookConfig represents generic webhook provider configuration. When Secret is
+// set, every request carries an X-ChainLaunch-Timestamp header and an
+// X-ChainLaunch-Signature header containing "sha256=" followed by the hex encoded
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret.
+type WebhookConfig struct {
+	URL       string            `json:"url" validate:"required,url"`
+	Secret    string            `json:"secret,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Templates MessageTemplates  `json:"templates,omitempty"`
+}
+
+// WebhookPayload is the JSON body posted by generic webhook providers
+type WebhookPayload struct {
+	Type      NotificationType `json:"type"`
+	Title     string           `json:"title"`
+	Message   string           `json:"message"`
+	Data      interface{}      `json:"data,omitempty"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
 // TestProviderParams represents parameters for testing a provider
 type TestProviderParams struct {
-	TestEmail string `json:"testEmail" validate:"required,email"`
+	// TestEmail is the recipient of the test message, required for SMTP providers
+	TestEmail string `json:"testEmail" validate:"omitempty,email"`
 }
 
 // TestResult represents the result of testing a notification provider
@@ -160,3 +221,62 @@ type NodeUpData struct {
 	ResponseTime     time.Duration `json:"responseTime"`
 	DowntimeDuration time.Duration `json:"downtimeDuration"`
 }
+
+// AlertData represents data for notifications about alert rules that fired or resolved
+type AlertData struct {
+	AlertID     int64             `json:"alertId"`
+	RuleID      int64             `json:"ruleId"`
+	RuleName    string            `json:"ruleName"`
+	Description string            `json:"description,omitempty"`
+	Expression  string            `json:"expression"`
+	Severity    string            `json:"severity"`
+	Labels      map[string]string `json:"labels"`
+	Value       float64           `json:"value"`
+	ActiveAt    time.Time         `json:"activeAt"`
+	FiredAt     time.Time         `json:"firedAt"`
+	ResolvedAt  *time.Time        `json:"resolvedAt,omitempty"`
+}
+
+// NodeDegradedData represents data for notifications about nodes that are reachable
+// but fail a protocol health check, such as a peer lagging behind its channel
+type NodeDegradedData struct {
+	NodeID     int64     `json:"nodeId"`
+	NodeName   string    `json:"nodeName"`
+	NodeType   string    `json:"nodeType"`
+	Endpoint   string    `json:"endpoint"`
+	Reason     string    `json:"reason"`
+	Message    string    `json:"message"`
+	DetectedAt time.Time `json:"detectedAt"`
+}
+
+// BackupVerificationFailureData represents data for notifications about backups
+// failing their verification
+type BackupVerificationFailureData struct {
+	BackupID     int64     `json:"backupId"`
+	SnapshotID   string    `json:"snapshotId"`
+	ScheduleName string    `json:"scheduleName"`
+	TargetName   string    `json:"targetName"`
+	TargetType   string    `json:"targetType"`
+	BackupTime   time.Time `json:"backupTime"`
+	VerifiedAt   time.Time `json:"verifiedAt"`
+	// FailedChecks lists the checks which failed with their error
+	FailedChecks []string `json:"failedChecks"`
+}
+
+// CertificateExpiringData represents data for certificate expiry notifications
+type CertificateExpiringData struct {
+	// Subject identifies the certificate, e.g. "node:3:tls" or "key:12"
+	Subject          string    `json:"subject"`
+	CertificateType  string    `json:"certificateType"`
+	CommonName       string    `json:"commonName"`
+	KeyID            int64     `json:"keyId,omitempty"`
+	KeyName          string    `json:"keyName,omitempty"`
+	NodeID           int64     `json:"nodeId,omitempty"`
+	NodeName         string    `json:"nodeName,omitempty"`
+	NodeType         string    `json:"nodeType,omitempty"`
+	ExpiresAt        time.Time `json:"expiresAt"`
+	DaysRemaining    int       `json:"daysRemaining"`
+	ThresholdDays    int       `json:"thresholdDays"`
+	AutoRenewed      bool      `json:"autoRenewed"`
+	AutoRenewalError string    `json:"autoRenewalError,omitempty"`
+}
@@ -106,9 +106,13 @@ func (v *FabricKeyValue) GetValue(ctx context.Context) (interface{}, error) {
 	}
 
 	// Get private key
-	privateKey, err := v.keyManagement.GetDecryptedPrivateKey(int(v.KeyID))
+	privateKey, err := v.privateKey(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get private key: %w", err)
+		return nil, err
+	}
+	keyPath := ""
+	if privateKey != "" {
+		keyPath = fmt.Sprintf("/etc/chainlaunch/%s/key.pem", v.Key)
 	}
 
 	// Create key details
@@ -122,7 +126,7 @@ func (v *FabricKeyValue) GetValue(ctx context.Context) (interface{}, error) {
 		Certificate: *key.Certificate,
 		PrivateKey:  privateKey,
 		CertPath:    fmt.Sprintf("/etc/chainlaunch/%s/cert.pem", v.Key),
-		KeyPath:     fmt.Sprintf("/etc/chainlaunch/%s/key.pem", v.Key),
+		KeyPath:     keyPath,
 	}
 
 	return details, nil
@@ -138,9 +142,9 @@ func (v *FabricKeyValue) GetVolumeMounts(ctx context.Context) ([]VolumeMount, er
 	}
 
 	// Get private key
-	privateKey, err := v.keyManagement.GetDecryptedPrivateKey(int(v.KeyID))
+	privateKey, err := v.privateKey(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get private key: %w", err)
+		return nil, err
 	}
 
 	// Create a temporary directory for the key
@@ -155,12 +159,6 @@ func (v *FabricKeyValue) GetVolumeMounts(ctx context.Context) ([]VolumeMount, er
 		return nil, fmt.Errorf("failed to write certificate: %w", err)
 	}
 
-	// Create private key file
-	keyPath := filepath.Join(tempDir, "key.pem")
-	if err := os.WriteFile(keyPath, []byte(privateKey), 0600); err != nil {
-		return nil, fmt.Errorf("failed to write private key: %w", err)
-	}
-
 	// Add volume mounts
 	mounts = append(mounts, VolumeMount{
 		Source:      certPath,
@@ -170,6 +168,17 @@ func (v *FabricKeyValue) GetVolumeMounts(ctx context.Context) ([]VolumeMount, er
 		Description: fmt.Sprintf("Fabric key certificate for %s", key.Name),
 	})
 
+	// Keys held in a PKCS#11 token only mount their certificate
+	if privateKey == "" {
+		return mounts, nil
+	}
+
+	// Create private key file
+	keyPath := filepath.Join(tempDir, "key.pem")
+	if err := os.WriteFile(keyPath, []byte(privateKey), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write private key: %w", err)
+	}
+
 	mounts = append(mounts, VolumeMount{
 		Source:      keyPath,
 		Target:      fmt.Sprintf("/etc/chainlaunch/%s/key.pem", v.Key),
@@ -180,3 +189,20 @@ func (v *FabricKeyValue) GetVolumeMounts(ctx context.Context) ([]VolumeMount, er
 
 	return mounts, nil
 }
+
+// privateKey returns the PEM private key, or an empty string when the key is
+// held in a PKCS#11 token and can't be exported
+func (v *FabricKeyValue) privateKey(ctx context.Context) (string, error) {
+	settings, err := v.keyManagement.GetPKCS11Settings(ctx, int(v.KeyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get key provider: %w", err)
+	}
+	if settings != nil {
+		return "", nil
+	}
+	privateKey, err := v.keyManagement.GetDecryptedPrivateKey(int(v.KeyID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get private key: %w", err)
+	}
+	return privateKey, nil
+}